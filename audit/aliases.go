@@ -0,0 +1,139 @@
+package audit
+
+import (
+	"path"
+	"regexp"
+
+	"github.com/brandonapol/snoop/osv"
+)
+
+// advisoryIDRegex matches advisory identifiers such as GHSA-xxxx-xxxx-xxxx,
+// CVE-2024-1234, or PYSEC-2024-1
+var advisoryIDRegex = regexp.MustCompile(`^[A-Z][A-Z0-9]*-[A-Za-z0-9-]+$`)
+
+// mergeAliasedAdvisories collapses advisories that describe the same
+// vulnerability, such as a GHSA and a PYSEC entry that both alias one CVE, so a
+// package isn't counted once per advisory database. Two advisories are the same
+// when their IDs or aliases overlap. Each merged advisory keeps the richest
+// description and the union of aliases and affected ranges, so fix versions
+// from every source are preserved.
+func mergeAliasedAdvisories(vulns []osv.Vulnerability) []osv.Vulnerability {
+	if len(vulns) < 2 {
+		return vulns
+	}
+
+	// Union-find over advisory indexes, linked through shared identifiers
+	parent := make([]int, len(vulns))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	owner := make(map[string]int)
+	for i, vuln := range vulns {
+		for _, id := range append([]string{vuln.ID}, vuln.Aliases...) {
+			if j, ok := owner[id]; ok {
+				if root, other := find(i), find(j); root != other {
+					// Keep the earliest advisory as the root so output order is stable
+					parent[max(root, other)] = min(root, other)
+				}
+				continue
+			}
+			owner[id] = i
+		}
+	}
+
+	var roots []int
+	groups := make(map[int][]osv.Vulnerability)
+	for i, vuln := range vulns {
+		root := find(i)
+		if _, ok := groups[root]; !ok {
+			roots = append(roots, root)
+		}
+		groups[root] = append(groups[root], vuln)
+	}
+	if len(roots) == len(vulns) {
+		return vulns
+	}
+
+	merged := make([]osv.Vulnerability, 0, len(roots))
+	for _, root := range roots {
+		merged = append(merged, mergeAdvisoryGroup(groups[root]))
+	}
+	return merged
+}
+
+// mergeAdvisoryGroup folds advisories for one vulnerability into the one with
+// the richest description
+func mergeAdvisoryGroup(group []osv.Vulnerability) osv.Vulnerability {
+	if len(group) == 1 {
+		return group[0]
+	}
+
+	primary := 0
+	for i, vuln := range group {
+		if descriptionLength(vuln) > descriptionLength(group[primary]) {
+			primary = i
+		}
+	}
+
+	merged := group[primary]
+	merged.Aliases = nil
+	merged.Affected = nil
+	merged.References = nil
+	merged.Severity = nil
+
+	seen := map[string]bool{merged.ID: true}
+	addAlias := func(id string) {
+		if !seen[id] {
+			seen[id] = true
+			merged.Aliases = append(merged.Aliases, id)
+		}
+	}
+
+	for _, vuln := range group {
+		addAlias(vuln.ID)
+		for _, alias := range vuln.Aliases {
+			addAlias(alias)
+		}
+		merged.Affected = append(merged.Affected, vuln.Affected...)
+		merged.References = append(merged.References, vuln.References...)
+		merged.Severity = append(merged.Severity, vuln.Severity...)
+	}
+
+	return merged
+}
+
+// descriptionLength measures how much descriptive text an advisory carries
+func descriptionLength(vuln osv.Vulnerability) int {
+	return len(vuln.Summary) + len(vuln.Details)
+}
+
+// AdvisoryIDs returns the advisory identifiers npm attributes to this entry:
+// GHSA IDs from npm audit's advisory URLs, or the OSV IDs recorded by the OSV
+// fallback. Package names that via uses for inherited findings are skipped.
+func (v Vulnerability) AdvisoryIDs() []string {
+	var ids []string
+	for _, via := range v.Via {
+		switch via := via.(type) {
+		case string:
+			// npm package names are lowercase, advisory IDs are not
+			if advisoryIDRegex.MatchString(via) {
+				ids = append(ids, via)
+			}
+		case map[string]any:
+			if advisoryURL, ok := via["url"].(string); ok {
+				if id := path.Base(advisoryURL); advisoryIDRegex.MatchString(id) {
+					ids = append(ids, id)
+				}
+			}
+		}
+	}
+	return ids
+}
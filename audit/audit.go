@@ -1,12 +1,18 @@
 package audit
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"time"
+
+	"github.com/brandonapol/snoop/osv"
+	"github.com/brandonapol/snoop/security"
 )
 
 // Severity represents the severity level of a vulnerability
@@ -22,14 +28,49 @@ const (
 
 // Vulnerability represents a security vulnerability in a package
 type Vulnerability struct {
-	Name         string          `json:"name"`
-	Severity     Severity        `json:"severity"`
-	IsDirect     bool            `json:"isDirect"`
-	Via          []any           `json:"via"`
-	Effects      []string        `json:"effects"`
-	Range        string          `json:"range"`
-	Nodes        []string        `json:"nodes"`
-	FixAvailable json.RawMessage `json:"fixAvailable,omitempty"`
+	Name         string       `json:"name"`
+	Severity     Severity     `json:"severity"`
+	IsDirect     bool         `json:"isDirect"`
+	Via          []any        `json:"via"`
+	Effects      []string     `json:"effects"`
+	Range        string       `json:"range"`
+	Nodes        []string     `json:"nodes"`
+	FixAvailable FixAvailable `json:"fixAvailable,omitzero"`
+}
+
+// FixAvailable is npm audit's fixAvailable field. npm writes it either as a
+// bool, true when npm audit fix resolves the finding within the declared
+// ranges, or as an object naming the package upgrade that fixes it. Reports
+// always write the object form, with Available set.
+type FixAvailable struct {
+	Available     bool   `json:"available"`
+	Name          string `json:"name,omitempty"`    // Package to upgrade, which may be a dependent of the vulnerable one
+	Version       string `json:"version,omitempty"` // Version to upgrade Name to
+	IsSemVerMajor bool   `json:"isSemVerMajor,omitempty"`
+}
+
+// UnmarshalJSON reads fixAvailable in either of the forms npm writes it
+func (f *FixAvailable) UnmarshalJSON(data []byte) error {
+	var available bool
+	if err := json.Unmarshal(data, &available); err == nil {
+		*f = FixAvailable{Available: available}
+		return nil
+	}
+
+	// The object form only appears when there's a fix, unless it's a report
+	// saying otherwise
+	type fixObject FixAvailable
+	object := fixObject{Available: true}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return fmt.Errorf("fixAvailable is neither a bool nor an object: %w", err)
+	}
+	*f = FixAvailable(object)
+	return nil
+}
+
+// Upgrade reports whether the fix names a version to upgrade to
+func (f FixAvailable) Upgrade() bool {
+	return f.Name != "" && f.Version != ""
 }
 
 // VulnerabilitySummary contains summary statistics for vulnerabilities
@@ -40,6 +81,19 @@ type VulnerabilitySummary struct {
 	High     int `json:"high"`
 	Critical int `json:"critical"`
 	Total    int `json:"total"`
+
+	// Direct and Transitive split Total by whether the vulnerable package
+	// is declared directly in the manifest or pulled in by another package
+	Direct     int `json:"direct"`
+	Transitive int `json:"transitive"`
+}
+
+// SecurityNote is an informational finding about a manifest that isn't tied
+// to a published advisory, such as a custom package index
+type SecurityNote struct {
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+	Line     int      `json:"line,omitempty"`
 }
 
 // DependencyMetadata contains dependency count information
@@ -67,18 +121,43 @@ type NpmAuditResponse struct {
 
 // AuditResult contains the results of running npm audit
 type AuditResult struct {
-	PackageJSONPath string
-	Response        *NpmAuditResponse
-	Vulnerabilities []Vulnerability
-	Summary         VulnerabilitySummary
-	RawOutput       string
-	Error           error
+	PackageJSONPath  string
+	Response         *NpmAuditResponse
+	Vulnerabilities  []Vulnerability
+	Summary          VulnerabilitySummary
+	RawOutput        string
+	Recommendations  []FixRecommendation
+	PackagesScanned  int
+	Dependencies     []NpmPackage // Every package of the project, for SBOM output
+	Warnings         []string     // Non-fatal problems such as failed queries
+	Unverified       bool         // Every OSV query failed, so no findings doesn't mean clean
+	WithdrawnSkipped int          // Advisories OSV has withdrawn, left out of the findings
+	// IncompleteLockfile is set when package-lock.json lacks resolved
+	// versions, so no findings doesn't mean clean
+	IncompleteLockfile bool
+	// Workspaces lists the package.json files of the workspace members
+	// audited along with this workspace root, rather than on their own
+	Workspaces []string
+	Error      error
 }
 
+// DefaultMaxUnpinnedAdvisories is the default number of advisories a version-less
+// query may return before they are collapsed into a single finding
+const DefaultMaxUnpinnedAdvisories = 10
+
 // Runner handles npm audit execution
 type Runner struct {
-	timeout time.Duration
-	verbose bool
+	ctx                   context.Context // Cancels OSV queries and npm audit in flight
+	timeout               time.Duration
+	verbose               bool
+	osvClient             *osv.Client
+	maxUnpinnedAdvisories int
+	includePrerelease     bool
+	includePolicy         IncludePolicy
+	excludedPackages      map[string]bool // Keyed by exclusionKey
+	includeIndirect       bool
+	minTyposquatting      security.Confidence // Empty reports every typosquat match
+	checks                []string            // Supply chain checks selected, such as security.CheckTyposquat
 }
 
 // NewRunner creates a new audit runner
@@ -87,37 +166,139 @@ func NewRunner(timeout time.Duration, verbose bool) *Runner {
 		timeout = 60 * time.Second // Default 60 second timeout
 	}
 	return &Runner{
-		timeout: timeout,
-		verbose: verbose,
+		ctx:                   context.Background(),
+		timeout:               timeout,
+		verbose:               verbose,
+		osvClient:             osv.NewClient(),
+		maxUnpinnedAdvisories: DefaultMaxUnpinnedAdvisories,
+		includePrerelease:     true,
 	}
 }
 
+// SetContext makes audits stop when ctx is done, such as when the user
+// interrupts a scan: OSV requests in flight are abandoned and npm audit is
+// killed. Each audit that didn't finish reports ctx's error.
+func (r *Runner) SetContext(ctx context.Context) {
+	r.ctx = ctx
+}
+
+// SetIncludePrerelease sets whether pre-release pins are matched by advisory
+// ranges that don't name a pre-release of the same version
+func (r *Runner) SetIncludePrerelease(include bool) {
+	r.includePrerelease = include
+}
+
+// SetIncludeIndirect sets whether requirements marked // indirect in go.mod are
+// audited. They are skipped by default to limit noise, and counted in
+// GoAuditResult.IndirectSkipped.
+func (r *Runner) SetIncludeIndirect(include bool) {
+	r.includeIndirect = include
+}
+
+// SetIncludePolicy bounds which files -r includes in requirements.txt may pull in
+func (r *Runner) SetIncludePolicy(policy IncludePolicy) {
+	r.includePolicy = policy
+}
+
+// OSVEndpoint returns the OSV API endpoint queried by OSV-based audits
+func (r *Runner) OSVEndpoint() string {
+	return r.osvClient.Endpoint()
+}
+
+// SetOSVClient replaces the client used for OSV-based audits
+func (r *Runner) SetOSVClient(client *osv.Client) {
+	r.osvClient = client
+}
+
+// SetMinTyposquattingConfidence drops typosquatting matches less confident
+// than min from audit results, such as the medium-confidence matches two
+// edits from a popular name that legitimately named internal packages tend to
+// produce
+func (r *Runner) SetMinTyposquattingConfidence(min security.Confidence) {
+	r.minTyposquatting = min
+}
+
+// SetSupplyChainChecks selects the supply chain checks audits run alongside
+// the vulnerability checks, such as security.CheckTyposquat, which compares
+// Python package names against popular PyPI packages. None run by default.
+func (r *Runner) SetSupplyChainChecks(checks []string) {
+	r.checks = checks
+}
+
+// SetOSVRateLimit sets how many requests per second OSV-based audits send at
+// most, across every manifest audited in parallel. Zero or less removes the
+// limit.
+func (r *Runner) SetOSVRateLimit(perSecond float64) {
+	r.osvClient.SetRateLimit(perSecond)
+}
+
+// SetOSVCache makes OSV-based audits reuse responses stored in cache and store
+// fresh ones in it
+func (r *Runner) SetOSVCache(cache *osv.Cache) {
+	r.osvClient.SetCache(cache)
+}
+
+// EnableAutoConcurrency runs OSV queries in parallel, adapting how many are in
+// flight to the API's current rate limits
+func (r *Runner) EnableAutoConcurrency() {
+	controller := osv.NewConcurrencyController(osv.DefaultMaxConcurrency)
+	if r.verbose {
+		controller.OnChange(func(limit int, reason string) {
+			fmt.Fprintf(os.Stderr, "  OSV concurrency: %d (%s)\n", limit, reason)
+		})
+	}
+	r.osvClient.SetConcurrencyController(controller)
+}
+
+// SetMaxUnpinnedAdvisories sets how many advisories a version-less query may
+// return before they are collapsed into one finding. Zero disables the cap.
+func (r *Runner) SetMaxUnpinnedAdvisories(max int) {
+	r.maxUnpinnedAdvisories = max
+}
+
 // CheckNpmInstalled checks if npm is installed and available
 func CheckNpmInstalled() error {
+	_, err := NpmVersion()
+	return err
+}
+
+// NpmVersion returns the version of the npm used for auditing
+func NpmVersion() (string, error) {
 	cmd := exec.Command("npm", "--version")
 	output, err := cmd.Output()
 	if err != nil {
-		return fmt.Errorf("npm is not installed or not available in PATH")
+		return "", fmt.Errorf("npm is not installed or not available in PATH")
 	}
 
-	if len(output) == 0 {
-		return fmt.Errorf("npm is not properly configured")
+	version := strings.TrimSpace(string(output))
+	if version == "" {
+		return "", fmt.Errorf("npm is not properly configured")
 	}
 
-	return nil
+	return version, nil
 }
 
 // RunAudit executes npm audit on a package.json file
 func (r *Runner) RunAudit(packageJSONPath string) *AuditResult {
+	// Get the directory containing package.json
+	dir := filepath.Dir(packageJSONPath)
+
+	// Without npm, a lockfile still gives exact versions to check against OSV
+	if CheckNpmInstalled() != nil && hasNpmLockfile(dir) {
+		if r.verbose {
+			fmt.Fprintln(os.Stderr, "npm is not installed, checking the lockfile against OSV")
+		}
+		return r.RunNpmOSVAudit(packageJSONPath)
+	}
+
 	result := &AuditResult{
 		PackageJSONPath: packageJSONPath,
 	}
-
-	// Get the directory containing package.json
-	dir := filepath.Dir(packageJSONPath)
+	r.checkPackageLock(result)
+	result.Dependencies = withoutExcluded(r, osv.NPM, npmDependencies(packageJSONPath))
 
 	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	ctx, cancel := context.WithTimeout(r.ctx, r.timeout)
 	defer cancel()
 
 	// Run npm audit --json
@@ -125,14 +306,21 @@ func (r *Runner) RunAudit(packageJSONPath string) *AuditResult {
 	cmd.Dir = dir
 
 	if r.verbose {
-		fmt.Printf("Running npm audit in: %s\n", dir)
+		fmt.Fprintf(os.Stderr, "Running npm audit in: %s\n", dir)
 	}
 
+	// stderr is kept apart from the JSON on stdout, to explain failures
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
 	output, err := cmd.Output()
 
 	// npm audit returns exit code 1 when vulnerabilities are found
 	// This is expected behavior, not an error
 	if err != nil {
+		if r.ctx.Err() != nil {
+			result.Error = fmt.Errorf("npm audit stopped: %w", r.ctx.Err())
+			return result
+		}
 		if ctx.Err() == context.DeadlineExceeded {
 			result.Error = fmt.Errorf("npm audit timed out after %v", r.timeout)
 			return result
@@ -143,7 +331,7 @@ func (r *Runner) RunAudit(packageJSONPath string) *AuditResult {
 			// Exit codes 1-6 are expected when vulnerabilities are found
 			// We still want to parse the output
 			if r.verbose {
-				fmt.Printf("npm audit exited with code: %d (vulnerabilities found)\n", exitErr.ExitCode())
+				fmt.Fprintf(os.Stderr, "npm audit exited with code: %d (vulnerabilities found)\n", exitErr.ExitCode())
 			}
 			// Continue to parse output
 		} else {
@@ -154,15 +342,25 @@ func (r *Runner) RunAudit(packageJSONPath string) *AuditResult {
 
 	result.RawOutput = string(output)
 
+	if err := npmAuditFailure(output, stderr.String()); err != nil {
+		result.Error = err
+		return result
+	}
+
 	// Parse JSON output
 	var auditResponse NpmAuditResponse
 	if err := json.Unmarshal(output, &auditResponse); err != nil {
-		result.Error = fmt.Errorf("failed to parse npm audit output: %w", err)
+		if message := npmStderrMessage(stderr.String()); message != "" {
+			result.Error = fmt.Errorf("failed to parse npm audit output: %w (npm: %s)", err, message)
+		} else {
+			result.Error = fmt.Errorf("failed to parse npm audit output: %w", err)
+		}
 		return result
 	}
 
 	result.Response = &auditResponse
 	result.Summary = auditResponse.Metadata.Vulnerabilities
+	result.PackagesScanned = auditResponse.Metadata.Dependencies.Total
 
 	// Convert map to slice for easier processing
 	for name, vuln := range auditResponse.Vulnerabilities {
@@ -170,24 +368,113 @@ func (r *Runner) RunAudit(packageJSONPath string) *AuditResult {
 		result.Vulnerabilities = append(result.Vulnerabilities, vuln)
 	}
 
+	// npm audit metadata doesn't split by directness, so count it ourselves
+	result.Summary.Direct, result.Summary.Transitive = countDirectness(result.Vulnerabilities)
+	result.withoutExcludedFindings(r)
+	result.Recommendations = RecommendNpmFixes(result.Vulnerabilities, DetectPackageManager(dir))
+
 	return result
 }
 
-// FilterBySeverity filters vulnerabilities by minimum severity level
-func FilterBySeverity(vulnerabilities []Vulnerability, minSeverity Severity) []Vulnerability {
-	severityLevel := map[Severity]int{
-		SeverityInfo:     0,
-		SeverityLow:      1,
-		SeverityModerate: 2,
-		SeverityHigh:     3,
-		SeverityCritical: 4,
+// npmAuditErrorReport is what npm audit --json writes in place of a report
+// when it can't audit, such as without a lockfile
+type npmAuditErrorReport struct {
+	Error *struct {
+		Code    string `json:"code"`
+		Summary string `json:"summary"`
+		Detail  string `json:"detail"`
+	} `json:"error"`
+}
+
+// npmLockfileHint is added to failures caused by a missing lockfile
+const npmLockfileHint = "run npm install first to create package-lock.json"
+
+// npmAuditFailure explains why npm audit produced no report: from the error
+// npm wrote to stdout, or from its stderr when stdout is empty. It returns nil
+// when stdout may hold a report.
+func npmAuditFailure(stdout []byte, stderr string) error {
+	var code, message string
+	if len(bytes.TrimSpace(stdout)) == 0 {
+		message = npmStderrMessage(stderr)
+		if message == "" {
+			message = "no output"
+		}
+	} else {
+		var report npmAuditErrorReport
+		if json.Unmarshal(stdout, &report) != nil || report.Error == nil {
+			return nil
+		}
+		code = report.Error.Code
+		message = strings.TrimSpace(report.Error.Summary)
+		if message == "" {
+			message = npmStderrMessage(stderr)
+		}
+	}
+
+	lower := strings.ToLower(message)
+	if code == "ENOLOCK" || strings.Contains(lower, "enolock") || strings.Contains(lower, "lockfile") || strings.Contains(lower, "package-lock.json") {
+		return fmt.Errorf("npm audit failed: %s; %s", message, npmLockfileHint)
+	}
+	return fmt.Errorf("npm audit failed: %s", message)
+}
+
+// npmStderrMessage condenses what npm wrote to stderr into one line, without
+// its warnings, "npm ERR!" prefixes, or the pointer to its debug log
+func npmStderrMessage(stderr string) string {
+	var lines []string
+	for _, line := range strings.Split(stderr, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(strings.ToLower(line), "npm warn") {
+			continue
+		}
+		for _, prefix := range []string{"npm ERR!", "npm error"} {
+			line = strings.TrimSpace(strings.TrimPrefix(line, prefix))
+		}
+		if line == "" || strings.Contains(line, "complete log of this run") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "; ")
+}
+
+// countDirectness counts direct and transitive npm vulnerabilities
+func countDirectness(vulnerabilities []Vulnerability) (direct, transitive int) {
+	for _, vuln := range vulnerabilities {
+		if vuln.IsDirect {
+			direct++
+		} else {
+			transitive++
+		}
 	}
+	return direct, transitive
+}
+
+// severityRank orders severity levels from least to most severe
+var severityRank = map[Severity]int{
+	SeverityInfo:     0,
+	SeverityLow:      1,
+	SeverityModerate: 2,
+	"medium":         2,
+	SeverityHigh:     3,
+	SeverityCritical: 4,
+}
 
-	minLevel := severityLevel[minSeverity]
-	var filtered []Vulnerability
+// SeverityRated is a finding with a severity. Every vulnerability type of
+// this package is one, so findings of any ecosystem filter the same way.
+type SeverityRated interface {
+	// SeverityLevel returns the finding's severity as written, such as
+	// "high" or "moderate"
+	SeverityLevel() string
+}
+
+// FilterBySeverity keeps the vulnerabilities at least as severe as
+// minSeverity
+func FilterBySeverity[T SeverityRated](vulnerabilities []T, minSeverity Severity) []T {
+	var filtered []T
 
 	for _, vuln := range vulnerabilities {
-		if severityLevel[vuln.Severity] >= minLevel {
+		if atLeastSeverity(vuln, minSeverity) {
 			filtered = append(filtered, vuln)
 		}
 	}
@@ -195,11 +482,118 @@ func FilterBySeverity(vulnerabilities []Vulnerability, minSeverity Severity) []V
 	return filtered
 }
 
+// atLeastSeverity reports whether a finding is at least as severe as
+// minSeverity
+func atLeastSeverity(finding SeverityRated, minSeverity Severity) bool {
+	return severityLevel(finding.SeverityLevel()) >= severityRank[minSeverity]
+}
+
+// severityLevel maps a severity written as a plain string, as OSV-backed
+// findings carry it, onto the same scale as Severity. Case is ignored, and a
+// level it doesn't recognize ranks as high, the level OSV findings default to.
+func severityLevel(severity string) int {
+	if level, ok := severityRank[Severity(strings.ToLower(severity))]; ok {
+		return level
+	}
+	return severityRank[SeverityHigh]
+}
+
+// SeverityLevel returns npm audit's severity for the finding
+func (v Vulnerability) SeverityLevel() string { return string(v.Severity) }
+
+// SeverityLevel returns the finding's severity
+func (v PythonVulnerability) SeverityLevel() string { return v.Severity }
+
+// SeverityLevel returns the finding's severity
+func (v GoVulnerability) SeverityLevel() string { return v.Severity }
+
+// SeverityLevel returns the finding's severity
+func (v MavenVulnerability) SeverityLevel() string { return v.Severity }
+
+// SeverityLevel returns the finding's severity
+func (v SwiftVulnerability) SeverityLevel() string { return v.Severity }
+
+// SeverityLevel returns the finding's severity
+func (v RuntimeVulnerability) SeverityLevel() string { return v.Severity }
+
+// SeverityLevel returns the finding's severity
+func (v SBOMVulnerability) SeverityLevel() string { return v.Severity }
+
+// SeverityLevel returns the finding's severity
+func (v CustomVulnerability) SeverityLevel() string { return v.Severity }
+
+// SeverityLevel returns the finding's severity
+func (v CargoVulnerability) SeverityLevel() string { return v.Severity }
+
+// SeverityLevel returns the finding's severity
+func (v RubyVulnerability) SeverityLevel() string { return v.Severity }
+
+// SeverityLevel returns the finding's severity
+func (v ComposerVulnerability) SeverityLevel() string { return v.Severity }
+
+// ExcludeInfo drops info-severity findings and removes them from the summary,
+// returning how many findings were dropped
+func (r *AuditResult) ExcludeInfo() int {
+	var kept []Vulnerability
+	for _, vuln := range r.Vulnerabilities {
+		if vuln.Severity == SeverityInfo {
+			continue
+		}
+		kept = append(kept, vuln)
+	}
+	dropped := len(r.Vulnerabilities) - len(kept)
+	r.Vulnerabilities = kept
+
+	// npm audit counts info findings in its metadata even when it reports none
+	r.Summary.Total -= r.Summary.Info
+	r.Summary.Info = 0
+	r.Summary.Direct, r.Summary.Transitive = countDirectness(kept)
+	return dropped
+}
+
 // HasVulnerabilities returns true if the result contains vulnerabilities
 func (r *AuditResult) HasVulnerabilities() bool {
 	return r.Summary.Total > 0
 }
 
+// batchThreshold is the number of packages above which a manifest is queried
+// with the OSV batch endpoint instead of one request per package
+const batchThreshold = 5
+
+// queryPackages queries OSV for pkgs, in one batch when there are enough of
+// them. If the batch fails, for example against a mirror that only serves
+// /v1/query, each package is queried on its own.
+func (r *Runner) queryPackages(pkgs []osv.Package) []osv.QueryResult {
+	if len(pkgs) > batchThreshold {
+		responses, err := r.osvClient.QueryBatchContext(r.ctx, pkgs)
+		if err == nil {
+			results := make([]osv.QueryResult, len(responses))
+			for i, response := range responses {
+				results[i] = osv.QueryResult{Response: response}
+			}
+			return results
+		}
+		if r.verbose {
+			fmt.Fprintf(os.Stderr, "OSV batch query failed, querying packages individually: %v\n", err)
+		}
+	}
+	return r.osvClient.QueryPackagesContext(r.ctx, pkgs)
+}
+
+// allQueriesFailed reports whether a batch of OSV queries never got an answer,
+// which means the backend is down rather than the packages being clean
+func allQueriesFailed(results []osv.QueryResult) bool {
+	if len(results) == 0 {
+		return false
+	}
+	for _, result := range results {
+		if result.Err == nil {
+			return false
+		}
+	}
+	return true
+}
+
 // GetSeverityColor returns ANSI color code for severity level
 func GetSeverityColor(severity Severity) string {
 	switch severity {
@@ -221,6 +615,44 @@ func ResetColor() string {
 	return "\033[0m"
 }
 
+// AddDirectness records a single finding as direct or transitive
+func (s *VulnerabilitySummary) AddDirectness(isDirect bool) {
+	if isDirect {
+		s.Direct++
+	} else {
+		s.Transitive++
+	}
+}
+
+// Add accumulates the counts of another summary into this one
+func (s *VulnerabilitySummary) Add(other VulnerabilitySummary) {
+	s.Info += other.Info
+	s.Low += other.Low
+	s.Moderate += other.Moderate
+	s.High += other.High
+	s.Critical += other.Critical
+	s.Total += other.Total
+	s.Direct += other.Direct
+	s.Transitive += other.Transitive
+}
+
+// AtOrAbove returns how many findings are of minSeverity or more severe
+func (s VulnerabilitySummary) AtOrAbove(minSeverity Severity) int {
+	count := 0
+	for severity, n := range map[Severity]int{
+		SeverityCritical: s.Critical,
+		SeverityHigh:     s.High,
+		SeverityModerate: s.Moderate,
+		SeverityLow:      s.Low,
+		SeverityInfo:     s.Info,
+	} {
+		if severityRank[severity] >= severityRank[minSeverity] {
+			count += n
+		}
+	}
+	return count
+}
+
 // FormatSummary returns a formatted summary string
 func (s *VulnerabilitySummary) FormatSummary() string {
 	if s.Total == 0 {
@@ -243,6 +675,9 @@ func (s *VulnerabilitySummary) FormatSummary() string {
 	if s.Info > 0 {
 		summary += fmt.Sprintf("  Info: %d\n", s.Info)
 	}
+	if s.Direct > 0 || s.Transitive > 0 {
+		summary += fmt.Sprintf("  Direct: %d, Transitive: %d\n", s.Direct, s.Transitive)
+	}
 
 	return summary
 }
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
 	"time"
 )
@@ -70,6 +72,239 @@ func TestFilterBySeverity(t *testing.T) {
 	}
 }
 
+func TestFilterBySeverityPython(t *testing.T) {
+	vulnerabilities := []PythonVulnerability{
+		{Name: "critical-vuln", Severity: "critical"},
+		{Name: "high-vuln", Severity: "HIGH"},
+		{Name: "medium-vuln", Severity: "medium"},
+		{Name: "low-vuln", Severity: "low"},
+		{Name: "info-vuln", Severity: "info"},
+	}
+
+	tests := []struct {
+		name        string
+		minSeverity Severity
+		expected    int
+	}{
+		{
+			name:        "filter by critical",
+			minSeverity: SeverityCritical,
+			expected:    1,
+		},
+		{
+			name:        "filter by high",
+			minSeverity: SeverityHigh,
+			expected:    2,
+		},
+		{
+			name:        "filter by moderate",
+			minSeverity: SeverityModerate,
+			expected:    3,
+		},
+		{
+			name:        "filter by low",
+			minSeverity: SeverityLow,
+			expected:    4,
+		},
+		{
+			name:        "filter by info",
+			minSeverity: SeverityInfo,
+			expected:    5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filtered := FilterBySeverity(vulnerabilities, tt.minSeverity)
+			if len(filtered) != tt.expected {
+				t.Errorf("FilterBySeverity() returned %d vulnerabilities, expected %d", len(filtered), tt.expected)
+			}
+		})
+	}
+}
+
+func TestFilterBySeverityGo(t *testing.T) {
+	vulnerabilities := []GoVulnerability{
+		{Module: "critical-vuln", Severity: "critical"},
+		{Module: "high-vuln", Severity: "high"},
+		{Module: "moderate-vuln", Severity: "moderate"},
+		{Module: "low-vuln", Severity: "low"},
+		{Module: "unknown-vuln", Severity: ""}, // Unrecognized levels rank as high
+	}
+
+	tests := []struct {
+		name        string
+		minSeverity Severity
+		expected    int
+	}{
+		{
+			name:        "filter by critical",
+			minSeverity: SeverityCritical,
+			expected:    1,
+		},
+		{
+			name:        "filter by high",
+			minSeverity: SeverityHigh,
+			expected:    3,
+		},
+		{
+			name:        "filter by moderate",
+			minSeverity: SeverityModerate,
+			expected:    4,
+		},
+		{
+			name:        "filter by low",
+			minSeverity: SeverityLow,
+			expected:    5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filtered := FilterBySeverity(vulnerabilities, tt.minSeverity)
+			if len(filtered) != tt.expected {
+				t.Errorf("FilterBySeverity() returned %d vulnerabilities, expected %d", len(filtered), tt.expected)
+			}
+		})
+	}
+}
+
+func TestFilterBySeverityMaven(t *testing.T) {
+	vulnerabilities := []MavenVulnerability{
+		{ArtifactID: "critical-vuln", Severity: "critical"},
+		{ArtifactID: "high-vuln", Severity: "high"},
+		{ArtifactID: "moderate-vuln", Severity: "moderate"},
+		{ArtifactID: "low-vuln", Severity: "low"},
+	}
+
+	tests := []struct {
+		name        string
+		minSeverity Severity
+		expected    int
+	}{
+		{
+			name:        "filter by critical",
+			minSeverity: SeverityCritical,
+			expected:    1,
+		},
+		{
+			name:        "filter by high",
+			minSeverity: SeverityHigh,
+			expected:    2,
+		},
+		{
+			name:        "filter by moderate",
+			minSeverity: SeverityModerate,
+			expected:    3,
+		},
+		{
+			name:        "filter by low",
+			minSeverity: SeverityLow,
+			expected:    4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filtered := FilterBySeverity(vulnerabilities, tt.minSeverity)
+			if len(filtered) != tt.expected {
+				t.Errorf("FilterBySeverity() returned %d vulnerabilities, expected %d", len(filtered), tt.expected)
+			}
+		})
+	}
+}
+
+func TestFilterBySeverityEveryEcosystem(t *testing.T) {
+	// Each ecosystem keeps only its high finding at --severity high
+	tests := []struct {
+		name     string
+		filtered int
+	}{
+		{"swift", len(FilterBySeverity([]SwiftVulnerability{{Severity: "high"}, {Severity: "low"}}, SeverityHigh))},
+		{"runtime", len(FilterBySeverity([]RuntimeVulnerability{{Severity: "high"}, {Severity: "moderate"}}, SeverityHigh))},
+		{"sbom", len(FilterBySeverity([]SBOMVulnerability{{Severity: "high"}, {Severity: "low"}}, SeverityHigh))},
+		{"custom", len(FilterBySeverity([]CustomVulnerability{{Severity: "high"}, {Severity: "moderate"}}, SeverityHigh))},
+		{"cargo", len(FilterBySeverity([]CargoVulnerability{{Severity: "high"}, {Severity: "low"}}, SeverityHigh))},
+		{"ruby", len(FilterBySeverity([]RubyVulnerability{{Severity: "high"}, {Severity: "moderate"}}, SeverityHigh))},
+		{"composer", len(FilterBySeverity([]ComposerVulnerability{{Severity: "high"}, {Severity: "low"}}, SeverityHigh))},
+	}
+
+	for _, tt := range tests {
+		if tt.filtered != 1 {
+			t.Errorf("FilterBySeverity() kept %d %s vulnerabilities, expected 1", tt.filtered, tt.name)
+		}
+	}
+}
+
+func TestFindingsAreCountedBySeverity(t *testing.T) {
+	// Info findings count as info, unknown severities as high, medium as moderate
+	summary := VulnerabilitySummary{}
+	for _, finding := range []Finding{
+		GoVulnerability{Severity: "info", IsDirect: true},
+		PythonVulnerability{Severity: "medium"},
+		CargoVulnerability{Severity: "unknown"},
+		RuntimeVulnerability{Severity: "critical"},
+		SBOMVulnerability{Severity: "low"},
+	} {
+		finding.countIn(&summary)
+	}
+
+	expected := VulnerabilitySummary{Critical: 1, High: 1, Moderate: 1, Low: 1, Info: 1, Total: 5, Direct: 2, Transitive: 2}
+	if summary != expected {
+		t.Errorf("summary = %+v, expected %+v", summary, expected)
+	}
+}
+
+func TestFilterFixableEveryEcosystem(t *testing.T) {
+	// Each result keeps only its finding with a fix, and Go drops the
+	// recommendation for the module without one
+	goResult := &GoAuditResult{
+		Vulnerabilities: []GoVulnerability{{Module: "fixed", Severity: "high", FixVersions: []string{"1.2.0"}}, {Module: "unfixed", Severity: "low"}},
+		Recommendations: []FixRecommendation{{Package: "fixed", Kind: FixUpgradeDirect}, {Package: "unfixed", Kind: FixNone}},
+	}
+	results := map[string]Result{
+		"go":      goResult,
+		"cargo":   &CargoAuditResult{Vulnerabilities: []CargoVulnerability{{Severity: "high", FixVersions: []string{"1.0.1"}}, {Severity: "low"}}},
+		"runtime": &RuntimeAuditResult{Vulnerabilities: []RuntimeVulnerability{{Severity: "high", FixVersions: []string{"20.11.1"}}, {Severity: "moderate"}}},
+		"sbom":    &SBOMAuditResult{Vulnerabilities: []SBOMVulnerability{{Severity: "high", FixVersions: []string{"2.0.0"}}, {Severity: "low"}}},
+	}
+
+	for name, result := range results {
+		if hidden := FilterFixable(result); hidden != 1 {
+			t.Errorf("FilterFixable(%s) = %d, expected 1", name, hidden)
+		}
+	}
+	if len(goResult.Recommendations) != 1 || goResult.Recommendations[0].Package != "fixed" {
+		t.Errorf("Go recommendations = %+v, expected only fixed", goResult.Recommendations)
+	}
+	if goResult.Summary != (VulnerabilitySummary{High: 1, Total: 1, Transitive: 1}) {
+		t.Errorf("Go summary = %+v, expected one transitive high", goResult.Summary)
+	}
+}
+
+func TestExcludeInfo(t *testing.T) {
+	result := &AuditResult{
+		Vulnerabilities: []Vulnerability{
+			{Name: "high-vuln", Severity: SeverityHigh, IsDirect: true},
+			{Name: "info-direct", Severity: SeverityInfo, IsDirect: true},
+			{Name: "info-transitive", Severity: SeverityInfo},
+		},
+		Summary: VulnerabilitySummary{Info: 2, High: 1, Total: 3, Direct: 2, Transitive: 1},
+	}
+
+	if dropped := result.ExcludeInfo(); dropped != 2 {
+		t.Errorf("ExcludeInfo() = %d, expected 2", dropped)
+	}
+	if len(result.Vulnerabilities) != 1 || result.Vulnerabilities[0].Name != "high-vuln" {
+		t.Errorf("ExcludeInfo() kept %v, expected only high-vuln", result.Vulnerabilities)
+	}
+
+	expected := VulnerabilitySummary{High: 1, Total: 1, Direct: 1}
+	if result.Summary != expected {
+		t.Errorf("ExcludeInfo() summary = %+v, expected %+v", result.Summary, expected)
+	}
+}
+
 func TestGetSeverityColor(t *testing.T) {
 	tests := []struct {
 		severity Severity
@@ -258,6 +493,94 @@ func TestRunAuditInvalidPath(t *testing.T) {
 	}
 }
 
+// fakeNpm puts an npm on PATH that runs script instead of auditing
+func fakeNpm(t *testing.T, script string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake npm is a shell script")
+	}
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "npm"), []byte("#!/bin/sh\n"+script), 0755); err != nil {
+		t.Fatalf("Failed to write fake npm: %v", err)
+	}
+	t.Setenv("PATH", bin)
+}
+
+func TestRunAuditReportsNpmStderr(t *testing.T) {
+	// npm audit fails with its message on stderr and nothing on stdout
+	fakeNpm(t, `echo "npm ERR! code ENOLOCK" >&2
+echo "npm ERR! audit This command requires an existing lockfile." >&2
+echo "npm ERR! A complete log of this run can be found in: /tmp/debug.log" >&2
+exit 1
+`)
+
+	tmpDir := t.TempDir()
+	packageJSON := filepath.Join(tmpDir, "package.json")
+	if err := os.WriteFile(packageJSON, []byte(`{"name": "app", "dependencies": {}}`), 0644); err != nil {
+		t.Fatalf("Failed to create test package.json: %v", err)
+	}
+
+	result := NewRunner(10*time.Second, false).RunAudit(packageJSON)
+	if result.Error == nil {
+		t.Fatal("RunAudit() expected an error for empty npm audit output")
+	}
+	message := result.Error.Error()
+	for _, want := range []string{"code ENOLOCK", "This command requires an existing lockfile.", npmLockfileHint} {
+		if !strings.Contains(message, want) {
+			t.Errorf("RunAudit() error = %q, expected it to contain %q", message, want)
+		}
+	}
+	if strings.Contains(message, "complete log") {
+		t.Errorf("RunAudit() error = %q, expected npm's log pointer left out", message)
+	}
+}
+
+func TestNpmAuditFailure(t *testing.T) {
+	tests := []struct {
+		name   string
+		stdout string
+		stderr string
+		want   string // Empty when stdout is a report
+	}{
+		{
+			name:   "report",
+			stdout: `{"auditReportVersion": 2, "vulnerabilities": {}}`,
+			stderr: "npm WARN config production Use --omit=dev instead.",
+		},
+		{
+			name:   "error object without a lockfile",
+			stdout: `{"error": {"code": "ENOLOCK", "summary": "This command requires an existing lockfile.", "detail": "Try creating one first with: npm i --package-lock-only"}}`,
+			want:   "npm audit failed: This command requires an existing lockfile.; " + npmLockfileHint,
+		},
+		{
+			name:   "error object",
+			stdout: `{"error": {"code": "ENOTFOUND", "summary": "request to https://registry.npmjs.org failed"}}`,
+			want:   "npm audit failed: request to https://registry.npmjs.org failed",
+		},
+		{
+			name:   "empty stdout",
+			stderr: "npm WARN deprecated\nnpm ERR! network request failed\n",
+			want:   "npm audit failed: network request failed",
+		},
+		{
+			name: "no output at all",
+			want: "npm audit failed: no output",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := npmAuditFailure([]byte(tt.stdout), tt.stderr)
+			switch {
+			case tt.want == "" && err != nil:
+				t.Errorf("npmAuditFailure() = %v, expected nil", err)
+			case tt.want != "" && (err == nil || err.Error() != tt.want):
+				t.Errorf("npmAuditFailure() = %v, expected %q", err, tt.want)
+			}
+		})
+	}
+}
+
 func TestJSONParsing(t *testing.T) {
 	// Test that our structs correctly parse npm audit JSON output
 	mockAuditJSON := `{
@@ -384,4 +707,145 @@ func TestJSONParsingWithObjectFixAvailable(t *testing.T) {
 	if len(vuln.Via) == 0 {
 		t.Error("Via array is empty, expected at least one element")
 	}
+
+	expected := FixAvailable{Available: true, Name: "test-package", Version: "3.0.0", IsSemVerMajor: true}
+	if vuln.FixAvailable != expected {
+		t.Errorf("FixAvailable = %+v, expected %+v", vuln.FixAvailable, expected)
+	}
+}
+
+func TestFixAvailableForms(t *testing.T) {
+	tests := []struct {
+		name     string
+		json     string
+		expected FixAvailable
+	}{
+		{"fixable in range", `true`, FixAvailable{Available: true}},
+		{"no fix", `false`, FixAvailable{}},
+		{"upgrade", `{"name":"jest","version":"29.0.0","isSemVerMajor":true}`, FixAvailable{Available: true, Name: "jest", Version: "29.0.0", IsSemVerMajor: true}},
+		// Reports write the object form even without a fix
+		{"report without fix", `{"available":false}`, FixAvailable{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fix FixAvailable
+			if err := json.Unmarshal([]byte(tt.json), &fix); err != nil {
+				t.Fatalf("Unmarshal(%s) unexpected error: %v", tt.json, err)
+			}
+			if fix != tt.expected {
+				t.Errorf("Unmarshal(%s) = %+v, expected %+v", tt.json, fix, tt.expected)
+			}
+
+			// What a report writes reads back the same
+			data, err := json.Marshal(fix)
+			if err != nil {
+				t.Fatalf("Marshal(%+v) unexpected error: %v", fix, err)
+			}
+			var roundTrip FixAvailable
+			if err := json.Unmarshal(data, &roundTrip); err != nil || roundTrip != fix {
+				t.Errorf("%s read back as %+v (error %v), expected %+v", data, roundTrip, err, fix)
+			}
+		})
+	}
+
+	var fix FixAvailable
+	if err := json.Unmarshal([]byte(`"soon"`), &fix); err == nil {
+		t.Error("Unmarshal(\"soon\") expected an error")
+	}
+}
+
+func TestDirectTransitiveSummary(t *testing.T) {
+	npmVulns := []Vulnerability{
+		{Name: "direct-a", Severity: SeverityHigh, IsDirect: true},
+		{Name: "transitive-a", Severity: SeverityHigh, IsDirect: false},
+		{Name: "transitive-b", Severity: SeverityLow, IsDirect: false},
+		{Name: "transitive-c", Severity: SeverityModerate, IsDirect: false},
+	}
+
+	direct, transitive := countDirectness(npmVulns)
+	if direct != 1 || transitive != 3 {
+		t.Errorf("countDirectness() = (%d, %d), expected (1, 3)", direct, transitive)
+	}
+
+	npmSummary := VulnerabilitySummary{Total: 4, Direct: direct, Transitive: transitive}
+
+	// Go findings: one direct module, one marked "// indirect"
+	goSummary := VulnerabilitySummary{}
+	for _, vuln := range []GoVulnerability{
+		{Module: "github.com/direct/mod", IsDirect: true},
+		{Module: "github.com/indirect/mod", IsDirect: false},
+	} {
+		goSummary.Total++
+		goSummary.AddDirectness(vuln.IsDirect)
+	}
+
+	total := VulnerabilitySummary{}
+	total.Add(npmSummary)
+	total.Add(goSummary)
+
+	if total.Total != 6 {
+		t.Errorf("Total = %d, expected 6", total.Total)
+	}
+	if total.Direct != 2 {
+		t.Errorf("Direct = %d, expected 2", total.Direct)
+	}
+	if total.Transitive != 4 {
+		t.Errorf("Transitive = %d, expected 4", total.Transitive)
+	}
+	if total.Direct+total.Transitive != total.Total {
+		t.Errorf("Direct + Transitive = %d, expected to equal Total %d", total.Direct+total.Transitive, total.Total)
+	}
+
+	if formatted := total.FormatSummary(); !strings.Contains(formatted, "Direct: 2, Transitive: 4") {
+		t.Errorf("FormatSummary() missing directness breakdown. Got: %s", formatted)
+	}
+}
+
+func TestParseGoModIndirect(t *testing.T) {
+	tmpDir := t.TempDir()
+	goMod := filepath.Join(tmpDir, "go.mod")
+	content := `module example.com/test
+
+go 1.21
+
+require github.com/direct/mod v1.2.3
+require github.com/indirect/mod v0.1.0 // indirect
+`
+	if err := os.WriteFile(goMod, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write go.mod: %v", err)
+	}
+
+	modules, err := ParseGoMod(goMod)
+	if err != nil {
+		t.Fatalf("ParseGoMod() unexpected error: %v", err)
+	}
+	if len(modules) != 2 {
+		t.Fatalf("ParseGoMod() returned %d modules, expected 2", len(modules))
+	}
+	if modules[0].Indirect {
+		t.Errorf("%s should be direct", modules[0].Path)
+	}
+	if !modules[1].Indirect {
+		t.Errorf("%s should be indirect", modules[1].Path)
+	}
+}
+
+func TestRegisterParserRejectsTakenTypes(t *testing.T) {
+	parser := func(path string) ([]ManifestPackage, error) { return nil, nil }
+	registerTestParser(t, "deps.taken", parser)
+
+	for _, manifestType := range []string{"requirements.txt", "pom.xml", "deps.taken"} {
+		if err := RegisterParser(manifestType, parser); err == nil {
+			t.Errorf("RegisterParser(%q) expected error, got nil", manifestType)
+		}
+	}
+
+	// A custom parser doesn't stand in for a built-in one, or the reverse
+	if _, ok := lookupParser[ParserFunc]("requirements.txt"); ok {
+		t.Error("lookupParser[ParserFunc](requirements.txt) found a parser, expected only the built-in one")
+	}
+	if _, ok := lookupParser[pythonParser]("deps.taken"); ok {
+		t.Error("lookupParser[pythonParser](deps.taken) found a parser, expected only the custom one")
+	}
 }
@@ -0,0 +1,141 @@
+package audit
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/brandonapol/snoop/osv"
+)
+
+// CargoVulnerability represents a security vulnerability in a Rust crate
+type CargoVulnerability struct {
+	Name        string    `json:"name"`
+	Version     string    `json:"version"`
+	ID          string    `json:"id"`
+	FixVersions []string  `json:"fix_versions"`
+	Description string    `json:"description"`
+	Aliases     []string  `json:"aliases"`
+	Severity    string    `json:"severity"`
+	Published   time.Time `json:"published,omitzero"`
+	Modified    time.Time `json:"modified,omitzero"`
+	IsDirect    bool      `json:"is_direct"`
+}
+
+// CargoAuditResult contains the results of running Cargo vulnerability check
+type CargoAuditResult struct {
+	ManifestPath     string
+	ManifestType     string
+	Vulnerabilities  []CargoVulnerability
+	Summary          VulnerabilitySummary
+	PackagesScanned  int
+	Dependencies     []CargoPackage // Every pinned crate, for SBOM output
+	Warnings         []string       // Non-fatal problems such as failed queries
+	Unverified       bool           // Every OSV query failed, so no findings doesn't mean clean
+	WithdrawnSkipped int            // Advisories OSV has withdrawn, left out of the findings
+	Error            error
+}
+
+// RunCargoAudit checks Rust crates for vulnerabilities using OSV API. Cargo.lock
+// gives the exact version of every crate in the dependency tree, so only it is
+// audited; a Cargo.toml is covered by the Cargo.lock of its workspace.
+func (r *Runner) RunCargoAudit(manifestPath string, manifestType string) *CargoAuditResult {
+	result := &CargoAuditResult{
+		ManifestPath: manifestPath,
+		ManifestType: manifestType,
+	}
+
+	// Only parse Cargo.lock files
+	if manifestType != "Cargo.lock" {
+		return result
+	}
+
+	packages, err := ParseCargoLock(manifestPath)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+	packages = withoutExcluded(r, osv.CratesIO, packages)
+
+	if len(packages) == 0 {
+		// No packages found, not an error
+		return result
+	}
+
+	result.PackagesScanned = len(packages)
+	result.Dependencies = packages
+
+	if r.verbose {
+		fmt.Fprintf(os.Stderr, "Found %d Cargo crates in %s\n", len(packages), filepath.Base(manifestPath))
+	}
+
+	osvPkgs := make([]osv.Package, 0, len(packages))
+	for _, pkg := range packages {
+		osvPkgs = append(osvPkgs, osv.Package{
+			Name:      pkg.Name,
+			Version:   pkg.Version,
+			Ecosystem: osv.CratesIO,
+		})
+	}
+	responses := r.queryPackages(osvPkgs)
+	result.Unverified = allQueriesFailed(responses)
+
+	for i, pkg := range packages {
+		if r.verbose {
+			fmt.Fprintf(os.Stderr, "  Checking %s@%s...\n", pkg.Name, pkg.Version)
+		}
+
+		response, err := responses[i].Response, responses[i].Err
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("failed to query %s: %v", pkg.Name, err))
+			if r.verbose {
+				fmt.Fprintf(os.Stderr, "    Warning: Failed to query %s: %v\n", pkg.Name, err)
+			}
+			continue
+		}
+
+		// Withdrawn advisories no longer describe a vulnerability
+		var withdrawn int
+		response.Vulns, withdrawn = dropWithdrawn(response.Vulns)
+		result.WithdrawnSkipped += withdrawn
+
+		// Re-check matches against semver ranges locally
+		response.Vulns = r.filterAffected(osvPkgs[i], response.Vulns)
+
+		// Count advisories that alias one another as a single finding
+		response.Vulns = mergeAliasedAdvisories(response.Vulns)
+
+		if r.verbose && len(response.Vulns) > 0 {
+			fmt.Fprintf(os.Stderr, "    Found %d vulnerability(ies)\n", len(response.Vulns))
+			printAdvisories(response.Vulns)
+		}
+
+		for _, vuln := range response.Vulns {
+			cargoVuln := CargoVulnerability{
+				Name:        pkg.Name,
+				Version:     pkg.Version,
+				ID:          vuln.ID,
+				FixVersions: extractFixVersions(vuln),
+				Description: vuln.Summary,
+				Aliases:     vuln.Aliases,
+				Severity:    vuln.GetSeverityLevel(),
+				Published:   vuln.PublishedTime(),
+				Modified:    vuln.ModifiedTime(),
+				IsDirect:    pkg.IsDirect,
+			}
+
+			result.Vulnerabilities = append(result.Vulnerabilities, cargoVuln)
+
+			// Count it in the summary by severity and directness
+			cargoVuln.countIn(&result.Summary)
+		}
+	}
+
+	return result
+}
+
+// HasVulnerabilities returns true if the Cargo audit result contains vulnerabilities
+func (r *CargoAuditResult) HasVulnerabilities() bool {
+	return r.Summary.Total > 0
+}
@@ -0,0 +1,68 @@
+package audit
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// CargoPackage represents a crate pinned in a Cargo.lock
+type CargoPackage struct {
+	Name     string
+	Version  string
+	IsDirect bool
+}
+
+// cargoLockFile is the part of a Cargo.lock an audit needs
+type cargoLockFile struct {
+	Packages []struct {
+		Name         string   `toml:"name"`
+		Version      string   `toml:"version"`
+		Source       string   `toml:"source"`
+		Dependencies []string `toml:"dependencies"`
+	} `toml:"package"`
+}
+
+// ParseCargoLock returns every crate a Cargo.lock pins from a registry, in
+// the order Cargo writes them. Packages without a source are the crates of
+// the workspace itself, and git dependencies aren't releases OSV knows about,
+// so both are skipped. A crate is direct when a workspace crate depends on it;
+// a lockfile without workspace crates marks every crate as direct.
+func ParseCargoLock(path string) ([]CargoPackage, error) {
+	var lock cargoLockFile
+	if _, err := toml.DecodeFile(path, &lock); err != nil {
+		return nil, fmt.Errorf("failed to parse Cargo.lock: %w", err)
+	}
+
+	// Dependencies are listed as "name", or "name version" when several
+	// versions of the crate are locked
+	direct := make(map[string]bool)
+	hasWorkspace := false
+	for _, pkg := range lock.Packages {
+		if pkg.Source != "" {
+			continue
+		}
+		hasWorkspace = true
+		for _, dep := range pkg.Dependencies {
+			fields := strings.Fields(dep)
+			switch len(fields) {
+			case 0:
+			case 1:
+				direct[fields[0]] = true
+			default:
+				direct[fields[0]+" "+fields[1]] = true
+			}
+		}
+	}
+
+	var packages []CargoPackage
+	for _, pkg := range lock.Packages {
+		if pkg.Name == "" || pkg.Version == "" || pkg.Source == "" || strings.HasPrefix(pkg.Source, "git+") {
+			continue
+		}
+		isDirect := !hasWorkspace || direct[pkg.Name+" "+pkg.Version] || direct[pkg.Name]
+		packages = append(packages, CargoPackage{Name: pkg.Name, Version: pkg.Version, IsDirect: isDirect})
+	}
+	return packages, nil
+}
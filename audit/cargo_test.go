@@ -0,0 +1,174 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/brandonapol/snoop/osv"
+)
+
+// cargoLockFixture is a Cargo.lock for a workspace crate that depends on
+// regex directly and on two versions of memchr through it
+const cargoLockFixture = `# This file is automatically @generated by Cargo.
+# It is not intended for manual editing.
+version = 3
+
+[[package]]
+name = "app"
+version = "0.1.0"
+dependencies = [
+ "local-utils",
+ "memchr 2.5.0",
+ "regex",
+ "tokio-fork",
+]
+
+[[package]]
+name = "local-utils"
+version = "0.1.0"
+
+[[package]]
+name = "memchr"
+version = "2.5.0"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+checksum = "2dffe52ecf27772e601905b7522cb4ef790d2cc203488bbd0e2fe85fcb74566d"
+
+[[package]]
+name = "memchr"
+version = "2.4.1"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+checksum = "308cc39be01b73d0d18f82a0e7b2a3df85245f84af96fdddc5d202d27e47b86a"
+
+[[package]]
+name = "regex"
+version = "1.5.4"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+checksum = "d07a8629359eb56f1e2fb1652bb04212c072a87ba68546a04065d525673ac461"
+dependencies = [
+ "memchr 2.4.1",
+]
+
+[[package]]
+name = "tokio-fork"
+version = "1.0.0"
+source = "git+https://github.com/example/tokio-fork?branch=main#abc123"
+`
+
+func TestParseCargoLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Cargo.lock")
+	if err := os.WriteFile(path, []byte(cargoLockFixture), 0644); err != nil {
+		t.Fatalf("Failed to write Cargo.lock: %v", err)
+	}
+
+	packages, err := ParseCargoLock(path)
+	if err != nil {
+		t.Fatalf("ParseCargoLock() error = %v", err)
+	}
+
+	expected := []CargoPackage{
+		{Name: "memchr", Version: "2.5.0", IsDirect: true},
+		{Name: "memchr", Version: "2.4.1", IsDirect: false},
+		{Name: "regex", Version: "1.5.4", IsDirect: true},
+	}
+	if !reflect.DeepEqual(packages, expected) {
+		t.Errorf("ParseCargoLock() = %+v, expected %+v", packages, expected)
+	}
+}
+
+func TestParseCargoLockFixture(t *testing.T) {
+	packages, err := ParseCargoLock(filepath.Join("..", "test-project-rust", "Cargo.lock"))
+	if err != nil {
+		t.Fatalf("ParseCargoLock() error = %v", err)
+	}
+
+	direct := 0
+	for _, pkg := range packages {
+		if pkg.IsDirect {
+			direct++
+		}
+	}
+	if len(packages) != 4 || direct != 2 {
+		t.Errorf("ParseCargoLock() = %+v, expected 4 crates, 2 of them direct", packages)
+	}
+}
+
+func TestParseCargoLockWithoutWorkspaceCrates(t *testing.T) {
+	content := `version = 4
+
+[[package]]
+name = "serde"
+version = "1.0.130"
+source = "sparse+https://index.crates.io/"
+`
+	path := filepath.Join(t.TempDir(), "Cargo.lock")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write Cargo.lock: %v", err)
+	}
+
+	packages, err := ParseCargoLock(path)
+	if err != nil {
+		t.Fatalf("ParseCargoLock() error = %v", err)
+	}
+	expected := []CargoPackage{{Name: "serde", Version: "1.0.130", IsDirect: true}}
+	if !reflect.DeepEqual(packages, expected) {
+		t.Errorf("ParseCargoLock() = %+v, expected %+v", packages, expected)
+	}
+}
+
+func TestParseCargoLockInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Cargo.lock")
+	if err := os.WriteFile(path, []byte("[[package]\nname = "), 0644); err != nil {
+		t.Fatalf("Failed to write Cargo.lock: %v", err)
+	}
+	if _, err := ParseCargoLock(path); err == nil {
+		t.Error("ParseCargoLock() expected error for invalid TOML")
+	}
+}
+
+func TestRunCargoAuditQueriesLockedVersions(t *testing.T) {
+	server := newMockOSVServer(t, func(request osv.QueryRequest) []osv.Vulnerability {
+		if request.Package.Ecosystem != osv.CratesIO {
+			t.Errorf("query ecosystem = %s, expected %s", request.Package.Ecosystem, osv.CratesIO)
+		}
+		if request.Package.Name == "memchr" && request.Package.Version == "2.4.1" {
+			return []osv.Vulnerability{{ID: "RUSTSEC-2099-0001", Summary: "out of bounds read"}}
+		}
+		return nil
+	})
+
+	lockPath := filepath.Join(t.TempDir(), "Cargo.lock")
+	if err := os.WriteFile(lockPath, []byte(cargoLockFixture), 0644); err != nil {
+		t.Fatalf("Failed to write Cargo.lock: %v", err)
+	}
+
+	runner := NewRunner(0, false)
+	runner.SetOSVClient(osv.NewClientWithURL(server.URL))
+	result := runner.RunCargoAudit(lockPath, "Cargo.lock")
+
+	if result.Error != nil {
+		t.Fatalf("RunCargoAudit() error = %v", result.Error)
+	}
+	if result.PackagesScanned != 3 {
+		t.Errorf("PackagesScanned = %d, expected 3", result.PackagesScanned)
+	}
+	if len(result.Vulnerabilities) != 1 || result.Vulnerabilities[0].ID != "RUSTSEC-2099-0001" {
+		t.Fatalf("Vulnerabilities = %+v, expected RUSTSEC-2099-0001", result.Vulnerabilities)
+	}
+	// memchr 2.4.1 is only pulled in by regex
+	if result.Vulnerabilities[0].IsDirect {
+		t.Errorf("memchr 2.4.1 finding IsDirect = true, expected false")
+	}
+	if result.Summary.Total != 1 || result.Summary.Transitive != 1 {
+		t.Errorf("Summary = %+v, expected one transitive finding", result.Summary)
+	}
+}
+
+func TestRunCargoAuditSkipsCargoToml(t *testing.T) {
+	runner := NewRunner(0, false)
+	result := runner.RunCargoAudit(filepath.Join(t.TempDir(), "Cargo.toml"), "Cargo.toml")
+	if result.Error != nil || result.PackagesScanned != 0 {
+		t.Errorf("RunCargoAudit(Cargo.toml) = %+v, expected nothing audited", result)
+	}
+}
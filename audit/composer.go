@@ -0,0 +1,140 @@
+package audit
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/brandonapol/snoop/osv"
+)
+
+// ComposerVulnerability represents a security vulnerability in a PHP package
+type ComposerVulnerability struct {
+	Name        string    `json:"name"`
+	Version     string    `json:"version"`
+	ID          string    `json:"id"`
+	FixVersions []string  `json:"fix_versions"`
+	Description string    `json:"description"`
+	Aliases     []string  `json:"aliases"`
+	Severity    string    `json:"severity"`
+	Published   time.Time `json:"published,omitzero"`
+	Modified    time.Time `json:"modified,omitzero"`
+	IsDirect    bool      `json:"is_direct"`
+}
+
+// ComposerAuditResult contains the results of running PHP vulnerability check
+type ComposerAuditResult struct {
+	ManifestPath     string
+	ManifestType     string
+	Vulnerabilities  []ComposerVulnerability
+	Summary          VulnerabilitySummary
+	PackagesScanned  int
+	Dependencies     []ComposerPackage // Every locked package, for SBOM output
+	Warnings         []string          // Non-fatal problems such as failed queries
+	Unverified       bool              // Every OSV query failed, so no findings doesn't mean clean
+	WithdrawnSkipped int               // Advisories OSV has withdrawn, left out of the findings
+	Error            error
+}
+
+// RunComposerAudit checks PHP packages for vulnerabilities using OSV API.
+// composer.lock pins the exact version of every installed package.
+func (r *Runner) RunComposerAudit(manifestPath string, manifestType string) *ComposerAuditResult {
+	result := &ComposerAuditResult{
+		ManifestPath: manifestPath,
+		ManifestType: manifestType,
+	}
+
+	// Only parse composer.lock files
+	if manifestType != "composer.lock" {
+		return result
+	}
+
+	packages, err := ParseComposerLock(manifestPath)
+	if err != nil {
+		result.Error = fmt.Errorf("failed to parse composer.lock: %w", err)
+		return result
+	}
+	packages = withoutExcluded(r, osv.Packagist, packages)
+
+	if len(packages) == 0 {
+		// No packages found, not an error
+		return result
+	}
+
+	result.PackagesScanned = len(packages)
+	result.Dependencies = packages
+
+	if r.verbose {
+		fmt.Fprintf(os.Stderr, "Found %d Composer packages in %s\n", len(packages), filepath.Base(manifestPath))
+	}
+
+	osvPkgs := make([]osv.Package, 0, len(packages))
+	for _, pkg := range packages {
+		osvPkgs = append(osvPkgs, osv.Package{
+			Name:      pkg.Name,
+			Version:   pkg.Version,
+			Ecosystem: osv.Packagist,
+		})
+	}
+	responses := r.queryPackages(osvPkgs)
+	result.Unverified = allQueriesFailed(responses)
+
+	for i, pkg := range packages {
+		if r.verbose {
+			fmt.Fprintf(os.Stderr, "  Checking %s@%s...\n", pkg.Name, pkg.Version)
+		}
+
+		response, err := responses[i].Response, responses[i].Err
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("failed to query %s: %v", pkg.Name, err))
+			if r.verbose {
+				fmt.Fprintf(os.Stderr, "    Warning: Failed to query %s: %v\n", pkg.Name, err)
+			}
+			continue
+		}
+
+		// Withdrawn advisories no longer describe a vulnerability
+		var withdrawn int
+		response.Vulns, withdrawn = dropWithdrawn(response.Vulns)
+		result.WithdrawnSkipped += withdrawn
+
+		// Re-check matches against semver ranges locally
+		response.Vulns = r.filterAffected(osvPkgs[i], response.Vulns)
+
+		// Count advisories that alias one another as a single finding
+		response.Vulns = mergeAliasedAdvisories(response.Vulns)
+
+		if r.verbose && len(response.Vulns) > 0 {
+			fmt.Fprintf(os.Stderr, "    Found %d vulnerability(ies)\n", len(response.Vulns))
+			printAdvisories(response.Vulns)
+		}
+
+		for _, vuln := range response.Vulns {
+			composerVuln := ComposerVulnerability{
+				Name:        pkg.Name,
+				Version:     pkg.Version,
+				ID:          vuln.ID,
+				FixVersions: extractFixVersions(vuln),
+				Description: vuln.Summary,
+				Aliases:     vuln.Aliases,
+				Severity:    vuln.GetSeverityLevel(),
+				Published:   vuln.PublishedTime(),
+				Modified:    vuln.ModifiedTime(),
+				IsDirect:    pkg.IsDirect,
+			}
+
+			result.Vulnerabilities = append(result.Vulnerabilities, composerVuln)
+
+			// Count it in the summary by severity and directness
+			composerVuln.countIn(&result.Summary)
+		}
+	}
+
+	return result
+}
+
+// HasVulnerabilities returns true if the Composer audit result contains vulnerabilities
+func (r *ComposerAuditResult) HasVulnerabilities() bool {
+	return r.Summary.Total > 0
+}
@@ -0,0 +1,102 @@
+package audit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ComposerPackage represents a package pinned in a composer.lock
+type ComposerPackage struct {
+	Name     string
+	Version  string
+	IsDirect bool // Required by the composer.json next to the lock file
+}
+
+// composerLock holds the locked packages of a composer.lock
+type composerLock struct {
+	Packages    []composerLockPackage `json:"packages"`
+	PackagesDev []composerLockPackage `json:"packages-dev"`
+}
+
+// composerLockPackage is one entry of a composer.lock package list
+type composerLockPackage struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// composerManifest holds the requirements of a composer.json
+type composerManifest struct {
+	Require    map[string]string `json:"require"`
+	RequireDev map[string]string `json:"require-dev"`
+}
+
+// ParseComposerLock returns every package a composer.lock pins, from both
+// packages and packages-dev, in the order Composer writes them. The "v" some
+// packages tag their releases with is dropped from the version, and packages
+// installed from a branch, such as dev-main, are skipped since they aren't
+// releases on Packagist. A package is direct when the composer.json next to
+// the lock file requires it; without that file every package counts as
+// direct.
+func ParseComposerLock(path string) ([]ComposerPackage, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	var lock composerLock
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	direct, err := composerRequirements(filepath.Join(filepath.Dir(path), "composer.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var packages []ComposerPackage
+	seen := make(map[string]bool)
+	for _, pkg := range append(lock.Packages, lock.PackagesDev...) {
+		version := strings.TrimPrefix(pkg.Version, "v")
+		if pkg.Name == "" || version == "" || strings.HasPrefix(version, "dev-") || strings.HasSuffix(version, "-dev") {
+			continue
+		}
+		name := strings.ToLower(pkg.Name)
+		key := name + "@" + version
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		packages = append(packages, ComposerPackage{Name: name, Version: version, IsDirect: direct == nil || direct[name]})
+	}
+	return packages, nil
+}
+
+// composerRequirements returns the packages a composer.json requires, keyed
+// by lowercase name, or nil when there's no composer.json
+func composerRequirements(path string) (map[string]bool, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read composer.json: %w", err)
+	}
+
+	var manifest composerManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse composer.json: %w", err)
+	}
+
+	required := make(map[string]bool)
+	for _, requirements := range []map[string]string{manifest.Require, manifest.RequireDev} {
+		for name := range requirements {
+			required[strings.ToLower(name)] = true
+		}
+	}
+	return required, nil
+}
@@ -0,0 +1,140 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/brandonapol/snoop/osv"
+)
+
+// composerLockFixture is a composer.lock with a tagged release, a package
+// installed from a branch, a dev package, and a package pulled in by another
+const composerLockFixture = `{
+    "packages": [
+        {"name": "guzzlehttp/guzzle", "version": "7.4.1"},
+        {"name": "guzzlehttp/psr7", "version": "2.1.0"},
+        {"name": "Twig/Twig", "version": "v3.4.2"},
+        {"name": "acme/internal", "version": "dev-main"}
+    ],
+    "packages-dev": [
+        {"name": "phpunit/php-timer", "version": "5.0.3"}
+    ]
+}`
+
+// composerJSONFixture requires the packages of composerLockFixture that
+// aren't pulled in by others
+const composerJSONFixture = `{
+    "require": {"php": ">=7.4", "guzzlehttp/guzzle": "^7.4", "twig/twig": "^3.4", "acme/internal": "dev-main"},
+    "require-dev": {"phpunit/php-timer": "^5.0"}
+}`
+
+// writeComposerProject writes a composer.lock, and a composer.json when
+// manifest isn't empty, and returns the lock file's path
+func writeComposerProject(t *testing.T, manifest string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "composer.lock"), []byte(composerLockFixture), 0644); err != nil {
+		t.Fatalf("Failed to write composer.lock: %v", err)
+	}
+	if manifest != "" {
+		if err := os.WriteFile(filepath.Join(dir, "composer.json"), []byte(manifest), 0644); err != nil {
+			t.Fatalf("Failed to write composer.json: %v", err)
+		}
+	}
+	return filepath.Join(dir, "composer.lock")
+}
+
+func TestParseComposerLock(t *testing.T) {
+	packages, err := ParseComposerLock(writeComposerProject(t, composerJSONFixture))
+	if err != nil {
+		t.Fatalf("ParseComposerLock() error = %v", err)
+	}
+
+	expected := []ComposerPackage{
+		{Name: "guzzlehttp/guzzle", Version: "7.4.1", IsDirect: true},
+		{Name: "guzzlehttp/psr7", Version: "2.1.0", IsDirect: false},
+		{Name: "twig/twig", Version: "3.4.2", IsDirect: true},
+		{Name: "phpunit/php-timer", Version: "5.0.3", IsDirect: true},
+	}
+	if !reflect.DeepEqual(packages, expected) {
+		t.Errorf("ParseComposerLock() = %+v, expected %+v", packages, expected)
+	}
+}
+
+func TestParseComposerLockWithoutComposerJSON(t *testing.T) {
+	packages, err := ParseComposerLock(writeComposerProject(t, ""))
+	if err != nil {
+		t.Fatalf("ParseComposerLock() error = %v", err)
+	}
+	for _, pkg := range packages {
+		if !pkg.IsDirect {
+			t.Errorf("%s IsDirect = false, expected every package direct without a composer.json", pkg.Name)
+		}
+	}
+}
+
+func TestParseComposerLockFixture(t *testing.T) {
+	packages, err := ParseComposerLock(filepath.Join("..", "test-project-php", "composer.lock"))
+	if err != nil {
+		t.Fatalf("ParseComposerLock() error = %v", err)
+	}
+
+	direct := 0
+	for _, pkg := range packages {
+		if pkg.IsDirect {
+			direct++
+		}
+	}
+	if len(packages) != 7 || direct != 3 {
+		t.Errorf("ParseComposerLock() = %+v, expected 7 packages, 3 of them direct", packages)
+	}
+}
+
+func TestParseComposerLockErrors(t *testing.T) {
+	if _, err := ParseComposerLock(filepath.Join(t.TempDir(), "composer.lock")); err == nil {
+		t.Error("ParseComposerLock() expected error for missing file")
+	}
+
+	path := filepath.Join(t.TempDir(), "composer.lock")
+	if err := os.WriteFile(path, []byte("{not json"), 0644); err != nil {
+		t.Fatalf("Failed to write composer.lock: %v", err)
+	}
+	if _, err := ParseComposerLock(path); err == nil {
+		t.Error("ParseComposerLock() expected error for invalid JSON")
+	}
+}
+
+func TestRunComposerAuditQueriesLockedVersions(t *testing.T) {
+	server := newMockOSVServer(t, func(request osv.QueryRequest) []osv.Vulnerability {
+		if request.Package.Ecosystem != osv.Packagist {
+			t.Errorf("query ecosystem = %s, expected %s", request.Package.Ecosystem, osv.Packagist)
+		}
+		if request.Package.Name == "guzzlehttp/psr7" && request.Package.Version == "2.1.0" {
+			return []osv.Vulnerability{{ID: "GHSA-q7rv-6hp3-vh96", Summary: "improper header validation"}}
+		}
+		return nil
+	})
+
+	runner := NewRunner(0, false)
+	runner.SetOSVClient(osv.NewClientWithURL(server.URL))
+	result := runner.RunComposerAudit(writeComposerProject(t, composerJSONFixture), "composer.lock")
+
+	if result.Error != nil {
+		t.Fatalf("RunComposerAudit() error = %v", result.Error)
+	}
+	if result.PackagesScanned != 4 {
+		t.Errorf("PackagesScanned = %d, expected 4", result.PackagesScanned)
+	}
+	if len(result.Vulnerabilities) != 1 || result.Vulnerabilities[0].ID != "GHSA-q7rv-6hp3-vh96" {
+		t.Fatalf("Vulnerabilities = %+v, expected GHSA-q7rv-6hp3-vh96", result.Vulnerabilities)
+	}
+	// psr7 is only pulled in by guzzle
+	if result.Vulnerabilities[0].IsDirect {
+		t.Errorf("psr7 finding IsDirect = true, expected false")
+	}
+	if result.Summary.Total != 1 || result.Summary.Transitive != 1 {
+		t.Errorf("Summary = %+v, expected one transitive finding", result.Summary)
+	}
+}
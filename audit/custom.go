@@ -0,0 +1,203 @@
+package audit
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/brandonapol/snoop/osv"
+)
+
+// ManifestPackage is a dependency read from a manifest by a registered parser
+type ManifestPackage struct {
+	Name     string
+	Version  string
+	IsDirect bool
+}
+
+// ParserFunc extracts the dependencies declared in the manifest at path
+type ParserFunc func(path string) ([]ManifestPackage, error)
+
+// parsers maps each manifest type to the function that reads it: the
+// built-in parsers behind RunPythonAudit and RunMavenAudit, and the
+// ParserFuncs library users register for RunCustomAudit
+var (
+	parsersMu sync.RWMutex
+	parsers   = make(map[string]any)
+)
+
+// RegisterParser makes RunCustomAudit read manifests of manifestType with
+// parser. Pair it with scanner.RegisterManifest so the files are detected.
+func RegisterParser(manifestType string, parser ParserFunc) error {
+	if manifestType == "" || parser == nil {
+		return fmt.Errorf("manifest type and parser are required")
+	}
+
+	parsersMu.Lock()
+	defer parsersMu.Unlock()
+
+	if existing, ok := parsers[manifestType]; ok {
+		if _, custom := existing.(ParserFunc); !custom {
+			return fmt.Errorf("%s is a built-in manifest type", manifestType)
+		}
+		return fmt.Errorf("a parser for %s is already registered", manifestType)
+	}
+	parsers[manifestType] = parser
+	return nil
+}
+
+// registerBuiltinParser adds the parser an ecosystem's audit reads
+// manifestType with
+func registerBuiltinParser[P any](manifestType string, parser P) {
+	parsersMu.Lock()
+	defer parsersMu.Unlock()
+
+	parsers[manifestType] = parser
+}
+
+// lookupParser returns the parser of type P registered for manifestType
+func lookupParser[P any](manifestType string) (P, bool) {
+	parsersMu.RLock()
+	defer parsersMu.RUnlock()
+
+	parser, ok := parsers[manifestType].(P)
+	return parser, ok
+}
+
+// CustomVulnerability represents a security vulnerability in a package read
+// by a registered parser
+type CustomVulnerability struct {
+	Name        string        `json:"name"`
+	Version     string        `json:"version"`
+	Ecosystem   osv.Ecosystem `json:"ecosystem"`
+	ID          string        `json:"id"`
+	FixVersions []string      `json:"fix_versions"`
+	Description string        `json:"description"`
+	Aliases     []string      `json:"aliases"`
+	Severity    string        `json:"severity"`
+	Published   time.Time     `json:"published,omitzero"`
+	Modified    time.Time     `json:"modified,omitzero"`
+	IsDirect    bool          `json:"is_direct"`
+}
+
+// CustomAuditResult contains the results of auditing a manifest read by a registered parser
+type CustomAuditResult struct {
+	ManifestPath     string
+	ManifestType     string
+	Ecosystem        osv.Ecosystem
+	Vulnerabilities  []CustomVulnerability
+	Summary          VulnerabilitySummary
+	PackagesScanned  int
+	Dependencies     []ManifestPackage // Every package in the manifest, for SBOM output
+	Warnings         []string          // Non-fatal problems such as failed queries
+	Unverified       bool              // Every OSV query failed, so no findings doesn't mean clean
+	WithdrawnSkipped int               // Advisories OSV has withdrawn, left out of the findings
+	Error            error
+}
+
+// RunCustomAudit checks the packages of a manifest read by a registered parser
+// for vulnerabilities in the given OSV ecosystem using OSV API
+func (r *Runner) RunCustomAudit(manifestPath string, manifestType string, ecosystem osv.Ecosystem) *CustomAuditResult {
+	result := &CustomAuditResult{
+		ManifestPath: manifestPath,
+		ManifestType: manifestType,
+		Ecosystem:    ecosystem,
+	}
+
+	parser, ok := lookupParser[ParserFunc](manifestType)
+	if !ok {
+		result.Error = fmt.Errorf("no parser registered for manifest type: %s", manifestType)
+		return result
+	}
+
+	packages, err := parser(manifestPath)
+	if err != nil {
+		result.Error = fmt.Errorf("failed to parse manifest: %w", err)
+		return result
+	}
+	packages = withoutExcluded(r, ecosystem, packages)
+
+	if len(packages) == 0 {
+		// No packages found, not an error
+		return result
+	}
+
+	result.PackagesScanned = len(packages)
+	result.Dependencies = packages
+
+	if r.verbose {
+		fmt.Fprintf(os.Stderr, "Found %d packages in %s\n", len(packages), filepath.Base(manifestPath))
+	}
+
+	osvPkgs := make([]osv.Package, 0, len(packages))
+	for _, pkg := range packages {
+		osvPkgs = append(osvPkgs, osv.Package{
+			Name:      pkg.Name,
+			Version:   pkg.Version,
+			Ecosystem: ecosystem,
+		})
+	}
+	responses := r.osvClient.QueryPackagesContext(r.ctx, osvPkgs)
+	result.Unverified = allQueriesFailed(responses)
+
+	for i, pkg := range packages {
+		if r.verbose {
+			fmt.Fprintf(os.Stderr, "  Checking %s@%s...\n", pkg.Name, pkg.Version)
+		}
+
+		response, err := responses[i].Response, responses[i].Err
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("failed to query %s: %v", pkg.Name, err))
+			if r.verbose {
+				fmt.Fprintf(os.Stderr, "    Warning: Failed to query %s: %v\n", pkg.Name, err)
+			}
+			continue
+		}
+
+		// Withdrawn advisories no longer describe a vulnerability
+		var withdrawn int
+		response.Vulns, withdrawn = dropWithdrawn(response.Vulns)
+		result.WithdrawnSkipped += withdrawn
+
+		// Re-check matches against semver ranges locally
+		response.Vulns = r.filterAffected(osvPkgs[i], response.Vulns)
+
+		// Count advisories that alias one another as a single finding
+		response.Vulns = mergeAliasedAdvisories(response.Vulns)
+
+		if r.verbose && len(response.Vulns) > 0 {
+			fmt.Fprintf(os.Stderr, "    Found %d vulnerability(ies)\n", len(response.Vulns))
+			printAdvisories(response.Vulns)
+		}
+
+		for _, vuln := range response.Vulns {
+			customVuln := CustomVulnerability{
+				Name:        pkg.Name,
+				Version:     pkg.Version,
+				Ecosystem:   ecosystem,
+				ID:          vuln.ID,
+				FixVersions: extractFixVersions(vuln),
+				Description: vuln.Summary,
+				Aliases:     vuln.Aliases,
+				Severity:    vuln.GetSeverityLevel(),
+				Published:   vuln.PublishedTime(),
+				Modified:    vuln.ModifiedTime(),
+				IsDirect:    pkg.IsDirect,
+			}
+
+			result.Vulnerabilities = append(result.Vulnerabilities, customVuln)
+
+			// Count it in the summary by severity and directness
+			customVuln.countIn(&result.Summary)
+		}
+	}
+
+	return result
+}
+
+// HasVulnerabilities returns true if the custom audit result contains vulnerabilities
+func (r *CustomAuditResult) HasVulnerabilities() bool {
+	return r.Summary.Total > 0
+}
@@ -0,0 +1,182 @@
+package audit
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/brandonapol/snoop/osv"
+)
+
+// PackageExclusion names a package that is left out of the audit entirely.
+// Unlike suppressing an advisory, the package is never queried and doesn't
+// appear in the results, which suits first-party packages the team owns.
+type PackageExclusion struct {
+	Ecosystem osv.Ecosystem
+	Name      string
+}
+
+// exclusionEcosystems maps the ecosystem prefixes accepted by
+// ParsePackageExclusion to OSV ecosystems. Both OSV names and the names used
+// in reports and package URLs are accepted.
+var exclusionEcosystems = map[string]osv.Ecosystem{
+	"npm":       osv.NPM,
+	"pypi":      osv.PyPI,
+	"python":    osv.PyPI,
+	"go":        osv.Go,
+	"golang":    osv.Go,
+	"maven":     osv.Maven,
+	"swift":     osv.SwiftURL,
+	"swifturl":  osv.SwiftURL,
+	"rubygems":  osv.RubyGems,
+	"gem":       osv.RubyGems,
+	"ruby":      osv.RubyGems,
+	"crates.io": osv.CratesIO,
+	"cargo":     osv.CratesIO,
+	"nuget":     osv.NuGet,
+	"packagist": osv.Packagist,
+	"composer":  osv.Packagist,
+	"php":       osv.Packagist,
+}
+
+// ParsePackageExclusion parses an exclusion written as ecosystem:name, such
+// as npm:@acme/ui, python:acme-utils, or maven:com.acme:core. The ecosystem
+// is case-insensitive; everything after the first colon is the name.
+func ParsePackageExclusion(spec string) (PackageExclusion, error) {
+	prefix, name, ok := strings.Cut(strings.TrimSpace(spec), ":")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" {
+		return PackageExclusion{}, fmt.Errorf("invalid package exclusion %q: expected ecosystem:name", spec)
+	}
+
+	ecosystem, ok := exclusionEcosystems[strings.ToLower(strings.TrimSpace(prefix))]
+	if !ok {
+		return PackageExclusion{}, fmt.Errorf("invalid package exclusion %q: unknown ecosystem %q", spec, prefix)
+	}
+
+	return PackageExclusion{Ecosystem: ecosystem, Name: name}, nil
+}
+
+// pythonNameSeparators are the runs of characters PEP 503 treats as one "-"
+var pythonNameSeparators = regexp.MustCompile(`[-_.]+`)
+
+// normalizePythonName returns the PEP 503 form of a Python package name, so
+// Flask_Login and flask-login compare equal
+func normalizePythonName(name string) string {
+	return pythonNameSeparators.ReplaceAllString(strings.ToLower(name), "-")
+}
+
+// exclusionKey identifies a package for exclusion, normalizing names in
+// ecosystems where differently written names refer to the same package
+func exclusionKey(ecosystem osv.Ecosystem, name string) string {
+	if ecosystem == osv.PyPI {
+		name = normalizePythonName(name)
+	}
+	return string(ecosystem) + ":" + name
+}
+
+// SetExcludedPackages sets the packages every audit drops before querying
+func (r *Runner) SetExcludedPackages(exclusions []PackageExclusion) {
+	r.excludedPackages = make(map[string]bool, len(exclusions))
+	for _, exclusion := range exclusions {
+		r.excludedPackages[exclusionKey(exclusion.Ecosystem, exclusion.Name)] = true
+	}
+}
+
+// isExcluded reports whether the named package is excluded from the audit
+func (r *Runner) isExcluded(ecosystem osv.Ecosystem, name string) bool {
+	if !r.excludedPackages[exclusionKey(ecosystem, name)] {
+		return false
+	}
+	if r.verbose {
+		fmt.Fprintf(os.Stderr, "  Skipping excluded package %s\n", name)
+	}
+	return true
+}
+
+// excludable is a package an exclusion can name
+type excludable interface {
+	// excludedAs returns the ecosystem and name an exclusion matches the
+	// package by, given the ecosystem of the manifest it was read from
+	excludedAs(manifest osv.Ecosystem) (osv.Ecosystem, string)
+}
+
+// withoutExcluded drops excluded packages from the packages read from a
+// manifest of the given ecosystem
+func withoutExcluded[T excludable](r *Runner, ecosystem osv.Ecosystem, packages []T) []T {
+	var kept []T
+	for _, pkg := range packages {
+		if !r.isExcluded(pkg.excludedAs(ecosystem)) {
+			kept = append(kept, pkg)
+		}
+	}
+	return kept
+}
+
+// excludedAs names the package as its manifest does
+func (p NpmPackage) excludedAs(manifest osv.Ecosystem) (osv.Ecosystem, string) {
+	return manifest, p.Name
+}
+
+// excludedAs names the package as its manifest does
+func (p PythonPackage) excludedAs(manifest osv.Ecosystem) (osv.Ecosystem, string) {
+	return manifest, p.Name
+}
+
+// excludedAs names the package as its manifest does
+func (m GoModule) excludedAs(manifest osv.Ecosystem) (osv.Ecosystem, string) {
+	return manifest, m.Path
+}
+
+// excludedAs names a Maven dependency groupId:artifactId
+func (d MavenDependency) excludedAs(manifest osv.Ecosystem) (osv.Ecosystem, string) {
+	return manifest, d.GetMavenPackageName()
+}
+
+// excludedAs names the package as its manifest does
+func (p SwiftPackage) excludedAs(manifest osv.Ecosystem) (osv.Ecosystem, string) {
+	return manifest, p.Name
+}
+
+// excludedAs names the package as its manifest does
+func (p CargoPackage) excludedAs(manifest osv.Ecosystem) (osv.Ecosystem, string) {
+	return manifest, p.Name
+}
+
+// excludedAs names the package as its manifest does
+func (p RubyGem) excludedAs(manifest osv.Ecosystem) (osv.Ecosystem, string) {
+	return manifest, p.Name
+}
+
+// excludedAs names the package as its manifest does
+func (p ComposerPackage) excludedAs(manifest osv.Ecosystem) (osv.Ecosystem, string) {
+	return manifest, p.Name
+}
+
+// excludedAs names the package as its manifest does
+func (p ManifestPackage) excludedAs(manifest osv.Ecosystem) (osv.Ecosystem, string) {
+	return manifest, p.Name
+}
+
+// excludedAs names an SBOM component in its own ecosystem, since an SBOM
+// lists packages of many
+func (c SBOMComponent) excludedAs(osv.Ecosystem) (osv.Ecosystem, string) {
+	return c.Ecosystem, c.Name
+}
+
+// withoutExcludedFindings drops npm audit findings for excluded packages and
+// recomputes the summary. npm audit queries the whole tree itself, so its
+// findings are filtered afterwards.
+func (r *AuditResult) withoutExcludedFindings(runner *Runner) {
+	excluded := func(vuln Vulnerability) bool {
+		return runner.isExcluded(osv.NPM, vuln.Name)
+	}
+	if len(runner.excludedPackages) == 0 || !slices.ContainsFunc(r.Vulnerabilities, excluded) {
+		return
+	}
+	r.retain(func(finding Finding) bool {
+		return !excluded(finding.(Vulnerability))
+	})
+}
@@ -0,0 +1,121 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/brandonapol/snoop/osv"
+)
+
+func TestParsePackageExclusion(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected PackageExclusion
+		wantErr  bool
+	}{
+		{spec: "npm:@acme/ui", expected: PackageExclusion{Ecosystem: osv.NPM, Name: "@acme/ui"}},
+		{spec: "Python:acme-utils", expected: PackageExclusion{Ecosystem: osv.PyPI, Name: "acme-utils"}},
+		{spec: "maven:com.acme:core", expected: PackageExclusion{Ecosystem: osv.Maven, Name: "com.acme:core"}},
+		{spec: "go:github.com/acme/lib", expected: PackageExclusion{Ecosystem: osv.Go, Name: "github.com/acme/lib"}},
+		{spec: "lodash", wantErr: true},
+		{spec: "npm:", wantErr: true},
+		{spec: "cobol:acme", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			exclusion, err := ParsePackageExclusion(tt.spec)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParsePackageExclusion(%q) expected error, got %+v", tt.spec, exclusion)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParsePackageExclusion(%q) unexpected error: %v", tt.spec, err)
+			}
+			if exclusion != tt.expected {
+				t.Errorf("ParsePackageExclusion(%q) = %+v, expected %+v", tt.spec, exclusion, tt.expected)
+			}
+		})
+	}
+}
+
+func TestExcludedPackageIsNeverQueried(t *testing.T) {
+	var mu sync.Mutex
+	queried := make(map[string]int)
+	server := newMockOSVServer(t, func(request osv.QueryRequest) []osv.Vulnerability {
+		mu.Lock()
+		queried[request.Package.Name]++
+		mu.Unlock()
+		return []osv.Vulnerability{{ID: "PYSEC-2021-1", Summary: "issue in " + request.Package.Name}}
+	})
+
+	tmpDir := t.TempDir()
+	requirementsPath := filepath.Join(tmpDir, "requirements.txt")
+	if err := os.WriteFile(requirementsPath, []byte("flask==2.0.0\nAcme_Utils==1.0.0\n"), 0644); err != nil {
+		t.Fatalf("Failed to write requirements.txt: %v", err)
+	}
+
+	runner := NewRunner(0, false)
+	runner.osvClient = osv.NewClientWithURL(server.URL)
+	// Python names are matched as pip does, ignoring case and separators
+	runner.SetExcludedPackages([]PackageExclusion{{Ecosystem: osv.PyPI, Name: "acme-utils"}})
+
+	result := runner.RunPythonAudit(requirementsPath, "requirements.txt")
+	if result.Error != nil {
+		t.Fatalf("RunPythonAudit() unexpected error: %v", result.Error)
+	}
+
+	if queried["Acme_Utils"] != 0 {
+		t.Errorf("excluded package was queried %d time(s)", queried["Acme_Utils"])
+	}
+	if queried["flask"] != 1 {
+		t.Errorf("flask was queried %d time(s), expected 1", queried["flask"])
+	}
+	if result.PackagesScanned != 1 || len(result.Dependencies) != 1 {
+		t.Errorf("PackagesScanned = %d, Dependencies = %+v, expected only flask", result.PackagesScanned, result.Dependencies)
+	}
+	for _, vuln := range result.Vulnerabilities {
+		if vuln.Name != "flask" {
+			t.Errorf("excluded package reported: %+v", vuln)
+		}
+	}
+}
+
+func TestExcludedComponentsMatchTheirOwnEcosystem(t *testing.T) {
+	runner := NewRunner(0, false)
+	runner.SetExcludedPackages([]PackageExclusion{{Ecosystem: osv.NPM, Name: "acme-ui"}})
+
+	// Only the npm component is excluded, not the PyPI one of the same name
+	components := withoutExcluded(runner, "", []SBOMComponent{
+		{Name: "acme-ui", Ecosystem: osv.NPM},
+		{Name: "acme-ui", Ecosystem: osv.PyPI},
+	})
+	if len(components) != 1 || components[0].Ecosystem != osv.PyPI {
+		t.Errorf("withoutExcluded() = %+v, expected only the PyPI acme-ui", components)
+	}
+}
+
+func TestExcludedNpmFindingsAreDropped(t *testing.T) {
+	runner := NewRunner(0, false)
+	runner.SetExcludedPackages([]PackageExclusion{{Ecosystem: osv.NPM, Name: "acme-ui"}})
+
+	result := &AuditResult{
+		Vulnerabilities: []Vulnerability{
+			{Name: "acme-ui", Severity: SeverityCritical, IsDirect: true},
+			{Name: "lodash", Severity: SeverityHigh},
+		},
+		Summary: VulnerabilitySummary{Critical: 1, High: 1, Total: 2, Direct: 1, Transitive: 1},
+	}
+	result.withoutExcludedFindings(runner)
+
+	if len(result.Vulnerabilities) != 1 || result.Vulnerabilities[0].Name != "lodash" {
+		t.Errorf("Vulnerabilities = %+v, expected only lodash", result.Vulnerabilities)
+	}
+	if expected := (VulnerabilitySummary{High: 1, Total: 1, Transitive: 1}); result.Summary != expected {
+		t.Errorf("Summary = %+v, expected %+v", result.Summary, expected)
+	}
+}
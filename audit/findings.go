@@ -0,0 +1,170 @@
+package audit
+
+// Finding is a vulnerability of any ecosystem, as the filters that drop
+// findings from a result see it
+type Finding interface {
+	SeverityRated
+	// countIn adds the finding to a summary, by severity and directness
+	countIn(summary *VulnerabilitySummary)
+	// fixable reports whether the finding has a fix to upgrade to
+	fixable() bool
+	// suppressedBy reports whether suppressions accept the finding's risk
+	suppressedBy(suppressions []Suppression) bool
+}
+
+// Result is the audit of one manifest, of any ecosystem. Every result type of
+// this package is one, so findings are dropped and their summary recounted
+// the same way whatever the ecosystem.
+type Result interface {
+	// retain keeps the findings keep accepts, recounts the summary from
+	// them, and returns how many were dropped
+	retain(keep func(Finding) bool) int
+}
+
+// retain keeps the findings keep accepts and rebuilds summary from them,
+// returning how many were dropped. Each result type's retain method calls it
+// with its own findings and summary.
+func retain[T Finding](findings *[]T, summary *VulnerabilitySummary, keep func(Finding) bool) int {
+	var kept []T
+	counted := VulnerabilitySummary{}
+	for _, finding := range *findings {
+		if keep(finding) {
+			kept = append(kept, finding)
+			finding.countIn(&counted)
+		}
+	}
+	dropped := len(*findings) - len(kept)
+	*findings = kept
+	*summary = counted
+	return dropped
+}
+
+// FilterSeverity drops the findings of a result less severe than
+// minSeverity and recounts its summary, returning how many were dropped
+func FilterSeverity(r Result, minSeverity Severity) int {
+	return r.retain(func(finding Finding) bool {
+		return atLeastSeverity(finding, minSeverity)
+	})
+}
+
+// retain keeps the findings keep accepts; see Result
+func (r *AuditResult) retain(keep func(Finding) bool) int {
+	return retain(&r.Vulnerabilities, &r.Summary, keep)
+}
+
+// retain keeps the findings keep accepts; see Result
+func (r *PythonAuditResult) retain(keep func(Finding) bool) int {
+	return retain(&r.Vulnerabilities, &r.Summary, keep)
+}
+
+// retain keeps the findings keep accepts; see Result
+func (r *GoAuditResult) retain(keep func(Finding) bool) int {
+	return retain(&r.Vulnerabilities, &r.Summary, keep)
+}
+
+// retain keeps the findings keep accepts; see Result
+func (r *MavenAuditResult) retain(keep func(Finding) bool) int {
+	return retain(&r.Vulnerabilities, &r.Summary, keep)
+}
+
+// retain keeps the findings keep accepts; see Result
+func (r *SwiftAuditResult) retain(keep func(Finding) bool) int {
+	return retain(&r.Vulnerabilities, &r.Summary, keep)
+}
+
+// retain keeps the findings keep accepts; see Result
+func (r *CargoAuditResult) retain(keep func(Finding) bool) int {
+	return retain(&r.Vulnerabilities, &r.Summary, keep)
+}
+
+// retain keeps the findings keep accepts; see Result
+func (r *RubyAuditResult) retain(keep func(Finding) bool) int {
+	return retain(&r.Vulnerabilities, &r.Summary, keep)
+}
+
+// retain keeps the findings keep accepts; see Result
+func (r *ComposerAuditResult) retain(keep func(Finding) bool) int {
+	return retain(&r.Vulnerabilities, &r.Summary, keep)
+}
+
+// retain keeps the findings keep accepts; see Result
+func (r *RuntimeAuditResult) retain(keep func(Finding) bool) int {
+	return retain(&r.Vulnerabilities, &r.Summary, keep)
+}
+
+// retain keeps the findings keep accepts; see Result
+func (r *SBOMAuditResult) retain(keep func(Finding) bool) int {
+	return retain(&r.Vulnerabilities, &r.Summary, keep)
+}
+
+// retain keeps the findings keep accepts; see Result
+func (r *CustomAuditResult) retain(keep func(Finding) bool) int {
+	return retain(&r.Vulnerabilities, &r.Summary, keep)
+}
+
+// countIn adds the finding to a summary
+func (v Vulnerability) countIn(s *VulnerabilitySummary) {
+	s.addFinding(v.Severity)
+	s.AddDirectness(v.IsDirect)
+}
+
+// countIn adds the finding to a summary
+func (v PythonVulnerability) countIn(s *VulnerabilitySummary) {
+	s.addFinding(Severity(v.Severity))
+	s.AddDirectness(v.IsDirect)
+}
+
+// countIn adds the finding to a summary
+func (v GoVulnerability) countIn(s *VulnerabilitySummary) {
+	s.addFinding(Severity(v.Severity))
+	s.AddDirectness(v.IsDirect)
+}
+
+// countIn adds the finding to a summary
+func (v MavenVulnerability) countIn(s *VulnerabilitySummary) {
+	s.addFinding(Severity(v.Severity))
+	s.AddDirectness(v.IsDirect)
+}
+
+// countIn adds the finding to a summary
+func (v SwiftVulnerability) countIn(s *VulnerabilitySummary) {
+	s.addFinding(Severity(v.Severity))
+	s.AddDirectness(v.IsDirect)
+}
+
+// countIn adds the finding to a summary
+func (v CargoVulnerability) countIn(s *VulnerabilitySummary) {
+	s.addFinding(Severity(v.Severity))
+	s.AddDirectness(v.IsDirect)
+}
+
+// countIn adds the finding to a summary
+func (v RubyVulnerability) countIn(s *VulnerabilitySummary) {
+	s.addFinding(Severity(v.Severity))
+	s.AddDirectness(v.IsDirect)
+}
+
+// countIn adds the finding to a summary
+func (v ComposerVulnerability) countIn(s *VulnerabilitySummary) {
+	s.addFinding(Severity(v.Severity))
+	s.AddDirectness(v.IsDirect)
+}
+
+// countIn counts a runtime finding as direct: the manifest declares the
+// runtime version itself
+func (v RuntimeVulnerability) countIn(s *VulnerabilitySummary) {
+	s.addFinding(Severity(v.Severity))
+	s.AddDirectness(true)
+}
+
+// countIn counts an SBOM finding by severity only, since an SBOM doesn't say
+// which components are direct
+func (v SBOMVulnerability) countIn(s *VulnerabilitySummary) {
+	s.addFinding(Severity(v.Severity))
+}
+
+// countIn adds the finding to a summary
+func (v CustomVulnerability) countIn(s *VulnerabilitySummary) {
+	s.addFinding(Severity(v.Severity))
+	s.AddDirectness(v.IsDirect)
+}
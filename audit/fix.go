@@ -0,0 +1,255 @@
+package audit
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// FixKind classifies how a vulnerable dependency can be fixed
+type FixKind string
+
+const (
+	// FixUpgradeDirect upgrades a direct dependency, either the vulnerable
+	// package itself or the one that pulls it in
+	FixUpgradeDirect FixKind = "upgrade-direct"
+	// FixUpdateLockfile refreshes the lockfile; a fixed version already
+	// satisfies the declared ranges
+	FixUpdateLockfile FixKind = "update-lockfile"
+	// FixOverride pins a transitive dependency because no release of the
+	// direct dependency pulls in the fix
+	FixOverride FixKind = "override"
+	// FixNone means no fixed version has been published
+	FixNone FixKind = "none"
+)
+
+// Node.js package managers, which differ in how transitive versions are pinned
+const (
+	PackageManagerNpm  = "npm"
+	PackageManagerYarn = "yarn"
+	PackageManagerPnpm = "pnpm"
+)
+
+// FixRecommendation describes how to remediate one vulnerable package
+type FixRecommendation struct {
+	Package   string  `json:"package"`
+	Kind      FixKind `json:"kind"`
+	Target    string  `json:"target,omitempty"` // Version or range to move to
+	Directive string  `json:"directive"`        // Command or manifest change to apply
+}
+
+// upperBoundRegex matches the exclusive upper bound of an npm range, e.g. "<4.17.21"
+var upperBoundRegex = regexp.MustCompile(`(?:^|\s)<\s*v?([0-9][^\s|]*)`)
+
+// DetectPackageManager guesses the Node.js package manager from the lockfile
+// next to package.json, defaulting to npm
+func DetectPackageManager(dir string) string {
+	if _, err := os.Stat(filepath.Join(dir, "yarn.lock")); err == nil {
+		return PackageManagerYarn
+	}
+	if _, err := os.Stat(filepath.Join(dir, "pnpm-lock.yaml")); err == nil {
+		return PackageManagerPnpm
+	}
+	return PackageManagerNpm
+}
+
+// RecommendNpmFixes works out, for each vulnerable package, whether upgrading
+// a direct dependency fixes it or whether the transitive version has to be
+// pinned with an override (npm, pnpm) or resolution (yarn). Packages that are
+// only vulnerable through one of their dependencies are left to that
+// dependency's recommendation.
+func RecommendNpmFixes(vulns []Vulnerability, packageManager string) []FixRecommendation {
+	var recommendations []FixRecommendation
+
+	vulnerable := make(map[string]bool)
+	for _, vuln := range vulns {
+		vulnerable[vuln.Name] = true
+	}
+
+	for _, vuln := range vulns {
+		if !hasAdvisory(vuln, vulnerable) {
+			continue
+		}
+
+		fix := vuln.FixAvailable
+		if fix.Upgrade() {
+			directive := fmt.Sprintf("npm install %s@%s", fix.Name, fix.Version)
+			if fix.IsSemVerMajor {
+				directive += " (major upgrade)"
+			}
+			recommendations = append(recommendations, FixRecommendation{
+				Package:   vuln.Name,
+				Kind:      FixUpgradeDirect,
+				Target:    fmt.Sprintf("%s@%s", fix.Name, fix.Version),
+				Directive: directive,
+			})
+			continue
+		}
+
+		// OSV-based findings without fix versions aren't Available
+		if fix.Available {
+			recommendations = append(recommendations, FixRecommendation{
+				Package:   vuln.Name,
+				Kind:      FixUpdateLockfile,
+				Directive: "npm audit fix",
+			})
+			continue
+		}
+
+		target := fixedRangeFromVulnerableRange(vuln.Range)
+		switch {
+		case target == "":
+			recommendations = append(recommendations, FixRecommendation{
+				Package:   vuln.Name,
+				Kind:      FixNone,
+				Directive: fmt.Sprintf("No fixed version of %s has been published; consider replacing it", vuln.Name),
+			})
+		case vuln.IsDirect:
+			recommendations = append(recommendations, FixRecommendation{
+				Package:   vuln.Name,
+				Kind:      FixUpgradeDirect,
+				Target:    target,
+				Directive: fmt.Sprintf("npm install %s@%s", vuln.Name, target),
+			})
+		default:
+			recommendations = append(recommendations, FixRecommendation{
+				Package:   vuln.Name,
+				Kind:      FixOverride,
+				Target:    target,
+				Directive: overrideDirective(packageManager, vuln.Name, target),
+			})
+		}
+	}
+
+	sort.SliceStable(recommendations, func(i, j int) bool {
+		return recommendations[i].Package < recommendations[j].Package
+	})
+	return recommendations
+}
+
+// hasAdvisory reports whether an npm audit entry carries its own advisory
+// rather than only inheriting one through a dependency. npm audit names such
+// dependencies in via; anything else in via is an advisory.
+func hasAdvisory(vuln Vulnerability, vulnerable map[string]bool) bool {
+	for _, via := range vuln.Via {
+		name, ok := via.(string)
+		if !ok || !vulnerable[name] {
+			return true
+		}
+	}
+	return false
+}
+
+// fixedRangeFromVulnerableRange turns a vulnerable range such as "<4.17.21"
+// into the range of fixed versions "^4.17.21", using the highest exclusive
+// upper bound. It returns "" when the range has no upper bound.
+func fixedRangeFromVulnerableRange(vulnerableRange string) string {
+	best := ""
+	for _, match := range upperBoundRegex.FindAllStringSubmatch(vulnerableRange, -1) {
+		candidate := match[1]
+		if best == "" {
+			best = candidate
+			continue
+		}
+		a, okA := parseSemver(candidate)
+		b, okB := parseSemver(best)
+		if (okA && okB && a.compare(b) > 0) || (!(okA && okB) && candidate > best) {
+			best = candidate
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return "^" + best
+}
+
+// overrideDirective renders the package.json change pinning a transitive
+// dependency for the given package manager
+func overrideDirective(packageManager, name, target string) string {
+	switch packageManager {
+	case PackageManagerYarn:
+		return fmt.Sprintf(`package.json: "resolutions": { "%s": "%s" }`, name, target)
+	case PackageManagerPnpm:
+		return fmt.Sprintf(`package.json: "pnpm": { "overrides": { "%s": "%s" } }`, name, target)
+	default:
+		return fmt.Sprintf(`package.json: "overrides": { "%s": "%s" }`, name, target)
+	}
+}
+
+// RecommendGoFixes recommends raising the required version of each vulnerable
+// module. For an indirect module this pins the transitive version in go.mod,
+// which minimal version selection honors without a replace directive.
+func RecommendGoFixes(vulns []GoVulnerability) []FixRecommendation {
+	var recommendations []FixRecommendation
+	seen := make(map[string]bool)
+
+	for _, vuln := range vulns {
+		if seen[vuln.Module] {
+			continue
+		}
+		seen[vuln.Module] = true
+
+		fixVersion := ""
+		for _, v := range vulns {
+			if v.Module != vuln.Module {
+				continue
+			}
+			if candidate := lowestFixAbove(v.Version, v.FixVersions); candidate != "" {
+				if fixVersion == "" || compareVersionStrings(candidate, fixVersion) > 0 {
+					fixVersion = candidate
+				}
+			}
+		}
+
+		if fixVersion == "" {
+			recommendations = append(recommendations, FixRecommendation{
+				Package:   vuln.Module,
+				Kind:      FixNone,
+				Directive: fmt.Sprintf("No fixed version of %s has been published; consider replacing it", vuln.Module),
+			})
+			continue
+		}
+
+		kind := FixUpgradeDirect
+		if !vuln.IsDirect {
+			kind = FixOverride
+		}
+		fixVersion = "v" + strings.TrimPrefix(fixVersion, "v")
+		recommendations = append(recommendations, FixRecommendation{
+			Package:   vuln.Module,
+			Kind:      kind,
+			Target:    fixVersion,
+			Directive: fmt.Sprintf("go get %s@%s", vuln.Module, fixVersion),
+		})
+	}
+
+	return recommendations
+}
+
+// lowestFixAbove returns the lowest fix version newer than current
+func lowestFixAbove(current string, fixVersions []string) string {
+	lowest := ""
+	for _, fix := range fixVersions {
+		if compareVersionStrings(fix, current) <= 0 {
+			continue
+		}
+		if lowest == "" || compareVersionStrings(fix, lowest) < 0 {
+			lowest = fix
+		}
+	}
+	return lowest
+}
+
+// compareVersionStrings compares two versions by semver precedence, falling
+// back to string order when either isn't semver
+func compareVersionStrings(a, b string) int {
+	va, okA := parseSemver(a)
+	vb, okB := parseSemver(b)
+	if okA && okB {
+		return va.compare(vb)
+	}
+	return strings.Compare(a, b)
+}
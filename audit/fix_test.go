@@ -0,0 +1,169 @@
+package audit
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/brandonapol/snoop/osv"
+)
+
+// mockOverrideAuditJSON is npm audit output where minimist is vulnerable but
+// mkdirp, which pulls it in, has no release with the fix
+const mockOverrideAuditJSON = `{
+	"auditReportVersion": 2,
+	"vulnerabilities": {
+		"minimist": {
+			"name": "minimist",
+			"severity": "critical",
+			"isDirect": false,
+			"via": [{"source": 1179, "name": "minimist", "title": "Prototype Pollution in minimist", "range": "<1.2.6"}],
+			"effects": ["mkdirp"],
+			"range": "<1.2.6",
+			"nodes": ["node_modules/minimist"],
+			"fixAvailable": false
+		},
+		"mkdirp": {
+			"name": "mkdirp",
+			"severity": "critical",
+			"isDirect": true,
+			"via": ["minimist"],
+			"effects": [],
+			"range": "0.4.1 - 0.5.1",
+			"nodes": ["node_modules/mkdirp"],
+			"fixAvailable": false
+		},
+		"lodash": {
+			"name": "lodash",
+			"severity": "high",
+			"isDirect": true,
+			"via": [{"source": 1523, "name": "lodash", "title": "Command Injection in lodash", "range": "<4.17.21"}],
+			"effects": [],
+			"range": "<4.17.21",
+			"nodes": ["node_modules/lodash"],
+			"fixAvailable": {"name": "lodash", "version": "4.17.21", "isSemVerMajor": false}
+		}
+	},
+	"metadata": {"vulnerabilities": {"critical": 2, "high": 1, "total": 3}}
+}`
+
+func parseMockAudit(t *testing.T, data string) []Vulnerability {
+	t.Helper()
+	var response NpmAuditResponse
+	if err := json.Unmarshal([]byte(data), &response); err != nil {
+		t.Fatalf("Failed to parse mock audit JSON: %v", err)
+	}
+	var vulns []Vulnerability
+	for name, vuln := range response.Vulnerabilities {
+		vuln.Name = name
+		vulns = append(vulns, vuln)
+	}
+	return vulns
+}
+
+func TestRecommendNpmFixesSuggestsOverride(t *testing.T) {
+	vulns := parseMockAudit(t, mockOverrideAuditJSON)
+
+	recommendations := RecommendNpmFixes(vulns, PackageManagerNpm)
+	byPackage := make(map[string]FixRecommendation)
+	for _, rec := range recommendations {
+		byPackage[rec.Package] = rec
+	}
+
+	minimist, ok := byPackage["minimist"]
+	if !ok {
+		t.Fatalf("RecommendNpmFixes() = %+v, expected a recommendation for minimist", recommendations)
+	}
+	if minimist.Kind != FixOverride || minimist.Target != "^1.2.6" {
+		t.Errorf("minimist recommendation = %+v, expected an override to ^1.2.6", minimist)
+	}
+	if !strings.Contains(minimist.Directive, `"overrides": { "minimist": "^1.2.6" }`) {
+		t.Errorf("minimist directive = %q, expected an npm overrides entry", minimist.Directive)
+	}
+
+	// mkdirp is only vulnerable through minimist, so the override covers it
+	if rec, ok := byPackage["mkdirp"]; ok {
+		t.Errorf("RecommendNpmFixes() recommended %+v for mkdirp, expected none", rec)
+	}
+
+	lodash := byPackage["lodash"]
+	if lodash.Kind != FixUpgradeDirect || lodash.Directive != "npm install lodash@4.17.21" {
+		t.Errorf("lodash recommendation = %+v, expected npm install lodash@4.17.21", lodash)
+	}
+}
+
+func TestRecommendNpmFixesUsesYarnResolutions(t *testing.T) {
+	vulns := parseMockAudit(t, mockOverrideAuditJSON)
+
+	for _, rec := range RecommendNpmFixes(vulns, PackageManagerYarn) {
+		if rec.Package == "minimist" && !strings.Contains(rec.Directive, `"resolutions": { "minimist": "^1.2.6" }`) {
+			t.Errorf("minimist directive = %q, expected a yarn resolutions entry", rec.Directive)
+		}
+	}
+}
+
+func TestRecommendGoFixes(t *testing.T) {
+	vulns := []GoVulnerability{
+		{Module: "golang.org/x/net", Version: "v0.7.0", FixVersions: []string{"0.17.0", "0.23.0"}, IsDirect: false},
+		{Module: "golang.org/x/net", Version: "v0.7.0", FixVersions: []string{"0.19.0"}, IsDirect: false},
+		{Module: "github.com/gin-gonic/gin", Version: "v1.7.0", FixVersions: []string{"1.9.1"}, IsDirect: true},
+		{Module: "github.com/abandoned/lib", Version: "v1.0.0", IsDirect: true},
+	}
+
+	expected := []FixRecommendation{
+		{Package: "golang.org/x/net", Kind: FixOverride, Target: "v0.19.0", Directive: "go get golang.org/x/net@v0.19.0"},
+		{Package: "github.com/gin-gonic/gin", Kind: FixUpgradeDirect, Target: "v1.9.1", Directive: "go get github.com/gin-gonic/gin@v1.9.1"},
+		{Package: "github.com/abandoned/lib", Kind: FixNone, Directive: "No fixed version of github.com/abandoned/lib has been published; consider replacing it"},
+	}
+
+	recommendations := RecommendGoFixes(vulns)
+	if len(recommendations) != len(expected) {
+		t.Fatalf("RecommendGoFixes() = %+v, expected %+v", recommendations, expected)
+	}
+	for i := range expected {
+		if recommendations[i] != expected[i] {
+			t.Errorf("RecommendGoFixes()[%d] = %+v, expected %+v", i, recommendations[i], expected[i])
+		}
+	}
+}
+
+func TestUpgradesSplitBreakingAndNonBreaking(t *testing.T) {
+	python := &PythonAuditResult{Vulnerabilities: []PythonVulnerability{
+		{Name: "django", Version: "4.2.0", FixVersions: []string{"4.2.8"}},              // patch
+		{Name: "jinja2", Version: "2.10", FixVersions: []string{"3.1.3"}},               // major
+		{Name: "urllib3", Version: "1.26.5", FixVersions: []string{"1.26.18", "2.0.7"}}, // patch on the current line
+		{Name: "pyyaml", Version: "5.3", FixVersions: nil},                              // no fix
+	}}
+	if got := python.Upgrades(); got != (UpgradeSummary{NonBreaking: 2, Breaking: 1}) {
+		t.Errorf("PythonAuditResult.Upgrades() = %+v, expected 2 non-breaking and 1 breaking", got)
+	}
+
+	golang := &GoAuditResult{Vulnerabilities: []GoVulnerability{
+		{Module: "golang.org/x/net", Version: "v0.17.0", FixVersions: []string{"0.23.0"}}, // 0.x minor bump
+		{Module: "golang.org/x/text", Version: "v0.3.7", FixVersions: []string{"0.3.8"}},  // 0.x patch
+		{Module: "github.com/gin-gonic/gin", Version: "v1.7.0", FixVersions: []string{"1.9.1"}},
+	}}
+	if got := golang.Upgrades(); got != (UpgradeSummary{NonBreaking: 2, Breaking: 1}) {
+		t.Errorf("GoAuditResult.Upgrades() = %+v, expected 2 non-breaking and 1 breaking", got)
+	}
+
+	npm := &AuditResult{Vulnerabilities: []Vulnerability{
+		{Name: "minimist", FixAvailable: FixAvailable{Available: true}},
+		{Name: "lodash", FixAvailable: FixAvailable{Available: true, Name: "lodash", Version: "4.17.21"}},
+		{Name: "request", FixAvailable: FixAvailable{Available: true, Name: "jest", Version: "29.0.0", IsSemVerMajor: true}},
+		{Name: "left-pad", FixAvailable: FixAvailable{}},
+	}}
+	if got := npm.Upgrades(); got != (UpgradeSummary{NonBreaking: 2, Breaking: 1}) {
+		t.Errorf("AuditResult.Upgrades() = %+v, expected 2 non-breaking and 1 breaking", got)
+	}
+
+	// The OSV fallback fills in isSemVerMajor from the pinned version
+	fallback := &AuditResult{Vulnerabilities: []Vulnerability{
+		npmVulnerabilityFromOSV(NpmPackage{Name: "axios", Version: "0.21.1"}, []osv.Vulnerability{{
+			ID: "GHSA-1", Affected: []osv.Affected{{Ranges: []osv.VersionRange{{Type: "SEMVER", Events: []osv.Event{{Introduced: "0"}, {Fixed: "1.6.0"}}}}}},
+		}}),
+	}}
+	if got := fallback.Upgrades(); got != (UpgradeSummary{Breaking: 1}) {
+		t.Errorf("OSV fallback Upgrades() = %+v, expected 1 breaking", got)
+	}
+}
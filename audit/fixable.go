@@ -0,0 +1,106 @@
+package audit
+
+// addFinding counts one finding of the given severity in the summary
+func (s *VulnerabilitySummary) addFinding(severity Severity) {
+	switch severity {
+	case SeverityCritical:
+		s.Critical++
+	case SeverityHigh:
+		s.High++
+	case SeverityModerate, "medium":
+		s.Moderate++
+	case SeverityLow:
+		s.Low++
+	case SeverityInfo:
+		s.Info++
+	default:
+		s.High++ // Default to high
+	}
+	s.Total++
+}
+
+// IsFixable returns true if npm reports a fix, either within the declared
+// range or by upgrading a dependency
+func (v Vulnerability) IsFixable() bool {
+	return v.FixAvailable.Available
+}
+
+// withoutUnfixedRecommendations drops recommendations for packages with no fix
+func withoutUnfixedRecommendations(recommendations []FixRecommendation) []FixRecommendation {
+	var kept []FixRecommendation
+	for _, rec := range recommendations {
+		if rec.Kind != FixNone {
+			kept = append(kept, rec)
+		}
+	}
+	return kept
+}
+
+// FilterFixable drops the findings of a result that have no fix and recounts
+// its summary, returning how many were hidden. The fix recommendations of npm
+// and Go results are dropped along with them.
+func FilterFixable(r Result) int {
+	hidden := r.retain(Finding.fixable)
+	switch r := r.(type) {
+	case *AuditResult:
+		r.Recommendations = withoutUnfixedRecommendations(r.Recommendations)
+	case *GoAuditResult:
+		r.Recommendations = withoutUnfixedRecommendations(r.Recommendations)
+	}
+	return hidden
+}
+
+// fixable reports whether npm reports a fix; see IsFixable
+func (v Vulnerability) fixable() bool {
+	return v.IsFixable()
+}
+
+// fixable reports whether the finding has a fix version
+func (v PythonVulnerability) fixable() bool {
+	return len(v.FixVersions) > 0
+}
+
+// fixable reports whether the finding has a fix version
+func (v GoVulnerability) fixable() bool {
+	return len(v.FixVersions) > 0
+}
+
+// fixable reports whether the finding has a fix version
+func (v MavenVulnerability) fixable() bool {
+	return len(v.FixVersions) > 0
+}
+
+// fixable reports whether the finding has a fix version
+func (v SwiftVulnerability) fixable() bool {
+	return len(v.FixVersions) > 0
+}
+
+// fixable reports whether the finding has a fix version
+func (v CargoVulnerability) fixable() bool {
+	return len(v.FixVersions) > 0
+}
+
+// fixable reports whether the finding has a fix version
+func (v RubyVulnerability) fixable() bool {
+	return len(v.FixVersions) > 0
+}
+
+// fixable reports whether the finding has a fix version
+func (v ComposerVulnerability) fixable() bool {
+	return len(v.FixVersions) > 0
+}
+
+// fixable reports whether the finding has a fix version
+func (v RuntimeVulnerability) fixable() bool {
+	return len(v.FixVersions) > 0
+}
+
+// fixable reports whether the finding has a fix version
+func (v SBOMVulnerability) fixable() bool {
+	return len(v.FixVersions) > 0
+}
+
+// fixable reports whether the finding has a fix version
+func (v CustomVulnerability) fixable() bool {
+	return len(v.FixVersions) > 0
+}
@@ -3,44 +3,75 @@ package audit
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
-	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/brandonapol/snoop/osv"
 )
 
 // GoModule represents a Go module dependency
 type GoModule struct {
-	Path    string
-	Version string
-	Line    int
+	Path     string
+	Version  string
+	Line     int
+	Indirect bool // Marked "// indirect" in go.mod
 }
 
 // GoVulnerability represents a security vulnerability in a Go module
 type GoVulnerability struct {
-	Module      string   `json:"module"`
-	Version     string   `json:"version"`
-	ID          string   `json:"id"`
-	FixVersions []string `json:"fix_versions"`
-	Description string   `json:"description"`
-	Aliases     []string `json:"aliases"`
-	Severity    string   `json:"severity"`
+	Module      string    `json:"module"`
+	Version     string    `json:"version"`
+	ID          string    `json:"id"`
+	FixVersions []string  `json:"fix_versions"`
+	Description string    `json:"description"`
+	Aliases     []string  `json:"aliases"`
+	Severity    string    `json:"severity"`
+	Published   time.Time `json:"published,omitzero"`
+	Modified    time.Time `json:"modified,omitzero"`
+	IsDirect    bool      `json:"is_direct"`
+	Manifests   []string  `json:"manifests,omitempty"` // go.mod files requiring the module, in a combined audit
 }
 
 // GoAuditResult contains the results of running Go vulnerability check
 type GoAuditResult struct {
-	ManifestPath    string
-	ManifestType    string
-	Vulnerabilities []GoVulnerability
-	Summary         VulnerabilitySummary
-	ModulesScanned  int
-	Error           error
+	ManifestPath     string
+	ManifestType     string
+	Vulnerabilities  []GoVulnerability
+	Summary          VulnerabilitySummary
+	ModulesScanned   int
+	Manifests        []string // Every go.mod audited, in a combined audit
+	Vendored         []string // vendor/modules.txt files whose versions were audited in place of go.mod's
+	IndirectSkipped  int      // Indirect go.mod requirements left out of the audit; see SetIncludeIndirect
+	WithdrawnSkipped int      // Advisories OSV has withdrawn, left out of the findings
+	Recommendations  []FixRecommendation
+	Dependencies     []GoModule // Every module audited, for SBOM output
+	Warnings         []string   // Non-fatal problems such as failed queries
+	Unverified       bool       // Every OSV query failed, so no findings doesn't mean clean
+	Error            error
 }
 
-// ParseGoMod parses a go.mod file and extracts dependencies
-func ParseGoMod(filepath string) ([]GoModule, error) {
+// goReplacement is the target of a replace directive. A replacement by a
+// local directory has no version.
+type goReplacement struct {
+	path    string
+	version string
+}
+
+// ParseGoMod parses a go.mod file and extracts dependencies. Versions are
+// returned the way OSV expects them: without the "v" prefix or the
+// +incompatible marker of pre-module major versions, so
+// v2.0.0+incompatible becomes 2.0.0. Pseudo-versions such as
+// v0.0.0-20210101000000-abcdef123456 are valid semver pre-releases and are
+// kept as they are. A module named by a replace directive is reported at its
+// replacement; modules replaced by a local directory are skipped, since no
+// published version of them is built. Requirements marked // indirect are
+// returned with Indirect set.
+func ParseGoMod(filepath string) (modules []GoModule, err error) {
 	file, err := os.Open(filepath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open go.mod: %w", err)
@@ -51,76 +82,201 @@ func ParseGoMod(filepath string) ([]GoModule, error) {
 		}
 	}()
 
+	return ParseGoModFromReader(file)
+}
+
+// ParseGoModFromReader parses the contents of a go.mod file read from r, the
+// way ParseGoMod does
+func ParseGoModFromReader(r io.Reader) ([]GoModule, error) {
 	var modules []GoModule
-	scanner := bufio.NewScanner(file)
+	// Keyed by module path, or by path@version for a replacement of one version
+	replacements := make(map[string]goReplacement)
+	scanner := bufio.NewScanner(r)
 	lineNum := 0
-	inRequireBlock := false
-
-	// Regex to match require statements
-	// Matches: github.com/user/repo v1.2.3
-	requireRegex := regexp.MustCompile(`^\s*([a-zA-Z0-9\-_\./]+)\s+v?([0-9]+\.[0-9]+\.[0-9]+[^\s]*)`)
-
-	// Simple require statement
-	simpleRequireRegex := regexp.MustCompile(`^require\s+([a-zA-Z0-9\-_\./]+)\s+v?([0-9]+\.[0-9]+\.[0-9]+[^\s]*)`)
+	block := "" // Directive of the ( ... ) block being read
 
 	for scanner.Scan() {
 		lineNum++
-		line := scanner.Text()
-		trimmedLine := strings.TrimSpace(line)
+		content, comment, _ := strings.Cut(scanner.Text(), "//")
+		indirect := strings.HasPrefix(strings.TrimSpace(comment), "indirect")
 
-		// Skip empty lines and comments
-		if trimmedLine == "" || strings.HasPrefix(trimmedLine, "//") {
+		fields := strings.Fields(content)
+		if len(fields) == 0 {
 			continue
 		}
 
-		// Check for require block
-		if strings.HasPrefix(trimmedLine, "require (") {
-			inRequireBlock = true
+		directive := block
+		if block == "" {
+			directive, fields = fields[0], fields[1:]
+			if len(fields) == 1 && fields[0] == "(" {
+				block = directive
+				continue
+			}
+		} else if fields[0] == ")" {
+			block = ""
 			continue
 		}
 
-		// Check for end of require block
-		if inRequireBlock && strings.Contains(trimmedLine, ")") {
-			inRequireBlock = false
-			continue
+		switch directive {
+		case "require":
+			if len(fields) < 2 {
+				continue
+			}
+			modules = append(modules, GoModule{
+				Path:     goModulePath(fields[0]),
+				Version:  goVersion(fields[1]),
+				Line:     lineNum,
+				Indirect: indirect,
+			})
+		case "replace":
+			old, replacement, ok := parseGoReplace(fields)
+			if ok {
+				replacements[old] = replacement
+			}
 		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading go.mod: %w", err)
+	}
 
-		// Parse simple require statement (single line)
-		if !inRequireBlock && strings.HasPrefix(trimmedLine, "require ") {
-			matches := simpleRequireRegex.FindStringSubmatch(trimmedLine)
-			if len(matches) >= 3 {
-				modules = append(modules, GoModule{
-					Path:    matches[1],
-					Version: matches[2],
-					Line:    lineNum,
-				})
+	return applyGoReplacements(modules, replacements), nil
+}
+
+// parseGoReplace reads the fields of a replace directive, such as
+// "old v1.0.0 => new v1.2.3" or "old => ../local". The key is the old path,
+// with @version when only that version is replaced.
+func parseGoReplace(fields []string) (string, goReplacement, bool) {
+	arrow := slices.Index(fields, "=>")
+	if arrow < 1 || arrow > 2 || len(fields) <= arrow+1 {
+		return "", goReplacement{}, false
+	}
+
+	key := goModulePath(fields[0])
+	if arrow == 2 {
+		key += "@" + goVersion(fields[1])
+	}
+
+	replacement := goReplacement{path: goModulePath(fields[arrow+1])}
+	if len(fields) > arrow+2 {
+		replacement.version = goVersion(fields[arrow+2])
+	}
+	return key, replacement, true
+}
+
+// applyGoReplacements swaps replaced modules for their replacements, a
+// version-specific replace taking precedence over one for every version
+func applyGoReplacements(modules []GoModule, replacements map[string]goReplacement) []GoModule {
+	if len(replacements) == 0 {
+		return modules
+	}
+
+	var replaced []GoModule
+	for _, module := range modules {
+		replacement, ok := replacements[module.Path+"@"+module.Version]
+		if !ok {
+			replacement, ok = replacements[module.Path]
+		}
+		if ok {
+			if replacement.version == "" {
+				continue
 			}
-			continue
+			module.Path, module.Version = replacement.path, replacement.version
+		}
+		replaced = append(replaced, module)
+	}
+	return replaced
+}
+
+// goModulePath unquotes a module path, which go.mod may write as a string
+func goModulePath(path string) string {
+	if unquoted, err := strconv.Unquote(path); err == nil {
+		return unquoted
+	}
+	return path
+}
+
+// goVersion converts a go.mod version to the form OSV matches against
+func goVersion(version string) string {
+	version = strings.TrimSuffix(goModulePath(version), "+incompatible")
+	return strings.TrimPrefix(version, "v")
+}
+
+// ParseVendorModulesTxt parses a vendor/modules.txt file, which lists the module
+// versions actually compiled into a vendored build. A replaced module is
+// reported at its replacement's version; modules replaced by a local
+// directory have no version and are skipped.
+func ParseVendorModulesTxt(path string) (modules []GoModule, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open modules.txt: %w", err)
+	}
+	defer func() {
+		if closeErr := file.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("failed to close file: %w", closeErr)
 		}
+	}()
 
-		// Parse dependencies in require block
-		if inRequireBlock {
-			matches := requireRegex.FindStringSubmatch(line)
-			if len(matches) >= 3 {
-				// Skip indirect dependencies if needed
-				if !strings.Contains(line, "// indirect") {
-					modules = append(modules, GoModule{
-						Path:    matches[1],
-						Version: matches[2],
-						Line:    lineNum,
-					})
-				}
-			}
+	scanner := bufio.NewScanner(file)
+	lineNum := 0
+
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+
+		// Module lines start with "# "; "## " lines annotate the module above
+		if !strings.HasPrefix(line, "# ") {
+			continue
+		}
+		fields := strings.Fields(strings.TrimPrefix(line, "# "))
+		if i := slices.Index(fields, "=>"); i >= 0 {
+			fields = fields[i+1:]
+		}
+		if len(fields) != 2 {
+			continue
 		}
+
+		modules = append(modules, GoModule{
+			Path:    fields[0],
+			Version: goVersion(fields[1]),
+			Line:    lineNum,
+		})
 	}
 
 	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error reading go.mod: %w", err)
+		return nil, fmt.Errorf("error reading modules.txt: %w", err)
 	}
 
 	return modules, nil
 }
 
+// vendoredModules returns the modules a vendored build of manifestPath
+// compiles, marking as indirect those go.mod doesn't require directly. It
+// returns the go.mod modules and an empty path when there is no vendor/modules.txt.
+func vendoredModules(manifestPath string, modules []GoModule) ([]GoModule, string, error) {
+	vendorPath := filepath.Join(filepath.Dir(manifestPath), "vendor", "modules.txt")
+	if _, err := os.Stat(vendorPath); err != nil {
+		return modules, "", nil
+	}
+
+	vendored, err := ParseVendorModulesTxt(vendorPath)
+	if err != nil {
+		return modules, "", err
+	}
+
+	direct := make(map[string]bool)
+	for _, module := range modules {
+		if !module.Indirect {
+			direct[module.Path] = true
+		}
+	}
+	for i := range vendored {
+		vendored[i].Indirect = !direct[vendored[i].Path]
+	}
+
+	return vendored, vendorPath, nil
+}
+
 // RunGoAudit checks Go modules for vulnerabilities using OSV API
 func (r *Runner) RunGoAudit(manifestPath string, manifestType string) *GoAuditResult {
 	result := &GoAuditResult{
@@ -141,45 +297,167 @@ func (r *Runner) RunGoAudit(manifestPath string, manifestType string) *GoAuditRe
 		return result
 	}
 
+	// Vendored builds compile vendor/, not whatever go.mod resolves to
+	modules, vendorPath, err := vendoredModules(manifestPath, modules)
+	if err != nil {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("ignoring vendor/modules.txt: %v", err))
+	} else if vendorPath != "" {
+		result.Vendored = []string{vendorPath}
+	}
+	if vendorPath == "" {
+		modules = r.goModulesToAudit(result, modules)
+	}
+
 	if len(modules) == 0 {
 		// No modules found
 		return result
 	}
 
-	result.ModulesScanned = len(modules)
+	if r.verbose {
+		fmt.Fprintf(os.Stderr, "Found %d modules in %s\n", len(modules), filepath.Base(manifestPath))
+	}
+
+	r.auditGoModules(result, modules, nil)
+
+	return result
+}
+
+// RunCombinedGoAudit audits the go.mod files of a repository as one module
+// set, so dependencies shared by several modules are queried and reported
+// once. Each finding lists the go.mod files that require the module.
+func (r *Runner) RunCombinedGoAudit(manifestPaths []string) *GoAuditResult {
+	result := &GoAuditResult{
+		ManifestType: "go.mod (combined)",
+	}
+	if len(manifestPaths) == 0 {
+		return result
+	}
+	result.ManifestPath = manifestPaths[0]
+	result.Manifests = manifestPaths
+
+	var modules []GoModule
+	requiredBy := make(map[string][]string)
+	index := make(map[string]int)
+	failed := 0
+
+	for _, manifestPath := range manifestPaths {
+		parsed, err := ParseGoMod(manifestPath)
+		if err != nil {
+			// One unreadable go.mod shouldn't hide findings in the others
+			result.Warnings = append(result.Warnings, fmt.Sprintf("failed to parse %s: %v", manifestPath, err))
+			failed++
+			continue
+		}
+
+		parsed, vendorPath, err := vendoredModules(manifestPath, parsed)
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("ignoring vendor/modules.txt of %s: %v", manifestPath, err))
+		} else if vendorPath != "" {
+			result.Vendored = append(result.Vendored, vendorPath)
+		}
+		if vendorPath == "" {
+			parsed = r.goModulesToAudit(result, parsed)
+		}
+
+		for _, module := range parsed {
+			key := module.Path + "@" + module.Version
+			requiredBy[key] = append(requiredBy[key], manifestPath)
+			if i, ok := index[key]; ok {
+				// Direct in any module makes it direct for the repository
+				modules[i].Indirect = modules[i].Indirect && module.Indirect
+				continue
+			}
+			index[key] = len(modules)
+			modules = append(modules, module)
+		}
+	}
+
+	if failed == len(manifestPaths) {
+		result.Error = fmt.Errorf("failed to parse any of %d go.mod files", len(manifestPaths))
+		return result
+	}
 
 	if r.verbose {
-		fmt.Printf("Found %d modules in %s\n", len(modules), filepath.Base(manifestPath))
+		fmt.Fprintf(os.Stderr, "Found %d distinct modules across %d go.mod files\n", len(modules), len(manifestPaths))
 	}
 
-	// Create OSV client
-	osvClient := osv.NewClient()
+	r.auditGoModules(result, modules, requiredBy)
 
-	// Query OSV for each module
+	return result
+}
+
+// goModulesToAudit drops the indirect requirements of a go.mod unless the
+// runner includes them, counting those dropped in result. Vendored modules
+// aren't passed through it, since a vendored build compiles all of them.
+func (r *Runner) goModulesToAudit(result *GoAuditResult, modules []GoModule) []GoModule {
+	if r.includeIndirect {
+		return modules
+	}
+
+	var direct []GoModule
 	for _, module := range modules {
-		if r.verbose {
-			fmt.Printf("  Checking %s@%s...\n", module.Path, module.Version)
+		if module.Indirect {
+			result.IndirectSkipped++
+			continue
 		}
+		direct = append(direct, module)
+	}
+	return direct
+}
+
+// auditGoModules queries OSV for modules and records their vulnerabilities in
+// result. requiredBy, when set, maps "path@version" to the go.mod files
+// requiring it.
+func (r *Runner) auditGoModules(result *GoAuditResult, modules []GoModule, requiredBy map[string][]string) {
+	modules = withoutExcluded(r, osv.Go, modules)
+	if len(modules) == 0 {
+		return
+	}
+	result.ModulesScanned = len(modules)
+	result.Dependencies = modules
 
-		// Query OSV API
-		osvPkg := osv.Package{
+	// Query OSV for each module
+	osvPkgs := make([]osv.Package, 0, len(modules))
+	for _, module := range modules {
+		osvPkgs = append(osvPkgs, osv.Package{
 			Name:      module.Path,
 			Version:   module.Version,
 			Ecosystem: osv.Go,
+		})
+	}
+	responses := r.queryPackages(osvPkgs)
+	result.Unverified = allQueriesFailed(responses)
+
+	for i, module := range modules {
+		if r.verbose {
+			fmt.Fprintf(os.Stderr, "  Checking %s@%s...\n", module.Path, module.Version)
 		}
 
-		response, err := osvClient.QueryPackage(osvPkg)
+		response, err := responses[i].Response, responses[i].Err
 		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("failed to query %s: %v", module.Path, err))
 			if r.verbose {
-				fmt.Printf("    Warning: Failed to query %s: %v\n", module.Path, err)
+				fmt.Fprintf(os.Stderr, "    Warning: Failed to query %s: %v\n", module.Path, err)
 			}
 			continue
 		}
 
+		// Withdrawn advisories no longer describe a vulnerability
+		var withdrawn int
+		response.Vulns, withdrawn = dropWithdrawn(response.Vulns)
+		result.WithdrawnSkipped += withdrawn
+
+		// Re-check matches against semver ranges locally
+		response.Vulns = r.filterAffected(osvPkgs[i], response.Vulns)
+
+		// Count advisories that alias one another as a single finding
+		response.Vulns = mergeAliasedAdvisories(response.Vulns)
+
 		// Process vulnerabilities
 		if len(response.Vulns) > 0 {
 			if r.verbose {
-				fmt.Printf("    Found %d vulnerability(ies)\n", len(response.Vulns))
+				fmt.Fprintf(os.Stderr, "    Found %d vulnerability(ies)\n", len(response.Vulns))
+				printAdvisories(response.Vulns)
 			}
 
 			for _, vuln := range response.Vulns {
@@ -194,29 +472,21 @@ func (r *Runner) RunGoAudit(manifestPath string, manifestType string) *GoAuditRe
 					Description: vuln.Summary,
 					Aliases:     vuln.Aliases,
 					Severity:    vuln.GetSeverityLevel(),
+					Published:   vuln.PublishedTime(),
+					Modified:    vuln.ModifiedTime(),
+					IsDirect:    !module.Indirect,
+					Manifests:   requiredBy[module.Path+"@"+module.Version],
 				}
 
 				result.Vulnerabilities = append(result.Vulnerabilities, goVuln)
 
-				// Update summary based on severity
-				switch goVuln.Severity {
-				case "critical":
-					result.Summary.Critical++
-				case "high":
-					result.Summary.High++
-				case "moderate", "medium":
-					result.Summary.Moderate++
-				case "low":
-					result.Summary.Low++
-				default:
-					result.Summary.High++ // Default to high
-				}
-				result.Summary.Total++
+				// Count it in the summary by severity and directness
+				goVuln.countIn(&result.Summary)
 			}
 		}
 	}
 
-	return result
+	result.Recommendations = RecommendGoFixes(result.Vulnerabilities)
 }
 
 // HasVulnerabilities returns true if the Go audit result contains vulnerabilities
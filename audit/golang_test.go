@@ -0,0 +1,334 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/brandonapol/snoop/osv"
+)
+
+func TestCombinedGoAuditQueriesSharedModulesOnce(t *testing.T) {
+	var mu sync.Mutex
+	queries := make(map[string]int)
+	server := newMockOSVServer(t, func(request osv.QueryRequest) []osv.Vulnerability {
+		mu.Lock()
+		queries[request.Package.Name]++
+		mu.Unlock()
+
+		if request.Package.Name == "golang.org/x/net" {
+			return []osv.Vulnerability{{ID: "GO-2023-2102", Summary: "HTTP/2 rapid reset"}}
+		}
+		return nil
+	})
+
+	dir := t.TempDir()
+	manifests := map[string]string{
+		"go.mod": `module example.com/app
+
+go 1.22
+
+require (
+	golang.org/x/net v0.17.0
+	golang.org/x/text v0.13.0
+)
+`,
+		"tools/go.mod": `module example.com/app/tools
+
+go 1.22
+
+require (
+	golang.org/x/net v0.17.0
+	golang.org/x/tools v0.14.0
+)
+`,
+	}
+	var paths []string
+	for name, content := range manifests {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		paths = append(paths, path)
+	}
+
+	runner := NewRunner(0, false)
+	runner.SetOSVClient(osv.NewClientWithURL(server.URL))
+
+	result := runner.RunCombinedGoAudit(paths)
+	if result.Error != nil {
+		t.Fatalf("RunCombinedGoAudit() unexpected error: %v", result.Error)
+	}
+
+	if queries["golang.org/x/net"] != 1 {
+		t.Errorf("golang.org/x/net queried %d times, expected 1", queries["golang.org/x/net"])
+	}
+	if result.ModulesScanned != 3 {
+		t.Errorf("ModulesScanned = %d, expected 3 distinct modules", result.ModulesScanned)
+	}
+	if len(result.Manifests) != 2 {
+		t.Errorf("Manifests = %v, expected both go.mod files", result.Manifests)
+	}
+
+	if len(result.Vulnerabilities) != 1 {
+		t.Fatalf("RunCombinedGoAudit() found %d vulnerabilities, expected 1: %+v", len(result.Vulnerabilities), result.Vulnerabilities)
+	}
+	if vuln := result.Vulnerabilities[0]; len(vuln.Manifests) != 2 {
+		t.Errorf("%s Manifests = %v, expected both go.mod files", vuln.ID, vuln.Manifests)
+	}
+}
+
+func TestGoAuditUsesVendoredVersions(t *testing.T) {
+	var mu sync.Mutex
+	queried := make(map[string]string)
+	server := newMockOSVServer(t, func(request osv.QueryRequest) []osv.Vulnerability {
+		mu.Lock()
+		queried[request.Package.Name] = request.Package.Version
+		mu.Unlock()
+
+		if request.Package.Name == "golang.org/x/text" {
+			return []osv.Vulnerability{{ID: "GO-2022-1059", Summary: "Denial of service via crafted Accept-Language header"}}
+		}
+		return nil
+	})
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": `module example.com/app
+
+go 1.22
+
+require (
+	github.com/pkg/errors v0.9.1
+	golang.org/x/net v0.10.0
+)
+
+replace github.com/old/dep => github.com/new/dep v1.2.0
+`,
+		// go.mod was edited without re-running go mod vendor, so the
+		// vendored x/net is not what go.mod asks for
+		"vendor/modules.txt": `# github.com/old/dep v1.0.0 => github.com/new/dep v1.2.0
+## explicit
+github.com/old/dep
+# github.com/pkg/errors v0.9.1
+## explicit
+github.com/pkg/errors
+# golang.org/x/net v0.17.0
+## explicit; go 1.17
+golang.org/x/net/html
+# golang.org/x/text v0.3.7
+## go 1.17
+golang.org/x/text/language
+# example.com/local => ../local
+example.com/local
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	runner := NewRunner(0, false)
+	runner.SetOSVClient(osv.NewClientWithURL(server.URL))
+
+	result := runner.RunGoAudit(filepath.Join(dir, "go.mod"), "go.mod")
+	if result.Error != nil {
+		t.Fatalf("RunGoAudit() unexpected error: %v", result.Error)
+	}
+
+	expected := map[string]string{
+		"github.com/new/dep":    "1.2.0",
+		"github.com/pkg/errors": "0.9.1",
+		"golang.org/x/net":      "0.17.0",
+		"golang.org/x/text":     "0.3.7",
+	}
+	for module, version := range expected {
+		if queried[module] != version {
+			t.Errorf("%s queried at %q, expected the vendored %s", module, queried[module], version)
+		}
+	}
+	if len(queried) != len(expected) {
+		t.Errorf("queried %v, expected only the vendored modules", queried)
+	}
+	if len(result.Vendored) != 1 || result.Vendored[0] != filepath.Join(dir, "vendor", "modules.txt") {
+		t.Errorf("Vendored = %v, expected vendor/modules.txt", result.Vendored)
+	}
+
+	if len(result.Vulnerabilities) != 1 {
+		t.Fatalf("RunGoAudit() found %d vulnerabilities, expected 1: %+v", len(result.Vulnerabilities), result.Vulnerabilities)
+	}
+	if vuln := result.Vulnerabilities[0]; vuln.Version != "0.3.7" || vuln.IsDirect {
+		t.Errorf("x/text finding = %+v, expected an indirect finding at the vendored 0.3.7", vuln)
+	}
+}
+
+func TestVendorFixtureDriftsFromGoMod(t *testing.T) {
+	dir := filepath.Join("..", "test-project-go-vendor")
+	required, err := ParseGoMod(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		t.Fatalf("ParseGoMod() unexpected error: %v", err)
+	}
+	vendored, err := ParseVendorModulesTxt(filepath.Join(dir, "vendor", "modules.txt"))
+	if err != nil {
+		t.Fatalf("ParseVendorModulesTxt() unexpected error: %v", err)
+	}
+
+	// The fixture's go.mod was bumped without re-vendoring, so every module
+	// it requires is vendored at another version
+	versions := make(map[string]string)
+	for _, module := range vendored {
+		versions[module.Path] = module.Version
+	}
+	for _, module := range required {
+		if vendoredVersion, ok := versions[module.Path]; !ok || vendoredVersion == module.Version {
+			t.Errorf("%s is vendored at %q, expected a version other than go.mod's %s", module.Path, vendoredVersion, module.Version)
+		}
+	}
+	if versions["golang.org/x/text"] != "0.3.0" {
+		t.Errorf("golang.org/x/text vendored at %q, expected the transitive 0.3.0", versions["golang.org/x/text"])
+	}
+}
+
+func TestParseGoModVersions(t *testing.T) {
+	goMod := filepath.Join(t.TempDir(), "go.mod")
+	content := `module example.com/app
+
+go 1.21
+
+require (
+	github.com/foo/bar/v3 v3.1.0
+	golang.org/x/crypto v0.0.0-20210921155107-089bfa567519
+	github.com/docker/docker v20.10.7+incompatible
+	github.com/old/lib v1.0.0
+	github.com/pinned/lib v1.1.0
+	github.com/local/lib v0.3.0
+	"github.com/quoted/lib" v1.0.0
+	golang.org/x/net v0.7.0 // indirect
+)
+
+replace github.com/old/lib => github.com/new/lib v1.4.2
+
+replace (
+	github.com/pinned/lib v1.0.0 => github.com/pinned/lib v1.0.1
+	github.com/pinned/lib v1.1.0 => github.com/pinned/lib v1.1.1
+	github.com/local/lib => ../lib
+)
+
+exclude github.com/foo/bar/v3 v3.0.0
+retract (
+	v1.0.0 // Published by mistake
+)
+`
+	if err := os.WriteFile(goMod, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write go.mod: %v", err)
+	}
+
+	modules, err := ParseGoMod(goMod)
+	if err != nil {
+		t.Fatalf("ParseGoMod() unexpected error: %v", err)
+	}
+
+	// +incompatible is stripped, pseudo-versions are kept, major version
+	// suffixes stay in the path, and replaced modules report the replacement
+	expected := []GoModule{
+		{Path: "github.com/foo/bar/v3", Version: "3.1.0", Line: 6},
+		{Path: "golang.org/x/crypto", Version: "0.0.0-20210921155107-089bfa567519", Line: 7},
+		{Path: "github.com/docker/docker", Version: "20.10.7", Line: 8},
+		{Path: "github.com/new/lib", Version: "1.4.2", Line: 9},
+		{Path: "github.com/pinned/lib", Version: "1.1.1", Line: 10},
+		{Path: "github.com/quoted/lib", Version: "1.0.0", Line: 12},
+		{Path: "golang.org/x/net", Version: "0.7.0", Line: 13, Indirect: true},
+	}
+	if !reflect.DeepEqual(modules, expected) {
+		t.Errorf("ParseGoMod() = %+v, expected %+v", modules, expected)
+	}
+}
+
+func TestParseGoModFromReader(t *testing.T) {
+	content := `module example.com/app
+
+go 1.22
+
+require (
+	github.com/gin-gonic/gin v1.9.1
+	golang.org/x/net v0.17.0 // indirect
+)
+
+replace github.com/gin-gonic/gin => github.com/gin-gonic/gin v1.9.0
+`
+	modules, err := ParseGoModFromReader(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("ParseGoModFromReader() unexpected error: %v", err)
+	}
+
+	expected := []GoModule{
+		{Path: "github.com/gin-gonic/gin", Version: "1.9.0", Line: 6},
+		{Path: "golang.org/x/net", Version: "0.17.0", Line: 7, Indirect: true},
+	}
+	if !reflect.DeepEqual(modules, expected) {
+		t.Errorf("ParseGoModFromReader() = %+v, expected %+v", modules, expected)
+	}
+}
+
+func TestGoAuditSkipsIndirectModulesByDefault(t *testing.T) {
+	var mu sync.Mutex
+	queried := make(map[string]bool)
+	server := newMockOSVServer(t, func(request osv.QueryRequest) []osv.Vulnerability {
+		mu.Lock()
+		queried[request.Package.Name] = true
+		mu.Unlock()
+
+		if request.Package.Name == "golang.org/x/text" {
+			return []osv.Vulnerability{{ID: "GO-2022-1059", Summary: "Denial of service via crafted Accept-Language header"}}
+		}
+		return nil
+	})
+
+	goMod := filepath.Join(t.TempDir(), "go.mod")
+	content := `module example.com/app
+
+go 1.22
+
+require (
+	github.com/pkg/errors v0.9.1
+	golang.org/x/text v0.3.7 // indirect
+)
+`
+	if err := os.WriteFile(goMod, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write go.mod: %v", err)
+	}
+
+	runner := NewRunner(0, false)
+	runner.osvClient = osv.NewClientWithURL(server.URL)
+
+	result := runner.RunGoAudit(goMod, "go.mod")
+	if result.Error != nil {
+		t.Fatalf("RunGoAudit() unexpected error: %v", result.Error)
+	}
+	if queried["golang.org/x/text"] || result.Summary.Total != 0 {
+		t.Errorf("indirect module was audited by default: queried = %v, findings = %+v", queried, result.Vulnerabilities)
+	}
+	if result.IndirectSkipped != 1 || result.ModulesScanned != 1 {
+		t.Errorf("IndirectSkipped = %d, ModulesScanned = %d, expected 1 and 1", result.IndirectSkipped, result.ModulesScanned)
+	}
+
+	runner.SetIncludeIndirect(true)
+	result = runner.RunGoAudit(goMod, "go.mod")
+	if result.Error != nil {
+		t.Fatalf("RunGoAudit() unexpected error: %v", result.Error)
+	}
+	if result.IndirectSkipped != 0 || result.Summary.Total != 1 || result.Summary.Transitive != 1 {
+		t.Errorf("with indirect modules included, IndirectSkipped = %d, summary = %+v, expected one transitive finding", result.IndirectSkipped, result.Summary)
+	}
+}
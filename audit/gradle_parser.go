@@ -0,0 +1,116 @@
+package audit
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// gradleStringDependencyRegex matches a dependency declared as a
+// group:name:version string, in Groovy such as implementation 'g:a:v' or
+// implementation("g:a:v"), and in the Kotlin DSL implementation("g:a:v")
+var gradleStringDependencyRegex = regexp.MustCompile(`^(\w+)\s*\(?\s*['"]([^'"\s]+)['"]`)
+
+// gradleMapDependencyRegex matches a dependency declared by its parts, in
+// Groovy as implementation group: 'g', name: 'a', version: 'v' and in the
+// Kotlin DSL as implementation(group = "g", name = "a", version = "v")
+var gradleMapDependencyRegex = regexp.MustCompile(`^(\w+)\s*\(?\s*group\s*[:=]\s*['"]([^'"]+)['"]\s*,\s*name\s*[:=]\s*['"]([^'"]+)['"](?:\s*,\s*version\s*[:=]\s*['"]([^'"]+)['"])?`)
+
+// gradleBlockCommentRegex matches a /* ... */ comment
+var gradleBlockCommentRegex = regexp.MustCompile(`(?s)/\*.*?\*/`)
+
+// gradleConfigurations are the dependency configurations read from a build
+// file, with the Maven scope each corresponds to
+var gradleConfigurations = map[string]string{
+	"api":                 "compile",
+	"implementation":      "compile",
+	"compile":             "compile",
+	"compileOnly":         "provided",
+	"compileOnlyApi":      "provided",
+	"runtimeOnly":         "runtime",
+	"runtime":             "runtime",
+	"annotationProcessor": "provided",
+	"kapt":                "provided",
+	"classpath":           "compile",
+	"testImplementation":  "test",
+	"testCompile":         "test",
+	"testCompileOnly":     "test",
+	"testRuntimeOnly":     "test",
+	"testRuntime":         "test",
+}
+
+// ParseGradle parses a build.gradle or build.gradle.kts file for the
+// dependencies it declares with a version. Gradle and Maven share
+// coordinates, so these are audited as Maven packages. Dependencies without a
+// literal version, such as those from a platform, a version catalog, or an
+// interpolated "$version", are skipped, as are dynamic versions like 1.+.
+func ParseGradle(path string) ([]MavenDependency, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	content := gradleBlockCommentRegex.ReplaceAllString(string(data), "")
+
+	var dependencies []MavenDependency
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "//") {
+			continue
+		}
+
+		if dep, ok := parseGradleDependency(line); ok {
+			dependencies = append(dependencies, dep)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading file: %w", err)
+	}
+
+	return dependencies, nil
+}
+
+// parseGradleDependency reads the dependency declared on a line, if any
+func parseGradleDependency(line string) (MavenDependency, bool) {
+	if matches := gradleMapDependencyRegex.FindStringSubmatch(line); matches != nil {
+		scope, ok := gradleConfigurations[matches[1]]
+		if !ok || !gradleConcreteVersion(matches[4]) {
+			return MavenDependency{}, false
+		}
+		return MavenDependency{GroupID: matches[2], ArtifactID: matches[3], Version: matches[4], Scope: scope}, true
+	}
+
+	matches := gradleStringDependencyRegex.FindStringSubmatch(line)
+	if matches == nil {
+		return MavenDependency{}, false
+	}
+	scope, ok := gradleConfigurations[matches[1]]
+	if !ok {
+		return MavenDependency{}, false
+	}
+
+	// group:name:version[:classifier][@type]
+	coordinates, packaging, _ := strings.Cut(matches[2], "@")
+	parts := strings.Split(coordinates, ":")
+	if len(parts) < 3 || len(parts) > 4 || parts[0] == "" || parts[1] == "" || !gradleConcreteVersion(parts[2]) {
+		return MavenDependency{}, false
+	}
+
+	dep := MavenDependency{GroupID: parts[0], ArtifactID: parts[1], Version: parts[2], Scope: scope, Type: packaging}
+	if len(parts) == 4 {
+		dep.Classifier = parts[3]
+	}
+	return dep, true
+}
+
+// gradleConcreteVersion reports whether version names a single release,
+// rather than being missing, interpolated, a range, or dynamic
+func gradleConcreteVersion(version string) bool {
+	if version == "" || strings.HasPrefix(version, "latest.") {
+		return false
+	}
+	return !strings.ContainsAny(version, "$+[]()")
+}
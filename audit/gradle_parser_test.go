@@ -0,0 +1,131 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseGradleGroovy(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "build.gradle")
+	content := `buildscript {
+    dependencies {
+        classpath 'com.android.tools.build:gradle:7.0.0'
+    }
+}
+
+plugins {
+    id 'java'
+}
+
+dependencies {
+    implementation 'org.apache.logging.log4j:log4j-core:2.14.1'
+    api "com.google.guava:guava:30.0-jre"
+    implementation('org.apache.commons:commons-text:1.6')
+    runtimeOnly group: 'com.h2database', name: 'h2', version: '1.4.199'
+    testImplementation 'junit:junit:4.12'
+    implementation 'io.netty:netty-tcnative:2.0.30.Final:linux-x86_64'
+    implementation 'org.webjars:jquery:3.4.1@zip'
+
+    // implementation 'org.yaml:snakeyaml:1.26'
+    /*
+    implementation 'org.yaml:snakeyaml:1.27'
+    */
+    implementation platform('org.springframework.boot:spring-boot-dependencies:2.5.0')
+    implementation 'org.springframework.boot:spring-boot-starter-web'
+    implementation "com.fasterxml.jackson.core:jackson-databind:$jacksonVersion"
+    implementation 'org.slf4j:slf4j-api:1.+'
+    implementation project(':core')
+    implementation libs.guava
+}
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write build.gradle: %v", err)
+	}
+
+	deps, err := ParseGradle(path)
+	if err != nil {
+		t.Fatalf("ParseGradle() unexpected error: %v", err)
+	}
+
+	expected := []MavenDependency{
+		{GroupID: "com.android.tools.build", ArtifactID: "gradle", Version: "7.0.0", Scope: "compile"},
+		{GroupID: "org.apache.logging.log4j", ArtifactID: "log4j-core", Version: "2.14.1", Scope: "compile"},
+		{GroupID: "com.google.guava", ArtifactID: "guava", Version: "30.0-jre", Scope: "compile"},
+		{GroupID: "org.apache.commons", ArtifactID: "commons-text", Version: "1.6", Scope: "compile"},
+		{GroupID: "com.h2database", ArtifactID: "h2", Version: "1.4.199", Scope: "runtime"},
+		{GroupID: "junit", ArtifactID: "junit", Version: "4.12", Scope: "test"},
+		{GroupID: "io.netty", ArtifactID: "netty-tcnative", Version: "2.0.30.Final", Scope: "compile", Classifier: "linux-x86_64"},
+		{GroupID: "org.webjars", ArtifactID: "jquery", Version: "3.4.1", Scope: "compile", Type: "zip"},
+	}
+	if !reflect.DeepEqual(deps, expected) {
+		t.Errorf("ParseGradle() = %+v, expected %+v", deps, expected)
+	}
+}
+
+func TestParseGradleKotlin(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "build.gradle.kts")
+	content := `plugins {
+    kotlin("jvm") version "1.9.22"
+}
+
+dependencies {
+    implementation("org.apache.logging.log4j:log4j-core:2.14.1")
+    implementation(kotlin("stdlib"))
+    compileOnly("org.projectlombok:lombok:1.18.20")
+    implementation(group = "org.springframework", name = "spring-core", version = "5.2.0.RELEASE")
+    implementation(group = "org.springframework", name = "spring-beans")
+    testImplementation("org.yaml:snakeyaml:1.26")
+    implementation(libs.guava)
+}
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write build.gradle.kts: %v", err)
+	}
+
+	deps, err := ParseGradle(path)
+	if err != nil {
+		t.Fatalf("ParseGradle() unexpected error: %v", err)
+	}
+
+	expected := []MavenDependency{
+		{GroupID: "org.apache.logging.log4j", ArtifactID: "log4j-core", Version: "2.14.1", Scope: "compile"},
+		{GroupID: "org.projectlombok", ArtifactID: "lombok", Version: "1.18.20", Scope: "provided"},
+		{GroupID: "org.springframework", ArtifactID: "spring-core", Version: "5.2.0.RELEASE", Scope: "compile"},
+		{GroupID: "org.yaml", ArtifactID: "snakeyaml", Version: "1.26", Scope: "test"},
+	}
+	if !reflect.DeepEqual(deps, expected) {
+		t.Errorf("ParseGradle() = %+v, expected %+v", deps, expected)
+	}
+}
+
+func TestParseGradleFixtures(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected int
+	}{
+		{filepath.Join("..", "test-project-gradle", "build.gradle"), 6},
+		{filepath.Join("..", "test-project-gradle-kts", "build.gradle.kts"), 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(filepath.Base(tt.path), func(t *testing.T) {
+			deps, err := ParseGradle(tt.path)
+			if err != nil {
+				t.Fatalf("ParseGradle() unexpected error: %v", err)
+			}
+			if len(deps) != tt.expected {
+				t.Errorf("ParseGradle() found %d dependencies, expected %d: %+v", len(deps), tt.expected, deps)
+			}
+		})
+	}
+}
+
+func TestParseGradleMissingFile(t *testing.T) {
+	if _, err := ParseGradle(filepath.Join(t.TempDir(), "build.gradle")); err == nil {
+		t.Error("ParseGradle() expected error for missing file")
+	}
+}
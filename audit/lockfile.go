@@ -0,0 +1,258 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// maxListedUnresolved caps how many unresolved packages a warning names
+const maxListedUnresolved = 5
+
+// packageLock is the part of package-lock.json that records resolved versions.
+// Lockfile v2/v3 list every install location under "packages"; v1 nests them
+// under "dependencies".
+type packageLock struct {
+	Packages     map[string]packageLockPackage    `json:"packages"`
+	Dependencies map[string]packageLockDependency `json:"dependencies"`
+}
+
+// packageLockPackage is an install location in lockfile v2/v3. Its
+// dependencies map names to version ranges; those of the root entry ""
+// are the ones the project declares.
+type packageLockPackage struct {
+	Version              string            `json:"version"`
+	Link                 bool              `json:"link"` // Symlink to a workspace, versioned by its target
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+}
+
+// packageLockDependency is a lockfile v1 entry, with nested entries for
+// packages installed under its own node_modules
+type packageLockDependency struct {
+	Version      string                           `json:"version"`
+	Dependencies map[string]packageLockDependency `json:"dependencies"`
+}
+
+// UnresolvedLockfilePackages returns the packages in a package-lock.json that
+// have no resolved version. Such a lockfile can't be matched against
+// advisories, so a clean audit of it proves nothing.
+func UnresolvedLockfilePackages(path string) ([]string, error) {
+	lock, err := readPackageLock(path)
+	if err != nil {
+		return nil, err
+	}
+
+	unresolved := make(map[string]bool)
+	for location, entry := range lock.Packages {
+		// "" is the root project and other paths are workspaces, neither of
+		// which needs a published version
+		idx := strings.LastIndex(location, "node_modules/")
+		if idx < 0 || entry.Link {
+			continue
+		}
+		if strings.TrimSpace(entry.Version) == "" {
+			unresolved[location[idx+len("node_modules/"):]] = true
+		}
+	}
+
+	var walk func(deps map[string]packageLockDependency)
+	walk = func(deps map[string]packageLockDependency) {
+		for name, entry := range deps {
+			if strings.TrimSpace(entry.Version) == "" {
+				unresolved[name] = true
+			}
+			walk(entry.Dependencies)
+		}
+	}
+	walk(lock.Dependencies)
+
+	names := make([]string, 0, len(unresolved))
+	for name := range unresolved {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+// ParsePackageLock returns every package installed by a package-lock.json at
+// its resolved version, sorted by name and version. Lockfile v2/v3 record what
+// the project declares, so those packages installed at the top level are
+// marked direct. Lockfile v1 doesn't, so every top-level package is.
+func ParsePackageLock(path string) ([]NpmPackage, error) {
+	lock, err := readPackageLock(path)
+	if err != nil {
+		return nil, err
+	}
+
+	root, ok := lock.Packages[""]
+	if !ok {
+		return lock.installed(func(string) bool { return true }), nil
+	}
+	return lock.installed(declaredIn(root.Dependencies, root.DevDependencies, root.OptionalDependencies)), nil
+}
+
+// LockfilePackages returns every package installed by a package-lock.json at
+// its resolved version, sorted by name and version. Packages declared in m and
+// installed at the top level are marked direct.
+func (m *PackageJSON) LockfilePackages(path string) ([]NpmPackage, error) {
+	lock, err := readPackageLock(path)
+	if err != nil {
+		return nil, err
+	}
+	return lock.installed(declaredIn(m.Dependencies, m.DevDependencies, m.OptionalDependencies)), nil
+}
+
+// declaredIn reports whether a package is named in any of deps
+func declaredIn(deps ...map[string]string) func(name string) bool {
+	return func(name string) bool {
+		for _, declared := range deps {
+			if _, ok := declared[name]; ok {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// installed lists the packages in the lockfile that have a resolved version.
+// Those installed at the top level are direct when declared says so.
+func (lock *packageLock) installed(declared func(name string) bool) []NpmPackage {
+	seen := make(map[NpmPackage]bool)
+	add := func(name, version string, topLevel bool) {
+		if strings.TrimSpace(version) == "" {
+			return
+		}
+		seen[NpmPackage{Name: name, Version: version, IsDirect: topLevel && declared(name)}] = true
+	}
+
+	for location, entry := range lock.Packages {
+		idx := strings.LastIndex(location, "node_modules/")
+		if idx < 0 || entry.Link {
+			continue
+		}
+		add(location[idx+len("node_modules/"):], entry.Version, idx == 0)
+	}
+
+	// Lockfile v2 carries both sections, so v1 entries are only read alone
+	if len(lock.Packages) == 0 {
+		var walk func(deps map[string]packageLockDependency, topLevel bool)
+		walk = func(deps map[string]packageLockDependency, topLevel bool) {
+			for name, entry := range deps {
+				add(name, entry.Version, topLevel)
+				walk(entry.Dependencies, false)
+			}
+		}
+		walk(lock.Dependencies, true)
+	}
+
+	packages := make([]NpmPackage, 0, len(seen))
+	for pkg := range seen {
+		packages = append(packages, pkg)
+	}
+	sort.Slice(packages, func(i, j int) bool {
+		if packages[i].Name != packages[j].Name {
+			return packages[i].Name < packages[j].Name
+		}
+		return packages[i].Version < packages[j].Version
+	})
+
+	return packages
+}
+
+// readPackageLock reads and decodes a package-lock.json
+func readPackageLock(path string) (*packageLock, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open package-lock.json: %w", err)
+	}
+
+	var lock packageLock
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("failed to parse package-lock.json: %w", err)
+	}
+	return &lock, nil
+}
+
+// npmDependencies lists the packages of a Node.js project from its
+// lockfile, or from the versions pinned in package.json when there is
+// no usable lockfile. Problems are left for the audit itself to report.
+func npmDependencies(packageJSONPath string) []NpmPackage {
+	manifest, err := ParsePackageJSON(packageJSONPath)
+	if err != nil {
+		return nil
+	}
+
+	packages, _ := manifest.installedPackages(packageJSONPath)
+	return packages
+}
+
+// npmLockfiles are the lockfiles installedPackages reads, in order of preference
+var npmLockfiles = []string{"package-lock.json", "yarn.lock", "pnpm-lock.yaml"}
+
+// installedPackages returns the packages in the first usable lockfile beside
+// package.json along with its name, or the versions pinned in m and "" when
+// there is none. A lockfile already reflects overrides and resolutions.
+func (m *PackageJSON) installedPackages(packageJSONPath string) ([]NpmPackage, string) {
+	dir := filepath.Dir(packageJSONPath)
+	readers := map[string]func(string) ([]NpmPackage, error){
+		"package-lock.json": m.LockfilePackages,
+		"yarn.lock":         m.YarnLockPackages,
+		"pnpm-lock.yaml":    ParsePnpmLock,
+	}
+	for _, lockfile := range npmLockfiles {
+		if packages, err := readers[lockfile](filepath.Join(dir, lockfile)); err == nil && len(packages) > 0 {
+			return packages, lockfile
+		}
+	}
+	return m.Packages(), ""
+}
+
+// hasNpmLockfile reports whether dir has a lockfile installedPackages reads
+func hasNpmLockfile(dir string) bool {
+	for _, lockfile := range npmLockfiles {
+		if _, err := os.Stat(filepath.Join(dir, lockfile)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// checkPackageLock flags the result when the package-lock.json next to
+// package.json is missing resolved versions
+func (r *Runner) checkPackageLock(result *AuditResult) {
+	lockPath := filepath.Join(filepath.Dir(result.PackageJSONPath), "package-lock.json")
+	if _, err := os.Stat(lockPath); err != nil {
+		return
+	}
+
+	unresolved, err := UnresolvedLockfilePackages(lockPath)
+	if err != nil {
+		result.Warnings = append(result.Warnings, err.Error())
+		return
+	}
+	if len(unresolved) == 0 {
+		return
+	}
+
+	result.IncompleteLockfile = true
+	listed := unresolved
+	if len(listed) > maxListedUnresolved {
+		listed = listed[:maxListedUnresolved]
+	}
+	warning := fmt.Sprintf("package-lock.json incomplete — cannot determine versions of %d package(s): %s",
+		len(unresolved), strings.Join(listed, ", "))
+	if len(unresolved) > len(listed) {
+		warning += fmt.Sprintf(" and %d more", len(unresolved)-len(listed))
+	}
+	result.Warnings = append(result.Warnings, warning)
+
+	if r.verbose {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+}
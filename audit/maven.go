@@ -2,51 +2,91 @@ package audit
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/brandonapol/snoop/osv"
 )
 
 // MavenVulnerability represents a security vulnerability in a Maven package
 type MavenVulnerability struct {
-	GroupID     string   `json:"group_id"`
-	ArtifactID  string   `json:"artifact_id"`
-	Version     string   `json:"version"`
-	ID          string   `json:"id"`
-	FixVersions []string `json:"fix_versions"`
-	Description string   `json:"description"`
-	Aliases     []string `json:"aliases"`
-	Severity    string   `json:"severity"`
+	GroupID     string    `json:"group_id"`
+	ArtifactID  string    `json:"artifact_id"`
+	Type        string    `json:"type,omitempty"`
+	Classifier  string    `json:"classifier,omitempty"`
+	Version     string    `json:"version"`
+	ID          string    `json:"id"`
+	FixVersions []string  `json:"fix_versions"`
+	Description string    `json:"description"`
+	Aliases     []string  `json:"aliases"`
+	Severity    string    `json:"severity"`
+	Published   time.Time `json:"published,omitzero"`
+	Modified    time.Time `json:"modified,omitzero"`
+	IsDirect    bool      `json:"is_direct"`
 }
 
 // MavenAuditResult contains the results of running Maven vulnerability check
 type MavenAuditResult struct {
-	ManifestPath    string
-	ManifestType    string
-	Vulnerabilities []MavenVulnerability
-	Summary         VulnerabilitySummary
-	PackagesScanned int
-	Error           error
+	ManifestPath     string
+	ManifestType     string
+	Vulnerabilities  []MavenVulnerability
+	Summary          VulnerabilitySummary
+	PackagesScanned  int
+	WithdrawnSkipped int                    // Advisories OSV has withdrawn, left out of the findings
+	Dependencies     []MavenDependency      // Every dependency in the manifest, for SBOM output
+	Unresolved       []UnresolvedDependency // Dependencies not audited because their version comes from a parent or BOM
+	Warnings         []string               // Non-fatal problems such as failed queries
+	Unverified       bool                   // Every OSV query failed, so no findings doesn't mean clean
+	Error            error
 }
 
-// RunMavenAudit checks Maven dependencies for vulnerabilities using OSV API
+// mavenParser reads the dependencies of a Maven or Gradle build file, adding
+// any warnings about the file to result
+type mavenParser func(path string, result *MavenAuditResult) ([]MavenDependency, error)
+
+func init() {
+	registerBuiltinParser("pom.xml", mavenParser(parsePomForAudit))
+	// Gradle builds name the same coordinates as Maven
+	registerBuiltinParser("build.gradle", mavenParser(parseGradleForAudit))
+	registerBuiltinParser("build.gradle.kts", mavenParser(parseGradleForAudit))
+}
+
+// parsePomForAudit reads a pom.xml, noting the dependencies whose versions
+// it couldn't resolve
+func parsePomForAudit(path string, result *MavenAuditResult) ([]MavenDependency, error) {
+	pom, err := ParsePomFile(path)
+	if err != nil {
+		return nil, err
+	}
+	result.Warnings = append(result.Warnings, pom.Warnings...)
+	result.Unresolved = pom.Unresolved
+	return pom.Dependencies, nil
+}
+
+// parseGradleForAudit reads a build.gradle or build.gradle.kts file
+func parseGradleForAudit(path string, _ *MavenAuditResult) ([]MavenDependency, error) {
+	return ParseGradle(path)
+}
+
+// RunMavenAudit checks the dependencies of a pom.xml, build.gradle, or
+// build.gradle.kts file for vulnerabilities using OSV API
 func (r *Runner) RunMavenAudit(manifestPath string, manifestType string) *MavenAuditResult {
 	result := &MavenAuditResult{
 		ManifestPath: manifestPath,
 		ManifestType: manifestType,
 	}
 
-	// Only parse pom.xml files
-	if manifestType != "pom.xml" {
+	parse, ok := lookupParser[mavenParser](manifestType)
+	if !ok {
 		return result
 	}
-
-	// Parse pom.xml file
-	dependencies, err := ParsePomXML(manifestPath)
+	declared, err := parse(manifestPath, result)
 	if err != nil {
-		result.Error = fmt.Errorf("failed to parse pom.xml: %w", err)
+		result.Error = fmt.Errorf("failed to parse %s: %w", manifestType, err)
 		return result
 	}
+	dependencies := withoutExcluded(r, osv.Maven, declared)
 
 	if len(dependencies) == 0 {
 		// No dependencies found
@@ -54,39 +94,54 @@ func (r *Runner) RunMavenAudit(manifestPath string, manifestType string) *MavenA
 	}
 
 	result.PackagesScanned = len(dependencies)
+	result.Dependencies = dependencies
 
 	if r.verbose {
-		fmt.Printf("Found %d Maven dependencies in %s\n", len(dependencies), filepath.Base(manifestPath))
+		fmt.Fprintf(os.Stderr, "Found %d Maven dependencies in %s\n", len(dependencies), filepath.Base(manifestPath))
 	}
 
-	// Create OSV client
-	osvClient := osv.NewClient()
-
 	// Query OSV for each dependency
+	osvPkgs := make([]osv.Package, 0, len(dependencies))
 	for _, dep := range dependencies {
-		if r.verbose {
-			fmt.Printf("  Checking %s@%s...\n", dep.GetMavenPackageName(), dep.Version)
-		}
-
-		// Query OSV API
-		osvPkg := osv.Package{
+		osvPkgs = append(osvPkgs, osv.Package{
 			Name:      dep.GetMavenPackageName(),
 			Version:   dep.Version,
 			Ecosystem: osv.Maven,
+		})
+	}
+	responses := r.queryPackages(osvPkgs)
+	result.Unverified = allQueriesFailed(responses)
+
+	for i, dep := range dependencies {
+		if r.verbose {
+			fmt.Fprintf(os.Stderr, "  Checking %s@%s...\n", dep.ArtifactName(), dep.Version)
 		}
 
-		response, err := osvClient.QueryPackage(osvPkg)
+		response, err := responses[i].Response, responses[i].Err
 		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("failed to query %s: %v", dep.ArtifactName(), err))
 			if r.verbose {
-				fmt.Printf("    Warning: Failed to query %s: %v\n", dep.GetMavenPackageName(), err)
+				fmt.Fprintf(os.Stderr, "    Warning: Failed to query %s: %v\n", dep.ArtifactName(), err)
 			}
 			continue
 		}
 
+		// Withdrawn advisories no longer describe a vulnerability
+		var withdrawn int
+		response.Vulns, withdrawn = dropWithdrawn(response.Vulns)
+		result.WithdrawnSkipped += withdrawn
+
+		// Re-check matches against semver ranges locally
+		response.Vulns = r.filterAffected(osvPkgs[i], response.Vulns)
+
+		// Count advisories that alias one another as a single finding
+		response.Vulns = mergeAliasedAdvisories(response.Vulns)
+
 		// Process vulnerabilities
 		if len(response.Vulns) > 0 {
 			if r.verbose {
-				fmt.Printf("    Found %d vulnerability(ies)\n", len(response.Vulns))
+				fmt.Fprintf(os.Stderr, "    Found %d vulnerability(ies)\n", len(response.Vulns))
+				printAdvisories(response.Vulns)
 			}
 
 			for _, vuln := range response.Vulns {
@@ -96,30 +151,23 @@ func (r *Runner) RunMavenAudit(manifestPath string, manifestType string) *MavenA
 				mavenVuln := MavenVulnerability{
 					GroupID:     dep.GroupID,
 					ArtifactID:  dep.ArtifactID,
+					Type:        dep.Type,
+					Classifier:  dep.Classifier,
 					Version:     dep.Version,
 					ID:          vuln.ID,
 					FixVersions: fixVersions,
 					Description: vuln.Summary,
 					Aliases:     vuln.Aliases,
 					Severity:    vuln.GetSeverityLevel(),
+					Published:   vuln.PublishedTime(),
+					Modified:    vuln.ModifiedTime(),
+					IsDirect:    true, // Declared in the manifest itself
 				}
 
 				result.Vulnerabilities = append(result.Vulnerabilities, mavenVuln)
 
-				// Update summary based on severity
-				switch mavenVuln.Severity {
-				case "critical":
-					result.Summary.Critical++
-				case "high":
-					result.Summary.High++
-				case "moderate", "medium":
-					result.Summary.Moderate++
-				case "low":
-					result.Summary.Low++
-				default:
-					result.Summary.High++ // Default to high
-				}
-				result.Summary.Total++
+				// Count it in the summary by severity and directness
+				mavenVuln.countIn(&result.Summary)
 			}
 		}
 	}
@@ -127,6 +175,12 @@ func (r *Runner) RunMavenAudit(manifestPath string, manifestType string) *MavenA
 	return result
 }
 
+// ArtifactName identifies the vulnerable artifact, including its type and
+// classifier when they differ from the plain jar
+func (v MavenVulnerability) ArtifactName() string {
+	return mavenArtifactName(v.GroupID, v.ArtifactID, v.Type, v.Classifier)
+}
+
 // HasVulnerabilities returns true if the Maven audit result contains vulnerabilities
 func (r *MavenAuditResult) HasVulnerabilities() bool {
 	return r.Summary.Total > 0
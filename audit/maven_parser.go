@@ -3,31 +3,71 @@ package audit
 import (
 	"encoding/xml"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
 )
 
+// pomPlaceholderRegex matches a ${name} property reference
+var pomPlaceholderRegex = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+// maxPropertyDepth bounds how many times property references are expanded,
+// since properties may refer to each other or form a cycle
+const maxPropertyDepth = 10
+
+// maxParentDepth bounds how many parent poms are followed up the hierarchy
+const maxParentDepth = 10
+
 // MavenDependency represents a Maven dependency from pom.xml
 type MavenDependency struct {
 	GroupID    string
 	ArtifactID string
 	Version    string
 	Scope      string
+	Type       string // Packaging such as jar or test-jar; empty means jar
+	Classifier string // Variant of the artifact, such as linux-x86_64 or sources
+	Optional   bool   // Not passed on to projects that depend on this one
 }
 
 // PomProject represents the root element of a pom.xml file
 type PomProject struct {
-	XMLName       xml.Name          `xml:"project"`
-	Dependencies  PomDependencies   `xml:"dependencies"`
-	Parent        *PomParent        `xml:"parent"`
-	Properties    map[string]string `xml:"-"`
-	PropertiesRaw xml.Name          `xml:"properties"`
+	XMLName              xml.Name                `xml:"project"`
+	GroupID              string                  `xml:"groupId"`
+	ArtifactID           string                  `xml:"artifactId"`
+	Version              string                  `xml:"version"`
+	Dependencies         PomDependencies         `xml:"dependencies"`
+	DependencyManagement PomDependencyManagement `xml:"dependencyManagement"`
+	Parent               *PomParent              `xml:"parent"`
+	Properties           map[string]string       `xml:"-"`
+	PropertiesRaw        PomProperties           `xml:"properties"`
+}
+
+// PomDependencyManagement represents the dependencyManagement section, which
+// sets versions for dependencies declared without one and imports BOMs
+type PomDependencyManagement struct {
+	Dependencies PomDependencies `xml:"dependencies"`
+}
+
+// PomProperties represents the properties section, whose element names are
+// the property names
+type PomProperties struct {
+	Entries []PomProperty `xml:",any"`
+}
+
+// PomProperty represents a single <name>value</name> property
+type PomProperty struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
 }
 
 // PomParent represents the parent section of a pom.xml
 type PomParent struct {
-	GroupID    string `xml:"groupId"`
-	ArtifactID string `xml:"artifactId"`
-	Version    string `xml:"version"`
+	GroupID      string  `xml:"groupId"`
+	ArtifactID   string  `xml:"artifactId"`
+	Version      string  `xml:"version"`
+	RelativePath *string `xml:"relativePath"` // Nil means ../pom.xml; empty disables the lookup on disk
 }
 
 // PomDependencies represents the dependencies section
@@ -41,11 +81,140 @@ type PomDependency struct {
 	ArtifactID string `xml:"artifactId"`
 	Version    string `xml:"version"`
 	Scope      string `xml:"scope"`
+	Type       string `xml:"type"`
+	Classifier string `xml:"classifier"`
+	Optional   bool   `xml:"optional"`
+}
+
+// PomFile contains everything extracted from a pom.xml file
+type PomFile struct {
+	Dependencies []MavenDependency
+	Unresolved   []UnresolvedDependency // Dependencies skipped because their version isn't in this pom
+	Warnings     []string               // Dependencies skipped over unresolved placeholders or versions
+}
+
+// UnresolvedDependency is a dependency left out of the audit because its
+// version is inherited from a parent or BOM, or set by a property this pom
+// doesn't define
+type UnresolvedDependency struct {
+	GroupID    string
+	ArtifactID string
+	Reference  string // Where the version comes from, such as ${spring.version} or a parent's coordinates; empty if nowhere
 }
 
 // ParsePomXML parses a pom.xml file and extracts dependencies
 func ParsePomXML(filepath string) ([]MavenDependency, error) {
-	file, err := os.Open(filepath)
+	pom, err := ParsePomFile(filepath)
+	if err != nil {
+		return nil, err
+	}
+	return pom.Dependencies, nil
+}
+
+// ParsePomXMLFromReader parses the contents of a pom.xml file read from r and
+// extracts dependencies. Placeholders are resolved from the pom's own
+// properties and coordinates and from environment variables; with no file to
+// resolve <relativePath> against, parent poms aren't read.
+func ParsePomXMLFromReader(r io.Reader) ([]MavenDependency, error) {
+	project, err := decodePomProject(r)
+	if err != nil {
+		return nil, err
+	}
+	return project.resolve(nil, nil).Dependencies, nil
+}
+
+// ParsePomFile parses a pom.xml file, resolving ${...} placeholders in
+// dependency coordinates from <properties>, the project's own coordinates,
+// and ${env.NAME} environment variables. Dependencies left with unresolved
+// placeholders are skipped with a warning, since they can't be matched
+// against advisories.
+//
+// Parent poms found on disk through <relativePath>, as in a multi-module
+// build, contribute their properties and <dependencyManagement> versions,
+// even when they lie outside the scanned directory.
+func ParsePomFile(path string) (*PomFile, error) {
+	return ParsePomFileWithManaged(path, nil)
+}
+
+// ParsePomFileWithManaged parses a pom.xml file like ParsePomFile, taking
+// versions from managed, keyed by artifact name such as
+// com.fasterxml.jackson.core:jackson-databind, for dependencies that neither
+// the pom nor its parents manage
+func ParsePomFileWithManaged(path string, managed map[string]string) (*PomFile, error) {
+	project, err := readPomProject(path)
+	if err != nil {
+		return nil, err
+	}
+	return project.resolve(parentPoms(path, project), managed), nil
+}
+
+// resolve returns the pom's dependencies, with placeholders and inherited
+// versions resolved from its ancestors, nearest first, and then from managed
+func (p *PomProject) resolve(ancestors []*PomProject, managed map[string]string) *PomFile {
+	p.inheritProperties(ancestors)
+	versions, boms := p.managedVersions(ancestors)
+	for artifact, version := range managed {
+		if _, ok := versions[artifact]; !ok {
+			versions[artifact] = version
+		}
+	}
+
+	pom := &PomFile{}
+	for _, dep := range p.Dependencies.Dependency {
+		// Type and classifier only tell artifacts apart and don't affect
+		// matching, so placeholders left in them, such as the classifier
+		// ${os.detected.classifier} set by a build extension, are kept as is
+		dep.Type, _ = p.interpolate(dep.Type)
+		dep.Classifier, _ = p.interpolate(dep.Classifier)
+
+		var unresolved []string
+		for _, field := range []*string{&dep.GroupID, &dep.ArtifactID} {
+			var missing []string
+			*field, missing = p.interpolate(*field)
+			unresolved = append(unresolved, missing...)
+		}
+
+		// A dependency without a version takes the one dependencyManagement
+		// sets for it, or one from the parent or an imported BOM otherwise
+		if dep.Version == "" && len(unresolved) == 0 {
+			dep.Version = versions[mavenArtifactName(dep.GroupID, dep.ArtifactID, dep.Type, dep.Classifier)]
+			if dep.Version == "" {
+				reference := p.versionSource(ancestors, boms)
+				pom.Unresolved = append(pom.Unresolved, UnresolvedDependency{GroupID: dep.GroupID, ArtifactID: dep.ArtifactID, Reference: reference})
+				if reference == "" {
+					pom.Warnings = append(pom.Warnings, fmt.Sprintf("skipped %s:%s: no version", dep.GroupID, dep.ArtifactID))
+				} else {
+					pom.Warnings = append(pom.Warnings, fmt.Sprintf("skipped %s:%s: version managed by %s", dep.GroupID, dep.ArtifactID, reference))
+				}
+				continue
+			}
+		}
+
+		version, missing := p.interpolate(dep.Version)
+		if len(missing) > 0 && len(unresolved) == 0 {
+			pom.Unresolved = append(pom.Unresolved, UnresolvedDependency{GroupID: dep.GroupID, ArtifactID: dep.ArtifactID, Reference: strings.Join(missing, ", ")})
+		}
+		dep.Version = version
+		unresolved = append(unresolved, missing...)
+		if len(unresolved) > 0 {
+			pom.Warnings = append(pom.Warnings, fmt.Sprintf("skipped %s:%s: unresolved %s",
+				dep.GroupID, dep.ArtifactID, strings.Join(unresolved, ", ")))
+			continue
+		}
+
+		// Skip test and provided scope dependencies (optional - could include these)
+		// For now, we'll include all dependencies to be thorough
+		mavenDep := MavenDependency(dep)
+
+		pom.Dependencies = append(pom.Dependencies, mavenDep)
+	}
+
+	return pom
+}
+
+// readPomProject decodes a pom.xml and collects its properties
+func readPomProject(path string) (project *PomProject, err error) {
+	file, err := os.Open(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open pom.xml: %w", err)
 	}
@@ -55,30 +224,209 @@ func ParsePomXML(filepath string) ([]MavenDependency, error) {
 		}
 	}()
 
-	var project PomProject
-	decoder := xml.NewDecoder(file)
-	if err := decoder.Decode(&project); err != nil {
+	return decodePomProject(file)
+}
+
+// decodePomProject decodes a pom.xml read from r and collects its properties
+func decodePomProject(r io.Reader) (*PomProject, error) {
+	project := &PomProject{}
+	decoder := xml.NewDecoder(r)
+	if err := decoder.Decode(project); err != nil {
 		return nil, fmt.Errorf("failed to parse pom.xml: %w", err)
 	}
 
-	var dependencies []MavenDependency
-	for _, dep := range project.Dependencies.Dependency {
-		// Skip dependencies without version (managed by parent or BOM)
-		if dep.Version == "" {
+	project.Properties = make(map[string]string, len(project.PropertiesRaw.Entries))
+	for _, property := range project.PropertiesRaw.Entries {
+		project.Properties[property.XMLName.Local] = strings.TrimSpace(property.Value)
+	}
+	return project, nil
+}
+
+// parentPoms returns the parent poms of the pom at path that can be found on
+// disk, nearest first. Each <parent> is looked for at its <relativePath>,
+// ../pom.xml by default. The chain ends at a parent that is missing or whose
+// coordinates don't match, since Maven would fetch that one from a repository.
+func parentPoms(path string, project *PomProject) []*PomProject {
+	var ancestors []*PomProject
+	visited := map[string]bool{filepath.Clean(path): true}
+	for project.Parent != nil && len(ancestors) < maxParentDepth {
+		parentPath, ok := project.Parent.pomPath(path)
+		if !ok || visited[parentPath] {
+			break
+		}
+		visited[parentPath] = true
+
+		parent, err := readPomProject(parentPath)
+		if err != nil || parent.ArtifactID != project.Parent.ArtifactID || parent.groupID() != project.Parent.GroupID {
+			break
+		}
+		ancestors = append(ancestors, parent)
+		path, project = parentPath, parent
+	}
+	return ancestors
+}
+
+// pomPath returns where the parent pom of the pom at childPath is on disk
+func (p *PomParent) pomPath(childPath string) (string, bool) {
+	relativePath := "../pom.xml"
+	if p.RelativePath != nil {
+		relativePath = strings.TrimSpace(*p.RelativePath)
+	}
+	if relativePath == "" {
+		return "", false
+	}
+
+	parentPath := filepath.Join(filepath.Dir(childPath), filepath.FromSlash(relativePath))
+	if info, err := os.Stat(parentPath); err == nil && info.IsDir() {
+		parentPath = filepath.Join(parentPath, "pom.xml")
+	}
+	return filepath.Clean(parentPath), true
+}
+
+// groupID returns the project's groupId, which it inherits from its parent
+// when it doesn't declare one
+func (p *PomProject) groupID() string {
+	if p.GroupID == "" && p.Parent != nil {
+		return p.Parent.GroupID
+	}
+	return p.GroupID
+}
+
+// inheritProperties adds the properties of ancestors, nearest first, that the
+// project doesn't define itself
+func (p *PomProject) inheritProperties(ancestors []*PomProject) {
+	for _, ancestor := range ancestors {
+		for name, value := range ancestor.Properties {
+			if _, ok := p.Properties[name]; !ok {
+				p.Properties[name] = value
+			}
+		}
+	}
+}
+
+// managedVersions returns the versions the dependencyManagement sections of
+// the project and its ancestors set, keyed by artifact name, along with the
+// coordinates of the BOMs they import. The nearest pom's version wins, and
+// placeholders resolve against the project as they do in Maven.
+func (p *PomProject) managedVersions(ancestors []*PomProject) (map[string]string, []string) {
+	managed := make(map[string]string)
+	var boms []string
+	var entries []PomDependency
+	for i := len(ancestors) - 1; i >= 0; i-- {
+		entries = append(entries, ancestors[i].DependencyManagement.Dependencies.Dependency...)
+	}
+	entries = append(entries, p.DependencyManagement.Dependencies.Dependency...)
+
+	for _, dep := range entries {
+		groupID, _ := p.interpolate(dep.GroupID)
+		artifactID, _ := p.interpolate(dep.ArtifactID)
+		version, _ := p.interpolate(dep.Version)
+
+		if dep.Scope == "import" {
+			boms = append(boms, groupID+":"+artifactID+":"+version)
 			continue
 		}
+		packaging, _ := p.interpolate(dep.Type)
+		classifier, _ := p.interpolate(dep.Classifier)
+		managed[mavenArtifactName(groupID, artifactID, packaging, classifier)] = version
+	}
+	return managed, boms
+}
 
-		// Skip test and provided scope dependencies (optional - could include these)
-		// For now, we'll include all dependencies to be thorough
-		mavenDep := MavenDependency(dep)
+// versionSource describes where a dependency declared without a version gets
+// it from when neither this pom nor its parents on disk say: the imported
+// BOMs or the first parent that couldn't be read. It is empty when there are
+// neither.
+func (p *PomProject) versionSource(ancestors []*PomProject, boms []string) string {
+	var sources []string
+	for _, bom := range boms {
+		sources = append(sources, "BOM "+bom)
+	}
+	top := p
+	if len(ancestors) > 0 {
+		top = ancestors[len(ancestors)-1]
+	}
+	if parent := top.Parent; parent != nil {
+		sources = append(sources, fmt.Sprintf("parent %s:%s:%s", parent.GroupID, parent.ArtifactID, parent.Version))
+	}
+	return strings.Join(sources, " or ")
+}
 
-		dependencies = append(dependencies, mavenDep)
+// interpolate expands the ${...} placeholders in value and returns any it
+// couldn't resolve
+func (p *PomProject) interpolate(value string) (string, []string) {
+	for range maxPropertyDepth {
+		expanded := pomPlaceholderRegex.ReplaceAllStringFunc(value, func(placeholder string) string {
+			if resolved, ok := p.lookupProperty(placeholder[2 : len(placeholder)-1]); ok {
+				return resolved
+			}
+			return placeholder
+		})
+		if expanded == value {
+			break
+		}
+		value = expanded
 	}
+	return value, pomPlaceholderRegex.FindAllString(value, -1)
+}
 
-	return dependencies, nil
+// lookupProperty resolves a property name the way Maven does for the common
+// cases: environment variables, the project's coordinates, then <properties>
+func (p *PomProject) lookupProperty(name string) (string, bool) {
+	if envName, ok := strings.CutPrefix(name, "env."); ok {
+		return os.LookupEnv(envName)
+	}
+
+	var value string
+	switch name {
+	case "project.groupId", "pom.groupId":
+		value = p.GroupID
+		if value == "" && p.Parent != nil {
+			value = p.Parent.GroupID
+		}
+	case "project.version", "pom.version", "version":
+		value = p.Version
+		if value == "" && p.Parent != nil {
+			value = p.Parent.Version
+		}
+	case "project.parent.version":
+		if p.Parent != nil {
+			value = p.Parent.Version
+		}
+	default:
+		value, ok := p.Properties[name]
+		return value, ok
+	}
+	return value, value != ""
 }
 
-// GetMavenPackageName returns the package name in Maven format (groupId:artifactId)
+// GetMavenPackageName returns the package name in Maven format
+// (groupId:artifactId). This is the name OSV knows the package by; every type
+// and classifier of the artifact shares its advisories.
 func (d *MavenDependency) GetMavenPackageName() string {
 	return fmt.Sprintf("%s:%s", d.GroupID, d.ArtifactID)
 }
+
+// ArtifactName identifies the artifact, including its type and classifier
+// when they differ from the plain jar, such as
+// io.netty:netty-tcnative:jar:linux-x86_64
+func (d *MavenDependency) ArtifactName() string {
+	return mavenArtifactName(d.GroupID, d.ArtifactID, d.Type, d.Classifier)
+}
+
+// mavenArtifactName formats groupId:artifactId[:type[:classifier]] the way
+// Maven writes coordinates, leaving out the default jar type when there is
+// no classifier
+func mavenArtifactName(groupID, artifactID, packaging, classifier string) string {
+	name := groupID + ":" + artifactID
+	if packaging == "" {
+		packaging = "jar"
+	}
+	if packaging != "jar" || classifier != "" {
+		name += ":" + packaging
+	}
+	if classifier != "" {
+		name += ":" + classifier
+	}
+	return name
+}
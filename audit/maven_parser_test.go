@@ -0,0 +1,423 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParsePomFileInterpolatesPlaceholders(t *testing.T) {
+	t.Setenv("LIB_VERSION", "2.14.1")
+
+	tmpDir := t.TempDir()
+	pomPath := filepath.Join(tmpDir, "pom.xml")
+	content := `<?xml version="1.0" encoding="UTF-8"?>
+<project>
+  <groupId>com.example</groupId>
+  <artifactId>app</artifactId>
+  <version>1.4.0</version>
+  <properties>
+    <jackson.version>2.15.2</jackson.version>
+    <log4j.version>${env.LIB_VERSION}</log4j.version>
+  </properties>
+  <dependencies>
+    <dependency>
+      <groupId>org.apache.logging.log4j</groupId>
+      <artifactId>log4j-core</artifactId>
+      <version>${env.LIB_VERSION}</version>
+    </dependency>
+    <dependency>
+      <groupId>org.apache.logging.log4j</groupId>
+      <artifactId>log4j-api</artifactId>
+      <version>${log4j.version}</version>
+    </dependency>
+    <dependency>
+      <groupId>com.fasterxml.jackson.core</groupId>
+      <artifactId>jackson-databind</artifactId>
+      <version>${jackson.version}</version>
+    </dependency>
+    <dependency>
+      <groupId>${project.groupId}</groupId>
+      <artifactId>app-common</artifactId>
+      <version>${project.version}</version>
+    </dependency>
+    <dependency>
+      <groupId>org.yaml</groupId>
+      <artifactId>snakeyaml</artifactId>
+      <version>${env.SNOOP_TEST_UNSET_VERSION}</version>
+    </dependency>
+  </dependencies>
+</project>
+`
+	if err := os.WriteFile(pomPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write pom.xml: %v", err)
+	}
+
+	pom, err := ParsePomFile(pomPath)
+	if err != nil {
+		t.Fatalf("ParsePomFile() unexpected error: %v", err)
+	}
+
+	expected := []MavenDependency{
+		{GroupID: "org.apache.logging.log4j", ArtifactID: "log4j-core", Version: "2.14.1"},
+		{GroupID: "org.apache.logging.log4j", ArtifactID: "log4j-api", Version: "2.14.1"},
+		{GroupID: "com.fasterxml.jackson.core", ArtifactID: "jackson-databind", Version: "2.15.2"},
+		{GroupID: "com.example", ArtifactID: "app-common", Version: "1.4.0"},
+	}
+	if !reflect.DeepEqual(pom.Dependencies, expected) {
+		t.Errorf("ParsePomFile() dependencies = %+v, expected %+v", pom.Dependencies, expected)
+	}
+
+	expectedWarnings := []string{"skipped org.yaml:snakeyaml: unresolved ${env.SNOOP_TEST_UNSET_VERSION}"}
+	if !reflect.DeepEqual(pom.Warnings, expectedWarnings) {
+		t.Errorf("ParsePomFile() warnings = %q, expected %q", pom.Warnings, expectedWarnings)
+	}
+}
+
+func TestParsePomXMLFromReader(t *testing.T) {
+	// The parent can't be looked for without a file, so versions it would
+	// manage stay unresolved
+	content := `<project>
+  <parent>
+    <groupId>com.example</groupId>
+    <artifactId>parent</artifactId>
+    <version>1.0.0</version>
+  </parent>
+  <artifactId>app</artifactId>
+  <properties>
+    <jackson.version>2.15.2</jackson.version>
+  </properties>
+  <dependencies>
+    <dependency>
+      <groupId>com.fasterxml.jackson.core</groupId>
+      <artifactId>jackson-databind</artifactId>
+      <version>${jackson.version}</version>
+    </dependency>
+    <dependency>
+      <groupId>${project.groupId}</groupId>
+      <artifactId>app-common</artifactId>
+      <version>${project.version}</version>
+    </dependency>
+    <dependency>
+      <groupId>org.yaml</groupId>
+      <artifactId>snakeyaml</artifactId>
+    </dependency>
+  </dependencies>
+</project>
+`
+	dependencies, err := ParsePomXMLFromReader(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("ParsePomXMLFromReader() unexpected error: %v", err)
+	}
+
+	expected := []MavenDependency{
+		{GroupID: "com.fasterxml.jackson.core", ArtifactID: "jackson-databind", Version: "2.15.2"},
+		{GroupID: "com.example", ArtifactID: "app-common", Version: "1.0.0"},
+	}
+	if !reflect.DeepEqual(dependencies, expected) {
+		t.Errorf("ParsePomXMLFromReader() = %+v, expected %+v", dependencies, expected)
+	}
+}
+
+func TestParsePomFileReadsClassifierTypeAndOptional(t *testing.T) {
+	tmpDir := t.TempDir()
+	pomPath := filepath.Join(tmpDir, "pom.xml")
+	content := `<?xml version="1.0" encoding="UTF-8"?>
+<project>
+  <groupId>com.example</groupId>
+  <artifactId>app</artifactId>
+  <version>1.0.0</version>
+  <dependencies>
+    <dependency>
+      <groupId>io.netty</groupId>
+      <artifactId>netty-tcnative-boringssl-static</artifactId>
+      <version>2.0.46.Final</version>
+      <classifier>linux-x86_64</classifier>
+    </dependency>
+    <dependency>
+      <groupId>io.netty</groupId>
+      <artifactId>netty-tcnative-boringssl-static</artifactId>
+      <version>2.0.46.Final</version>
+      <classifier>${os.detected.classifier}</classifier>
+    </dependency>
+    <dependency>
+      <groupId>com.example</groupId>
+      <artifactId>app-core</artifactId>
+      <version>1.0.0</version>
+      <type>test-jar</type>
+      <scope>test</scope>
+    </dependency>
+    <dependency>
+      <groupId>com.h2database</groupId>
+      <artifactId>h2</artifactId>
+      <version>1.4.200</version>
+      <optional>true</optional>
+    </dependency>
+  </dependencies>
+</project>
+`
+	if err := os.WriteFile(pomPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write pom.xml: %v", err)
+	}
+
+	pom, err := ParsePomFile(pomPath)
+	if err != nil {
+		t.Fatalf("ParsePomFile() unexpected error: %v", err)
+	}
+
+	expected := []MavenDependency{
+		{GroupID: "io.netty", ArtifactID: "netty-tcnative-boringssl-static", Version: "2.0.46.Final", Classifier: "linux-x86_64"},
+		{GroupID: "io.netty", ArtifactID: "netty-tcnative-boringssl-static", Version: "2.0.46.Final", Classifier: "${os.detected.classifier}"},
+		{GroupID: "com.example", ArtifactID: "app-core", Version: "1.0.0", Scope: "test", Type: "test-jar"},
+		{GroupID: "com.h2database", ArtifactID: "h2", Version: "1.4.200", Optional: true},
+	}
+	if !reflect.DeepEqual(pom.Dependencies, expected) {
+		t.Fatalf("ParsePomFile() dependencies = %+v, expected %+v", pom.Dependencies, expected)
+	}
+
+	// Each classifier is its own artifact, but all share the OSV package name
+	names := []string{
+		"io.netty:netty-tcnative-boringssl-static:jar:linux-x86_64",
+		"io.netty:netty-tcnative-boringssl-static:jar:${os.detected.classifier}",
+		"com.example:app-core:test-jar",
+		"com.h2database:h2",
+	}
+	for i, dep := range pom.Dependencies {
+		if got := dep.ArtifactName(); got != names[i] {
+			t.Errorf("ArtifactName() = %q, expected %q", got, names[i])
+		}
+	}
+	if got := pom.Dependencies[0].GetMavenPackageName(); got != "io.netty:netty-tcnative-boringssl-static" {
+		t.Errorf("GetMavenPackageName() = %q, expected the classifier left out", got)
+	}
+}
+
+func TestParsePomFileResolvesManagedVersions(t *testing.T) {
+	tmpDir := t.TempDir()
+	pomPath := filepath.Join(tmpDir, "pom.xml")
+	content := `<?xml version="1.0" encoding="UTF-8"?>
+<project>
+  <parent>
+    <groupId>org.springframework.boot</groupId>
+    <artifactId>spring-boot-starter-parent</artifactId>
+    <version>3.1.0</version>
+  </parent>
+  <artifactId>app</artifactId>
+  <version>2.0.0</version>
+  <properties>
+    <lombok.version>1.18.28</lombok.version>
+    <jackson.version>2.15.2</jackson.version>
+  </properties>
+  <dependencyManagement>
+    <dependencies>
+      <dependency>
+        <groupId>com.fasterxml.jackson.core</groupId>
+        <artifactId>jackson-databind</artifactId>
+        <version>${jackson.version}</version>
+      </dependency>
+      <dependency>
+        <groupId>software.amazon.awssdk</groupId>
+        <artifactId>bom</artifactId>
+        <version>2.20.0</version>
+        <type>pom</type>
+        <scope>import</scope>
+      </dependency>
+    </dependencies>
+  </dependencyManagement>
+  <dependencies>
+    <dependency>
+      <groupId>org.projectlombok</groupId>
+      <artifactId>lombok</artifactId>
+      <version>${lombok.version}</version>
+      <scope>provided</scope>
+    </dependency>
+    <dependency>
+      <groupId>com.fasterxml.jackson.core</groupId>
+      <artifactId>jackson-databind</artifactId>
+    </dependency>
+    <dependency>
+      <groupId>org.springframework.boot</groupId>
+      <artifactId>spring-boot-starter-web</artifactId>
+    </dependency>
+    <dependency>
+      <groupId>org.hibernate</groupId>
+      <artifactId>hibernate-core</artifactId>
+      <version>${hibernate.version}</version>
+    </dependency>
+  </dependencies>
+</project>
+`
+	if err := os.WriteFile(pomPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write pom.xml: %v", err)
+	}
+
+	pom, err := ParsePomFile(pomPath)
+	if err != nil {
+		t.Fatalf("ParsePomFile() unexpected error: %v", err)
+	}
+
+	expected := []MavenDependency{
+		{GroupID: "org.projectlombok", ArtifactID: "lombok", Version: "1.18.28", Scope: "provided"},
+		{GroupID: "com.fasterxml.jackson.core", ArtifactID: "jackson-databind", Version: "2.15.2"},
+	}
+	if !reflect.DeepEqual(pom.Dependencies, expected) {
+		t.Errorf("ParsePomFile() dependencies = %+v, expected %+v", pom.Dependencies, expected)
+	}
+
+	expectedUnresolved := []UnresolvedDependency{
+		{
+			GroupID:    "org.springframework.boot",
+			ArtifactID: "spring-boot-starter-web",
+			Reference:  "BOM software.amazon.awssdk:bom:2.20.0 or parent org.springframework.boot:spring-boot-starter-parent:3.1.0",
+		},
+		{GroupID: "org.hibernate", ArtifactID: "hibernate-core", Reference: "${hibernate.version}"},
+	}
+	if !reflect.DeepEqual(pom.Unresolved, expectedUnresolved) {
+		t.Errorf("ParsePomFile() unresolved = %+v, expected %+v", pom.Unresolved, expectedUnresolved)
+	}
+	if len(pom.Warnings) != 2 {
+		t.Errorf("ParsePomFile() warnings = %q, expected one per unresolved dependency", pom.Warnings)
+	}
+}
+
+func TestParsePomFileInheritsFromParentPoms(t *testing.T) {
+	// platform/ sits outside the scanned repo and is reached by <relativePath>
+	root := t.TempDir()
+	files := map[string]string{
+		"platform/pom.xml": `<project>
+  <parent>
+    <groupId>org.springframework.boot</groupId>
+    <artifactId>spring-boot-starter-parent</artifactId>
+    <version>3.1.0</version>
+  </parent>
+  <groupId>com.example</groupId>
+  <artifactId>platform</artifactId>
+  <version>1.0.0</version>
+  <properties>
+    <guava.version>31.1-jre</guava.version>
+  </properties>
+  <dependencyManagement>
+    <dependencies>
+      <dependency>
+        <groupId>com.google.guava</groupId>
+        <artifactId>guava</artifactId>
+        <version>${guava.version}</version>
+      </dependency>
+      <dependency>
+        <groupId>org.yaml</groupId>
+        <artifactId>snakeyaml</artifactId>
+        <version>1.33</version>
+      </dependency>
+    </dependencies>
+  </dependencyManagement>
+</project>
+`,
+		"repo/pom.xml": `<project>
+  <parent>
+    <groupId>com.example</groupId>
+    <artifactId>platform</artifactId>
+    <version>1.0.0</version>
+    <relativePath>../platform/pom.xml</relativePath>
+  </parent>
+  <artifactId>reactor</artifactId>
+  <packaging>pom</packaging>
+  <modules>
+    <module>service</module>
+  </modules>
+  <properties>
+    <jackson.version>2.15.2</jackson.version>
+  </properties>
+  <dependencyManagement>
+    <dependencies>
+      <dependency>
+        <groupId>com.fasterxml.jackson.core</groupId>
+        <artifactId>jackson-databind</artifactId>
+        <version>${jackson.version}</version>
+      </dependency>
+      <dependency>
+        <groupId>org.yaml</groupId>
+        <artifactId>snakeyaml</artifactId>
+        <version>2.0</version>
+      </dependency>
+    </dependencies>
+  </dependencyManagement>
+</project>
+`,
+		"repo/service/pom.xml": `<project>
+  <parent>
+    <groupId>com.example</groupId>
+    <artifactId>reactor</artifactId>
+    <version>1.0.0</version>
+  </parent>
+  <artifactId>service</artifactId>
+  <properties>
+    <jackson.version>2.16.0</jackson.version>
+  </properties>
+  <dependencies>
+    <dependency>
+      <groupId>com.fasterxml.jackson.core</groupId>
+      <artifactId>jackson-databind</artifactId>
+    </dependency>
+    <dependency>
+      <groupId>org.yaml</groupId>
+      <artifactId>snakeyaml</artifactId>
+    </dependency>
+    <dependency>
+      <groupId>com.google.guava</groupId>
+      <artifactId>guava</artifactId>
+    </dependency>
+    <dependency>
+      <groupId>org.springframework.boot</groupId>
+      <artifactId>spring-boot-starter-web</artifactId>
+    </dependency>
+  </dependencies>
+</project>
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	pom, err := ParsePomFile(filepath.Join(root, "repo", "service", "pom.xml"))
+	if err != nil {
+		t.Fatalf("ParsePomFile() unexpected error: %v", err)
+	}
+
+	// The child's own property and the nearest parent's managed version win
+	expected := []MavenDependency{
+		{GroupID: "com.fasterxml.jackson.core", ArtifactID: "jackson-databind", Version: "2.16.0"},
+		{GroupID: "org.yaml", ArtifactID: "snakeyaml", Version: "2.0"},
+		{GroupID: "com.google.guava", ArtifactID: "guava", Version: "31.1-jre"},
+	}
+	if !reflect.DeepEqual(pom.Dependencies, expected) {
+		t.Errorf("ParsePomFile() dependencies = %+v, expected %+v", pom.Dependencies, expected)
+	}
+
+	// Only the parent that isn't on disk is left to supply a version
+	expectedUnresolved := []UnresolvedDependency{{
+		GroupID:    "org.springframework.boot",
+		ArtifactID: "spring-boot-starter-web",
+		Reference:  "parent org.springframework.boot:spring-boot-starter-parent:3.1.0",
+	}}
+	if !reflect.DeepEqual(pom.Unresolved, expectedUnresolved) {
+		t.Errorf("ParsePomFile() unresolved = %+v, expected %+v", pom.Unresolved, expectedUnresolved)
+	}
+
+	// Versions passed in fill what the hierarchy leaves open
+	pom, err = ParsePomFileWithManaged(filepath.Join(root, "repo", "service", "pom.xml"),
+		map[string]string{"org.springframework.boot:spring-boot-starter-web": "3.1.0"})
+	if err != nil {
+		t.Fatalf("ParsePomFileWithManaged() unexpected error: %v", err)
+	}
+	if len(pom.Dependencies) != 4 || len(pom.Unresolved) != 0 {
+		t.Errorf("ParsePomFileWithManaged() dependencies = %+v, unresolved = %+v, expected all four resolved", pom.Dependencies, pom.Unresolved)
+	}
+}
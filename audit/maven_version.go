@@ -0,0 +1,160 @@
+package audit
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// mavenQualifierRanks orders the well-known Maven version qualifiers. A
+// release ("", ga, final, release) sorts after its pre-releases and before its
+// service packs; unknown qualifiers sort after all of these.
+var mavenQualifierRanks = map[string]int{
+	"alpha":     0,
+	"a":         0,
+	"beta":      1,
+	"b":         1,
+	"milestone": 2,
+	"m":         2,
+	"rc":        3,
+	"cr":        3,
+	"snapshot":  4,
+	"":          5,
+	"ga":        5,
+	"final":     5,
+	"release":   5,
+	"sp":        6,
+}
+
+// mavenUnknownQualifierRank is the rank of qualifiers Maven doesn't know
+const mavenUnknownQualifierRank = 7
+
+// mavenVersionItem is one part of a Maven version: a number or a qualifier
+type mavenVersionItem struct {
+	isNumber  bool
+	number    int
+	qualifier string
+}
+
+// parseMavenVersion splits a Maven version such as "2.13.4.2" or
+// "5.3.0-RC1" into its numbers and qualifiers. Items end at a dot, a dash, or
+// a change between digits and letters. Trailing zeros and release qualifiers
+// are dropped, so 1.0, 1.0.0, and 1.0-final are equal.
+func parseMavenVersion(version string) ([]mavenVersionItem, bool) {
+	version = strings.ToLower(strings.TrimSpace(version))
+	if version == "" {
+		return nil, false
+	}
+
+	var items []mavenVersionItem
+	addItem := func(token string) {
+		if n, err := strconv.Atoi(token); err == nil {
+			items = append(items, mavenVersionItem{isNumber: true, number: n})
+		} else {
+			items = append(items, mavenVersionItem{qualifier: token})
+		}
+	}
+
+	start := 0
+	for i, r := range version {
+		switch {
+		case r == '.' || r == '-':
+			addItem(version[start:i])
+			start = i + 1
+		case i > start && unicode.IsDigit(r) != unicode.IsDigit(rune(version[i-1])):
+			addItem(version[start:i])
+			start = i
+		}
+	}
+	addItem(version[start:])
+
+	for len(items) > 0 && items[len(items)-1].isNull() {
+		items = items[:len(items)-1]
+	}
+	return items, true
+}
+
+// isNull reports whether an item is a zero or a release qualifier, which
+// don't change a version's order when trailing
+func (item mavenVersionItem) isNull() bool {
+	if item.isNumber {
+		return item.number == 0
+	}
+	switch item.qualifier {
+	case "", "ga", "final", "release":
+		return true
+	}
+	return false
+}
+
+// compareMavenItems orders two version items. A missing item is a zero when
+// compared with a number and a release when compared with a qualifier.
+// Numbers sort after qualifiers, so 1.0.1 comes after 1.0-rc1.
+func compareMavenItems(a, b *mavenVersionItem) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -compareMavenItems(b, nil)
+	case b == nil:
+		if a.isNumber {
+			return sign(a.number)
+		}
+		return compareMavenQualifiers(a.qualifier, "")
+	case a.isNumber && b.isNumber:
+		return sign(a.number - b.number)
+	case a.isNumber:
+		return 1
+	case b.isNumber:
+		return -1
+	}
+	return compareMavenQualifiers(a.qualifier, b.qualifier)
+}
+
+// compareMavenQualifiers orders qualifiers by rank, and unknown qualifiers
+// lexically
+func compareMavenQualifiers(a, b string) int {
+	rankA, knownA := mavenQualifierRanks[a]
+	rankB, knownB := mavenQualifierRanks[b]
+	if !knownA {
+		rankA = mavenUnknownQualifierRank
+	}
+	if !knownB {
+		rankB = mavenUnknownQualifierRank
+	}
+	if rankA != rankB {
+		return sign(rankA - rankB)
+	}
+	if !knownA && !knownB {
+		return strings.Compare(a, b)
+	}
+	return 0
+}
+
+// compareMavenVersions orders two Maven versions, following the rules of
+// Maven's ComparableVersion for the common cases; ok is false when either is
+// empty
+func compareMavenVersions(a, b string) (int, bool) {
+	itemsA, ok := parseMavenVersion(a)
+	if !ok {
+		return 0, false
+	}
+	itemsB, ok := parseMavenVersion(b)
+	if !ok {
+		return 0, false
+	}
+
+	for i := 0; i < max(len(itemsA), len(itemsB)); i++ {
+		var itemA, itemB *mavenVersionItem
+		if i < len(itemsA) {
+			itemA = &itemsA[i]
+		}
+		if i < len(itemsB) {
+			itemB = &itemsB[i]
+		}
+		if c := compareMavenItems(itemA, itemB); c != 0 {
+			return c, true
+		}
+	}
+	return 0, true
+}
@@ -0,0 +1,34 @@
+package audit
+
+import "testing"
+
+func TestCompareMavenVersions(t *testing.T) {
+	// Each version sorts before the next
+	ordered := []string{
+		"1.0-alpha-1",
+		"1.0-beta2",
+		"1.0-M1",
+		"1.0-rc1",
+		"1.0-SNAPSHOT",
+		"1.0",
+		"1.0-sp1",
+		"1.0.1",
+		"2.13.4",
+		"2.13.4.2",
+		"2.14",
+	}
+	for i := 0; i < len(ordered)-1; i++ {
+		if c, ok := compareMavenVersions(ordered[i], ordered[i+1]); !ok || c != -1 {
+			t.Errorf("compareMavenVersions(%q, %q) = %d, %v; expected -1, true", ordered[i], ordered[i+1], c, ok)
+		}
+		if c, ok := compareMavenVersions(ordered[i+1], ordered[i]); !ok || c != 1 {
+			t.Errorf("compareMavenVersions(%q, %q) = %d, %v; expected 1, true", ordered[i+1], ordered[i], c, ok)
+		}
+	}
+
+	for _, pair := range [][2]string{{"1.0", "1.0.0"}, {"1.0", "1.0-final"}, {"1.0.GA", "1"}, {"5.3.0.RC1", "5.3.0-rc1"}} {
+		if c, ok := compareMavenVersions(pair[0], pair[1]); !ok || c != 0 {
+			t.Errorf("compareMavenVersions(%q, %q) = %d, %v; expected 0, true", pair[0], pair[1], c, ok)
+		}
+	}
+}
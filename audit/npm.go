@@ -0,0 +1,165 @@
+package audit
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/brandonapol/snoop/osv"
+)
+
+// RunNpmOSVAudit checks npm packages for vulnerabilities using the OSV API.
+// It is the fallback when npm isn't installed. With a package-lock.json,
+// yarn.lock, or pnpm-lock.yaml it audits every installed package at its
+// resolved version; otherwise only the pinned dependencies of package.json,
+// with overrides/resolutions applied.
+func (r *Runner) RunNpmOSVAudit(packageJSONPath string) *AuditResult {
+	result := &AuditResult{
+		PackageJSONPath: packageJSONPath,
+	}
+	r.checkPackageLock(result)
+
+	manifest, err := ParsePackageJSON(packageJSONPath)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+
+	packages, lockfile := manifest.installedPackages(packageJSONPath)
+	packages = withoutExcluded(r, osv.NPM, packages)
+	result.Dependencies = packages
+	if len(packages) == 0 {
+		return result
+	}
+	result.PackagesScanned = len(packages)
+
+	if r.verbose {
+		if lockfile != "" {
+			fmt.Fprintf(os.Stderr, "Found %d npm packages in %s\n", len(packages), lockfile)
+		} else {
+			fmt.Fprintf(os.Stderr, "Found %d pinned npm packages in %s\n", len(packages), filepath.Base(packageJSONPath))
+		}
+	}
+
+	osvPkgs := make([]osv.Package, 0, len(packages))
+	for _, pkg := range packages {
+		osvPkgs = append(osvPkgs, osv.Package{
+			Name:      pkg.Name,
+			Version:   pkg.Version,
+			Ecosystem: osv.NPM,
+		})
+	}
+	responses := r.osvClient.QueryPackagesContext(r.ctx, osvPkgs)
+	result.Unverified = allQueriesFailed(responses)
+
+	for i, pkg := range packages {
+		if r.verbose {
+			if pkg.Overridden {
+				fmt.Fprintf(os.Stderr, "  Checking %s@%s (override)...\n", pkg.Name, pkg.Version)
+			} else {
+				fmt.Fprintf(os.Stderr, "  Checking %s@%s...\n", pkg.Name, pkg.Version)
+			}
+		}
+
+		response, err := responses[i].Response, responses[i].Err
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("failed to query %s: %v", pkg.Name, err))
+			if r.verbose {
+				fmt.Fprintf(os.Stderr, "    Warning: Failed to query %s: %v\n", pkg.Name, err)
+			}
+			continue
+		}
+
+		// Withdrawn advisories no longer describe a vulnerability
+		var withdrawn int
+		response.Vulns, withdrawn = dropWithdrawn(response.Vulns)
+		result.WithdrawnSkipped += withdrawn
+
+		// Re-check matches against semver ranges locally
+		response.Vulns = r.filterAffected(osvPkgs[i], response.Vulns)
+
+		// Count advisories that alias one another as a single finding
+		response.Vulns = mergeAliasedAdvisories(response.Vulns)
+
+		if len(response.Vulns) == 0 {
+			continue
+		}
+
+		if r.verbose {
+			fmt.Fprintf(os.Stderr, "    Found %d vulnerability(ies)\n", len(response.Vulns))
+			printAdvisories(response.Vulns)
+		}
+
+		vuln := npmVulnerabilityFromOSV(pkg, response.Vulns)
+		result.Vulnerabilities = append(result.Vulnerabilities, vuln)
+
+		// Count it in the summary by severity and directness
+		vuln.countIn(&result.Summary)
+	}
+
+	result.Recommendations = RecommendNpmFixes(result.Vulnerabilities, DetectPackageManager(filepath.Dir(packageJSONPath)))
+
+	return result
+}
+
+// npmVulnerabilityFromOSV folds the OSV advisories for one package into a
+// single entry shaped like npm audit's output
+func npmVulnerabilityFromOSV(pkg NpmPackage, vulns []osv.Vulnerability) Vulnerability {
+	vuln := Vulnerability{
+		Name:     pkg.Name,
+		IsDirect: pkg.IsDirect,
+		Nodes:    []string{"node_modules/" + pkg.Name},
+	}
+
+	var ranges, fixVersions []string
+	for _, osvVuln := range vulns {
+		vuln.Via = append(vuln.Via, osvVuln.ID)
+		severity := Severity(osvVuln.GetSeverityLevel())
+		if vuln.Severity == "" || severityRank[severity] > severityRank[vuln.Severity] {
+			vuln.Severity = severity
+		}
+		ranges = append(ranges, affectedRanges(osvVuln)...)
+		fixVersions = append(fixVersions, extractFixVersions(osvVuln)...)
+	}
+	vuln.Range = strings.Join(ranges, " || ")
+
+	if len(fixVersions) > 0 {
+		fixVersion := fixVersions[len(fixVersions)-1]
+		vuln.FixAvailable = FixAvailable{
+			Available: true,
+			Name:      pkg.Name,
+			Version:   fixVersion,
+			// npm audit sets this itself; the OSV fallback works it out from the pinned version
+			IsSemVerMajor: isMajorUpgrade(pkg.Version, fixVersion),
+		}
+	}
+
+	return vuln
+}
+
+// affectedRanges renders the OSV introduced/fixed events as npm-style ranges
+func affectedRanges(vuln osv.Vulnerability) []string {
+	var ranges []string
+	for _, affected := range vuln.Affected {
+		for _, vrange := range affected.Ranges {
+			introduced := ""
+			for _, event := range vrange.Events {
+				switch {
+				case event.Introduced != "":
+					introduced = event.Introduced
+				case event.Fixed != "":
+					ranges = append(ranges, fmt.Sprintf(">=%s <%s", introduced, event.Fixed))
+					introduced = ""
+				case event.LastAffected != "":
+					ranges = append(ranges, fmt.Sprintf(">=%s <=%s", introduced, event.LastAffected))
+					introduced = ""
+				}
+			}
+			if introduced != "" {
+				ranges = append(ranges, ">="+introduced)
+			}
+		}
+	}
+	return ranges
+}
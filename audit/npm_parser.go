@@ -0,0 +1,164 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// exactVersionRegex matches a single pinned semver version such as 1.2.3 or 2.0.0-rc.1
+var exactVersionRegex = regexp.MustCompile(`^v?=?\s*([0-9]+\.[0-9]+\.[0-9]+(?:[-+][0-9A-Za-z\.\-+]+)?)$`)
+
+// NpmPackage represents an npm package resolved to a specific version
+type NpmPackage struct {
+	Name       string
+	Version    string
+	IsDirect   bool
+	Overridden bool // Version forced by overrides/resolutions
+}
+
+// PackageJSON represents the parts of package.json relevant to auditing
+type PackageJSON struct {
+	Name                 string            `json:"name"`
+	Version              string            `json:"version"`
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	Overrides            map[string]any    `json:"overrides"`   // npm
+	Resolutions          map[string]string `json:"resolutions"` // yarn
+	Workspaces           NpmWorkspaces     `json:"workspaces"`
+}
+
+// ParsePackageJSON parses a package.json file
+func ParsePackageJSON(filepath string) (*PackageJSON, error) {
+	data, err := os.ReadFile(filepath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open package.json: %w", err)
+	}
+
+	var manifest PackageJSON
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse package.json: %w", err)
+	}
+
+	return &manifest, nil
+}
+
+// OverriddenVersions returns the version forced for each package by npm
+// "overrides" or yarn "resolutions". Nested and path-scoped entries such as
+// {"parent": {"child": "1.0.0"}} or "**/child" apply to the innermost package.
+func (m *PackageJSON) OverriddenVersions() map[string]string {
+	versions := make(map[string]string)
+
+	for key, value := range m.Resolutions {
+		versions[lastPackageInPath(key)] = value
+	}
+
+	var walk func(name string, value any)
+	walk = func(name string, value any) {
+		switch v := value.(type) {
+		case string:
+			versions[name] = m.resolveOverrideReference(v)
+		case map[string]any:
+			for child, childValue := range v {
+				if child == "." {
+					walk(name, childValue)
+				} else {
+					walk(stripVersionSelector(child), childValue)
+				}
+			}
+		}
+	}
+	for key, value := range m.Overrides {
+		walk(stripVersionSelector(key), value)
+	}
+
+	return versions
+}
+
+// Packages returns every package that can be audited at an exact version:
+// pinned direct dependencies plus any package pinned by an override, with
+// overrides taking precedence over the declared version
+func (m *PackageJSON) Packages() []NpmPackage {
+	byName := make(map[string]NpmPackage)
+
+	for _, deps := range m.declaredDependencies() {
+		for name, spec := range deps {
+			if version, ok := ExactVersion(spec); ok {
+				byName[name] = NpmPackage{Name: name, Version: version, IsDirect: true}
+			}
+		}
+	}
+
+	for name, spec := range m.OverriddenVersions() {
+		version, ok := ExactVersion(spec)
+		if !ok {
+			continue
+		}
+		isDirect := slices.ContainsFunc(m.declaredDependencies(), func(deps map[string]string) bool {
+			_, ok := deps[name]
+			return ok
+		})
+		byName[name] = NpmPackage{Name: name, Version: version, IsDirect: isDirect, Overridden: true}
+	}
+
+	packages := make([]NpmPackage, 0, len(byName))
+	for _, pkg := range byName {
+		packages = append(packages, pkg)
+	}
+	sort.Slice(packages, func(i, j int) bool { return packages[i].Name < packages[j].Name })
+
+	return packages
+}
+
+// declaredDependencies returns the dependency lists whose entries are direct
+// dependencies of the package
+func (m *PackageJSON) declaredDependencies() []map[string]string {
+	return []map[string]string{m.Dependencies, m.DevDependencies, m.OptionalDependencies}
+}
+
+// resolveOverrideReference resolves npm's "$name" override syntax, which
+// reuses the version declared for a direct dependency
+func (m *PackageJSON) resolveOverrideReference(spec string) string {
+	if !strings.HasPrefix(spec, "$") {
+		return spec
+	}
+	name := strings.TrimPrefix(spec, "$")
+	if version, ok := m.Dependencies[name]; ok {
+		return version
+	}
+	return m.DevDependencies[name]
+}
+
+// ExactVersion returns the version from a spec that pins exactly one version
+func ExactVersion(spec string) (string, bool) {
+	matches := exactVersionRegex.FindStringSubmatch(strings.TrimSpace(spec))
+	if len(matches) < 2 {
+		return "", false
+	}
+	return matches[1], true
+}
+
+// stripVersionSelector removes a version selector from an override key,
+// e.g. "foo@1.x" becomes "foo" and "@scope/foo@2" becomes "@scope/foo"
+func stripVersionSelector(key string) string {
+	if idx := strings.LastIndex(key, "@"); idx > 0 {
+		return key[:idx]
+	}
+	return key
+}
+
+// lastPackageInPath returns the innermost package of a yarn resolution path,
+// e.g. "**/parent/child" and "parent/@scope/child" yield "child" and "@scope/child"
+func lastPackageInPath(path string) string {
+	segments := strings.Split(path, "/")
+	last := stripVersionSelector(segments[len(segments)-1])
+	if len(segments) >= 2 && strings.HasPrefix(segments[len(segments)-2], "@") {
+		return segments[len(segments)-2] + "/" + last
+	}
+	return last
+}
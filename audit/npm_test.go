@@ -0,0 +1,425 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/brandonapol/snoop/osv"
+)
+
+func TestOverriddenVersions(t *testing.T) {
+	manifest := &PackageJSON{
+		Dependencies: map[string]string{"lodash": "4.17.21"},
+		Overrides: map[string]any{
+			"minimist": "1.2.6",
+			"webpack": map[string]any{
+				".":          "5.0.0",
+				"terser@5.x": "5.14.2",
+			},
+			"@babel/traverse": "$lodash",
+		},
+		Resolutions: map[string]string{
+			"**/ansi-regex":       "5.0.1",
+			"parent/@scope/child": "2.0.0",
+		},
+	}
+
+	expected := map[string]string{
+		"minimist":        "1.2.6",
+		"webpack":         "5.0.0",
+		"terser":          "5.14.2",
+		"@babel/traverse": "4.17.21",
+		"ansi-regex":      "5.0.1",
+		"@scope/child":    "2.0.0",
+	}
+
+	if got := manifest.OverriddenVersions(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("OverriddenVersions() = %v, expected %v", got, expected)
+	}
+}
+
+func TestPackagesOverriddenDirectness(t *testing.T) {
+	manifest := &PackageJSON{
+		Dependencies:         map[string]string{"lodash": "^4.17.0"},
+		DevDependencies:      map[string]string{"jest": "^29.0.0"},
+		OptionalDependencies: map[string]string{"fsevents": "^2.3.0"},
+		Overrides: map[string]any{
+			"lodash":   "4.17.21",
+			"jest":     "29.7.0",
+			"fsevents": "2.3.3",
+			"minimist": "1.2.8",
+		},
+	}
+
+	expected := []NpmPackage{
+		{Name: "fsevents", Version: "2.3.3", IsDirect: true, Overridden: true},
+		{Name: "jest", Version: "29.7.0", IsDirect: true, Overridden: true},
+		{Name: "lodash", Version: "4.17.21", IsDirect: true, Overridden: true},
+		{Name: "minimist", Version: "1.2.8", IsDirect: false, Overridden: true},
+	}
+
+	if got := manifest.Packages(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Packages() = %+v, expected %+v", got, expected)
+	}
+}
+
+func TestRunNpmOSVAuditHonorsOverrides(t *testing.T) {
+	// minimist is only vulnerable before 1.2.6
+	var mu sync.Mutex
+	queried := make(map[string]string)
+	server := newMockOSVServer(t, func(request osv.QueryRequest) []osv.Vulnerability {
+		mu.Lock()
+		queried[request.Package.Name] = request.Package.Version
+		mu.Unlock()
+		if request.Package.Name == "minimist" && request.Package.Version == "1.2.5" {
+			return []osv.Vulnerability{{ID: "GHSA-xvch-5gv4-984h", Summary: "Prototype Pollution in minimist"}}
+		}
+		return nil
+	})
+
+	tmpDir := t.TempDir()
+	packageJSONPath := filepath.Join(tmpDir, "package.json")
+	writePackageJSON := func(content string) {
+		if err := os.WriteFile(packageJSONPath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write package.json: %v", err)
+		}
+	}
+
+	runner := NewRunner(0, false)
+	runner.osvClient = osv.NewClientWithURL(server.URL)
+
+	// The override pins the transitive minimist to the vulnerable release
+	writePackageJSON(`{
+		"name": "app",
+		"dependencies": {"mkdirp": "0.5.5"},
+		"overrides": {"minimist": "1.2.5"}
+	}`)
+	result := runner.RunNpmOSVAudit(packageJSONPath)
+	if result.Error != nil {
+		t.Fatalf("RunNpmOSVAudit() unexpected error: %v", result.Error)
+	}
+	if result.Summary.Total != 1 || result.Vulnerabilities[0].Name != "minimist" {
+		t.Fatalf("expected one minimist finding, got %+v", result.Vulnerabilities)
+	}
+	if result.Vulnerabilities[0].IsDirect {
+		t.Error("overridden transitive package should not be reported as direct")
+	}
+
+	// The override forces the fixed release, so nothing is reported
+	writePackageJSON(`{
+		"name": "app",
+		"dependencies": {"mkdirp": "0.5.5"},
+		"overrides": {"minimist": "1.2.6"}
+	}`)
+	result = runner.RunNpmOSVAudit(packageJSONPath)
+	if result.Summary.Total != 0 {
+		t.Errorf("expected no findings with the fixed override, got %+v", result.Vulnerabilities)
+	}
+	if queried["minimist"] != "1.2.6" {
+		t.Errorf("minimist queried at %q, expected the overridden version 1.2.6", queried["minimist"])
+	}
+	if queried["mkdirp"] != "0.5.5" {
+		t.Errorf("mkdirp queried at %q, expected 0.5.5", queried["mkdirp"])
+	}
+}
+
+func TestRunNpmOSVAuditFlagsIncompleteLockfile(t *testing.T) {
+	server := newMockOSVServer(t, func(osv.QueryRequest) []osv.Vulnerability { return nil })
+
+	tmpDir := t.TempDir()
+	packageJSONPath := filepath.Join(tmpDir, "package.json")
+	lockPath := filepath.Join(tmpDir, "package-lock.json")
+	if err := os.WriteFile(packageJSONPath, []byte(`{"name": "app", "dependencies": {"express": "4.18.2"}}`), 0644); err != nil {
+		t.Fatalf("Failed to write package.json: %v", err)
+	}
+	writeLock := func(content string) {
+		if err := os.WriteFile(lockPath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write package-lock.json: %v", err)
+		}
+	}
+
+	runner := NewRunner(0, false)
+	runner.osvClient = osv.NewClientWithURL(server.URL)
+
+	// A truncated lockfile: the root and the workspace link need no version,
+	// but the installed packages do
+	writeLock(`{
+		"name": "app",
+		"lockfileVersion": 3,
+		"packages": {
+			"": {"name": "app"},
+			"node_modules/express": {"resolved": "https://registry.npmjs.org/express/-/express-4.18.2.tgz"},
+			"node_modules/express/node_modules/debug": {"version": ""},
+			"node_modules/shared": {"link": true},
+			"node_modules/qs": {"version": "6.11.0"}
+		}
+	}`)
+	result := runner.RunNpmOSVAudit(packageJSONPath)
+	if result.Error != nil {
+		t.Fatalf("RunNpmOSVAudit() unexpected error: %v", result.Error)
+	}
+	if !result.IncompleteLockfile {
+		t.Fatal("lockfile without versions should be reported as incomplete")
+	}
+	expected := "package-lock.json incomplete — cannot determine versions of 2 package(s): debug, express"
+	if len(result.Warnings) != 1 || result.Warnings[0] != expected {
+		t.Errorf("Warnings = %q, expected [%q]", result.Warnings, expected)
+	}
+
+	// Lockfile v1 nests dependencies instead
+	writeLock(`{
+		"name": "app",
+		"lockfileVersion": 1,
+		"dependencies": {
+			"express": {"version": "4.18.2", "dependencies": {"debug": {"requires": {"ms": "2.0.0"}}}}
+		}
+	}`)
+	result = runner.RunNpmOSVAudit(packageJSONPath)
+	if !result.IncompleteLockfile || len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], ": debug") {
+		t.Errorf("v1 lockfile missing debug's version: IncompleteLockfile = %v, Warnings = %q", result.IncompleteLockfile, result.Warnings)
+	}
+
+	writeLock(`{
+		"name": "app",
+		"lockfileVersion": 3,
+		"packages": {"": {"name": "app"}, "node_modules/express": {"version": "4.18.2"}}
+	}`)
+	result = runner.RunNpmOSVAudit(packageJSONPath)
+	if result.IncompleteLockfile || len(result.Warnings) != 0 {
+		t.Errorf("complete lockfile flagged: IncompleteLockfile = %v, Warnings = %q", result.IncompleteLockfile, result.Warnings)
+	}
+}
+
+func TestLockfilePackages(t *testing.T) {
+	tmpDir := t.TempDir()
+	lockPath := filepath.Join(tmpDir, "package-lock.json")
+	manifest := &PackageJSON{
+		Dependencies:    map[string]string{"express": "^4.18.0", "@babel/core": "^7.0.0"},
+		DevDependencies: map[string]string{"debug": "^4.0.0"},
+	}
+
+	// Install locations list their dependencies as ranges, which must not
+	// break decoding
+	lock := `{
+		"name": "app",
+		"lockfileVersion": 3,
+		"packages": {
+			"": {"name": "app", "dependencies": {"express": "^4.18.0", "@babel/core": "^7.0.0"}, "devDependencies": {"debug": "^4.0.0"}},
+			"node_modules/express": {"version": "4.18.2", "dependencies": {"debug": "2.6.9"}},
+			"node_modules/express/node_modules/debug": {"version": "2.6.9"},
+			"node_modules/debug": {"version": "4.3.4", "dev": true},
+			"node_modules/@babel/core": {"version": "7.22.0"},
+			"node_modules/shared": {"link": true},
+			"packages/shared": {"version": "1.0.0"}
+		}
+	}`
+	if err := os.WriteFile(lockPath, []byte(lock), 0644); err != nil {
+		t.Fatalf("Failed to write package-lock.json: %v", err)
+	}
+
+	packages, err := manifest.LockfilePackages(lockPath)
+	if err != nil {
+		t.Fatalf("LockfilePackages() unexpected error: %v", err)
+	}
+	expected := []NpmPackage{
+		{Name: "@babel/core", Version: "7.22.0", IsDirect: true},
+		{Name: "debug", Version: "2.6.9"},
+		{Name: "debug", Version: "4.3.4", IsDirect: true},
+		{Name: "express", Version: "4.18.2", IsDirect: true},
+	}
+	if !reflect.DeepEqual(packages, expected) {
+		t.Errorf("LockfilePackages() = %+v, expected %+v", packages, expected)
+	}
+
+	if unresolved, err := UnresolvedLockfilePackages(lockPath); err != nil || len(unresolved) != 0 {
+		t.Errorf("UnresolvedLockfilePackages() = %v, %v, expected a complete lockfile", unresolved, err)
+	}
+
+	// Lockfile v1 marks only top-level entries as direct
+	lock = `{
+		"name": "app",
+		"lockfileVersion": 1,
+		"dependencies": {
+			"express": {"version": "4.18.2", "requires": {"debug": "2.6.9"}, "dependencies": {"debug": {"version": "2.6.9"}}},
+			"ms": {"version": "2.0.0"}
+		}
+	}`
+	if err := os.WriteFile(lockPath, []byte(lock), 0644); err != nil {
+		t.Fatalf("Failed to write package-lock.json: %v", err)
+	}
+
+	packages, err = manifest.LockfilePackages(lockPath)
+	if err != nil {
+		t.Fatalf("LockfilePackages() unexpected error: %v", err)
+	}
+	expected = []NpmPackage{
+		{Name: "debug", Version: "2.6.9"},
+		{Name: "express", Version: "4.18.2", IsDirect: true},
+		{Name: "ms", Version: "2.0.0"},
+	}
+	if !reflect.DeepEqual(packages, expected) {
+		t.Errorf("LockfilePackages() v1 = %+v, expected %+v", packages, expected)
+	}
+}
+
+func TestParsePackageLock(t *testing.T) {
+	lockPath := filepath.Join(t.TempDir(), "package-lock.json")
+
+	// The root entry of lockfile v2/v3 says which packages are declared
+	lock := `{
+		"name": "app",
+		"lockfileVersion": 2,
+		"packages": {
+			"": {"name": "app", "dependencies": {"express": "^4.18.0"}},
+			"node_modules/express": {"version": "4.18.2", "dependencies": {"qs": "6.11.0"}},
+			"node_modules/qs": {"version": "6.11.0"}
+		},
+		"dependencies": {
+			"express": {"version": "4.18.2"},
+			"qs": {"version": "6.11.0"}
+		}
+	}`
+	if err := os.WriteFile(lockPath, []byte(lock), 0644); err != nil {
+		t.Fatalf("Failed to write package-lock.json: %v", err)
+	}
+
+	packages, err := ParsePackageLock(lockPath)
+	if err != nil {
+		t.Fatalf("ParsePackageLock() unexpected error: %v", err)
+	}
+	expected := []NpmPackage{
+		{Name: "express", Version: "4.18.2", IsDirect: true},
+		{Name: "qs", Version: "6.11.0"},
+	}
+	if !reflect.DeepEqual(packages, expected) {
+		t.Errorf("ParsePackageLock() = %+v, expected %+v", packages, expected)
+	}
+
+	// Lockfile v1 has no root entry, so every top-level package counts as direct
+	lock = `{
+		"name": "app",
+		"lockfileVersion": 1,
+		"dependencies": {
+			"express": {"version": "4.18.2", "dependencies": {"debug": {"version": "2.6.9"}}},
+			"ms": {"version": "2.0.0"}
+		}
+	}`
+	if err := os.WriteFile(lockPath, []byte(lock), 0644); err != nil {
+		t.Fatalf("Failed to write package-lock.json: %v", err)
+	}
+
+	packages, err = ParsePackageLock(lockPath)
+	if err != nil {
+		t.Fatalf("ParsePackageLock() unexpected error: %v", err)
+	}
+	expected = []NpmPackage{
+		{Name: "debug", Version: "2.6.9"},
+		{Name: "express", Version: "4.18.2", IsDirect: true},
+		{Name: "ms", Version: "2.0.0", IsDirect: true},
+	}
+	if !reflect.DeepEqual(packages, expected) {
+		t.Errorf("ParsePackageLock() v1 = %+v, expected %+v", packages, expected)
+	}
+
+	if _, err := ParsePackageLock(filepath.Join(t.TempDir(), "package-lock.json")); err == nil {
+		t.Error("ParsePackageLock() of a missing file should fail")
+	}
+}
+
+func TestRunAuditFallsBackToLockfileWithoutNpm(t *testing.T) {
+	// Hide npm so RunAudit can't shell out to it
+	t.Setenv("PATH", t.TempDir())
+
+	server := newMockOSVServer(t, func(request osv.QueryRequest) []osv.Vulnerability {
+		if request.Package.Name == "qs" && request.Package.Version == "6.11.0" {
+			return []osv.Vulnerability{{ID: "GHSA-hrpp-h998-j3pp", Summary: "qs vulnerable to Prototype Pollution"}}
+		}
+		return nil
+	})
+
+	tmpDir := t.TempDir()
+	packageJSONPath := filepath.Join(tmpDir, "package.json")
+	if err := os.WriteFile(packageJSONPath, []byte(`{"name": "app", "dependencies": {"express": "^4.18.0"}}`), 0644); err != nil {
+		t.Fatalf("Failed to write package.json: %v", err)
+	}
+	lock := `{
+		"name": "app",
+		"lockfileVersion": 3,
+		"packages": {
+			"": {"name": "app", "dependencies": {"express": "^4.18.0"}},
+			"node_modules/express": {"version": "4.18.2"},
+			"node_modules/qs": {"version": "6.11.0"}
+		}
+	}`
+	if err := os.WriteFile(filepath.Join(tmpDir, "package-lock.json"), []byte(lock), 0644); err != nil {
+		t.Fatalf("Failed to write package-lock.json: %v", err)
+	}
+
+	runner := NewRunner(0, false)
+	runner.osvClient = osv.NewClientWithURL(server.URL)
+	result := runner.RunAudit(packageJSONPath)
+
+	if result.Error != nil {
+		t.Fatalf("RunAudit() unexpected error: %v", result.Error)
+	}
+	// The ranged express dependency only has an exact version in the lockfile
+	if result.PackagesScanned != 2 {
+		t.Errorf("PackagesScanned = %d, expected both locked packages", result.PackagesScanned)
+	}
+	if result.Summary.Total != 1 || result.Vulnerabilities[0].Name != "qs" {
+		t.Fatalf("expected one qs finding, got %+v", result.Vulnerabilities)
+	}
+	if result.Vulnerabilities[0].IsDirect {
+		t.Error("transitive qs should not be reported as direct")
+	}
+}
+
+func TestWorkspacesForms(t *testing.T) {
+	for _, content := range []string{
+		`{"workspaces": ["packages/*", "tools/cli"]}`,
+		`{"workspaces": {"packages": ["packages/*", "tools/cli"], "nohoist": ["**/react"]}}`,
+	} {
+		path := filepath.Join(t.TempDir(), "package.json")
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write package.json: %v", err)
+		}
+		manifest, err := ParsePackageJSON(path)
+		if err != nil {
+			t.Fatalf("ParsePackageJSON(%s) error = %v", content, err)
+		}
+		expected := NpmWorkspaces{"packages/*", "tools/cli"}
+		if !reflect.DeepEqual(manifest.Workspaces, expected) {
+			t.Errorf("Workspaces of %s = %v, expected %v", content, manifest.Workspaces, expected)
+		}
+	}
+}
+
+func TestHasWorkspaceMember(t *testing.T) {
+	root := "repo"
+	manifest := &PackageJSON{Workspaces: NpmWorkspaces{"packages/*", "./tools/cli/", "apps/**", "!packages/legacy"}}
+
+	tests := []struct {
+		dir      string
+		expected bool
+	}{
+		{"packages/web", true},
+		{"packages/legacy", false},     // Negated
+		{"packages/web/nested", false}, // * is one directory
+		{"tools/cli", true},
+		{"tools/other", false},
+		{"apps/site/admin", true},
+		{".", false},               // The root itself
+		{"../packages/web", false}, // Outside the root
+	}
+
+	for _, tt := range tests {
+		member := filepath.Join(root, filepath.FromSlash(tt.dir))
+		if got := manifest.HasWorkspaceMember(root, member); got != tt.expected {
+			t.Errorf("HasWorkspaceMember(%q) = %v, expected %v", tt.dir, got, tt.expected)
+		}
+	}
+}
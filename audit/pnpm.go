@@ -0,0 +1,152 @@
+package audit
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// pnpmLock is the part of a pnpm-lock.yaml needed to list its packages.
+// LockfileVersion is a number in 5.x and a quoted string later.
+type pnpmLock struct {
+	LockfileVersion string `yaml:"lockfileVersion"`
+	// The root project's dependencies sit at the top level outside monorepos
+	// before 9.x
+	pnpmImporter `yaml:",inline"`
+	Importers    map[string]pnpmImporter `yaml:"importers"`
+	Packages     map[string]yaml.Node    `yaml:"packages"`
+}
+
+// pnpmImporter lists the dependencies of one project in a pnpm-lock.yaml
+type pnpmImporter struct {
+	Dependencies         map[string]pnpmDependency `yaml:"dependencies"`
+	DevDependencies      map[string]pnpmDependency `yaml:"devDependencies"`
+	OptionalDependencies map[string]pnpmDependency `yaml:"optionalDependencies"`
+}
+
+// pnpmDependency is the version a project's dependency resolved to. 5.x writes
+// the version as the value, 6.x and 9.x under version: beside the specifier.
+type pnpmDependency struct {
+	Version string `yaml:"version"`
+}
+
+// UnmarshalYAML accepts both the 5.x scalar and the later mapping form
+func (d *pnpmDependency) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		d.Version = node.Value
+		return nil
+	}
+	type plain pnpmDependency
+	return node.Decode((*plain)(d))
+}
+
+// pnpmVersion matches the start of a version resolved from the registry, as
+// opposed to link:, file:, or git references
+var pnpmVersion = regexp.MustCompile(`^\d+\.\d+\.\d+`)
+
+// ParsePnpmLock returns every registry package a pnpm-lock.yaml resolves at
+// its version, sorted by name and version. It reads the packages map in the
+// key formats of lockfileVersion 5.x (/name/version_peer), 6.x
+// (/name@version(peer)), and 9.x (name@version). Packages the root project
+// depends on are marked direct.
+func ParsePnpmLock(path string) ([]NpmPackage, error) {
+	lock, err := readPnpmLock(path)
+	if err != nil {
+		return nil, err
+	}
+
+	slashKeys := strings.HasPrefix(lock.LockfileVersion, "5")
+
+	direct := make(map[NpmPackage]bool)
+	// The root project's dependencies sit under importers["."] in monorepos
+	// and 9.x, and at the top level otherwise
+	root, ok := lock.Importers["."]
+	if !ok {
+		root = lock.pnpmImporter
+	}
+	for _, deps := range []map[string]pnpmDependency{root.Dependencies, root.DevDependencies, root.OptionalDependencies} {
+		for name, dep := range deps {
+			if version, ok := pnpmResolvedVersion(dep.Version); ok {
+				direct[NpmPackage{Name: name, Version: version}] = true
+			}
+		}
+	}
+
+	found := make(map[NpmPackage]bool)
+	for key := range lock.Packages {
+		name, version, ok := parsePnpmPackageKey(key, slashKeys)
+		if ok {
+			found[NpmPackage{Name: name, Version: version}] = true
+		}
+	}
+
+	packages := make([]NpmPackage, 0, len(found))
+	for pkg := range found {
+		pkg.IsDirect = direct[pkg]
+		packages = append(packages, pkg)
+	}
+	sort.Slice(packages, func(i, j int) bool {
+		if packages[i].Name != packages[j].Name {
+			return packages[i].Name < packages[j].Name
+		}
+		return packages[i].Version < packages[j].Version
+	})
+	return packages, nil
+}
+
+// parsePnpmPackageKey splits a packages key into name and version, dropping
+// the peer dependency suffix. slashKeys selects the 5.x format, where the
+// version follows the last "/" instead of the "@" after the name.
+func parsePnpmPackageKey(key string, slashKeys bool) (name, version string, ok bool) {
+	if slashKeys {
+		// Keys without a leading "/" name a tarball or a non-default registry
+		key, ok = strings.CutPrefix(key, "/")
+		if !ok {
+			return "", "", false
+		}
+		slash := strings.LastIndex(key, "/")
+		if slash <= 0 {
+			return "", "", false
+		}
+		name, version = key[:slash], key[slash+1:]
+	} else {
+		name, version, ok = splitYarnDescriptor(strings.TrimPrefix(key, "/"))
+		if !ok {
+			return "", "", false
+		}
+	}
+
+	version, ok = pnpmResolvedVersion(version)
+	if !ok || name == "" {
+		return "", "", false
+	}
+	return name, version, true
+}
+
+// pnpmResolvedVersion strips the peer dependency suffix from a resolved
+// version, written 1.0.0_react@17.0.2 in 5.x and 1.0.0(react@17.0.2) later.
+// It fails for versions that don't come from the registry.
+func pnpmResolvedVersion(version string) (string, bool) {
+	if i := strings.IndexAny(version, "_("); i >= 0 {
+		version = version[:i]
+	}
+	return version, pnpmVersion.MatchString(version)
+}
+
+// readPnpmLock decodes a pnpm-lock.yaml
+func readPnpmLock(path string) (*pnpmLock, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pnpm-lock.yaml: %w", err)
+	}
+
+	var lock pnpmLock
+	if err := yaml.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &lock, nil
+}
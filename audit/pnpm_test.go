@@ -0,0 +1,222 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/brandonapol/snoop/osv"
+)
+
+// pnpmLockV5 is a pnpm-lock.yaml with lockfileVersion 5.4
+const pnpmLockV5 = `lockfileVersion: 5.4
+
+specifiers:
+  '@babel/core': ^7.0.0
+  react-dom: ^17.0.2
+
+dependencies:
+  '@babel/core': 7.0.0
+  react-dom: 17.0.2_react@17.0.2
+
+packages:
+
+  /@babel/core/7.0.0:
+    resolution: {integrity: sha512-abc}
+    dependencies:
+      minimist: 1.2.5
+    dev: false
+
+  /minimist/1.2.5:
+    resolution: {integrity: sha512-def}
+    dev: false
+
+  /react-dom/17.0.2_react@17.0.2:
+    resolution: {integrity: sha512-ghi}
+    peerDependencies:
+      react: 17.0.2
+    dev: false
+
+  /react/17.0.2:
+    resolution: {integrity: sha512-jkl}
+    dev: false
+
+  github.com/example/private-lib/1a2b3c4:
+    resolution: {tarball: https://codeload.github.com/example/private-lib/tar.gz/1a2b3c4}
+    name: private-lib
+    version: 1.0.0
+    dev: false
+`
+
+// pnpmLockV6 is a pnpm-lock.yaml with lockfileVersion 6.0
+const pnpmLockV6 = `lockfileVersion: '6.0'
+
+dependencies:
+  '@babel/core':
+    specifier: ^7.0.0
+    version: 7.0.0
+  react-dom:
+    specifier: ^17.0.2
+    version: 17.0.2(react@17.0.2)
+
+packages:
+
+  /@babel/core@7.0.0:
+    resolution: {integrity: sha512-abc}
+    dependencies:
+      minimist: 1.2.5
+    dev: false
+
+  /minimist@1.2.5:
+    resolution: {integrity: sha512-def}
+    dev: false
+
+  /react-dom@17.0.2(react@17.0.2):
+    resolution: {integrity: sha512-ghi}
+    peerDependencies:
+      react: 17.0.2
+    dev: false
+
+  /react@17.0.2:
+    resolution: {integrity: sha512-jkl}
+    dev: false
+
+  file:../local-lib:
+    resolution: {directory: ../local-lib, type: directory}
+    name: local-lib
+    dev: false
+`
+
+// pnpmLockV9 is a pnpm-lock.yaml with lockfileVersion 9.0
+const pnpmLockV9 = `lockfileVersion: '9.0'
+
+settings:
+  autoInstallPeers: true
+  excludeLinksFromLockfile: false
+
+importers:
+
+  .:
+    dependencies:
+      '@babel/core':
+        specifier: ^7.0.0
+        version: 7.0.0
+      react-dom:
+        specifier: ^17.0.2
+        version: 17.0.2(react@17.0.2)
+
+  packages/ui:
+    dependencies:
+      minimist:
+        specifier: 1.2.5
+        version: 1.2.5
+
+packages:
+
+  '@babel/core@7.0.0':
+    resolution: {integrity: sha512-abc}
+
+  minimist@1.2.5:
+    resolution: {integrity: sha512-def}
+
+  react-dom@17.0.2:
+    resolution: {integrity: sha512-ghi}
+    peerDependencies:
+      react: 17.0.2
+
+  react@17.0.2:
+    resolution: {integrity: sha512-jkl}
+
+snapshots:
+
+  '@babel/core@7.0.0':
+    dependencies:
+      minimist: 1.2.5
+
+  minimist@1.2.5: {}
+
+  react-dom@17.0.2(react@17.0.2):
+    dependencies:
+      react: 17.0.2
+
+  react@17.0.2: {}
+`
+
+func TestParsePnpmLock(t *testing.T) {
+	expected := []NpmPackage{
+		{Name: "@babel/core", Version: "7.0.0", IsDirect: true},
+		{Name: "minimist", Version: "1.2.5"},
+		{Name: "react", Version: "17.0.2"},
+		{Name: "react-dom", Version: "17.0.2", IsDirect: true},
+	}
+
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "v5", content: pnpmLockV5},
+		{name: "v6", content: pnpmLockV6},
+		{name: "v9", content: pnpmLockV9},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "pnpm-lock.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write pnpm-lock.yaml: %v", err)
+			}
+
+			packages, err := ParsePnpmLock(path)
+			if err != nil {
+				t.Fatalf("ParsePnpmLock() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(packages, expected) {
+				t.Errorf("ParsePnpmLock() = %+v, expected %+v", packages, expected)
+			}
+		})
+	}
+}
+
+func TestParsePnpmLockRejectsInvalidYaml(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pnpm-lock.yaml")
+	if err := os.WriteFile(path, []byte("lockfileVersion: '9.0'\npackages:\n  minimist@1.2.5: {resolution: [\n"), 0644); err != nil {
+		t.Fatalf("Failed to write pnpm-lock.yaml: %v", err)
+	}
+
+	if _, err := ParsePnpmLock(path); err == nil {
+		t.Error("ParsePnpmLock() expected error for invalid YAML")
+	}
+}
+
+func TestRunNpmOSVAuditReadsPnpmLock(t *testing.T) {
+	server := newMockOSVServer(t, func(request osv.QueryRequest) []osv.Vulnerability {
+		if request.Package.Name == "minimist" && request.Package.Version == "1.2.5" {
+			return []osv.Vulnerability{{ID: "GHSA-xvch-5gv4-984h", Summary: "Prototype Pollution in minimist"}}
+		}
+		return nil
+	})
+
+	tmpDir := t.TempDir()
+	packageJSONPath := filepath.Join(tmpDir, "package.json")
+	if err := os.WriteFile(packageJSONPath, []byte(`{"name": "app", "dependencies": {"@babel/core": "^7.0.0"}}`), 0644); err != nil {
+		t.Fatalf("Failed to write package.json: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "pnpm-lock.yaml"), []byte(pnpmLockV9), 0644); err != nil {
+		t.Fatalf("Failed to write pnpm-lock.yaml: %v", err)
+	}
+
+	runner := NewRunner(0, false)
+	runner.osvClient = osv.NewClientWithURL(server.URL)
+	result := runner.RunNpmOSVAudit(packageJSONPath)
+
+	if result.Error != nil {
+		t.Fatalf("RunNpmOSVAudit() unexpected error: %v", result.Error)
+	}
+	if result.PackagesScanned != 4 {
+		t.Errorf("PackagesScanned = %d, expected 4", result.PackagesScanned)
+	}
+	if result.Summary.Total != 1 || result.Vulnerabilities[0].Name != "minimist" || result.Vulnerabilities[0].IsDirect {
+		t.Errorf("expected one transitive minimist finding, got %+v", result.Vulnerabilities)
+	}
+}
@@ -0,0 +1,177 @@
+package audit
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// pyprojectFile holds the dependency tables of a pyproject.toml: PEP 621's
+// [project] and Poetry's [tool.poetry]
+type pyprojectFile struct {
+	Project struct {
+		Dependencies         []string            `toml:"dependencies"`
+		OptionalDependencies map[string][]string `toml:"optional-dependencies"`
+	} `toml:"project"`
+	Tool struct {
+		Poetry struct {
+			Dependencies    map[string]any `toml:"dependencies"`
+			DevDependencies map[string]any `toml:"dev-dependencies"`
+			Group           map[string]struct {
+				Dependencies map[string]any `toml:"dependencies"`
+			} `toml:"group"`
+		} `toml:"poetry"`
+	} `toml:"tool"`
+}
+
+// pep508Requirement splits a PEP 508 requirement such as
+// "requests[security]>=2.28; python_version < '3.8'" into its name and the
+// rest, once the environment marker has been removed
+var pep508Requirement = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)\s*(?:\[[^\]]*\])?\s*(.*)$`)
+
+// ParsePyprojectToml parses a pyproject.toml file and extracts dependencies
+// from [project] dependencies and optional-dependencies (PEP 621) and from
+// [tool.poetry] dependencies, dev-dependencies, and every dependency group.
+// Only exact pins keep their version; ranges such as ^2.28 or >=2.0 are
+// queried for all versions. Path, git, and URL dependencies are skipped since
+// they don't come from PyPI.
+func ParsePyprojectToml(path string) (packages []PythonPackage, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open pyproject.toml: %w", err)
+	}
+	defer func() {
+		if closeErr := file.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("failed to close file: %w", closeErr)
+		}
+	}()
+
+	return ParsePyprojectTomlFromReader(file)
+}
+
+// ParsePyprojectTomlFromReader parses the contents of a pyproject.toml file
+// read from r, the way ParsePyprojectToml does
+func ParsePyprojectTomlFromReader(r io.Reader) ([]PythonPackage, error) {
+	var pyproject pyprojectFile
+	if _, err := toml.NewDecoder(r).Decode(&pyproject); err != nil {
+		return nil, fmt.Errorf("failed to parse pyproject.toml: %w", err)
+	}
+
+	var packages []PythonPackage
+	seen := make(map[PythonPackage]bool)
+	add := func(name, version string) {
+		pkg := PythonPackage{Name: name, Version: version}
+		if !seen[pkg] {
+			seen[pkg] = true
+			packages = append(packages, pkg)
+		}
+	}
+
+	project := pyproject.Project
+	requirements := project.Dependencies
+	for _, extra := range sortedKeys(project.OptionalDependencies) {
+		requirements = append(requirements, project.OptionalDependencies[extra]...)
+	}
+	for _, requirement := range requirements {
+		if name, version, ok := parsePEP508Requirement(requirement); ok {
+			add(name, version)
+		}
+	}
+
+	poetry := pyproject.Tool.Poetry
+	tables := []map[string]any{poetry.Dependencies, poetry.DevDependencies}
+	for _, group := range sortedKeys(poetry.Group) {
+		tables = append(tables, poetry.Group[group].Dependencies)
+	}
+	for _, table := range tables {
+		for _, name := range sortedKeys(table) {
+			// The python entry constrains the interpreter, not a package
+			if strings.EqualFold(name, "python") {
+				continue
+			}
+			if version, ok := poetryDependencyVersion(table[name]); ok {
+				add(name, version)
+			}
+		}
+	}
+
+	return packages, nil
+}
+
+// parsePEP508Requirement returns the name and pinned version of a PEP 508
+// requirement. Direct references (name @ url) are not PyPI packages and fail.
+func parsePEP508Requirement(requirement string) (name, version string, ok bool) {
+	if i := strings.Index(requirement, ";"); i >= 0 {
+		requirement = requirement[:i]
+	}
+
+	matches := pep508Requirement.FindStringSubmatch(strings.TrimSpace(requirement))
+	if matches == nil {
+		return "", "", false
+	}
+	specifier := strings.TrimSpace(matches[2])
+	if strings.HasPrefix(specifier, "@") {
+		return "", "", false
+	}
+
+	// Older metadata wraps the specifier in parentheses: name (>=1.0)
+	specifier = strings.TrimSuffix(strings.TrimPrefix(specifier, "("), ")")
+	return matches[1], pinnedPythonVersion(specifier), true
+}
+
+// poetryDependencyVersion returns the pinned version of a Poetry dependency,
+// written as a constraint string, a table with a version key, or a list of
+// constraints for different environments. Tables without a version name a
+// path, git, or URL source and fail.
+func poetryDependencyVersion(value any) (string, bool) {
+	switch dep := value.(type) {
+	case string:
+		return pinnedPythonVersion(dep), true
+	case map[string]any:
+		constraint, ok := dep["version"].(string)
+		if !ok {
+			return "", false
+		}
+		return pinnedPythonVersion(constraint), true
+	case []map[string]any:
+		// Environment-specific constraints can't be narrowed to one version
+		return "", true
+	case []any:
+		return "", true
+	}
+	return "", false
+}
+
+// pinnedPythonVersion returns the version a specifier pins exactly: ==2.28.0,
+// ===2.28.0, or Poetry's bare 2.28.0. Ranges such as ^2.28, ~2.28, ~=2.28,
+// >=2.0,<3, and wildcards like ==2.* return "" so every version is queried.
+func pinnedPythonVersion(specifier string) string {
+	version := strings.TrimSpace(specifier)
+	if rest, ok := strings.CutPrefix(version, "==="); ok {
+		version = rest
+	} else if rest, ok := strings.CutPrefix(version, "=="); ok {
+		version = rest
+	}
+	version = strings.TrimSpace(version)
+
+	if version == "" || version[0] < '0' || version[0] > '9' || strings.ContainsAny(version, ",*<>=!~^| ") {
+		return ""
+	}
+	return version
+}
+
+// sortedKeys returns the keys of m in order, so packages are listed the same
+// way on every run
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,146 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// poetryPyproject is the pyproject.toml of a Poetry project
+const poetryPyproject = `[tool.poetry]
+name = "app"
+version = "0.1.0"
+
+[tool.poetry.dependencies]
+python = "^3.10"
+requests = "^2.28.0"
+django = "4.2.0"
+flask = { version = "==2.0.0", extras = ["async"] }
+internal-lib = { path = "../internal-lib", develop = true }
+private-lib = { git = "https://github.com/example/private-lib.git" }
+numpy = [
+    { version = "1.24.0", python = "<3.12" },
+    { version = "1.26.0", python = ">=3.12" },
+]
+
+[tool.poetry.group.dev.dependencies]
+pytest = "~7.4"
+black = "*"
+
+[tool.poetry.group.docs.dependencies]
+sphinx = "7.2.6"
+`
+
+// pep621Pyproject is the pyproject.toml of a project using PEP 621 metadata
+const pep621Pyproject = `[build-system]
+requires = ["setuptools>=61"]
+build-backend = "setuptools.build_meta"
+
+[project]
+name = "app"
+version = "0.1.0"
+dependencies = [
+    "requests[security]>=2.28",
+    "django==4.2.0",
+    "urllib3 === 1.26.5",
+    "pyyaml ~= 6.0",
+    "attrs",
+    "tomli==2.0.1; python_version < '3.11'",
+    "mylib @ https://example.com/mylib-1.0.tar.gz",
+]
+
+[project.optional-dependencies]
+test = ["pytest==7.4.0", "django==4.2.0"]
+docs = ["sphinx>=7,<8"]
+`
+
+func TestParsePyprojectToml(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected []PythonPackage
+	}{
+		{
+			name:    "poetry",
+			content: poetryPyproject,
+			expected: []PythonPackage{
+				{Name: "django", Version: "4.2.0"},
+				{Name: "flask", Version: "2.0.0"},
+				{Name: "numpy", Version: ""},
+				{Name: "requests", Version: ""},
+				{Name: "black", Version: ""},
+				{Name: "pytest", Version: ""},
+				{Name: "sphinx", Version: "7.2.6"},
+			},
+		},
+		{
+			name:    "pep 621",
+			content: pep621Pyproject,
+			expected: []PythonPackage{
+				{Name: "requests", Version: ""},
+				{Name: "django", Version: "4.2.0"},
+				{Name: "urllib3", Version: "1.26.5"},
+				{Name: "pyyaml", Version: ""},
+				{Name: "attrs", Version: ""},
+				{Name: "tomli", Version: "2.0.1"},
+				{Name: "sphinx", Version: ""},
+				{Name: "pytest", Version: "7.4.0"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "pyproject.toml")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write pyproject.toml: %v", err)
+			}
+
+			packages, err := ParsePyprojectToml(path)
+			if err != nil {
+				t.Fatalf("ParsePyprojectToml() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(packages, tt.expected) {
+				t.Errorf("ParsePyprojectToml() = %+v, expected %+v", packages, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParsePyprojectTomlFromReader(t *testing.T) {
+	packages, err := ParsePyprojectTomlFromReader(strings.NewReader(pep621Pyproject))
+	if err != nil {
+		t.Fatalf("ParsePyprojectTomlFromReader() unexpected error: %v", err)
+	}
+
+	expected := []PythonPackage{
+		{Name: "requests", Version: ""},
+		{Name: "django", Version: "4.2.0"},
+		{Name: "urllib3", Version: "1.26.5"},
+		{Name: "pyyaml", Version: ""},
+		{Name: "attrs", Version: ""},
+		{Name: "tomli", Version: "2.0.1"},
+		{Name: "sphinx", Version: ""},
+		{Name: "pytest", Version: "7.4.0"},
+	}
+	if !reflect.DeepEqual(packages, expected) {
+		t.Errorf("ParsePyprojectTomlFromReader() = %+v, expected %+v", packages, expected)
+	}
+
+	if _, err := ParsePyprojectTomlFromReader(strings.NewReader("[project\n")); err == nil {
+		t.Error("ParsePyprojectTomlFromReader() expected error for invalid TOML")
+	}
+}
+
+func TestParsePyprojectTomlRejectsInvalidToml(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pyproject.toml")
+	if err := os.WriteFile(path, []byte("[project\ndependencies = [\"django==4.2.0\"]\n"), 0644); err != nil {
+		t.Fatalf("Failed to write pyproject.toml: %v", err)
+	}
+
+	if _, err := ParsePyprojectToml(path); err == nil {
+		t.Error("ParsePyprojectToml() expected error for invalid TOML")
+	}
+}
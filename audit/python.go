@@ -2,30 +2,80 @@ package audit
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
+	"slices"
+	"time"
 
+	"github.com/BurntSushi/toml"
 	"github.com/brandonapol/snoop/osv"
+	"github.com/brandonapol/snoop/security"
 )
 
 // PythonVulnerability represents a security vulnerability in a Python package
 type PythonVulnerability struct {
-	Name        string   `json:"name"`
-	Version     string   `json:"version"`
-	ID          string   `json:"id"`
-	FixVersions []string `json:"fix_versions"`
-	Description string   `json:"description"`
-	Aliases     []string `json:"aliases"`
-	Severity    string   `json:"severity"`
+	Name        string    `json:"name"`
+	Version     string    `json:"version"`
+	ID          string    `json:"id"`
+	FixVersions []string  `json:"fix_versions"`
+	Description string    `json:"description"`
+	Aliases     []string  `json:"aliases"`
+	Severity    string    `json:"severity"`
+	Published   time.Time `json:"published,omitzero"`
+	Modified    time.Time `json:"modified,omitzero"`
+	IsDirect    bool      `json:"is_direct"`
+	Unconfirmed bool      `json:"unconfirmed,omitempty"` // The version isn't pinned, so the package is possibly, not certainly, affected
 }
 
+// UnpinnedAdvisoriesID identifies a finding that collapses the advisories of a
+// package queried without a version
+const UnpinnedAdvisoriesID = "UNPINNED-ADVISORIES"
+
 // PythonAuditResult contains the results of running Python vulnerability check
 type PythonAuditResult struct {
-	ManifestPath    string
-	ManifestType    string
-	Vulnerabilities []PythonVulnerability
-	Summary         VulnerabilitySummary
-	PackagesScanned int
-	Error           error
+	ManifestPath     string
+	ManifestType     string
+	Vulnerabilities  []PythonVulnerability
+	Summary          VulnerabilitySummary
+	Notes            []SecurityNote
+	Typosquatting    []security.TyposquattingRisk // Names close to a popular PyPI package
+	PackagesScanned  int
+	WithdrawnSkipped int             // Advisories OSV has withdrawn, left out of the findings
+	Dependencies     []PythonPackage // Every package in the manifest, for SBOM output
+	Warnings         []string        // Non-fatal problems such as failed queries
+	Unverified       bool            // Every OSV query failed, so no findings doesn't mean clean
+	Error            error
+}
+
+// pythonParser reads the packages of a Python manifest, adding any notes and
+// warnings about the file to result
+type pythonParser func(r *Runner, path string, result *PythonAuditResult) ([]PythonPackage, error)
+
+func init() {
+	registerBuiltinParser("requirements.txt", pythonParser(parseRequirementsForAudit))
+	registerBuiltinParser("Pipfile", pythonFileParser(ParsePipfile))
+	registerBuiltinParser("pyproject.toml", pythonFileParser(ParsePyprojectToml))
+	registerBuiltinParser("poetry.lock", pythonFileParser(ParsePoetryLock))
+	registerBuiltinParser("Pipfile.lock", pythonFileParser(ParsePipfileLock))
+}
+
+// pythonFileParser adapts a parser that needs nothing but the manifest's path
+func pythonFileParser(parse func(path string) ([]PythonPackage, error)) pythonParser {
+	return func(_ *Runner, path string, _ *PythonAuditResult) ([]PythonPackage, error) {
+		return parse(path)
+	}
+}
+
+// parseRequirementsForAudit reads a requirements file under the runner's
+// include policy, noting any custom package indexes it configures
+func parseRequirementsForAudit(r *Runner, path string, result *PythonAuditResult) ([]PythonPackage, error) {
+	requirements, err := ParseRequirementsFileWithPolicy(path, r.includePolicy)
+	if err != nil {
+		return nil, err
+	}
+	result.Notes = append(result.Notes, indexNotes(requirements.Indexes)...)
+	result.Warnings = append(result.Warnings, requirements.Warnings...)
+	return requirements.Packages, nil
 }
 
 // RunPythonAudit checks Python packages for vulnerabilities using OSV API
@@ -36,25 +86,19 @@ func (r *Runner) RunPythonAudit(manifestPath string, manifestType string) *Pytho
 	}
 
 	// Parse the manifest file to extract packages
-	var packages []PythonPackage
-	var err error
-
-	switch manifestType {
-	case "requirements.txt":
-		packages, err = ParseRequirementsTxt(manifestPath)
-	case "Pipfile":
-		packages, err = ParsePipfile(manifestPath)
-	case "pyproject.toml":
-		packages, err = ParsePyprojectToml(manifestPath)
-	default:
+	parse, ok := lookupParser[pythonParser](manifestType)
+	if !ok {
 		result.Error = fmt.Errorf("unsupported Python manifest type: %s", manifestType)
 		return result
 	}
 
+	packages, err := parse(r, manifestPath, result)
 	if err != nil {
 		result.Error = fmt.Errorf("failed to parse manifest: %w", err)
 		return result
 	}
+	packages = markDirectPythonPackages(manifestPath, manifestType, packages)
+	packages = withoutExcluded(r, osv.PyPI, packages)
 
 	if len(packages) == 0 {
 		// No packages found, not an error
@@ -62,75 +106,102 @@ func (r *Runner) RunPythonAudit(manifestPath string, manifestType string) *Pytho
 	}
 
 	result.PackagesScanned = len(packages)
+	result.Dependencies = packages
 
 	if r.verbose {
-		fmt.Printf("Found %d packages in %s\n", len(packages), filepath.Base(manifestPath))
+		fmt.Fprintf(os.Stderr, "Found %d packages in %s\n", len(packages), filepath.Base(manifestPath))
 	}
 
-	// Create OSV client
-	osvClient := osv.NewClient()
+	// Names close to a popular PyPI package are only flagged when the
+	// typosquat check is selected, as for npm dependencies
+	if slices.Contains(r.checks, security.CheckTyposquat) {
+		for _, pkg := range packages {
+			if risk := security.CheckTyposquattingPyPI(pkg.Name, 0); risk != nil && risk.Confidence.AtLeast(r.minTyposquatting) {
+				result.Typosquatting = append(result.Typosquatting, *risk)
+			}
+		}
+	}
 
 	// Query OSV for each package
+	osvPkgs := make([]osv.Package, 0, len(packages))
 	for _, pkg := range packages {
+		osvPkgs = append(osvPkgs, osv.Package{
+			Name:      pkg.Name,
+			Version:   pkg.Version,
+			Ecosystem: osv.PyPI,
+		})
+	}
+	responses := r.queryPackages(osvPkgs)
+	result.Unverified = allQueriesFailed(responses)
+
+	for i, pkg := range packages {
 		if r.verbose {
 			if pkg.Version != "" {
-				fmt.Printf("  Checking %s==%s...\n", pkg.Name, pkg.Version)
+				fmt.Fprintf(os.Stderr, "  Checking %s==%s...\n", pkg.Name, pkg.Version)
 			} else {
-				fmt.Printf("  Checking %s (all versions)...\n", pkg.Name)
+				fmt.Fprintf(os.Stderr, "  Checking %s (all versions)...\n", pkg.Name)
 			}
 		}
 
-		// Query OSV API
-		osvPkg := osv.Package{
-			Name:      pkg.Name,
-			Version:   pkg.Version,
-			Ecosystem: osv.PyPI,
-		}
-
-		response, err := osvClient.QueryPackage(osvPkg)
+		response, err := responses[i].Response, responses[i].Err
 		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("failed to query %s: %v", pkg.Name, err))
 			if r.verbose {
-				fmt.Printf("    Warning: Failed to query %s: %v\n", pkg.Name, err)
+				fmt.Fprintf(os.Stderr, "    Warning: Failed to query %s: %v\n", pkg.Name, err)
 			}
 			continue
 		}
 
+		// Withdrawn advisories no longer describe a vulnerability
+		var withdrawn int
+		response.Vulns, withdrawn = dropWithdrawn(response.Vulns)
+		result.WithdrawnSkipped += withdrawn
+
+		// Re-check matches against the advisories' ranges locally
+		response.Vulns = r.filterAffected(osvPkgs[i], response.Vulns)
+
+		// An unpinned package is queried for every version, so drop advisories
+		// fixed before the lowest version its specifier allows
+		response.Vulns = filterBelowMinimum(osvPkgs[i], pkg.MinVersion, response.Vulns)
+
+		// Count advisories that alias one another as a single finding
+		response.Vulns = mergeAliasedAdvisories(response.Vulns)
+
 		// Process vulnerabilities
 		if len(response.Vulns) > 0 {
 			if r.verbose {
-				fmt.Printf("    Found %d vulnerability(ies)\n", len(response.Vulns))
+				fmt.Fprintf(os.Stderr, "    Found %d vulnerability(ies)\n", len(response.Vulns))
+				printAdvisories(response.Vulns)
 			}
 
-			for _, vuln := range response.Vulns {
-				// Extract fix versions
-				fixVersions := extractFixVersions(vuln)
-
-				pythonVuln := PythonVulnerability{
-					Name:        pkg.Name,
-					Version:     pkg.Version,
-					ID:          vuln.ID,
-					FixVersions: fixVersions,
-					Description: vuln.Summary,
-					Aliases:     vuln.Aliases,
-					Severity:    vuln.GetSeverityLevel(),
+			var pythonVulns []PythonVulnerability
+			if pkg.Version == "" && r.maxUnpinnedAdvisories > 0 && len(response.Vulns) > r.maxUnpinnedAdvisories {
+				// Without a pinned version OSV returns advisories for every release,
+				// most of which are stale, so report them as one finding
+				pythonVulns = append(pythonVulns, collapseUnpinnedAdvisories(pkg, response.Vulns))
+			} else {
+				for _, vuln := range response.Vulns {
+					pythonVulns = append(pythonVulns, PythonVulnerability{
+						Name:        pkg.Name,
+						Version:     pkg.Version,
+						ID:          vuln.ID,
+						FixVersions: extractFixVersions(vuln),
+						Description: vuln.Summary,
+						Aliases:     vuln.Aliases,
+						Severity:    vuln.GetSeverityLevel(),
+						Published:   vuln.PublishedTime(),
+						Modified:    vuln.ModifiedTime(),
+						IsDirect:    pkg.IsDirect,
+						Unconfirmed: pkg.Version == "",
+					})
 				}
+			}
 
+			for _, pythonVuln := range pythonVulns {
 				result.Vulnerabilities = append(result.Vulnerabilities, pythonVuln)
 
-				// Update summary based on severity
-				switch pythonVuln.Severity {
-				case "critical":
-					result.Summary.Critical++
-				case "high":
-					result.Summary.High++
-				case "moderate", "medium":
-					result.Summary.Moderate++
-				case "low":
-					result.Summary.Low++
-				default:
-					result.Summary.High++ // Default to high
-				}
-				result.Summary.Total++
+				// Count it in the summary by severity and directness
+				pythonVuln.countIn(&result.Summary)
 			}
 		}
 	}
@@ -138,6 +209,57 @@ func (r *Runner) RunPythonAudit(manifestPath string, manifestType string) *Pytho
 	return result
 }
 
+// collapseUnpinnedAdvisories folds every advisory returned for an unpinned
+// package into a single finding carrying the highest severity among them
+func collapseUnpinnedAdvisories(pkg PythonPackage, vulns []osv.Vulnerability) PythonVulnerability {
+	collapsed := PythonVulnerability{
+		Name:        pkg.Name,
+		ID:          UnpinnedAdvisoriesID,
+		Description: fmt.Sprintf("%d advisories across all versions — pin a version for accurate results", len(vulns)),
+		IsDirect:    pkg.IsDirect,
+		Unconfirmed: true,
+	}
+
+	for _, vuln := range vulns {
+		collapsed.Aliases = append(collapsed.Aliases, vuln.ID)
+		severity := vuln.GetSeverityLevel()
+		if collapsed.Severity == "" || severityRank[Severity(severity)] > severityRank[Severity(collapsed.Severity)] {
+			collapsed.Severity = severity
+		}
+	}
+
+	return collapsed
+}
+
+// indexNotes reports custom package indexes, which are a dependency-confusion
+// vector: a package name can resolve to a different index than intended
+func indexNotes(indexes []PackageIndex) []SecurityNote {
+	var notes []SecurityNote
+	for _, index := range indexes {
+		notes = append(notes, SecurityNote{
+			Severity: SeverityInfo,
+			Message:  fmt.Sprintf("Custom package index configured via %s: %s (possible dependency confusion vector)", index.Option, index.URL),
+			Line:     index.Line,
+		})
+	}
+	return notes
+}
+
+// printAdvisories lists each advisory with its publication dates in verbose output
+func printAdvisories(vulns []osv.Vulnerability) {
+	for _, vuln := range vulns {
+		published, modified := vuln.PublishedTime(), vuln.ModifiedTime()
+		switch {
+		case !published.IsZero() && !modified.IsZero():
+			fmt.Fprintf(os.Stderr, "      %s (published %s, modified %s)\n", vuln.ID, published.Format(time.DateOnly), modified.Format(time.DateOnly))
+		case !published.IsZero():
+			fmt.Fprintf(os.Stderr, "      %s (published %s)\n", vuln.ID, published.Format(time.DateOnly))
+		default:
+			fmt.Fprintf(os.Stderr, "      %s\n", vuln.ID)
+		}
+	}
+}
+
 // extractFixVersions extracts fixed versions from OSV vulnerability
 func extractFixVersions(vuln osv.Vulnerability) []string {
 	var fixVersions []string
@@ -157,6 +279,62 @@ func extractFixVersions(vuln osv.Vulnerability) []string {
 	return fixVersions
 }
 
+// markDirectPythonPackages sets IsDirect on the packages parsed from a
+// manifest. Everything in a requirements file, Pipfile, or pyproject.toml is
+// declared there, so is direct. A lockfile also pins what those depend on, so
+// only the packages declared by the manifest beside it are direct:
+// pyproject.toml for poetry.lock and Pipfile for Pipfile.lock. Without that
+// manifest there's no telling, in which case every locked package is treated
+// as direct.
+func markDirectPythonPackages(manifestPath, manifestType string, packages []PythonPackage) []PythonPackage {
+	var declared map[string]bool
+	switch manifestType {
+	case "poetry.lock":
+		declared = declaredPyprojectPackages(filepath.Join(filepath.Dir(manifestPath), "pyproject.toml"))
+	case "Pipfile.lock":
+		declared = declaredPipfilePackages(filepath.Join(filepath.Dir(manifestPath), "Pipfile"))
+	}
+
+	for i := range packages {
+		packages[i].IsDirect = declared == nil || declared[normalizePythonName(packages[i].Name)]
+	}
+	return packages
+}
+
+// declaredPyprojectPackages returns the normalized names of the packages a
+// pyproject.toml declares, or nil if it can't be read
+func declaredPyprojectPackages(path string) map[string]bool {
+	packages, err := ParsePyprojectToml(path)
+	if err != nil {
+		return nil
+	}
+	declared := make(map[string]bool, len(packages))
+	for _, pkg := range packages {
+		declared[normalizePythonName(pkg.Name)] = true
+	}
+	return declared
+}
+
+// declaredPipfilePackages returns the normalized names of the packages and
+// dev-packages a Pipfile declares, whatever their version requirement, or
+// nil if it can't be read
+func declaredPipfilePackages(path string) map[string]bool {
+	var pipfile struct {
+		Packages    map[string]any `toml:"packages"`
+		DevPackages map[string]any `toml:"dev-packages"`
+	}
+	if _, err := toml.DecodeFile(path, &pipfile); err != nil {
+		return nil
+	}
+	declared := make(map[string]bool, len(pipfile.Packages)+len(pipfile.DevPackages))
+	for _, section := range []map[string]any{pipfile.Packages, pipfile.DevPackages} {
+		for name := range section {
+			declared[normalizePythonName(name)] = true
+		}
+	}
+	return declared
+}
+
 // HasVulnerabilities returns true if the Python audit result contains vulnerabilities
 func (r *PythonAuditResult) HasVulnerabilities() bool {
 	return r.Summary.Total > 0
@@ -0,0 +1,101 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// poetryLockFile is the part of a poetry.lock an audit needs
+type poetryLockFile struct {
+	Packages []struct {
+		Name    string `toml:"name"`
+		Version string `toml:"version"`
+		Source  struct {
+			Type string `toml:"type"`
+		} `toml:"source"`
+	} `toml:"package"`
+}
+
+// poetryLocalSources are [package.source] types that don't resolve from a
+// package index, so their name and version say nothing about PyPI releases
+var poetryLocalSources = map[string]bool{
+	"directory": true,
+	"file":      true,
+	"git":       true,
+	"url":       true,
+}
+
+// ParsePoetryLock returns every package a poetry.lock pins at its exact
+// version, in the order Poetry writes them. Packages installed from a path,
+// git, or URL source are skipped.
+func ParsePoetryLock(path string) ([]PythonPackage, error) {
+	var lock poetryLockFile
+	if _, err := toml.DecodeFile(path, &lock); err != nil {
+		return nil, fmt.Errorf("failed to parse poetry.lock: %w", err)
+	}
+
+	var packages []PythonPackage
+	for _, pkg := range lock.Packages {
+		if pkg.Name == "" || pkg.Version == "" || poetryLocalSources[pkg.Source.Type] {
+			continue
+		}
+		packages = append(packages, PythonPackage{Name: pkg.Name, Version: pkg.Version})
+	}
+	return packages, nil
+}
+
+// pipfileLockFile is the part of a Pipfile.lock an audit needs
+type pipfileLockFile struct {
+	Default map[string]pipfileLockEntry `json:"default"`
+	Develop map[string]pipfileLockEntry `json:"develop"`
+}
+
+// pipfileLockEntry is one locked package. Version is written as ==1.2.3 and
+// is missing for git and path dependencies.
+type pipfileLockEntry struct {
+	Version string `json:"version"`
+}
+
+// ParsePipfileLock returns every package a Pipfile.lock pins, from both the
+// default and develop sections, sorted by name. Packages without a version,
+// such as git or path dependencies, are skipped.
+func ParsePipfileLock(path string) ([]PythonPackage, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Pipfile.lock: %w", err)
+	}
+
+	var lock pipfileLockFile
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("failed to parse Pipfile.lock: %w", err)
+	}
+
+	seen := make(map[PythonPackage]bool)
+	var packages []PythonPackage
+	for _, section := range []map[string]pipfileLockEntry{lock.Default, lock.Develop} {
+		for name, entry := range section {
+			version := strings.TrimSpace(strings.TrimPrefix(entry.Version, "=="))
+			if version == "" {
+				continue
+			}
+			pkg := PythonPackage{Name: name, Version: version}
+			if !seen[pkg] {
+				seen[pkg] = true
+				packages = append(packages, pkg)
+			}
+		}
+	}
+
+	sort.Slice(packages, func(i, j int) bool {
+		if packages[i].Name != packages[j].Name {
+			return packages[i].Name < packages[j].Name
+		}
+		return packages[i].Version < packages[j].Version
+	})
+	return packages, nil
+}
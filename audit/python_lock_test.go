@@ -0,0 +1,173 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/brandonapol/snoop/osv"
+)
+
+// poetryLock is a poetry.lock written by Poetry 1.x
+const poetryLock = `# This file is automatically @generated by Poetry 1.7.1 and should not be changed by hand.
+
+[[package]]
+name = "certifi"
+version = "2023.7.22"
+description = "Python package for providing Mozilla's CA Bundle."
+optional = false
+python-versions = ">=3.6"
+files = [
+    {file = "certifi-2023.7.22-py3-none-any.whl", hash = "sha256:abc"},
+]
+
+[[package]]
+name = "internal-lib"
+version = "0.1.0"
+description = ""
+optional = false
+python-versions = "^3.10"
+files = []
+develop = true
+
+[package.source]
+type = "directory"
+url = "../internal-lib"
+
+[[package]]
+name = "requests"
+version = "2.28.0"
+description = "Python HTTP for Humans."
+optional = false
+python-versions = ">=3.7, <4"
+files = []
+
+[package.dependencies]
+certifi = ">=2017.4.17"
+
+[package.extras]
+socks = ["PySocks (>=1.5.6,!=1.5.7)"]
+
+[metadata]
+lock-version = "2.0"
+python-versions = "^3.10"
+content-hash = "abc123"
+`
+
+// pipfileLock is a Pipfile.lock written by Pipenv
+const pipfileLock = `{
+    "_meta": {
+        "hash": {"sha256": "abc123"},
+        "pipfile-spec": 6,
+        "requires": {"python_version": "3.11"},
+        "sources": [{"name": "pypi", "url": "https://pypi.org/simple", "verify_ssl": true}]
+    },
+    "default": {
+        "requests": {
+            "hashes": ["sha256:abc"],
+            "index": "pypi",
+            "markers": "python_version >= '3.7'",
+            "version": "==2.28.0"
+        },
+        "certifi": {
+            "hashes": ["sha256:def"],
+            "version": "==2023.7.22"
+        },
+        "private-lib": {
+            "git": "https://github.com/example/private-lib.git",
+            "ref": "1a2b3c4"
+        }
+    },
+    "develop": {
+        "pytest": {
+            "hashes": ["sha256:ghi"],
+            "version": "==7.4.0"
+        },
+        "certifi": {
+            "version": "==2023.7.22"
+        }
+    }
+}
+`
+
+func TestParsePoetryLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "poetry.lock")
+	if err := os.WriteFile(path, []byte(poetryLock), 0644); err != nil {
+		t.Fatalf("Failed to write poetry.lock: %v", err)
+	}
+
+	packages, err := ParsePoetryLock(path)
+	if err != nil {
+		t.Fatalf("ParsePoetryLock() unexpected error: %v", err)
+	}
+
+	expected := []PythonPackage{
+		{Name: "certifi", Version: "2023.7.22"},
+		{Name: "requests", Version: "2.28.0"},
+	}
+	if !reflect.DeepEqual(packages, expected) {
+		t.Errorf("ParsePoetryLock() = %+v, expected %+v", packages, expected)
+	}
+}
+
+func TestParsePipfileLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Pipfile.lock")
+	if err := os.WriteFile(path, []byte(pipfileLock), 0644); err != nil {
+		t.Fatalf("Failed to write Pipfile.lock: %v", err)
+	}
+
+	packages, err := ParsePipfileLock(path)
+	if err != nil {
+		t.Fatalf("ParsePipfileLock() unexpected error: %v", err)
+	}
+
+	expected := []PythonPackage{
+		{Name: "certifi", Version: "2023.7.22"},
+		{Name: "pytest", Version: "7.4.0"},
+		{Name: "requests", Version: "2.28.0"},
+	}
+	if !reflect.DeepEqual(packages, expected) {
+		t.Errorf("ParsePipfileLock() = %+v, expected %+v", packages, expected)
+	}
+}
+
+func TestRunPythonAuditQueriesLockedVersions(t *testing.T) {
+	var mu sync.Mutex
+	var queried []osv.Package
+	server := newMockOSVServer(t, func(request osv.QueryRequest) []osv.Vulnerability {
+		mu.Lock()
+		queried = append(queried, request.Package)
+		mu.Unlock()
+		if request.Package.Name == "requests" && request.Package.Version == "2.28.0" {
+			return []osv.Vulnerability{{ID: "GHSA-j8r2-6x86-q33q", Summary: "Unintended leak of Proxy-Authorization header in requests"}}
+		}
+		return nil
+	})
+
+	tmpDir := t.TempDir()
+	lockPath := filepath.Join(tmpDir, "poetry.lock")
+	if err := os.WriteFile(lockPath, []byte(poetryLock), 0644); err != nil {
+		t.Fatalf("Failed to write poetry.lock: %v", err)
+	}
+
+	runner := NewRunner(0, false)
+	runner.osvClient = osv.NewClientWithURL(server.URL)
+	result := runner.RunPythonAudit(lockPath, "poetry.lock")
+
+	if result.Error != nil {
+		t.Fatalf("RunPythonAudit() unexpected error: %v", result.Error)
+	}
+	if result.PackagesScanned != 2 {
+		t.Errorf("PackagesScanned = %d, expected 2", result.PackagesScanned)
+	}
+	for _, pkg := range queried {
+		if pkg.Version == "" {
+			t.Errorf("%s was queried without a version, expected the locked one", pkg.Name)
+		}
+	}
+	if result.Summary.Total != 1 || result.Vulnerabilities[0].Name != "requests" {
+		t.Errorf("expected one requests finding, got %+v", result.Vulnerabilities)
+	}
+}
@@ -3,23 +3,111 @@ package audit
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 )
 
 // PythonPackage represents a Python package with its version
 type PythonPackage struct {
-	Name    string
-	Version string
-	Line    int // Line number where found (for debugging)
+	Name       string
+	Version    string
+	MinVersion string // Lowest version a loose specifier such as >=2.0 allows, when Version isn't pinned
+	Line       int    // Line number where found (for debugging)
+	IsDirect   bool   // Declared in a manifest, not only pinned by a lockfile
+}
+
+// defaultPackageIndexes are the package index URLs pip uses out of the box
+var defaultPackageIndexes = map[string]bool{
+	"https://pypi.org/simple":        true,
+	"https://pypi.python.org/simple": true,
+}
+
+// PackageIndex represents a package index configured in requirements.txt
+type PackageIndex struct {
+	URL    string
+	Option string // The option that configured it, e.g. "--extra-index-url"
+	Line   int
+}
+
+// RequirementsFile contains everything extracted from a requirements.txt file
+// and the files it includes with -r
+type RequirementsFile struct {
+	Packages []PythonPackage
+	Indexes  []PackageIndex // Non-default package indexes only
+	Warnings []string       // Includes that were outside the boundary or unreadable
+}
+
+// IncludePolicy bounds which files -r includes may pull in
+type IncludePolicy struct {
+	// Root is the directory includes must stay within. Empty means the
+	// directory of the top-level requirements file.
+	Root string
+
+	// Strict skips includes outside Root instead of following them
+	Strict bool
 }
 
 // ParseRequirementsTxt parses a requirements.txt file and extracts packages
 func ParseRequirementsTxt(filepath string) ([]PythonPackage, error) {
-	file, err := os.Open(filepath)
+	requirements, err := ParseRequirementsFile(filepath)
+	if err != nil {
+		return nil, err
+	}
+	return requirements.Packages, nil
+}
+
+// ParseRequirementsTxtFromReader parses the contents of a requirements.txt
+// file read from r and extracts packages. -r includes are not followed, since
+// there's no file to resolve them against.
+func ParseRequirementsTxtFromReader(r io.Reader) ([]PythonPackage, error) {
+	requirements := &RequirementsFile{}
+	if err := parseRequirementsReader(r, "", IncludePolicy{}, nil, requirements); err != nil {
+		return nil, err
+	}
+	return requirements.Packages, nil
+}
+
+// ParseRequirementsFile parses a requirements.txt file and extracts packages
+// along with any non-default package indexes it configures
+func ParseRequirementsFile(path string) (*RequirementsFile, error) {
+	return ParseRequirementsFileWithPolicy(path, IncludePolicy{})
+}
+
+// ParseRequirementsFileWithPolicy parses a requirements.txt file, following
+// -r includes relative to the including file. Includes that resolve outside
+// the policy's root are reported as warnings, and skipped in strict mode.
+func ParseRequirementsFileWithPolicy(path string, policy IncludePolicy) (*RequirementsFile, error) {
+	if policy.Root == "" {
+		policy.Root = filepath.Dir(path)
+	}
+	root, err := filepath.Abs(policy.Root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve include root: %w", err)
+	}
+	policy.Root = root
+
+	requirements := &RequirementsFile{}
+	if err := parseRequirementsInto(path, policy, make(map[string]bool), requirements); err != nil {
+		return nil, err
+	}
+	return requirements, nil
+}
+
+// parseRequirementsInto parses one requirements file into requirements,
+// recursing into its -r includes. visited guards against include cycles.
+func parseRequirementsInto(path string, policy IncludePolicy, visited map[string]bool, requirements *RequirementsFile) (err error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+	visited[absPath] = true
+
+	file, err := os.Open(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open requirements.txt: %w", err)
+		return fmt.Errorf("failed to open requirements.txt: %w", err)
 	}
 	defer func() {
 		if closeErr := file.Close(); closeErr != nil && err == nil {
@@ -27,8 +115,14 @@ func ParseRequirementsTxt(filepath string) ([]PythonPackage, error) {
 		}
 	}()
 
-	var packages []PythonPackage
-	scanner := bufio.NewScanner(file)
+	return parseRequirementsReader(file, absPath, policy, visited, requirements)
+}
+
+// parseRequirementsReader parses requirements read from r into requirements.
+// from is the absolute path of the file being read, which -r includes are
+// resolved against; includes are skipped when it is empty.
+func parseRequirementsReader(r io.Reader, from string, policy IncludePolicy, visited map[string]bool, requirements *RequirementsFile) error {
+	scanner := bufio.NewScanner(r)
 	lineNum := 0
 
 	// Regex to match package specifications
@@ -36,66 +130,173 @@ func ParseRequirementsTxt(filepath string) ([]PythonPackage, error) {
 	pkgRegex := regexp.MustCompile(`^([a-zA-Z0-9\-_\.]+)\s*([=<>~!]+)\s*([0-9\.\*]+.*)$`)
 	simplePkgRegex := regexp.MustCompile(`^[a-zA-Z0-9\-_\.]+$`)
 
+	// Lines ending in a backslash continue on the next line (common with --hash)
+	var pending strings.Builder
+	startLine := 0
+
 	for scanner.Scan() {
 		lineNum++
-		line := strings.TrimSpace(scanner.Text())
+		rawLine := strings.TrimSpace(scanner.Text())
+
+		if pending.Len() == 0 {
+			startLine = lineNum
+		}
+		if strings.HasSuffix(rawLine, "\\") {
+			pending.WriteString(strings.TrimSuffix(rawLine, "\\"))
+			pending.WriteString(" ")
+			continue
+		}
+		pending.WriteString(rawLine)
+		line := stripRequirementComment(strings.TrimSpace(pending.String()))
+		pending.Reset()
 
 		// Skip empty lines and comments
-		if line == "" || strings.HasPrefix(line, "#") {
+		if line == "" {
 			continue
 		}
 
-		// Skip lines with -r (requirements file includes)
-		if strings.HasPrefix(line, "-r ") || strings.HasPrefix(line, "--requirement") {
+		// Option lines (--index-url, -f, -r, -c, ...) never declare packages
+		if strings.HasPrefix(line, "-") {
+			option, value := splitRequirementOption(line)
+			switch option {
+			case "-i", "--index-url", "--extra-index-url":
+				if !defaultPackageIndexes[strings.TrimSuffix(value, "/")] {
+					requirements.Indexes = append(requirements.Indexes, PackageIndex{
+						URL:    value,
+						Option: option,
+						Line:   startLine,
+					})
+				}
+			case "-r", "--requirement":
+				if from != "" {
+					includeRequirements(from, value, startLine, policy, visited, requirements)
+				}
+			}
 			continue
 		}
 
-		// Skip editable installs and URLs
-		if strings.HasPrefix(line, "-e ") || strings.Contains(line, "://") {
+		// Skip URLs
+		if strings.Contains(line, "://") {
 			continue
 		}
 
+		// Drop per-requirement options (--hash=...) and environment markers
+		line = stripRequirementOptions(line)
+
 		// Parse package specification
 		matches := pkgRegex.FindStringSubmatch(line)
 		if len(matches) >= 4 {
 			pkg := PythonPackage{
 				Name:    strings.TrimSpace(matches[1]),
 				Version: strings.TrimSpace(matches[3]),
-				Line:    lineNum,
+				Line:    startLine,
 			}
 
 			// Handle version specifiers - for OSV we need exact version
 			// If it's ==, use that version. For other operators, we'll skip for now
 			operator := strings.TrimSpace(matches[2])
 			if operator == "==" {
-				packages = append(packages, pkg)
+				requirements.Packages = append(requirements.Packages, pkg)
 			} else {
 				// For >=, ~=, etc., we can't determine exact version
 				// OSV API can work without version to get all vulns
+				pkg.MinVersion = minimumPythonVersion(operator + pkg.Version)
 				pkg.Version = "" // Query all versions
-				packages = append(packages, pkg)
+				requirements.Packages = append(requirements.Packages, pkg)
 			}
 		} else {
 			// Try simple package name without version
 			if simplePkgRegex.MatchString(line) {
-				packages = append(packages, PythonPackage{
+				requirements.Packages = append(requirements.Packages, PythonPackage{
 					Name:    line,
 					Version: "", // No version specified
-					Line:    lineNum,
+					Line:    startLine,
 				})
 			}
 		}
 	}
 
 	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error reading requirements.txt: %w", err)
+		return fmt.Errorf("error reading requirements.txt: %w", err)
 	}
 
-	return packages, nil
+	return nil
+}
+
+// includeRequirements follows a -r include found on line of the file at
+// from, resolving it relative to that file. Problems are recorded as warnings
+// so one bad include doesn't discard the rest of the manifest.
+func includeRequirements(from, include string, line int, policy IncludePolicy, visited map[string]bool, requirements *RequirementsFile) {
+	if include == "" {
+		return
+	}
+
+	target := include
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(filepath.Dir(from), target)
+	}
+	target = filepath.Clean(target)
+
+	if !isWithin(policy.Root, target) {
+		if policy.Strict {
+			requirements.Warnings = append(requirements.Warnings, fmt.Sprintf("%s line %d: skipping include %q outside %s", filepath.Base(from), line, include, policy.Root))
+			return
+		}
+		requirements.Warnings = append(requirements.Warnings, fmt.Sprintf("%s line %d: include %q resolves outside %s", filepath.Base(from), line, include, policy.Root))
+	}
+
+	if visited[target] {
+		return
+	}
+
+	if err := parseRequirementsInto(target, policy, visited, requirements); err != nil {
+		requirements.Warnings = append(requirements.Warnings, fmt.Sprintf("%s line %d: failed to read include %q: %v", filepath.Base(from), line, include, err))
+	}
+}
+
+// isWithin returns true if path is root or lies beneath it
+func isWithin(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// stripRequirementComment removes a trailing "# comment" from a requirements line
+func stripRequirementComment(line string) string {
+	if strings.HasPrefix(line, "#") {
+		return ""
+	}
+	if idx := strings.Index(line, " #"); idx >= 0 {
+		line = line[:idx]
+	}
+	return strings.TrimSpace(line)
+}
+
+// stripRequirementOptions removes per-requirement options such as --hash and
+// environment markers such as "; python_version < '3.8'"
+func stripRequirementOptions(line string) string {
+	if idx := strings.Index(line, ";"); idx >= 0 {
+		line = line[:idx]
+	}
+	if idx := strings.Index(line, " -"); idx >= 0 {
+		line = line[:idx]
+	}
+	return strings.TrimSpace(line)
+}
+
+// splitRequirementOption splits an option line into its option and value,
+// accepting both "--opt value" and "--opt=value" forms
+func splitRequirementOption(line string) (string, string) {
+	if idx := strings.IndexAny(line, " \t="); idx >= 0 {
+		return line[:idx], strings.TrimSpace(line[idx+1:])
+	}
+	return line, ""
 }
 
 // ParsePipfile parses a Pipfile and extracts packages
-func ParsePipfile(filepath string) ([]PythonPackage, error) {
+func ParsePipfile(filepath string) (packages []PythonPackage, err error) {
 	file, err := os.Open(filepath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open Pipfile: %w", err)
@@ -106,8 +307,14 @@ func ParsePipfile(filepath string) ([]PythonPackage, error) {
 		}
 	}()
 
+	return ParsePipfileFromReader(file)
+}
+
+// ParsePipfileFromReader parses the contents of a Pipfile read from r and
+// extracts packages
+func ParsePipfileFromReader(r io.Reader) ([]PythonPackage, error) {
 	var packages []PythonPackage
-	scanner := bufio.NewScanner(file)
+	scanner := bufio.NewScanner(r)
 	inPackagesSection := false
 	lineNum := 0
 
@@ -160,68 +367,3 @@ func ParsePipfile(filepath string) ([]PythonPackage, error) {
 
 	return packages, nil
 }
-
-// ParsePyprojectToml parses a pyproject.toml file and extracts dependencies
-func ParsePyprojectToml(filepath string) ([]PythonPackage, error) {
-	file, err := os.Open(filepath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open pyproject.toml: %w", err)
-	}
-	defer func() {
-		if closeErr := file.Close(); closeErr != nil && err == nil {
-			err = fmt.Errorf("failed to close file: %w", closeErr)
-		}
-	}()
-
-	var packages []PythonPackage
-	scanner := bufio.NewScanner(file)
-	inDependenciesSection := false
-	lineNum := 0
-
-	// Regex to match dependencies in TOML format
-	// Matches: "package==1.0.0", "package>=1.0.0", etc.
-	pkgRegex := regexp.MustCompile(`"([a-zA-Z0-9\-_\.]+)\s*([=<>~!]+)\s*([0-9\.]+.*)"`)
-
-	for scanner.Scan() {
-		lineNum++
-		line := strings.TrimSpace(scanner.Text())
-
-		// Check for dependencies section
-		if strings.Contains(line, "dependencies") && strings.Contains(line, "[") {
-			inDependenciesSection = true
-			continue
-		}
-
-		// Check for end of array
-		if inDependenciesSection && strings.Contains(line, "]") {
-			inDependenciesSection = false
-			continue
-		}
-
-		// Parse dependencies
-		if inDependenciesSection && line != "" && !strings.HasPrefix(line, "#") {
-			matches := pkgRegex.FindStringSubmatch(line)
-			if len(matches) >= 4 {
-				operator := strings.TrimSpace(matches[2])
-				version := strings.TrimSpace(matches[3])
-
-				// For == we use exact version, for others we query all versions
-				if operator != "==" {
-					version = ""
-				}
-
-				packages = append(packages, PythonPackage{
-					Name:    strings.TrimSpace(matches[1]),
-					Version: version,
-					Line:    lineNum,
-				})
-			}
-		}
-	}
-
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error reading pyproject.toml: %w", err)
-	}
-
-	return packages, nil
-}
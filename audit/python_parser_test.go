@@ -0,0 +1,221 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseRequirementsFileSkipsOptionLines(t *testing.T) {
+	tmpDir := t.TempDir()
+	requirementsPath := filepath.Join(tmpDir, "requirements.txt")
+	content := `--index-url https://pypi.org/simple/
+--extra-index-url=https://packages.example.com/simple
+-i https://pypi.org/simple
+--find-links ./wheels
+-f https://example.com/wheels
+--trusted-host packages.example.com
+--no-binary :all:
+--pre
+-r base.txt
+-c constraints.txt
+-e ./local-package
+requests==2.31.0 \
+    --hash=sha256:aaaa \
+    --hash=sha256:bbbb
+flask>=2.0  # web framework
+urllib3==1.26.5; python_version < "3.10"
+six
+`
+	if err := os.WriteFile(requirementsPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write requirements.txt: %v", err)
+	}
+
+	requirements, err := ParseRequirementsFile(requirementsPath)
+	if err != nil {
+		t.Fatalf("ParseRequirementsFile() unexpected error: %v", err)
+	}
+
+	expected := []PythonPackage{
+		{Name: "requests", Version: "2.31.0", Line: 12},
+		{Name: "flask", Version: "", MinVersion: "2.0", Line: 15},
+		{Name: "urllib3", Version: "1.26.5", Line: 16},
+		{Name: "six", Version: "", Line: 17},
+	}
+	if len(requirements.Packages) != len(expected) {
+		t.Fatalf("ParseRequirementsFile() returned %d packages, expected %d: %+v", len(requirements.Packages), len(expected), requirements.Packages)
+	}
+	for i, pkg := range requirements.Packages {
+		if pkg != expected[i] {
+			t.Errorf("package %d = %+v, expected %+v", i, pkg, expected[i])
+		}
+	}
+
+	// Only the non-default extra index should be recorded
+	if len(requirements.Indexes) != 1 {
+		t.Fatalf("ParseRequirementsFile() returned %d indexes, expected 1: %+v", len(requirements.Indexes), requirements.Indexes)
+	}
+	index := requirements.Indexes[0]
+	if index.URL != "https://packages.example.com/simple" || index.Option != "--extra-index-url" || index.Line != 2 {
+		t.Errorf("unexpected index: %+v", index)
+	}
+}
+
+func TestParseRequirementsTxtFromReader(t *testing.T) {
+	content := `-r base.txt
+requests==2.31.0
+flask>=2.0
+`
+	packages, err := ParseRequirementsTxtFromReader(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("ParseRequirementsTxtFromReader() unexpected error: %v", err)
+	}
+
+	// The include has no file to be resolved against, so it's not followed
+	expected := []PythonPackage{
+		{Name: "requests", Version: "2.31.0", Line: 2},
+		{Name: "flask", Version: "", MinVersion: "2.0", Line: 3},
+	}
+	if !reflect.DeepEqual(packages, expected) {
+		t.Errorf("ParseRequirementsTxtFromReader() = %+v, expected %+v", packages, expected)
+	}
+}
+
+func TestParsePipfileFromReader(t *testing.T) {
+	content := `[[source]]
+url = "https://pypi.org/simple"
+
+[packages]
+django = "==3.2.0"
+requests = "*"
+
+[dev-packages]
+pytest = "==7.4.0"
+`
+	packages, err := ParsePipfileFromReader(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("ParsePipfileFromReader() unexpected error: %v", err)
+	}
+
+	expected := []PythonPackage{
+		{Name: "django", Version: "3.2.0", Line: 5},
+		{Name: "requests", Version: "", Line: 6},
+	}
+	if !reflect.DeepEqual(packages, expected) {
+		t.Errorf("ParsePipfileFromReader() = %+v, expected %+v", packages, expected)
+	}
+}
+
+func TestCustomIndexReportedAsNote(t *testing.T) {
+	tmpDir := t.TempDir()
+	requirementsPath := filepath.Join(tmpDir, "requirements.txt")
+	content := "--index-url https://internal.example.com/simple\n"
+	if err := os.WriteFile(requirementsPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write requirements.txt: %v", err)
+	}
+
+	runner := NewRunner(0, false)
+	result := runner.RunPythonAudit(requirementsPath, "requirements.txt")
+	if result.Error != nil {
+		t.Fatalf("RunPythonAudit() unexpected error: %v", result.Error)
+	}
+
+	if len(result.Notes) != 1 {
+		t.Fatalf("RunPythonAudit() returned %d notes, expected 1", len(result.Notes))
+	}
+	note := result.Notes[0]
+	if note.Severity != SeverityInfo {
+		t.Errorf("note severity = %s, expected %s", note.Severity, SeverityInfo)
+	}
+	if !strings.Contains(note.Message, "https://internal.example.com/simple") {
+		t.Errorf("note message doesn't mention the index URL: %s", note.Message)
+	}
+	if result.Summary.Total != 0 {
+		t.Errorf("informational notes should not count as vulnerabilities, got total %d", result.Summary.Total)
+	}
+}
+
+func TestRequirementsIncludesAreClampedToRoot(t *testing.T) {
+	outerDir := t.TempDir()
+	root := filepath.Join(outerDir, "project")
+	if err := os.MkdirAll(filepath.Join(root, "requirements"), 0755); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	files := map[string]string{
+		filepath.Join(outerDir, "outside.txt"):             "leaked==1.0.0\n",
+		filepath.Join(root, "requirements", "base.txt"):    "django==4.2.0\n-r ../requirements.txt\n",
+		filepath.Join(root, "requirements", "prod.txt"):    "-r base.txt\n-r ../../outside.txt\ngunicorn==21.2.0\n",
+		filepath.Join(root, "requirements.txt"):            "requests==2.31.0\n",
+		filepath.Join(root, "requirements", "missing.txt"): "-r does-not-exist.txt\n",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+	prodPath := filepath.Join(root, "requirements", "prod.txt")
+
+	names := func(requirements *RequirementsFile) string {
+		var names []string
+		for _, pkg := range requirements.Packages {
+			names = append(names, pkg.Name)
+		}
+		return strings.Join(names, ",")
+	}
+
+	tests := []struct {
+		name     string
+		strict   bool
+		packages string
+		warning  string
+	}{
+		{
+			name:     "lenient follows with a warning",
+			strict:   false,
+			packages: "django,requests,leaked,gunicorn",
+			warning:  `prod.txt line 2: include "../../outside.txt" resolves outside`,
+		},
+		{
+			name:     "strict skips the include",
+			strict:   true,
+			packages: "django,requests,gunicorn",
+			warning:  `prod.txt line 2: skipping include "../../outside.txt" outside`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requirements, err := ParseRequirementsFileWithPolicy(prodPath, IncludePolicy{Root: root, Strict: tt.strict})
+			if err != nil {
+				t.Fatalf("ParseRequirementsFileWithPolicy() unexpected error: %v", err)
+			}
+			if got := names(requirements); got != tt.packages {
+				t.Errorf("ParseRequirementsFileWithPolicy() packages = %s, expected %s", got, tt.packages)
+			}
+			if len(requirements.Warnings) != 1 || !strings.Contains(requirements.Warnings[0], tt.warning) {
+				t.Errorf("ParseRequirementsFileWithPolicy() warnings = %v, expected one containing %q", requirements.Warnings, tt.warning)
+			}
+		})
+	}
+
+	// Without an explicit root, includes are bounded by the top-level file's directory
+	requirements, err := ParseRequirementsFile(filepath.Join(root, "requirements", "base.txt"))
+	if err != nil {
+		t.Fatalf("ParseRequirementsFile() unexpected error: %v", err)
+	}
+	if len(requirements.Warnings) != 1 || !strings.Contains(requirements.Warnings[0], "resolves outside") {
+		t.Errorf("ParseRequirementsFile() warnings = %v, expected one escaping include", requirements.Warnings)
+	}
+
+	// A missing include is a warning, not an error
+	requirements, err = ParseRequirementsFileWithPolicy(filepath.Join(root, "requirements", "missing.txt"), IncludePolicy{Root: root})
+	if err != nil {
+		t.Fatalf("ParseRequirementsFileWithPolicy() unexpected error: %v", err)
+	}
+	if len(requirements.Warnings) != 1 || !strings.Contains(requirements.Warnings[0], "failed to read include") {
+		t.Errorf("ParseRequirementsFileWithPolicy() warnings = %v, expected a failed include", requirements.Warnings)
+	}
+}
@@ -0,0 +1,344 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/brandonapol/snoop/osv"
+	"github.com/brandonapol/snoop/security"
+)
+
+// newMockOSVServer returns a test server answering every query with the
+// vulnerabilities produced by respond
+func newMockOSVServer(t *testing.T, respond func(osv.QueryRequest) []osv.Vulnerability) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request osv.QueryRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := json.NewEncoder(w).Encode(osv.QueryResponse{Vulns: respond(request)}); err != nil {
+			t.Errorf("failed to encode mock response: %v", err)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestUnpinnedAdvisoriesAreCollapsed(t *testing.T) {
+	server := newMockOSVServer(t, func(request osv.QueryRequest) []osv.Vulnerability {
+		var vulns []osv.Vulnerability
+		for i := 0; i < 15; i++ {
+			vulns = append(vulns, osv.Vulnerability{ID: fmt.Sprintf("PYSEC-2020-%d", i), Summary: "old issue"})
+		}
+		return vulns
+	})
+
+	tmpDir := t.TempDir()
+	requirementsPath := filepath.Join(tmpDir, "requirements.txt")
+	if err := os.WriteFile(requirementsPath, []byte("django\nflask==2.0.0\n"), 0644); err != nil {
+		t.Fatalf("Failed to write requirements.txt: %v", err)
+	}
+
+	runner := NewRunner(0, false)
+	runner.osvClient = osv.NewClientWithURL(server.URL)
+	runner.SetMaxUnpinnedAdvisories(10)
+
+	result := runner.RunPythonAudit(requirementsPath, "requirements.txt")
+	if result.Error != nil {
+		t.Fatalf("RunPythonAudit() unexpected error: %v", result.Error)
+	}
+
+	// django is unpinned and collapses to one finding; flask is pinned and keeps all 15
+	var django, flask []PythonVulnerability
+	for _, vuln := range result.Vulnerabilities {
+		switch vuln.Name {
+		case "django":
+			django = append(django, vuln)
+		case "flask":
+			flask = append(flask, vuln)
+		}
+	}
+
+	if len(django) != 1 {
+		t.Fatalf("unpinned package produced %d findings, expected 1", len(django))
+	}
+	if django[0].ID != UnpinnedAdvisoriesID {
+		t.Errorf("collapsed finding ID = %s, expected %s", django[0].ID, UnpinnedAdvisoriesID)
+	}
+	if !strings.Contains(django[0].Description, "15 advisories across all versions") {
+		t.Errorf("collapsed finding description = %q", django[0].Description)
+	}
+	if len(django[0].Aliases) != 15 {
+		t.Errorf("collapsed finding lists %d advisory IDs, expected 15", len(django[0].Aliases))
+	}
+	if len(flask) != 15 {
+		t.Errorf("pinned package produced %d findings, expected 15", len(flask))
+	}
+	if result.Summary.Total != 16 {
+		t.Errorf("Summary.Total = %d, expected 16", result.Summary.Total)
+	}
+
+	// Disabling the cap reports every advisory
+	runner.SetMaxUnpinnedAdvisories(0)
+	result = runner.RunPythonAudit(requirementsPath, "requirements.txt")
+	if result.Summary.Total != 30 {
+		t.Errorf("with cap disabled Summary.Total = %d, expected 30", result.Summary.Total)
+	}
+}
+
+func TestAliasedAdvisoriesAreCountedOnce(t *testing.T) {
+	fixedIn := func(version string) []osv.Affected {
+		return []osv.Affected{{
+			Package: osv.Package{Name: "requests", Ecosystem: osv.PyPI},
+			Ranges:  []osv.VersionRange{{Type: "ECOSYSTEM", Events: []osv.Event{{Introduced: "0"}, {Fixed: version}}}},
+		}}
+	}
+	server := newMockOSVServer(t, func(request osv.QueryRequest) []osv.Vulnerability {
+		return []osv.Vulnerability{
+			{ID: "GHSA-xxxx-yyyy-zzzz", Summary: "Leak", Aliases: []string{"CVE-2024-1234"}, Affected: fixedIn("2.31.0")},
+			{ID: "PYSEC-2024-1", Summary: "Proxy-Authorization header leak on redirect", Aliases: []string{"CVE-2024-1234"}, Affected: fixedIn("2.32.0")},
+			{ID: "PYSEC-2024-2", Summary: "Unrelated issue", Aliases: []string{"CVE-2024-9999"}},
+		}
+	})
+
+	tmpDir := t.TempDir()
+	requirementsPath := filepath.Join(tmpDir, "requirements.txt")
+	if err := os.WriteFile(requirementsPath, []byte("requests==2.30.0\n"), 0644); err != nil {
+		t.Fatalf("Failed to write requirements.txt: %v", err)
+	}
+
+	runner := NewRunner(0, false)
+	runner.osvClient = osv.NewClientWithURL(server.URL)
+
+	result := runner.RunPythonAudit(requirementsPath, "requirements.txt")
+	if result.Error != nil {
+		t.Fatalf("RunPythonAudit() unexpected error: %v", result.Error)
+	}
+
+	if len(result.Vulnerabilities) != 2 {
+		t.Fatalf("RunPythonAudit() reported %d findings, expected 2: %+v", len(result.Vulnerabilities), result.Vulnerabilities)
+	}
+	if result.Summary.Total != 2 {
+		t.Errorf("Summary.Total = %d, expected 2", result.Summary.Total)
+	}
+
+	merged := result.Vulnerabilities[0]
+	if merged.ID != "PYSEC-2024-1" {
+		t.Errorf("merged finding ID = %s, expected the richer PYSEC-2024-1", merged.ID)
+	}
+	if merged.Description != "Proxy-Authorization header leak on redirect" {
+		t.Errorf("merged finding description = %q", merged.Description)
+	}
+	if strings.Join(merged.Aliases, ",") != "GHSA-xxxx-yyyy-zzzz,CVE-2024-1234" {
+		t.Errorf("merged finding aliases = %v, expected [GHSA-xxxx-yyyy-zzzz CVE-2024-1234]", merged.Aliases)
+	}
+	if strings.Join(merged.FixVersions, ",") != "2.31.0,2.32.0" {
+		t.Errorf("merged finding fix versions = %v, expected [2.31.0 2.32.0]", merged.FixVersions)
+	}
+}
+
+func TestLargeManifestFallsBackWhenBatchIsUnsupported(t *testing.T) {
+	// The mock only implements /v1/query, so the batch request fails
+	server := newMockOSVServer(t, func(request osv.QueryRequest) []osv.Vulnerability {
+		if request.Package.Name == "urllib3" {
+			return []osv.Vulnerability{{ID: "PYSEC-2023-212", Summary: "Cookie leak on redirect"}}
+		}
+		return nil
+	})
+
+	tmpDir := t.TempDir()
+	requirementsPath := filepath.Join(tmpDir, "requirements.txt")
+	content := "certifi==2023.7.22\nidna==3.4\nrequests==2.31.0\nsix==1.16.0\nurllib3==1.26.5\nzipp==3.17.0\n"
+	if err := os.WriteFile(requirementsPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write requirements.txt: %v", err)
+	}
+
+	runner := NewRunner(0, false)
+	runner.osvClient = osv.NewClientWithURL(server.URL)
+
+	result := runner.RunPythonAudit(requirementsPath, "requirements.txt")
+	if result.Error != nil || result.Unverified || len(result.Warnings) > 0 {
+		t.Fatalf("RunPythonAudit() error = %v, unverified = %v, warnings = %v", result.Error, result.Unverified, result.Warnings)
+	}
+	if len(result.Vulnerabilities) != 1 || result.Vulnerabilities[0].Name != "urllib3" {
+		t.Errorf("RunPythonAudit() findings = %+v, expected the urllib3 advisory", result.Vulnerabilities)
+	}
+}
+
+func TestSummaryCountsCVSSSeverity(t *testing.T) {
+	server := newMockOSVServer(t, func(request osv.QueryRequest) []osv.Vulnerability {
+		return []osv.Vulnerability{
+			{ID: "PYSEC-2024-10", Severity: []osv.Severity{{Type: osv.SeverityCVSSV3, Score: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"}}},
+			{ID: "PYSEC-2024-11", Severity: []osv.Severity{{Type: osv.SeverityCVSSV3, Score: "CVSS:3.1/AV:N/AC:L/PR:N/UI:R/S:C/C:L/I:L/A:N"}}},
+			{ID: "GHSA-aaaa-bbbb-cccc", DatabaseSpecific: map[string]any{"severity": "LOW"}},
+		}
+	})
+
+	tmpDir := t.TempDir()
+	requirementsPath := filepath.Join(tmpDir, "requirements.txt")
+	if err := os.WriteFile(requirementsPath, []byte("jinja2==2.10\n"), 0644); err != nil {
+		t.Fatalf("Failed to write requirements.txt: %v", err)
+	}
+
+	runner := NewRunner(0, false)
+	runner.osvClient = osv.NewClientWithURL(server.URL)
+
+	result := runner.RunPythonAudit(requirementsPath, "requirements.txt")
+	if result.Error != nil {
+		t.Fatalf("RunPythonAudit() unexpected error: %v", result.Error)
+	}
+	summary := result.Summary
+	if summary.Critical != 1 || summary.Moderate != 1 || summary.Low != 1 || summary.High != 0 {
+		t.Errorf("Summary = %+v, expected one critical, one moderate, and one low", summary)
+	}
+}
+
+func TestRunPythonAuditFlagsTyposquatting(t *testing.T) {
+	server := newMockOSVServer(t, func(osv.QueryRequest) []osv.Vulnerability { return nil })
+
+	tmpDir := t.TempDir()
+	requirementsPath := filepath.Join(tmpDir, "requirements.txt")
+	if err := os.WriteFile(requirementsPath, []byte("djanga==4.2.0\nrequests==2.31.0\nPyYAML==6.0\n"), 0644); err != nil {
+		t.Fatalf("Failed to write requirements.txt: %v", err)
+	}
+
+	runner := NewRunner(0, false)
+	runner.osvClient = osv.NewClientWithURL(server.URL)
+
+	// Only the vulnerability checks run by default
+	result := runner.RunPythonAudit(requirementsPath, "requirements.txt")
+	if result.Error != nil {
+		t.Fatalf("RunPythonAudit() unexpected error: %v", result.Error)
+	}
+	if len(result.Typosquatting) != 0 {
+		t.Fatalf("without the typosquat check, flagged %+v, expected none", result.Typosquatting)
+	}
+
+	runner.SetSupplyChainChecks([]string{security.CheckTyposquat})
+	result = runner.RunPythonAudit(requirementsPath, "requirements.txt")
+	if len(result.Typosquatting) != 1 {
+		t.Fatalf("RunPythonAudit() flagged %d typosquats, expected 1: %+v", len(result.Typosquatting), result.Typosquatting)
+	}
+	if risk := result.Typosquatting[0]; risk.PackageName != "djanga" || risk.SimilarTo != "django" {
+		t.Errorf("typosquat = %s similar to %s, expected djanga similar to django", risk.PackageName, risk.SimilarTo)
+	}
+}
+
+func TestLockedPythonPackagesAreDirectOnlyWhenDeclared(t *testing.T) {
+	server := newMockOSVServer(t, func(request osv.QueryRequest) []osv.Vulnerability {
+		return []osv.Vulnerability{{ID: "PYSEC-" + request.Package.Name}}
+	})
+
+	tmpDir := t.TempDir()
+	lock := `[[package]]
+name = "flask-login"
+version = "0.6.2"
+
+[[package]]
+name = "werkzeug"
+version = "2.2.2"
+`
+	pyproject := `[tool.poetry.dependencies]
+python = "^3.10"
+Flask_Login = "^0.6"
+`
+	lockPath := filepath.Join(tmpDir, "poetry.lock")
+	if err := os.WriteFile(lockPath, []byte(lock), 0644); err != nil {
+		t.Fatalf("Failed to write poetry.lock: %v", err)
+	}
+
+	runner := NewRunner(0, false)
+	runner.osvClient = osv.NewClientWithURL(server.URL)
+
+	// Without a pyproject.toml to compare against, every package is direct
+	result := runner.RunPythonAudit(lockPath, "poetry.lock")
+	if result.Error != nil {
+		t.Fatalf("RunPythonAudit() unexpected error: %v", result.Error)
+	}
+	if result.Summary.Direct != 2 || result.Summary.Transitive != 0 {
+		t.Errorf("without pyproject.toml, summary = %+v, expected 2 direct findings", result.Summary)
+	}
+
+	if err := os.WriteFile(filepath.Join(tmpDir, "pyproject.toml"), []byte(pyproject), 0644); err != nil {
+		t.Fatalf("Failed to write pyproject.toml: %v", err)
+	}
+	result = runner.RunPythonAudit(lockPath, "poetry.lock")
+	if result.Error != nil {
+		t.Fatalf("RunPythonAudit() unexpected error: %v", result.Error)
+	}
+
+	direct := make(map[string]bool)
+	for _, vuln := range result.Vulnerabilities {
+		direct[vuln.Name] = vuln.IsDirect
+	}
+	// Flask_Login and flask-login are the same package under PEP 503
+	expected := map[string]bool{"flask-login": true, "werkzeug": false}
+	for name, isDirect := range expected {
+		if direct[name] != isDirect {
+			t.Errorf("%s IsDirect = %v, expected %v", name, direct[name], isDirect)
+		}
+	}
+	if result.Summary.Direct != 1 || result.Summary.Transitive != 1 {
+		t.Errorf("summary = %+v, expected 1 direct and 1 transitive finding", result.Summary)
+	}
+}
+
+func TestPipfileLockDirectnessFollowsPipfile(t *testing.T) {
+	tmpDir := t.TempDir()
+	pipfile := `[packages]
+requests = ">=2.28"
+
+[dev-packages]
+pytest = "*"
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "Pipfile"), []byte(pipfile), 0644); err != nil {
+		t.Fatalf("Failed to write Pipfile: %v", err)
+	}
+
+	packages := markDirectPythonPackages(filepath.Join(tmpDir, "Pipfile.lock"), "Pipfile.lock", []PythonPackage{
+		{Name: "requests", Version: "2.31.0"},
+		{Name: "urllib3", Version: "2.0.4"},
+		{Name: "pytest", Version: "7.4.0"},
+	})
+
+	expected := map[string]bool{"requests": true, "urllib3": false, "pytest": true}
+	for _, pkg := range packages {
+		if pkg.IsDirect != expected[pkg.Name] {
+			t.Errorf("%s IsDirect = %v, expected %v", pkg.Name, pkg.IsDirect, expected[pkg.Name])
+		}
+	}
+}
+
+func TestRunPythonAuditDropsTyposquatsBelowMinConfidence(t *testing.T) {
+	server := newMockOSVServer(t, func(osv.QueryRequest) []osv.Vulnerability { return nil })
+
+	tmpDir := t.TempDir()
+	requirementsPath := filepath.Join(tmpDir, "requirements.txt")
+	// djanga is one edit from django, reqeustz two from requests
+	if err := os.WriteFile(requirementsPath, []byte("djanga==4.2.0\nreqeustz==2.31.0\n"), 0644); err != nil {
+		t.Fatalf("Failed to write requirements.txt: %v", err)
+	}
+
+	runner := NewRunner(0, false)
+	runner.osvClient = osv.NewClientWithURL(server.URL)
+	runner.SetSupplyChainChecks([]string{security.CheckTyposquat})
+
+	result := runner.RunPythonAudit(requirementsPath, "requirements.txt")
+	if len(result.Typosquatting) != 2 {
+		t.Fatalf("without a minimum, flagged %d typosquats, expected 2: %+v", len(result.Typosquatting), result.Typosquatting)
+	}
+
+	runner.SetMinTyposquattingConfidence(security.ConfidenceHigh)
+	result = runner.RunPythonAudit(requirementsPath, "requirements.txt")
+	if len(result.Typosquatting) != 1 || result.Typosquatting[0].PackageName != "djanga" {
+		t.Errorf("at high confidence, flagged %+v, expected only djanga", result.Typosquatting)
+	}
+}
@@ -0,0 +1,141 @@
+package audit
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// pep440Regex matches a PEP 440 version in any of its permitted spellings,
+// such as "1.0", "v2!1.0rc1", "1.0.post2", "1.0-1", or "1.0.dev3+local"
+var pep440Regex = regexp.MustCompile(`^v?(?:(\d+)!)?(\d+(?:\.\d+)*)(?:[-_.]?(alpha|a|beta|b|preview|pre|c|rc)[-_.]?(\d+)?)?(?:-(\d+)|[-_.]?(post|rev|r)[-_.]?(\d+)?)?(?:[-_.]?(dev)[-_.]?(\d+)?)?(?:\+[a-z0-9]+(?:[-_.][a-z0-9]+)*)?$`)
+
+// pep440PreRanks orders the pre-release phases, with their alternate spellings
+var pep440PreRanks = map[string]int{
+	"a": 0, "alpha": 0,
+	"b": 1, "beta": 1,
+	"rc": 2, "c": 2, "pre": 2, "preview": 2,
+}
+
+// pep440Version is a parsed PEP 440 version. A local label such as +cpu is
+// dropped, since it doesn't change which advisories apply.
+type pep440Version struct {
+	epoch   int
+	release []int
+	pre     [2]int // Phase rank and number, ordered so that dev < pre < release
+	post    int    // -1 without a post-release
+	dev     int    // math.MaxInt without a dev release, so it sorts last
+}
+
+// parsePEP440 parses a Python package version
+func parsePEP440(version string) (pep440Version, bool) {
+	matches := pep440Regex.FindStringSubmatch(strings.ToLower(strings.TrimSpace(version)))
+	if matches == nil {
+		return pep440Version{}, false
+	}
+
+	number := func(s string) int {
+		n, _ := strconv.Atoi(s) // A missing number, as in "1.0rc", is 0
+		return n
+	}
+
+	v := pep440Version{epoch: number(matches[1]), post: -1, dev: math.MaxInt}
+	for _, part := range strings.Split(matches[2], ".") {
+		v.release = append(v.release, number(part))
+	}
+	switch {
+	case matches[5] != "":
+		v.post = number(matches[5])
+	case matches[6] != "":
+		v.post = number(matches[7])
+	}
+	if matches[8] != "" {
+		v.dev = number(matches[9])
+	}
+
+	switch {
+	case matches[3] != "":
+		v.pre = [2]int{pep440PreRanks[matches[3]], number(matches[4])}
+	case v.post < 0 && v.dev != math.MaxInt:
+		// 1.0.dev1 comes before 1.0a1
+		v.pre = [2]int{-1, 0}
+	default:
+		v.pre = [2]int{math.MaxInt, 0}
+	}
+	return v, true
+}
+
+// compare orders versions as pip does, returning -1, 0, or 1
+func (v pep440Version) compare(other pep440Version) int {
+	if v.epoch != other.epoch {
+		return sign(v.epoch - other.epoch)
+	}
+	// Trailing zeros don't matter: 1.0 equals 1.0.0
+	for i := 0; i < max(len(v.release), len(other.release)); i++ {
+		var a, b int
+		if i < len(v.release) {
+			a = v.release[i]
+		}
+		if i < len(other.release) {
+			b = other.release[i]
+		}
+		if a != b {
+			return sign(a - b)
+		}
+	}
+	for _, pair := range [][2]int{{v.pre[0], other.pre[0]}, {v.pre[1], other.pre[1]}, {v.post, other.post}, {v.dev, other.dev}} {
+		if pair[0] != pair[1] {
+			if pair[0] < pair[1] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// comparePEP440 orders two Python package versions; ok is false when either
+// isn't a PEP 440 version
+func comparePEP440(a, b string) (int, bool) {
+	va, ok := parsePEP440(a)
+	if !ok {
+		return 0, false
+	}
+	vb, ok := parsePEP440(b)
+	if !ok {
+		return 0, false
+	}
+	return va.compare(vb), true
+}
+
+// minimumPythonVersion returns the lowest version a loose specifier such as
+// ">=2.0,<3", "~=2.28", or "==2.*" allows, or "" when it sets no lower bound.
+// With several lower bounds the highest applies.
+func minimumPythonVersion(specifier string) string {
+	minimum := ""
+	for _, clause := range strings.Split(specifier, ",") {
+		clause = strings.TrimSpace(clause)
+		var version string
+		switch {
+		case strings.HasPrefix(clause, ">="), strings.HasPrefix(clause, "~="):
+			version = clause[2:]
+		case strings.HasPrefix(clause, "=="):
+			version = strings.TrimSuffix(strings.TrimSuffix(clause[2:], "*"), ".")
+		case strings.HasPrefix(clause, ">"):
+			// Everything above the bound is allowed, so the bound is a safe floor
+			version = clause[1:]
+		default:
+			continue
+		}
+
+		version = strings.TrimSpace(version)
+		if _, ok := parsePEP440(version); !ok {
+			continue
+		}
+		if c, _ := comparePEP440(version, minimum); minimum == "" || c > 0 {
+			minimum = version
+		}
+	}
+	return minimum
+}
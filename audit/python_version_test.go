@@ -0,0 +1,68 @@
+package audit
+
+import "testing"
+
+func TestComparePEP440(t *testing.T) {
+	// Each version sorts before the next
+	ordered := []string{
+		"1.0.dev1",
+		"1.0a1",
+		"1.0a2.dev1",
+		"1.0a2",
+		"1.0b1",
+		"1.0rc1",
+		"1.0",
+		"1.0.post1.dev1",
+		"1.0.post1",
+		"1.0.1",
+		"1.10",
+		"2!0.1",
+	}
+	for i := 0; i < len(ordered)-1; i++ {
+		if c, ok := comparePEP440(ordered[i], ordered[i+1]); !ok || c != -1 {
+			t.Errorf("comparePEP440(%q, %q) = %d, %v; expected -1, true", ordered[i], ordered[i+1], c, ok)
+		}
+		if c, ok := comparePEP440(ordered[i+1], ordered[i]); !ok || c != 1 {
+			t.Errorf("comparePEP440(%q, %q) = %d, %v; expected 1, true", ordered[i+1], ordered[i], c, ok)
+		}
+	}
+
+	equal := [][2]string{
+		{"1.0", "1.0.0"},
+		{"1.0rc1", "1.0-RC-1"},
+		{"1.0c1", "1.0rc1"},
+		{"1.0alpha1", "1.0a1"},
+		{"1.0-1", "1.0.post1"},
+		{"v1.0", "1.0+local.7"},
+	}
+	for _, pair := range equal {
+		if c, ok := comparePEP440(pair[0], pair[1]); !ok || c != 0 {
+			t.Errorf("comparePEP440(%q, %q) = %d, %v; expected 0, true", pair[0], pair[1], c, ok)
+		}
+	}
+
+	if _, ok := comparePEP440("1.0", "not-a-version"); ok {
+		t.Error("comparePEP440() accepted an invalid version")
+	}
+}
+
+func TestMinimumPythonVersion(t *testing.T) {
+	tests := []struct {
+		specifier string
+		expected  string
+	}{
+		{">=2.0", "2.0"},
+		{">=2.0,<3", "2.0"},
+		{"~=2.28", "2.28"},
+		{"==2.*", "2"},
+		{">1.5", "1.5"},
+		{">=1.0, >=1.4", "1.4"},
+		{"<3", ""},
+		{"!=2.1", ""},
+	}
+	for _, tt := range tests {
+		if got := minimumPythonVersion(tt.specifier); got != tt.expected {
+			t.Errorf("minimumPythonVersion(%q) = %q, expected %q", tt.specifier, got, tt.expected)
+		}
+	}
+}
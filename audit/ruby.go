@@ -0,0 +1,140 @@
+package audit
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/brandonapol/snoop/osv"
+)
+
+// RubyVulnerability represents a security vulnerability in a Ruby gem
+type RubyVulnerability struct {
+	Name        string    `json:"name"`
+	Version     string    `json:"version"`
+	ID          string    `json:"id"`
+	FixVersions []string  `json:"fix_versions"`
+	Description string    `json:"description"`
+	Aliases     []string  `json:"aliases"`
+	Severity    string    `json:"severity"`
+	Published   time.Time `json:"published,omitzero"`
+	Modified    time.Time `json:"modified,omitzero"`
+	IsDirect    bool      `json:"is_direct"`
+}
+
+// RubyAuditResult contains the results of running Ruby vulnerability check
+type RubyAuditResult struct {
+	ManifestPath     string
+	ManifestType     string
+	Vulnerabilities  []RubyVulnerability
+	Summary          VulnerabilitySummary
+	PackagesScanned  int
+	Dependencies     []RubyGem // Every locked gem, for SBOM output
+	Warnings         []string  // Non-fatal problems such as failed queries
+	Unverified       bool      // Every OSV query failed, so no findings doesn't mean clean
+	WithdrawnSkipped int       // Advisories OSV has withdrawn, left out of the findings
+	Error            error
+}
+
+// RunRubyAudit checks Ruby gems for vulnerabilities using OSV API. Gemfile.lock
+// pins the exact version of every gem in the bundle.
+func (r *Runner) RunRubyAudit(manifestPath string, manifestType string) *RubyAuditResult {
+	result := &RubyAuditResult{
+		ManifestPath: manifestPath,
+		ManifestType: manifestType,
+	}
+
+	// Only parse Gemfile.lock files
+	if manifestType != "Gemfile.lock" {
+		return result
+	}
+
+	packages, err := ParseGemfileLock(manifestPath)
+	if err != nil {
+		result.Error = fmt.Errorf("failed to parse Gemfile.lock: %w", err)
+		return result
+	}
+	packages = withoutExcluded(r, osv.RubyGems, packages)
+
+	if len(packages) == 0 {
+		// No packages found, not an error
+		return result
+	}
+
+	result.PackagesScanned = len(packages)
+	result.Dependencies = packages
+
+	if r.verbose {
+		fmt.Fprintf(os.Stderr, "Found %d Ruby gems in %s\n", len(packages), filepath.Base(manifestPath))
+	}
+
+	osvPkgs := make([]osv.Package, 0, len(packages))
+	for _, pkg := range packages {
+		osvPkgs = append(osvPkgs, osv.Package{
+			Name:      pkg.Name,
+			Version:   pkg.Version,
+			Ecosystem: osv.RubyGems,
+		})
+	}
+	responses := r.queryPackages(osvPkgs)
+	result.Unverified = allQueriesFailed(responses)
+
+	for i, pkg := range packages {
+		if r.verbose {
+			fmt.Fprintf(os.Stderr, "  Checking %s@%s...\n", pkg.Name, pkg.Version)
+		}
+
+		response, err := responses[i].Response, responses[i].Err
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("failed to query %s: %v", pkg.Name, err))
+			if r.verbose {
+				fmt.Fprintf(os.Stderr, "    Warning: Failed to query %s: %v\n", pkg.Name, err)
+			}
+			continue
+		}
+
+		// Withdrawn advisories no longer describe a vulnerability
+		var withdrawn int
+		response.Vulns, withdrawn = dropWithdrawn(response.Vulns)
+		result.WithdrawnSkipped += withdrawn
+
+		// Re-check matches against semver ranges locally
+		response.Vulns = r.filterAffected(osvPkgs[i], response.Vulns)
+
+		// Count advisories that alias one another as a single finding
+		response.Vulns = mergeAliasedAdvisories(response.Vulns)
+
+		if r.verbose && len(response.Vulns) > 0 {
+			fmt.Fprintf(os.Stderr, "    Found %d vulnerability(ies)\n", len(response.Vulns))
+			printAdvisories(response.Vulns)
+		}
+
+		for _, vuln := range response.Vulns {
+			rubyVuln := RubyVulnerability{
+				Name:        pkg.Name,
+				Version:     pkg.Version,
+				ID:          vuln.ID,
+				FixVersions: extractFixVersions(vuln),
+				Description: vuln.Summary,
+				Aliases:     vuln.Aliases,
+				Severity:    vuln.GetSeverityLevel(),
+				Published:   vuln.PublishedTime(),
+				Modified:    vuln.ModifiedTime(),
+				IsDirect:    pkg.IsDirect,
+			}
+
+			result.Vulnerabilities = append(result.Vulnerabilities, rubyVuln)
+
+			// Count it in the summary by severity and directness
+			rubyVuln.countIn(&result.Summary)
+		}
+	}
+
+	return result
+}
+
+// HasVulnerabilities returns true if the Ruby audit result contains vulnerabilities
+func (r *RubyAuditResult) HasVulnerabilities() bool {
+	return r.Summary.Total > 0
+}
@@ -0,0 +1,81 @@
+package audit
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// RubyGem represents a gem pinned in a Gemfile.lock
+type RubyGem struct {
+	Name     string
+	Version  string
+	IsDirect bool
+}
+
+// gemSpecRegex matches a spec line of a Gemfile.lock, such as
+// "    nokogiri (1.10.4-x86_64-linux)". Specs are indented four spaces; the
+// gems they depend on are indented six and carry a requirement, not a version.
+var gemSpecRegex = regexp.MustCompile(`^ {4}([^\s(]+) \(([^)]+)\)$`)
+
+// ParseGemfileLock returns every gem the GEM sections of a Gemfile.lock pin,
+// in the order Bundler writes them. Gems from GIT and PATH sections aren't
+// releases on RubyGems, so they're skipped. A platform suffix such as
+// -x86_64-linux is dropped from the version. A gem is direct when it's listed
+// under DEPENDENCIES, the gems the Gemfile names.
+func ParseGemfileLock(path string) ([]RubyGem, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	var gems []RubyGem
+	seen := make(map[string]bool)
+	direct := make(map[string]bool)
+	section := ""
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+		// Section headers such as GEM, GIT, PLATFORMS, and DEPENDENCIES
+		// aren't indented
+		if !strings.HasPrefix(line, " ") {
+			section = strings.TrimSpace(line)
+			continue
+		}
+
+		switch section {
+		case "GEM":
+			matches := gemSpecRegex.FindStringSubmatch(line)
+			if matches == nil {
+				continue
+			}
+			// Platform-specific builds of a gem are listed once per platform
+			version, _, _ := strings.Cut(matches[2], "-")
+			key := matches[1] + "@" + version
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			gems = append(gems, RubyGem{Name: matches[1], Version: version})
+		case "DEPENDENCIES":
+			// "  rails (~> 6.0.0)", or "  rails!" for a gem from a GIT or PATH source
+			name, _, _ := strings.Cut(strings.TrimSpace(line), " ")
+			direct[strings.TrimSuffix(name, "!")] = true
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading file: %w", err)
+	}
+
+	for i := range gems {
+		gems[i].IsDirect = direct[gems[i].Name]
+	}
+	return gems, nil
+}
@@ -0,0 +1,124 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/brandonapol/snoop/osv"
+)
+
+// gemfileLockFixture is a Gemfile.lock with a gem from git, two platform
+// builds of nokogiri, and gems pulled in by the ones the Gemfile names
+const gemfileLockFixture = `GIT
+  remote: https://github.com/example/internal-gem.git
+  revision: 0123456789abcdef
+  specs:
+    internal-gem (0.1.0)
+
+GEM
+  remote: https://rubygems.org/
+  specs:
+    actionpack (6.0.0)
+      rack (~> 2.0, >= 2.0.8)
+    nokogiri (1.10.4)
+      mini_portile2 (~> 2.4.0)
+    nokogiri (1.10.4-x86_64-linux)
+    mini_portile2 (2.4.0)
+    rack (2.2.3)
+
+PLATFORMS
+  ruby
+
+DEPENDENCIES
+  actionpack (~> 6.0.0)
+  internal-gem!
+  nokogiri
+
+BUNDLED WITH
+   2.1.4
+`
+
+func TestParseGemfileLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Gemfile.lock")
+	if err := os.WriteFile(path, []byte(gemfileLockFixture), 0644); err != nil {
+		t.Fatalf("Failed to write Gemfile.lock: %v", err)
+	}
+
+	gems, err := ParseGemfileLock(path)
+	if err != nil {
+		t.Fatalf("ParseGemfileLock() error = %v", err)
+	}
+
+	expected := []RubyGem{
+		{Name: "actionpack", Version: "6.0.0", IsDirect: true},
+		{Name: "nokogiri", Version: "1.10.4", IsDirect: true},
+		{Name: "mini_portile2", Version: "2.4.0", IsDirect: false},
+		{Name: "rack", Version: "2.2.3", IsDirect: false},
+	}
+	if !reflect.DeepEqual(gems, expected) {
+		t.Errorf("ParseGemfileLock() = %+v, expected %+v", gems, expected)
+	}
+}
+
+func TestParseGemfileLockFixture(t *testing.T) {
+	gems, err := ParseGemfileLock(filepath.Join("..", "test-project-ruby", "Gemfile.lock"))
+	if err != nil {
+		t.Fatalf("ParseGemfileLock() error = %v", err)
+	}
+
+	direct := 0
+	for _, gem := range gems {
+		if gem.IsDirect {
+			direct++
+		}
+	}
+	if len(gems) != 6 || direct != 3 {
+		t.Errorf("ParseGemfileLock() = %+v, expected 6 gems, 3 of them direct", gems)
+	}
+}
+
+func TestParseGemfileLockMissingFile(t *testing.T) {
+	if _, err := ParseGemfileLock(filepath.Join(t.TempDir(), "Gemfile.lock")); err == nil {
+		t.Error("ParseGemfileLock() expected error for missing file")
+	}
+}
+
+func TestRunRubyAuditQueriesLockedVersions(t *testing.T) {
+	server := newMockOSVServer(t, func(request osv.QueryRequest) []osv.Vulnerability {
+		if request.Package.Ecosystem != osv.RubyGems {
+			t.Errorf("query ecosystem = %s, expected %s", request.Package.Ecosystem, osv.RubyGems)
+		}
+		if request.Package.Name == "rack" && request.Package.Version == "2.2.3" {
+			return []osv.Vulnerability{{ID: "GHSA-rack", Summary: "denial of service"}}
+		}
+		return nil
+	})
+
+	lockPath := filepath.Join(t.TempDir(), "Gemfile.lock")
+	if err := os.WriteFile(lockPath, []byte(gemfileLockFixture), 0644); err != nil {
+		t.Fatalf("Failed to write Gemfile.lock: %v", err)
+	}
+
+	runner := NewRunner(0, false)
+	runner.SetOSVClient(osv.NewClientWithURL(server.URL))
+	result := runner.RunRubyAudit(lockPath, "Gemfile.lock")
+
+	if result.Error != nil {
+		t.Fatalf("RunRubyAudit() error = %v", result.Error)
+	}
+	if result.PackagesScanned != 4 {
+		t.Errorf("PackagesScanned = %d, expected 4", result.PackagesScanned)
+	}
+	if len(result.Vulnerabilities) != 1 || result.Vulnerabilities[0].ID != "GHSA-rack" {
+		t.Fatalf("Vulnerabilities = %+v, expected GHSA-rack", result.Vulnerabilities)
+	}
+	// rack is only pulled in by actionpack
+	if result.Vulnerabilities[0].IsDirect {
+		t.Errorf("rack finding IsDirect = true, expected false")
+	}
+	if result.Summary.Total != 1 || result.Summary.Transitive != 1 {
+		t.Errorf("Summary = %+v, expected one transitive finding", result.Summary)
+	}
+}
@@ -0,0 +1,230 @@
+package audit
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/brandonapol/snoop/osv"
+)
+
+// Runtime names as reported in findings
+const (
+	RuntimeNode   = "node"
+	RuntimePython = "python"
+	RuntimeGo     = "go"
+)
+
+// runtimePackages maps a runtime to the OSV package tracking its advisories
+var runtimePackages = map[string]osv.Package{
+	RuntimeNode:   {Name: "node", Ecosystem: osv.Bitnami},
+	RuntimePython: {Name: "python", Ecosystem: osv.Bitnami},
+	RuntimeGo:     {Name: "stdlib", Ecosystem: osv.Go},
+}
+
+// toolVersionsNames maps asdf .tool-versions plugin names to runtimes
+var toolVersionsNames = map[string]string{
+	"nodejs": RuntimeNode,
+	"node":   RuntimeNode,
+	"python": RuntimePython,
+	"golang": RuntimeGo,
+	"go":     RuntimeGo,
+}
+
+// runtimeVersionRegex matches a full major.minor.patch version, optionally prefixed with "v"
+var runtimeVersionRegex = regexp.MustCompile(`^v?([0-9]+\.[0-9]+\.[0-9]+)$`)
+
+// goDirectiveRegex matches the go directive of a go.mod file, e.g. "go 1.21" or "go 1.21.3"
+var goDirectiveRegex = regexp.MustCompile(`^go\s+([0-9]+\.[0-9]+(?:\.[0-9]+)?)\s*$`)
+
+// RuntimeVersion is a language runtime version declared in a project
+type RuntimeVersion struct {
+	Runtime string `json:"runtime"`
+	Version string `json:"version"`
+	Line    int    `json:"line"`
+}
+
+// RuntimeVulnerability represents a security vulnerability in a language runtime
+type RuntimeVulnerability struct {
+	Runtime     string    `json:"runtime"`
+	Version     string    `json:"version"`
+	ID          string    `json:"id"`
+	FixVersions []string  `json:"fix_versions"`
+	Description string    `json:"description"`
+	Aliases     []string  `json:"aliases"`
+	Severity    string    `json:"severity"`
+	Published   time.Time `json:"published,omitzero"`
+	Modified    time.Time `json:"modified,omitzero"`
+}
+
+// RuntimeAuditResult contains the results of checking declared runtime versions
+type RuntimeAuditResult struct {
+	ManifestPath     string
+	ManifestType     string
+	Runtimes         []RuntimeVersion
+	Vulnerabilities  []RuntimeVulnerability
+	Summary          VulnerabilitySummary
+	Warnings         []string // Non-fatal problems such as failed queries
+	Unverified       bool     // Every OSV query failed, so no findings doesn't mean clean
+	WithdrawnSkipped int      // Advisories OSV has withdrawn, left out of the findings
+	Error            error
+}
+
+// ParseRuntimeVersions extracts runtime versions from .nvmrc, .python-version,
+// .tool-versions, or the go directive of a go.mod file. Versions that aren't
+// fully specified (e.g. "lts/*" or "18") are returned as warnings instead.
+func ParseRuntimeVersions(path string, manifestType string) ([]RuntimeVersion, []string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open %s: %w", manifestType, err)
+	}
+	defer func() {
+		if closeErr := file.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("failed to close file: %w", closeErr)
+		}
+	}()
+
+	var runtimes []RuntimeVersion
+	var warnings []string
+	scanner := bufio.NewScanner(file)
+	lineNum := 0
+
+	add := func(runtime, version string) {
+		if matches := runtimeVersionRegex.FindStringSubmatch(version); len(matches) >= 2 {
+			runtimes = append(runtimes, RuntimeVersion{Runtime: runtime, Version: matches[1], Line: lineNum})
+			return
+		}
+		warnings = append(warnings, fmt.Sprintf("%s version %q on line %d is not an exact version, skipping", runtime, version, lineNum))
+	}
+
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if idx := strings.Index(line, "#"); idx >= 0 {
+			line = strings.TrimSpace(line[:idx])
+		}
+		if line == "" {
+			continue
+		}
+
+		switch manifestType {
+		case ".nvmrc":
+			add(RuntimeNode, line)
+		case ".python-version":
+			// pyenv allows several versions, one per line
+			add(RuntimePython, line)
+		case ".tool-versions":
+			fields := strings.Fields(line)
+			runtime, ok := toolVersionsNames[fields[0]]
+			if !ok || len(fields) < 2 {
+				continue
+			}
+			// Later versions are fallbacks, so only the first one is in use
+			add(runtime, fields[1])
+		case "go.mod":
+			// The go directive may omit the patch release
+			if matches := goDirectiveRegex.FindStringSubmatch(line); len(matches) >= 2 {
+				version := matches[1]
+				if strings.Count(version, ".") == 1 {
+					version += ".0"
+				}
+				add(RuntimeGo, version)
+			}
+		default:
+			return nil, nil, fmt.Errorf("unsupported runtime manifest type: %s", manifestType)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("error reading %s: %w", manifestType, err)
+	}
+
+	return runtimes, warnings, nil
+}
+
+// RunRuntimeAudit checks declared language runtime versions for vulnerabilities using OSV API
+func (r *Runner) RunRuntimeAudit(manifestPath string, manifestType string) *RuntimeAuditResult {
+	result := &RuntimeAuditResult{
+		ManifestPath: manifestPath,
+		ManifestType: manifestType,
+	}
+
+	runtimes, warnings, err := ParseRuntimeVersions(manifestPath, manifestType)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+	result.Runtimes = runtimes
+	result.Warnings = append(result.Warnings, warnings...)
+
+	if r.verbose && len(runtimes) > 0 {
+		fmt.Fprintf(os.Stderr, "Found %d runtime version(s) in %s\n", len(runtimes), filepath.Base(manifestPath))
+	}
+
+	failed := 0
+	for _, runtime := range runtimes {
+		if r.verbose {
+			fmt.Fprintf(os.Stderr, "  Checking %s %s...\n", runtime.Runtime, runtime.Version)
+		}
+
+		osvPkg := runtimePackages[runtime.Runtime]
+		osvPkg.Version = runtime.Version
+
+		response, err := r.osvClient.QueryPackageContext(r.ctx, osvPkg)
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("failed to query %s %s: %v", runtime.Runtime, runtime.Version, err))
+			if r.verbose {
+				fmt.Fprintf(os.Stderr, "    Warning: Failed to query %s: %v\n", runtime.Runtime, err)
+			}
+			failed++
+			continue
+		}
+
+		// Withdrawn advisories no longer describe a vulnerability
+		var withdrawn int
+		response.Vulns, withdrawn = dropWithdrawn(response.Vulns)
+		result.WithdrawnSkipped += withdrawn
+
+		// Re-check matches against semver ranges locally
+		response.Vulns = r.filterAffected(osvPkg, response.Vulns)
+
+		// Count advisories that alias one another as a single finding
+		response.Vulns = mergeAliasedAdvisories(response.Vulns)
+
+		if r.verbose && len(response.Vulns) > 0 {
+			fmt.Fprintf(os.Stderr, "    Found %d vulnerability(ies)\n", len(response.Vulns))
+			printAdvisories(response.Vulns)
+		}
+
+		for _, vuln := range response.Vulns {
+			runtimeVuln := RuntimeVulnerability{
+				Runtime:     runtime.Runtime,
+				Version:     runtime.Version,
+				ID:          vuln.ID,
+				FixVersions: extractFixVersions(vuln),
+				Description: vuln.Summary,
+				Aliases:     vuln.Aliases,
+				Severity:    vuln.GetSeverityLevel(),
+				Published:   vuln.PublishedTime(),
+				Modified:    vuln.ModifiedTime(),
+			}
+
+			result.Vulnerabilities = append(result.Vulnerabilities, runtimeVuln)
+
+			// Count it in the summary by severity and directness
+			runtimeVuln.countIn(&result.Summary)
+		}
+	}
+	result.Unverified = len(runtimes) > 0 && failed == len(runtimes)
+
+	return result
+}
+
+// HasVulnerabilities returns true if the runtime audit result contains vulnerabilities
+func (r *RuntimeAuditResult) HasVulnerabilities() bool {
+	return r.Summary.Total > 0
+}
@@ -0,0 +1,106 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/brandonapol/snoop/osv"
+)
+
+func TestParseRuntimeVersions(t *testing.T) {
+	tests := []struct {
+		name         string
+		fileName     string
+		content      string
+		manifestType string
+		expected     []RuntimeVersion
+		warnings     int
+	}{
+		{
+			name:         "nvmrc with v prefix",
+			fileName:     ".nvmrc",
+			content:      "v18.12.0\n",
+			manifestType: ".nvmrc",
+			expected:     []RuntimeVersion{{Runtime: RuntimeNode, Version: "18.12.0", Line: 1}},
+		},
+		{
+			name:         "nvmrc alias",
+			fileName:     ".nvmrc",
+			content:      "lts/*\n",
+			manifestType: ".nvmrc",
+			warnings:     1,
+		},
+		{
+			name:         "tool-versions",
+			fileName:     ".tool-versions",
+			content:      "# runtimes\nnodejs 20.1.0\npython 3.11.4\nruby 3.2.0\n",
+			manifestType: ".tool-versions",
+			expected: []RuntimeVersion{
+				{Runtime: RuntimeNode, Version: "20.1.0", Line: 2},
+				{Runtime: RuntimePython, Version: "3.11.4", Line: 3},
+			},
+		},
+		{
+			name:         "go directive without patch",
+			fileName:     "go.mod",
+			content:      "module example.com/test\n\ngo 1.21\n",
+			manifestType: "go.mod",
+			expected:     []RuntimeVersion{{Runtime: RuntimeGo, Version: "1.21.0", Line: 3}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.fileName)
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write %s: %v", tt.fileName, err)
+			}
+
+			runtimes, warnings, err := ParseRuntimeVersions(path, tt.manifestType)
+			if err != nil {
+				t.Fatalf("ParseRuntimeVersions() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(runtimes, tt.expected) {
+				t.Errorf("ParseRuntimeVersions() = %v, expected %v", runtimes, tt.expected)
+			}
+			if len(warnings) != tt.warnings {
+				t.Errorf("ParseRuntimeVersions() warnings = %v, expected %d", warnings, tt.warnings)
+			}
+		})
+	}
+}
+
+func TestRunRuntimeAuditReportsNodeAdvisory(t *testing.T) {
+	server := newMockOSVServer(t, func(request osv.QueryRequest) []osv.Vulnerability {
+		if request.Package.Ecosystem == osv.Bitnami && request.Package.Name == "node" && request.Package.Version == "18.12.0" {
+			return []osv.Vulnerability{{ID: "BIT-node-2023-0001", Summary: "HTTP request smuggling"}}
+		}
+		return nil
+	})
+
+	nvmrcPath := filepath.Join(t.TempDir(), ".nvmrc")
+	if err := os.WriteFile(nvmrcPath, []byte("v18.12.0\n"), 0644); err != nil {
+		t.Fatalf("Failed to write .nvmrc: %v", err)
+	}
+
+	runner := NewRunner(0, false)
+	runner.osvClient = osv.NewClientWithURL(server.URL)
+
+	result := runner.RunRuntimeAudit(nvmrcPath, ".nvmrc")
+	if result.Error != nil {
+		t.Fatalf("RunRuntimeAudit() unexpected error: %v", result.Error)
+	}
+	if len(result.Vulnerabilities) != 1 {
+		t.Fatalf("RunRuntimeAudit() found %d vulnerabilities, expected 1", len(result.Vulnerabilities))
+	}
+
+	vuln := result.Vulnerabilities[0]
+	if vuln.Runtime != RuntimeNode || vuln.Version != "18.12.0" || vuln.ID != "BIT-node-2023-0001" {
+		t.Errorf("RunRuntimeAudit() vulnerability = %+v, expected node 18.12.0 BIT-node-2023-0001", vuln)
+	}
+	if result.Summary.Total != 1 {
+		t.Errorf("RunRuntimeAudit() summary total = %d, expected 1", result.Summary.Total)
+	}
+}
@@ -0,0 +1,358 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/brandonapol/snoop/osv"
+)
+
+// SBOM document formats
+const (
+	SBOMCycloneDX = "CycloneDX"
+	SBOMSPDX      = "SPDX"
+)
+
+// purlEcosystems maps package URL types to OSV ecosystems
+var purlEcosystems = map[string]osv.Ecosystem{
+	"npm":      osv.NPM,
+	"pypi":     osv.PyPI,
+	"golang":   osv.Go,
+	"maven":    osv.Maven,
+	"gem":      osv.RubyGems,
+	"cargo":    osv.CratesIO,
+	"nuget":    osv.NuGet,
+	"composer": osv.Packagist,
+	"swift":    osv.SwiftURL,
+}
+
+// SBOMComponent is a package listed in an SBOM
+type SBOMComponent struct {
+	Name      string        `json:"name"`
+	Version   string        `json:"version"`
+	Ecosystem osv.Ecosystem `json:"ecosystem"`
+	PURL      string        `json:"purl"`
+}
+
+// SBOMVulnerability represents a security vulnerability in an SBOM component
+type SBOMVulnerability struct {
+	Name        string        `json:"name"`
+	Version     string        `json:"version"`
+	Ecosystem   osv.Ecosystem `json:"ecosystem"`
+	PURL        string        `json:"purl"`
+	ID          string        `json:"id"`
+	FixVersions []string      `json:"fix_versions"`
+	Description string        `json:"description"`
+	Aliases     []string      `json:"aliases"`
+	Severity    string        `json:"severity"`
+	Published   time.Time     `json:"published,omitzero"`
+	Modified    time.Time     `json:"modified,omitzero"`
+}
+
+// SBOMAuditResult contains the results of auditing the components of an SBOM
+type SBOMAuditResult struct {
+	SBOMPath          string
+	Format            string
+	Vulnerabilities   []SBOMVulnerability
+	Summary           VulnerabilitySummary
+	ComponentsScanned int
+	Warnings          []string // Non-fatal problems such as skipped components
+	Unverified        bool     // Every OSV query failed, so no findings doesn't mean clean
+	WithdrawnSkipped  int      // Advisories OSV has withdrawn, left out of the findings
+	Error             error
+}
+
+// cycloneDXComponent is the subset of a CycloneDX component snoop reads
+type cycloneDXComponent struct {
+	Name       string               `json:"name"`
+	Version    string               `json:"version"`
+	PURL       string               `json:"purl"`
+	Components []cycloneDXComponent `json:"components"`
+}
+
+// sbomDocument holds the fields of both CycloneDX and SPDX JSON documents
+type sbomDocument struct {
+	// CycloneDX
+	BOMFormat  string               `json:"bomFormat"`
+	Components []cycloneDXComponent `json:"components"`
+
+	// SPDX
+	SPDXVersion string `json:"spdxVersion"`
+	Packages    []struct {
+		Name         string `json:"name"`
+		VersionInfo  string `json:"versionInfo"`
+		ExternalRefs []struct {
+			ReferenceType    string `json:"referenceType"`
+			ReferenceLocator string `json:"referenceLocator"`
+		} `json:"externalRefs"`
+	} `json:"packages"`
+}
+
+// ParseSBOM reads a CycloneDX or SPDX JSON SBOM and returns its format and the
+// components that map to an OSV ecosystem. Components that can't be audited
+// are reported as warnings.
+func ParseSBOM(path string) (string, []SBOMComponent, []string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("failed to read SBOM: %w", err)
+	}
+
+	var doc sbomDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return "", nil, nil, fmt.Errorf("failed to parse SBOM: %w", err)
+	}
+
+	type entry struct{ name, version, purl string }
+	var entries []entry
+	var format string
+
+	switch {
+	case doc.BOMFormat == SBOMCycloneDX:
+		format = SBOMCycloneDX
+		var walk func(components []cycloneDXComponent)
+		walk = func(components []cycloneDXComponent) {
+			for _, component := range components {
+				entries = append(entries, entry{component.Name, component.Version, component.PURL})
+				walk(component.Components)
+			}
+		}
+		walk(doc.Components)
+	case strings.HasPrefix(doc.SPDXVersion, "SPDX-"):
+		format = SBOMSPDX
+		for _, pkg := range doc.Packages {
+			e := entry{name: pkg.Name, version: pkg.VersionInfo}
+			for _, ref := range pkg.ExternalRefs {
+				if ref.ReferenceType == "purl" {
+					e.purl = ref.ReferenceLocator
+					break
+				}
+			}
+			entries = append(entries, e)
+		}
+	default:
+		return "", nil, nil, fmt.Errorf("unrecognized SBOM format: expected CycloneDX or SPDX JSON")
+	}
+
+	var components []SBOMComponent
+	var warnings []string
+	seen := make(map[string]bool)
+
+	for _, e := range entries {
+		if e.purl == "" {
+			warnings = append(warnings, fmt.Sprintf("skipped %s: no package URL", e.name))
+			continue
+		}
+
+		component, err := ParsePURL(e.purl)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("skipped %s: %v", e.name, err))
+			continue
+		}
+		if component.Version == "" {
+			component.Version = e.version
+		}
+		if component.Version == "" {
+			warnings = append(warnings, fmt.Sprintf("skipped %s: no version", component.Name))
+			continue
+		}
+
+		key := string(component.Ecosystem) + "|" + component.Name + "|" + component.Version
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		components = append(components, component)
+	}
+
+	return format, components, warnings, nil
+}
+
+// ParsePURL converts a package URL such as "pkg:npm/%40scope/name@1.0.0" into
+// a component named the way OSV expects for its ecosystem
+func ParsePURL(purl string) (SBOMComponent, error) {
+	component := SBOMComponent{PURL: purl}
+
+	rest, ok := strings.CutPrefix(purl, "pkg:")
+	if !ok {
+		return component, fmt.Errorf("invalid package URL %q", purl)
+	}
+
+	// Qualifiers and subpath don't affect the package identity
+	if i := strings.IndexAny(rest, "?#"); i >= 0 {
+		rest = rest[:i]
+	}
+
+	purlType, rest, ok := strings.Cut(rest, "/")
+	if !ok {
+		return component, fmt.Errorf("invalid package URL %q", purl)
+	}
+
+	if at := strings.LastIndex(rest, "@"); at >= 0 {
+		version, err := url.PathUnescape(rest[at+1:])
+		if err != nil {
+			return component, fmt.Errorf("invalid package URL %q: %w", purl, err)
+		}
+		component.Version = version
+		rest = rest[:at]
+	}
+
+	var segments []string
+	for _, segment := range strings.Split(rest, "/") {
+		unescaped, err := url.PathUnescape(segment)
+		if err != nil {
+			return component, fmt.Errorf("invalid package URL %q: %w", purl, err)
+		}
+		segments = append(segments, unescaped)
+	}
+
+	purlType = strings.ToLower(purlType)
+	ecosystem, ok := purlEcosystems[purlType]
+	if !ok {
+		return component, fmt.Errorf("unsupported package type %q", purlType)
+	}
+	component.Ecosystem = ecosystem
+
+	switch purlType {
+	case "maven":
+		// OSV names Maven packages "groupId:artifactId"
+		component.Name = strings.Join(segments, ":")
+	case "pypi":
+		// PyPI names are case-insensitive and normalized to lowercase
+		component.Name = strings.ToLower(strings.Join(segments, "/"))
+	default:
+		component.Name = strings.Join(segments, "/")
+	}
+
+	return component, nil
+}
+
+// PackageURL builds the package URL of a package named the way OSV names it,
+// the inverse of ParsePURL. It fails for ecosystems without a purl type.
+func PackageURL(ecosystem osv.Ecosystem, name, version string) (string, bool) {
+	purlType := ""
+	for candidate, candidateEcosystem := range purlEcosystems {
+		if candidateEcosystem == ecosystem {
+			purlType = candidate
+			break
+		}
+	}
+	if purlType == "" || name == "" {
+		return "", false
+	}
+
+	segments := strings.Split(name, "/")
+	switch purlType {
+	case "maven":
+		segments = strings.SplitN(name, ":", 2)
+	case "pypi":
+		// The purl spec normalizes PyPI names to lowercase with dashes
+		segments = []string{strings.ReplaceAll(strings.ToLower(name), "_", "-")}
+	}
+	for i, segment := range segments {
+		// An unescaped "@" would be read as the start of the version
+		segments[i] = strings.ReplaceAll(url.PathEscape(segment), "@", "%40")
+	}
+
+	purl := "pkg:" + purlType + "/" + strings.Join(segments, "/")
+	if version != "" {
+		purl += "@" + url.PathEscape(version)
+	}
+	return purl, true
+}
+
+// RunSBOMAudit checks every component of an SBOM for vulnerabilities using OSV API
+func (r *Runner) RunSBOMAudit(sbomPath string) *SBOMAuditResult {
+	result := &SBOMAuditResult{
+		SBOMPath: sbomPath,
+	}
+
+	format, components, warnings, err := ParseSBOM(sbomPath)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+	result.Format = format
+	result.Warnings = append(result.Warnings, warnings...)
+	// An SBOM has no one ecosystem; each component is excluded by its own
+	components = withoutExcluded(r, "", components)
+	result.ComponentsScanned = len(components)
+
+	if r.verbose {
+		fmt.Fprintf(os.Stderr, "Found %d auditable component(s) in %s SBOM %s\n", len(components), format, filepath.Base(sbomPath))
+	}
+
+	osvPkgs := make([]osv.Package, 0, len(components))
+	for _, component := range components {
+		osvPkgs = append(osvPkgs, osv.Package{
+			Name:      component.Name,
+			Version:   component.Version,
+			Ecosystem: component.Ecosystem,
+		})
+	}
+	responses := r.osvClient.QueryPackagesContext(r.ctx, osvPkgs)
+	result.Unverified = allQueriesFailed(responses)
+
+	for i, component := range components {
+		if r.verbose {
+			fmt.Fprintf(os.Stderr, "  Checking %s@%s (%s)...\n", component.Name, component.Version, component.Ecosystem)
+		}
+
+		response, err := responses[i].Response, responses[i].Err
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("failed to query %s: %v", component.Name, err))
+			if r.verbose {
+				fmt.Fprintf(os.Stderr, "    Warning: Failed to query %s: %v\n", component.Name, err)
+			}
+			continue
+		}
+
+		// Withdrawn advisories no longer describe a vulnerability
+		var withdrawn int
+		response.Vulns, withdrawn = dropWithdrawn(response.Vulns)
+		result.WithdrawnSkipped += withdrawn
+
+		// Re-check matches against semver ranges locally
+		response.Vulns = r.filterAffected(osvPkgs[i], response.Vulns)
+
+		// Count advisories that alias one another as a single finding
+		response.Vulns = mergeAliasedAdvisories(response.Vulns)
+
+		if r.verbose && len(response.Vulns) > 0 {
+			fmt.Fprintf(os.Stderr, "    Found %d vulnerability(ies)\n", len(response.Vulns))
+			printAdvisories(response.Vulns)
+		}
+
+		for _, vuln := range response.Vulns {
+			sbomVuln := SBOMVulnerability{
+				Name:        component.Name,
+				Version:     component.Version,
+				Ecosystem:   component.Ecosystem,
+				PURL:        component.PURL,
+				ID:          vuln.ID,
+				FixVersions: extractFixVersions(vuln),
+				Description: vuln.Summary,
+				Aliases:     vuln.Aliases,
+				Severity:    vuln.GetSeverityLevel(),
+				Published:   vuln.PublishedTime(),
+				Modified:    vuln.ModifiedTime(),
+			}
+
+			result.Vulnerabilities = append(result.Vulnerabilities, sbomVuln)
+
+			// Count it in the summary by severity
+			sbomVuln.countIn(&result.Summary)
+		}
+	}
+
+	return result
+}
+
+// HasVulnerabilities returns true if the SBOM audit result contains vulnerabilities
+func (r *SBOMAuditResult) HasVulnerabilities() bool {
+	return r.Summary.Total > 0
+}
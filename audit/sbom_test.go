@@ -0,0 +1,204 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/brandonapol/snoop/osv"
+)
+
+func TestParsePURL(t *testing.T) {
+	tests := []struct {
+		purl     string
+		expected SBOMComponent
+		wantErr  bool
+	}{
+		{
+			purl:     "pkg:npm/lodash@4.17.20",
+			expected: SBOMComponent{Name: "lodash", Version: "4.17.20", Ecosystem: osv.NPM},
+		},
+		{
+			purl:     "pkg:npm/%40babel/core@7.0.0",
+			expected: SBOMComponent{Name: "@babel/core", Version: "7.0.0", Ecosystem: osv.NPM},
+		},
+		{
+			purl:     "pkg:pypi/Django@3.2.0",
+			expected: SBOMComponent{Name: "django", Version: "3.2.0", Ecosystem: osv.PyPI},
+		},
+		{
+			purl:     "pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1?type=jar",
+			expected: SBOMComponent{Name: "org.apache.logging.log4j:log4j-core", Version: "2.14.1", Ecosystem: osv.Maven},
+		},
+		{
+			purl:     "pkg:golang/github.com/gin-gonic/gin@v1.7.0#subpath",
+			expected: SBOMComponent{Name: "github.com/gin-gonic/gin", Version: "v1.7.0", Ecosystem: osv.Go},
+		},
+		{purl: "pkg:deb/debian/curl@7.68.0", wantErr: true},
+		{purl: "not-a-purl", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.purl, func(t *testing.T) {
+			result, err := ParsePURL(tt.purl)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePURL(%q) error = %v, wantErr %v", tt.purl, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			tt.expected.PURL = tt.purl
+			if result != tt.expected {
+				t.Errorf("ParsePURL(%q) = %+v, expected %+v", tt.purl, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPackageURL(t *testing.T) {
+	tests := []struct {
+		ecosystem osv.Ecosystem
+		name      string
+		version   string
+		expected  string
+	}{
+		{osv.NPM, "lodash", "4.17.19", "pkg:npm/lodash@4.17.19"},
+		{osv.NPM, "@babel/core", "7.0.0", "pkg:npm/%40babel/core@7.0.0"},
+		{osv.PyPI, "Flask_Login", "0.6.2", "pkg:pypi/flask-login@0.6.2"},
+		{osv.PyPI, "requests", "", "pkg:pypi/requests"},
+		{osv.Maven, "org.apache.logging.log4j:log4j-core", "2.14.1", "pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1"},
+		{osv.Go, "github.com/gin-gonic/gin", "v1.7.0", "pkg:golang/github.com/gin-gonic/gin@v1.7.0"},
+		{osv.SwiftURL, "github.com/apple/swift-nio", "2.40.0", "pkg:swift/github.com/apple/swift-nio@2.40.0"},
+		{"Debian", "curl", "7.68.0", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			purl, ok := PackageURL(tt.ecosystem, tt.name, tt.version)
+			if ok != (tt.expected != "") || purl != tt.expected {
+				t.Fatalf("PackageURL(%s, %q, %q) = %q, %v, expected %q", tt.ecosystem, tt.name, tt.version, purl, ok, tt.expected)
+			}
+			if !ok {
+				return
+			}
+
+			component, err := ParsePURL(purl)
+			if err != nil {
+				t.Fatalf("ParsePURL(%q) unexpected error: %v", purl, err)
+			}
+			if component.Ecosystem != tt.ecosystem || component.Version != tt.version {
+				t.Errorf("ParsePURL(%q) = %+v, expected the %s package back", purl, component, tt.ecosystem)
+			}
+		})
+	}
+}
+
+func TestParseSBOMSPDX(t *testing.T) {
+	sbom := `{
+  "spdxVersion": "SPDX-2.3",
+  "packages": [
+    {
+      "name": "lodash",
+      "versionInfo": "4.17.20",
+      "externalRefs": [
+        {"referenceCategory": "PACKAGE-MANAGER", "referenceType": "purl", "referenceLocator": "pkg:npm/lodash"}
+      ]
+    },
+    {"name": "project-root", "versionInfo": "1.0.0"}
+  ]
+}`
+	path := filepath.Join(t.TempDir(), "sbom.spdx.json")
+	if err := os.WriteFile(path, []byte(sbom), 0644); err != nil {
+		t.Fatalf("Failed to write SBOM: %v", err)
+	}
+
+	format, components, warnings, err := ParseSBOM(path)
+	if err != nil {
+		t.Fatalf("ParseSBOM() unexpected error: %v", err)
+	}
+	if format != SBOMSPDX {
+		t.Errorf("ParseSBOM() format = %s, expected %s", format, SBOMSPDX)
+	}
+	// The version comes from versionInfo when the purl omits it
+	if len(components) != 1 || components[0].Name != "lodash" || components[0].Version != "4.17.20" {
+		t.Errorf("ParseSBOM() components = %+v, expected lodash 4.17.20", components)
+	}
+	if len(warnings) != 1 {
+		t.Errorf("ParseSBOM() warnings = %v, expected 1 for the package without a purl", warnings)
+	}
+}
+
+func TestRunSBOMAuditQueriesCycloneDXComponents(t *testing.T) {
+	var mu sync.Mutex
+	var queried []string
+	server := newMockOSVServer(t, func(request osv.QueryRequest) []osv.Vulnerability {
+		mu.Lock()
+		queried = append(queried, string(request.Package.Ecosystem)+":"+request.Package.Name+"@"+request.Package.Version)
+		mu.Unlock()
+
+		if request.Package.Name == "lodash" {
+			return []osv.Vulnerability{{ID: "GHSA-35jh-r3h4-6jhm", Summary: "Command injection in lodash"}}
+		}
+		return nil
+	})
+
+	sbom := `{
+  "bomFormat": "CycloneDX",
+  "specVersion": "1.5",
+  "components": [
+    {"type": "library", "name": "lodash", "version": "4.17.20", "purl": "pkg:npm/lodash@4.17.20"},
+    {
+      "type": "library",
+      "name": "requests",
+      "version": "2.25.0",
+      "purl": "pkg:pypi/requests@2.25.0",
+      "components": [
+        {"type": "library", "name": "urllib3", "version": "1.26.0", "purl": "pkg:pypi/urllib3@1.26.0"}
+      ]
+    },
+    {"type": "library", "name": "log4j-core", "version": "2.14.1", "purl": "pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1"},
+    {"type": "application", "name": "internal-tool", "version": "0.1.0"}
+  ]
+}`
+	sbomPath := filepath.Join(t.TempDir(), "bom.json")
+	if err := os.WriteFile(sbomPath, []byte(sbom), 0644); err != nil {
+		t.Fatalf("Failed to write SBOM: %v", err)
+	}
+
+	runner := NewRunner(0, false)
+	runner.osvClient = osv.NewClientWithURL(server.URL)
+
+	result := runner.RunSBOMAudit(sbomPath)
+	if result.Error != nil {
+		t.Fatalf("RunSBOMAudit() unexpected error: %v", result.Error)
+	}
+	if result.Format != SBOMCycloneDX {
+		t.Errorf("RunSBOMAudit() format = %s, expected %s", result.Format, SBOMCycloneDX)
+	}
+
+	sort.Strings(queried)
+	expected := []string{
+		"Maven:org.apache.logging.log4j:log4j-core@2.14.1",
+		"PyPI:requests@2.25.0",
+		"PyPI:urllib3@1.26.0",
+		"npm:lodash@4.17.20",
+	}
+	if len(queried) != len(expected) {
+		t.Fatalf("RunSBOMAudit() queried %v, expected %v", queried, expected)
+	}
+	for i := range expected {
+		if queried[i] != expected[i] {
+			t.Errorf("RunSBOMAudit() queried %v, expected %v", queried, expected)
+			break
+		}
+	}
+
+	if result.Summary.Total != 1 || result.Vulnerabilities[0].Name != "lodash" {
+		t.Errorf("RunSBOMAudit() vulnerabilities = %+v, expected one for lodash", result.Vulnerabilities)
+	}
+	if len(result.Warnings) != 1 {
+		t.Errorf("RunSBOMAudit() warnings = %v, expected 1 for the component without a purl", result.Warnings)
+	}
+}
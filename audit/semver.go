@@ -0,0 +1,369 @@
+package audit
+
+import (
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/brandonapol/snoop/osv"
+)
+
+// semver is a parsed semantic version; build metadata is dropped because it
+// doesn't affect precedence
+type semver struct {
+	major, minor, patch int
+	prerelease          []string
+}
+
+// parseSemver parses a version such as "1.2.3", "v1.2.3-rc.1", or "1.2.3+build"
+func parseSemver(version string) (semver, bool) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	version, _, _ = strings.Cut(version, "+")
+
+	core, prerelease, hasPrerelease := strings.Cut(version, "-")
+	parts := strings.Split(core, ".")
+	if len(parts) != 3 {
+		return semver{}, false
+	}
+
+	var numbers [3]int
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return semver{}, false
+		}
+		numbers[i] = n
+	}
+
+	v := semver{major: numbers[0], minor: numbers[1], patch: numbers[2]}
+	if hasPrerelease {
+		if prerelease == "" {
+			return semver{}, false
+		}
+		v.prerelease = strings.Split(prerelease, ".")
+	}
+	return v, true
+}
+
+// isPrerelease reports whether v carries pre-release identifiers
+func (v semver) isPrerelease() bool {
+	return len(v.prerelease) > 0
+}
+
+// sameCore reports whether v and other share major, minor, and patch
+func (v semver) sameCore(other semver) bool {
+	return v.major == other.major && v.minor == other.minor && v.patch == other.patch
+}
+
+// compare orders versions by semver precedence, returning -1, 0, or 1
+func (v semver) compare(other semver) int {
+	for _, diff := range []int{v.major - other.major, v.minor - other.minor, v.patch - other.patch} {
+		if diff != 0 {
+			return sign(diff)
+		}
+	}
+
+	// A pre-release has lower precedence than its release
+	switch {
+	case !v.isPrerelease() && !other.isPrerelease():
+		return 0
+	case !v.isPrerelease():
+		return 1
+	case !other.isPrerelease():
+		return -1
+	}
+
+	for i := 0; i < len(v.prerelease) && i < len(other.prerelease); i++ {
+		if c := comparePrereleaseIdentifier(v.prerelease[i], other.prerelease[i]); c != 0 {
+			return c
+		}
+	}
+	return sign(len(v.prerelease) - len(other.prerelease))
+}
+
+// comparePrereleaseIdentifier compares numeric identifiers numerically and
+// others lexically, with numeric identifiers ordered first
+func comparePrereleaseIdentifier(a, b string) int {
+	aNum, aErr := strconv.Atoi(a)
+	bNum, bErr := strconv.Atoi(b)
+	switch {
+	case aErr == nil && bErr == nil:
+		return sign(aNum - bNum)
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// sign returns -1, 0, or 1 according to the sign of n
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
+
+// semverAffected evaluates the SEMVER ranges of an OSV advisory against a
+// version. Unless includePrerelease is set, a pre-release version is only
+// matched by a range with a pre-release bound of the same major.minor.patch,
+// as with npm ranges. ok is false when the advisory has no SEMVER ranges to
+// evaluate or the version isn't semver, leaving the decision to OSV.
+func semverAffected(version string, ranges []osv.VersionRange, includePrerelease bool) (affected bool, ok bool) {
+	v, parsed := parseSemver(version)
+	if !parsed {
+		return false, false
+	}
+
+	for _, vrange := range ranges {
+		if vrange.Type != "SEMVER" {
+			continue
+		}
+
+		type bound struct {
+			version semver
+			event   osv.Event
+		}
+		var bounds []bound
+		sharesCore := false
+		for _, event := range vrange.Events {
+			value := event.Introduced + event.Fixed + event.LastAffected
+			if event.Introduced == "0" {
+				bounds = append(bounds, bound{event: event})
+				continue
+			}
+			boundVersion, parsed := parseSemver(value)
+			if !parsed {
+				return false, false
+			}
+			if boundVersion.isPrerelease() && boundVersion.sameCore(v) {
+				sharesCore = true
+			}
+			bounds = append(bounds, bound{version: boundVersion, event: event})
+		}
+		ok = true
+
+		if v.isPrerelease() && !includePrerelease && !sharesCore {
+			continue
+		}
+
+		sort.SliceStable(bounds, func(i, j int) bool {
+			return bounds[i].version.compare(bounds[j].version) < 0
+		})
+
+		inRange := false
+		for _, b := range bounds {
+			switch {
+			case b.event.Introduced != "":
+				if b.event.Introduced == "0" || v.compare(b.version) >= 0 {
+					inRange = true
+				}
+			case b.event.Fixed != "":
+				if v.compare(b.version) >= 0 {
+					inRange = false
+				}
+			case b.event.LastAffected != "":
+				if v.compare(b.version) > 0 {
+					inRange = false
+				}
+			}
+		}
+		if inRange {
+			return true, true
+		}
+	}
+
+	return false, ok
+}
+
+// versionComparator orders two versions of an ecosystem, returning -1, 0, or
+// 1; ok is false when either version can't be parsed
+type versionComparator func(a, b string) (int, bool)
+
+// ecosystemComparators order the versions of ecosystems whose OSV advisories
+// use ECOSYSTEM ranges, which follow the ecosystem's own version rules rather
+// than semver
+var ecosystemComparators = map[osv.Ecosystem]versionComparator{
+	osv.PyPI:  comparePEP440,
+	osv.Maven: compareMavenVersions,
+}
+
+// ecosystemAffected evaluates the ECOSYSTEM ranges of an OSV advisory against
+// a version, ordering versions with compare. ok is false when the advisory
+// has no ECOSYSTEM ranges or a version can't be parsed.
+func ecosystemAffected(version string, ranges []osv.VersionRange, compare versionComparator) (affected bool, ok bool) {
+	for _, vrange := range ranges {
+		if vrange.Type != "ECOSYSTEM" {
+			continue
+		}
+
+		// Walk the events in version order, entering the range at an
+		// introduced version and leaving it at a fixed or last affected one
+		events := slices.Clone(vrange.Events)
+		valid := true
+		slices.SortStableFunc(events, func(a, b osv.Event) int {
+			c, parsed := compareEventVersions(a, b, compare)
+			valid = valid && parsed
+			return c
+		})
+		if !valid {
+			return false, false
+		}
+
+		inRange := false
+		for _, event := range events {
+			var c int
+			var parsed bool
+			switch {
+			case event.Introduced == "0":
+				inRange = true
+				continue
+			case event.Introduced != "":
+				c, parsed = compare(version, event.Introduced)
+				inRange = inRange || (parsed && c >= 0)
+			case event.Fixed != "":
+				c, parsed = compare(version, event.Fixed)
+				inRange = inRange && !(parsed && c >= 0)
+			case event.LastAffected != "":
+				c, parsed = compare(version, event.LastAffected)
+				inRange = inRange && !(parsed && c > 0)
+			default:
+				continue
+			}
+			if !parsed {
+				return false, false
+			}
+		}
+		ok = true
+		if inRange {
+			return true, true
+		}
+	}
+	return false, ok
+}
+
+// compareEventVersions orders range events by their version, with an
+// introduced "0" before everything
+func compareEventVersions(a, b osv.Event, compare versionComparator) (int, bool) {
+	versionA := a.Introduced + a.Fixed + a.LastAffected
+	versionB := b.Introduced + b.Fixed + b.LastAffected
+	switch {
+	case versionA == "0" && versionB == "0":
+		return 0, true
+	case versionA == "0":
+		return -1, true
+	case versionB == "0":
+		return 1, true
+	}
+	return compare(versionA, versionB)
+}
+
+// affectedRangesFor collects the ranges and listed versions an advisory gives
+// for pkg, matching names as the ecosystem does
+func affectedRangesFor(pkg osv.Package, vuln osv.Vulnerability) ([]osv.VersionRange, []string) {
+	var ranges []osv.VersionRange
+	var versions []string
+	for _, affected := range vuln.Affected {
+		if affected.Package.Ecosystem == pkg.Ecosystem && exclusionKey(pkg.Ecosystem, affected.Package.Name) == exclusionKey(pkg.Ecosystem, pkg.Name) {
+			ranges = append(ranges, affected.Ranges...)
+			versions = append(versions, affected.Versions...)
+		}
+	}
+	return ranges, versions
+}
+
+// versionAffected evaluates an advisory's ranges for pkg: SEMVER ranges in
+// any ecosystem, and ECOSYSTEM ranges where the ecosystem's versions can be
+// ordered. ok is false when none of the ranges can be evaluated.
+func (r *Runner) versionAffected(pkg osv.Package, ranges []osv.VersionRange, versions []string) (affected bool, ok bool) {
+	if slices.Contains(versions, pkg.Version) {
+		return true, true
+	}
+	affected, ok = semverAffected(pkg.Version, ranges, r.includePrerelease)
+	if affected {
+		return true, true
+	}
+	if compare, found := ecosystemComparators[pkg.Ecosystem]; found {
+		ecosystemAffected, ecosystemOK := ecosystemAffected(pkg.Version, ranges, compare)
+		return ecosystemAffected, ok || ecosystemOK
+	}
+	return affected, ok
+}
+
+// filterAffected drops advisories whose ranges show that pkg's version isn't
+// affected, which corrects OSV's matching for pre-release versions and for
+// versions OSV matched loosely. Advisories that can't be evaluated locally
+// are kept.
+func (r *Runner) filterAffected(pkg osv.Package, vulns []osv.Vulnerability) []osv.Vulnerability {
+	if pkg.Version == "" {
+		return vulns
+	}
+
+	var filtered []osv.Vulnerability
+	for _, vuln := range vulns {
+		ranges, versions := affectedRangesFor(pkg, vuln)
+		if isAffected, ok := r.versionAffected(pkg, ranges, versions); ok && !isAffected {
+			continue
+		}
+		filtered = append(filtered, vuln)
+	}
+	return filtered
+}
+
+// filterBelowMinimum drops advisories that only affect versions below
+// minVersion, the lowest version a loose specifier such as >=2.0 allows, for
+// a package queried without a version. An advisory is kept when minVersion
+// itself is affected or a later version introduces the vulnerability again,
+// or when its ranges can't be evaluated.
+func filterBelowMinimum(pkg osv.Package, minVersion string, vulns []osv.Vulnerability) []osv.Vulnerability {
+	compare, found := ecosystemComparators[pkg.Ecosystem]
+	if minVersion == "" || !found {
+		return vulns
+	}
+
+	var filtered []osv.Vulnerability
+	for _, vuln := range vulns {
+		ranges, _ := affectedRangesFor(pkg, vuln)
+		if affected, ok := ecosystemAffected(minVersion, ranges, compare); ok && !affected && !introducedAfter(minVersion, ranges, compare) {
+			continue
+		}
+		filtered = append(filtered, vuln)
+	}
+	return filtered
+}
+
+// introducedAfter reports whether any ECOSYSTEM range introduces the
+// vulnerability in a version after version
+func introducedAfter(version string, ranges []osv.VersionRange, compare versionComparator) bool {
+	for _, vrange := range ranges {
+		if vrange.Type != "ECOSYSTEM" {
+			continue
+		}
+		for _, event := range vrange.Events {
+			if event.Introduced == "" || event.Introduced == "0" {
+				continue
+			}
+			if c, ok := compare(event.Introduced, version); !ok || c > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// dropWithdrawn drops advisories OSV has withdrawn, returning the rest and how
+// many were dropped
+func dropWithdrawn(vulns []osv.Vulnerability) ([]osv.Vulnerability, int) {
+	var kept []osv.Vulnerability
+	for _, vuln := range vulns {
+		if !vuln.IsWithdrawn() {
+			kept = append(kept, vuln)
+		}
+	}
+	return kept, len(vulns) - len(kept)
+}
@@ -0,0 +1,298 @@
+package audit
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/brandonapol/snoop/osv"
+)
+
+func TestCompareSemver(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"1.0.0", "1.0.0", 0},
+		{"v1.2.3", "1.2.3", 0},
+		{"1.0.0+build.1", "1.0.0", 0},
+		{"1.0.0-alpha", "1.0.0", -1},
+		{"1.0.0-alpha", "1.0.0-alpha.1", -1},
+		{"1.0.0-alpha.1", "1.0.0-alpha.beta", -1},
+		{"1.0.0-beta", "1.0.0-beta.2", -1},
+		{"1.0.0-beta.2", "1.0.0-beta.11", -1},
+		{"1.0.0-rc.1", "1.0.0-beta.11", 1},
+		{"1.10.0", "1.9.0", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"_"+tt.b, func(t *testing.T) {
+			a, okA := parseSemver(tt.a)
+			b, okB := parseSemver(tt.b)
+			if !okA || !okB {
+				t.Fatalf("parseSemver(%q, %q) failed", tt.a, tt.b)
+			}
+			if result := a.compare(b); result != tt.expected {
+				t.Errorf("compare(%s, %s) = %d, expected %d", tt.a, tt.b, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSemverAffectedAcrossPrereleaseFix(t *testing.T) {
+	// Fixed in a release candidate, so earlier candidates are affected
+	rcFix := []osv.VersionRange{{
+		Type:   "SEMVER",
+		Events: []osv.Event{{Introduced: "0"}, {Fixed: "2.0.0-rc.2"}},
+	}}
+	// Fixed in a release, which a naive string comparison gets wrong for pre-releases
+	releaseFix := []osv.VersionRange{{
+		Type:   "SEMVER",
+		Events: []osv.Event{{Introduced: "1.0.0"}, {Fixed: "2.0.0"}},
+	}}
+
+	tests := []struct {
+		name              string
+		version           string
+		ranges            []osv.VersionRange
+		includePrerelease bool
+		expected          bool
+	}{
+		{"earlier candidate", "2.0.0-rc.1", rcFix, false, true},
+		{"alpha before candidate", "2.0.0-alpha", rcFix, false, true},
+		{"fixed candidate", "2.0.0-rc.2", rcFix, false, false},
+		{"numeric ordering of candidates", "2.0.0-rc.10", rcFix, false, false},
+		{"final release", "2.0.0", rcFix, false, false},
+		{"candidate before release fix", "2.0.0-rc.1", releaseFix, true, true},
+		{"candidate before release fix excluded", "2.0.0-rc.1", releaseFix, false, false},
+		{"release inside range", "1.5.0", releaseFix, false, true},
+		{"release after fix", "2.0.1", releaseFix, false, false},
+		{"candidate after fix", "2.0.1-rc.1", releaseFix, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			affected, ok := semverAffected(tt.version, tt.ranges, tt.includePrerelease)
+			if !ok {
+				t.Fatalf("semverAffected(%s) could not evaluate ranges", tt.version)
+			}
+			if affected != tt.expected {
+				t.Errorf("semverAffected(%s) = %v, expected %v", tt.version, affected, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFilterAffectedKeepsUnevaluableAdvisories(t *testing.T) {
+	runner := NewRunner(0, false)
+	pkg := osv.Package{Name: "left-pad", Version: "2.0.0-rc.3", Ecosystem: osv.NPM}
+
+	vulns := []osv.Vulnerability{
+		{
+			ID: "GHSA-fixed-in-rc2",
+			Affected: []osv.Affected{{
+				Package: osv.Package{Name: "left-pad", Ecosystem: osv.NPM},
+				Ranges:  []osv.VersionRange{{Type: "SEMVER", Events: []osv.Event{{Introduced: "0"}, {Fixed: "2.0.0-rc.2"}}}},
+			}},
+		},
+		{
+			ID: "GHSA-no-ranges",
+		},
+	}
+
+	filtered := runner.filterAffected(pkg, vulns)
+	if len(filtered) != 1 || filtered[0].ID != "GHSA-no-ranges" {
+		t.Errorf("filterAffected() = %v, expected only GHSA-no-ranges", filtered)
+	}
+}
+
+func TestWithdrawnAdvisoriesAreSkipped(t *testing.T) {
+	server := newMockOSVServer(t, func(request osv.QueryRequest) []osv.Vulnerability {
+		return []osv.Vulnerability{
+			{ID: "GHSA-live-0000-0000", Summary: "still affected"},
+			{ID: "GHSA-gone-0000-0000", Summary: "disputed", Withdrawn: "2024-03-01T00:00:00Z"},
+		}
+	})
+
+	dir := t.TempDir()
+	manifests := map[string]string{
+		"go.mod":           "module example.com/app\n\ngo 1.22\n\nrequire golang.org/x/net v0.17.0\n",
+		"requirements.txt": "flask==2.0.0\n",
+		"build.gradle":     "dependencies {\n    implementation 'org.yaml:snakeyaml:1.30'\n}\n",
+		"composer.lock":    `{"packages": [{"name": "twig/twig", "version": "3.4.2"}]}`,
+		"package.json":     `{"name": "app", "dependencies": {"minimist": "1.2.5"}}`,
+		"Package.resolved": `{"pins": [{"identity": "swift-nio", "location": "https://github.com/apple/swift-nio.git", "state": {"version": "2.40.0"}}], "version": 2}`,
+		"Cargo.lock":       "[[package]]\nname = \"smallvec\"\nversion = \"1.6.0\"\nsource = \"registry+https://github.com/rust-lang/crates.io-index\"\n",
+		"Gemfile.lock":     "GEM\n  remote: https://rubygems.org/\n  specs:\n    rack (2.2.3)\n",
+		".nvmrc":           "18.12.0\n",
+		"bom.json":         `{"bomFormat": "CycloneDX", "specVersion": "1.5", "components": [{"type": "library", "name": "lodash", "version": "4.17.20", "purl": "pkg:npm/lodash@4.17.20"}]}`,
+		"deps.withdrawn":   "left-pad 1.0.0\n",
+	}
+	for name, content := range manifests {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	registerTestParser(t, "deps.withdrawn", func(path string) ([]ManifestPackage, error) {
+		return []ManifestPackage{{Name: "left-pad", Version: "1.0.0", IsDirect: true}}, nil
+	})
+
+	runner := NewRunner(0, false)
+	runner.osvClient = osv.NewClientWithURL(server.URL)
+
+	goResult := runner.RunGoAudit(filepath.Join(dir, "go.mod"), "go.mod")
+	pythonResult := runner.RunPythonAudit(filepath.Join(dir, "requirements.txt"), "requirements.txt")
+	mavenResult := runner.RunMavenAudit(filepath.Join(dir, "build.gradle"), "build.gradle")
+	composerResult := runner.RunComposerAudit(filepath.Join(dir, "composer.lock"), "composer.lock")
+	npmResult := runner.RunNpmOSVAudit(filepath.Join(dir, "package.json"))
+	swiftResult := runner.RunSwiftAudit(filepath.Join(dir, "Package.resolved"), "Package.resolved")
+	cargoResult := runner.RunCargoAudit(filepath.Join(dir, "Cargo.lock"), "Cargo.lock")
+	rubyResult := runner.RunRubyAudit(filepath.Join(dir, "Gemfile.lock"), "Gemfile.lock")
+	runtimeResult := runner.RunRuntimeAudit(filepath.Join(dir, ".nvmrc"), ".nvmrc")
+	sbomResult := runner.RunSBOMAudit(filepath.Join(dir, "bom.json"))
+	customResult := runner.RunCustomAudit(filepath.Join(dir, "deps.withdrawn"), "deps.withdrawn", osv.NPM)
+
+	results := []struct {
+		ecosystem string
+		err       error
+		ids       []string
+		summary   VulnerabilitySummary
+		withdrawn int
+	}{
+		{"Go", goResult.Error, vulnerabilityIDs(goResult.Vulnerabilities, func(v GoVulnerability) string { return v.ID }), goResult.Summary, goResult.WithdrawnSkipped},
+		{"Python", pythonResult.Error, vulnerabilityIDs(pythonResult.Vulnerabilities, func(v PythonVulnerability) string { return v.ID }), pythonResult.Summary, pythonResult.WithdrawnSkipped},
+		{"Maven", mavenResult.Error, vulnerabilityIDs(mavenResult.Vulnerabilities, func(v MavenVulnerability) string { return v.ID }), mavenResult.Summary, mavenResult.WithdrawnSkipped},
+		{"Composer", composerResult.Error, vulnerabilityIDs(composerResult.Vulnerabilities, func(v ComposerVulnerability) string { return v.ID }), composerResult.Summary, composerResult.WithdrawnSkipped},
+		{"npm", npmResult.Error, vulnerabilityIDs(npmResult.Vulnerabilities, func(v Vulnerability) string { return fmt.Sprint(v.Via...) }), npmResult.Summary, npmResult.WithdrawnSkipped},
+		{"Swift", swiftResult.Error, vulnerabilityIDs(swiftResult.Vulnerabilities, func(v SwiftVulnerability) string { return v.ID }), swiftResult.Summary, swiftResult.WithdrawnSkipped},
+		{"Cargo", cargoResult.Error, vulnerabilityIDs(cargoResult.Vulnerabilities, func(v CargoVulnerability) string { return v.ID }), cargoResult.Summary, cargoResult.WithdrawnSkipped},
+		{"Ruby", rubyResult.Error, vulnerabilityIDs(rubyResult.Vulnerabilities, func(v RubyVulnerability) string { return v.ID }), rubyResult.Summary, rubyResult.WithdrawnSkipped},
+		{"Runtime", runtimeResult.Error, vulnerabilityIDs(runtimeResult.Vulnerabilities, func(v RuntimeVulnerability) string { return v.ID }), runtimeResult.Summary, runtimeResult.WithdrawnSkipped},
+		{"SBOM", sbomResult.Error, vulnerabilityIDs(sbomResult.Vulnerabilities, func(v SBOMVulnerability) string { return v.ID }), sbomResult.Summary, sbomResult.WithdrawnSkipped},
+		{"Custom", customResult.Error, vulnerabilityIDs(customResult.Vulnerabilities, func(v CustomVulnerability) string { return v.ID }), customResult.Summary, customResult.WithdrawnSkipped},
+	}
+	for _, result := range results {
+		if result.err != nil {
+			t.Fatalf("%s audit unexpected error: %v", result.ecosystem, result.err)
+		}
+		if len(result.ids) != 1 || result.ids[0] != "GHSA-live-0000-0000" {
+			t.Errorf("%s findings = %v, expected only GHSA-live-0000-0000", result.ecosystem, result.ids)
+		}
+		if result.summary.Total != 1 {
+			t.Errorf("%s summary total = %d, expected the withdrawn advisory to be left out", result.ecosystem, result.summary.Total)
+		}
+		if result.withdrawn != 1 {
+			t.Errorf("%s withdrawn skipped = %d, expected 1", result.ecosystem, result.withdrawn)
+		}
+	}
+}
+
+// registerTestParser registers parser for manifestType until the test ends
+func registerTestParser(t *testing.T, manifestType string, parser ParserFunc) {
+	t.Helper()
+	if err := RegisterParser(manifestType, parser); err != nil {
+		t.Fatalf("RegisterParser() unexpected error: %v", err)
+	}
+	t.Cleanup(func() {
+		parsersMu.Lock()
+		defer parsersMu.Unlock()
+		delete(parsers, manifestType)
+	})
+}
+
+// vulnerabilityIDs lists the advisory ID of each finding
+func vulnerabilityIDs[V any](vulns []V, id func(V) string) []string {
+	var ids []string
+	for _, vuln := range vulns {
+		ids = append(ids, id(vuln))
+	}
+	return ids
+}
+
+// ecosystemRange builds an ECOSYSTEM range from alternating introduced and
+// fixed versions
+func ecosystemRange(bounds ...string) osv.VersionRange {
+	vrange := osv.VersionRange{Type: "ECOSYSTEM"}
+	for i, bound := range bounds {
+		if i%2 == 0 {
+			vrange.Events = append(vrange.Events, osv.Event{Introduced: bound})
+		} else {
+			vrange.Events = append(vrange.Events, osv.Event{Fixed: bound})
+		}
+	}
+	return vrange
+}
+
+func TestEcosystemRangesAreCheckedLocally(t *testing.T) {
+	advisory := func(id string, pkg osv.Package, versions []string, ranges ...osv.VersionRange) osv.Vulnerability {
+		return osv.Vulnerability{ID: id, Affected: []osv.Affected{{Package: osv.Package{Name: pkg.Name, Ecosystem: pkg.Ecosystem}, Ranges: ranges, Versions: versions}}}
+	}
+	server := newMockOSVServer(t, func(request osv.QueryRequest) []osv.Vulnerability {
+		pkg := request.Package
+		switch pkg.Name {
+		case "flask":
+			return []osv.Vulnerability{
+				advisory("PYSEC-fixed-before-minimum", pkg, nil, ecosystemRange("0", "1.0")),
+				advisory("PYSEC-open", pkg, nil, ecosystemRange("0", "3.0")),
+				advisory("PYSEC-reintroduced", pkg, nil, ecosystemRange("0", "0.5", "2.5", "2.6")),
+			}
+		case "requests":
+			return []osv.Vulnerability{
+				advisory("PYSEC-fixed-in-pin", pkg, nil, ecosystemRange("0", "2.31.0")),
+				advisory("PYSEC-listed", pkg, []string{"2.31.0"}),
+				advisory("PYSEC-post-release", pkg, nil, ecosystemRange("2.30", "2.31.0.post1")),
+			}
+		case "org.yaml:snakeyaml":
+			return []osv.Vulnerability{
+				advisory("GHSA-fixed-before", pkg, nil, ecosystemRange("0", "1.26")),
+				advisory("GHSA-fixed-after", pkg, nil, ecosystemRange("0", "2.0")),
+			}
+		}
+		return nil
+	})
+
+	dir := t.TempDir()
+	manifests := map[string]string{
+		"requirements.txt": "flask>=2.0\nrequests==2.31.0\n",
+		"build.gradle":     "dependencies {\n    implementation 'org.yaml:snakeyaml:1.30'\n}\n",
+	}
+	for name, content := range manifests {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	runner := NewRunner(0, false)
+	runner.osvClient = osv.NewClientWithURL(server.URL)
+
+	pythonResult := runner.RunPythonAudit(filepath.Join(dir, "requirements.txt"), "requirements.txt")
+	if pythonResult.Error != nil {
+		t.Fatalf("RunPythonAudit() unexpected error: %v", pythonResult.Error)
+	}
+	unconfirmed := make(map[string]bool)
+	for _, vuln := range pythonResult.Vulnerabilities {
+		unconfirmed[vuln.ID] = vuln.Unconfirmed
+	}
+	expected := map[string]bool{
+		"PYSEC-open":         true,
+		"PYSEC-reintroduced": true,
+		"PYSEC-listed":       false,
+		"PYSEC-post-release": false,
+	}
+	if !maps.Equal(unconfirmed, expected) {
+		t.Errorf("Python findings (ID: unconfirmed) = %v, expected %v", unconfirmed, expected)
+	}
+
+	mavenResult := runner.RunMavenAudit(filepath.Join(dir, "build.gradle"), "build.gradle")
+	if mavenResult.Error != nil {
+		t.Fatalf("RunMavenAudit() unexpected error: %v", mavenResult.Error)
+	}
+	ids := vulnerabilityIDs(mavenResult.Vulnerabilities, func(v MavenVulnerability) string { return v.ID })
+	if !slices.Equal(ids, []string{"GHSA-fixed-after"}) {
+		t.Errorf("Maven findings = %v, expected only GHSA-fixed-after", ids)
+	}
+}
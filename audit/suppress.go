@@ -0,0 +1,172 @@
+package audit
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SuppressionFile is the name of the suppression file read from the scanned
+// directory when no other file is given
+const SuppressionFile = ".snoopignore"
+
+// Suppression accepts the risk of one advisory so it stops being reported.
+// Package limits it to findings in that package; Expires, a YYYY-MM-DD date,
+// ends it after that day so the advisory is reviewed again.
+type Suppression struct {
+	ID      string `yaml:"id"`
+	Package string `yaml:"package,omitempty"`
+	Expires string `yaml:"expires,omitempty"`
+	Reason  string `yaml:"reason,omitempty"`
+}
+
+// suppressionFile is the layout of a suppression file. JSON is a subset of
+// YAML, so one decoder reads both.
+type suppressionFile struct {
+	Ignore []Suppression `yaml:"ignore"`
+}
+
+// ReadSuppressions reads the suppressions listed under "ignore" in a YAML or
+// JSON file. Every entry needs an ID, and an expiry must be a YYYY-MM-DD date.
+func ReadSuppressions(path string) ([]Suppression, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read suppression file: %w", err)
+	}
+
+	var file suppressionFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse suppression file %s: %w", path, err)
+	}
+
+	for i, suppression := range file.Ignore {
+		if strings.TrimSpace(suppression.ID) == "" {
+			return nil, fmt.Errorf("invalid suppression file %s: entry %d has no id", path, i+1)
+		}
+		if suppression.Expires != "" {
+			if _, err := time.Parse(time.DateOnly, suppression.Expires); err != nil {
+				return nil, fmt.Errorf("invalid suppression file %s: %s expires %q, expected YYYY-MM-DD", path, suppression.ID, suppression.Expires)
+			}
+		}
+	}
+	return file.Ignore, nil
+}
+
+// Expired reports whether the suppression's expiry date has passed. It still
+// applies on the day it expires.
+func (s Suppression) Expired(now time.Time) bool {
+	return s.Expires != "" && now.Format(time.DateOnly) > s.Expires
+}
+
+// String describes the suppression for warnings, such as
+// "GHSA-xxxx in lodash"
+func (s Suppression) String() string {
+	if s.Package == "" {
+		return s.ID
+	}
+	return s.ID + " in " + s.Package
+}
+
+// ActiveSuppressions splits suppressions into those in effect at now and
+// those that have expired
+func ActiveSuppressions(suppressions []Suppression, now time.Time) (active, expired []Suppression) {
+	for _, suppression := range suppressions {
+		if suppression.Expired(now) {
+			expired = append(expired, suppression)
+		} else {
+			active = append(active, suppression)
+		}
+	}
+	return active, expired
+}
+
+// isSuppressed reports whether a finding in pkg, known by any of ids, is
+// suppressed
+func isSuppressed(suppressions []Suppression, pkg string, ids ...string) bool {
+	for _, suppression := range suppressions {
+		if suppression.Package != "" && !strings.EqualFold(suppression.Package, pkg) {
+			continue
+		}
+		for _, id := range ids {
+			if strings.EqualFold(suppression.ID, id) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// FilterSuppressed drops the findings of a result that suppressions accept
+// and recounts its summary, returning how many were suppressed
+func FilterSuppressed(r Result, suppressions []Suppression) int {
+	return r.retain(func(finding Finding) bool {
+		return !finding.suppressedBy(suppressions)
+	})
+}
+
+// suppressedBy reports whether every advisory the npm finding lists is
+// suppressed. Entries vulnerable only through another package list none and
+// are never suppressed.
+func (v Vulnerability) suppressedBy(suppressions []Suppression) bool {
+	ids := v.AdvisoryIDs()
+	for _, id := range ids {
+		if !isSuppressed(suppressions, v.Name, id) {
+			return false
+		}
+	}
+	return len(ids) > 0
+}
+
+// suppressedBy reports whether suppressions accept the finding
+func (v PythonVulnerability) suppressedBy(suppressions []Suppression) bool {
+	return isSuppressed(suppressions, v.Name, append([]string{v.ID}, v.Aliases...)...)
+}
+
+// suppressedBy reports whether suppressions accept the finding
+func (v GoVulnerability) suppressedBy(suppressions []Suppression) bool {
+	return isSuppressed(suppressions, v.Module, append([]string{v.ID}, v.Aliases...)...)
+}
+
+// suppressedBy reports whether suppressions accept the finding, with its
+// package named groupId:artifactId
+func (v MavenVulnerability) suppressedBy(suppressions []Suppression) bool {
+	return isSuppressed(suppressions, v.GroupID+":"+v.ArtifactID, append([]string{v.ID}, v.Aliases...)...)
+}
+
+// suppressedBy reports whether suppressions accept the finding
+func (v SwiftVulnerability) suppressedBy(suppressions []Suppression) bool {
+	return isSuppressed(suppressions, v.Name, append([]string{v.ID}, v.Aliases...)...)
+}
+
+// suppressedBy reports whether suppressions accept the finding
+func (v CargoVulnerability) suppressedBy(suppressions []Suppression) bool {
+	return isSuppressed(suppressions, v.Name, append([]string{v.ID}, v.Aliases...)...)
+}
+
+// suppressedBy reports whether suppressions accept the finding
+func (v RubyVulnerability) suppressedBy(suppressions []Suppression) bool {
+	return isSuppressed(suppressions, v.Name, append([]string{v.ID}, v.Aliases...)...)
+}
+
+// suppressedBy reports whether suppressions accept the finding
+func (v ComposerVulnerability) suppressedBy(suppressions []Suppression) bool {
+	return isSuppressed(suppressions, v.Name, append([]string{v.ID}, v.Aliases...)...)
+}
+
+// suppressedBy reports whether suppressions accept the finding
+func (v RuntimeVulnerability) suppressedBy(suppressions []Suppression) bool {
+	return isSuppressed(suppressions, v.Runtime, append([]string{v.ID}, v.Aliases...)...)
+}
+
+// suppressedBy reports whether suppressions accept the finding
+func (v SBOMVulnerability) suppressedBy(suppressions []Suppression) bool {
+	return isSuppressed(suppressions, v.Name, append([]string{v.ID}, v.Aliases...)...)
+}
+
+// suppressedBy reports whether suppressions accept the finding
+func (v CustomVulnerability) suppressedBy(suppressions []Suppression) bool {
+	return isSuppressed(suppressions, v.Name, append([]string{v.ID}, v.Aliases...)...)
+}
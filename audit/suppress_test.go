@@ -0,0 +1,119 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestReadSuppressions(t *testing.T) {
+	expected := []Suppression{
+		{ID: "GHSA-jf85-cpcp-j695", Package: "lodash", Expires: "2025-06-30", Reason: "not reachable from our code"},
+		{ID: "PYSEC-2021-66"},
+	}
+
+	files := map[string]string{
+		".snoopignore": `ignore:
+  - id: GHSA-jf85-cpcp-j695
+    package: lodash
+    expires: 2025-06-30
+    reason: not reachable from our code
+  - id: PYSEC-2021-66
+`,
+		"snoopignore.json": `{"ignore": [
+  {"id": "GHSA-jf85-cpcp-j695", "package": "lodash", "expires": "2025-06-30", "reason": "not reachable from our code"},
+  {"id": "PYSEC-2021-66"}
+]}`,
+	}
+
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatalf("Failed to write %s: %v", name, err)
+			}
+
+			suppressions, err := ReadSuppressions(path)
+			if err != nil {
+				t.Fatalf("ReadSuppressions() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(suppressions, expected) {
+				t.Errorf("ReadSuppressions() = %+v, expected %+v", suppressions, expected)
+			}
+		})
+	}
+}
+
+func TestReadSuppressionsRejectsInvalidEntries(t *testing.T) {
+	for name, content := range map[string]string{
+		"missing id":   "ignore:\n  - package: lodash\n",
+		"invalid date": "ignore:\n  - id: GHSA-jf85-cpcp-j695\n    expires: next year\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), SuppressionFile)
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatalf("Failed to write %s: %v", SuppressionFile, err)
+			}
+			if _, err := ReadSuppressions(path); err == nil {
+				t.Error("ReadSuppressions() expected an error")
+			}
+		})
+	}
+}
+
+func TestSuppressionExpiry(t *testing.T) {
+	suppression := Suppression{ID: "GHSA-jf85-cpcp-j695", Expires: "2025-06-30"}
+
+	tests := []struct {
+		now     time.Time
+		expired bool
+	}{
+		{time.Date(2025, 6, 29, 12, 0, 0, 0, time.UTC), false},
+		{time.Date(2025, 6, 30, 23, 59, 0, 0, time.UTC), false}, // Still applies on the day it expires
+		{time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC), true},
+	}
+	for _, tt := range tests {
+		if got := suppression.Expired(tt.now); got != tt.expired {
+			t.Errorf("Expired(%s) = %v, expected %v", tt.now.Format(time.DateOnly), got, tt.expired)
+		}
+	}
+
+	if (Suppression{ID: "PYSEC-2021-66"}).Expired(time.Now()) {
+		t.Error("a suppression without an expiry should never expire")
+	}
+
+	active, expired := ActiveSuppressions([]Suppression{suppression, {ID: "PYSEC-2021-66"}}, time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC))
+	if len(active) != 1 || active[0].ID != "PYSEC-2021-66" || len(expired) != 1 || expired[0].ID != suppression.ID {
+		t.Errorf("ActiveSuppressions() = %+v, %+v; expected PYSEC-2021-66 active and %s expired", active, expired, suppression.ID)
+	}
+}
+
+func TestFilterSuppressed(t *testing.T) {
+	result := &PythonAuditResult{
+		Vulnerabilities: []PythonVulnerability{
+			{Name: "django", ID: "PYSEC-2021-98", Aliases: []string{"CVE-2021-33203"}, Severity: "high", IsDirect: true},
+			{Name: "flask", ID: "PYSEC-2023-62", Severity: "high", IsDirect: true},
+			{Name: "requests", ID: "GHSA-j8r2-6x86-q33q", Severity: "moderate"},
+		},
+		Summary: VulnerabilitySummary{Total: 3, High: 2, Moderate: 1, Direct: 2, Transitive: 1},
+	}
+
+	suppressed := FilterSuppressed(result, []Suppression{
+		{ID: "cve-2021-33203"},                          // Matched by alias, case-insensitively
+		{ID: "GHSA-j8r2-6x86-q33q", Package: "urllib3"}, // Scoped to another package
+		{ID: "PYSEC-2023-62", Package: "Flask"},
+	})
+
+	if suppressed != 2 {
+		t.Errorf("FilterSuppressed() = %d, expected 2", suppressed)
+	}
+	if len(result.Vulnerabilities) != 1 || result.Vulnerabilities[0].Name != "requests" {
+		t.Errorf("Vulnerabilities = %+v, expected only requests", result.Vulnerabilities)
+	}
+	expected := VulnerabilitySummary{Total: 1, Moderate: 1, Transitive: 1}
+	if result.Summary != expected {
+		t.Errorf("Summary = %+v, expected %+v", result.Summary, expected)
+	}
+}
@@ -0,0 +1,180 @@
+package audit
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/brandonapol/snoop/osv"
+)
+
+// SwiftVulnerability represents a security vulnerability in a Swift package
+type SwiftVulnerability struct {
+	Name        string    `json:"name"`
+	Version     string    `json:"version"`
+	ID          string    `json:"id"`
+	FixVersions []string  `json:"fix_versions"`
+	Description string    `json:"description"`
+	Aliases     []string  `json:"aliases"`
+	Severity    string    `json:"severity"`
+	Published   time.Time `json:"published,omitzero"`
+	Modified    time.Time `json:"modified,omitzero"`
+	IsDirect    bool      `json:"is_direct"`
+}
+
+// SwiftAuditResult contains the results of running Swift vulnerability check
+type SwiftAuditResult struct {
+	ManifestPath     string
+	ManifestType     string
+	Vulnerabilities  []SwiftVulnerability
+	Summary          VulnerabilitySummary
+	PackagesScanned  int
+	Dependencies     []SwiftPackage // Every pinned package, for SBOM output
+	Warnings         []string       // Non-fatal problems such as failed queries
+	Unverified       bool           // Every OSV query failed, so no findings doesn't mean clean
+	WithdrawnSkipped int            // Advisories OSV has withdrawn, left out of the findings
+	Error            error
+}
+
+// RunSwiftAudit checks Swift packages for vulnerabilities using OSV API. A
+// Package.resolved gives the exact version of every package; a Package.swift
+// on its own only gives those pinned with an exact requirement.
+func (r *Runner) RunSwiftAudit(manifestPath string, manifestType string) *SwiftAuditResult {
+	result := &SwiftAuditResult{
+		ManifestPath: manifestPath,
+		ManifestType: manifestType,
+	}
+
+	var packages []SwiftPackage
+	switch manifestType {
+	case "Package.resolved":
+		pins, err := ParsePackageResolved(manifestPath)
+		if err != nil {
+			result.Error = fmt.Errorf("failed to parse Package.resolved: %w", err)
+			return result
+		}
+		packages = markDirectSwiftPackages(manifestPath, pins)
+	case "Package.swift":
+		manifest, err := ParsePackageSwift(manifestPath)
+		if err != nil {
+			result.Error = fmt.Errorf("failed to parse Package.swift: %w", err)
+			return result
+		}
+		if len(manifest.Ranged) > 0 {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%d Swift package(s) not audited because they declare a version range; resolve them to a Package.resolved to audit exact versions", len(manifest.Ranged)))
+		}
+		packages = manifest.Packages
+	default:
+		return result
+	}
+	packages = withoutExcluded(r, osv.SwiftURL, packages)
+
+	if len(packages) == 0 {
+		// No packages found, not an error
+		return result
+	}
+
+	result.PackagesScanned = len(packages)
+	result.Dependencies = packages
+
+	if r.verbose {
+		fmt.Fprintf(os.Stderr, "Found %d Swift packages in %s\n", len(packages), filepath.Base(manifestPath))
+	}
+
+	osvPkgs := make([]osv.Package, 0, len(packages))
+	for _, pkg := range packages {
+		osvPkgs = append(osvPkgs, osv.Package{
+			Name:      pkg.Name,
+			Version:   pkg.Version,
+			Ecosystem: osv.SwiftURL,
+		})
+	}
+	responses := r.queryPackages(osvPkgs)
+	result.Unverified = allQueriesFailed(responses)
+
+	for i, pkg := range packages {
+		if r.verbose {
+			fmt.Fprintf(os.Stderr, "  Checking %s@%s...\n", pkg.Name, pkg.Version)
+		}
+
+		response, err := responses[i].Response, responses[i].Err
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("failed to query %s: %v", pkg.Name, err))
+			if r.verbose {
+				fmt.Fprintf(os.Stderr, "    Warning: Failed to query %s: %v\n", pkg.Name, err)
+			}
+			continue
+		}
+
+		// Withdrawn advisories no longer describe a vulnerability
+		var withdrawn int
+		response.Vulns, withdrawn = dropWithdrawn(response.Vulns)
+		result.WithdrawnSkipped += withdrawn
+
+		// Re-check matches against semver ranges locally
+		response.Vulns = r.filterAffected(osvPkgs[i], response.Vulns)
+
+		// Count advisories that alias one another as a single finding
+		response.Vulns = mergeAliasedAdvisories(response.Vulns)
+
+		if r.verbose && len(response.Vulns) > 0 {
+			fmt.Fprintf(os.Stderr, "    Found %d vulnerability(ies)\n", len(response.Vulns))
+			printAdvisories(response.Vulns)
+		}
+
+		for _, vuln := range response.Vulns {
+			swiftVuln := SwiftVulnerability{
+				Name:        pkg.Name,
+				Version:     pkg.Version,
+				ID:          vuln.ID,
+				FixVersions: extractFixVersions(vuln),
+				Description: vuln.Summary,
+				Aliases:     vuln.Aliases,
+				Severity:    vuln.GetSeverityLevel(),
+				Published:   vuln.PublishedTime(),
+				Modified:    vuln.ModifiedTime(),
+				IsDirect:    pkg.IsDirect,
+			}
+
+			result.Vulnerabilities = append(result.Vulnerabilities, swiftVuln)
+
+			// Count it in the summary by severity and directness
+			swiftVuln.countIn(&result.Summary)
+		}
+	}
+
+	return result
+}
+
+// markDirectSwiftPackages marks the pins of a Package.resolved as direct when
+// the Package.swift beside it declares them. Package.resolved doesn't say which
+// pins are direct, and Xcode projects keep it without a Package.swift, in which
+// case every pin is treated as direct.
+func markDirectSwiftPackages(resolvedPath string, pins []SwiftPackage) []SwiftPackage {
+	manifestPath := filepath.Join(filepath.Dir(resolvedPath), "Package.swift")
+	if _, err := os.Stat(manifestPath); err != nil {
+		return pins
+	}
+	manifest, err := ParsePackageSwift(manifestPath)
+	if err != nil {
+		return pins
+	}
+
+	declared := make(map[string]bool)
+	for _, pkg := range manifest.Packages {
+		declared[pkg.Name] = true
+	}
+	for _, name := range manifest.Ranged {
+		declared[name] = true
+	}
+	for i := range pins {
+		pins[i].IsDirect = declared[pins[i].Name]
+	}
+	return pins
+}
+
+// HasVulnerabilities returns true if the Swift audit result contains vulnerabilities
+func (r *SwiftAuditResult) HasVulnerabilities() bool {
+	return r.Summary.Total > 0
+}
@@ -0,0 +1,143 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// SwiftPackage represents a Swift package dependency
+type SwiftPackage struct {
+	Name     string // Repository URL without scheme or .git suffix, as OSV's SwiftURL ecosystem names it
+	Version  string
+	IsDirect bool
+}
+
+// packageResolved is a Package.resolved lockfile. Version 1 nests the pins
+// under "object" and names the URL repositoryURL; versions 2 and 3 list them at
+// the top level with the URL in location.
+type packageResolved struct {
+	Version int                  `json:"version"`
+	Pins    []packageResolvedPin `json:"pins"`
+	Object  struct {
+		Pins []packageResolvedPin `json:"pins"`
+	} `json:"object"`
+}
+
+type packageResolvedPin struct {
+	Identity      string `json:"identity"`
+	Package       string `json:"package"`
+	Location      string `json:"location"`
+	RepositoryURL string `json:"repositoryURL"`
+	State         struct {
+		Version  string `json:"version"`
+		Branch   string `json:"branch"`
+		Revision string `json:"revision"`
+	} `json:"state"`
+}
+
+// ParsePackageResolved reads the pinned packages of a Package.resolved file.
+// Pins that track a branch or revision rather than a version are skipped, since
+// OSV can't match them.
+func ParsePackageResolved(path string) ([]SwiftPackage, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	var resolved packageResolved
+	if err := json.Unmarshal(data, &resolved); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	pins := resolved.Pins
+	if len(pins) == 0 {
+		pins = resolved.Object.Pins
+	}
+
+	packages := make([]SwiftPackage, 0, len(pins))
+	for _, pin := range pins {
+		location := pin.Location
+		if location == "" {
+			location = pin.RepositoryURL
+		}
+		name := swiftPackageName(location)
+		if name == "" || pin.State.Version == "" {
+			continue
+		}
+		packages = append(packages, SwiftPackage{Name: name, Version: pin.State.Version, IsDirect: true})
+	}
+
+	return packages, nil
+}
+
+// swiftPackageURLRegex matches the url argument of a .package(...) dependency
+var swiftPackageURLRegex = regexp.MustCompile(`url:\s*"([^"]+)"`)
+
+// swiftExactVersionRegex matches an exact version requirement, written either
+// as exact: "1.2.3" or .exact("1.2.3")
+var swiftExactVersionRegex = regexp.MustCompile(`(?:exact:\s*|\.exact\(\s*)"([^"]+)"`)
+
+// SwiftManifest holds the dependencies declared in a Package.swift
+type SwiftManifest struct {
+	Packages []SwiftPackage // Dependencies pinned to an exact version
+	Ranged   []string       // Names of dependencies that declare a version range, branch, or revision
+}
+
+// ParsePackageSwift reads the remote dependencies of a Package.swift. Only
+// exact version requirements give a version to audit; Package.resolved records
+// what the others resolved to.
+func ParsePackageSwift(path string) (*SwiftManifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	manifest := &SwiftManifest{}
+	// Each dependency starts at ".package(", and its requirement follows the url
+	declarations := strings.Split(string(data), ".package(")
+	for _, declaration := range declarations[1:] {
+		match := swiftPackageURLRegex.FindStringSubmatch(declaration)
+		if match == nil {
+			// Local path dependencies aren't published anywhere OSV tracks
+			continue
+		}
+		name := swiftPackageName(match[1])
+		if name == "" {
+			continue
+		}
+
+		if exact := swiftExactVersionRegex.FindStringSubmatch(declaration); exact != nil {
+			manifest.Packages = append(manifest.Packages, SwiftPackage{Name: name, Version: exact[1], IsDirect: true})
+		} else {
+			manifest.Ranged = append(manifest.Ranged, name)
+		}
+	}
+
+	return manifest, nil
+}
+
+// swiftPackageName turns a repository URL such as
+// https://github.com/apple/swift-nio.git or git@github.com:apple/swift-nio.git
+// into the github.com/apple/swift-nio form that OSV uses
+func swiftPackageName(location string) string {
+	name := strings.TrimSpace(location)
+	if scheme := strings.Index(name, "://"); scheme >= 0 {
+		name = name[scheme+3:]
+		// Drop credentials such as git@
+		if at := strings.Index(name, "@"); at >= 0 && at < strings.Index(name+"/", "/") {
+			name = name[at+1:]
+		}
+	} else if user, rest, ok := strings.Cut(name, "@"); ok && !strings.Contains(user, "/") {
+		// scp-like syntax separates the host from the path with a colon
+		name = strings.Replace(rest, ":", "/", 1)
+	}
+
+	name = strings.TrimSuffix(strings.TrimSuffix(name, "/"), ".git")
+	if !strings.Contains(name, "/") {
+		return ""
+	}
+	return name
+}
@@ -0,0 +1,170 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/brandonapol/snoop/osv"
+)
+
+func TestParsePackageResolved(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected []SwiftPackage
+	}{
+		{
+			name: "version 2",
+			content: `{
+  "pins" : [
+    {
+      "identity" : "swift-nio",
+      "kind" : "remoteSourceControl",
+      "location" : "https://github.com/apple/swift-nio.git",
+      "state" : { "revision" : "a8e2f1c", "version" : "2.40.0" }
+    },
+    {
+      "identity" : "vapor",
+      "kind" : "remoteSourceControl",
+      "location" : "git@github.com:vapor/vapor.git",
+      "state" : { "revision" : "b1d2c3e", "version" : "4.60.0" }
+    },
+    {
+      "identity" : "nightly",
+      "kind" : "remoteSourceControl",
+      "location" : "https://github.com/example/nightly",
+      "state" : { "branch" : "main", "revision" : "c4d5e6f" }
+    }
+  ],
+  "version" : 2
+}`,
+			expected: []SwiftPackage{
+				{Name: "github.com/apple/swift-nio", Version: "2.40.0", IsDirect: true},
+				{Name: "github.com/vapor/vapor", Version: "4.60.0", IsDirect: true},
+			},
+		},
+		{
+			name: "version 1",
+			content: `{
+  "object": {
+    "pins": [
+      {
+        "package": "Alamofire",
+        "repositoryURL": "https://github.com/Alamofire/Alamofire.git",
+        "state": { "branch": null, "revision": "f96b619", "version": "5.4.0" }
+      }
+    ]
+  },
+  "version": 1
+}`,
+			expected: []SwiftPackage{
+				{Name: "github.com/Alamofire/Alamofire", Version: "5.4.0", IsDirect: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "Package.resolved")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write Package.resolved: %v", err)
+			}
+
+			packages, err := ParsePackageResolved(path)
+			if err != nil {
+				t.Fatalf("ParsePackageResolved() error = %v", err)
+			}
+			if !reflect.DeepEqual(packages, tt.expected) {
+				t.Errorf("ParsePackageResolved() = %+v, expected %+v", packages, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParsePackageSwift(t *testing.T) {
+	content := `// swift-tools-version:5.7
+import PackageDescription
+
+let package = Package(
+    name: "App",
+    dependencies: [
+        .package(url: "https://github.com/apple/swift-nio.git", exact: "2.40.0"),
+        .package(url: "https://github.com/vapor/vapor.git", .exact("4.60.0")),
+        .package(url: "https://github.com/apple/swift-log.git", from: "1.4.0"),
+        .package(path: "../LocalKit"),
+    ]
+)
+`
+	path := filepath.Join(t.TempDir(), "Package.swift")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write Package.swift: %v", err)
+	}
+
+	manifest, err := ParsePackageSwift(path)
+	if err != nil {
+		t.Fatalf("ParsePackageSwift() error = %v", err)
+	}
+
+	expected := []SwiftPackage{
+		{Name: "github.com/apple/swift-nio", Version: "2.40.0", IsDirect: true},
+		{Name: "github.com/vapor/vapor", Version: "4.60.0", IsDirect: true},
+	}
+	if !reflect.DeepEqual(manifest.Packages, expected) {
+		t.Errorf("ParsePackageSwift() packages = %+v, expected %+v", manifest.Packages, expected)
+	}
+	if !reflect.DeepEqual(manifest.Ranged, []string{"github.com/apple/swift-log"}) {
+		t.Errorf("ParsePackageSwift() ranged = %v, expected [github.com/apple/swift-log]", manifest.Ranged)
+	}
+}
+
+func TestRunSwiftAuditQueriesResolvedVersions(t *testing.T) {
+	server := newMockOSVServer(t, func(request osv.QueryRequest) []osv.Vulnerability {
+		if request.Package.Ecosystem != osv.SwiftURL {
+			t.Errorf("query ecosystem = %s, expected %s", request.Package.Ecosystem, osv.SwiftURL)
+		}
+		if request.Package.Name == "github.com/apple/swift-nio" && request.Package.Version == "2.40.0" {
+			return []osv.Vulnerability{{ID: "GHSA-swift-nio", Summary: "request smuggling"}}
+		}
+		return nil
+	})
+
+	dir := t.TempDir()
+	resolved := `{
+  "pins" : [
+    { "identity" : "swift-nio", "location" : "https://github.com/apple/swift-nio.git", "state" : { "version" : "2.40.0" } },
+    { "identity" : "swift-log", "location" : "https://github.com/apple/swift-log.git", "state" : { "version" : "1.5.3" } }
+  ],
+  "version" : 2
+}`
+	manifest := `let package = Package(name: "App", dependencies: [.package(url: "https://github.com/apple/swift-log.git", from: "1.4.0")])`
+	resolvedPath := filepath.Join(dir, "Package.resolved")
+	if err := os.WriteFile(resolvedPath, []byte(resolved), 0644); err != nil {
+		t.Fatalf("Failed to write Package.resolved: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "Package.swift"), []byte(manifest), 0644); err != nil {
+		t.Fatalf("Failed to write Package.swift: %v", err)
+	}
+
+	runner := NewRunner(0, false)
+	runner.SetOSVClient(osv.NewClientWithURL(server.URL))
+	result := runner.RunSwiftAudit(resolvedPath, "Package.resolved")
+
+	if result.Error != nil {
+		t.Fatalf("RunSwiftAudit() error = %v", result.Error)
+	}
+	if result.PackagesScanned != 2 {
+		t.Errorf("PackagesScanned = %d, expected 2", result.PackagesScanned)
+	}
+	if len(result.Vulnerabilities) != 1 || result.Vulnerabilities[0].ID != "GHSA-swift-nio" {
+		t.Fatalf("Vulnerabilities = %+v, expected GHSA-swift-nio", result.Vulnerabilities)
+	}
+	// swift-nio is only pulled in transitively, since Package.swift doesn't declare it
+	if result.Vulnerabilities[0].IsDirect {
+		t.Errorf("swift-nio finding IsDirect = true, expected false")
+	}
+	if result.Summary.Total != 1 || result.Summary.Transitive != 1 {
+		t.Errorf("Summary = %+v, expected one transitive finding", result.Summary)
+	}
+}
@@ -0,0 +1,193 @@
+package audit
+
+import (
+	"strconv"
+	"strings"
+)
+
+// UpgradeSummary splits fixable findings by the effort their fix takes: a
+// patch or minor upgrade that shouldn't break callers, or a major upgrade
+type UpgradeSummary struct {
+	NonBreaking int `json:"nonBreaking"`
+	Breaking    int `json:"breaking"`
+}
+
+// Add adds the counts of other to the summary
+func (s *UpgradeSummary) Add(other UpgradeSummary) {
+	s.NonBreaking += other.NonBreaking
+	s.Breaking += other.Breaking
+}
+
+// Total returns the number of findings with a known upgrade
+func (s UpgradeSummary) Total() int {
+	return s.NonBreaking + s.Breaking
+}
+
+// count records one finding; findings without a fix are ignored
+func (s *UpgradeSummary) count(fixable, breaking bool) {
+	switch {
+	case !fixable:
+	case breaking:
+		s.Breaking++
+	default:
+		s.NonBreaking++
+	}
+}
+
+// versionLine returns the major version, and for 0.x versions the minor
+// version too, since semver treats every 0.x minor release as breaking
+func versionLine(version string) (string, bool) {
+	parts := strings.SplitN(strings.TrimPrefix(strings.TrimSpace(version), "v"), ".", 3)
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return "", false
+	}
+	if major != 0 {
+		return parts[0], true
+	}
+	if len(parts) < 2 {
+		return "", false
+	}
+	minor := parts[1]
+	if i := strings.IndexFunc(minor, func(r rune) bool { return r < '0' || r > '9' }); i >= 0 {
+		minor = minor[:i]
+	}
+	if minor == "" {
+		return "", false
+	}
+	return "0." + minor, true
+}
+
+// isMajorUpgrade reports whether moving from current to fix changes the
+// version line, i.e. is a breaking upgrade under semver
+func isMajorUpgrade(current, fix string) bool {
+	currentLine, ok := versionLine(current)
+	if !ok {
+		return false
+	}
+	fixLine, ok := versionLine(fix)
+	return ok && fixLine != currentLine
+}
+
+// classifyUpgrade reports whether a finding on current is fixable and, if so,
+// whether every fix lies outside current's version line. Findings on versions
+// that can't be compared aren't counted.
+func classifyUpgrade(current string, fixVersions []string) (fixable, breaking bool) {
+	if len(fixVersions) == 0 {
+		return false, false
+	}
+	if _, ok := versionLine(current); !ok {
+		return false, false
+	}
+	for _, fix := range fixVersions {
+		if !isMajorUpgrade(current, fix) {
+			return true, false
+		}
+	}
+	return true, true
+}
+
+// Upgrades counts findings npm can fix without and with a semver-major
+// upgrade, using npm's isSemVerMajor flag
+func (r *AuditResult) Upgrades() UpgradeSummary {
+	var summary UpgradeSummary
+	for _, vuln := range r.Vulnerabilities {
+		if vuln.FixAvailable.Upgrade() {
+			summary.count(true, vuln.FixAvailable.IsSemVerMajor)
+			continue
+		}
+		// A plain true means the fix fits within the declared range
+		summary.count(vuln.IsFixable(), false)
+	}
+	return summary
+}
+
+// Upgrades counts findings fixable without and with a major upgrade
+func (r *PythonAuditResult) Upgrades() UpgradeSummary {
+	var summary UpgradeSummary
+	for _, vuln := range r.Vulnerabilities {
+		summary.count(classifyUpgrade(vuln.Version, vuln.FixVersions))
+	}
+	return summary
+}
+
+// Upgrades counts findings fixable without and with a major upgrade
+func (r *GoAuditResult) Upgrades() UpgradeSummary {
+	var summary UpgradeSummary
+	for _, vuln := range r.Vulnerabilities {
+		summary.count(classifyUpgrade(vuln.Version, vuln.FixVersions))
+	}
+	return summary
+}
+
+// Upgrades counts findings fixable without and with a major upgrade
+func (r *MavenAuditResult) Upgrades() UpgradeSummary {
+	var summary UpgradeSummary
+	for _, vuln := range r.Vulnerabilities {
+		summary.count(classifyUpgrade(vuln.Version, vuln.FixVersions))
+	}
+	return summary
+}
+
+// Upgrades counts findings fixable without and with a major upgrade
+func (r *SwiftAuditResult) Upgrades() UpgradeSummary {
+	var summary UpgradeSummary
+	for _, vuln := range r.Vulnerabilities {
+		summary.count(classifyUpgrade(vuln.Version, vuln.FixVersions))
+	}
+	return summary
+}
+
+// Upgrades counts findings fixable without and with a major upgrade
+func (r *CargoAuditResult) Upgrades() UpgradeSummary {
+	var summary UpgradeSummary
+	for _, vuln := range r.Vulnerabilities {
+		summary.count(classifyUpgrade(vuln.Version, vuln.FixVersions))
+	}
+	return summary
+}
+
+// Upgrades counts findings fixable without and with a major upgrade
+func (r *RubyAuditResult) Upgrades() UpgradeSummary {
+	var summary UpgradeSummary
+	for _, vuln := range r.Vulnerabilities {
+		summary.count(classifyUpgrade(vuln.Version, vuln.FixVersions))
+	}
+	return summary
+}
+
+// Upgrades counts findings fixable without and with a major upgrade
+func (r *ComposerAuditResult) Upgrades() UpgradeSummary {
+	var summary UpgradeSummary
+	for _, vuln := range r.Vulnerabilities {
+		summary.count(classifyUpgrade(vuln.Version, vuln.FixVersions))
+	}
+	return summary
+}
+
+// Upgrades counts findings fixable without and with a major upgrade
+func (r *RuntimeAuditResult) Upgrades() UpgradeSummary {
+	var summary UpgradeSummary
+	for _, vuln := range r.Vulnerabilities {
+		summary.count(classifyUpgrade(vuln.Version, vuln.FixVersions))
+	}
+	return summary
+}
+
+// Upgrades counts findings fixable without and with a major upgrade
+func (r *SBOMAuditResult) Upgrades() UpgradeSummary {
+	var summary UpgradeSummary
+	for _, vuln := range r.Vulnerabilities {
+		summary.count(classifyUpgrade(vuln.Version, vuln.FixVersions))
+	}
+	return summary
+}
+
+// Upgrades counts findings fixable without and with a major upgrade
+func (r *CustomAuditResult) Upgrades() UpgradeSummary {
+	var summary UpgradeSummary
+	for _, vuln := range r.Vulnerabilities {
+		summary.count(classifyUpgrade(vuln.Version, vuln.FixVersions))
+	}
+	return summary
+}
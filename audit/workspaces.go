@@ -0,0 +1,75 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// NpmWorkspaces is the "workspaces" field of package.json: the patterns of
+// the directories holding the workspace members. npm writes it as a list;
+// yarn also accepts an object listing them under "packages".
+type NpmWorkspaces []string
+
+// UnmarshalJSON reads workspaces in either of the forms package.json uses
+func (w *NpmWorkspaces) UnmarshalJSON(data []byte) error {
+	var patterns []string
+	if err := json.Unmarshal(data, &patterns); err == nil {
+		*w = patterns
+		return nil
+	}
+
+	var object struct {
+		Packages []string `json:"packages"`
+	}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return fmt.Errorf("workspaces is neither a list nor an object: %w", err)
+	}
+	*w = object.Packages
+	return nil
+}
+
+// HasWorkspaceMember reports whether the package.json in memberDir is one of
+// the workspaces declared by this package.json, found in rootDir. Patterns
+// are matched in order against memberDir relative to rootDir, so a later
+// "!pattern" takes back what an earlier one matched.
+func (m *PackageJSON) HasWorkspaceMember(rootDir, memberDir string) bool {
+	rel, err := filepath.Rel(rootDir, memberDir)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+
+	member := false
+	for _, pattern := range m.Workspaces {
+		negated := strings.HasPrefix(pattern, "!")
+		pattern = strings.TrimPrefix(pattern, "!")
+		pattern = strings.TrimSuffix(strings.TrimPrefix(pattern, "./"), "/")
+		if matchWorkspacePattern(pattern, rel) {
+			member = !negated
+		}
+	}
+	return member
+}
+
+// matchWorkspacePattern matches a slash-separated directory against a
+// workspace glob, where a "**" segment matches every directory below it
+func matchWorkspacePattern(pattern, dir string) bool {
+	patternSegments := strings.Split(pattern, "/")
+	dirSegments := strings.Split(dir, "/")
+
+	for i, segment := range patternSegments {
+		if segment == "**" {
+			return true
+		}
+		if i >= len(dirSegments) {
+			return false
+		}
+		if matched, err := path.Match(segment, dirSegments[i]); err != nil || !matched {
+			return false
+		}
+	}
+	return len(patternSegments) == len(dirSegments)
+}
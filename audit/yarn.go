@@ -0,0 +1,196 @@
+package audit
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// yarnLockEntry is one resolved package in a yarn.lock, listing every
+// descriptor such as lodash@^4.17.0 that resolved to it
+type yarnLockEntry struct {
+	descriptors []string
+	version     string
+}
+
+// yarnNonRegistryProtocols mark descriptors that don't resolve to a published
+// package, such as workspaces, patches, and git checkouts
+var yarnNonRegistryProtocols = []string{"workspace:", "patch:", "link:", "portal:", "file:", "exec:", "git", "http:", "https:"}
+
+// ParseYarnLock returns every package a yarn.lock resolves at its version,
+// sorted by name and version. Both the classic v1 format and the YAML format
+// of Yarn 2+ (Berry) are read. A yarn.lock doesn't say which packages the
+// project declares, so none are marked direct.
+func ParseYarnLock(path string) ([]NpmPackage, error) {
+	entries, err := readYarnLock(path)
+	if err != nil {
+		return nil, err
+	}
+	return yarnPackages(entries, func(string, string) bool { return false }), nil
+}
+
+// YarnLockPackages returns every package a yarn.lock resolves, like
+// ParseYarnLock, marking those that m declares with the same range as direct
+func (m *PackageJSON) YarnLockPackages(path string) ([]NpmPackage, error) {
+	entries, err := readYarnLock(path)
+	if err != nil {
+		return nil, err
+	}
+
+	direct := func(name, versionRange string) bool {
+		for _, deps := range []map[string]string{m.Dependencies, m.DevDependencies, m.OptionalDependencies} {
+			if declared, ok := deps[name]; ok && strings.TrimPrefix(declared, "npm:") == versionRange {
+				return true
+			}
+		}
+		return false
+	}
+	return yarnPackages(entries, direct), nil
+}
+
+// yarnPackages turns lockfile entries into packages, skipping those that
+// don't come from the registry. direct is asked about each descriptor's name
+// and range.
+func yarnPackages(entries []yarnLockEntry, direct func(name, versionRange string) bool) []NpmPackage {
+	// Keyed without IsDirect, since the same version may be listed under
+	// several entries, any of which can make it direct
+	found := make(map[NpmPackage]bool)
+	for _, entry := range entries {
+		if entry.version == "" || len(entry.descriptors) == 0 {
+			continue
+		}
+
+		name, _, ok := parseYarnDescriptor(entry.descriptors[0])
+		if !ok {
+			continue
+		}
+
+		key := NpmPackage{Name: name, Version: entry.version}
+		for _, descriptor := range entry.descriptors {
+			alias, versionRange, _ := splitYarnDescriptor(descriptor)
+			found[key] = found[key] || direct(alias, strings.TrimPrefix(versionRange, "npm:"))
+		}
+	}
+
+	packages := make([]NpmPackage, 0, len(found))
+	for pkg, isDirect := range found {
+		pkg.IsDirect = isDirect
+		packages = append(packages, pkg)
+	}
+	sort.Slice(packages, func(i, j int) bool {
+		if packages[i].Name != packages[j].Name {
+			return packages[i].Name < packages[j].Name
+		}
+		return packages[i].Version < packages[j].Version
+	})
+	return packages
+}
+
+// readYarnLock reads the entries of a yarn.lock. An entry starts with an
+// unindented line listing its descriptors and ending in a colon, followed by
+// indented fields. The version field is written version "1.0.0" in v1 and
+// version: 1.0.0 in Berry.
+func readYarnLock(path string) ([]yarnLockEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open yarn.lock: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	var entries []yarnLockEntry
+	var current *yarnLockEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		if !strings.HasPrefix(line, " ") {
+			current = nil
+			header, ok := strings.CutSuffix(line, ":")
+			if !ok || header == "__metadata" {
+				continue
+			}
+			entries = append(entries, yarnLockEntry{descriptors: splitYarnHeader(header)})
+			current = &entries[len(entries)-1]
+			continue
+		}
+
+		// Only the entry's own fields, not those nested under dependencies
+		if current == nil || strings.HasPrefix(line, "   ") {
+			continue
+		}
+		if value, ok := strings.CutPrefix(trimmed, "version"); ok && (strings.HasPrefix(value, " ") || strings.HasPrefix(value, ":")) {
+			current.version = strings.Trim(strings.TrimSpace(strings.TrimPrefix(value, ":")), `"'`)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read yarn.lock: %w", err)
+	}
+
+	return entries, nil
+}
+
+// splitYarnHeader splits an entry header into its descriptors. v1 quotes each
+// descriptor that needs it ("@babel/core@^7.0.0", "@babel/core@^7.1.0"); Berry
+// quotes the whole list ("lodash@npm:^4.17.0, lodash@npm:^4.17.21").
+func splitYarnHeader(header string) []string {
+	var descriptors []string
+	for _, part := range strings.Split(header, ",") {
+		descriptor := strings.Trim(strings.TrimSpace(part), `"`)
+		if descriptor != "" {
+			descriptors = append(descriptors, descriptor)
+		}
+	}
+	return descriptors
+}
+
+// parseYarnDescriptor returns the registry package a descriptor resolves to,
+// following npm: aliases such as my-lodash@npm:lodash@^4.17.0. It fails for
+// descriptors that don't resolve from the registry.
+func parseYarnDescriptor(descriptor string) (name, versionRange string, ok bool) {
+	name, versionRange, ok = splitYarnDescriptor(descriptor)
+	if !ok {
+		return "", "", false
+	}
+
+	versionRange = strings.TrimPrefix(versionRange, "npm:")
+	for _, protocol := range yarnNonRegistryProtocols {
+		if strings.HasPrefix(versionRange, protocol) {
+			return "", "", false
+		}
+	}
+	if strings.Contains(versionRange, "://") {
+		return "", "", false
+	}
+
+	// An alias names the real package in its range
+	if target, targetRange, isAlias := splitYarnDescriptor(versionRange); isAlias {
+		return target, targetRange, true
+	}
+	return name, versionRange, true
+}
+
+// splitYarnDescriptor splits a descriptor such as @babel/core@^7.0.0 at the
+// "@" that follows the package name
+func splitYarnDescriptor(descriptor string) (name, versionRange string, ok bool) {
+	start := 0
+	if strings.HasPrefix(descriptor, "@") {
+		slash := strings.Index(descriptor, "/")
+		if slash < 0 {
+			return "", "", false
+		}
+		start = slash
+	}
+
+	at := strings.Index(descriptor[start:], "@")
+	if at < 0 {
+		return "", "", false
+	}
+	at += start
+	return descriptor[:at], descriptor[at+1:], at > 0
+}
@@ -0,0 +1,171 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/brandonapol/snoop/osv"
+)
+
+// yarnClassicLock is a yarn.lock in the classic v1 format
+const yarnClassicLock = `# THIS IS AN AUTOGENERATED FILE. DO NOT EDIT THIS FILE DIRECTLY.
+# yarn lockfile v1
+
+
+"@babel/code-frame@^7.0.0", "@babel/code-frame@^7.10.4":
+  version "7.12.13"
+  resolved "https://registry.yarnpkg.com/@babel/code-frame/-/code-frame-7.12.13.tgz"
+  integrity sha512-HV1Cm0Q3ZrpCR93tkWOYiuYIgLxZXZFVG2VgK+MBWjUqZTundupbfx2aXarXuw5Ko5aMcjtJgbSs4vUGBS5v6g==
+  dependencies:
+    "@babel/highlight" "^7.12.13"
+
+lodash@^4.17.0, lodash@^4.17.15:
+  version "4.17.15"
+  resolved "https://registry.yarnpkg.com/lodash/-/lodash-4.17.15.tgz"
+
+minimist@^1.2.0:
+  version "1.2.5"
+  resolved "https://registry.yarnpkg.com/minimist/-/minimist-1.2.5.tgz"
+
+my-lodash@npm:lodash@^4.17.21:
+  version "4.17.21"
+  resolved "https://registry.yarnpkg.com/lodash/-/lodash-4.17.21.tgz"
+
+private-lib@git+https://github.com/example/private-lib.git:
+  version "1.0.0"
+  resolved "git+https://github.com/example/private-lib.git#abc123"
+`
+
+// yarnBerryLock is a yarn.lock in the YAML format of Yarn 2+
+const yarnBerryLock = `# This file is generated by running "yarn install" inside your project.
+# Manual changes might be lost - proceed with caution!
+
+__metadata:
+  version: 6
+  cacheKey: 8
+
+"@babel/code-frame@npm:^7.0.0, @babel/code-frame@npm:^7.10.4":
+  version: 7.12.13
+  resolution: "@babel/code-frame@npm:7.12.13"
+  dependencies:
+    "@babel/highlight": ^7.12.13
+  checksum: 471532bb7c
+  languageName: node
+  linkType: hard
+
+"app@workspace:.":
+  version: 0.0.0-use.local
+  resolution: "app@workspace:."
+  dependencies:
+    lodash: ^4.17.0
+  languageName: unknown
+  linkType: soft
+
+"lodash@npm:^4.17.0, lodash@npm:^4.17.15":
+  version: 4.17.15
+  resolution: "lodash@npm:4.17.15"
+  checksum: 3a5b4c5e2d
+  languageName: node
+  linkType: hard
+
+"minimist@npm:^1.2.0":
+  version: 1.2.5
+  resolution: "minimist@npm:1.2.5"
+  languageName: node
+  linkType: hard
+
+"resolve@patch:resolve@^1.20.0#~builtin<compat/resolve>":
+  version: 1.20.0
+  resolution: "resolve@patch:resolve@npm%3A1.20.0#~builtin<compat/resolve>::version=1.20.0&hash=07638b"
+  languageName: node
+  linkType: hard
+`
+
+func TestParseYarnLock(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected []NpmPackage
+	}{
+		{
+			name:    "classic",
+			content: yarnClassicLock,
+			expected: []NpmPackage{
+				{Name: "@babel/code-frame", Version: "7.12.13"},
+				{Name: "lodash", Version: "4.17.15"},
+				{Name: "lodash", Version: "4.17.21"},
+				{Name: "minimist", Version: "1.2.5"},
+			},
+		},
+		{
+			name:    "berry",
+			content: yarnBerryLock,
+			expected: []NpmPackage{
+				{Name: "@babel/code-frame", Version: "7.12.13"},
+				{Name: "lodash", Version: "4.17.15"},
+				{Name: "minimist", Version: "1.2.5"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "yarn.lock")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write yarn.lock: %v", err)
+			}
+
+			packages, err := ParseYarnLock(path)
+			if err != nil {
+				t.Fatalf("ParseYarnLock() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(packages, tt.expected) {
+				t.Errorf("ParseYarnLock() = %+v, expected %+v", packages, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRunNpmOSVAuditReadsYarnLock(t *testing.T) {
+	server := newMockOSVServer(t, func(request osv.QueryRequest) []osv.Vulnerability {
+		if request.Package.Name == "minimist" && request.Package.Version == "1.2.5" {
+			return []osv.Vulnerability{{ID: "GHSA-xvch-5gv4-984h", Summary: "Prototype Pollution in minimist"}}
+		}
+		return nil
+	})
+
+	for _, lock := range []string{yarnClassicLock, yarnBerryLock} {
+		tmpDir := t.TempDir()
+		packageJSONPath := filepath.Join(tmpDir, "package.json")
+		if err := os.WriteFile(packageJSONPath, []byte(`{"name": "app", "dependencies": {"lodash": "^4.17.0"}}`), 0644); err != nil {
+			t.Fatalf("Failed to write package.json: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(tmpDir, "yarn.lock"), []byte(lock), 0644); err != nil {
+			t.Fatalf("Failed to write yarn.lock: %v", err)
+		}
+
+		runner := NewRunner(0, false)
+		runner.osvClient = osv.NewClientWithURL(server.URL)
+		result := runner.RunNpmOSVAudit(packageJSONPath)
+
+		if result.Error != nil {
+			t.Fatalf("RunNpmOSVAudit() unexpected error: %v", result.Error)
+		}
+		// The ranged dependency only has an exact version in yarn.lock
+		if result.Summary.Total != 1 || result.Vulnerabilities[0].Name != "minimist" || result.Vulnerabilities[0].IsDirect {
+			t.Errorf("expected one transitive minimist finding, got %+v", result.Vulnerabilities)
+		}
+
+		var lodashDirect bool
+		for _, dep := range result.Dependencies {
+			if dep.Name == "lodash" && dep.Version == "4.17.15" {
+				lodashDirect = dep.IsDirect
+			}
+		}
+		if !lodashDirect {
+			t.Errorf("declared lodash should be direct, dependencies = %+v", result.Dependencies)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/brandonapol/snoop/formatter"
+	"github.com/brandonapol/snoop/scanner"
+)
+
+// readPaths reads one directory per line, skipping blank lines and # comments
+func readPaths(r io.Reader) ([]string, error) {
+	var paths []string
+	lineScanner := bufio.NewScanner(r)
+	for lineScanner.Scan() {
+		line := strings.TrimSpace(lineScanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		paths = append(paths, line)
+	}
+	if err := lineScanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read paths: %w", err)
+	}
+	return paths, nil
+}
+
+// openPathsSource opens the --paths-from file, where "-" means stdin
+func openPathsSource(name string) (io.ReadCloser, error) {
+	if name == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open paths file: %w", err)
+	}
+	return file, nil
+}
+
+// runBulk scans each directory and writes one JSON report per line to w. A
+// directory that fails is reported on its own line without stopping the rest.
+// Once ctx is done, the directory being scanned and the rest are skipped.
+func runBulk(ctx context.Context, dirs []string, w io.Writer) error {
+	for _, dir := range dirs {
+		output := scanProjectIsolated(ctx, dir)
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		line, err := formatter.FormatJSONLine(output, dir)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return fmt.Errorf("failed to write report: %w", err)
+		}
+	}
+	return nil
+}
+
+// scanProjectIsolated scans dir, turning errors and panics into a report that
+// carries the error instead of aborting the run
+func scanProjectIsolated(ctx context.Context, dir string) (output *formatter.ScanOutput) {
+	failed := func(err error) *formatter.ScanOutput {
+		return &formatter.ScanOutput{
+			Metadata:    newOutputMetadata(dir),
+			ScanResults: &scanner.ScanResult{Errors: []error{err}},
+			HasErrors:   true,
+		}
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			output = failed(fmt.Errorf("scan aborted: %v", r))
+		}
+	}()
+
+	output, _, err := scanProject(ctx, dir, false)
+	if err != nil {
+		return failed(err)
+	}
+	if output == nil {
+		// Nothing to audit, which is still a valid (empty) report
+		return &formatter.ScanOutput{
+			Metadata:    newOutputMetadata(dir),
+			ScanResults: &scanner.ScanResult{},
+		}
+	}
+
+	prepareReport(output)
+	return output
+}
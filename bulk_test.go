@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadPaths(t *testing.T) {
+	input := "# fleet\n/srv/a\n\n  /srv/b  \n#/srv/skipped\n"
+
+	paths, err := readPaths(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("readPaths() error = %v", err)
+	}
+
+	expected := []string{"/srv/a", "/srv/b"}
+	if len(paths) != len(expected) {
+		t.Fatalf("readPaths() = %v, expected %v", paths, expected)
+	}
+	for i := range expected {
+		if paths[i] != expected[i] {
+			t.Errorf("readPaths()[%d] = %q, expected %q", i, paths[i], expected[i])
+		}
+	}
+}
+
+func TestRunBulkWritesOneLinePerPath(t *testing.T) {
+	// Projects without manifests need no network access
+	first := t.TempDir()
+	second := t.TempDir()
+	if err := os.WriteFile(filepath.Join(second, "README.md"), []byte("# empty\n"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	missing := filepath.Join(t.TempDir(), "does-not-exist")
+
+	pathsFile := filepath.Join(t.TempDir(), "paths.txt")
+	content := first + "\n" + missing + "\n" + second + "\n"
+	if err := os.WriteFile(pathsFile, []byte(content), 0o644); err != nil {
+		t.Fatalf("failed to write paths file: %v", err)
+	}
+
+	source, err := openPathsSource(pathsFile)
+	if err != nil {
+		t.Fatalf("openPathsSource() error = %v", err)
+	}
+	dirs, err := readPaths(source)
+	_ = source.Close()
+	if err != nil {
+		t.Fatalf("readPaths() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := runBulk(context.Background(), dirs, &buf); err != nil {
+		t.Fatalf("runBulk() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("runBulk() wrote %d lines, expected 3:\n%s", len(lines), buf.String())
+	}
+
+	expected := []struct {
+		root      string
+		hasErrors bool
+	}{
+		{first, false},
+		{missing, true},
+		{second, false},
+	}
+	for i, line := range lines {
+		var report struct {
+			Root   string            `json:"root"`
+			Errors []json.RawMessage `json:"errors"`
+		}
+		if err := json.Unmarshal([]byte(line), &report); err != nil {
+			t.Fatalf("line %d is not valid JSON: %v\n%s", i+1, err, line)
+		}
+		if report.Root != expected[i].root {
+			t.Errorf("line %d root = %q, expected %q", i+1, report.Root, expected[i].root)
+		}
+		if hasErrors := len(report.Errors) > 0; hasErrors != expected[i].hasErrors {
+			t.Errorf("line %d has errors = %v, expected %v", i+1, hasErrors, expected[i].hasErrors)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/brandonapol/snoop/formatter"
+)
+
+// daemonReportsKept is how many reports --output-dir keeps before the oldest
+// are removed
+const daemonReportsKept = 10
+
+// daemonReportPrefix starts the name of every report the daemon writes, so
+// rotation never touches other files in the directory
+const daemonReportPrefix = "snoop-"
+
+// daemon re-runs a scan on a schedule, stores each report, and alerts only
+// when the findings differ from the previous cycle. Every cycle builds a new
+// audit runner that bypasses the OSV cache, so advisories published between
+// cycles are picked up.
+type daemon struct {
+	scan      func(ctx context.Context) *formatter.ScanOutput
+	alert     func(output *formatter.ScanOutput, diff formatter.FindingsDiff)
+	outputDir string // Where each cycle's report is written; empty keeps none
+	previous  *formatter.ScanOutput
+}
+
+// newDaemon creates a daemon scanning dir with the command-line flags
+func newDaemon(dir string) *daemon {
+	return &daemon{
+		scan:      func(ctx context.Context) *formatter.ScanOutput { return scanProjectIsolated(ctx, dir) },
+		alert:     alertChanges,
+		outputDir: outputDir,
+	}
+}
+
+// run scans once right away and then every interval until ctx is done
+func (d *daemon) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		// A cycle cut short by ctx isn't worth a warning
+		if err := d.cycle(ctx); err != nil && ctx.Err() == nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// cycle runs one scan and alerts when its findings changed. The first cycle
+// alerts on any finding. A scan whose backend was unavailable isn't compared,
+// since its missing findings would look resolved and then new again. A scan
+// stopped by ctx is discarded.
+func (d *daemon) cycle(ctx context.Context) error {
+	output := d.scan(ctx)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var reportErr error
+	if d.outputDir != "" {
+		reportErr = writeDaemonReport(d.outputDir, output)
+	}
+
+	if unverified := formatter.UnverifiedEcosystems(output); len(unverified) > 0 {
+		return fmt.Errorf("scan not compared with the previous cycle: %s unverified", strings.Join(unverified, ", "))
+	}
+	if len(output.ScanResults.Errors) > 0 {
+		return fmt.Errorf("scan not compared with the previous cycle: %v", output.ScanResults.Errors[0])
+	}
+
+	diff := formatter.DiffFindings(d.previous, output)
+	d.previous = output
+	if diff.Changed() {
+		d.alert(output, diff)
+	}
+	return reportErr
+}
+
+// alertChanges prints the changed findings and sends the report to the
+// webhook when one is configured
+func alertChanges(output *formatter.ScanOutput, diff formatter.FindingsDiff) {
+	fmt.Printf("%s: %d new, %d resolved finding(s)\n", time.Now().Format(time.RFC3339), len(diff.New), len(diff.Resolved))
+	for _, change := range diff.New {
+		fmt.Printf("  + %s\n", change)
+	}
+	for _, change := range diff.Resolved {
+		fmt.Printf("  - %s\n", change)
+	}
+
+	if webhookSink != nil {
+		sendWebhook(output)
+	}
+}
+
+// daemonReportExtensions maps output formats to report file extensions
+var daemonReportExtensions = map[string]string{
+	"json":      "json",
+	"markdown":  "md",
+	"sarif":     "sarif",
+	"cyclonedx": "cdx.json",
+	"junit":     "xml",
+	"csv":       "csv",
+	"html":      "html",
+	"gitlab":    "gl.json",
+}
+
+// writeDaemonReport writes a timestamped report in the --format format to dir,
+// then removes all but the newest daemonReportsKept reports
+func writeDaemonReport(dir string, output *formatter.ScanOutput) error {
+	report, err := formatter.GetFormatter(formatter.OutputFormat(format)).Format(output)
+	if err != nil {
+		return fmt.Errorf("failed to format report: %w", err)
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	extension, ok := daemonReportExtensions[format]
+	if !ok {
+		extension = "txt"
+	}
+	// Nanoseconds keep names unique and sorting by time
+	name := daemonReportPrefix + time.Now().UTC().Format("20060102T150405.000000000Z") + "." + extension
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(report+"\n"), 0o644); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+
+	return rotateDaemonReports(dir)
+}
+
+// rotateDaemonReports removes the oldest reports in dir beyond daemonReportsKept
+func rotateDaemonReports(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to list output directory: %w", err)
+	}
+
+	var reports []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasPrefix(entry.Name(), daemonReportPrefix) {
+			reports = append(reports, entry.Name())
+		}
+	}
+	sort.Strings(reports)
+
+	for len(reports) > daemonReportsKept {
+		if err := os.Remove(filepath.Join(dir, reports[0])); err != nil {
+			return fmt.Errorf("failed to remove old report: %w", err)
+		}
+		reports = reports[1:]
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/brandonapol/snoop/formatter"
+	"github.com/brandonapol/snoop/osv"
+	"github.com/brandonapol/snoop/scanner"
+)
+
+func TestDaemonAlertsWhenAdvisoryAppears(t *testing.T) {
+	// The advisory is only published after the first cycle
+	var published atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response osv.QueryResponse
+		if published.Load() {
+			response.Vulns = []osv.Vulnerability{{ID: "GHSA-m2qf-hxjv-5gpq", Summary: "Flask session cookie disclosure"}}
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Errorf("failed to encode mock response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	osvClient = osv.NewClientWithURL(server.URL)
+	t.Cleanup(func() { osvClient = nil })
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "requirements.txt"), []byte("flask==2.0.0\n"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	var alerts []formatter.FindingsDiff
+	d := newDaemon(dir)
+	d.outputDir = t.TempDir()
+	d.alert = func(_ *formatter.ScanOutput, diff formatter.FindingsDiff) {
+		alerts = append(alerts, diff)
+	}
+
+	if err := d.cycle(context.Background()); err != nil {
+		t.Fatalf("first cycle error = %v", err)
+	}
+	if len(alerts) != 0 {
+		t.Fatalf("clean first cycle alerted: %+v", alerts)
+	}
+
+	published.Store(true)
+	if err := d.cycle(context.Background()); err != nil {
+		t.Fatalf("second cycle error = %v", err)
+	}
+	if len(alerts) != 1 {
+		t.Fatalf("second cycle raised %d alerts, expected 1", len(alerts))
+	}
+	diff := alerts[0]
+	if len(diff.New) != 1 || diff.New[0].ID != "GHSA-m2qf-hxjv-5gpq" || diff.New[0].Package != "flask" || len(diff.Resolved) != 0 {
+		t.Errorf("second cycle diff = %+v, expected only the new flask advisory", diff)
+	}
+
+	// An unchanged cycle doesn't alert again
+	if err := d.cycle(context.Background()); err != nil {
+		t.Fatalf("third cycle error = %v", err)
+	}
+	if len(alerts) != 1 {
+		t.Errorf("unchanged cycle alerted, %d alerts in total", len(alerts))
+	}
+
+	reports, err := os.ReadDir(d.outputDir)
+	if err != nil {
+		t.Fatalf("failed to list output directory: %v", err)
+	}
+	if len(reports) != 3 || !strings.HasPrefix(reports[0].Name(), daemonReportPrefix) {
+		t.Errorf("output directory has %d reports, expected one per cycle", len(reports))
+	}
+}
+
+func TestDaemonBypassesOSVCache(t *testing.T) {
+	// The advisory is only published after the first cycle, well within the
+	// cache TTL
+	var published atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response osv.QueryResponse
+		if published.Load() {
+			response.Vulns = []osv.Vulnerability{{ID: "GHSA-m2qf-hxjv-5gpq", Summary: "Flask session cookie disclosure"}}
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Errorf("failed to encode mock response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	// The cache is on, as it is by default, and lives in a temporary directory
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	previousURL, previousNoCache, previousTTL, previousDaemon := osvURL, noCache, cacheTTL, daemonMode
+	osvURL, noCache, cacheTTL, daemonMode = server.URL, false, osv.DefaultCacheTTL, true
+	t.Cleanup(func() {
+		osvURL, noCache, cacheTTL, daemonMode = previousURL, previousNoCache, previousTTL, previousDaemon
+	})
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "requirements.txt"), []byte("flask==2.0.0\n"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	var alerts []formatter.FindingsDiff
+	d := newDaemon(dir)
+	d.alert = func(_ *formatter.ScanOutput, diff formatter.FindingsDiff) {
+		alerts = append(alerts, diff)
+	}
+
+	if err := d.cycle(context.Background()); err != nil {
+		t.Fatalf("first cycle error = %v", err)
+	}
+	published.Store(true)
+	if err := d.cycle(context.Background()); err != nil {
+		t.Fatalf("second cycle error = %v", err)
+	}
+
+	if len(alerts) != 1 || len(alerts[0].New) != 1 || alerts[0].New[0].ID != "GHSA-m2qf-hxjv-5gpq" {
+		t.Errorf("alerts = %+v, expected the second cycle to alert on the new advisory", alerts)
+	}
+}
+
+func TestDaemonDiscardsInterruptedCycle(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	d := &daemon{
+		scan: func(ctx context.Context) *formatter.ScanOutput {
+			// The scan is cut short partway through
+			cancel()
+			return &formatter.ScanOutput{ScanResults: &scanner.ScanResult{}}
+		},
+		alert: func(*formatter.ScanOutput, formatter.FindingsDiff) {
+			t.Error("interrupted cycle alerted")
+		},
+		outputDir: t.TempDir(),
+	}
+
+	if err := d.cycle(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("cycle() error = %v, expected context.Canceled", err)
+	}
+	if d.previous != nil {
+		t.Error("interrupted cycle became the baseline for the next one")
+	}
+	if reports, _ := os.ReadDir(d.outputDir); len(reports) != 0 {
+		t.Errorf("interrupted cycle wrote %d reports, expected none", len(reports))
+	}
+}
+
+func TestRotateDaemonReports(t *testing.T) {
+	dir := t.TempDir()
+	for i := 0; i < daemonReportsKept+2; i++ {
+		name := filepath.Join(dir, daemonReportPrefix+string(rune('a'+i))+".txt")
+		if err := os.WriteFile(name, nil, 0o644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+	}
+	// Unrelated files are left alone
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), nil, 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	if err := rotateDaemonReports(dir); err != nil {
+		t.Fatalf("rotateDaemonReports() error = %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("failed to list directory: %v", err)
+	}
+	if len(entries) != daemonReportsKept+1 {
+		t.Errorf("directory has %d files, expected %d reports plus notes.txt", len(entries), daemonReportsKept)
+	}
+	if _, err := os.Stat(filepath.Join(dir, daemonReportPrefix+"a.txt")); !os.IsNotExist(err) {
+		t.Errorf("oldest report should have been removed, Stat() error = %v", err)
+	}
+}
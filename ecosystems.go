@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/brandonapol/snoop/scanner"
+)
+
+// ecosystemNames are the accepted --ecosystems values
+var ecosystemNames = []string{"nodejs", "python", "go", "maven", "swift", "cargo", "ruby", "php"}
+
+// ecosystemManifests tells whether a manifest type belongs to each ecosystem
+var ecosystemManifests = map[string]func(scanner.ManifestType) bool{
+	"nodejs": scanner.IsNodeJSManifest,
+	"python": scanner.IsPythonManifest,
+	"go":     scanner.IsGoManifest,
+	"maven":  scanner.IsMavenManifest,
+	"swift":  scanner.IsSwiftManifest,
+	"cargo":  scanner.IsCargoManifest,
+	"ruby":   scanner.IsRubyManifest,
+	"php":    scanner.IsComposerManifest,
+}
+
+// validateEcosystems checks the --ecosystems values
+func validateEcosystems(selected []string) error {
+	for _, ecosystem := range selected {
+		if !slices.Contains(ecosystemNames, ecosystem) {
+			return fmt.Errorf("invalid --ecosystems value %q (available: %s)", ecosystem, strings.Join(ecosystemNames, ", "))
+		}
+	}
+	return nil
+}
+
+// filterEcosystems keeps only the manifests of the selected ecosystems, or
+// all of them when none are selected. Runtime version files and registered
+// custom manifest types belong to none of the ecosystems, so a selection
+// leaves them out too; go.mod still brings the Go toolchain check with it.
+func filterEcosystems(files []scanner.DetectedFile, selected []string) []scanner.DetectedFile {
+	if len(selected) == 0 {
+		return files
+	}
+
+	kept := make([]scanner.DetectedFile, 0, len(files))
+	for _, file := range files {
+		for _, ecosystem := range selected {
+			if ecosystemManifests[ecosystem](file.Type) {
+				kept = append(kept, file)
+				break
+			}
+		}
+	}
+	return kept
+}
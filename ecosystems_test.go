@@ -0,0 +1,45 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/brandonapol/snoop/scanner"
+)
+
+func TestValidateEcosystems(t *testing.T) {
+	if err := validateEcosystems([]string{"go", "nodejs"}); err != nil {
+		t.Errorf("validateEcosystems() unexpected error: %v", err)
+	}
+
+	err := validateEcosystems([]string{"go", "rust"})
+	if err == nil {
+		t.Fatal("validateEcosystems() expected an error for rust")
+	}
+	for _, name := range ecosystemNames {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("validateEcosystems() error %q doesn't list %s", err, name)
+		}
+	}
+}
+
+func TestFilterEcosystems(t *testing.T) {
+	files := []scanner.DetectedFile{
+		{Path: "web/package.json", Type: scanner.PackageJSON},
+		{Path: "web/.nvmrc", Type: scanner.Nvmrc},
+		{Path: "api/go.mod", Type: scanner.GoMod},
+		{Path: "ml/requirements.txt", Type: scanner.RequirementsTxt},
+		{Path: "svc/pom.xml", Type: scanner.PomXML},
+	}
+
+	if kept := filterEcosystems(files, nil); !reflect.DeepEqual(kept, files) {
+		t.Errorf("filterEcosystems() with no selection = %v, expected every manifest", kept)
+	}
+
+	kept := filterEcosystems(files, []string{"go", "maven"})
+	expected := []scanner.DetectedFile{files[2], files[4]}
+	if !reflect.DeepEqual(kept, expected) {
+		t.Errorf("filterEcosystems() = %v, expected %v", kept, expected)
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/brandonapol/snoop/audit"
+)
+
+// readPackageExclusions parses the --exclude-package values along with the
+// entries of the --exclude-packages-from file, if any. The file lists one
+// ecosystem:name per line and may contain blank lines and # comments, so a
+// team can commit it next to the code.
+func readPackageExclusions(specs []string, file string) ([]audit.PackageExclusion, error) {
+	if file != "" {
+		source, err := os.Open(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open exclusions file: %w", err)
+		}
+		listed, err := readPaths(source)
+		_ = source.Close()
+		if err != nil {
+			return nil, err
+		}
+		specs = append(append([]string(nil), specs...), listed...)
+	}
+
+	exclusions := make([]audit.PackageExclusion, 0, len(specs))
+	for _, spec := range specs {
+		exclusion, err := audit.ParsePackageExclusion(spec)
+		if err != nil {
+			return nil, err
+		}
+		exclusions = append(exclusions, exclusion)
+	}
+	return exclusions, nil
+}
+
+// readSuppressions reads the --ignore-file, or the .snoopignore in dir when
+// none was given, which is optional. Expired suppressions are warned about
+// on stderr, since their advisories are reported again.
+func readSuppressions(file, dir string) ([]audit.Suppression, error) {
+	if file == "" {
+		file = filepath.Join(dir, audit.SuppressionFile)
+		if _, err := os.Stat(file); os.IsNotExist(err) {
+			return nil, nil
+		}
+	}
+
+	suppressions, err := audit.ReadSuppressions(file)
+	if err != nil {
+		return nil, err
+	}
+
+	_, expired := audit.ActiveSuppressions(suppressions, time.Now())
+	for _, suppression := range expired {
+		fmt.Fprintf(os.Stderr, "Warning: suppression of %s expired on %s and no longer applies\n", suppression, suppression.Expires)
+	}
+	return suppressions, nil
+}
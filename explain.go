@@ -0,0 +1,196 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/brandonapol/snoop/formatter"
+	"github.com/brandonapol/snoop/osv"
+	"github.com/spf13/cobra"
+)
+
+// explainFormats are the --format values explain accepts
+var explainFormats = []formatter.OutputFormat{formatter.FormatTable, formatter.FormatJSON, formatter.FormatMarkdown}
+
+var explainFormat string
+
+var explainCmd = &cobra.Command{
+	Use:   "explain <id>",
+	Short: "Look up a single vulnerability by its OSV, GHSA, or CVE ID",
+	Long: `Explain fetches one advisory from OSV and prints its summary, details,
+affected ranges, aliases, and references, without scanning a directory.`,
+	Example: `  snoop explain GHSA-29mw-wpgm-hmr9
+  snoop explain CVE-2021-23337 --format json`,
+	Args: cobra.ExactArgs(1),
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		return validateServiceURL("osv-url", osvURL)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		client := osvClient
+		if client == nil && osvURL != "" {
+			client = osv.NewClientWithURL(osv.QueryURL(osvURL))
+		} else if client == nil {
+			client = osv.NewClient()
+		}
+
+		report, err := explain(client, args[0], formatter.OutputFormat(explainFormat))
+		if errors.Is(err, osv.ErrNotFound) {
+			fmt.Fprintf(os.Stderr, "Vulnerability %s not found in OSV\n", args[0])
+			os.Exit(1)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), report)
+	},
+}
+
+func init() {
+	explainCmd.Flags().StringVarP(&explainFormat, "format", "f", "table", "Output format (table, json, markdown)")
+	explainCmd.Flags().StringVar(&osvURL, "osv-url", "", "Base URL of a self-hosted OSV API mirror serving /v1/vulns, such as https://osv.example.com (default https://api.osv.dev)")
+}
+
+// explain fetches the advisory with the given ID and formats it
+func explain(client *osv.Client, id string, format formatter.OutputFormat) (string, error) {
+	var render func(*osv.Vulnerability) (string, error)
+	switch format {
+	case formatter.FormatTable:
+		render = explainTable
+	case formatter.FormatJSON:
+		render = explainJSON
+	case formatter.FormatMarkdown:
+		render = explainMarkdown
+	default:
+		names := make([]string, len(explainFormats))
+		for i, f := range explainFormats {
+			names[i] = string(f)
+		}
+		return "", fmt.Errorf("explain doesn't support format %q; use one of %s", format, strings.Join(names, ", "))
+	}
+
+	vuln, err := client.GetVulnerability(id)
+	if err != nil {
+		return "", err
+	}
+	return render(vuln)
+}
+
+// explainJSON prints the advisory as OSV returned it
+func explainJSON(vuln *osv.Vulnerability) (string, error) {
+	data, err := json.MarshalIndent(vuln, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal advisory: %w", err)
+	}
+	return string(data), nil
+}
+
+func explainTable(vuln *osv.Vulnerability) (string, error) {
+	var builder strings.Builder
+	builder.WriteString(vuln.ID + "\n")
+	builder.WriteString(strings.Repeat("=", len(vuln.ID)) + "\n")
+	if vuln.Summary != "" {
+		builder.WriteString(vuln.Summary + "\n")
+	}
+	builder.WriteString(fmt.Sprintf("\nSeverity: %s (%s)\n", vuln.GetSeverityLevel(), vuln.GetSeverityScore()))
+	if len(vuln.Aliases) > 0 {
+		builder.WriteString(fmt.Sprintf("Aliases: %s\n", strings.Join(vuln.Aliases, ", ")))
+	}
+	if vuln.Published != "" {
+		builder.WriteString(fmt.Sprintf("Published: %s\n", vuln.Published))
+	}
+	if vuln.Modified != "" {
+		builder.WriteString(fmt.Sprintf("Modified: %s\n", vuln.Modified))
+	}
+
+	if vuln.Details != "" {
+		builder.WriteString("\nDetails:\n")
+		builder.WriteString(strings.TrimSpace(vuln.Details) + "\n")
+	}
+
+	if len(vuln.Affected) > 0 {
+		builder.WriteString("\nAffected:\n")
+		for _, affected := range vuln.Affected {
+			builder.WriteString(fmt.Sprintf("  %s (%s): %s\n", affected.Package.Name, affected.Package.Ecosystem, affectedRanges(affected)))
+		}
+	}
+
+	if len(vuln.References) > 0 {
+		builder.WriteString("\nReferences:\n")
+		for _, ref := range vuln.References {
+			builder.WriteString(fmt.Sprintf("  %s\n", ref.URL))
+		}
+	}
+	return strings.TrimRight(builder.String(), "\n"), nil
+}
+
+func explainMarkdown(vuln *osv.Vulnerability) (string, error) {
+	var builder strings.Builder
+	title := vuln.ID
+	if vuln.Summary != "" {
+		title += ": " + vuln.Summary
+	}
+	builder.WriteString("# " + title + "\n\n")
+	builder.WriteString(fmt.Sprintf("**Severity:** %s (%s)\n", vuln.GetSeverityLevel(), vuln.GetSeverityScore()))
+	if len(vuln.Aliases) > 0 {
+		builder.WriteString(fmt.Sprintf("\n**Aliases:** %s\n", strings.Join(vuln.Aliases, ", ")))
+	}
+	if vuln.Published != "" {
+		builder.WriteString(fmt.Sprintf("\n**Published:** %s\n", vuln.Published))
+	}
+
+	if vuln.Details != "" {
+		builder.WriteString("\n## Details\n\n")
+		builder.WriteString(strings.TrimSpace(vuln.Details) + "\n")
+	}
+
+	if len(vuln.Affected) > 0 {
+		builder.WriteString("\n## Affected\n\n")
+		builder.WriteString("| Package | Ecosystem | Versions |\n")
+		builder.WriteString("|---------|-----------|----------|\n")
+		for _, affected := range vuln.Affected {
+			builder.WriteString(fmt.Sprintf("| %s | %s | %s |\n", affected.Package.Name, affected.Package.Ecosystem, affectedRanges(affected)))
+		}
+	}
+
+	if len(vuln.References) > 0 {
+		builder.WriteString("\n## References\n\n")
+		for _, ref := range vuln.References {
+			builder.WriteString(fmt.Sprintf("- %s\n", ref.URL))
+		}
+	}
+	return strings.TrimRight(builder.String(), "\n"), nil
+}
+
+// affectedRanges describes the versions an affected entry covers, one
+// comparison per introduced, fixed, or last affected event, such as
+// ">= 1.0.0, < 1.2.3"; with no ranges it lists the affected versions
+func affectedRanges(affected osv.Affected) string {
+	var ranges []string
+	for _, r := range affected.Ranges {
+		var bounds []string
+		for _, event := range r.Events {
+			switch {
+			case event.Introduced != "":
+				bounds = append(bounds, ">= "+event.Introduced)
+			case event.Fixed != "":
+				bounds = append(bounds, "< "+event.Fixed)
+			case event.LastAffected != "":
+				bounds = append(bounds, "<= "+event.LastAffected)
+			}
+		}
+		if len(bounds) > 0 {
+			ranges = append(ranges, strings.Join(bounds, ", "))
+		}
+	}
+	if len(ranges) > 0 {
+		return strings.Join(ranges, "; ")
+	}
+	if len(affected.Versions) > 0 {
+		return strings.Join(affected.Versions, ", ")
+	}
+	return "all versions"
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/brandonapol/snoop/formatter"
+	"github.com/brandonapol/snoop/osv"
+)
+
+func newExplainClient(t *testing.T) *osv.Client {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/vulns/GHSA-35jh-r3h4-6jhm" {
+			http.Error(w, `{"code":5,"message":"Bug not found."}`, http.StatusNotFound)
+			return
+		}
+		vuln := osv.Vulnerability{
+			ID:        "GHSA-35jh-r3h4-6jhm",
+			Summary:   "Command Injection in lodash",
+			Details:   "lodash versions prior to 4.17.21 are vulnerable to Command Injection via template.",
+			Aliases:   []string{"CVE-2021-23337"},
+			Published: "2021-05-06T16:05:51Z",
+			Severity:  []osv.Severity{{Type: "CVSS_V3", Score: "CVSS:3.1/AV:N/AC:L/PR:H/UI:N/S:U/C:H/I:H/A:H"}},
+			Affected: []osv.Affected{{
+				Package: osv.Package{Name: "lodash", Ecosystem: osv.NPM},
+				Ranges:  []osv.VersionRange{{Type: "SEMVER", Events: []osv.Event{{Introduced: "0"}, {Fixed: "4.17.21"}}}},
+			}},
+			References: []osv.Reference{{Type: "ADVISORY", URL: "https://nvd.nist.gov/vuln/detail/CVE-2021-23337"}},
+		}
+		if err := json.NewEncoder(w).Encode(vuln); err != nil {
+			t.Errorf("failed to encode mock advisory: %v", err)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return osv.NewClientWithURL(server.URL)
+}
+
+func TestExplain(t *testing.T) {
+	client := newExplainClient(t)
+
+	for _, format := range explainFormats {
+		report, err := explain(client, "GHSA-35jh-r3h4-6jhm", format)
+		if err != nil {
+			t.Fatalf("explain(%s) unexpected error: %v", format, err)
+		}
+		for _, expected := range []string{"GHSA-35jh-r3h4-6jhm", "Command Injection in lodash", "CVE-2021-23337", "https://nvd.nist.gov/vuln/detail/CVE-2021-23337"} {
+			if !strings.Contains(report, expected) {
+				t.Errorf("explain(%s) missing %q:\n%s", format, expected, report)
+			}
+		}
+		if format != formatter.FormatJSON && !strings.Contains(report, ">= 0, < 4.17.21") {
+			t.Errorf("explain(%s) missing the affected range:\n%s", format, report)
+		}
+	}
+}
+
+func TestExplainNotFound(t *testing.T) {
+	client := newExplainClient(t)
+
+	if _, err := explain(client, "GHSA-xxxx-xxxx-xxxx", formatter.FormatTable); !errors.Is(err, osv.ErrNotFound) {
+		t.Errorf("explain() error = %v, expected ErrNotFound", err)
+	}
+}
+
+func TestExplainRejectsUnsupportedFormat(t *testing.T) {
+	client := newExplainClient(t)
+
+	if _, err := explain(client, "GHSA-35jh-r3h4-6jhm", formatter.FormatSARIF); err == nil {
+		t.Error("explain() with sarif format expected an error")
+	}
+}
+
+func TestAffectedRanges(t *testing.T) {
+	tests := []struct {
+		name     string
+		affected osv.Affected
+		expected string
+	}{
+		{
+			name:     "introduced and fixed",
+			affected: osv.Affected{Ranges: []osv.VersionRange{{Events: []osv.Event{{Introduced: "1.0.0"}, {Fixed: "1.2.3"}}}}},
+			expected: ">= 1.0.0, < 1.2.3",
+		},
+		{
+			name:     "last affected",
+			affected: osv.Affected{Ranges: []osv.VersionRange{{Events: []osv.Event{{Introduced: "0"}, {LastAffected: "2.0.0"}}}}},
+			expected: ">= 0, <= 2.0.0",
+		},
+		{
+			name:     "versions only",
+			affected: osv.Affected{Versions: []string{"1.0.0", "1.0.1"}},
+			expected: "1.0.0, 1.0.1",
+		},
+		{
+			name:     "nothing listed",
+			affected: osv.Affected{},
+			expected: "all versions",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := affectedRanges(tt.affected); got != tt.expected {
+				t.Errorf("affectedRanges() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package formatter
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/brandonapol/snoop/audit"
+)
+
+// maxTableTitle caps how much of an advisory title the table format prints
+const maxTableTitle = 60
+
+// npmAdvisory is one advisory behind an npm audit entry
+type npmAdvisory struct {
+	id    string
+	title string
+	url   string
+	cvss  float64 // 0 when the advisory has no CVSS score
+}
+
+// npmAdvisories reads the advisories listed in an npm audit entry's via. npm
+// audit lists objects with a url, title, and cvss; the OSV fallback lists
+// advisory IDs. The names of vulnerable dependencies, also listed there, are
+// left out; see viaDependencies.
+func npmAdvisories(vuln audit.Vulnerability) []npmAdvisory {
+	ids := vuln.AdvisoryIDs()
+	var advisories []npmAdvisory
+	for _, via := range vuln.Via {
+		switch via := via.(type) {
+		case string:
+			if slices.Contains(ids, via) {
+				advisories = append(advisories, npmAdvisory{id: via, url: osvHelpURI(via)})
+			}
+		case map[string]any:
+			advisory := npmAdvisory{}
+			advisory.url, _ = via["url"].(string)
+			advisory.title, _ = via["title"].(string)
+			if cvss, ok := via["cvss"].(map[string]any); ok {
+				advisory.cvss, _ = cvss["score"].(float64)
+			}
+			if advisory.url != "" {
+				advisory.id = path.Base(advisory.url)
+			} else if name, ok := via["name"].(string); ok {
+				advisory.id = name
+			}
+			if advisory.id == "" && advisory.title == "" {
+				continue
+			}
+			advisories = append(advisories, advisory)
+		}
+	}
+	return advisories
+}
+
+// viaDependencies lists the dependencies an npm audit entry is vulnerable
+// through, which npm audit names in via instead of an advisory
+func viaDependencies(vuln audit.Vulnerability) []string {
+	ids := vuln.AdvisoryIDs()
+	var names []string
+	for _, via := range vuln.Via {
+		if name, ok := via.(string); ok && !slices.Contains(ids, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// describe is the advisory's title followed by its CVSS score, if known
+func (a npmAdvisory) describe() string {
+	if a.cvss > 0 {
+		if a.title == "" {
+			return fmt.Sprintf("CVSS %.1f", a.cvss)
+		}
+		return fmt.Sprintf("%s (CVSS %.1f)", a.title, a.cvss)
+	}
+	return a.title
+}
+
+// truncate shortens s to at most limit characters, ending it with "..." when
+// anything was cut
+func truncate(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit-3]) + "..."
+}
+
+// writeTableAdvisories writes the advisories of an npm audit entry below its
+// row, one per line with the title cut to fit, or the dependencies it's
+// vulnerable through when it has none of its own
+func writeTableAdvisories(builder *strings.Builder, vuln audit.Vulnerability) {
+	for _, advisory := range npmAdvisories(vuln) {
+		line := "  " + advisory.id
+		if description := advisory.describe(); description != "" {
+			line += ": " + truncate(description, maxTableTitle)
+		}
+		if advisory.url != "" {
+			line += " " + advisory.url
+		}
+		builder.WriteString(line + "\n")
+	}
+	if dependencies := viaDependencies(vuln); len(dependencies) > 0 {
+		builder.WriteString(fmt.Sprintf("  Via: %s\n", strings.Join(dependencies, ", ")))
+	}
+}
+
+// markdownAdvisories renders the advisory and description cells of an npm
+// audit entry's markdown row, with one advisory per line
+func markdownAdvisories(vuln audit.Vulnerability) (links, descriptions string) {
+	var linkLines, descriptionLines []string
+	for _, advisory := range npmAdvisories(vuln) {
+		link := advisory.id
+		if advisory.url != "" {
+			link = fmt.Sprintf("[%s](%s)", advisory.id, advisory.url)
+		}
+		linkLines = append(linkLines, link)
+		descriptionLines = append(descriptionLines, escapeMarkdownCell(advisory.describe()))
+	}
+	// Blank lines keep descriptions beside their advisory, unless all are blank
+	if strings.Join(descriptionLines, "") == "" {
+		descriptionLines = nil
+	}
+	if dependencies := viaDependencies(vuln); len(dependencies) > 0 {
+		descriptionLines = append(descriptionLines, fmt.Sprintf("Via `%s`", strings.Join(dependencies, "`, `")))
+	}
+	return strings.Join(linkLines, "<br>"), strings.Join(descriptionLines, "<br>")
+}
+
+// escapeMarkdownCell keeps text inside its markdown table cell
+func escapeMarkdownCell(text string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(text, "|", "\\|"), "\n", " ")
+}
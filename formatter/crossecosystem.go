@@ -0,0 +1,255 @@
+package formatter
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/brandonapol/snoop/audit"
+	"github.com/brandonapol/snoop/osv"
+)
+
+// CrossEcosystemFinding is one affected package of a cross-ecosystem advisory
+type CrossEcosystemFinding struct {
+	Ecosystem string `json:"ecosystem"`
+	Package   string `json:"package"`
+	Version   string `json:"version,omitempty"`
+	Manifest  string `json:"manifest"`
+}
+
+// CrossEcosystemAdvisory groups findings from different ecosystems that share
+// a vulnerability, such as a library published to both PyPI and npm
+type CrossEcosystemAdvisory struct {
+	ID         string                  `json:"id"` // A CVE when one is known
+	Aliases    []string                `json:"aliases"`
+	Ecosystems []string                `json:"ecosystems"`
+	Findings   []CrossEcosystemFinding `json:"findings"`
+}
+
+// correlatedFinding is a finding along with every identifier it is known by
+type correlatedFinding struct {
+	finding CrossEcosystemFinding
+	ids     []string
+}
+
+// CrossEcosystemAdvisories correlates findings across ecosystems through their
+// advisory IDs and aliases, returning the vulnerabilities that affect packages
+// in more than one ecosystem
+func CrossEcosystemAdvisories(output *ScanOutput) []CrossEcosystemAdvisory {
+	findings := collectCorrelatedFindings(output)
+
+	// Union-find over findings, linked through shared identifiers
+	parent := make([]int, len(findings))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	owner := make(map[string]int)
+	for i, finding := range findings {
+		for _, id := range finding.ids {
+			if j, ok := owner[id]; ok {
+				parent[find(i)] = find(j)
+				continue
+			}
+			owner[id] = i
+		}
+	}
+
+	groups := make(map[int][]correlatedFinding)
+	for i, finding := range findings {
+		root := find(i)
+		groups[root] = append(groups[root], finding)
+	}
+
+	var advisories []CrossEcosystemAdvisory
+	for _, group := range groups {
+		advisory := CrossEcosystemAdvisory{}
+		ids := make(map[string]bool)
+		for _, finding := range group {
+			advisory.Findings = append(advisory.Findings, finding.finding)
+			if !slices.Contains(advisory.Ecosystems, finding.finding.Ecosystem) {
+				advisory.Ecosystems = append(advisory.Ecosystems, finding.finding.Ecosystem)
+			}
+			for _, id := range finding.ids {
+				ids[id] = true
+			}
+		}
+		if len(advisory.Ecosystems) < 2 {
+			continue
+		}
+
+		advisory.ID, advisory.Aliases = primaryAdvisoryID(ids)
+		sort.Strings(advisory.Ecosystems)
+		sort.Slice(advisory.Findings, func(i, j int) bool {
+			a, b := advisory.Findings[i], advisory.Findings[j]
+			if a.Ecosystem != b.Ecosystem {
+				return a.Ecosystem < b.Ecosystem
+			}
+			if a.Package != b.Package {
+				return a.Package < b.Package
+			}
+			return a.Manifest < b.Manifest
+		})
+		advisories = append(advisories, advisory)
+	}
+
+	sort.Slice(advisories, func(i, j int) bool {
+		return advisories[i].ID < advisories[j].ID
+	})
+	return advisories
+}
+
+// collectCorrelatedFindings gathers the findings of every ecosystem with the
+// identifiers each one is known by
+func collectCorrelatedFindings(output *ScanOutput) []correlatedFinding {
+	var findings []correlatedFinding
+	add := func(finding CrossEcosystemFinding, id string, aliases []string) {
+		var ids []string
+		// A collapsed unpinned finding stands for many advisories, not one
+		if id != "" && id != audit.UnpinnedAdvisoriesID {
+			ids = append(ids, id)
+		}
+		ids = append(ids, aliases...)
+		if len(ids) > 0 {
+			findings = append(findings, correlatedFinding{finding: finding, ids: ids})
+		}
+	}
+
+	for _, result := range output.AuditResults {
+		for _, vuln := range result.Vulnerabilities {
+			add(CrossEcosystemFinding{Ecosystem: string(osv.NPM), Package: vuln.Name, Manifest: result.PackageJSONPath}, "", vuln.AdvisoryIDs())
+		}
+	}
+	for _, result := range output.PythonAuditResults {
+		for _, vuln := range result.Vulnerabilities {
+			if vuln.ID == audit.UnpinnedAdvisoriesID {
+				continue
+			}
+			add(CrossEcosystemFinding{Ecosystem: string(osv.PyPI), Package: vuln.Name, Version: vuln.Version, Manifest: result.ManifestPath}, vuln.ID, vuln.Aliases)
+		}
+	}
+	for _, result := range output.GoAuditResults {
+		for _, vuln := range result.Vulnerabilities {
+			add(CrossEcosystemFinding{Ecosystem: string(osv.Go), Package: vuln.Module, Version: vuln.Version, Manifest: result.ManifestPath}, vuln.ID, vuln.Aliases)
+		}
+	}
+	for _, result := range output.MavenAuditResults {
+		for _, vuln := range result.Vulnerabilities {
+			name := vuln.ArtifactName()
+			add(CrossEcosystemFinding{Ecosystem: string(osv.Maven), Package: name, Version: vuln.Version, Manifest: result.ManifestPath}, vuln.ID, vuln.Aliases)
+		}
+	}
+	for _, result := range output.SwiftAuditResults {
+		for _, vuln := range result.Vulnerabilities {
+			add(CrossEcosystemFinding{Ecosystem: string(osv.SwiftURL), Package: vuln.Name, Version: vuln.Version, Manifest: result.ManifestPath}, vuln.ID, vuln.Aliases)
+		}
+	}
+	for _, result := range output.CargoAuditResults {
+		for _, vuln := range result.Vulnerabilities {
+			add(CrossEcosystemFinding{Ecosystem: string(osv.CratesIO), Package: vuln.Name, Version: vuln.Version, Manifest: result.ManifestPath}, vuln.ID, vuln.Aliases)
+		}
+	}
+	for _, result := range output.RubyAuditResults {
+		for _, vuln := range result.Vulnerabilities {
+			add(CrossEcosystemFinding{Ecosystem: string(osv.RubyGems), Package: vuln.Name, Version: vuln.Version, Manifest: result.ManifestPath}, vuln.ID, vuln.Aliases)
+		}
+	}
+	for _, result := range output.ComposerAuditResults {
+		for _, vuln := range result.Vulnerabilities {
+			add(CrossEcosystemFinding{Ecosystem: string(osv.Packagist), Package: vuln.Name, Version: vuln.Version, Manifest: result.ManifestPath}, vuln.ID, vuln.Aliases)
+		}
+	}
+	for _, result := range output.RuntimeAuditResults {
+		for _, vuln := range result.Vulnerabilities {
+			add(CrossEcosystemFinding{Ecosystem: EcosystemRuntime, Package: vuln.Runtime, Version: vuln.Version, Manifest: result.ManifestPath}, vuln.ID, vuln.Aliases)
+		}
+	}
+	for _, result := range output.SBOMAuditResults {
+		for _, vuln := range result.Vulnerabilities {
+			add(CrossEcosystemFinding{Ecosystem: string(vuln.Ecosystem), Package: vuln.Name, Version: vuln.Version, Manifest: result.SBOMPath}, vuln.ID, vuln.Aliases)
+		}
+	}
+	for _, result := range output.CustomAuditResults {
+		for _, vuln := range result.Vulnerabilities {
+			add(CrossEcosystemFinding{Ecosystem: string(vuln.Ecosystem), Package: vuln.Name, Version: vuln.Version, Manifest: result.ManifestPath}, vuln.ID, vuln.Aliases)
+		}
+	}
+
+	return findings
+}
+
+// primaryAdvisoryID picks the identifier to headline a group with, preferring
+// the lowest CVE, and returns the rest as sorted aliases
+func primaryAdvisoryID(ids map[string]bool) (string, []string) {
+	var all []string
+	for id := range ids {
+		all = append(all, id)
+	}
+	sort.Strings(all)
+
+	primary := all[0]
+	for _, id := range all {
+		if strings.HasPrefix(id, "CVE-") {
+			primary = id
+			break
+		}
+	}
+
+	aliases := make([]string, 0, len(all)-1)
+	for _, id := range all {
+		if id != primary {
+			aliases = append(aliases, id)
+		}
+	}
+	return primary, aliases
+}
+
+// describeFinding renders a finding as "package@version (manifest)"
+func describeFinding(finding CrossEcosystemFinding) string {
+	name := finding.Package
+	if finding.Version != "" {
+		name += "@" + finding.Version
+	}
+	return fmt.Sprintf("%s (%s)", name, finding.Manifest)
+}
+
+// writeTableCrossEcosystem writes the cross-ecosystem advisories section
+func writeTableCrossEcosystem(builder *strings.Builder, advisories []CrossEcosystemAdvisory) {
+	if len(advisories) == 0 {
+		return
+	}
+
+	builder.WriteString("Cross-ecosystem advisories\n")
+	builder.WriteString(strings.Repeat("-", 80) + "\n")
+	for _, advisory := range advisories {
+		builder.WriteString(fmt.Sprintf("%s affects %s dependencies:\n", advisory.ID, strings.Join(advisory.Ecosystems, " and ")))
+		for _, finding := range advisory.Findings {
+			builder.WriteString(fmt.Sprintf("  [%s] %s\n", finding.Ecosystem, describeFinding(finding)))
+		}
+	}
+	builder.WriteString("\n")
+}
+
+// writeMarkdownCrossEcosystem writes the cross-ecosystem advisories section
+func writeMarkdownCrossEcosystem(builder *strings.Builder, advisories []CrossEcosystemAdvisory) {
+	if len(advisories) == 0 {
+		return
+	}
+
+	builder.WriteString("### Cross-ecosystem Advisories\n\n")
+	for _, advisory := range advisories {
+		builder.WriteString(fmt.Sprintf("**%s** affects %s dependencies:\n\n", advisory.ID, strings.Join(advisory.Ecosystems, " and ")))
+		for _, finding := range advisory.Findings {
+			builder.WriteString(fmt.Sprintf("- %s: `%s`\n", finding.Ecosystem, describeFinding(finding)))
+		}
+		builder.WriteString("\n")
+	}
+}
@@ -0,0 +1,46 @@
+package formatter
+
+import (
+	"encoding/csv"
+	"fmt"
+	"strings"
+)
+
+// csvHeader names the columns of CSV output
+var csvHeader = []string{"ecosystem", "manifest", "package", "version", "id", "severity", "fix_versions", "dependency"}
+
+// CSVFormatter implements CSV output for spreadsheet triage: a header row,
+// then one row per finding across every ecosystem. Fix versions are joined
+// with "; ", and dependency is direct or indirect, or empty for runtimes and
+// SBOM components.
+type CSVFormatter struct{}
+
+func (f *CSVFormatter) Format(output *ScanOutput) (string, error) {
+	var builder strings.Builder
+	writer := csv.NewWriter(&builder)
+
+	if err := writer.Write(csvHeader); err != nil {
+		return "", fmt.Errorf("failed to write CSV header: %w", err)
+	}
+	for _, finding := range collectFindings(output) {
+		row := []string{
+			finding.ecosystem,
+			relativePath(output.Metadata.Directory, finding.manifest),
+			finding.pkg,
+			finding.version,
+			finding.id,
+			string(grepSeverity(finding.severity)),
+			strings.Join(finding.fixVersions, "; "),
+			finding.dependency,
+		}
+		if err := writer.Write(row); err != nil {
+			return "", fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return "", fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return builder.String(), nil
+}
@@ -0,0 +1,334 @@
+package formatter
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/brandonapol/snoop/audit"
+	"github.com/brandonapol/snoop/osv"
+)
+
+// cycloneDXSpecVersion is the CycloneDX version of exported SBOMs
+const cycloneDXSpecVersion = "1.5"
+
+// cycloneDXRootRef is the bom-ref of the scanned project itself
+const cycloneDXRootRef = "root"
+
+// cycloneDXBOM is the root of a CycloneDX JSON document
+type cycloneDXBOM struct {
+	BOMFormat       string                   `json:"bomFormat"`
+	SpecVersion     string                   `json:"specVersion"`
+	SerialNumber    string                   `json:"serialNumber,omitempty"`
+	Version         int                      `json:"version"`
+	Metadata        cycloneDXMetadata        `json:"metadata"`
+	Components      []cycloneDXComponent     `json:"components"`
+	Dependencies    []cycloneDXDependency    `json:"dependencies"`
+	Vulnerabilities []cycloneDXVulnerability `json:"vulnerabilities,omitempty"`
+}
+
+type cycloneDXMetadata struct {
+	Timestamp string             `json:"timestamp,omitempty"`
+	Tools     cycloneDXTools     `json:"tools"`
+	Component cycloneDXComponent `json:"component"`
+}
+
+type cycloneDXTools struct {
+	Components []cycloneDXComponent `json:"components"`
+}
+
+type cycloneDXComponent struct {
+	Type    string `json:"type"`
+	BOMRef  string `json:"bom-ref,omitempty"`
+	Group   string `json:"group,omitempty"`
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	PURL    string `json:"purl,omitempty"`
+}
+
+type cycloneDXDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn"`
+}
+
+type cycloneDXVulnerability struct {
+	BOMRef         string            `json:"bom-ref"`
+	ID             string            `json:"id"`
+	Source         cycloneDXSource   `json:"source"`
+	Ratings        []cycloneDXRating `json:"ratings"`
+	Description    string            `json:"description,omitempty"`
+	Recommendation string            `json:"recommendation,omitempty"`
+	Affects        []cycloneDXAffect `json:"affects"`
+}
+
+type cycloneDXSource struct {
+	Name string `json:"name"`
+	URL  string `json:"url,omitempty"`
+}
+
+type cycloneDXRating struct {
+	Source   cycloneDXSource `json:"source"`
+	Severity string          `json:"severity"`
+}
+
+type cycloneDXAffect struct {
+	Ref string `json:"ref"`
+}
+
+// cycloneDXSeverity maps a severity to a CycloneDX rating severity
+func cycloneDXSeverity(severity string) string {
+	switch audit.Severity(strings.ToLower(severity)) {
+	case audit.SeverityCritical:
+		return "critical"
+	case audit.SeverityHigh:
+		return "high"
+	case audit.SeverityModerate, "medium":
+		return "medium"
+	case audit.SeverityLow:
+		return "low"
+	case audit.SeverityInfo:
+		return "info"
+	default:
+		return "unknown"
+	}
+}
+
+// cycloneDXLibrary describes a dependency as a component whose bom-ref is its
+// package URL. npm scopes and Maven group IDs become the component group.
+func cycloneDXLibrary(ecosystem osv.Ecosystem, name, version string) (cycloneDXComponent, bool) {
+	purl := packageURL(ecosystem, name, version)
+	if purl == "" {
+		return cycloneDXComponent{}, false
+	}
+
+	component := cycloneDXComponent{Type: "library", BOMRef: purl, Name: name, Version: version, PURL: purl}
+	switch ecosystem {
+	case osv.NPM:
+		if scope, unscoped, ok := strings.Cut(name, "/"); ok && strings.HasPrefix(scope, "@") {
+			component.Group, component.Name = scope, unscoped
+		}
+	case osv.Maven:
+		if group, artifact, ok := strings.Cut(name, ":"); ok {
+			component.Group, component.Name = group, artifact
+		}
+	}
+	return component, true
+}
+
+// cycloneDXInventory collects the dependencies of every audited manifest,
+// deduplicated by package URL
+type cycloneDXInventory struct {
+	components map[string]cycloneDXComponent
+	direct     map[string]bool
+}
+
+func (inv *cycloneDXInventory) add(ecosystem osv.Ecosystem, name, version string, direct bool) string {
+	component, ok := cycloneDXLibrary(ecosystem, name, version)
+	if !ok {
+		return ""
+	}
+	inv.components[component.BOMRef] = component
+	if direct {
+		inv.direct[component.BOMRef] = true
+	}
+	return component.BOMRef
+}
+
+// affected resolves the package URL of a finding to component references. A
+// finding without a version affects every version of the package in the
+// inventory.
+func (inv *cycloneDXInventory) affected(purl string) []string {
+	if _, ok := inv.components[purl]; ok {
+		return []string{purl}
+	}
+
+	// Names never contain a literal "@", so one marks a version
+	var refs []string
+	if !strings.Contains(purl, "@") {
+		for ref := range inv.components {
+			if strings.HasPrefix(ref, purl+"@") {
+				refs = append(refs, ref)
+			}
+		}
+	}
+	if len(refs) > 0 {
+		sort.Strings(refs)
+		return refs
+	}
+
+	// Findings on packages the manifests don't list, such as transitive npm
+	// dependencies without a lockfile, are still recorded
+	component, err := audit.ParsePURL(purl)
+	if err != nil {
+		return nil
+	}
+	if ref := inv.add(component.Ecosystem, component.Name, component.Version, false); ref != "" {
+		return []string{ref}
+	}
+	return nil
+}
+
+// CycloneDXFormatter implements CycloneDX 1.5 JSON SBOM output
+type CycloneDXFormatter struct{}
+
+func (f *CycloneDXFormatter) Format(output *ScanOutput) (string, error) {
+	inv := &cycloneDXInventory{components: make(map[string]cycloneDXComponent), direct: make(map[string]bool)}
+
+	for _, result := range output.AuditResults {
+		for _, dep := range result.Dependencies {
+			inv.add(osv.NPM, dep.Name, dep.Version, dep.IsDirect)
+		}
+	}
+	for _, result := range output.PythonAuditResults {
+		for _, dep := range result.Dependencies {
+			inv.add(osv.PyPI, dep.Name, dep.Version, dep.IsDirect)
+		}
+	}
+	for _, result := range output.GoAuditResults {
+		for _, dep := range result.Dependencies {
+			inv.add(osv.Go, dep.Path, dep.Version, !dep.Indirect)
+		}
+	}
+	for _, result := range output.MavenAuditResults {
+		for _, dep := range result.Dependencies {
+			inv.add(osv.Maven, dep.GetMavenPackageName(), dep.Version, true)
+		}
+	}
+	for _, result := range output.SwiftAuditResults {
+		for _, dep := range result.Dependencies {
+			inv.add(osv.SwiftURL, dep.Name, dep.Version, dep.IsDirect)
+		}
+	}
+	for _, result := range output.CargoAuditResults {
+		for _, dep := range result.Dependencies {
+			inv.add(osv.CratesIO, dep.Name, dep.Version, dep.IsDirect)
+		}
+	}
+	for _, result := range output.RubyAuditResults {
+		for _, dep := range result.Dependencies {
+			inv.add(osv.RubyGems, dep.Name, dep.Version, dep.IsDirect)
+		}
+	}
+	for _, result := range output.ComposerAuditResults {
+		for _, dep := range result.Dependencies {
+			inv.add(osv.Packagist, dep.Name, dep.Version, dep.IsDirect)
+		}
+	}
+	for _, result := range output.CustomAuditResults {
+		for _, dep := range result.Dependencies {
+			inv.add(result.Ecosystem, dep.Name, dep.Version, dep.IsDirect)
+		}
+	}
+
+	// One entry per advisory, listing every component it affects
+	var vulnerabilities []cycloneDXVulnerability
+	byID := make(map[string]int)
+	for _, finding := range collectFindings(output) {
+		if finding.id == "" || finding.id == audit.UnpinnedAdvisoriesID || finding.purl == "" {
+			continue
+		}
+
+		index, ok := byID[finding.id]
+		if !ok {
+			index = len(vulnerabilities)
+			byID[finding.id] = index
+			source := cycloneDXSource{Name: "OSV", URL: osvHelpURI(finding.id)}
+			vuln := cycloneDXVulnerability{
+				BOMRef:      finding.id,
+				ID:          finding.id,
+				Source:      source,
+				Ratings:     []cycloneDXRating{{Source: source, Severity: cycloneDXSeverity(finding.severity)}},
+				Description: finding.description,
+				Affects:     make([]cycloneDXAffect, 0),
+			}
+			if len(finding.fixVersions) > 0 {
+				vuln.Recommendation = fmt.Sprintf("Upgrade to %s", strings.Join(finding.fixVersions, ", "))
+			}
+			vulnerabilities = append(vulnerabilities, vuln)
+		}
+
+		vuln := &vulnerabilities[index]
+		for _, ref := range inv.affected(finding.purl) {
+			if !containsAffect(vuln.Affects, ref) {
+				vuln.Affects = append(vuln.Affects, cycloneDXAffect{Ref: ref})
+			}
+		}
+	}
+
+	components := make([]cycloneDXComponent, 0, len(inv.components))
+	for _, component := range inv.components {
+		components = append(components, component)
+	}
+	sort.Slice(components, func(i, j int) bool { return components[i].BOMRef < components[j].BOMRef })
+
+	direct := make([]string, 0, len(inv.direct))
+	for ref := range inv.direct {
+		direct = append(direct, ref)
+	}
+	sort.Strings(direct)
+
+	toolName := output.Metadata.ToolName
+	if toolName == "" {
+		toolName = "snoop"
+	}
+	bom := cycloneDXBOM{
+		BOMFormat:   "CycloneDX",
+		SpecVersion: cycloneDXSpecVersion,
+		Version:     1,
+		Metadata: cycloneDXMetadata{
+			Tools: cycloneDXTools{Components: []cycloneDXComponent{
+				{Type: "application", Name: toolName, Version: output.Metadata.ToolVersion},
+			}},
+			Component: cycloneDXComponent{Type: "application", BOMRef: cycloneDXRootRef, Name: projectName(output.Metadata.Directory)},
+		},
+		Components:      components,
+		Dependencies:    []cycloneDXDependency{{Ref: cycloneDXRootRef, DependsOn: direct}},
+		Vulnerabilities: vulnerabilities,
+	}
+
+	// A normalized report has no timestamp, and gets no serial number either
+	// so that it stays byte-identical between runs
+	if !output.Metadata.Timestamp.IsZero() {
+		bom.Metadata.Timestamp = output.Metadata.Timestamp.UTC().Format(time.RFC3339)
+		bom.SerialNumber = "urn:uuid:" + newUUID()
+	}
+
+	data, err := json.MarshalIndent(bom, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal CycloneDX: %w", err)
+	}
+
+	return string(data), nil
+}
+
+// containsAffect reports whether affects already references ref
+func containsAffect(affects []cycloneDXAffect, ref string) bool {
+	for _, affect := range affects {
+		if affect.Ref == ref {
+			return true
+		}
+	}
+	return false
+}
+
+// projectName names the scanned project after its directory
+func projectName(dir string) string {
+	name := filepath.Base(filepath.Clean(dir))
+	if name == "." || name == string(filepath.Separator) {
+		return "project"
+	}
+	return name
+}
+
+// newUUID returns a random version 4 UUID
+func newUUID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,94 @@
+package formatter
+
+import (
+	"slices"
+	"strings"
+)
+
+// UniqueVulnerabilities is TotalVulns with each vulnerability counted once,
+// however many manifests report it. A finding repeats another when both have
+// the same ecosystem, package, version, and advisory ID, as when several
+// go.mod files in a monorepo require the same vulnerable module. TotalVulns
+// still counts every occurrence. The per-manifest results are only read.
+func UniqueVulnerabilities(output *ScanOutput) int {
+	unique := output.TotalVulns - repeatedFindings(output)
+	if unique < 0 {
+		return 0
+	}
+	return unique
+}
+
+// repeatedFindings counts the listed findings that repeat one already seen
+// in an earlier manifest
+func repeatedFindings(output *ScanOutput) int {
+	seen := make(map[string]bool)
+	repeated := 0
+	count := func(key ...string) {
+		k := strings.Join(key, "\x00")
+		if seen[k] {
+			repeated++
+		}
+		seen[k] = true
+	}
+
+	for _, result := range output.AuditResults {
+		for _, vuln := range result.Vulnerabilities {
+			// npm entries carry a range rather than a version, and may
+			// list several advisories
+			ids := vuln.AdvisoryIDs()
+			slices.Sort(ids)
+			count(EcosystemNpm, vuln.Name, vuln.Range, strings.Join(ids, ","))
+		}
+	}
+	for _, result := range output.PythonAuditResults {
+		for _, vuln := range result.Vulnerabilities {
+			count(EcosystemPython, strings.ToLower(vuln.Name), vuln.Version, vuln.ID)
+		}
+	}
+	for _, result := range output.GoAuditResults {
+		for _, vuln := range result.Vulnerabilities {
+			count(EcosystemGo, vuln.Module, vuln.Version, vuln.ID)
+		}
+	}
+	for _, result := range output.MavenAuditResults {
+		for _, vuln := range result.Vulnerabilities {
+			count(EcosystemMaven, vuln.GroupID+":"+vuln.ArtifactID, vuln.Version, vuln.ID)
+		}
+	}
+	for _, result := range output.SwiftAuditResults {
+		for _, vuln := range result.Vulnerabilities {
+			count(EcosystemSwift, vuln.Name, vuln.Version, vuln.ID)
+		}
+	}
+	for _, result := range output.CargoAuditResults {
+		for _, vuln := range result.Vulnerabilities {
+			count(EcosystemCargo, vuln.Name, vuln.Version, vuln.ID)
+		}
+	}
+	for _, result := range output.RubyAuditResults {
+		for _, vuln := range result.Vulnerabilities {
+			count(EcosystemRuby, vuln.Name, vuln.Version, vuln.ID)
+		}
+	}
+	for _, result := range output.ComposerAuditResults {
+		for _, vuln := range result.Vulnerabilities {
+			count(EcosystemPHP, vuln.Name, vuln.Version, vuln.ID)
+		}
+	}
+	for _, result := range output.RuntimeAuditResults {
+		for _, vuln := range result.Vulnerabilities {
+			count(EcosystemRuntime, vuln.Runtime, vuln.Version, vuln.ID)
+		}
+	}
+	for _, result := range output.SBOMAuditResults {
+		for _, vuln := range result.Vulnerabilities {
+			count(EcosystemSBOM, string(vuln.Ecosystem), vuln.Name, vuln.Version, vuln.ID)
+		}
+	}
+	for _, result := range output.CustomAuditResults {
+		for _, vuln := range result.Vulnerabilities {
+			count(EcosystemCustom, string(vuln.Ecosystem), vuln.Name, vuln.Version, vuln.ID)
+		}
+	}
+	return repeated
+}
@@ -0,0 +1,100 @@
+package formatter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// FindingChange is a finding that appeared or disappeared between two scans
+type FindingChange struct {
+	ID        string `json:"id"`
+	Severity  string `json:"severity"`
+	Ecosystem string `json:"ecosystem"`
+	Manifest  string `json:"manifest"`
+	Package   string `json:"package"`
+	Version   string `json:"version,omitempty"`
+}
+
+// String describes the change on one line
+func (c FindingChange) String() string {
+	pkg := c.Package
+	if c.Version != "" {
+		pkg += "@" + c.Version
+	}
+	return fmt.Sprintf("%s %s in %s (%s)", c.ID, strings.ToUpper(c.Severity), pkg, c.Manifest)
+}
+
+// FindingsDiff lists the findings added and resolved since a previous scan
+type FindingsDiff struct {
+	New      []FindingChange `json:"new"`
+	Resolved []FindingChange `json:"resolved"`
+}
+
+// Changed reports whether any finding was added or resolved
+func (d FindingsDiff) Changed() bool {
+	return len(d.New) > 0 || len(d.Resolved) > 0
+}
+
+// DiffFindings compares the findings of two scans of the same project. A
+// finding is the same across scans when its advisory, package, version, and
+// manifest match, so a dependency that newly matches an advisory, for example
+// after the vulnerability database is updated, counts as new. A nil previous
+// scan makes every current finding new.
+func DiffFindings(previous, current *ScanOutput) FindingsDiff {
+	before := findingChanges(previous)
+	after := findingChanges(current)
+
+	var diff FindingsDiff
+	for key, change := range after {
+		if _, ok := before[key]; !ok {
+			diff.New = append(diff.New, change)
+		}
+	}
+	for key, change := range before {
+		if _, ok := after[key]; !ok {
+			diff.Resolved = append(diff.Resolved, change)
+		}
+	}
+	sortFindingChanges(diff.New)
+	sortFindingChanges(diff.Resolved)
+	return diff
+}
+
+// findingChanges keys the findings of a scan by what identifies them across scans
+func findingChanges(output *ScanOutput) map[string]FindingChange {
+	changes := make(map[string]FindingChange)
+	if output == nil {
+		return changes
+	}
+
+	for _, finding := range collectFindings(output) {
+		key := strings.Join([]string{finding.ecosystem, finding.manifest, finding.pkg, finding.version, finding.id}, "\x00")
+		changes[key] = FindingChange{
+			ID:        finding.id,
+			Severity:  finding.severity,
+			Ecosystem: finding.ecosystem,
+			Manifest:  finding.manifest,
+			Package:   finding.pkg,
+			Version:   finding.version,
+		}
+	}
+	return changes
+}
+
+// sortFindingChanges orders changes by manifest, package, version, and advisory
+func sortFindingChanges(changes []FindingChange) {
+	sort.Slice(changes, func(i, j int) bool {
+		a, b := changes[i], changes[j]
+		if a.Manifest != b.Manifest {
+			return a.Manifest < b.Manifest
+		}
+		if a.Package != b.Package {
+			return a.Package < b.Package
+		}
+		if a.Version != b.Version {
+			return a.Version < b.Version
+		}
+		return a.ID < b.ID
+	})
+}
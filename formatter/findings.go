@@ -0,0 +1,278 @@
+package formatter
+
+import (
+	"path"
+	"slices"
+
+	"github.com/brandonapol/snoop/audit"
+	"github.com/brandonapol/snoop/osv"
+)
+
+// finding is one advisory affecting one package, the unit that SARIF and
+// grep output report on
+type finding struct {
+	id          string
+	description string
+	helpURI     string
+	severity    string
+	ecosystem   string
+	manifest    string
+	pkg         string
+	version     string
+	purl        string // Without a version when the affected version isn't known
+	fixVersions []string
+	aliases     []string // Other IDs of the advisory, such as its CVE
+	dependency  string   // "direct" or "indirect", or empty when it doesn't apply
+}
+
+// dependencyKind describes whether a package is a direct dependency
+func dependencyKind(isDirect bool) string {
+	if isDirect {
+		return "direct"
+	}
+	return "indirect"
+}
+
+// osvHelpURI links to an advisory's page on osv.dev
+func osvHelpURI(id string) string {
+	if id == audit.UnpinnedAdvisoriesID {
+		return ""
+	}
+	return "https://osv.dev/vulnerability/" + id
+}
+
+// packageURL returns the package URL of a package, or "" when its ecosystem
+// has none
+func packageURL(ecosystem osv.Ecosystem, name, version string) string {
+	purl, _ := audit.PackageURL(ecosystem, name, version)
+	return purl
+}
+
+// collectFindings flattens every ecosystem's findings. npm entries that are
+// only vulnerable through a dependency have no advisory of their own and are
+// reported through that dependency instead.
+func collectFindings(output *ScanOutput) []finding {
+	var findings []finding
+
+	for _, result := range output.AuditResults {
+		for _, vuln := range result.Vulnerabilities {
+			for _, via := range vuln.Via {
+				finding := finding{
+					severity:   string(vuln.Severity),
+					ecosystem:  EcosystemNpm,
+					manifest:   result.PackageJSONPath,
+					pkg:        vuln.Name,
+					purl:       packageURL(osv.NPM, vuln.Name, ""),
+					dependency: dependencyKind(vuln.IsDirect),
+				}
+				// An upgrade of the vulnerable package itself is its fix version;
+				// one of a dependent package fixes it without naming a version of it
+				if fix := vuln.FixAvailable; fix.Upgrade() && fix.Name == vuln.Name {
+					finding.fixVersions = []string{fix.Version}
+				}
+				switch via := via.(type) {
+				case string:
+					// OSV-backed entries list advisory IDs; npm lists package names
+					if !slices.Contains(vuln.AdvisoryIDs(), via) {
+						continue
+					}
+					finding.id = via
+					finding.helpURI = osvHelpURI(via)
+				case map[string]any:
+					advisoryURL, _ := via["url"].(string)
+					finding.id = path.Base(advisoryURL)
+					if !slices.Contains(vuln.AdvisoryIDs(), finding.id) {
+						continue
+					}
+					finding.helpURI = advisoryURL
+					finding.description, _ = via["title"].(string)
+					if severity, ok := via["severity"].(string); ok {
+						finding.severity = severity
+					}
+				default:
+					continue
+				}
+				findings = append(findings, finding)
+			}
+		}
+	}
+	for _, result := range output.PythonAuditResults {
+		for _, vuln := range result.Vulnerabilities {
+			findings = append(findings, finding{
+				id:          vuln.ID,
+				description: vuln.Description,
+				helpURI:     osvHelpURI(vuln.ID),
+				severity:    vuln.Severity,
+				ecosystem:   EcosystemPython,
+				manifest:    result.ManifestPath,
+				pkg:         vuln.Name,
+				version:     vuln.Version,
+				purl:        packageURL(osv.PyPI, vuln.Name, vuln.Version),
+				fixVersions: vuln.FixVersions,
+				aliases:     vuln.Aliases,
+				dependency:  dependencyKind(vuln.IsDirect),
+			})
+		}
+	}
+	for _, result := range output.GoAuditResults {
+		for _, vuln := range result.Vulnerabilities {
+			findings = append(findings, finding{
+				id:          vuln.ID,
+				description: vuln.Description,
+				helpURI:     osvHelpURI(vuln.ID),
+				severity:    vuln.Severity,
+				ecosystem:   EcosystemGo,
+				manifest:    result.ManifestPath,
+				pkg:         vuln.Module,
+				version:     vuln.Version,
+				purl:        packageURL(osv.Go, vuln.Module, vuln.Version),
+				fixVersions: vuln.FixVersions,
+				aliases:     vuln.Aliases,
+				dependency:  dependencyKind(vuln.IsDirect),
+			})
+		}
+	}
+	for _, result := range output.MavenAuditResults {
+		for _, vuln := range result.Vulnerabilities {
+			findings = append(findings, finding{
+				id:          vuln.ID,
+				description: vuln.Description,
+				helpURI:     osvHelpURI(vuln.ID),
+				severity:    vuln.Severity,
+				ecosystem:   EcosystemMaven,
+				manifest:    result.ManifestPath,
+				pkg:         vuln.ArtifactName(),
+				version:     vuln.Version,
+				purl:        packageURL(osv.Maven, vuln.GroupID+":"+vuln.ArtifactID, vuln.Version),
+				fixVersions: vuln.FixVersions,
+				aliases:     vuln.Aliases,
+				dependency:  dependencyKind(vuln.IsDirect),
+			})
+		}
+	}
+	for _, result := range output.SwiftAuditResults {
+		for _, vuln := range result.Vulnerabilities {
+			findings = append(findings, finding{
+				id:          vuln.ID,
+				description: vuln.Description,
+				helpURI:     osvHelpURI(vuln.ID),
+				severity:    vuln.Severity,
+				ecosystem:   EcosystemSwift,
+				manifest:    result.ManifestPath,
+				pkg:         vuln.Name,
+				version:     vuln.Version,
+				purl:        packageURL(osv.SwiftURL, vuln.Name, vuln.Version),
+				fixVersions: vuln.FixVersions,
+				aliases:     vuln.Aliases,
+				dependency:  dependencyKind(vuln.IsDirect),
+			})
+		}
+	}
+	for _, result := range output.CargoAuditResults {
+		for _, vuln := range result.Vulnerabilities {
+			findings = append(findings, finding{
+				id:          vuln.ID,
+				description: vuln.Description,
+				helpURI:     osvHelpURI(vuln.ID),
+				severity:    vuln.Severity,
+				ecosystem:   EcosystemCargo,
+				manifest:    result.ManifestPath,
+				pkg:         vuln.Name,
+				version:     vuln.Version,
+				purl:        packageURL(osv.CratesIO, vuln.Name, vuln.Version),
+				fixVersions: vuln.FixVersions,
+				aliases:     vuln.Aliases,
+				dependency:  dependencyKind(vuln.IsDirect),
+			})
+		}
+	}
+	for _, result := range output.RubyAuditResults {
+		for _, vuln := range result.Vulnerabilities {
+			findings = append(findings, finding{
+				id:          vuln.ID,
+				description: vuln.Description,
+				helpURI:     osvHelpURI(vuln.ID),
+				severity:    vuln.Severity,
+				ecosystem:   EcosystemRuby,
+				manifest:    result.ManifestPath,
+				pkg:         vuln.Name,
+				version:     vuln.Version,
+				purl:        packageURL(osv.RubyGems, vuln.Name, vuln.Version),
+				fixVersions: vuln.FixVersions,
+				aliases:     vuln.Aliases,
+				dependency:  dependencyKind(vuln.IsDirect),
+			})
+		}
+	}
+	for _, result := range output.ComposerAuditResults {
+		for _, vuln := range result.Vulnerabilities {
+			findings = append(findings, finding{
+				id:          vuln.ID,
+				description: vuln.Description,
+				helpURI:     osvHelpURI(vuln.ID),
+				severity:    vuln.Severity,
+				ecosystem:   EcosystemPHP,
+				manifest:    result.ManifestPath,
+				pkg:         vuln.Name,
+				version:     vuln.Version,
+				purl:        packageURL(osv.Packagist, vuln.Name, vuln.Version),
+				fixVersions: vuln.FixVersions,
+				aliases:     vuln.Aliases,
+				dependency:  dependencyKind(vuln.IsDirect),
+			})
+		}
+	}
+	for _, result := range output.RuntimeAuditResults {
+		for _, vuln := range result.Vulnerabilities {
+			findings = append(findings, finding{
+				id:          vuln.ID,
+				description: vuln.Description,
+				helpURI:     osvHelpURI(vuln.ID),
+				severity:    vuln.Severity,
+				ecosystem:   EcosystemRuntime,
+				manifest:    result.ManifestPath,
+				pkg:         vuln.Runtime,
+				version:     vuln.Version,
+				fixVersions: vuln.FixVersions,
+				aliases:     vuln.Aliases,
+			})
+		}
+	}
+	for _, result := range output.SBOMAuditResults {
+		for _, vuln := range result.Vulnerabilities {
+			findings = append(findings, finding{
+				id:          vuln.ID,
+				description: vuln.Description,
+				helpURI:     osvHelpURI(vuln.ID),
+				severity:    vuln.Severity,
+				ecosystem:   EcosystemSBOM,
+				manifest:    result.SBOMPath,
+				pkg:         vuln.Name,
+				version:     vuln.Version,
+				purl:        vuln.PURL,
+				fixVersions: vuln.FixVersions,
+				aliases:     vuln.Aliases,
+			})
+		}
+	}
+	for _, result := range output.CustomAuditResults {
+		for _, vuln := range result.Vulnerabilities {
+			findings = append(findings, finding{
+				id:          vuln.ID,
+				description: vuln.Description,
+				helpURI:     osvHelpURI(vuln.ID),
+				severity:    vuln.Severity,
+				ecosystem:   EcosystemCustom,
+				manifest:    result.ManifestPath,
+				pkg:         vuln.Name,
+				version:     vuln.Version,
+				purl:        packageURL(result.Ecosystem, vuln.Name, vuln.Version),
+				fixVersions: vuln.FixVersions,
+				aliases:     vuln.Aliases,
+				dependency:  dependencyKind(vuln.IsDirect),
+			})
+		}
+	}
+
+	return findings
+}
@@ -0,0 +1,15 @@
+package formatter
+
+import "github.com/brandonapol/snoop/audit"
+
+// OnlyFixable hides findings that have no fix available, recomputing every
+// summary and the total, and records how many findings were hidden
+func OnlyFixable(output *ScanOutput) {
+	hidden := 0
+	for _, result := range auditResults(output) {
+		hidden += audit.FilterFixable(result)
+	}
+
+	output.HiddenUnfixable = hidden
+	output.TotalVulns = AggregateSummary(output).Total
+}
@@ -0,0 +1,42 @@
+package formatter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/brandonapol/snoop/audit"
+)
+
+// fixKindLabels describes each kind of fix for the table and markdown output
+var fixKindLabels = map[audit.FixKind]string{
+	audit.FixUpgradeDirect:  "upgrade",
+	audit.FixUpdateLockfile: "update lockfile",
+	audit.FixOverride:       "override (no direct upgrade available)",
+	audit.FixNone:           "no fix",
+}
+
+// writeTableFixes writes the fix recommendations for one manifest
+func writeTableFixes(builder *strings.Builder, recommendations []audit.FixRecommendation) {
+	if len(recommendations) == 0 {
+		return
+	}
+
+	builder.WriteString("Fix recommendations:\n")
+	for _, rec := range recommendations {
+		builder.WriteString(fmt.Sprintf("  %s [%s]: %s\n", rec.Package, fixKindLabels[rec.Kind], rec.Directive))
+	}
+	builder.WriteString("\n")
+}
+
+// writeMarkdownFixes writes the fix recommendations for one manifest
+func writeMarkdownFixes(builder *strings.Builder, recommendations []audit.FixRecommendation) {
+	if len(recommendations) == 0 {
+		return
+	}
+
+	builder.WriteString("**Fix Recommendations:**\n\n")
+	for _, rec := range recommendations {
+		builder.WriteString(fmt.Sprintf("- `%s` (%s): `%s`\n", rec.Package, fixKindLabels[rec.Kind], rec.Directive))
+	}
+	builder.WriteString("\n")
+}
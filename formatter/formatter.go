@@ -3,37 +3,62 @@ package formatter
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/brandonapol/snoop/audit"
 	"github.com/brandonapol/snoop/scanner"
+	"github.com/brandonapol/snoop/security"
 )
 
 // OutputFormat represents the type of output format
 type OutputFormat string
 
 const (
-	FormatJSON     OutputFormat = "json"
-	FormatTable    OutputFormat = "table"
-	FormatMarkdown OutputFormat = "markdown"
+	FormatJSON      OutputFormat = "json"
+	FormatTable     OutputFormat = "table"
+	FormatMarkdown  OutputFormat = "markdown"
+	FormatSARIF     OutputFormat = "sarif"
+	FormatGrep      OutputFormat = "grep"
+	FormatCycloneDX OutputFormat = "cyclonedx"
+	FormatJUnit     OutputFormat = "junit"
+	FormatCSV       OutputFormat = "csv"
+	FormatHTML      OutputFormat = "html"
+	FormatGitLab    OutputFormat = "gitlab"
 )
 
 // ScanOutput contains all the data to be formatted
 type ScanOutput struct {
-	Metadata           OutputMetadata
-	ScanResults        *scanner.ScanResult
-	AuditResults       []*audit.AuditResult
-	PythonAuditResults []*audit.PythonAuditResult
-	GoAuditResults     []*audit.GoAuditResult
-	MavenAuditResults  []*audit.MavenAuditResult
-	TotalVulns         int
-	HasErrors          bool
+	Metadata             OutputMetadata
+	ScanResults          *scanner.ScanResult
+	AuditResults         []*audit.AuditResult
+	PythonAuditResults   []*audit.PythonAuditResult
+	GoAuditResults       []*audit.GoAuditResult
+	MavenAuditResults    []*audit.MavenAuditResult
+	SwiftAuditResults    []*audit.SwiftAuditResult
+	CargoAuditResults    []*audit.CargoAuditResult
+	RubyAuditResults     []*audit.RubyAuditResult
+	ComposerAuditResults []*audit.ComposerAuditResult
+	RuntimeAuditResults  []*audit.RuntimeAuditResult
+	SBOMAuditResults     []*audit.SBOMAuditResult
+	CustomAuditResults   []*audit.CustomAuditResult
+	SecurityResults      []*security.ManifestReport // Supply chain checks of package.json dependencies
+	ShowFixes            bool                       // Include fix recommendations
+	ShowCrossEcosystem   bool                       // Include advisories that affect several ecosystems
+	ShowSeverityMatrix   bool                       // Open the markdown report with a severity-by-ecosystem matrix
+	SummaryOnly          bool                       // Report only the overall counts, without the per-manifest results
+	Backends             []ScanBackend              // Vulnerability data sources used, for the scan manifest
+	Flags                map[string]string          // Flags in effect, for the scan manifest
+	TotalVulns           int                        // Every occurrence, so a vulnerability in two manifests counts twice; see UniqueVulnerabilities
+	HiddenUnfixable      int                        // Findings hidden by OnlyFixable
+	Suppressed           int                        // Findings dropped by Suppress
+	HasErrors            bool
 }
 
 // OutputMetadata contains metadata about the scan
 type OutputMetadata struct {
-	Timestamp   time.Time `json:"timestamp"`
+	Timestamp   time.Time `json:"timestamp,omitzero"`
 	Directory   string    `json:"directory"`
 	ToolName    string    `json:"toolName"`
 	ToolVersion string    `json:"toolVersion"`
@@ -41,52 +66,181 @@ type OutputMetadata struct {
 
 // JSONOutput represents the complete JSON output structure
 type JSONOutput struct {
-	Metadata       OutputMetadata             `json:"metadata"`
-	ManifestsFound int                        `json:"manifestsFound"`
-	ManifestFiles  []scanner.DetectedFile     `json:"manifestFiles"`
-	Audits         []JSONAuditResult          `json:"audits"`
-	PythonAudits   []JSONPythonAuditResult    `json:"pythonAudits,omitempty"`
-	GoAudits       []JSONGoAuditResult        `json:"goAudits,omitempty"`
-	MavenAudits    []JSONMavenAuditResult     `json:"mavenAudits,omitempty"`
-	TotalVulns     int                        `json:"totalVulnerabilities"`
-	Summary        audit.VulnerabilitySummary `json:"summary"`
+	Root                 string                                `json:"root,omitempty"` // Set in JSON Lines output
+	Metadata             OutputMetadata                        `json:"metadata"`
+	ManifestsFound       int                                   `json:"manifestsFound"`
+	ManifestsByEcosystem map[string]int                        `json:"manifestsByEcosystem"` // Keyed by the Ecosystem* constants
+	ManifestFiles        []scanner.DetectedFile                `json:"manifestFiles"`
+	Audits               []JSONAuditResult                     `json:"audits"`
+	PythonAudits         []JSONPythonAuditResult               `json:"pythonAudits,omitempty"`
+	GoAudits             []JSONGoAuditResult                   `json:"goAudits,omitempty"`
+	MavenAudits          []JSONMavenAuditResult                `json:"mavenAudits,omitempty"`
+	SwiftAudits          []JSONSwiftAuditResult                `json:"swiftAudits,omitempty"`
+	CargoAudits          []JSONCargoAuditResult                `json:"cargoAudits,omitempty"`
+	RubyAudits           []JSONRubyAuditResult                 `json:"rubyAudits,omitempty"`
+	ComposerAudits       []JSONComposerAuditResult             `json:"composerAudits,omitempty"`
+	RuntimeAudits        []JSONRuntimeAuditResult              `json:"runtimeAudits,omitempty"`
+	SBOMAudits           []JSONSBOMAuditResult                 `json:"sbomAudits,omitempty"`
+	CustomAudits         []JSONCustomAuditResult               `json:"customAudits,omitempty"`
+	SupplyChain          []JSONSecurityResult                  `json:"supplyChain,omitempty"`
+	PackagesScanned      int                                   `json:"dependenciesScanned"` // Across every manifest
+	TotalVulns           int                                   `json:"totalVulnerabilities"`
+	UniqueVulns          int                                   `json:"uniqueVulnerabilities"` // TotalVulns with repeats across manifests counted once
+	HiddenUnfixable      int                                   `json:"hiddenUnfixable,omitempty"`
+	Suppressed           int                                   `json:"suppressed,omitempty"`
+	Unverified           []string                              `json:"unverifiedEcosystems,omitempty"` // Ecosystems whose backend was unavailable
+	CrossEcosystem       []CrossEcosystemAdvisory              `json:"crossEcosystemAdvisories,omitempty"`
+	Summary              audit.VulnerabilitySummary            `json:"summary"`
+	SummaryByEcosystem   map[string]audit.VulnerabilitySummary `json:"summaryByEcosystem"`  // Keyed by the Ecosystem* constants
+	Upgrades             audit.UpgradeSummary                  `json:"upgrades"`            // Fixable findings by breaking vs non-breaking upgrade
+	UpgradesByEcosystem  map[string]audit.UpgradeSummary       `json:"upgradesByEcosystem"` // Keyed by the Ecosystem* constants
+	Errors               []ReportIssue                         `json:"errors"`
+	ScanManifest         ScanManifest                          `json:"scanManifest"`
 }
 
 // JSONAuditResult represents audit results for a single package.json
 type JSONAuditResult struct {
-	PackageJSON     string                     `json:"packageJson"`
-	Vulnerabilities []audit.Vulnerability      `json:"vulnerabilities"`
-	Summary         audit.VulnerabilitySummary `json:"summary"`
-	Error           string                     `json:"error,omitempty"`
+	PackageJSON        string                     `json:"packageJson"`
+	Vulnerabilities    []audit.Vulnerability      `json:"vulnerabilities"`
+	Summary            audit.VulnerabilitySummary `json:"summary"`
+	PackagesScanned    int                        `json:"dependenciesScanned"`        // Zero when nothing was checked, as opposed to a clean result
+	DependencyCounts   *audit.DependencyMetadata  `json:"dependencyCounts,omitempty"` // npm audit's counts by dependency kind
+	FixRecommendations []audit.FixRecommendation  `json:"fixRecommendations,omitempty"`
+	Withdrawn          int                        `json:"withdrawnSkipped,omitempty"` // Advisories OSV has withdrawn, not reported
+	Unverified         bool                       `json:"unverified,omitempty"`
+	IncompleteLockfile bool                       `json:"incompleteLockfile,omitempty"`
+	Workspaces         []string                   `json:"workspaces,omitempty"` // Workspace members audited with this root
+	Error              string                     `json:"error,omitempty"`
 }
 
 // JSONPythonAuditResult represents audit results for a single Python manifest
 type JSONPythonAuditResult struct {
-	ManifestPath    string                      `json:"manifestPath"`
-	ManifestType    string                      `json:"manifestType"`
-	Vulnerabilities []audit.PythonVulnerability `json:"vulnerabilities"`
-	Summary         audit.VulnerabilitySummary  `json:"summary"`
-	Error           string                      `json:"error,omitempty"`
+	ManifestPath    string                       `json:"manifestPath"`
+	ManifestType    string                       `json:"manifestType"`
+	Vulnerabilities []audit.PythonVulnerability  `json:"vulnerabilities"`
+	Summary         audit.VulnerabilitySummary   `json:"summary"`
+	PackagesScanned int                          `json:"dependenciesScanned"`
+	Notes           []audit.SecurityNote         `json:"notes,omitempty"`
+	Typosquatting   []security.TyposquattingRisk `json:"typosquatting,omitempty"`
+	Withdrawn       int                          `json:"withdrawnSkipped,omitempty"` // Advisories OSV has withdrawn, not reported
+	Unverified      bool                         `json:"unverified,omitempty"`
+	Error           string                       `json:"error,omitempty"`
 }
 
 // JSONGoAuditResult represents audit results for a single Go manifest
 type JSONGoAuditResult struct {
+	ManifestPath       string                     `json:"manifestPath"`
+	ManifestType       string                     `json:"manifestType"`
+	Vulnerabilities    []audit.GoVulnerability    `json:"vulnerabilities"`
+	Summary            audit.VulnerabilitySummary `json:"summary"`
+	PackagesScanned    int                        `json:"dependenciesScanned"`
+	FixRecommendations []audit.FixRecommendation  `json:"fixRecommendations,omitempty"`
+	Manifests          []string                   `json:"manifests,omitempty"`        // Set when go.mod files are audited together
+	Vendored           []string                   `json:"vendored,omitempty"`         // vendor/modules.txt files that supplied the audited versions
+	IndirectSkipped    int                        `json:"indirectSkipped,omitempty"`  // Indirect requirements not audited without --include-indirect
+	Withdrawn          int                        `json:"withdrawnSkipped,omitempty"` // Advisories OSV has withdrawn, not reported
+	Unverified         bool                       `json:"unverified,omitempty"`
+	Error              string                     `json:"error,omitempty"`
+}
+
+// JSONMavenAuditResult represents audit results for a single Maven manifest
+type JSONMavenAuditResult struct {
 	ManifestPath    string                     `json:"manifestPath"`
 	ManifestType    string                     `json:"manifestType"`
-	Vulnerabilities []audit.GoVulnerability    `json:"vulnerabilities"`
+	Vulnerabilities []audit.MavenVulnerability `json:"vulnerabilities"`
 	Summary         audit.VulnerabilitySummary `json:"summary"`
+	PackagesScanned int                        `json:"dependenciesScanned"`
+	Unverified      bool                       `json:"unverified,omitempty"`
+	Unresolved      int                        `json:"unresolvedVersions,omitempty"` // Dependencies not audited because their version comes from a parent or BOM
+	Withdrawn       int                        `json:"withdrawnSkipped,omitempty"`   // Advisories OSV has withdrawn, not reported
 	Error           string                     `json:"error,omitempty"`
 }
 
-// JSONMavenAuditResult represents audit results for a single Maven manifest
-type JSONMavenAuditResult struct {
+// JSONSwiftAuditResult represents audit results for a single Swift manifest
+type JSONSwiftAuditResult struct {
 	ManifestPath    string                     `json:"manifestPath"`
 	ManifestType    string                     `json:"manifestType"`
-	Vulnerabilities []audit.MavenVulnerability `json:"vulnerabilities"`
+	Vulnerabilities []audit.SwiftVulnerability `json:"vulnerabilities"`
+	Summary         audit.VulnerabilitySummary `json:"summary"`
+	PackagesScanned int                        `json:"dependenciesScanned"`
+	Withdrawn       int                        `json:"withdrawnSkipped,omitempty"` // Advisories OSV has withdrawn, not reported
+	Unverified      bool                       `json:"unverified,omitempty"`
+	Error           string                     `json:"error,omitempty"`
+}
+
+// JSONCargoAuditResult represents audit results for a single Cargo manifest
+type JSONCargoAuditResult struct {
+	ManifestPath    string                     `json:"manifestPath"`
+	ManifestType    string                     `json:"manifestType"`
+	Vulnerabilities []audit.CargoVulnerability `json:"vulnerabilities"`
+	Summary         audit.VulnerabilitySummary `json:"summary"`
+	PackagesScanned int                        `json:"dependenciesScanned"`
+	Withdrawn       int                        `json:"withdrawnSkipped,omitempty"` // Advisories OSV has withdrawn, not reported
+	Unverified      bool                       `json:"unverified,omitempty"`
+	Error           string                     `json:"error,omitempty"`
+}
+
+// JSONRubyAuditResult represents audit results for a single Gemfile.lock
+type JSONRubyAuditResult struct {
+	ManifestPath    string                     `json:"manifestPath"`
+	ManifestType    string                     `json:"manifestType"`
+	Vulnerabilities []audit.RubyVulnerability  `json:"vulnerabilities"`
 	Summary         audit.VulnerabilitySummary `json:"summary"`
+	PackagesScanned int                        `json:"dependenciesScanned"`
+	Withdrawn       int                        `json:"withdrawnSkipped,omitempty"` // Advisories OSV has withdrawn, not reported
+	Unverified      bool                       `json:"unverified,omitempty"`
 	Error           string                     `json:"error,omitempty"`
 }
 
+// JSONComposerAuditResult represents audit results for a single composer.lock
+type JSONComposerAuditResult struct {
+	ManifestPath    string                        `json:"manifestPath"`
+	ManifestType    string                        `json:"manifestType"`
+	Vulnerabilities []audit.ComposerVulnerability `json:"vulnerabilities"`
+	Summary         audit.VulnerabilitySummary    `json:"summary"`
+	PackagesScanned int                           `json:"dependenciesScanned"`
+	Withdrawn       int                           `json:"withdrawnSkipped,omitempty"` // Advisories OSV has withdrawn, not reported
+	Unverified      bool                          `json:"unverified,omitempty"`
+	Error           string                        `json:"error,omitempty"`
+}
+
+// JSONRuntimeAuditResult represents audit results for a single runtime version declaration
+type JSONRuntimeAuditResult struct {
+	ManifestPath    string                       `json:"manifestPath"`
+	ManifestType    string                       `json:"manifestType"`
+	Runtimes        []audit.RuntimeVersion       `json:"runtimes"`
+	Vulnerabilities []audit.RuntimeVulnerability `json:"vulnerabilities"`
+	Summary         audit.VulnerabilitySummary   `json:"summary"`
+	Withdrawn       int                          `json:"withdrawnSkipped,omitempty"` // Advisories OSV has withdrawn, not reported
+	Unverified      bool                         `json:"unverified,omitempty"`
+	Error           string                       `json:"error,omitempty"`
+}
+
+// JSONSBOMAuditResult represents audit results for a single SBOM
+type JSONSBOMAuditResult struct {
+	SBOMPath          string                     `json:"sbomPath"`
+	Format            string                     `json:"format"`
+	ComponentsScanned int                        `json:"componentsScanned"`
+	Vulnerabilities   []audit.SBOMVulnerability  `json:"vulnerabilities"`
+	Summary           audit.VulnerabilitySummary `json:"summary"`
+	Withdrawn         int                        `json:"withdrawnSkipped,omitempty"` // Advisories OSV has withdrawn, not reported
+	Unverified        bool                       `json:"unverified,omitempty"`
+	Error             string                     `json:"error,omitempty"`
+}
+
+// JSONCustomAuditResult represents audit results for a single manifest read by a registered parser
+type JSONCustomAuditResult struct {
+	ManifestPath    string                      `json:"manifestPath"`
+	ManifestType    string                      `json:"manifestType"`
+	Ecosystem       string                      `json:"ecosystem"`
+	Vulnerabilities []audit.CustomVulnerability `json:"vulnerabilities"`
+	Summary         audit.VulnerabilitySummary  `json:"summary"`
+	PackagesScanned int                         `json:"dependenciesScanned"`
+	Withdrawn       int                         `json:"withdrawnSkipped,omitempty"` // Advisories OSV has withdrawn, not reported
+	Unverified      bool                        `json:"unverified,omitempty"`
+	Error           string                      `json:"error,omitempty"`
+}
+
 // Formatter interface for different output formatters
 type Formatter interface {
 	Format(output *ScanOutput) (string, error)
@@ -101,21 +255,299 @@ func GetFormatter(format OutputFormat) Formatter {
 		return &TableFormatter{}
 	case FormatMarkdown:
 		return &MarkdownFormatter{}
+	case FormatSARIF:
+		return &SARIFFormatter{}
+	case FormatGrep:
+		return &GrepFormatter{}
+	case FormatCycloneDX:
+		return &CycloneDXFormatter{}
+	case FormatJUnit:
+		return &JUnitFormatter{}
+	case FormatCSV:
+		return &CSVFormatter{}
+	case FormatHTML:
+		return &HTMLFormatter{}
+	case FormatGitLab:
+		return &GitLabFormatter{}
 	default:
 		return &TableFormatter{}
 	}
 }
 
+// Ecosystem keys used in the JSON summaryByEcosystem map
+const (
+	EcosystemNpm     = "npm"
+	EcosystemPython  = "python"
+	EcosystemGo      = "go"
+	EcosystemMaven   = "maven"
+	EcosystemSwift   = "swift"
+	EcosystemCargo   = "cargo"
+	EcosystemRuby    = "ruby"
+	EcosystemPHP     = "php"
+	EcosystemRuntime = "runtime"
+	EcosystemSBOM    = "sbom"
+	EcosystemCustom  = "custom"
+)
+
+// manifestsByEcosystem counts the detected manifests of each ecosystem.
+// Runtime version files count as runtime, and manifest types registered by
+// library users as custom, so the counts add up to every file detected.
+func manifestsByEcosystem(files []scanner.DetectedFile) map[string]int {
+	counts := make(map[string]int)
+	for _, file := range files {
+		switch {
+		case scanner.IsNodeJSManifest(file.Type):
+			counts[EcosystemNpm]++
+		case scanner.IsPythonManifest(file.Type):
+			counts[EcosystemPython]++
+		case scanner.IsGoManifest(file.Type):
+			counts[EcosystemGo]++
+		case scanner.IsMavenManifest(file.Type):
+			counts[EcosystemMaven]++
+		case scanner.IsSwiftManifest(file.Type):
+			counts[EcosystemSwift]++
+		case scanner.IsCargoManifest(file.Type):
+			counts[EcosystemCargo]++
+		case scanner.IsRubyManifest(file.Type):
+			counts[EcosystemRuby]++
+		case scanner.IsComposerManifest(file.Type):
+			counts[EcosystemPHP]++
+		case scanner.IsRuntimeManifest(file.Type):
+			counts[EcosystemRuntime]++
+		default:
+			counts[EcosystemCustom]++
+		}
+	}
+	return counts
+}
+
+// summaryByEcosystem sums the per-manifest summaries of every ecosystem that
+// has audit results
+func summaryByEcosystem(output *ScanOutput) map[string]audit.VulnerabilitySummary {
+	summaries := make(map[string]audit.VulnerabilitySummary)
+	add := func(ecosystem string, summary audit.VulnerabilitySummary) {
+		total := summaries[ecosystem]
+		total.Add(summary)
+		summaries[ecosystem] = total
+	}
+
+	for _, result := range output.AuditResults {
+		add(EcosystemNpm, result.Summary)
+	}
+	for _, result := range output.PythonAuditResults {
+		add(EcosystemPython, result.Summary)
+	}
+	for _, result := range output.GoAuditResults {
+		add(EcosystemGo, result.Summary)
+	}
+	for _, result := range output.MavenAuditResults {
+		add(EcosystemMaven, result.Summary)
+	}
+	for _, result := range output.SwiftAuditResults {
+		add(EcosystemSwift, result.Summary)
+	}
+	for _, result := range output.CargoAuditResults {
+		add(EcosystemCargo, result.Summary)
+	}
+	for _, result := range output.RubyAuditResults {
+		add(EcosystemRuby, result.Summary)
+	}
+	for _, result := range output.ComposerAuditResults {
+		add(EcosystemPHP, result.Summary)
+	}
+	for _, result := range output.RuntimeAuditResults {
+		add(EcosystemRuntime, result.Summary)
+	}
+	for _, result := range output.SBOMAuditResults {
+		add(EcosystemSBOM, result.Summary)
+	}
+	for _, result := range output.CustomAuditResults {
+		add(EcosystemCustom, result.Summary)
+	}
+	return summaries
+}
+
+// auditResults lists the audit results of every ecosystem, for the filters
+// that drop findings from each
+func auditResults(output *ScanOutput) []audit.Result {
+	var results []audit.Result
+	for _, result := range output.AuditResults {
+		results = append(results, result)
+	}
+	for _, result := range output.PythonAuditResults {
+		results = append(results, result)
+	}
+	for _, result := range output.GoAuditResults {
+		results = append(results, result)
+	}
+	for _, result := range output.MavenAuditResults {
+		results = append(results, result)
+	}
+	for _, result := range output.SwiftAuditResults {
+		results = append(results, result)
+	}
+	for _, result := range output.CargoAuditResults {
+		results = append(results, result)
+	}
+	for _, result := range output.RubyAuditResults {
+		results = append(results, result)
+	}
+	for _, result := range output.ComposerAuditResults {
+		results = append(results, result)
+	}
+	for _, result := range output.RuntimeAuditResults {
+		results = append(results, result)
+	}
+	for _, result := range output.SBOMAuditResults {
+		results = append(results, result)
+	}
+	for _, result := range output.CustomAuditResults {
+		results = append(results, result)
+	}
+	return results
+}
+
+// UnverifiedEcosystems lists the ecosystems, keyed by the Ecosystem* constants,
+// with at least one manifest whose OSV queries all failed
+func UnverifiedEcosystems(output *ScanOutput) []string {
+	seen := make(map[string]bool)
+	var ecosystems []string
+	add := func(ecosystem string, unverified bool) {
+		if unverified && !seen[ecosystem] {
+			seen[ecosystem] = true
+			ecosystems = append(ecosystems, ecosystem)
+		}
+	}
+
+	for _, result := range output.AuditResults {
+		add(EcosystemNpm, result.Unverified)
+	}
+	for _, result := range output.PythonAuditResults {
+		add(EcosystemPython, result.Unverified)
+	}
+	for _, result := range output.GoAuditResults {
+		add(EcosystemGo, result.Unverified)
+	}
+	for _, result := range output.MavenAuditResults {
+		add(EcosystemMaven, result.Unverified)
+	}
+	for _, result := range output.SwiftAuditResults {
+		add(EcosystemSwift, result.Unverified)
+	}
+	for _, result := range output.CargoAuditResults {
+		add(EcosystemCargo, result.Unverified)
+	}
+	for _, result := range output.RubyAuditResults {
+		add(EcosystemRuby, result.Unverified)
+	}
+	for _, result := range output.ComposerAuditResults {
+		add(EcosystemPHP, result.Unverified)
+	}
+	for _, result := range output.RuntimeAuditResults {
+		add(EcosystemRuntime, result.Unverified)
+	}
+	for _, result := range output.SBOMAuditResults {
+		add(EcosystemSBOM, result.Unverified)
+	}
+	for _, result := range output.CustomAuditResults {
+		add(EcosystemCustom, result.Unverified)
+	}
+	return ecosystems
+}
+
+// AggregateSummary combines the per-manifest summaries of every ecosystem
+func AggregateSummary(output *ScanOutput) audit.VulnerabilitySummary {
+	total := audit.VulnerabilitySummary{}
+	for _, summary := range summaryByEcosystem(output) {
+		total.Add(summary)
+	}
+	return total
+}
+
+// JSONSummaryOutput is a compact report carrying only the counts
+type JSONSummaryOutput struct {
+	Metadata           OutputMetadata                        `json:"metadata"`
+	ManifestsFound     int                                   `json:"manifestsFound"`
+	PackagesScanned    int                                   `json:"dependenciesScanned"`
+	TotalVulns         int                                   `json:"totalVulnerabilities"`
+	UniqueVulns        int                                   `json:"uniqueVulnerabilities"`
+	Summary            audit.VulnerabilitySummary            `json:"summary"`
+	SummaryByEcosystem map[string]audit.VulnerabilitySummary `json:"summaryByEcosystem"`
+	Upgrades           audit.UpgradeSummary                  `json:"upgrades"`
+	Unverified         []string                              `json:"unverifiedEcosystems,omitempty"`
+	Errors             int                                   `json:"errors"`
+	Warnings           int                                   `json:"warnings"`
+}
+
+// FormatSummaryJSON renders the compact summary of output as JSON
+func FormatSummaryJSON(output *ScanOutput) (string, error) {
+	errors, warnings := countIssues(collectIssues(output))
+	summary := JSONSummaryOutput{
+		Metadata:           output.Metadata,
+		ManifestsFound:     len(output.ScanResults.Files),
+		PackagesScanned:    dependenciesScanned(output),
+		TotalVulns:         output.TotalVulns,
+		UniqueVulns:        UniqueVulnerabilities(output),
+		Summary:            AggregateSummary(output),
+		SummaryByEcosystem: summaryByEcosystem(output),
+		Upgrades:           aggregateUpgrades(upgradesByEcosystem(output)),
+		Unverified:         UnverifiedEcosystems(output),
+		Errors:             errors,
+		Warnings:           warnings,
+	}
+
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return string(data), nil
+}
+
 // JSONFormatter implements JSON output
 type JSONFormatter struct{}
 
 func (f *JSONFormatter) Format(output *ScanOutput) (string, error) {
+	if output.SummaryOnly {
+		return FormatSummaryJSON(output)
+	}
+
+	data, err := json.MarshalIndent(buildJSONOutput(output), "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return string(data), nil
+}
+
+// FormatJSONLine renders output as a single line of JSON tagged with root, for
+// streaming many reports as JSON Lines. The root is passed separately because
+// normalization rewrites the metadata directory.
+func FormatJSONLine(output *ScanOutput, root string) (string, error) {
+	jsonOut := buildJSONOutput(output)
+	jsonOut.Root = root
+
+	data, err := json.Marshal(jsonOut)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return string(data), nil
+}
+
+// buildJSONOutput converts the scan output into the JSON report structure
+func buildJSONOutput(output *ScanOutput) JSONOutput {
 	jsonOut := JSONOutput{
-		Metadata:       output.Metadata,
-		ManifestsFound: len(output.ScanResults.Files),
-		ManifestFiles:  output.ScanResults.Files,
-		Audits:         make([]JSONAuditResult, 0),
-		TotalVulns:     output.TotalVulns,
+		Metadata:             output.Metadata,
+		ManifestsFound:       len(output.ScanResults.Files),
+		PackagesScanned:      dependenciesScanned(output),
+		ManifestsByEcosystem: manifestsByEcosystem(output.ScanResults.Files),
+		ManifestFiles:        output.ScanResults.Files,
+		Audits:               make([]JSONAuditResult, 0),
+		TotalVulns:           output.TotalVulns,
+		UniqueVulns:          UniqueVulnerabilities(output),
+		HiddenUnfixable:      output.HiddenUnfixable,
+		Suppressed:           output.Suppressed,
 	}
 
 	// Aggregate summary
@@ -123,9 +555,20 @@ func (f *JSONFormatter) Format(output *ScanOutput) (string, error) {
 
 	for _, auditResult := range output.AuditResults {
 		result := JSONAuditResult{
-			PackageJSON:     auditResult.PackageJSONPath,
-			Vulnerabilities: auditResult.Vulnerabilities,
-			Summary:         auditResult.Summary,
+			PackageJSON:        auditResult.PackageJSONPath,
+			Vulnerabilities:    auditResult.Vulnerabilities,
+			Summary:            auditResult.Summary,
+			PackagesScanned:    auditResult.PackagesScanned,
+			Withdrawn:          auditResult.WithdrawnSkipped,
+			Unverified:         auditResult.Unverified,
+			IncompleteLockfile: auditResult.IncompleteLockfile,
+			Workspaces:         auditResult.Workspaces,
+		}
+		if output.ShowFixes {
+			result.FixRecommendations = auditResult.Recommendations
+		}
+		if auditResult.Response != nil {
+			result.DependencyCounts = &auditResult.Response.Metadata.Dependencies
 		}
 		if auditResult.Error != nil {
 			result.Error = auditResult.Error.Error()
@@ -133,12 +576,7 @@ func (f *JSONFormatter) Format(output *ScanOutput) (string, error) {
 		jsonOut.Audits = append(jsonOut.Audits, result)
 
 		// Aggregate summary
-		totalSummary.Critical += auditResult.Summary.Critical
-		totalSummary.High += auditResult.Summary.High
-		totalSummary.Moderate += auditResult.Summary.Moderate
-		totalSummary.Low += auditResult.Summary.Low
-		totalSummary.Info += auditResult.Summary.Info
-		totalSummary.Total += auditResult.Summary.Total
+		totalSummary.Add(auditResult.Summary)
 	}
 
 	// Add Python audit results
@@ -149,6 +587,11 @@ func (f *JSONFormatter) Format(output *ScanOutput) (string, error) {
 			ManifestType:    pythonResult.ManifestType,
 			Vulnerabilities: pythonResult.Vulnerabilities,
 			Summary:         pythonResult.Summary,
+			PackagesScanned: pythonResult.PackagesScanned,
+			Unverified:      pythonResult.Unverified,
+			Notes:           pythonResult.Notes,
+			Typosquatting:   pythonResult.Typosquatting,
+			Withdrawn:       pythonResult.WithdrawnSkipped,
 		}
 		if pythonResult.Error != nil {
 			result.Error = pythonResult.Error.Error()
@@ -156,12 +599,7 @@ func (f *JSONFormatter) Format(output *ScanOutput) (string, error) {
 		jsonOut.PythonAudits = append(jsonOut.PythonAudits, result)
 
 		// Aggregate summary
-		totalSummary.Critical += pythonResult.Summary.Critical
-		totalSummary.High += pythonResult.Summary.High
-		totalSummary.Moderate += pythonResult.Summary.Moderate
-		totalSummary.Low += pythonResult.Summary.Low
-		totalSummary.Info += pythonResult.Summary.Info
-		totalSummary.Total += pythonResult.Summary.Total
+		totalSummary.Add(pythonResult.Summary)
 	}
 
 	// Add Go audit results
@@ -172,6 +610,15 @@ func (f *JSONFormatter) Format(output *ScanOutput) (string, error) {
 			ManifestType:    goResult.ManifestType,
 			Vulnerabilities: goResult.Vulnerabilities,
 			Summary:         goResult.Summary,
+			PackagesScanned: goResult.ModulesScanned,
+			Manifests:       goResult.Manifests,
+			Vendored:        goResult.Vendored,
+			IndirectSkipped: goResult.IndirectSkipped,
+			Withdrawn:       goResult.WithdrawnSkipped,
+			Unverified:      goResult.Unverified,
+		}
+		if output.ShowFixes {
+			result.FixRecommendations = goResult.Recommendations
 		}
 		if goResult.Error != nil {
 			result.Error = goResult.Error.Error()
@@ -179,12 +626,7 @@ func (f *JSONFormatter) Format(output *ScanOutput) (string, error) {
 		jsonOut.GoAudits = append(jsonOut.GoAudits, result)
 
 		// Aggregate summary
-		totalSummary.Critical += goResult.Summary.Critical
-		totalSummary.High += goResult.Summary.High
-		totalSummary.Moderate += goResult.Summary.Moderate
-		totalSummary.Low += goResult.Summary.Low
-		totalSummary.Info += goResult.Summary.Info
-		totalSummary.Total += goResult.Summary.Total
+		totalSummary.Add(goResult.Summary)
 	}
 
 	// Add Maven audit results
@@ -195,6 +637,10 @@ func (f *JSONFormatter) Format(output *ScanOutput) (string, error) {
 			ManifestType:    mavenResult.ManifestType,
 			Vulnerabilities: mavenResult.Vulnerabilities,
 			Summary:         mavenResult.Summary,
+			PackagesScanned: mavenResult.PackagesScanned,
+			Unverified:      mavenResult.Unverified,
+			Unresolved:      len(mavenResult.Unresolved),
+			Withdrawn:       mavenResult.WithdrawnSkipped,
 		}
 		if mavenResult.Error != nil {
 			result.Error = mavenResult.Error.Error()
@@ -202,22 +648,185 @@ func (f *JSONFormatter) Format(output *ScanOutput) (string, error) {
 		jsonOut.MavenAudits = append(jsonOut.MavenAudits, result)
 
 		// Aggregate summary
-		totalSummary.Critical += mavenResult.Summary.Critical
-		totalSummary.High += mavenResult.Summary.High
-		totalSummary.Moderate += mavenResult.Summary.Moderate
-		totalSummary.Low += mavenResult.Summary.Low
-		totalSummary.Info += mavenResult.Summary.Info
-		totalSummary.Total += mavenResult.Summary.Total
+		totalSummary.Add(mavenResult.Summary)
 	}
 
-	jsonOut.Summary = totalSummary
+	// Add Swift audit results
+	jsonOut.SwiftAudits = make([]JSONSwiftAuditResult, 0)
+	for _, swiftResult := range output.SwiftAuditResults {
+		result := JSONSwiftAuditResult{
+			ManifestPath:    swiftResult.ManifestPath,
+			ManifestType:    swiftResult.ManifestType,
+			Vulnerabilities: swiftResult.Vulnerabilities,
+			Summary:         swiftResult.Summary,
+			PackagesScanned: swiftResult.PackagesScanned,
+			Withdrawn:       swiftResult.WithdrawnSkipped,
+			Unverified:      swiftResult.Unverified,
+		}
+		if swiftResult.Error != nil {
+			result.Error = swiftResult.Error.Error()
+		}
+		jsonOut.SwiftAudits = append(jsonOut.SwiftAudits, result)
 
-	data, err := json.MarshalIndent(jsonOut, "", "  ")
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal JSON: %w", err)
+		// Aggregate summary
+		totalSummary.Add(swiftResult.Summary)
 	}
 
-	return string(data), nil
+	// Add Cargo audit results
+	jsonOut.CargoAudits = make([]JSONCargoAuditResult, 0)
+	for _, cargoResult := range output.CargoAuditResults {
+		result := JSONCargoAuditResult{
+			ManifestPath:    cargoResult.ManifestPath,
+			ManifestType:    cargoResult.ManifestType,
+			Vulnerabilities: cargoResult.Vulnerabilities,
+			Summary:         cargoResult.Summary,
+			PackagesScanned: cargoResult.PackagesScanned,
+			Withdrawn:       cargoResult.WithdrawnSkipped,
+			Unverified:      cargoResult.Unverified,
+		}
+		if cargoResult.Error != nil {
+			result.Error = cargoResult.Error.Error()
+		}
+		jsonOut.CargoAudits = append(jsonOut.CargoAudits, result)
+
+		// Aggregate summary
+		totalSummary.Add(cargoResult.Summary)
+	}
+
+	// Add Ruby audit results
+	jsonOut.RubyAudits = make([]JSONRubyAuditResult, 0)
+	for _, rubyResult := range output.RubyAuditResults {
+		result := JSONRubyAuditResult{
+			ManifestPath:    rubyResult.ManifestPath,
+			ManifestType:    rubyResult.ManifestType,
+			Vulnerabilities: rubyResult.Vulnerabilities,
+			Summary:         rubyResult.Summary,
+			PackagesScanned: rubyResult.PackagesScanned,
+			Withdrawn:       rubyResult.WithdrawnSkipped,
+			Unverified:      rubyResult.Unverified,
+		}
+		if rubyResult.Error != nil {
+			result.Error = rubyResult.Error.Error()
+		}
+		jsonOut.RubyAudits = append(jsonOut.RubyAudits, result)
+
+		// Aggregate summary
+		totalSummary.Add(rubyResult.Summary)
+	}
+
+	// Add Composer audit results
+	jsonOut.ComposerAudits = make([]JSONComposerAuditResult, 0)
+	for _, composerResult := range output.ComposerAuditResults {
+		result := JSONComposerAuditResult{
+			ManifestPath:    composerResult.ManifestPath,
+			ManifestType:    composerResult.ManifestType,
+			Vulnerabilities: composerResult.Vulnerabilities,
+			Summary:         composerResult.Summary,
+			PackagesScanned: composerResult.PackagesScanned,
+			Withdrawn:       composerResult.WithdrawnSkipped,
+			Unverified:      composerResult.Unverified,
+		}
+		if composerResult.Error != nil {
+			result.Error = composerResult.Error.Error()
+		}
+		jsonOut.ComposerAudits = append(jsonOut.ComposerAudits, result)
+
+		// Aggregate summary
+		totalSummary.Add(composerResult.Summary)
+	}
+
+	// Add runtime audit results
+	jsonOut.RuntimeAudits = make([]JSONRuntimeAuditResult, 0)
+	for _, runtimeResult := range output.RuntimeAuditResults {
+		result := JSONRuntimeAuditResult{
+			ManifestPath:    runtimeResult.ManifestPath,
+			ManifestType:    runtimeResult.ManifestType,
+			Runtimes:        runtimeResult.Runtimes,
+			Vulnerabilities: runtimeResult.Vulnerabilities,
+			Summary:         runtimeResult.Summary,
+			Withdrawn:       runtimeResult.WithdrawnSkipped,
+			Unverified:      runtimeResult.Unverified,
+		}
+		if runtimeResult.Error != nil {
+			result.Error = runtimeResult.Error.Error()
+		}
+		jsonOut.RuntimeAudits = append(jsonOut.RuntimeAudits, result)
+
+		// Aggregate summary
+		totalSummary.Add(runtimeResult.Summary)
+	}
+
+	// Add SBOM audit results
+	jsonOut.SBOMAudits = make([]JSONSBOMAuditResult, 0)
+	for _, sbomResult := range output.SBOMAuditResults {
+		result := JSONSBOMAuditResult{
+			SBOMPath:          sbomResult.SBOMPath,
+			Format:            sbomResult.Format,
+			ComponentsScanned: sbomResult.ComponentsScanned,
+			Vulnerabilities:   sbomResult.Vulnerabilities,
+			Summary:           sbomResult.Summary,
+			Withdrawn:         sbomResult.WithdrawnSkipped,
+			Unverified:        sbomResult.Unverified,
+		}
+		if sbomResult.Error != nil {
+			result.Error = sbomResult.Error.Error()
+		}
+		jsonOut.SBOMAudits = append(jsonOut.SBOMAudits, result)
+
+		// Aggregate summary
+		totalSummary.Add(sbomResult.Summary)
+	}
+
+	// Add custom manifest audit results
+	jsonOut.CustomAudits = make([]JSONCustomAuditResult, 0)
+	for _, customResult := range output.CustomAuditResults {
+		result := JSONCustomAuditResult{
+			ManifestPath:    customResult.ManifestPath,
+			ManifestType:    customResult.ManifestType,
+			Ecosystem:       string(customResult.Ecosystem),
+			Vulnerabilities: customResult.Vulnerabilities,
+			Summary:         customResult.Summary,
+			PackagesScanned: customResult.PackagesScanned,
+			Withdrawn:       customResult.WithdrawnSkipped,
+			Unverified:      customResult.Unverified,
+		}
+		if customResult.Error != nil {
+			result.Error = customResult.Error.Error()
+		}
+		jsonOut.CustomAudits = append(jsonOut.CustomAudits, result)
+
+		// Aggregate summary
+		totalSummary.Add(customResult.Summary)
+	}
+
+	jsonOut.SupplyChain = buildJSONSecurityResults(output.SecurityResults)
+
+	if output.ShowCrossEcosystem {
+		jsonOut.CrossEcosystem = CrossEcosystemAdvisories(output)
+	}
+
+	jsonOut.Summary = totalSummary
+	jsonOut.SummaryByEcosystem = summaryByEcosystem(output)
+	jsonOut.UpgradesByEcosystem = upgradesByEcosystem(output)
+	jsonOut.Upgrades = aggregateUpgrades(jsonOut.UpgradesByEcosystem)
+	jsonOut.Unverified = UnverifiedEcosystems(output)
+	jsonOut.Errors = collectIssues(output)
+	jsonOut.ScanManifest = buildScanManifest(output)
+
+	return jsonOut
+}
+
+// incompleteLockfileTable replaces "No vulnerabilities found!" for a
+// package.json whose lockfile is missing resolved versions
+const incompleteLockfileTable = "INCOMPLETE — cannot determine versions (package-lock.json is missing resolved versions)"
+
+// formatTableSummary renders a manifest's summary, flagging results that
+// couldn't be checked instead of reporting them as clean
+func formatTableSummary(summary audit.VulnerabilitySummary, unverified bool) string {
+	if unverified {
+		return "UNVERIFIED — backend unavailable (every vulnerability query failed)"
+	}
+	return summary.FormatSummary()
 }
 
 // TableFormatter implements table output using tablewriter
@@ -230,11 +839,20 @@ func (f *TableFormatter) Format(output *ScanOutput) (string, error) {
 	builder.WriteString(fmt.Sprintf("\n%s Scan Results\n", output.Metadata.ToolName))
 	builder.WriteString(strings.Repeat("=", 80) + "\n")
 	builder.WriteString(fmt.Sprintf("Directory: %s\n", output.Metadata.Directory))
-	builder.WriteString(fmt.Sprintf("Timestamp: %s\n\n", output.Metadata.Timestamp.Format(time.RFC3339)))
+	if !output.Metadata.Timestamp.IsZero() {
+		builder.WriteString(fmt.Sprintf("Timestamp: %s\n", output.Metadata.Timestamp.Format(time.RFC3339)))
+	}
+	builder.WriteString("\n")
 
 	// Manifest files summary
 	builder.WriteString(fmt.Sprintf("Found %d manifest file(s)\n\n", len(output.ScanResults.Files)))
 
+	if output.SummaryOnly {
+		writeTableOverallSummary(&builder, output)
+		writeTableIssues(&builder, collectIssues(output))
+		return builder.String(), nil
+	}
+
 	// For each audit result, create a table
 	for _, auditResult := range output.AuditResults {
 		if auditResult.Error != nil {
@@ -243,20 +861,26 @@ func (f *TableFormatter) Format(output *ScanOutput) (string, error) {
 		}
 
 		builder.WriteString(fmt.Sprintf("Package: %s\n", auditResult.PackageJSONPath))
-		builder.WriteString(auditResult.Summary.FormatSummary())
+		if len(auditResult.Workspaces) > 0 {
+			builder.WriteString(fmt.Sprintf("Workspaces: %s\n", strings.Join(auditResult.Workspaces, ", ")))
+		}
+		writeTableWithdrawn(&builder, auditResult.WithdrawnSkipped)
+		writeTableScanned(&builder, auditResult.PackagesScanned, npmDependencyCounts(auditResult))
+		if auditResult.IncompleteLockfile && !auditResult.Unverified && auditResult.Summary.Total == 0 {
+			builder.WriteString(incompleteLockfileTable)
+		} else {
+			builder.WriteString(formatTableSummary(auditResult.Summary, auditResult.Unverified))
+		}
 		builder.WriteString("\n")
 
 		if len(auditResult.Vulnerabilities) > 0 {
 			// Create simple table
-			builder.WriteString(fmt.Sprintf("%-40s %-12s %-20s %s\n",
-				"Package", "Severity", "Range", "Direct"))
-			builder.WriteString(strings.Repeat("-", 85) + "\n")
+			builder.WriteString(fmt.Sprintf("%-40s %-12s %-20s %-8s %s\n",
+				"Package", "Severity", "Range", "Direct", "Fix"))
+			builder.WriteString(strings.Repeat("-", 94) + "\n")
 
 			for _, vuln := range auditResult.Vulnerabilities {
-				isDirect := "No"
-				if vuln.IsDirect {
-					isDirect = "Yes"
-				}
+				isDirect := directLabel(vuln.IsDirect)
 
 				// Truncate long package names
 				pkgName := vuln.Name
@@ -270,16 +894,22 @@ func (f *TableFormatter) Format(output *ScanOutput) (string, error) {
 					vulnRange = vulnRange[:15] + "..."
 				}
 
-				builder.WriteString(fmt.Sprintf("%-40s %s%-12s%s %-20s %s\n",
+				builder.WriteString(fmt.Sprintf("%-40s %s%-12s%s %-20s %-8s %s\n",
 					pkgName,
 					audit.GetSeverityColor(vuln.Severity),
 					string(vuln.Severity),
 					audit.ResetColor(),
 					vulnRange,
-					isDirect))
+					isDirect,
+					npmFixLabel(vuln.FixAvailable)))
+				writeTableAdvisories(&builder, vuln)
 			}
 			builder.WriteString("\n")
 		}
+
+		if output.ShowFixes {
+			writeTableFixes(&builder, auditResult.Recommendations)
+		}
 	}
 
 	// For each Python audit result, create a table
@@ -290,14 +920,25 @@ func (f *TableFormatter) Format(output *ScanOutput) (string, error) {
 		}
 
 		builder.WriteString(fmt.Sprintf("Python Package: %s (%s)\n", pythonResult.ManifestPath, pythonResult.ManifestType))
-		builder.WriteString(pythonResult.Summary.FormatSummary())
+		writeTableWithdrawn(&builder, pythonResult.WithdrawnSkipped)
+		writeTableScanned(&builder, pythonResult.PackagesScanned, nil)
+		builder.WriteString(formatTableSummary(pythonResult.Summary, pythonResult.Unverified))
+		for _, note := range pythonResult.Notes {
+			builder.WriteString(fmt.Sprintf("  Note (line %d): %s\n", note.Line, note.Message))
+		}
+		for _, risk := range pythonResult.Typosquatting {
+			builder.WriteString(fmt.Sprintf("  Warning: %s is similar to %s (%s confidence typosquat)\n", risk.PackageName, risk.SimilarTo, risk.Confidence))
+		}
+		if unconfirmed := countUnconfirmed(pythonResult.Vulnerabilities); unconfirmed > 0 {
+			builder.WriteString(fmt.Sprintf("  Possible, not confirmed: %d finding(s) for unpinned packages; pin versions to confirm\n", unconfirmed))
+		}
 		builder.WriteString("\n")
 
 		if len(pythonResult.Vulnerabilities) > 0 {
 			// Create simple table
-			builder.WriteString(fmt.Sprintf("%-40s %-12s %-20s %s\n",
-				"Package", "Version", "Vulnerability ID", "Fix Versions"))
-			builder.WriteString(strings.Repeat("-", 85) + "\n")
+			builder.WriteString(fmt.Sprintf("%-40s %-12s %-20s %-8s %s\n",
+				"Package", "Version", "Vulnerability ID", "Direct", "Fix Versions"))
+			builder.WriteString(strings.Repeat("-", 94) + "\n")
 
 			for _, vuln := range pythonResult.Vulnerabilities {
 				// Truncate long package names
@@ -307,7 +948,7 @@ func (f *TableFormatter) Format(output *ScanOutput) (string, error) {
 				}
 
 				// Truncate long version
-				version := vuln.Version
+				version := pythonVersionLabel(vuln)
 				if len(version) > 10 {
 					version = version[:7] + "..."
 				}
@@ -324,10 +965,11 @@ func (f *TableFormatter) Format(output *ScanOutput) (string, error) {
 					fixVersions = "N/A"
 				}
 
-				builder.WriteString(fmt.Sprintf("%-40s %-12s %-20s %s\n",
+				builder.WriteString(fmt.Sprintf("%-40s %-12s %-20s %-8s %s\n",
 					pkgName,
 					version,
 					vulnID,
+					directLabel(vuln.IsDirect),
 					fixVersions))
 			}
 			builder.WriteString("\n")
@@ -341,15 +983,27 @@ func (f *TableFormatter) Format(output *ScanOutput) (string, error) {
 			continue
 		}
 
-		builder.WriteString(fmt.Sprintf("Go Module: %s\n", goResult.ManifestPath))
-		builder.WriteString(goResult.Summary.FormatSummary())
+		if len(goResult.Manifests) > 1 {
+			builder.WriteString(fmt.Sprintf("Go Modules (combined): %s\n", strings.Join(goResult.Manifests, ", ")))
+		} else {
+			builder.WriteString(fmt.Sprintf("Go Module: %s\n", goResult.ManifestPath))
+		}
+		if len(goResult.Vendored) > 0 {
+			builder.WriteString(fmt.Sprintf("Vendored versions from: %s\n", strings.Join(goResult.Vendored, ", ")))
+		}
+		if goResult.IndirectSkipped > 0 {
+			builder.WriteString(fmt.Sprintf("Indirect modules not audited: %d (use --include-indirect)\n", goResult.IndirectSkipped))
+		}
+		writeTableWithdrawn(&builder, goResult.WithdrawnSkipped)
+		writeTableScanned(&builder, goResult.ModulesScanned, nil)
+		builder.WriteString(formatTableSummary(goResult.Summary, goResult.Unverified))
 		builder.WriteString("\n")
 
 		if len(goResult.Vulnerabilities) > 0 {
 			// Create simple table
-			builder.WriteString(fmt.Sprintf("%-40s %-12s %-20s %s\n",
-				"Module", "Version", "Vulnerability ID", "Fix Versions"))
-			builder.WriteString(strings.Repeat("-", 85) + "\n")
+			builder.WriteString(fmt.Sprintf("%-40s %-12s %-20s %-8s %s\n",
+				"Module", "Version", "Vulnerability ID", "Direct", "Fix Versions"))
+			builder.WriteString(strings.Repeat("-", 94) + "\n")
 
 			for _, vuln := range goResult.Vulnerabilities {
 				// Truncate long module names
@@ -376,14 +1030,19 @@ func (f *TableFormatter) Format(output *ScanOutput) (string, error) {
 					fixVersions = "N/A"
 				}
 
-				builder.WriteString(fmt.Sprintf("%-40s %-12s %-20s %s\n",
+				builder.WriteString(fmt.Sprintf("%-40s %-12s %-20s %-8s %s\n",
 					moduleName,
 					version,
 					vulnID,
+					directLabel(vuln.IsDirect),
 					fixVersions))
 			}
 			builder.WriteString("\n")
 		}
+
+		if output.ShowFixes {
+			writeTableFixes(&builder, goResult.Recommendations)
+		}
 	}
 
 	// For each Maven audit result, create a table
@@ -394,18 +1053,23 @@ func (f *TableFormatter) Format(output *ScanOutput) (string, error) {
 		}
 
 		builder.WriteString(fmt.Sprintf("Maven Project: %s\n", mavenResult.ManifestPath))
-		builder.WriteString(mavenResult.Summary.FormatSummary())
+		if len(mavenResult.Unresolved) > 0 {
+			builder.WriteString(fmt.Sprintf("Dependencies not audited: %d (version from a parent or BOM)\n", len(mavenResult.Unresolved)))
+		}
+		writeTableWithdrawn(&builder, mavenResult.WithdrawnSkipped)
+		writeTableScanned(&builder, mavenResult.PackagesScanned, nil)
+		builder.WriteString(formatTableSummary(mavenResult.Summary, mavenResult.Unverified))
 		builder.WriteString("\n")
 
 		if len(mavenResult.Vulnerabilities) > 0 {
 			// Create simple table
-			builder.WriteString(fmt.Sprintf("%-40s %-12s %-20s %s\n",
-				"Dependency", "Version", "Vulnerability ID", "Fix Versions"))
-			builder.WriteString(strings.Repeat("-", 85) + "\n")
+			builder.WriteString(fmt.Sprintf("%-40s %-12s %-20s %-8s %s\n",
+				"Dependency", "Version", "Vulnerability ID", "Direct", "Fix Versions"))
+			builder.WriteString(strings.Repeat("-", 94) + "\n")
 
 			for _, vuln := range mavenResult.Vulnerabilities {
 				// Create dependency name (groupId:artifactId)
-				depName := fmt.Sprintf("%s:%s", vuln.GroupID, vuln.ArtifactID)
+				depName := vuln.ArtifactName()
 				if len(depName) > 38 {
 					depName = depName[:35] + "..."
 				}
@@ -428,279 +1092,1325 @@ func (f *TableFormatter) Format(output *ScanOutput) (string, error) {
 					fixVersions = "N/A"
 				}
 
-				builder.WriteString(fmt.Sprintf("%-40s %-12s %-20s %s\n",
+				builder.WriteString(fmt.Sprintf("%-40s %-12s %-20s %-8s %s\n",
 					depName,
 					version,
 					vulnID,
+					directLabel(vuln.IsDirect),
 					fixVersions))
 			}
 			builder.WriteString("\n")
 		}
 	}
 
-	// Overall summary
-	builder.WriteString(strings.Repeat("=", 80) + "\n")
-	builder.WriteString(fmt.Sprintf("Total vulnerabilities: %d\n", output.TotalVulns))
+	// For each Swift audit result, create a table
+	for _, swiftResult := range output.SwiftAuditResults {
+		if swiftResult.Error != nil {
+			builder.WriteString(fmt.Sprintf("Error auditing Swift %s: %v\n\n", swiftResult.ManifestPath, swiftResult.Error))
+			continue
+		}
 
-	return builder.String(), nil
-}
+		builder.WriteString(fmt.Sprintf("Swift Package: %s\n", swiftResult.ManifestPath))
+		writeTableWithdrawn(&builder, swiftResult.WithdrawnSkipped)
+		writeTableScanned(&builder, swiftResult.PackagesScanned, nil)
+		builder.WriteString(formatTableSummary(swiftResult.Summary, swiftResult.Unverified))
+		builder.WriteString("\n")
 
-// MarkdownFormatter implements markdown output
-type MarkdownFormatter struct{}
+		if len(swiftResult.Vulnerabilities) > 0 {
+			// Create simple table
+			builder.WriteString(fmt.Sprintf("%-40s %-12s %-20s %s\n",
+				"Package", "Version", "Vulnerability ID", "Fix Versions"))
+			builder.WriteString(strings.Repeat("-", 85) + "\n")
 
-func (f *MarkdownFormatter) Format(output *ScanOutput) (string, error) {
-	var builder strings.Builder
+			for _, vuln := range swiftResult.Vulnerabilities {
+				// Truncate long package name
+				pkgName := vuln.Name
+				if len(pkgName) > 38 {
+					pkgName = pkgName[:35] + "..."
+				}
 
-	// Write header
-	builder.WriteString(fmt.Sprintf("# %s Scan Results\n\n", output.Metadata.ToolName))
-	builder.WriteString(fmt.Sprintf("**Directory:** %s  \n", output.Metadata.Directory))
-	builder.WriteString(fmt.Sprintf("**Timestamp:** %s  \n", output.Metadata.Timestamp.Format(time.RFC3339)))
-	builder.WriteString(fmt.Sprintf("**Version:** %s  \n\n", output.Metadata.ToolVersion))
+				// Truncate long version
+				version := vuln.Version
+				if len(version) > 10 {
+					version = version[:7] + "..."
+				}
 
-	// Manifest files summary
-	builder.WriteString("## Manifest Files\n\n")
-	builder.WriteString(fmt.Sprintf("Found **%d** manifest file(s):\n\n", len(output.ScanResults.Files)))
-	for _, file := range output.ScanResults.Files {
-		builder.WriteString(fmt.Sprintf("- `%s` (%s)\n", file.Path, file.Type))
-	}
-	builder.WriteString("\n")
+				// Truncate long ID
+				vulnID := vuln.ID
+				if len(vulnID) > 18 {
+					vulnID = vulnID[:15] + "..."
+				}
 
-	// Audit results
-	builder.WriteString("## Security Audit Results\n\n")
+				// Format fix versions
+				fixVersions := strings.Join(vuln.FixVersions, ", ")
+				if len(fixVersions) == 0 {
+					fixVersions = "N/A"
+				}
 
-	// Node.js audit results
-	if len(output.AuditResults) > 0 {
+				builder.WriteString(fmt.Sprintf("%-40s %-12s %-20s %s\n",
+					pkgName,
+					version,
+					vulnID,
+					fixVersions))
+			}
+			builder.WriteString("\n")
+		}
+	}
+
+	// For each Cargo audit result, create a table
+	for _, cargoResult := range output.CargoAuditResults {
+		if cargoResult.Error != nil {
+			builder.WriteString(fmt.Sprintf("Error auditing Cargo %s: %v\n\n", cargoResult.ManifestPath, cargoResult.Error))
+			continue
+		}
+
+		builder.WriteString(fmt.Sprintf("Cargo Project: %s\n", cargoResult.ManifestPath))
+		writeTableWithdrawn(&builder, cargoResult.WithdrawnSkipped)
+		writeTableScanned(&builder, cargoResult.PackagesScanned, nil)
+		builder.WriteString(formatTableSummary(cargoResult.Summary, cargoResult.Unverified))
+		builder.WriteString("\n")
+
+		if len(cargoResult.Vulnerabilities) > 0 {
+			// Create simple table
+			builder.WriteString(fmt.Sprintf("%-40s %-12s %-20s %s\n",
+				"Crate", "Version", "Vulnerability ID", "Fix Versions"))
+			builder.WriteString(strings.Repeat("-", 85) + "\n")
+
+			for _, vuln := range cargoResult.Vulnerabilities {
+				// Truncate long package name
+				pkgName := vuln.Name
+				if len(pkgName) > 38 {
+					pkgName = pkgName[:35] + "..."
+				}
+
+				// Truncate long version
+				version := vuln.Version
+				if len(version) > 10 {
+					version = version[:7] + "..."
+				}
+
+				// Truncate long ID
+				vulnID := vuln.ID
+				if len(vulnID) > 18 {
+					vulnID = vulnID[:15] + "..."
+				}
+
+				// Format fix versions
+				fixVersions := strings.Join(vuln.FixVersions, ", ")
+				if len(fixVersions) == 0 {
+					fixVersions = "N/A"
+				}
+
+				builder.WriteString(fmt.Sprintf("%-40s %-12s %-20s %s\n",
+					pkgName,
+					version,
+					vulnID,
+					fixVersions))
+			}
+			builder.WriteString("\n")
+		}
+	}
+
+	// For each Ruby audit result, create a table
+	for _, rubyResult := range output.RubyAuditResults {
+		if rubyResult.Error != nil {
+			builder.WriteString(fmt.Sprintf("Error auditing Ruby %s: %v\n\n", rubyResult.ManifestPath, rubyResult.Error))
+			continue
+		}
+
+		builder.WriteString(fmt.Sprintf("Ruby Project: %s\n", rubyResult.ManifestPath))
+		writeTableWithdrawn(&builder, rubyResult.WithdrawnSkipped)
+		writeTableScanned(&builder, rubyResult.PackagesScanned, nil)
+		builder.WriteString(formatTableSummary(rubyResult.Summary, rubyResult.Unverified))
+		builder.WriteString("\n")
+
+		if len(rubyResult.Vulnerabilities) > 0 {
+			// Create simple table
+			builder.WriteString(fmt.Sprintf("%-40s %-12s %-20s %s\n",
+				"Gem", "Version", "Vulnerability ID", "Fix Versions"))
+			builder.WriteString(strings.Repeat("-", 85) + "\n")
+
+			for _, vuln := range rubyResult.Vulnerabilities {
+				// Truncate long package name
+				pkgName := vuln.Name
+				if len(pkgName) > 38 {
+					pkgName = pkgName[:35] + "..."
+				}
+
+				// Truncate long version
+				version := vuln.Version
+				if len(version) > 10 {
+					version = version[:7] + "..."
+				}
+
+				// Truncate long ID
+				vulnID := vuln.ID
+				if len(vulnID) > 18 {
+					vulnID = vulnID[:15] + "..."
+				}
+
+				// Format fix versions
+				fixVersions := strings.Join(vuln.FixVersions, ", ")
+				if len(fixVersions) == 0 {
+					fixVersions = "N/A"
+				}
+
+				builder.WriteString(fmt.Sprintf("%-40s %-12s %-20s %s\n",
+					pkgName,
+					version,
+					vulnID,
+					fixVersions))
+			}
+			builder.WriteString("\n")
+		}
+	}
+
+	// For each Composer audit result, create a table
+	for _, composerResult := range output.ComposerAuditResults {
+		if composerResult.Error != nil {
+			builder.WriteString(fmt.Sprintf("Error auditing PHP %s: %v\n\n", composerResult.ManifestPath, composerResult.Error))
+			continue
+		}
+
+		builder.WriteString(fmt.Sprintf("PHP Project: %s\n", composerResult.ManifestPath))
+		writeTableWithdrawn(&builder, composerResult.WithdrawnSkipped)
+		writeTableScanned(&builder, composerResult.PackagesScanned, nil)
+		builder.WriteString(formatTableSummary(composerResult.Summary, composerResult.Unverified))
+		builder.WriteString("\n")
+
+		if len(composerResult.Vulnerabilities) > 0 {
+			// Create simple table
+			builder.WriteString(fmt.Sprintf("%-40s %-12s %-20s %s\n",
+				"Package", "Version", "Vulnerability ID", "Fix Versions"))
+			builder.WriteString(strings.Repeat("-", 85) + "\n")
+
+			for _, vuln := range composerResult.Vulnerabilities {
+				// Truncate long package name
+				pkgName := vuln.Name
+				if len(pkgName) > 38 {
+					pkgName = pkgName[:35] + "..."
+				}
+
+				// Truncate long version
+				version := vuln.Version
+				if len(version) > 10 {
+					version = version[:7] + "..."
+				}
+
+				// Truncate long ID
+				vulnID := vuln.ID
+				if len(vulnID) > 18 {
+					vulnID = vulnID[:15] + "..."
+				}
+
+				// Format fix versions
+				fixVersions := strings.Join(vuln.FixVersions, ", ")
+				if len(fixVersions) == 0 {
+					fixVersions = "N/A"
+				}
+
+				builder.WriteString(fmt.Sprintf("%-40s %-12s %-20s %s\n",
+					pkgName,
+					version,
+					vulnID,
+					fixVersions))
+			}
+			builder.WriteString("\n")
+		}
+	}
+
+	// For each runtime audit result, create a table
+	for _, runtimeResult := range output.RuntimeAuditResults {
+		if runtimeResult.Error != nil {
+			builder.WriteString(fmt.Sprintf("Error auditing runtime %s: %v\n\n", runtimeResult.ManifestPath, runtimeResult.Error))
+			continue
+		}
+
+		builder.WriteString(fmt.Sprintf("Runtime: %s (%s)\n", runtimeResult.ManifestPath, runtimeResult.ManifestType))
+		writeTableWithdrawn(&builder, runtimeResult.WithdrawnSkipped)
+		builder.WriteString(formatTableSummary(runtimeResult.Summary, runtimeResult.Unverified))
+		builder.WriteString("\n")
+
+		if len(runtimeResult.Vulnerabilities) > 0 {
+			// Create simple table
+			builder.WriteString(fmt.Sprintf("%-40s %-12s %-20s %s\n",
+				"Runtime", "Version", "Vulnerability ID", "Fix Versions"))
+			builder.WriteString(strings.Repeat("-", 85) + "\n")
+
+			for _, vuln := range runtimeResult.Vulnerabilities {
+				// Truncate long ID
+				vulnID := vuln.ID
+				if len(vulnID) > 18 {
+					vulnID = vulnID[:15] + "..."
+				}
+
+				// Format fix versions
+				fixVersions := strings.Join(vuln.FixVersions, ", ")
+				if len(fixVersions) == 0 {
+					fixVersions = "N/A"
+				}
+
+				builder.WriteString(fmt.Sprintf("%-40s %-12s %-20s %s\n",
+					vuln.Runtime,
+					vuln.Version,
+					vulnID,
+					fixVersions))
+			}
+			builder.WriteString("\n")
+		}
+	}
+
+	// For each SBOM audit result, create a table
+	for _, sbomResult := range output.SBOMAuditResults {
+		if sbomResult.Error != nil {
+			builder.WriteString(fmt.Sprintf("Error auditing SBOM %s: %v\n\n", sbomResult.SBOMPath, sbomResult.Error))
+			continue
+		}
+
+		builder.WriteString(fmt.Sprintf("SBOM: %s (%s, %d components)\n", sbomResult.SBOMPath, sbomResult.Format, sbomResult.ComponentsScanned))
+		writeTableWithdrawn(&builder, sbomResult.WithdrawnSkipped)
+		builder.WriteString(formatTableSummary(sbomResult.Summary, sbomResult.Unverified))
+		builder.WriteString("\n")
+
+		if len(sbomResult.Vulnerabilities) > 0 {
+			// Create simple table
+			builder.WriteString(fmt.Sprintf("%-40s %-12s %-20s %s\n",
+				"Component", "Version", "Vulnerability ID", "Fix Versions"))
+			builder.WriteString(strings.Repeat("-", 85) + "\n")
+
+			for _, vuln := range sbomResult.Vulnerabilities {
+				// Truncate long component names
+				name := vuln.Name
+				if len(name) > 38 {
+					name = name[:35] + "..."
+				}
+
+				// Truncate long version
+				version := vuln.Version
+				if len(version) > 10 {
+					version = version[:7] + "..."
+				}
+
+				// Truncate long ID
+				vulnID := vuln.ID
+				if len(vulnID) > 18 {
+					vulnID = vulnID[:15] + "..."
+				}
+
+				// Format fix versions
+				fixVersions := strings.Join(vuln.FixVersions, ", ")
+				if len(fixVersions) == 0 {
+					fixVersions = "N/A"
+				}
+
+				builder.WriteString(fmt.Sprintf("%-40s %-12s %-20s %s\n",
+					name,
+					version,
+					vulnID,
+					fixVersions))
+			}
+			builder.WriteString("\n")
+		}
+	}
+
+	// For each custom manifest audit result, create a table
+	for _, customResult := range output.CustomAuditResults {
+		if customResult.Error != nil {
+			builder.WriteString(fmt.Sprintf("Error auditing %s: %v\n\n", customResult.ManifestPath, customResult.Error))
+			continue
+		}
+
+		builder.WriteString(fmt.Sprintf("Manifest: %s (%s, %s)\n", customResult.ManifestPath, customResult.ManifestType, customResult.Ecosystem))
+		writeTableWithdrawn(&builder, customResult.WithdrawnSkipped)
+		writeTableScanned(&builder, customResult.PackagesScanned, nil)
+		builder.WriteString(formatTableSummary(customResult.Summary, customResult.Unverified))
+		builder.WriteString("\n")
+
+		if len(customResult.Vulnerabilities) > 0 {
+			// Create simple table
+			builder.WriteString(fmt.Sprintf("%-40s %-12s %-20s %s\n",
+				"Package", "Version", "Vulnerability ID", "Fix Versions"))
+			builder.WriteString(strings.Repeat("-", 85) + "\n")
+
+			for _, vuln := range customResult.Vulnerabilities {
+				// Truncate long package names
+				name := vuln.Name
+				if len(name) > 38 {
+					name = name[:35] + "..."
+				}
+
+				// Truncate long version
+				version := vuln.Version
+				if len(version) > 10 {
+					version = version[:7] + "..."
+				}
+
+				// Truncate long ID
+				vulnID := vuln.ID
+				if len(vulnID) > 18 {
+					vulnID = vulnID[:15] + "..."
+				}
+
+				// Format fix versions
+				fixVersions := strings.Join(vuln.FixVersions, ", ")
+				if len(fixVersions) == 0 {
+					fixVersions = "N/A"
+				}
+
+				builder.WriteString(fmt.Sprintf("%-40s %-12s %-20s %s\n",
+					name,
+					version,
+					vulnID,
+					fixVersions))
+			}
+			builder.WriteString("\n")
+		}
+	}
+
+	writeTableSupplyChain(&builder, output.SecurityResults)
+
+	if output.ShowCrossEcosystem {
+		writeTableCrossEcosystem(&builder, CrossEcosystemAdvisories(output))
+	}
+
+	writeTableOverallSummary(&builder, output)
+	writeTableIssues(&builder, collectIssues(output))
+
+	return builder.String(), nil
+}
+
+// writeTableOverallSummary writes the counts across every manifest that
+// close the table report
+func writeTableOverallSummary(builder *strings.Builder, output *ScanOutput) {
+	totalSummary := AggregateSummary(output)
+	builder.WriteString(strings.Repeat("=", 80) + "\n")
+	builder.WriteString(fmt.Sprintf("Dependencies scanned: %d\n", dependenciesScanned(output)))
+	builder.WriteString(fmt.Sprintf("Total vulnerabilities: %d\n", output.TotalVulns))
+	if output.TotalVulns > 0 {
+		builder.WriteString(fmt.Sprintf("Unique vulnerabilities: %d (each counted once across manifests)\n", UniqueVulnerabilities(output)))
+		builder.WriteString(fmt.Sprintf("Direct: %d, Transitive: %d\n", totalSummary.Direct, totalSummary.Transitive))
+		if output.SummaryOnly {
+			// The per-manifest summaries that break findings down by severity aren't shown
+			builder.WriteString(fmt.Sprintf("By severity: %s\n", severityCounts(totalSummary)))
+		}
+	}
+	writeTableUpgrades(builder, upgradesByEcosystem(output))
+	if output.HiddenUnfixable > 0 {
+		builder.WriteString(fmt.Sprintf("Hidden (no fix available): %d\n", output.HiddenUnfixable))
+	}
+	if output.Suppressed > 0 {
+		builder.WriteString(fmt.Sprintf("Suppressed: %d\n", output.Suppressed))
+	}
+	if unverified := UnverifiedEcosystems(output); len(unverified) > 0 {
+		builder.WriteString(fmt.Sprintf("UNVERIFIED (backend unavailable): %s\n", strings.Join(unverified, ", ")))
+	}
+}
+
+// MarkdownFormatter implements markdown output
+type MarkdownFormatter struct{}
+
+func (f *MarkdownFormatter) Format(output *ScanOutput) (string, error) {
+	var builder strings.Builder
+
+	// Write header
+	builder.WriteString(fmt.Sprintf("# %s Scan Results\n\n", output.Metadata.ToolName))
+	builder.WriteString(fmt.Sprintf("**Directory:** %s  \n", output.Metadata.Directory))
+	if !output.Metadata.Timestamp.IsZero() {
+		builder.WriteString(fmt.Sprintf("**Timestamp:** %s  \n", output.Metadata.Timestamp.Format(time.RFC3339)))
+	}
+	builder.WriteString(fmt.Sprintf("**Version:** %s  \n\n", output.Metadata.ToolVersion))
+
+	if output.ShowSeverityMatrix {
+		builder.WriteString(RenderSeverityMatrix(output))
+	}
+
+	if output.SummaryOnly {
+		builder.WriteString(fmt.Sprintf("**Manifests:** %d\n\n", len(output.ScanResults.Files)))
+		writeMarkdownOverallSummary(&builder, output)
+		writeMarkdownIssues(&builder, collectIssues(output))
+		return builder.String(), nil
+	}
+
+	// Manifest files summary
+	builder.WriteString("## Manifest Files\n\n")
+	builder.WriteString(fmt.Sprintf("Found **%d** manifest file(s):\n\n", len(output.ScanResults.Files)))
+	for _, file := range output.ScanResults.Files {
+		builder.WriteString(fmt.Sprintf("- `%s` (%s)\n", file.Path, file.Type))
+	}
+	builder.WriteString("\n")
+
+	// Audit results
+	builder.WriteString("## Security Audit Results\n\n")
+
+	// Node.js audit results
+	if len(output.AuditResults) > 0 {
 		builder.WriteString("### Node.js Packages\n\n")
 	}
 
-	for _, auditResult := range output.AuditResults {
-		builder.WriteString(fmt.Sprintf("#### %s\n\n", auditResult.PackageJSONPath))
+	for _, auditResult := range output.AuditResults {
+		builder.WriteString(fmt.Sprintf("#### %s\n\n", auditResult.PackageJSONPath))
+
+		if auditResult.Error != nil {
+			builder.WriteString(fmt.Sprintf("**Error:** %v\n\n", auditResult.Error))
+			continue
+		}
+
+		if len(auditResult.Workspaces) > 0 {
+			builder.WriteString(fmt.Sprintf("**Workspaces:** %s\n\n", strings.Join(auditResult.Workspaces, ", ")))
+		}
+		writeMarkdownWithdrawn(&builder, auditResult.WithdrawnSkipped)
+		writeMarkdownScanned(&builder, auditResult.PackagesScanned, npmDependencyCounts(auditResult))
+
+		// Summary
+		builder.WriteString("**Summary:**\n\n")
+		if auditResult.Unverified {
+			builder.WriteString("⚠️ **UNVERIFIED — backend unavailable.** Every vulnerability query failed, so no findings doesn't mean no vulnerabilities.\n\n")
+		} else if auditResult.IncompleteLockfile && auditResult.Summary.Total == 0 {
+			builder.WriteString("⚠️ **INCOMPLETE — cannot determine versions.** package-lock.json is missing resolved versions, so no findings doesn't mean no vulnerabilities.\n\n")
+		} else if auditResult.Summary.Total == 0 {
+			builder.WriteString("✅ No vulnerabilities found!\n\n")
+		} else {
+			builder.WriteString(fmt.Sprintf("- Total: **%d**\n", auditResult.Summary.Total))
+			if auditResult.Summary.Critical > 0 {
+				builder.WriteString(fmt.Sprintf("- Critical: **%d** 🔴\n", auditResult.Summary.Critical))
+			}
+			if auditResult.Summary.High > 0 {
+				builder.WriteString(fmt.Sprintf("- High: **%d** 🟠\n", auditResult.Summary.High))
+			}
+			if auditResult.Summary.Moderate > 0 {
+				builder.WriteString(fmt.Sprintf("- Moderate: **%d** 🟡\n", auditResult.Summary.Moderate))
+			}
+			if auditResult.Summary.Low > 0 {
+				builder.WriteString(fmt.Sprintf("- Low: **%d** 🔵\n", auditResult.Summary.Low))
+			}
+			builder.WriteString(fmt.Sprintf("- Direct: **%d**, Transitive: **%d**\n", auditResult.Summary.Direct, auditResult.Summary.Transitive))
+			builder.WriteString("\n")
+		}
+
+		// Vulnerabilities table
+		if len(auditResult.Vulnerabilities) > 0 {
+			builder.WriteString("**Vulnerabilities:**\n\n")
+			builder.WriteString("| Package | Severity | Range | Direct | Fix | Advisory | Description |\n")
+			builder.WriteString("|---------|----------|-------|--------|-----|----------|-------------|\n")
+
+			for _, vuln := range auditResult.Vulnerabilities {
+				isDirect := directLabel(vuln.IsDirect)
+
+				// Format severity with emoji
+				severityStr := string(vuln.Severity)
+				switch vuln.Severity {
+				case audit.SeverityCritical:
+					severityStr = "🔴 Critical"
+				case audit.SeverityHigh:
+					severityStr = "🟠 High"
+				case audit.SeverityModerate:
+					severityStr = "🟡 Moderate"
+				case audit.SeverityLow:
+					severityStr = "🔵 Low"
+				}
+
+				advisories, descriptions := markdownAdvisories(vuln)
+				builder.WriteString(fmt.Sprintf("| `%s` | %s | `%s` | %s | %s | %s | %s |\n",
+					vuln.Name, severityStr, vuln.Range, isDirect, npmFixLabel(vuln.FixAvailable), advisories, descriptions))
+			}
+			builder.WriteString("\n")
+		}
+
+		if output.ShowFixes {
+			writeMarkdownFixes(&builder, auditResult.Recommendations)
+		}
+	}
+
+	// Python audit results
+	if len(output.PythonAuditResults) > 0 {
+		builder.WriteString("### Python Packages\n\n")
+	}
+
+	for _, pythonResult := range output.PythonAuditResults {
+		builder.WriteString(fmt.Sprintf("#### %s (%s)\n\n", pythonResult.ManifestPath, pythonResult.ManifestType))
+
+		if pythonResult.Error != nil {
+			builder.WriteString(fmt.Sprintf("**Error:** %v\n\n", pythonResult.Error))
+			continue
+		}
+		writeMarkdownWithdrawn(&builder, pythonResult.WithdrawnSkipped)
+
+		writeMarkdownScanned(&builder, pythonResult.PackagesScanned, nil)
+
+		// Summary
+		builder.WriteString("**Summary:**\n\n")
+		if pythonResult.Unverified {
+			builder.WriteString("⚠️ **UNVERIFIED — backend unavailable.** Every vulnerability query failed, so no findings doesn't mean no vulnerabilities.\n\n")
+		} else if pythonResult.Summary.Total == 0 {
+			builder.WriteString("✅ No vulnerabilities found!\n\n")
+		} else {
+			builder.WriteString(fmt.Sprintf("- Total: **%d**\n", pythonResult.Summary.Total))
+			if pythonResult.Summary.Critical > 0 {
+				builder.WriteString(fmt.Sprintf("- Critical: **%d** 🔴\n", pythonResult.Summary.Critical))
+			}
+			if pythonResult.Summary.High > 0 {
+				builder.WriteString(fmt.Sprintf("- High: **%d** 🟠\n", pythonResult.Summary.High))
+			}
+			if pythonResult.Summary.Moderate > 0 {
+				builder.WriteString(fmt.Sprintf("- Moderate: **%d** 🟡\n", pythonResult.Summary.Moderate))
+			}
+			if pythonResult.Summary.Low > 0 {
+				builder.WriteString(fmt.Sprintf("- Low: **%d** 🔵\n", pythonResult.Summary.Low))
+			}
+			builder.WriteString(fmt.Sprintf("- Direct: **%d**, Transitive: **%d**\n", pythonResult.Summary.Direct, pythonResult.Summary.Transitive))
+			builder.WriteString("\n")
+		}
+
+		// Informational notes
+		if len(pythonResult.Notes) > 0 {
+			builder.WriteString("**Notes:**\n\n")
+			for _, note := range pythonResult.Notes {
+				builder.WriteString(fmt.Sprintf("- ℹ️ Line %d: %s\n", note.Line, note.Message))
+			}
+			builder.WriteString("\n")
+		}
+
+		// Possible typosquats
+		if len(pythonResult.Typosquatting) > 0 {
+			builder.WriteString("**Possible Typosquats:**\n\n")
+			for _, risk := range pythonResult.Typosquatting {
+				builder.WriteString(fmt.Sprintf("- ⚠️ `%s` is similar to `%s` (%s confidence)\n", risk.PackageName, risk.SimilarTo, risk.Confidence))
+			}
+			builder.WriteString("\n")
+		}
+
+		// Vulnerabilities table
+		if unconfirmed := countUnconfirmed(pythonResult.Vulnerabilities); unconfirmed > 0 {
+			builder.WriteString(fmt.Sprintf("⚠️ **Possible, not confirmed:** %d finding(s) for unpinned packages. Pin versions to confirm them.\n\n", unconfirmed))
+		}
+
+		if len(pythonResult.Vulnerabilities) > 0 {
+			builder.WriteString("**Vulnerabilities:**\n\n")
+			builder.WriteString("| Package | Version | Vulnerability ID | Direct | Published | Fix Versions |\n")
+			builder.WriteString("|---------|---------|------------------|--------|-----------|-------------|\n")
+
+			for _, vuln := range pythonResult.Vulnerabilities {
+				fixVersions := strings.Join(vuln.FixVersions, ", ")
+				if len(fixVersions) == 0 {
+					fixVersions = "N/A"
+				}
+
+				builder.WriteString(fmt.Sprintf("| `%s` | `%s` | `%s` | %s | %s | %s |\n",
+					vuln.Name, pythonVersionLabel(vuln), vuln.ID, directLabel(vuln.IsDirect), formatAdvisoryDate(vuln.Published), fixVersions))
+			}
+			builder.WriteString("\n")
+		}
+	}
+
+	// Go audit results
+	if len(output.GoAuditResults) > 0 {
+		builder.WriteString("### Go Modules\n\n")
+	}
+
+	for _, goResult := range output.GoAuditResults {
+		if len(goResult.Manifests) > 1 {
+			builder.WriteString(fmt.Sprintf("#### Combined: %s\n\n", strings.Join(goResult.Manifests, ", ")))
+		} else {
+			builder.WriteString(fmt.Sprintf("#### %s\n\n", goResult.ManifestPath))
+		}
+		if len(goResult.Vendored) > 0 {
+			builder.WriteString(fmt.Sprintf("Vendored versions from `%s`\n\n", strings.Join(goResult.Vendored, "`, `")))
+		}
+		if goResult.IndirectSkipped > 0 {
+			builder.WriteString(fmt.Sprintf("%d indirect module(s) not audited (use `--include-indirect`)\n\n", goResult.IndirectSkipped))
+		}
+		writeMarkdownWithdrawn(&builder, goResult.WithdrawnSkipped)
+
+		if goResult.Error != nil {
+			builder.WriteString(fmt.Sprintf("**Error:** %v\n\n", goResult.Error))
+			continue
+		}
+
+		writeMarkdownScanned(&builder, goResult.ModulesScanned, nil)
+
+		// Summary
+		builder.WriteString("**Summary:**\n\n")
+		if goResult.Unverified {
+			builder.WriteString("⚠️ **UNVERIFIED — backend unavailable.** Every vulnerability query failed, so no findings doesn't mean no vulnerabilities.\n\n")
+		} else if goResult.Summary.Total == 0 {
+			builder.WriteString("✅ No vulnerabilities found!\n\n")
+		} else {
+			builder.WriteString(fmt.Sprintf("- Total: **%d**\n", goResult.Summary.Total))
+			if goResult.Summary.Critical > 0 {
+				builder.WriteString(fmt.Sprintf("- Critical: **%d** 🔴\n", goResult.Summary.Critical))
+			}
+			if goResult.Summary.High > 0 {
+				builder.WriteString(fmt.Sprintf("- High: **%d** 🟠\n", goResult.Summary.High))
+			}
+			if goResult.Summary.Moderate > 0 {
+				builder.WriteString(fmt.Sprintf("- Moderate: **%d** 🟡\n", goResult.Summary.Moderate))
+			}
+			if goResult.Summary.Low > 0 {
+				builder.WriteString(fmt.Sprintf("- Low: **%d** 🔵\n", goResult.Summary.Low))
+			}
+			builder.WriteString(fmt.Sprintf("- Direct: **%d**, Transitive: **%d**\n", goResult.Summary.Direct, goResult.Summary.Transitive))
+			builder.WriteString("\n")
+		}
+
+		// Vulnerabilities table
+		if len(goResult.Vulnerabilities) > 0 {
+			builder.WriteString("**Vulnerabilities:**\n\n")
+			builder.WriteString("| Module | Version | Vulnerability ID | Direct | Published | Fix Versions |\n")
+			builder.WriteString("|--------|---------|------------------|--------|-----------|-------------|\n")
+
+			for _, vuln := range goResult.Vulnerabilities {
+				fixVersions := strings.Join(vuln.FixVersions, ", ")
+				if len(fixVersions) == 0 {
+					fixVersions = "N/A"
+				}
+
+				builder.WriteString(fmt.Sprintf("| `%s` | `%s` | `%s` | %s | %s | %s |\n",
+					vuln.Module, vuln.Version, vuln.ID, directLabel(vuln.IsDirect), formatAdvisoryDate(vuln.Published), fixVersions))
+			}
+			builder.WriteString("\n")
+		}
+
+		if output.ShowFixes {
+			writeMarkdownFixes(&builder, goResult.Recommendations)
+		}
+	}
+
+	// Maven audit results
+	if len(output.MavenAuditResults) > 0 {
+		builder.WriteString("### Maven/Java Projects\n\n")
+	}
+
+	for _, mavenResult := range output.MavenAuditResults {
+		builder.WriteString(fmt.Sprintf("#### %s\n\n", mavenResult.ManifestPath))
+		if len(mavenResult.Unresolved) > 0 {
+			builder.WriteString(fmt.Sprintf("%d dependency version(s) inherited from a parent or BOM, not audited\n\n", len(mavenResult.Unresolved)))
+		}
+		writeMarkdownWithdrawn(&builder, mavenResult.WithdrawnSkipped)
+
+		if mavenResult.Error != nil {
+			builder.WriteString(fmt.Sprintf("**Error:** %v\n\n", mavenResult.Error))
+			continue
+		}
+
+		writeMarkdownScanned(&builder, mavenResult.PackagesScanned, nil)
+
+		// Summary
+		builder.WriteString("**Summary:**\n\n")
+		if mavenResult.Unverified {
+			builder.WriteString("⚠️ **UNVERIFIED — backend unavailable.** Every vulnerability query failed, so no findings doesn't mean no vulnerabilities.\n\n")
+		} else if mavenResult.Summary.Total == 0 {
+			builder.WriteString("✅ No vulnerabilities found!\n\n")
+		} else {
+			builder.WriteString(fmt.Sprintf("- Total: **%d**\n", mavenResult.Summary.Total))
+			if mavenResult.Summary.Critical > 0 {
+				builder.WriteString(fmt.Sprintf("- Critical: **%d** 🔴\n", mavenResult.Summary.Critical))
+			}
+			if mavenResult.Summary.High > 0 {
+				builder.WriteString(fmt.Sprintf("- High: **%d** 🟠\n", mavenResult.Summary.High))
+			}
+			if mavenResult.Summary.Moderate > 0 {
+				builder.WriteString(fmt.Sprintf("- Moderate: **%d** 🟡\n", mavenResult.Summary.Moderate))
+			}
+			if mavenResult.Summary.Low > 0 {
+				builder.WriteString(fmt.Sprintf("- Low: **%d** 🔵\n", mavenResult.Summary.Low))
+			}
+			builder.WriteString(fmt.Sprintf("- Direct: **%d**, Transitive: **%d**\n", mavenResult.Summary.Direct, mavenResult.Summary.Transitive))
+			builder.WriteString("\n")
+		}
+
+		// Vulnerabilities table
+		if len(mavenResult.Vulnerabilities) > 0 {
+			builder.WriteString("**Vulnerabilities:**\n\n")
+			builder.WriteString("| Dependency | Version | Vulnerability ID | Direct | Published | Fix Versions |\n")
+			builder.WriteString("|------------|---------|------------------|--------|-----------|-------------|\n")
+
+			for _, vuln := range mavenResult.Vulnerabilities {
+				depName := vuln.ArtifactName()
+				fixVersions := strings.Join(vuln.FixVersions, ", ")
+				if len(fixVersions) == 0 {
+					fixVersions = "N/A"
+				}
+
+				builder.WriteString(fmt.Sprintf("| `%s` | `%s` | `%s` | %s | %s | %s |\n",
+					depName, vuln.Version, vuln.ID, directLabel(vuln.IsDirect), formatAdvisoryDate(vuln.Published), fixVersions))
+			}
+			builder.WriteString("\n")
+		}
+	}
+
+	// Swift audit results
+	if len(output.SwiftAuditResults) > 0 {
+		builder.WriteString("### Swift Packages\n\n")
+	}
 
-		if auditResult.Error != nil {
-			builder.WriteString(fmt.Sprintf("**Error:** %v\n\n", auditResult.Error))
+	for _, swiftResult := range output.SwiftAuditResults {
+		builder.WriteString(fmt.Sprintf("#### %s\n\n", swiftResult.ManifestPath))
+
+		if swiftResult.Error != nil {
+			builder.WriteString(fmt.Sprintf("**Error:** %v\n\n", swiftResult.Error))
 			continue
 		}
 
+		writeMarkdownWithdrawn(&builder, swiftResult.WithdrawnSkipped)
+		writeMarkdownScanned(&builder, swiftResult.PackagesScanned, nil)
+
 		// Summary
 		builder.WriteString("**Summary:**\n\n")
-		if auditResult.Summary.Total == 0 {
+		if swiftResult.Unverified {
+			builder.WriteString("⚠️ **UNVERIFIED — backend unavailable.** Every vulnerability query failed, so no findings doesn't mean no vulnerabilities.\n\n")
+		} else if swiftResult.Summary.Total == 0 {
 			builder.WriteString("✅ No vulnerabilities found!\n\n")
 		} else {
-			builder.WriteString(fmt.Sprintf("- Total: **%d**\n", auditResult.Summary.Total))
-			if auditResult.Summary.Critical > 0 {
-				builder.WriteString(fmt.Sprintf("- Critical: **%d** 🔴\n", auditResult.Summary.Critical))
+			builder.WriteString(fmt.Sprintf("- Total: **%d**\n", swiftResult.Summary.Total))
+			if swiftResult.Summary.Critical > 0 {
+				builder.WriteString(fmt.Sprintf("- Critical: **%d** 🔴\n", swiftResult.Summary.Critical))
 			}
-			if auditResult.Summary.High > 0 {
-				builder.WriteString(fmt.Sprintf("- High: **%d** 🟠\n", auditResult.Summary.High))
+			if swiftResult.Summary.High > 0 {
+				builder.WriteString(fmt.Sprintf("- High: **%d** 🟠\n", swiftResult.Summary.High))
 			}
-			if auditResult.Summary.Moderate > 0 {
-				builder.WriteString(fmt.Sprintf("- Moderate: **%d** 🟡\n", auditResult.Summary.Moderate))
+			if swiftResult.Summary.Moderate > 0 {
+				builder.WriteString(fmt.Sprintf("- Moderate: **%d** 🟡\n", swiftResult.Summary.Moderate))
 			}
-			if auditResult.Summary.Low > 0 {
-				builder.WriteString(fmt.Sprintf("- Low: **%d** 🔵\n", auditResult.Summary.Low))
+			if swiftResult.Summary.Low > 0 {
+				builder.WriteString(fmt.Sprintf("- Low: **%d** 🔵\n", swiftResult.Summary.Low))
 			}
+			builder.WriteString(fmt.Sprintf("- Direct: **%d**, Transitive: **%d**\n", swiftResult.Summary.Direct, swiftResult.Summary.Transitive))
 			builder.WriteString("\n")
 		}
 
 		// Vulnerabilities table
-		if len(auditResult.Vulnerabilities) > 0 {
+		if len(swiftResult.Vulnerabilities) > 0 {
 			builder.WriteString("**Vulnerabilities:**\n\n")
-			builder.WriteString("| Package | Severity | Range | Direct |\n")
-			builder.WriteString("|---------|----------|-------|--------|\n")
+			builder.WriteString("| Package | Version | Vulnerability ID | Published | Fix Versions |\n")
+			builder.WriteString("|---------|---------|------------------|-----------|-------------|\n")
 
-			for _, vuln := range auditResult.Vulnerabilities {
-				isDirect := "No"
-				if vuln.IsDirect {
-					isDirect = "Yes"
+			for _, vuln := range swiftResult.Vulnerabilities {
+				fixVersions := strings.Join(vuln.FixVersions, ", ")
+				if len(fixVersions) == 0 {
+					fixVersions = "N/A"
 				}
 
-				// Format severity with emoji
-				severityStr := string(vuln.Severity)
-				switch vuln.Severity {
-				case audit.SeverityCritical:
-					severityStr = "🔴 Critical"
-				case audit.SeverityHigh:
-					severityStr = "🟠 High"
-				case audit.SeverityModerate:
-					severityStr = "🟡 Moderate"
-				case audit.SeverityLow:
-					severityStr = "🔵 Low"
+				builder.WriteString(fmt.Sprintf("| `%s` | `%s` | `%s` | %s | %s |\n",
+					vuln.Name, vuln.Version, vuln.ID, formatAdvisoryDate(vuln.Published), fixVersions))
+			}
+			builder.WriteString("\n")
+		}
+	}
+
+	// Cargo audit results
+	if len(output.CargoAuditResults) > 0 {
+		builder.WriteString("### Rust Crates\n\n")
+	}
+
+	for _, cargoResult := range output.CargoAuditResults {
+		builder.WriteString(fmt.Sprintf("#### %s\n\n", cargoResult.ManifestPath))
+
+		if cargoResult.Error != nil {
+			builder.WriteString(fmt.Sprintf("**Error:** %v\n\n", cargoResult.Error))
+			continue
+		}
+
+		writeMarkdownWithdrawn(&builder, cargoResult.WithdrawnSkipped)
+		writeMarkdownScanned(&builder, cargoResult.PackagesScanned, nil)
+
+		// Summary
+		builder.WriteString("**Summary:**\n\n")
+		if cargoResult.Unverified {
+			builder.WriteString("⚠️ **UNVERIFIED — backend unavailable.** Every vulnerability query failed, so no findings doesn't mean no vulnerabilities.\n\n")
+		} else if cargoResult.Summary.Total == 0 {
+			builder.WriteString("✅ No vulnerabilities found!\n\n")
+		} else {
+			builder.WriteString(fmt.Sprintf("- Total: **%d**\n", cargoResult.Summary.Total))
+			if cargoResult.Summary.Critical > 0 {
+				builder.WriteString(fmt.Sprintf("- Critical: **%d** 🔴\n", cargoResult.Summary.Critical))
+			}
+			if cargoResult.Summary.High > 0 {
+				builder.WriteString(fmt.Sprintf("- High: **%d** 🟠\n", cargoResult.Summary.High))
+			}
+			if cargoResult.Summary.Moderate > 0 {
+				builder.WriteString(fmt.Sprintf("- Moderate: **%d** 🟡\n", cargoResult.Summary.Moderate))
+			}
+			if cargoResult.Summary.Low > 0 {
+				builder.WriteString(fmt.Sprintf("- Low: **%d** 🔵\n", cargoResult.Summary.Low))
+			}
+			builder.WriteString(fmt.Sprintf("- Direct: **%d**, Transitive: **%d**\n", cargoResult.Summary.Direct, cargoResult.Summary.Transitive))
+			builder.WriteString("\n")
+		}
+
+		// Vulnerabilities table
+		if len(cargoResult.Vulnerabilities) > 0 {
+			builder.WriteString("**Vulnerabilities:**\n\n")
+			builder.WriteString("| Crate | Version | Vulnerability ID | Published | Fix Versions |\n")
+			builder.WriteString("|---------|---------|------------------|-----------|-------------|\n")
+
+			for _, vuln := range cargoResult.Vulnerabilities {
+				fixVersions := strings.Join(vuln.FixVersions, ", ")
+				if len(fixVersions) == 0 {
+					fixVersions = "N/A"
 				}
 
-				builder.WriteString(fmt.Sprintf("| `%s` | %s | `%s` | %s |\n",
-					vuln.Name, severityStr, vuln.Range, isDirect))
+				builder.WriteString(fmt.Sprintf("| `%s` | `%s` | `%s` | %s | %s |\n",
+					vuln.Name, vuln.Version, vuln.ID, formatAdvisoryDate(vuln.Published), fixVersions))
 			}
 			builder.WriteString("\n")
 		}
 	}
 
-	// Python audit results
-	if len(output.PythonAuditResults) > 0 {
-		builder.WriteString("### Python Packages\n\n")
+	// Ruby audit results
+	if len(output.RubyAuditResults) > 0 {
+		builder.WriteString("### Ruby Gems\n\n")
 	}
 
-	for _, pythonResult := range output.PythonAuditResults {
-		builder.WriteString(fmt.Sprintf("#### %s (%s)\n\n", pythonResult.ManifestPath, pythonResult.ManifestType))
+	for _, rubyResult := range output.RubyAuditResults {
+		builder.WriteString(fmt.Sprintf("#### %s\n\n", rubyResult.ManifestPath))
 
-		if pythonResult.Error != nil {
-			builder.WriteString(fmt.Sprintf("**Error:** %v\n\n", pythonResult.Error))
+		if rubyResult.Error != nil {
+			builder.WriteString(fmt.Sprintf("**Error:** %v\n\n", rubyResult.Error))
 			continue
 		}
 
+		writeMarkdownWithdrawn(&builder, rubyResult.WithdrawnSkipped)
+		writeMarkdownScanned(&builder, rubyResult.PackagesScanned, nil)
+
 		// Summary
 		builder.WriteString("**Summary:**\n\n")
-		if pythonResult.Summary.Total == 0 {
+		if rubyResult.Unverified {
+			builder.WriteString("⚠️ **UNVERIFIED — backend unavailable.** Every vulnerability query failed, so no findings doesn't mean no vulnerabilities.\n\n")
+		} else if rubyResult.Summary.Total == 0 {
 			builder.WriteString("✅ No vulnerabilities found!\n\n")
 		} else {
-			builder.WriteString(fmt.Sprintf("- Total: **%d**\n", pythonResult.Summary.Total))
-			if pythonResult.Summary.Critical > 0 {
-				builder.WriteString(fmt.Sprintf("- Critical: **%d** 🔴\n", pythonResult.Summary.Critical))
+			builder.WriteString(fmt.Sprintf("- Total: **%d**\n", rubyResult.Summary.Total))
+			if rubyResult.Summary.Critical > 0 {
+				builder.WriteString(fmt.Sprintf("- Critical: **%d** 🔴\n", rubyResult.Summary.Critical))
 			}
-			if pythonResult.Summary.High > 0 {
-				builder.WriteString(fmt.Sprintf("- High: **%d** 🟠\n", pythonResult.Summary.High))
+			if rubyResult.Summary.High > 0 {
+				builder.WriteString(fmt.Sprintf("- High: **%d** 🟠\n", rubyResult.Summary.High))
 			}
-			if pythonResult.Summary.Moderate > 0 {
-				builder.WriteString(fmt.Sprintf("- Moderate: **%d** 🟡\n", pythonResult.Summary.Moderate))
+			if rubyResult.Summary.Moderate > 0 {
+				builder.WriteString(fmt.Sprintf("- Moderate: **%d** 🟡\n", rubyResult.Summary.Moderate))
 			}
-			if pythonResult.Summary.Low > 0 {
-				builder.WriteString(fmt.Sprintf("- Low: **%d** 🔵\n", pythonResult.Summary.Low))
+			if rubyResult.Summary.Low > 0 {
+				builder.WriteString(fmt.Sprintf("- Low: **%d** 🔵\n", rubyResult.Summary.Low))
 			}
+			builder.WriteString(fmt.Sprintf("- Direct: **%d**, Transitive: **%d**\n", rubyResult.Summary.Direct, rubyResult.Summary.Transitive))
 			builder.WriteString("\n")
 		}
 
 		// Vulnerabilities table
-		if len(pythonResult.Vulnerabilities) > 0 {
+		if len(rubyResult.Vulnerabilities) > 0 {
 			builder.WriteString("**Vulnerabilities:**\n\n")
-			builder.WriteString("| Package | Version | Vulnerability ID | Fix Versions |\n")
-			builder.WriteString("|---------|---------|------------------|-------------|\n")
+			builder.WriteString("| Gem | Version | Vulnerability ID | Published | Fix Versions |\n")
+			builder.WriteString("|---------|---------|------------------|-----------|-------------|\n")
 
-			for _, vuln := range pythonResult.Vulnerabilities {
+			for _, vuln := range rubyResult.Vulnerabilities {
 				fixVersions := strings.Join(vuln.FixVersions, ", ")
 				if len(fixVersions) == 0 {
 					fixVersions = "N/A"
 				}
 
-				builder.WriteString(fmt.Sprintf("| `%s` | `%s` | `%s` | %s |\n",
-					vuln.Name, vuln.Version, vuln.ID, fixVersions))
+				builder.WriteString(fmt.Sprintf("| `%s` | `%s` | `%s` | %s | %s |\n",
+					vuln.Name, vuln.Version, vuln.ID, formatAdvisoryDate(vuln.Published), fixVersions))
 			}
 			builder.WriteString("\n")
 		}
 	}
 
-	// Go audit results
-	if len(output.GoAuditResults) > 0 {
-		builder.WriteString("### Go Modules\n\n")
+	// Composer audit results
+	if len(output.ComposerAuditResults) > 0 {
+		builder.WriteString("### PHP Packages\n\n")
 	}
 
-	for _, goResult := range output.GoAuditResults {
-		builder.WriteString(fmt.Sprintf("#### %s\n\n", goResult.ManifestPath))
+	for _, composerResult := range output.ComposerAuditResults {
+		builder.WriteString(fmt.Sprintf("#### %s\n\n", composerResult.ManifestPath))
 
-		if goResult.Error != nil {
-			builder.WriteString(fmt.Sprintf("**Error:** %v\n\n", goResult.Error))
+		if composerResult.Error != nil {
+			builder.WriteString(fmt.Sprintf("**Error:** %v\n\n", composerResult.Error))
 			continue
 		}
 
+		writeMarkdownWithdrawn(&builder, composerResult.WithdrawnSkipped)
+		writeMarkdownScanned(&builder, composerResult.PackagesScanned, nil)
+
 		// Summary
 		builder.WriteString("**Summary:**\n\n")
-		if goResult.Summary.Total == 0 {
+		if composerResult.Unverified {
+			builder.WriteString("⚠️ **UNVERIFIED — backend unavailable.** Every vulnerability query failed, so no findings doesn't mean no vulnerabilities.\n\n")
+		} else if composerResult.Summary.Total == 0 {
 			builder.WriteString("✅ No vulnerabilities found!\n\n")
 		} else {
-			builder.WriteString(fmt.Sprintf("- Total: **%d**\n", goResult.Summary.Total))
-			if goResult.Summary.Critical > 0 {
-				builder.WriteString(fmt.Sprintf("- Critical: **%d** 🔴\n", goResult.Summary.Critical))
+			builder.WriteString(fmt.Sprintf("- Total: **%d**\n", composerResult.Summary.Total))
+			if composerResult.Summary.Critical > 0 {
+				builder.WriteString(fmt.Sprintf("- Critical: **%d** 🔴\n", composerResult.Summary.Critical))
 			}
-			if goResult.Summary.High > 0 {
-				builder.WriteString(fmt.Sprintf("- High: **%d** 🟠\n", goResult.Summary.High))
+			if composerResult.Summary.High > 0 {
+				builder.WriteString(fmt.Sprintf("- High: **%d** 🟠\n", composerResult.Summary.High))
 			}
-			if goResult.Summary.Moderate > 0 {
-				builder.WriteString(fmt.Sprintf("- Moderate: **%d** 🟡\n", goResult.Summary.Moderate))
+			if composerResult.Summary.Moderate > 0 {
+				builder.WriteString(fmt.Sprintf("- Moderate: **%d** 🟡\n", composerResult.Summary.Moderate))
 			}
-			if goResult.Summary.Low > 0 {
-				builder.WriteString(fmt.Sprintf("- Low: **%d** 🔵\n", goResult.Summary.Low))
+			if composerResult.Summary.Low > 0 {
+				builder.WriteString(fmt.Sprintf("- Low: **%d** 🔵\n", composerResult.Summary.Low))
 			}
+			builder.WriteString(fmt.Sprintf("- Direct: **%d**, Transitive: **%d**\n", composerResult.Summary.Direct, composerResult.Summary.Transitive))
 			builder.WriteString("\n")
 		}
 
 		// Vulnerabilities table
-		if len(goResult.Vulnerabilities) > 0 {
+		if len(composerResult.Vulnerabilities) > 0 {
 			builder.WriteString("**Vulnerabilities:**\n\n")
-			builder.WriteString("| Module | Version | Vulnerability ID | Fix Versions |\n")
-			builder.WriteString("|--------|---------|------------------|-------------|\n")
+			builder.WriteString("| Package | Version | Vulnerability ID | Published | Fix Versions |\n")
+			builder.WriteString("|---------|---------|------------------|-----------|-------------|\n")
 
-			for _, vuln := range goResult.Vulnerabilities {
+			for _, vuln := range composerResult.Vulnerabilities {
 				fixVersions := strings.Join(vuln.FixVersions, ", ")
 				if len(fixVersions) == 0 {
 					fixVersions = "N/A"
 				}
 
-				builder.WriteString(fmt.Sprintf("| `%s` | `%s` | `%s` | %s |\n",
-					vuln.Module, vuln.Version, vuln.ID, fixVersions))
+				builder.WriteString(fmt.Sprintf("| `%s` | `%s` | `%s` | %s | %s |\n",
+					vuln.Name, vuln.Version, vuln.ID, formatAdvisoryDate(vuln.Published), fixVersions))
 			}
 			builder.WriteString("\n")
 		}
 	}
 
-	// Maven audit results
-	if len(output.MavenAuditResults) > 0 {
-		builder.WriteString("### Maven/Java Projects\n\n")
+	// Runtime audit results
+	if len(output.RuntimeAuditResults) > 0 {
+		builder.WriteString("### Runtime\n\n")
 	}
 
-	for _, mavenResult := range output.MavenAuditResults {
-		builder.WriteString(fmt.Sprintf("#### %s\n\n", mavenResult.ManifestPath))
+	for _, runtimeResult := range output.RuntimeAuditResults {
+		builder.WriteString(fmt.Sprintf("#### %s (%s)\n\n", runtimeResult.ManifestPath, runtimeResult.ManifestType))
 
-		if mavenResult.Error != nil {
-			builder.WriteString(fmt.Sprintf("**Error:** %v\n\n", mavenResult.Error))
+		if runtimeResult.Error != nil {
+			builder.WriteString(fmt.Sprintf("**Error:** %v\n\n", runtimeResult.Error))
 			continue
 		}
 
+		writeMarkdownWithdrawn(&builder, runtimeResult.WithdrawnSkipped)
+
 		// Summary
 		builder.WriteString("**Summary:**\n\n")
-		if mavenResult.Summary.Total == 0 {
+		if runtimeResult.Unverified {
+			builder.WriteString("⚠️ **UNVERIFIED — backend unavailable.** Every vulnerability query failed, so no findings doesn't mean no vulnerabilities.\n\n")
+		} else if runtimeResult.Summary.Total == 0 {
 			builder.WriteString("✅ No vulnerabilities found!\n\n")
 		} else {
-			builder.WriteString(fmt.Sprintf("- Total: **%d**\n", mavenResult.Summary.Total))
-			if mavenResult.Summary.Critical > 0 {
-				builder.WriteString(fmt.Sprintf("- Critical: **%d** 🔴\n", mavenResult.Summary.Critical))
+			builder.WriteString(fmt.Sprintf("- Total: **%d**\n", runtimeResult.Summary.Total))
+			if runtimeResult.Summary.Critical > 0 {
+				builder.WriteString(fmt.Sprintf("- Critical: **%d** 🔴\n", runtimeResult.Summary.Critical))
 			}
-			if mavenResult.Summary.High > 0 {
-				builder.WriteString(fmt.Sprintf("- High: **%d** 🟠\n", mavenResult.Summary.High))
+			if runtimeResult.Summary.High > 0 {
+				builder.WriteString(fmt.Sprintf("- High: **%d** 🟠\n", runtimeResult.Summary.High))
 			}
-			if mavenResult.Summary.Moderate > 0 {
-				builder.WriteString(fmt.Sprintf("- Moderate: **%d** 🟡\n", mavenResult.Summary.Moderate))
+			if runtimeResult.Summary.Moderate > 0 {
+				builder.WriteString(fmt.Sprintf("- Moderate: **%d** 🟡\n", runtimeResult.Summary.Moderate))
 			}
-			if mavenResult.Summary.Low > 0 {
-				builder.WriteString(fmt.Sprintf("- Low: **%d** 🔵\n", mavenResult.Summary.Low))
+			if runtimeResult.Summary.Low > 0 {
+				builder.WriteString(fmt.Sprintf("- Low: **%d** 🔵\n", runtimeResult.Summary.Low))
 			}
 			builder.WriteString("\n")
 		}
 
 		// Vulnerabilities table
-		if len(mavenResult.Vulnerabilities) > 0 {
+		if len(runtimeResult.Vulnerabilities) > 0 {
 			builder.WriteString("**Vulnerabilities:**\n\n")
-			builder.WriteString("| Dependency | Version | Vulnerability ID | Fix Versions |\n")
-			builder.WriteString("|------------|---------|------------------|-------------|\n")
+			builder.WriteString("| Runtime | Version | Vulnerability ID | Published | Fix Versions |\n")
+			builder.WriteString("|---------|---------|------------------|-----------|-------------|\n")
 
-			for _, vuln := range mavenResult.Vulnerabilities {
-				depName := fmt.Sprintf("%s:%s", vuln.GroupID, vuln.ArtifactID)
+			for _, vuln := range runtimeResult.Vulnerabilities {
+				fixVersions := strings.Join(vuln.FixVersions, ", ")
+				if len(fixVersions) == 0 {
+					fixVersions = "N/A"
+				}
+
+				builder.WriteString(fmt.Sprintf("| `%s` | `%s` | `%s` | %s | %s |\n",
+					vuln.Runtime, vuln.Version, vuln.ID, formatAdvisoryDate(vuln.Published), fixVersions))
+			}
+			builder.WriteString("\n")
+		}
+	}
+
+	// SBOM audit results
+	if len(output.SBOMAuditResults) > 0 {
+		builder.WriteString("### SBOM\n\n")
+	}
+
+	for _, sbomResult := range output.SBOMAuditResults {
+		builder.WriteString(fmt.Sprintf("#### %s (%s)\n\n", sbomResult.SBOMPath, sbomResult.Format))
+
+		if sbomResult.Error != nil {
+			builder.WriteString(fmt.Sprintf("**Error:** %v\n\n", sbomResult.Error))
+			continue
+		}
+
+		writeMarkdownWithdrawn(&builder, sbomResult.WithdrawnSkipped)
+
+		// Summary
+		builder.WriteString("**Summary:**\n\n")
+		builder.WriteString(fmt.Sprintf("- Components audited: **%d**\n", sbomResult.ComponentsScanned))
+		if sbomResult.Unverified {
+			builder.WriteString("\n⚠️ **UNVERIFIED — backend unavailable.** Every vulnerability query failed, so no findings doesn't mean no vulnerabilities.\n\n")
+		} else if sbomResult.Summary.Total == 0 {
+			builder.WriteString("\n✅ No vulnerabilities found!\n\n")
+		} else {
+			builder.WriteString(fmt.Sprintf("- Total: **%d**\n", sbomResult.Summary.Total))
+			if sbomResult.Summary.Critical > 0 {
+				builder.WriteString(fmt.Sprintf("- Critical: **%d** 🔴\n", sbomResult.Summary.Critical))
+			}
+			if sbomResult.Summary.High > 0 {
+				builder.WriteString(fmt.Sprintf("- High: **%d** 🟠\n", sbomResult.Summary.High))
+			}
+			if sbomResult.Summary.Moderate > 0 {
+				builder.WriteString(fmt.Sprintf("- Moderate: **%d** 🟡\n", sbomResult.Summary.Moderate))
+			}
+			if sbomResult.Summary.Low > 0 {
+				builder.WriteString(fmt.Sprintf("- Low: **%d** 🔵\n", sbomResult.Summary.Low))
+			}
+			builder.WriteString("\n")
+		}
+
+		// Vulnerabilities table
+		if len(sbomResult.Vulnerabilities) > 0 {
+			builder.WriteString("**Vulnerabilities:**\n\n")
+			builder.WriteString("| Component | Ecosystem | Version | Vulnerability ID | Published | Fix Versions |\n")
+			builder.WriteString("|-----------|-----------|---------|------------------|-----------|-------------|\n")
+
+			for _, vuln := range sbomResult.Vulnerabilities {
+				fixVersions := strings.Join(vuln.FixVersions, ", ")
+				if len(fixVersions) == 0 {
+					fixVersions = "N/A"
+				}
+
+				builder.WriteString(fmt.Sprintf("| `%s` | %s | `%s` | `%s` | %s | %s |\n",
+					vuln.Name, vuln.Ecosystem, vuln.Version, vuln.ID, formatAdvisoryDate(vuln.Published), fixVersions))
+			}
+			builder.WriteString("\n")
+		}
+	}
+
+	// Custom manifest audit results
+	if len(output.CustomAuditResults) > 0 {
+		builder.WriteString("### Other Manifests\n\n")
+	}
+
+	for _, customResult := range output.CustomAuditResults {
+		builder.WriteString(fmt.Sprintf("#### %s (%s, %s)\n\n", customResult.ManifestPath, customResult.ManifestType, customResult.Ecosystem))
+
+		if customResult.Error != nil {
+			builder.WriteString(fmt.Sprintf("**Error:** %v\n\n", customResult.Error))
+			continue
+		}
+
+		writeMarkdownWithdrawn(&builder, customResult.WithdrawnSkipped)
+		writeMarkdownScanned(&builder, customResult.PackagesScanned, nil)
+
+		// Summary
+		builder.WriteString("**Summary:**\n\n")
+		if customResult.Unverified {
+			builder.WriteString("⚠️ **UNVERIFIED — backend unavailable.** Every vulnerability query failed, so no findings doesn't mean no vulnerabilities.\n\n")
+		} else if customResult.Summary.Total == 0 {
+			builder.WriteString("✅ No vulnerabilities found!\n\n")
+		} else {
+			builder.WriteString(fmt.Sprintf("- Total: **%d**\n", customResult.Summary.Total))
+			if customResult.Summary.Critical > 0 {
+				builder.WriteString(fmt.Sprintf("- Critical: **%d** 🔴\n", customResult.Summary.Critical))
+			}
+			if customResult.Summary.High > 0 {
+				builder.WriteString(fmt.Sprintf("- High: **%d** 🟠\n", customResult.Summary.High))
+			}
+			if customResult.Summary.Moderate > 0 {
+				builder.WriteString(fmt.Sprintf("- Moderate: **%d** 🟡\n", customResult.Summary.Moderate))
+			}
+			if customResult.Summary.Low > 0 {
+				builder.WriteString(fmt.Sprintf("- Low: **%d** 🔵\n", customResult.Summary.Low))
+			}
+			builder.WriteString("\n")
+		}
+
+		// Vulnerabilities table
+		if len(customResult.Vulnerabilities) > 0 {
+			builder.WriteString("**Vulnerabilities:**\n\n")
+			builder.WriteString("| Package | Version | Vulnerability ID | Published | Fix Versions |\n")
+			builder.WriteString("|---------|---------|------------------|-----------|-------------|\n")
+
+			for _, vuln := range customResult.Vulnerabilities {
 				fixVersions := strings.Join(vuln.FixVersions, ", ")
 				if len(fixVersions) == 0 {
 					fixVersions = "N/A"
 				}
 
-				builder.WriteString(fmt.Sprintf("| `%s` | `%s` | `%s` | %s |\n",
-					depName, vuln.Version, vuln.ID, fixVersions))
+				builder.WriteString(fmt.Sprintf("| `%s` | `%s` | `%s` | %s | %s |\n",
+					vuln.Name, vuln.Version, vuln.ID, formatAdvisoryDate(vuln.Published), fixVersions))
 			}
 			builder.WriteString("\n")
 		}
 	}
 
-	// Overall summary
+	writeMarkdownSupplyChain(&builder, output.SecurityResults)
+
+	if output.ShowCrossEcosystem {
+		writeMarkdownCrossEcosystem(&builder, CrossEcosystemAdvisories(output))
+	}
+
+	writeMarkdownOverallSummary(&builder, output)
+	writeMarkdownIssues(&builder, collectIssues(output))
+
+	return builder.String(), nil
+}
+
+// writeMarkdownOverallSummary writes the counts across every manifest that
+// close the markdown report
+func writeMarkdownOverallSummary(builder *strings.Builder, output *ScanOutput) {
 	builder.WriteString("## Overall Summary\n\n")
+	builder.WriteString(fmt.Sprintf("**Dependencies Scanned:** %d\n\n", dependenciesScanned(output)))
 	builder.WriteString(fmt.Sprintf("**Total Vulnerabilities:** %d\n\n", output.TotalVulns))
+	if output.TotalVulns > 0 {
+		builder.WriteString(fmt.Sprintf("**Unique Vulnerabilities:** %d (each counted once across manifests)\n\n", UniqueVulnerabilities(output)))
+		totalSummary := AggregateSummary(output)
+		builder.WriteString(fmt.Sprintf("**Direct:** %d, **Transitive:** %d\n\n", totalSummary.Direct, totalSummary.Transitive))
+		if output.SummaryOnly {
+			// The per-manifest summaries that break findings down by severity aren't shown
+			builder.WriteString(fmt.Sprintf("**By Severity:** %s\n\n", severityCounts(totalSummary)))
+		}
+	}
+	writeMarkdownUpgrades(builder, upgradesByEcosystem(output))
+	if output.HiddenUnfixable > 0 {
+		builder.WriteString(fmt.Sprintf("**Hidden (no fix available):** %d\n\n", output.HiddenUnfixable))
+	}
+	if output.Suppressed > 0 {
+		builder.WriteString(fmt.Sprintf("**Suppressed:** %d\n\n", output.Suppressed))
+	}
+	if unverified := UnverifiedEcosystems(output); len(unverified) > 0 {
+		builder.WriteString(fmt.Sprintf("⚠️ **UNVERIFIED (backend unavailable):** %s\n\n", strings.Join(unverified, ", ")))
+	}
 
 	if output.HasErrors {
-		builder.WriteString("⚠️ Some audits encountered errors. See details above.\n")
+		builder.WriteString("⚠️ Some audits encountered errors. See the Errors & Warnings section below.\n")
+	}
+}
+
+// severityCounts lists how many findings a summary has at each severity, such
+// as "Critical: 1, High: 2, Moderate: 0, Low: 4"; info is listed only when
+// there are info findings
+func severityCounts(summary audit.VulnerabilitySummary) string {
+	counts := fmt.Sprintf("Critical: %d, High: %d, Moderate: %d, Low: %d", summary.Critical, summary.High, summary.Moderate, summary.Low)
+	if summary.Info > 0 {
+		counts += fmt.Sprintf(", Info: %d", summary.Info)
 	}
+	return counts
+}
 
-	return builder.String(), nil
+// dependenciesScanned counts the packages audited across every manifest and
+// SBOM. Runtime versions aren't dependencies, so they're left out.
+func dependenciesScanned(output *ScanOutput) int {
+	total := 0
+	for ecosystem, count := range buildScanManifest(output).PackagesQueried {
+		if ecosystem != EcosystemRuntime {
+			total += count
+		}
+	}
+	return total
+}
+
+// npmDependencyCounts returns npm audit's dependency counts by kind, or nil
+// when the package.json was audited through OSV instead
+func npmDependencyCounts(result *audit.AuditResult) *audit.DependencyMetadata {
+	if result.Response == nil {
+		return nil
+	}
+	return &result.Response.Metadata.Dependencies
+}
+
+// formatDependencyCounts renders how many dependencies a manifest's audit
+// checked, such as "142 (prod: 100, dev: 40, optional: 2, peer: 0)", with the
+// breakdown only when npm audit reported one
+func formatDependencyCounts(scanned int, counts *audit.DependencyMetadata) string {
+	if counts == nil {
+		return strconv.Itoa(scanned)
+	}
+	breakdown := fmt.Sprintf("prod: %d, dev: %d, optional: %d, peer: %d", counts.Prod, counts.Dev, counts.Optional, counts.Peer)
+	if counts.PeerOptional > 0 {
+		breakdown += fmt.Sprintf(", peer optional: %d", counts.PeerOptional)
+	}
+	return fmt.Sprintf("%d (%s)", scanned, breakdown)
+}
+
+// writeTableScanned notes how many dependencies a manifest's audit checked,
+// so a clean result can be told apart from one that checked nothing
+func writeTableScanned(builder *strings.Builder, scanned int, counts *audit.DependencyMetadata) {
+	builder.WriteString(fmt.Sprintf("Dependencies scanned: %s\n", formatDependencyCounts(scanned, counts)))
+}
+
+// writeMarkdownScanned notes how many dependencies a manifest's audit checked
+func writeMarkdownScanned(builder *strings.Builder, scanned int, counts *audit.DependencyMetadata) {
+	builder.WriteString(fmt.Sprintf("**Dependencies scanned:** %s\n\n", formatDependencyCounts(scanned, counts)))
+}
+
+// pythonVersionLabel shows a finding's version, or "unpinned" when the
+// package's version isn't pinned and the finding is only possible
+func pythonVersionLabel(vuln audit.PythonVulnerability) string {
+	if vuln.Version == "" && vuln.Unconfirmed {
+		return "unpinned"
+	}
+	return vuln.Version
+}
+
+// countUnconfirmed counts the findings for packages whose version isn't pinned
+func countUnconfirmed(vulns []audit.PythonVulnerability) int {
+	count := 0
+	for _, vuln := range vulns {
+		if vuln.Unconfirmed {
+			count++
+		}
+	}
+	return count
+}
+
+// writeTableWithdrawn notes how many withdrawn advisories were left out of a
+// manifest's findings
+func writeTableWithdrawn(builder *strings.Builder, withdrawn int) {
+	if withdrawn > 0 {
+		builder.WriteString(fmt.Sprintf("Withdrawn advisories not reported: %d\n", withdrawn))
+	}
+}
+
+// writeMarkdownWithdrawn notes how many withdrawn advisories were left out of
+// a manifest's findings
+func writeMarkdownWithdrawn(builder *strings.Builder, withdrawn int) {
+	if withdrawn > 0 {
+		builder.WriteString(fmt.Sprintf("%d withdrawn advisory(ies) not reported\n\n", withdrawn))
+	}
+}
+
+// directLabel shows whether a vulnerable package is a direct dependency
+func directLabel(isDirect bool) string {
+	if isDirect {
+		return "Yes"
+	}
+	return "No"
+}
+
+// npmFixLabel describes the fix npm audit reports for a finding: the upgrade
+// that fixes it, marked when it crosses a major version, or whether npm audit
+// fix resolves it within the declared ranges
+func npmFixLabel(fix audit.FixAvailable) string {
+	switch {
+	case fix.Upgrade() && fix.IsSemVerMajor:
+		return fmt.Sprintf("%s@%s, breaking (major)", fix.Name, fix.Version)
+	case fix.Upgrade():
+		return fmt.Sprintf("%s@%s", fix.Name, fix.Version)
+	case fix.Available:
+		return "npm audit fix"
+	default:
+		return "N/A"
+	}
+}
+
+// formatAdvisoryDate renders an advisory timestamp as a date, or N/A when unknown
+func formatAdvisoryDate(t time.Time) string {
+	if t.IsZero() {
+		return "N/A"
+	}
+	return t.Format(time.DateOnly)
 }
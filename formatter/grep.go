@@ -0,0 +1,59 @@
+package formatter
+
+import (
+	"strings"
+
+	"github.com/brandonapol/snoop/audit"
+)
+
+// GrepFormatter implements one-line-per-finding output for shell pipelines:
+// SEVERITY, ECOSYSTEM, PACKAGE@VERSION, ID, and MANIFEST separated by tabs,
+// with no header and no colors
+type GrepFormatter struct{}
+
+func (f *GrepFormatter) Format(output *ScanOutput) (string, error) {
+	var builder strings.Builder
+	for _, finding := range collectFindings(output) {
+		pkg := finding.pkg
+		if finding.version != "" {
+			pkg += "@" + finding.version
+		}
+		fields := []string{
+			string(grepSeverity(finding.severity)),
+			finding.ecosystem,
+			pkg,
+			finding.id,
+			relativePath(output.Metadata.Directory, finding.manifest),
+		}
+		for i, field := range fields {
+			fields[i] = grepField(field)
+		}
+		builder.WriteString(strings.Join(fields, "\t") + "\n")
+	}
+	return builder.String(), nil
+}
+
+// grepSeverity normalizes a severity the way the audit summaries count it,
+// so filtered line counts match the report's totals
+func grepSeverity(severity string) audit.Severity {
+	switch s := audit.Severity(strings.ToLower(severity)); s {
+	case audit.SeverityCritical, audit.SeverityHigh, audit.SeverityModerate, audit.SeverityLow, audit.SeverityInfo:
+		return s
+	case "medium":
+		return audit.SeverityModerate
+	default:
+		return audit.SeverityHigh
+	}
+}
+
+// grepField keeps a field on one line and within its column, and marks empty
+// fields with "-" so every line has five fields for awk and cut
+func grepField(field string) string {
+	field = strings.Join(strings.FieldsFunc(field, func(r rune) bool {
+		return r == '\t' || r == '\n' || r == '\r'
+	}), " ")
+	if field == "" {
+		return "-"
+	}
+	return field
+}
@@ -0,0 +1,329 @@
+package formatter
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+
+	"github.com/brandonapol/snoop/audit"
+)
+
+// htmlSeverities is the order severity badges are listed in
+var htmlSeverities = []audit.Severity{
+	audit.SeverityCritical,
+	audit.SeverityHigh,
+	audit.SeverityModerate,
+	audit.SeverityLow,
+	audit.SeverityInfo,
+}
+
+// htmlReport is the data the HTML template renders
+type htmlReport struct {
+	Title           string
+	Directory       string
+	ToolName        string
+	ToolVersion     string
+	Generated       string
+	ManifestsFound  int
+	Total           audit.VulnerabilitySummary
+	Unique          int
+	Ecosystems      []htmlEcosystem
+	Suppressed      int
+	HiddenUnfixable int
+	Unverified      []string
+	Manifests       []*htmlManifest
+	SupplyChain     []htmlSupplyChain
+	Issues          []ReportIssue
+}
+
+// htmlEcosystem is one row of the summary table
+type htmlEcosystem struct {
+	Name    string
+	Summary audit.VulnerabilitySummary
+}
+
+// htmlManifest is the collapsible section of one audited manifest
+type htmlManifest struct {
+	Path       string
+	Ecosystem  string
+	Error      string
+	Unverified bool
+	Counts     []htmlCount
+	Findings   []htmlFinding
+}
+
+// htmlCount is a severity badge with the number of findings at that severity
+type htmlCount struct {
+	Severity audit.Severity
+	Count    int
+}
+
+// htmlFinding is one row of a manifest's findings table
+type htmlFinding struct {
+	ID          string
+	URL         string
+	Severity    audit.Severity
+	Package     string
+	Version     string
+	Description string
+	FixVersions string
+	Dependency  string
+}
+
+// htmlSupplyChain is one dependency flagged by the supply chain checks
+type htmlSupplyChain struct {
+	Manifest string
+	Package  string
+	Risk     string
+	Findings []string
+}
+
+// HTMLFormatter implements a self-contained HTML report for sharing by email
+// or as a build artifact: a summary table by ecosystem, then a collapsible
+// section per audited manifest listing its findings with severity badges.
+// Styles are inline so the file needs nothing else to display. Every value
+// taken from manifests or advisories is escaped by html/template.
+type HTMLFormatter struct{}
+
+func (f *HTMLFormatter) Format(output *ScanOutput) (string, error) {
+	var builder strings.Builder
+	if err := htmlTemplate.Execute(&builder, buildHTMLReport(output)); err != nil {
+		return "", fmt.Errorf("failed to render HTML report: %w", err)
+	}
+	return builder.String(), nil
+}
+
+// buildHTMLReport gathers what the HTML report shows from the scan output
+func buildHTMLReport(output *ScanOutput) htmlReport {
+	report := htmlReport{
+		Title:           "Security Report",
+		Directory:       output.Metadata.Directory,
+		ToolName:        output.Metadata.ToolName,
+		ToolVersion:     output.Metadata.ToolVersion,
+		Total:           AggregateSummary(output),
+		Unique:          UniqueVulnerabilities(output),
+		Suppressed:      output.Suppressed,
+		HiddenUnfixable: output.HiddenUnfixable,
+		Unverified:      UnverifiedEcosystems(output),
+		Issues:          collectIssues(output),
+	}
+	if report.ToolName == "" {
+		report.ToolName = "snoop"
+	}
+	if !output.Metadata.Timestamp.IsZero() {
+		report.Generated = output.Metadata.Timestamp.Format(time.RFC1123)
+	}
+	if output.ScanResults != nil {
+		report.ManifestsFound = len(output.ScanResults.Files)
+	}
+
+	summaries := summaryByEcosystem(output)
+	for _, ecosystem := range ecosystemOrder {
+		if summary, ok := summaries[ecosystem]; ok {
+			report.Ecosystems = append(report.Ecosystems, htmlEcosystem{Name: ecosystem, Summary: summary})
+		}
+	}
+
+	manifests := make(map[string]*htmlManifest)
+	manifest := func(path, ecosystem string) *htmlManifest {
+		key := ecosystem + "\x00" + path
+		if m, ok := manifests[key]; ok {
+			return m
+		}
+		m := &htmlManifest{Path: relativePath(output.Metadata.Directory, path), Ecosystem: ecosystem}
+		manifests[key] = m
+		report.Manifests = append(report.Manifests, m)
+		return m
+	}
+
+	for _, audited := range auditedManifests(output) {
+		m := manifest(audited.path, audited.ecosystem)
+		if audited.err != nil {
+			m.Error = audited.err.Error()
+		}
+		m.Unverified = audited.unverified
+	}
+
+	for _, finding := range collectFindings(output) {
+		m := manifest(finding.manifest, finding.ecosystem)
+		m.Findings = append(m.Findings, htmlFinding{
+			ID:          finding.id,
+			URL:         finding.helpURI,
+			Severity:    grepSeverity(finding.severity),
+			Package:     finding.pkg,
+			Version:     finding.version,
+			Description: finding.description,
+			FixVersions: strings.Join(finding.fixVersions, ", "),
+			Dependency:  finding.dependency,
+		})
+	}
+
+	for _, m := range report.Manifests {
+		for _, severity := range htmlSeverities {
+			count := 0
+			for _, finding := range m.Findings {
+				if finding.Severity == severity {
+					count++
+				}
+			}
+			if count > 0 {
+				m.Counts = append(m.Counts, htmlCount{Severity: severity, Count: count})
+			}
+		}
+	}
+
+	for _, result := range output.SecurityResults {
+		for _, dependency := range result.Reports {
+			report.SupplyChain = append(report.SupplyChain, htmlSupplyChain{
+				Manifest: relativePath(output.Metadata.Directory, result.PackageJSONPath),
+				Package:  dependency.PackageName,
+				Risk:     dependency.OverallRiskLevel,
+				Findings: describeSecurityReport(dependency),
+			})
+		}
+	}
+
+	return report
+}
+
+// htmlTemplate renders the HTML report. Manifests with findings start
+// expanded; the rest can be opened to confirm they were audited.
+var htmlTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}{{if .Directory}} - {{.Directory}}{{end}}</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; color: #1f2328; background: #f6f8fa; margin: 0; padding: 24px; }
+main { max-width: 1100px; margin: 0 auto; }
+h1 { margin: 0 0 4px; font-size: 28px; }
+h2 { margin: 32px 0 12px; font-size: 20px; }
+.meta { color: #59636e; margin: 0 0 24px; font-size: 14px; }
+.card { background: #fff; border: 1px solid #d1d9e0; border-radius: 8px; padding: 16px; margin-bottom: 12px; }
+table { border-collapse: collapse; width: 100%; font-size: 14px; }
+th, td { text-align: left; padding: 8px; border-bottom: 1px solid #d1d9e0; vertical-align: top; }
+th { background: #f6f8fa; font-weight: 600; }
+td.num, th.num { text-align: right; }
+tr.total td { font-weight: 600; }
+.badge { display: inline-block; padding: 2px 8px; border-radius: 12px; font-size: 12px; font-weight: 600; color: #fff; white-space: nowrap; }
+.critical { background: #8b0000; }
+.high { background: #d1242f; }
+.moderate, .medium { background: #bc4c00; }
+.low { background: #9a6700; }
+.info { background: #59636e; }
+.clean { background: #1a7f37; }
+.warning { background: #9a6700; }
+.error { background: #d1242f; }
+details { background: #fff; border: 1px solid #d1d9e0; border-radius: 8px; margin-bottom: 12px; }
+summary { cursor: pointer; padding: 12px 16px; font-weight: 600; }
+summary .badge { margin-left: 6px; }
+details > div { padding: 0 16px 16px; }
+.ecosystem { color: #59636e; font-weight: 400; margin-left: 6px; }
+.description { color: #59636e; }
+.notice { color: #59636e; font-size: 14px; }
+code { font-family: SFMono-Regular, Consolas, "Liberation Mono", Menlo, monospace; font-size: 13px; }
+a { color: #0969da; }
+</style>
+</head>
+<body>
+<main>
+<h1>{{.Title}}</h1>
+<p class="meta">{{.ToolName}}{{if .ToolVersion}} v{{.ToolVersion}}{{end}}{{if .Directory}} &middot; <code>{{.Directory}}</code>{{end}}{{if .Generated}} &middot; {{.Generated}}{{end}}</p>
+
+<div class="card">
+<p>{{if .Total.Total}}<strong>{{.Total.Total}}</strong> vulnerabilities found{{else}}<span class="badge clean">No vulnerabilities found</span>{{end}}</p>
+<p class="notice">Manifests scanned: {{.ManifestsFound}}</p>
+{{- if .Total.Total}}
+<p class="notice">Unique vulnerabilities: {{.Unique}}, each counted once across manifests.</p>
+{{- end}}
+{{- if .Suppressed}}
+<p class="notice">{{.Suppressed}} suppressed by the ignore file.</p>
+{{- end}}
+{{- if .HiddenUnfixable}}
+<p class="notice">{{.HiddenUnfixable}} without a published fix are hidden.</p>
+{{- end}}
+{{- if .Unverified}}
+<p class="notice"><span class="badge warning">Unverified</span> The vulnerability backend was unavailable for: {{range $i, $e := .Unverified}}{{if $i}}, {{end}}{{$e}}{{end}}.</p>
+{{- end}}
+<table>
+<thead><tr><th>Ecosystem</th><th class="num">Critical</th><th class="num">High</th><th class="num">Moderate</th><th class="num">Low</th><th class="num">Info</th><th class="num">Total</th></tr></thead>
+<tbody>
+{{- range .Ecosystems}}
+<tr><td>{{.Name}}</td><td class="num">{{.Summary.Critical}}</td><td class="num">{{.Summary.High}}</td><td class="num">{{.Summary.Moderate}}</td><td class="num">{{.Summary.Low}}</td><td class="num">{{.Summary.Info}}</td><td class="num">{{.Summary.Total}}</td></tr>
+{{- end}}
+<tr class="total"><td>Total</td><td class="num">{{.Total.Critical}}</td><td class="num">{{.Total.High}}</td><td class="num">{{.Total.Moderate}}</td><td class="num">{{.Total.Low}}</td><td class="num">{{.Total.Info}}</td><td class="num">{{.Total.Total}}</td></tr>
+</tbody>
+</table>
+</div>
+
+{{- if .Manifests}}
+<h2>Manifests</h2>
+{{- range .Manifests}}
+<details{{if or .Findings .Error}} open{{end}}>
+<summary>{{.Path}}<span class="ecosystem">{{.Ecosystem}}</span>
+{{- if .Error}}<span class="badge error">Error</span>
+{{- else if .Unverified}}<span class="badge warning">Unverified</span>
+{{- else if not .Findings}}<span class="badge clean">No vulnerabilities</span>
+{{- end}}
+{{- range .Counts}}<span class="badge {{.Severity}}">{{.Count}} {{.Severity}}</span>{{end}}</summary>
+<div>
+{{- if .Error}}
+<p>{{.Error}}</p>
+{{- end}}
+{{- if .Findings}}
+<table>
+<thead><tr><th>Severity</th><th>Package</th><th>Version</th><th>Advisory</th><th>Fixed In</th><th>Dependency</th></tr></thead>
+<tbody>
+{{- range .Findings}}
+<tr>
+<td><span class="badge {{.Severity}}">{{.Severity}}</span></td>
+<td><code>{{.Package}}</code></td>
+<td>{{.Version}}</td>
+<td>{{if .URL}}<a href="{{.URL}}">{{.ID}}</a>{{else}}{{.ID}}{{end}}{{if .Description}}<br><span class="description">{{.Description}}</span>{{end}}</td>
+<td>{{.FixVersions}}</td>
+<td>{{.Dependency}}</td>
+</tr>
+{{- end}}
+</tbody>
+</table>
+{{- else if not .Error}}
+<p class="notice">No known vulnerabilities.</p>
+{{- end}}
+</div>
+</details>
+{{- end}}
+{{- end}}
+
+{{- if .SupplyChain}}
+<h2>Supply Chain Checks</h2>
+<div class="card">
+<table>
+<thead><tr><th>Risk</th><th>Package</th><th>Manifest</th><th>Findings</th></tr></thead>
+<tbody>
+{{- range .SupplyChain}}
+<tr><td><span class="badge {{.Risk}}">{{.Risk}}</span></td><td><code>{{.Package}}</code></td><td>{{.Manifest}}</td><td>{{range $i, $e := .Findings}}{{if $i}}<br>{{end}}{{$e}}{{end}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+</div>
+{{- end}}
+
+{{- if .Issues}}
+<h2>Errors and Warnings</h2>
+<div class="card">
+<table>
+<thead><tr><th>Level</th><th>Source</th><th>Message</th></tr></thead>
+<tbody>
+{{- range .Issues}}
+<tr><td><span class="badge {{.Level}}">{{.Level}}</span></td><td>{{.Source}}</td><td>{{.Message}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+</div>
+{{- end}}
+</main>
+</body>
+</html>
+`))
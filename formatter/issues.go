@@ -0,0 +1,128 @@
+package formatter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// IssueLevel distinguishes failures from non-fatal problems
+type IssueLevel string
+
+const (
+	IssueError   IssueLevel = "error"
+	IssueWarning IssueLevel = "warning"
+)
+
+// ReportIssue is a single error or warning raised anywhere during the run
+type ReportIssue struct {
+	Level   IssueLevel `json:"level"`
+	Source  string     `json:"source"`
+	Message string     `json:"message"`
+}
+
+// collectIssues gathers scan errors, audit errors, and query/parse warnings
+// from every ecosystem into a single list
+func collectIssues(output *ScanOutput) []ReportIssue {
+	issues := make([]ReportIssue, 0)
+
+	if output.ScanResults != nil {
+		for _, err := range output.ScanResults.Errors {
+			issues = append(issues, ReportIssue{Level: IssueError, Source: "scan", Message: err.Error()})
+		}
+		for _, warning := range output.ScanResults.Warnings {
+			issues = append(issues, ReportIssue{Level: IssueWarning, Source: "scan", Message: warning})
+		}
+	}
+
+	add := func(source string, err error, warnings []string) {
+		if err != nil {
+			issues = append(issues, ReportIssue{Level: IssueError, Source: source, Message: err.Error()})
+		}
+		for _, warning := range warnings {
+			issues = append(issues, ReportIssue{Level: IssueWarning, Source: source, Message: warning})
+		}
+	}
+
+	for _, result := range output.AuditResults {
+		add(result.PackageJSONPath, result.Error, result.Warnings)
+	}
+	for _, result := range output.PythonAuditResults {
+		add(result.ManifestPath, result.Error, result.Warnings)
+	}
+	for _, result := range output.GoAuditResults {
+		add(result.ManifestPath, result.Error, result.Warnings)
+	}
+	for _, result := range output.MavenAuditResults {
+		add(result.ManifestPath, result.Error, result.Warnings)
+	}
+	for _, result := range output.SwiftAuditResults {
+		add(result.ManifestPath, result.Error, result.Warnings)
+	}
+	for _, result := range output.CargoAuditResults {
+		add(result.ManifestPath, result.Error, result.Warnings)
+	}
+	for _, result := range output.RubyAuditResults {
+		add(result.ManifestPath, result.Error, result.Warnings)
+	}
+	for _, result := range output.ComposerAuditResults {
+		add(result.ManifestPath, result.Error, result.Warnings)
+	}
+	for _, result := range output.RuntimeAuditResults {
+		add(result.ManifestPath, result.Error, result.Warnings)
+	}
+	for _, result := range output.SBOMAuditResults {
+		add(result.SBOMPath, result.Error, result.Warnings)
+	}
+	for _, result := range output.CustomAuditResults {
+		add(result.ManifestPath, result.Error, result.Warnings)
+	}
+	for _, result := range output.SecurityResults {
+		add(result.PackageJSONPath, nil, result.Warnings)
+	}
+
+	return issues
+}
+
+// countIssues returns the number of errors and warnings in issues
+func countIssues(issues []ReportIssue) (errors, warnings int) {
+	for _, issue := range issues {
+		if issue.Level == IssueError {
+			errors++
+		} else {
+			warnings++
+		}
+	}
+	return errors, warnings
+}
+
+// writeTableIssues writes the consolidated "Errors & Warnings" table section
+func writeTableIssues(builder *strings.Builder, issues []ReportIssue) {
+	if len(issues) == 0 {
+		return
+	}
+
+	errors, warnings := countIssues(issues)
+	builder.WriteString(fmt.Sprintf("\nErrors & Warnings (%d errors, %d warnings)\n", errors, warnings))
+	builder.WriteString(strings.Repeat("-", 80) + "\n")
+	for _, issue := range issues {
+		builder.WriteString(fmt.Sprintf("  [%s] %s: %s\n", issue.Level, issue.Source, issue.Message))
+	}
+}
+
+// writeMarkdownIssues writes the consolidated "Errors & Warnings" markdown section
+func writeMarkdownIssues(builder *strings.Builder, issues []ReportIssue) {
+	if len(issues) == 0 {
+		return
+	}
+
+	errors, warnings := countIssues(issues)
+	builder.WriteString("\n## Errors & Warnings\n\n")
+	builder.WriteString(fmt.Sprintf("**%d** error(s), **%d** warning(s)\n\n", errors, warnings))
+	for _, issue := range issues {
+		icon := "⚠️"
+		if issue.Level == IssueError {
+			icon = "❌"
+		}
+		builder.WriteString(fmt.Sprintf("- %s `%s`: %s\n", icon, issue.Source, issue.Message))
+	}
+}
@@ -0,0 +1,192 @@
+package formatter
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// junitTestSuites is the root of a JUnit XML report
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+// junitTestSuite holds the test cases of one manifest file
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Errors   int             `xml:"errors,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+}
+
+// junitProblem is the body of a failure or error element
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// junitCleanCase names the passing test case of a manifest with no findings
+const junitCleanCase = "no known vulnerabilities"
+
+// JUnitFormatter implements JUnit XML output for CI systems such as Jenkins
+// and GitLab. Each audited manifest is a test suite and each finding a
+// failing test case named by its advisory ID; a manifest without findings
+// has one passing case, and a failed or unverified audit an erroring one.
+type JUnitFormatter struct{}
+
+func (f *JUnitFormatter) Format(output *ScanOutput) (string, error) {
+	report := junitTestSuites{Name: output.Metadata.ToolName, Suites: make([]junitTestSuite, 0)}
+	if report.Name == "" {
+		report.Name = "snoop"
+	}
+
+	suites := make(map[string]*junitTestSuite)
+	var order []string
+	suite := func(manifest string) *junitTestSuite {
+		name := relativePath(output.Metadata.Directory, manifest)
+		if s, ok := suites[name]; ok {
+			return s
+		}
+		suites[name] = &junitTestSuite{Name: name}
+		order = append(order, name)
+		return suites[name]
+	}
+
+	for _, audited := range auditedManifests(output) {
+		s := suite(audited.path)
+		switch {
+		case audited.err != nil:
+			s.Cases = append(s.Cases, junitTestCase{
+				Name:      "audit",
+				Classname: audited.ecosystem,
+				Error:     &junitProblem{Message: audited.err.Error(), Type: "error", Text: audited.err.Error()},
+			})
+		case audited.unverified:
+			message := "vulnerability backend unavailable; results not verified"
+			s.Cases = append(s.Cases, junitTestCase{
+				Name:      "audit",
+				Classname: audited.ecosystem,
+				Error:     &junitProblem{Message: message, Type: "unverified", Text: message},
+			})
+		}
+	}
+
+	for _, finding := range collectFindings(output) {
+		pkg := finding.pkg
+		if finding.version != "" {
+			pkg += "@" + finding.version
+		}
+		message := finding.description
+		if message == "" {
+			message = fmt.Sprintf("%s is affected by %s", pkg, finding.id)
+		}
+
+		details := []string{
+			"Package: " + pkg,
+			"Ecosystem: " + finding.ecosystem,
+			"Severity: " + finding.severity,
+		}
+		if len(finding.fixVersions) > 0 {
+			details = append(details, "Fixed in: "+strings.Join(finding.fixVersions, ", "))
+		}
+		if finding.helpURI != "" {
+			details = append(details, "More info: "+finding.helpURI)
+		}
+
+		s := suite(finding.manifest)
+		s.Cases = append(s.Cases, junitTestCase{
+			Name:      finding.id,
+			Classname: finding.ecosystem + "." + pkg,
+			Failure:   &junitProblem{Message: message, Type: finding.severity, Text: strings.Join(details, "\n")},
+		})
+	}
+
+	for _, name := range order {
+		s := suites[name]
+		// A manifest the audit read without problems or findings shows green
+		if len(s.Cases) == 0 {
+			s.Cases = append(s.Cases, junitTestCase{Name: junitCleanCase, Classname: name})
+		}
+		for _, c := range s.Cases {
+			if c.Failure != nil {
+				s.Failures++
+			}
+			if c.Error != nil {
+				s.Errors++
+			}
+		}
+		s.Tests = len(s.Cases)
+
+		report.Tests += s.Tests
+		report.Failures += s.Failures
+		report.Errors += s.Errors
+		report.Suites = append(report.Suites, *s)
+	}
+
+	data, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal JUnit XML: %w", err)
+	}
+	return xml.Header + string(data) + "\n", nil
+}
+
+// auditedManifest is one manifest an audit ran on, with how the audit went
+type auditedManifest struct {
+	path       string
+	ecosystem  string
+	err        error
+	unverified bool
+}
+
+// auditedManifests lists every manifest that was audited, in report order
+func auditedManifests(output *ScanOutput) []auditedManifest {
+	var manifests []auditedManifest
+	for _, result := range output.AuditResults {
+		manifests = append(manifests, auditedManifest{result.PackageJSONPath, EcosystemNpm, result.Error, result.Unverified})
+	}
+	for _, result := range output.PythonAuditResults {
+		manifests = append(manifests, auditedManifest{result.ManifestPath, EcosystemPython, result.Error, result.Unverified})
+	}
+	for _, result := range output.GoAuditResults {
+		manifests = append(manifests, auditedManifest{result.ManifestPath, EcosystemGo, result.Error, result.Unverified})
+	}
+	for _, result := range output.MavenAuditResults {
+		manifests = append(manifests, auditedManifest{result.ManifestPath, EcosystemMaven, result.Error, result.Unverified})
+	}
+	for _, result := range output.SwiftAuditResults {
+		manifests = append(manifests, auditedManifest{result.ManifestPath, EcosystemSwift, result.Error, result.Unverified})
+	}
+	for _, result := range output.CargoAuditResults {
+		manifests = append(manifests, auditedManifest{result.ManifestPath, EcosystemCargo, result.Error, result.Unverified})
+	}
+	for _, result := range output.RubyAuditResults {
+		manifests = append(manifests, auditedManifest{result.ManifestPath, EcosystemRuby, result.Error, result.Unverified})
+	}
+	for _, result := range output.ComposerAuditResults {
+		manifests = append(manifests, auditedManifest{result.ManifestPath, EcosystemPHP, result.Error, result.Unverified})
+	}
+	for _, result := range output.RuntimeAuditResults {
+		manifests = append(manifests, auditedManifest{result.ManifestPath, EcosystemRuntime, result.Error, result.Unverified})
+	}
+	for _, result := range output.SBOMAuditResults {
+		manifests = append(manifests, auditedManifest{result.SBOMPath, EcosystemSBOM, result.Error, result.Unverified})
+	}
+	for _, result := range output.CustomAuditResults {
+		manifests = append(manifests, auditedManifest{result.ManifestPath, EcosystemCustom, result.Error, result.Unverified})
+	}
+	return manifests
+}
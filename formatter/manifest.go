@@ -0,0 +1,161 @@
+package formatter
+
+import (
+	"sort"
+
+	"github.com/brandonapol/snoop/scanner"
+)
+
+// Statuses of a file in the scan manifest
+const (
+	FileAudited = "audited"
+	FileSkipped = "skipped"
+	FileError   = "error"
+)
+
+// skipReasons explains why a detected file wasn't audited on its own
+var skipReasons = map[scanner.ManifestType]string{
+	scanner.PackageLockJSON: "lockfile; resolved through npm audit of package.json",
+	scanner.YarnLock:        "lockfile; detection only",
+	scanner.PnpmLockYAML:    "lockfile; detection only",
+	scanner.Pipfile:         "audited via Pipfile.lock",
+	scanner.PyprojectTOML:   "audited via poetry.lock",
+	scanner.GoSum:           "checksums only; audited via go.mod",
+	scanner.CargoToml:       "audited via Cargo.lock",
+	scanner.Nvmrc:           "runtime checks not enabled (--runtime)",
+	scanner.PythonVersion:   "runtime checks not enabled (--runtime)",
+	scanner.ToolVersions:    "runtime checks not enabled (--runtime)",
+}
+
+// ScanBackend describes a vulnerability data source used during the scan
+type ScanBackend struct {
+	Name     string `json:"name"`
+	Version  string `json:"version,omitempty"`
+	Endpoint string `json:"endpoint,omitempty"`
+}
+
+// ScanManifestFile records what happened to one input file
+type ScanManifestFile struct {
+	Path   string `json:"path"`
+	Type   string `json:"type"`
+	Status string `json:"status"`           // One of the File* statuses
+	Reason string `json:"reason,omitempty"` // Why the file was skipped or failed
+}
+
+// ScanManifest is an audit trail of exactly what a scan inspected
+type ScanManifest struct {
+	Files           []ScanManifestFile `json:"files"`
+	Backends        []ScanBackend      `json:"backends"`
+	Flags           map[string]string  `json:"flags"`
+	PackagesQueried map[string]int     `json:"packagesQueried"` // Keyed by the Ecosystem* constants
+}
+
+// buildScanManifest lists every input file with its status, along with the
+// backends, flags, and package counts recorded on the output
+func buildScanManifest(output *ScanOutput) ScanManifest {
+	manifest := ScanManifest{
+		Files:           make([]ScanManifestFile, 0),
+		Backends:        output.Backends,
+		Flags:           output.Flags,
+		PackagesQueried: make(map[string]int),
+	}
+	if manifest.Backends == nil {
+		manifest.Backends = make([]ScanBackend, 0)
+	}
+	if manifest.Flags == nil {
+		manifest.Flags = make(map[string]string)
+	}
+
+	// A file may be audited more than once (go.mod also declares the Go
+	// toolchain), and any failed audit marks it as an error
+	audited := make(map[string]error)
+	record := func(path string, err error) {
+		if previous, ok := audited[path]; !ok || previous == nil {
+			audited[path] = err
+		}
+	}
+	count := func(ecosystem string, packages int) {
+		manifest.PackagesQueried[ecosystem] += packages
+	}
+
+	for _, result := range output.AuditResults {
+		record(result.PackageJSONPath, result.Error)
+		for _, member := range result.Workspaces {
+			record(member, result.Error)
+		}
+		count(EcosystemNpm, result.PackagesScanned)
+	}
+	for _, result := range output.PythonAuditResults {
+		record(result.ManifestPath, result.Error)
+		count(EcosystemPython, result.PackagesScanned)
+	}
+	for _, result := range output.GoAuditResults {
+		record(result.ManifestPath, result.Error)
+		for _, manifest := range result.Manifests {
+			record(manifest, result.Error)
+		}
+		count(EcosystemGo, result.ModulesScanned)
+	}
+	for _, result := range output.MavenAuditResults {
+		record(result.ManifestPath, result.Error)
+		count(EcosystemMaven, result.PackagesScanned)
+	}
+	for _, result := range output.SwiftAuditResults {
+		record(result.ManifestPath, result.Error)
+		count(EcosystemSwift, result.PackagesScanned)
+	}
+	for _, result := range output.CargoAuditResults {
+		record(result.ManifestPath, result.Error)
+		count(EcosystemCargo, result.PackagesScanned)
+	}
+	for _, result := range output.RubyAuditResults {
+		record(result.ManifestPath, result.Error)
+		count(EcosystemRuby, result.PackagesScanned)
+	}
+	for _, result := range output.ComposerAuditResults {
+		record(result.ManifestPath, result.Error)
+		count(EcosystemPHP, result.PackagesScanned)
+	}
+	for _, result := range output.RuntimeAuditResults {
+		record(result.ManifestPath, result.Error)
+		count(EcosystemRuntime, len(result.Runtimes))
+	}
+	for _, result := range output.CustomAuditResults {
+		record(result.ManifestPath, result.Error)
+		count(EcosystemCustom, result.PackagesScanned)
+	}
+
+	if output.ScanResults != nil {
+		for _, file := range output.ScanResults.Files {
+			entry := ScanManifestFile{Path: file.Path, Type: string(file.Type), Status: FileAudited}
+			if err, ok := audited[file.Path]; !ok {
+				entry.Status = FileSkipped
+				entry.Reason = skipReasons[file.Type]
+				if entry.Reason == "" {
+					entry.Reason = "no audit ran for this file"
+				}
+			} else if err != nil {
+				entry.Status = FileError
+				entry.Reason = err.Error()
+			}
+			manifest.Files = append(manifest.Files, entry)
+		}
+	}
+
+	// An SBOM is read directly rather than found by the scanner
+	for _, result := range output.SBOMAuditResults {
+		entry := ScanManifestFile{Path: result.SBOMPath, Type: "sbom", Status: FileAudited}
+		if result.Error != nil {
+			entry.Status = FileError
+			entry.Reason = result.Error.Error()
+		}
+		manifest.Files = append(manifest.Files, entry)
+		count(EcosystemSBOM, result.ComponentsScanned)
+	}
+
+	sort.SliceStable(manifest.Files, func(i, j int) bool {
+		return manifest.Files[i].Path < manifest.Files[j].Path
+	})
+
+	return manifest
+}
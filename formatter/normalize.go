@@ -0,0 +1,233 @@
+package formatter
+
+import (
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/brandonapol/snoop/scanner"
+)
+
+// Normalize rewrites the scan output so that identical inputs always produce
+// byte-identical reports. Volatile fields such as the timestamp are cleared,
+// paths are made relative to the scanned directory, and every list is sorted.
+func Normalize(output *ScanOutput) {
+	root := output.Metadata.Directory
+
+	output.Metadata.Timestamp = time.Time{}
+	output.Metadata.Directory = "."
+	if _, ok := output.Flags["path"]; ok {
+		output.Flags["path"] = "."
+	}
+
+	if output.ScanResults != nil {
+		for i := range output.ScanResults.Files {
+			output.ScanResults.Files[i].Path = relativePath(root, output.ScanResults.Files[i].Path)
+		}
+		sort.SliceStable(output.ScanResults.Files, func(i, j int) bool {
+			return lessDetectedFile(output.ScanResults.Files[i], output.ScanResults.Files[j])
+		})
+	}
+
+	for _, result := range output.AuditResults {
+		result.PackageJSONPath = relativePath(root, result.PackageJSONPath)
+		for i := range result.Workspaces {
+			result.Workspaces[i] = relativePath(root, result.Workspaces[i])
+		}
+		sort.SliceStable(result.Vulnerabilities, func(i, j int) bool {
+			return result.Vulnerabilities[i].Name < result.Vulnerabilities[j].Name
+		})
+	}
+	sort.SliceStable(output.AuditResults, func(i, j int) bool {
+		return output.AuditResults[i].PackageJSONPath < output.AuditResults[j].PackageJSONPath
+	})
+
+	for _, result := range output.PythonAuditResults {
+		result.ManifestPath = relativePath(root, result.ManifestPath)
+		sort.SliceStable(result.Vulnerabilities, func(i, j int) bool {
+			a, b := result.Vulnerabilities[i], result.Vulnerabilities[j]
+			if a.Name != b.Name {
+				return a.Name < b.Name
+			}
+			return a.ID < b.ID
+		})
+	}
+	sort.SliceStable(output.PythonAuditResults, func(i, j int) bool {
+		return output.PythonAuditResults[i].ManifestPath < output.PythonAuditResults[j].ManifestPath
+	})
+
+	for _, result := range output.GoAuditResults {
+		result.ManifestPath = relativePath(root, result.ManifestPath)
+		for i := range result.Manifests {
+			result.Manifests[i] = relativePath(root, result.Manifests[i])
+		}
+		for i := range result.Vendored {
+			result.Vendored[i] = relativePath(root, result.Vendored[i])
+		}
+		for _, vuln := range result.Vulnerabilities {
+			for i := range vuln.Manifests {
+				vuln.Manifests[i] = relativePath(root, vuln.Manifests[i])
+			}
+		}
+		sort.SliceStable(result.Vulnerabilities, func(i, j int) bool {
+			a, b := result.Vulnerabilities[i], result.Vulnerabilities[j]
+			if a.Module != b.Module {
+				return a.Module < b.Module
+			}
+			return a.ID < b.ID
+		})
+	}
+	sort.SliceStable(output.GoAuditResults, func(i, j int) bool {
+		return output.GoAuditResults[i].ManifestPath < output.GoAuditResults[j].ManifestPath
+	})
+
+	for _, result := range output.MavenAuditResults {
+		result.ManifestPath = relativePath(root, result.ManifestPath)
+		sort.SliceStable(result.Vulnerabilities, func(i, j int) bool {
+			a, b := result.Vulnerabilities[i], result.Vulnerabilities[j]
+			if a.GroupID != b.GroupID {
+				return a.GroupID < b.GroupID
+			}
+			if a.ArtifactID != b.ArtifactID {
+				return a.ArtifactID < b.ArtifactID
+			}
+			if a.ArtifactName() != b.ArtifactName() {
+				return a.ArtifactName() < b.ArtifactName()
+			}
+			return a.ID < b.ID
+		})
+	}
+	sort.SliceStable(output.MavenAuditResults, func(i, j int) bool {
+		return output.MavenAuditResults[i].ManifestPath < output.MavenAuditResults[j].ManifestPath
+	})
+
+	for _, result := range output.SwiftAuditResults {
+		result.ManifestPath = relativePath(root, result.ManifestPath)
+		sort.SliceStable(result.Vulnerabilities, func(i, j int) bool {
+			a, b := result.Vulnerabilities[i], result.Vulnerabilities[j]
+			if a.Name != b.Name {
+				return a.Name < b.Name
+			}
+			return a.ID < b.ID
+		})
+	}
+	sort.SliceStable(output.SwiftAuditResults, func(i, j int) bool {
+		return output.SwiftAuditResults[i].ManifestPath < output.SwiftAuditResults[j].ManifestPath
+	})
+
+	for _, result := range output.CargoAuditResults {
+		result.ManifestPath = relativePath(root, result.ManifestPath)
+		sort.SliceStable(result.Vulnerabilities, func(i, j int) bool {
+			a, b := result.Vulnerabilities[i], result.Vulnerabilities[j]
+			if a.Name != b.Name {
+				return a.Name < b.Name
+			}
+			return a.ID < b.ID
+		})
+	}
+	sort.SliceStable(output.CargoAuditResults, func(i, j int) bool {
+		return output.CargoAuditResults[i].ManifestPath < output.CargoAuditResults[j].ManifestPath
+	})
+
+	for _, result := range output.RubyAuditResults {
+		result.ManifestPath = relativePath(root, result.ManifestPath)
+		sort.SliceStable(result.Vulnerabilities, func(i, j int) bool {
+			a, b := result.Vulnerabilities[i], result.Vulnerabilities[j]
+			if a.Name != b.Name {
+				return a.Name < b.Name
+			}
+			return a.ID < b.ID
+		})
+	}
+	sort.SliceStable(output.RubyAuditResults, func(i, j int) bool {
+		return output.RubyAuditResults[i].ManifestPath < output.RubyAuditResults[j].ManifestPath
+	})
+
+	for _, result := range output.ComposerAuditResults {
+		result.ManifestPath = relativePath(root, result.ManifestPath)
+		sort.SliceStable(result.Vulnerabilities, func(i, j int) bool {
+			a, b := result.Vulnerabilities[i], result.Vulnerabilities[j]
+			if a.Name != b.Name {
+				return a.Name < b.Name
+			}
+			return a.ID < b.ID
+		})
+	}
+	sort.SliceStable(output.ComposerAuditResults, func(i, j int) bool {
+		return output.ComposerAuditResults[i].ManifestPath < output.ComposerAuditResults[j].ManifestPath
+	})
+
+	for _, result := range output.RuntimeAuditResults {
+		result.ManifestPath = relativePath(root, result.ManifestPath)
+		sort.SliceStable(result.Vulnerabilities, func(i, j int) bool {
+			a, b := result.Vulnerabilities[i], result.Vulnerabilities[j]
+			if a.Runtime != b.Runtime {
+				return a.Runtime < b.Runtime
+			}
+			return a.ID < b.ID
+		})
+	}
+	sort.SliceStable(output.RuntimeAuditResults, func(i, j int) bool {
+		return output.RuntimeAuditResults[i].ManifestPath < output.RuntimeAuditResults[j].ManifestPath
+	})
+
+	for _, result := range output.SBOMAuditResults {
+		result.SBOMPath = relativePath(root, result.SBOMPath)
+		sort.SliceStable(result.Vulnerabilities, func(i, j int) bool {
+			a, b := result.Vulnerabilities[i], result.Vulnerabilities[j]
+			if a.Ecosystem != b.Ecosystem {
+				return a.Ecosystem < b.Ecosystem
+			}
+			if a.Name != b.Name {
+				return a.Name < b.Name
+			}
+			return a.ID < b.ID
+		})
+	}
+	sort.SliceStable(output.SBOMAuditResults, func(i, j int) bool {
+		return output.SBOMAuditResults[i].SBOMPath < output.SBOMAuditResults[j].SBOMPath
+	})
+
+	for _, result := range output.CustomAuditResults {
+		result.ManifestPath = relativePath(root, result.ManifestPath)
+		sort.SliceStable(result.Vulnerabilities, func(i, j int) bool {
+			a, b := result.Vulnerabilities[i], result.Vulnerabilities[j]
+			if a.Name != b.Name {
+				return a.Name < b.Name
+			}
+			return a.ID < b.ID
+		})
+	}
+	sort.SliceStable(output.CustomAuditResults, func(i, j int) bool {
+		return output.CustomAuditResults[i].ManifestPath < output.CustomAuditResults[j].ManifestPath
+	})
+
+	// Findings within a report are already sorted by package name
+	for _, result := range output.SecurityResults {
+		result.PackageJSONPath = relativePath(root, result.PackageJSONPath)
+	}
+	sort.SliceStable(output.SecurityResults, func(i, j int) bool {
+		return output.SecurityResults[i].PackageJSONPath < output.SecurityResults[j].PackageJSONPath
+	})
+}
+
+// relativePath returns path relative to root, using forward slashes so the
+// report is identical across platforms
+func relativePath(root, path string) string {
+	if root == "" {
+		return filepath.ToSlash(path)
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// lessDetectedFile orders detected files by path, then manifest type
+func lessDetectedFile(a, b scanner.DetectedFile) bool {
+	if a.Path != b.Path {
+		return a.Path < b.Path
+	}
+	return a.Type < b.Type
+}
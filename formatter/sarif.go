@@ -0,0 +1,189 @@
+package formatter
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/brandonapol/snoop/audit"
+)
+
+// SARIF 2.1.0 identifiers, as expected by GitHub code scanning
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+// sarifLog is the root of a SARIF document
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri,omitempty"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string              `json:"id"`
+	ShortDescription     sarifMessage        `json:"shortDescription"`
+	HelpURI              string              `json:"helpUri,omitempty"`
+	DefaultConfiguration sarifConfiguration  `json:"defaultConfiguration"`
+	Properties           sarifRuleProperties `json:"properties"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+// sarifRuleProperties carries the properties GitHub reads to rank alerts
+type sarifRuleProperties struct {
+	Tags             []string `json:"tags"`
+	SecuritySeverity string   `json:"security-severity"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+// sarifLevel maps a severity to a SARIF result level
+func sarifLevel(severity string) string {
+	switch audit.Severity(strings.ToLower(severity)) {
+	case audit.SeverityCritical, audit.SeverityHigh:
+		return "error"
+	case audit.SeverityModerate, "medium":
+		return "warning"
+	case audit.SeverityLow, audit.SeverityInfo:
+		return "note"
+	default:
+		return "error" // Unknown severities are treated as high elsewhere
+	}
+}
+
+// sarifSecuritySeverity maps a severity to the CVSS-like score GitHub uses
+// to label alerts critical, high, medium, or low
+func sarifSecuritySeverity(severity string) string {
+	switch audit.Severity(strings.ToLower(severity)) {
+	case audit.SeverityCritical:
+		return "9.5"
+	case audit.SeverityModerate, "medium":
+		return "5.5"
+	case audit.SeverityLow:
+		return "2.0"
+	case audit.SeverityInfo:
+		return "0.0"
+	default:
+		return "8.0"
+	}
+}
+
+// SARIFFormatter implements SARIF 2.1.0 output for GitHub code scanning
+type SARIFFormatter struct{}
+
+func (f *SARIFFormatter) Format(output *ScanOutput) (string, error) {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           output.Metadata.ToolName,
+			Version:        output.Metadata.ToolVersion,
+			InformationURI: "https://github.com/brandonapol/snoop",
+			Rules:          make([]sarifRule, 0),
+		}},
+		Results: make([]sarifResult, 0),
+	}
+	if run.Tool.Driver.Name == "" {
+		run.Tool.Driver.Name = "snoop"
+	}
+
+	ruleIndex := make(map[string]int)
+	for _, finding := range collectFindings(output) {
+		index, ok := ruleIndex[finding.id]
+		if !ok {
+			index = len(run.Tool.Driver.Rules)
+			ruleIndex[finding.id] = index
+
+			description := finding.description
+			if description == "" {
+				description = fmt.Sprintf("%s vulnerability %s", finding.ecosystem, finding.id)
+			}
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+				ID:                   finding.id,
+				ShortDescription:     sarifMessage{Text: description},
+				HelpURI:              finding.helpURI,
+				DefaultConfiguration: sarifConfiguration{Level: sarifLevel(finding.severity)},
+				Properties: sarifRuleProperties{
+					Tags:             []string{"security", "dependency", finding.ecosystem},
+					SecuritySeverity: sarifSecuritySeverity(finding.severity),
+				},
+			})
+		}
+
+		message := fmt.Sprintf("%s is affected by %s", finding.pkg, finding.id)
+		if finding.version != "" {
+			message = fmt.Sprintf("%s %s is affected by %s", finding.pkg, finding.version, finding.id)
+		}
+		if len(finding.fixVersions) > 0 {
+			message += fmt.Sprintf(" (fixed in %s)", strings.Join(finding.fixVersions, ", "))
+		}
+
+		run.Results = append(run.Results, sarifResult{
+			RuleID:    finding.id,
+			RuleIndex: index,
+			Level:     sarifLevel(finding.severity),
+			Message:   sarifMessage{Text: message},
+			Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+				// Code scanning resolves locations relative to the repository root
+				ArtifactLocation: sarifArtifactLocation{URI: relativePath(output.Metadata.Directory, finding.manifest)},
+				Region:           sarifRegion{StartLine: 1},
+			}}},
+		})
+	}
+
+	data, err := json.MarshalIndent(sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []sarifRun{run},
+	}, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal SARIF: %w", err)
+	}
+
+	return string(data), nil
+}
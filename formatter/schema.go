@@ -0,0 +1,117 @@
+package formatter
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// jsonSchemaDraft is the JSON Schema dialect JSONSchema follows
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema describes the JSON report, JSONOutput, as a JSON Schema, so
+// tools consuming it have a contract to validate against. The schema is
+// generated from the Go types the report is marshaled from, so it follows
+// them as fields are added. Every struct becomes a definition under $defs,
+// named by package and type such as audit.GoVulnerability, that allows no
+// properties beyond its fields; fields marked omitempty or omitzero are
+// optional, the rest required. Slices and maps may be null, since empty ones
+// are written that way.
+func JSONSchema() (string, error) {
+	builder := &schemaBuilder{defs: make(map[string]any)}
+	root := builder.structSchema(reflect.TypeFor[JSONOutput]())
+	root["$schema"] = jsonSchemaDraft
+	root["title"] = "snoop JSON report"
+	root["$defs"] = builder.defs
+
+	data, err := json.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal JSON schema: %w", err)
+	}
+	return string(data), nil
+}
+
+// schemaBuilder collects the definitions of the structs a schema refers to
+type schemaBuilder struct {
+	defs map[string]any
+}
+
+var (
+	timeType       = reflect.TypeFor[time.Time]()
+	rawMessageType = reflect.TypeFor[json.RawMessage]()
+)
+
+// schemaFor returns the schema of values of type t, as encoding/json writes them
+func (b *schemaBuilder) schemaFor(t reflect.Type) map[string]any {
+	switch t {
+	case timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case rawMessageType:
+		return map[string]any{} // Any JSON value
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice:
+		return map[string]any{"type": []string{"array", "null"}, "items": b.schemaFor(t.Elem())}
+	case reflect.Array:
+		return map[string]any{"type": "array", "items": b.schemaFor(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": []string{"object", "null"}, "additionalProperties": b.schemaFor(t.Elem())}
+	case reflect.Pointer:
+		return map[string]any{"anyOf": []any{b.schemaFor(t.Elem()), map[string]any{"type": "null"}}}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.structSchema(t)
+		}
+		name := path.Base(t.PkgPath()) + "." + t.Name()
+		if _, ok := b.defs[name]; !ok {
+			b.defs[name] = nil // Reserved, so a type that refers to itself stops here
+			b.defs[name] = b.structSchema(t)
+		}
+		return map[string]any{"$ref": "#/$defs/" + name}
+	default:
+		return map[string]any{} // Interfaces hold any JSON value
+	}
+}
+
+// structSchema describes the JSON object a struct is written as
+func (b *schemaBuilder) structSchema(t reflect.Type) map[string]any {
+	properties := make(map[string]any)
+	required := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = b.schemaFor(field.Type)
+		if !strings.Contains(options, "omitempty") && !strings.Contains(options, "omitzero") {
+			required = append(required, name)
+		}
+	}
+	return map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}
+}
@@ -0,0 +1,259 @@
+package formatter
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brandonapol/snoop/audit"
+	"github.com/brandonapol/snoop/scanner"
+)
+
+// populate sets every field reachable from v to a non-zero value, with one
+// element in each slice and map, so the marshaled value has every property
+// the report can have
+func populate(v reflect.Value, depth int) {
+	if depth > 8 {
+		return
+	}
+	switch v.Type() {
+	case timeType:
+		v.Set(reflect.ValueOf(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)))
+		return
+	case rawMessageType:
+		v.Set(reflect.ValueOf(json.RawMessage(`{"name":"fix","version":"1.0.0"}`)))
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		v.SetBool(true)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(1)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v.SetUint(1)
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(1.5)
+	case reflect.String:
+		v.SetString("value")
+	case reflect.Slice:
+		slice := reflect.MakeSlice(v.Type(), 1, 1)
+		populate(slice.Index(0), depth+1)
+		v.Set(slice)
+	case reflect.Map:
+		m := reflect.MakeMap(v.Type())
+		key := reflect.New(v.Type().Key()).Elem()
+		populate(key, depth+1)
+		elem := reflect.New(v.Type().Elem()).Elem()
+		populate(elem, depth+1)
+		m.SetMapIndex(key, elem)
+		v.Set(m)
+	case reflect.Pointer:
+		elem := reflect.New(v.Type().Elem())
+		populate(elem.Elem(), depth+1)
+		v.Set(elem)
+	case reflect.Interface:
+		v.Set(reflect.ValueOf("value"))
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				populate(v.Field(i), depth+1)
+			}
+		}
+	}
+}
+
+// validateSchema checks value, as decoded by encoding/json, against the
+// parts of JSON Schema that JSONSchema and the vendored GitLab schema use
+func validateSchema(root, schema map[string]any, value any, at string) error {
+	if ref, ok := schema["$ref"].(string); ok {
+		section, name, _ := strings.Cut(strings.TrimPrefix(ref, "#/"), "/")
+		def, ok := root[section].(map[string]any)[name].(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s: $ref %s has no definition", at, ref)
+		}
+		return validateSchema(root, def, value, at)
+	}
+	if enum, ok := schema["enum"].([]any); ok && !slices.Contains(enum, value) {
+		return fmt.Errorf("%s: %v is not one of %v", at, value, enum)
+	}
+	if anyOf, ok := schema["anyOf"].([]any); ok {
+		for _, option := range anyOf {
+			if validateSchema(root, option.(map[string]any), value, at) == nil {
+				return nil
+			}
+		}
+		return fmt.Errorf("%s: %v matches none of anyOf", at, value)
+	}
+
+	var types []string
+	switch t := schema["type"].(type) {
+	case string:
+		types = []string{t}
+	case []any:
+		for _, name := range t {
+			types = append(types, name.(string))
+		}
+	}
+	if len(types) > 0 && !slices.Contains(types, jsonType(value)) && !(jsonType(value) == "integer" && slices.Contains(types, "number")) {
+		return fmt.Errorf("%s: %s, expected %v", at, jsonType(value), types)
+	}
+
+	switch value := value.(type) {
+	case string:
+		if minLength, ok := schema["minLength"].(float64); ok && len(value) < int(minLength) {
+			return fmt.Errorf("%s: %q is shorter than %v", at, value, minLength)
+		}
+		if maxLength, ok := schema["maxLength"].(float64); ok && len(value) > int(maxLength) {
+			return fmt.Errorf("%s: %q is longer than %v", at, value, maxLength)
+		}
+		if pattern, ok := schema["pattern"].(string); ok && !regexp.MustCompile(pattern).MatchString(value) {
+			return fmt.Errorf("%s: %q doesn't match %s", at, value, pattern)
+		}
+	case map[string]any:
+		properties, _ := schema["properties"].(map[string]any)
+		if required, ok := schema["required"].([]any); ok {
+			for _, name := range required {
+				if _, ok := value[name.(string)]; !ok {
+					return fmt.Errorf("%s: missing required property %s", at, name)
+				}
+			}
+		}
+		for name, property := range value {
+			if propertySchema, ok := properties[name]; ok {
+				if err := validateSchema(root, propertySchema.(map[string]any), property, at+"."+name); err != nil {
+					return err
+				}
+				continue
+			}
+			switch additional := schema["additionalProperties"].(type) {
+			case bool:
+				if !additional {
+					return fmt.Errorf("%s: unexpected property %s", at, name)
+				}
+			case map[string]any:
+				if err := validateSchema(root, additional, property, at+"."+name); err != nil {
+					return err
+				}
+			}
+		}
+	case []any:
+		if minItems, ok := schema["minItems"].(float64); ok && len(value) < int(minItems) {
+			return fmt.Errorf("%s: %d items, expected at least %v", at, len(value), minItems)
+		}
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range value {
+				if err := validateSchema(root, items, item, fmt.Sprintf("%s[%d]", at, i)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// jsonType names the JSON Schema type of a value decoded by encoding/json
+func jsonType(value any) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if value == float64(int64(value)) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+// decodeSchema generates the schema and decodes it for validateSchema
+func decodeSchema(t *testing.T) map[string]any {
+	t.Helper()
+	generated, err := JSONSchema()
+	if err != nil {
+		t.Fatalf("JSONSchema() unexpected error: %v", err)
+	}
+	var schema map[string]any
+	if err := json.Unmarshal([]byte(generated), &schema); err != nil {
+		t.Fatalf("JSONSchema() isn't valid JSON: %v", err)
+	}
+	return schema
+}
+
+func TestJSONSchemaDescribesFullyPopulatedOutput(t *testing.T) {
+	schema := decodeSchema(t)
+	if schema["$schema"] != jsonSchemaDraft {
+		t.Errorf("$schema = %v, expected %s", schema["$schema"], jsonSchemaDraft)
+	}
+
+	var output JSONOutput
+	populate(reflect.ValueOf(&output).Elem(), 0)
+	data, err := json.Marshal(output)
+	if err != nil {
+		t.Fatalf("Failed to marshal JSONOutput: %v", err)
+	}
+	var decoded any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Failed to decode JSONOutput: %v", err)
+	}
+
+	if err := validateSchema(schema, schema, decoded, "$"); err != nil {
+		t.Errorf("fully populated JSONOutput doesn't match the schema: %v", err)
+	}
+
+	// Every audit array is described
+	properties := schema["properties"].(map[string]any)
+	for _, name := range []string{"audits", "pythonAudits", "goAudits", "mavenAudits", "swiftAudits", "cargoAudits", "rubyAudits", "composerAudits", "summary", "metadata"} {
+		if _, ok := properties[name]; !ok {
+			t.Errorf("schema doesn't describe %s", name)
+		}
+	}
+}
+
+func TestJSONSchemaDescribesFormattedReport(t *testing.T) {
+	schema := decodeSchema(t)
+
+	// Formatted reports leave slices empty or nil, which the schema allows
+	output := &ScanOutput{
+		ScanResults: &scanner.ScanResult{Files: []scanner.DetectedFile{{Path: "go.mod", Type: scanner.GoMod}}},
+		GoAuditResults: []*audit.GoAuditResult{{
+			ManifestPath: "go.mod",
+			ManifestType: "go.mod",
+			Vulnerabilities: []audit.GoVulnerability{
+				{Module: "golang.org/x/net", Version: "0.1.0", ID: "GO-2023-1571", Severity: "high"},
+			},
+			Summary: audit.VulnerabilitySummary{High: 1, Total: 1, Transitive: 1},
+		}},
+		TotalVulns: 1,
+	}
+	formatted, err := (&JSONFormatter{}).Format(output)
+	if err != nil {
+		t.Fatalf("JSONFormatter.Format() unexpected error: %v", err)
+	}
+	var decoded any
+	if err := json.Unmarshal([]byte(formatted), &decoded); err != nil {
+		t.Fatalf("Failed to decode JSON output: %v", err)
+	}
+
+	if err := validateSchema(schema, schema, decoded, "$"); err != nil {
+		t.Errorf("JSON report doesn't match the schema: %v", err)
+	}
+
+	// A property the schema doesn't know about is rejected
+	decoded.(map[string]any)["unexpected"] = true
+	if err := validateSchema(schema, schema, decoded, "$"); err == nil {
+		t.Error("validateSchema() accepted a property missing from the schema")
+	}
+}
@@ -0,0 +1,63 @@
+package formatter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/brandonapol/snoop/audit"
+)
+
+// ecosystemOrder is the row order of the severity matrix
+var ecosystemOrder = []string{
+	EcosystemNpm,
+	EcosystemPython,
+	EcosystemGo,
+	EcosystemMaven,
+	EcosystemSwift,
+	EcosystemCargo,
+	EcosystemRuby,
+	EcosystemPHP,
+	EcosystemRuntime,
+	EcosystemSBOM,
+	EcosystemCustom,
+}
+
+// RenderSeverityMatrix renders a markdown table of findings by ecosystem and
+// severity, with a totals row and column. It's a compact posture snapshot for
+// the top of a report such as SECURITY.md. The Info column only appears when
+// info findings are reported, so every row still sums to its total.
+func RenderSeverityMatrix(output *ScanOutput) string {
+	summaries := summaryByEcosystem(output)
+	total := AggregateSummary(output)
+	showInfo := total.Info > 0
+
+	var builder strings.Builder
+	builder.WriteString("## Severity Matrix\n\n")
+
+	header := "| Ecosystem | Critical | High | Moderate | Low |"
+	separator := "|-----------|---------:|-----:|---------:|----:|"
+	if showInfo {
+		header += " Info |"
+		separator += "-----:|"
+	}
+	builder.WriteString(header + " Total |\n")
+	builder.WriteString(separator + "------:|\n")
+
+	row := func(name string, summary audit.VulnerabilitySummary) {
+		builder.WriteString(fmt.Sprintf("| %s | %d | %d | %d | %d |", name, summary.Critical, summary.High, summary.Moderate, summary.Low))
+		if showInfo {
+			builder.WriteString(fmt.Sprintf(" %d |", summary.Info))
+		}
+		builder.WriteString(fmt.Sprintf(" %d |\n", summary.Total))
+	}
+
+	for _, ecosystem := range ecosystemOrder {
+		if summary, ok := summaries[ecosystem]; ok {
+			row(ecosystem, summary)
+		}
+	}
+	row("**Total**", total)
+	builder.WriteString("\n")
+
+	return builder.String()
+}
@@ -0,0 +1,101 @@
+package formatter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/brandonapol/snoop/security"
+)
+
+// JSONSecurityResult represents the supply chain checks of a single package.json
+type JSONSecurityResult struct {
+	PackageJSON         string                     `json:"packageJson"`
+	DependenciesChecked int                        `json:"dependenciesChecked"`
+	Findings            []*security.SecurityReport `json:"findings"`
+}
+
+// buildJSONSecurityResults converts the supply chain checks for JSON output
+func buildJSONSecurityResults(results []*security.ManifestReport) []JSONSecurityResult {
+	if len(results) == 0 {
+		return nil
+	}
+
+	jsonResults := make([]JSONSecurityResult, 0, len(results))
+	for _, result := range results {
+		jsonResults = append(jsonResults, JSONSecurityResult{
+			PackageJSON:         result.PackageJSONPath,
+			DependenciesChecked: result.Checked,
+			Findings:            result.Reports,
+		})
+	}
+	return jsonResults
+}
+
+// describeSecurityReport lists a dependency's findings as short sentences
+func describeSecurityReport(report *security.SecurityReport) []string {
+	var findings []string
+	if risk := report.TyposquattingRisk; risk != nil {
+		findings = append(findings, fmt.Sprintf("Name is similar to %s (%s confidence typosquat)", risk.SimilarTo, risk.Confidence))
+	}
+	if risk := report.MaintainerRisk; risk != nil {
+		findings = append(findings, risk.Issues...)
+	}
+	if risk := report.PopularityRisk; risk != nil {
+		findings = append(findings, risk.Issues...)
+	}
+	for _, pattern := range report.SuspiciousPatterns {
+		findings = append(findings, fmt.Sprintf("%s script: %s", pattern.ScriptType, pattern.ScriptContent))
+	}
+	return findings
+}
+
+// writeTableSupplyChain writes the supply chain checks section
+func writeTableSupplyChain(builder *strings.Builder, results []*security.ManifestReport) {
+	for _, result := range results {
+		builder.WriteString(fmt.Sprintf("Supply chain checks: %s\n", result.PackageJSONPath))
+		builder.WriteString(strings.Repeat("-", 80) + "\n")
+
+		if len(result.Reports) == 0 {
+			builder.WriteString(fmt.Sprintf("No supply chain risks found in %d dependencies\n\n", result.Checked))
+			continue
+		}
+
+		builder.WriteString(fmt.Sprintf("%-40s %-8s %s\n", "Package", "Risk", "Findings"))
+		for _, report := range result.Reports {
+			findings := describeSecurityReport(report)
+			builder.WriteString(fmt.Sprintf("%-40s %-8s %s\n", report.PackageName, report.OverallRiskLevel, findings[0]))
+			for _, finding := range findings[1:] {
+				builder.WriteString(fmt.Sprintf("%-40s %-8s %s\n", "", "", finding))
+			}
+		}
+		builder.WriteString("\n")
+	}
+}
+
+// writeMarkdownSupplyChain writes the supply chain checks section
+func writeMarkdownSupplyChain(builder *strings.Builder, results []*security.ManifestReport) {
+	if len(results) == 0 {
+		return
+	}
+
+	builder.WriteString("## Supply Chain Checks\n\n")
+	for _, result := range results {
+		builder.WriteString(fmt.Sprintf("### `%s`\n\n", result.PackageJSONPath))
+
+		if len(result.Reports) == 0 {
+			builder.WriteString(fmt.Sprintf("✅ No supply chain risks found in %d dependencies.\n\n", result.Checked))
+			continue
+		}
+
+		builder.WriteString("| Package | Risk | Findings |\n")
+		builder.WriteString("|---------|------|----------|\n")
+		for _, report := range result.Reports {
+			findings := describeSecurityReport(report)
+			for i, finding := range findings {
+				findings[i] = strings.ReplaceAll(finding, "|", "\\|")
+			}
+			builder.WriteString(fmt.Sprintf("| `%s` | %s | %s |\n", report.PackageName, report.OverallRiskLevel, strings.Join(findings, "<br>")))
+		}
+		builder.WriteString("\n")
+	}
+}
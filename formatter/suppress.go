@@ -0,0 +1,15 @@
+package formatter
+
+import "github.com/brandonapol/snoop/audit"
+
+// Suppress drops the findings matched by suppressions, recomputing every
+// summary and the total, and records how many findings were suppressed
+func Suppress(output *ScanOutput, suppressions []audit.Suppression) {
+	suppressed := 0
+	for _, result := range auditResults(output) {
+		suppressed += audit.FilterSuppressed(result, suppressions)
+	}
+
+	output.Suppressed = suppressed
+	output.TotalVulns = AggregateSummary(output).Total
+}
@@ -0,0 +1,109 @@
+package formatter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/brandonapol/snoop/audit"
+)
+
+// upgradesByEcosystem counts, keyed by the Ecosystem* constants, the findings
+// fixable with and without a major upgrade
+func upgradesByEcosystem(output *ScanOutput) map[string]audit.UpgradeSummary {
+	upgrades := make(map[string]audit.UpgradeSummary)
+	add := func(ecosystem string, summary audit.UpgradeSummary) {
+		total := upgrades[ecosystem]
+		total.Add(summary)
+		upgrades[ecosystem] = total
+	}
+
+	for _, result := range output.AuditResults {
+		add(EcosystemNpm, result.Upgrades())
+	}
+	for _, result := range output.PythonAuditResults {
+		add(EcosystemPython, result.Upgrades())
+	}
+	for _, result := range output.GoAuditResults {
+		add(EcosystemGo, result.Upgrades())
+	}
+	for _, result := range output.MavenAuditResults {
+		add(EcosystemMaven, result.Upgrades())
+	}
+	for _, result := range output.SwiftAuditResults {
+		add(EcosystemSwift, result.Upgrades())
+	}
+	for _, result := range output.CargoAuditResults {
+		add(EcosystemCargo, result.Upgrades())
+	}
+	for _, result := range output.RubyAuditResults {
+		add(EcosystemRuby, result.Upgrades())
+	}
+	for _, result := range output.ComposerAuditResults {
+		add(EcosystemPHP, result.Upgrades())
+	}
+	for _, result := range output.RuntimeAuditResults {
+		add(EcosystemRuntime, result.Upgrades())
+	}
+	for _, result := range output.SBOMAuditResults {
+		add(EcosystemSBOM, result.Upgrades())
+	}
+	for _, result := range output.CustomAuditResults {
+		add(EcosystemCustom, result.Upgrades())
+	}
+	return upgrades
+}
+
+// aggregateUpgrades combines the upgrade counts of every ecosystem
+func aggregateUpgrades(upgrades map[string]audit.UpgradeSummary) audit.UpgradeSummary {
+	total := audit.UpgradeSummary{}
+	for _, summary := range upgrades {
+		total.Add(summary)
+	}
+	return total
+}
+
+// formatUpgrades renders upgrade counts as e.g. "28 fixable safely, 9 require major upgrades"
+func formatUpgrades(summary audit.UpgradeSummary) string {
+	return fmt.Sprintf("%d fixable safely, %d require major upgrades", summary.NonBreaking, summary.Breaking)
+}
+
+// writeTableUpgrades adds the remediation effort to the table summary, overall
+// and for each ecosystem with fixable findings
+func writeTableUpgrades(builder *strings.Builder, upgrades map[string]audit.UpgradeSummary) {
+	total := aggregateUpgrades(upgrades)
+	if total.Total() == 0 {
+		return
+	}
+
+	builder.WriteString(fmt.Sprintf("Fixes: %s\n", formatUpgrades(total)))
+	for _, ecosystem := range sortedUpgradeEcosystems(upgrades) {
+		builder.WriteString(fmt.Sprintf("  %s: %s\n", ecosystem, formatUpgrades(upgrades[ecosystem])))
+	}
+}
+
+// writeMarkdownUpgrades adds the remediation effort to the markdown summary
+func writeMarkdownUpgrades(builder *strings.Builder, upgrades map[string]audit.UpgradeSummary) {
+	total := aggregateUpgrades(upgrades)
+	if total.Total() == 0 {
+		return
+	}
+
+	builder.WriteString(fmt.Sprintf("**Fixes:** %s\n\n", formatUpgrades(total)))
+	for _, ecosystem := range sortedUpgradeEcosystems(upgrades) {
+		builder.WriteString(fmt.Sprintf("- %s: %s\n", ecosystem, formatUpgrades(upgrades[ecosystem])))
+	}
+	builder.WriteString("\n")
+}
+
+// sortedUpgradeEcosystems returns the ecosystems with fixable findings in name order
+func sortedUpgradeEcosystems(upgrades map[string]audit.UpgradeSummary) []string {
+	var ecosystems []string
+	for ecosystem, summary := range upgrades {
+		if summary.Total() > 0 {
+			ecosystems = append(ecosystems, ecosystem)
+		}
+	}
+	sort.Strings(ecosystems)
+	return ecosystems
+}
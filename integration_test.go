@@ -2,13 +2,17 @@ package main
 
 import (
 	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync/atomic"
 	"testing"
 
 	"github.com/brandonapol/snoop/formatter"
+	"github.com/brandonapol/snoop/osv"
 )
 
 // TestMain builds the binary before running tests
@@ -44,6 +48,124 @@ func TestRequirement_CLI_VersionFlag(t *testing.T) {
 	}
 }
 
+func TestVersionCommand(t *testing.T) {
+	output, err := exec.Command("./snoop-test", "version").CombinedOutput()
+	if err != nil {
+		t.Fatalf("version command failed: %v\n%s", err, output)
+	}
+
+	for _, want := range []string{"snoop 0.1.0", "commit: ", "built: ", "go: go"} {
+		if !strings.Contains(string(output), want) {
+			t.Errorf("version output missing %q. Got: %s", want, output)
+		}
+	}
+}
+
+func TestOSVURLQueriesMirror(t *testing.T) {
+	var queries atomic.Int32
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/query" {
+			http.NotFound(w, r)
+			return
+		}
+		queries.Add(1)
+		response := osv.QueryResponse{Vulns: []osv.Vulnerability{{ID: "GHSA-m2qf-hxjv-5gpq", Summary: "Flask session cookie disclosure"}}}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Errorf("failed to encode mock response: %v", err)
+		}
+	}))
+	defer mirror.Close()
+
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "requirements.txt"), []byte("flask==2.0.0\n"), 0644); err != nil {
+		t.Fatalf("Failed to write requirements.txt: %v", err)
+	}
+
+	output, err := exec.Command("./snoop-test", "--path", tmpDir, "--format", "json", "--no-cache", "--osv-url", mirror.URL).Output()
+	if err != nil {
+		t.Fatalf("snoop --osv-url failed: %v", err)
+	}
+	var report formatter.JSONOutput
+	if err := json.Unmarshal(output, &report); err != nil {
+		t.Fatalf("snoop --osv-url printed invalid JSON: %v", err)
+	}
+
+	if queries.Load() == 0 {
+		t.Error("snoop --osv-url never queried the mirror")
+	}
+	if report.Summary.Total != 1 {
+		t.Errorf("Summary.Total = %d, expected the mirror's advisory", report.Summary.Total)
+	}
+	backends := report.ScanManifest.Backends
+	if len(backends) != 1 || backends[0].Endpoint != mirror.URL+"/v1/query" {
+		t.Errorf("backends = %+v, expected the mirror's /v1/query", backends)
+	}
+}
+
+func TestServiceURLMustBeHTTP(t *testing.T) {
+	for _, flag := range []string{"--osv-url", "--registry-url"} {
+		output, err := exec.Command("./snoop-test", "--path", t.TempDir(), flag, "ftp://mirror.example.com").CombinedOutput()
+		if err == nil {
+			t.Errorf("snoop %s ftp://... succeeded, expected an error", flag)
+		}
+		if !strings.Contains(string(output), "must be an http or https URL") {
+			t.Errorf("snoop %s output = %s, expected the URL to be rejected", flag, output)
+		}
+	}
+}
+
+func TestScanCommandMatchesDefault(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "go.mod"), []byte("module example.com/app\n\ngo 1.21\n"), 0644); err != nil {
+		t.Fatalf("Failed to write go.mod: %v", err)
+	}
+
+	var reports []formatter.JSONOutput
+	for _, args := range [][]string{
+		{"--path", tmpDir, "--format", "json", "--normalized"},
+		{"scan", "--path", tmpDir, "--format", "json", "--normalized"},
+	} {
+		output, err := exec.Command("./snoop-test", args...).Output()
+		if err != nil {
+			t.Fatalf("snoop %s failed: %v", strings.Join(args, " "), err)
+		}
+		var report formatter.JSONOutput
+		if err := json.Unmarshal(output, &report); err != nil {
+			t.Fatalf("snoop %s printed invalid JSON: %v", strings.Join(args, " "), err)
+		}
+		reports = append(reports, report)
+	}
+
+	if reports[0].ManifestsFound != 1 || reports[1].ManifestsFound != reports[0].ManifestsFound {
+		t.Errorf("manifests found = %d with scan, %d without, expected 1 for both", reports[1].ManifestsFound, reports[0].ManifestsFound)
+	}
+}
+
+func TestSchemaFlag(t *testing.T) {
+	output, err := exec.Command("./snoop-test", "--schema").Output()
+	if err != nil {
+		t.Fatalf("--schema failed: %v", err)
+	}
+
+	var schema map[string]any
+	if err := json.Unmarshal(output, &schema); err != nil {
+		t.Fatalf("--schema didn't print JSON: %v\n%s", err, output)
+	}
+	if _, ok := schema["$defs"]; !ok {
+		t.Errorf("--schema output has no $defs. Got: %s", output)
+	}
+}
+
+func TestSummaryFlagRequiresSupportedFormat(t *testing.T) {
+	output, err := exec.Command("./snoop-test", "--path", t.TempDir(), "--summary", "--format", "sarif").CombinedOutput()
+	if err == nil {
+		t.Error("snoop --summary --format sarif succeeded, expected an error")
+	}
+	if !strings.Contains(string(output), "--summary only applies to --format table, markdown, or json") {
+		t.Errorf("snoop --summary --format sarif output = %s, expected the format to be rejected", output)
+	}
+}
+
 func TestRequirement_CLI_HelpFlag(t *testing.T) {
 	// Requirement: Basic CLI structure with help
 	cmd := exec.Command("./snoop-test", "--help")
@@ -567,6 +689,12 @@ func TestRequirement_MarkdownFormatter_Tables(t *testing.T) {
 		t.Fatalf("Failed to write package.json: %v", err)
 	}
 
+	// npm audit needs a lockfile to report anything
+	lock := `{"name":"test","version":"1.0.0","lockfileVersion":3,"requires":true,"packages":{"":{"name":"test","version":"1.0.0"}}}`
+	if err := os.WriteFile(filepath.Join(tmpDir, "package-lock.json"), []byte(lock), 0644); err != nil {
+		t.Fatalf("Failed to write package-lock.json: %v", err)
+	}
+
 	cmd := exec.Command("./snoop-test", "--path", tmpDir, "--format", "markdown")
 	output, _ := cmd.CombinedOutput()
 
@@ -654,3 +782,44 @@ func TestRequirement_MultipleManifests(t *testing.T) {
 		t.Errorf("Expected 4 manifests, got %d", result.ManifestsFound)
 	}
 }
+
+func TestNormalizedOutputIsStable(t *testing.T) {
+	// Running the same scan twice with --normalized must produce identical output
+	tmpDir := t.TempDir()
+	for _, sub := range []string{"service-b", "service-a"} {
+		dir := filepath.Join(tmpDir, sub)
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", sub, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/"+sub+"\n\ngo 1.21\n"), 0644); err != nil {
+			t.Fatalf("Failed to write go.mod: %v", err)
+		}
+	}
+
+	run := func() []byte {
+		cmd := exec.Command("./snoop-test", "--path", tmpDir, "--format", "json", "--normalized")
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("Normalized scan failed: %v\nOutput: %s", err, string(output))
+		}
+		return output
+	}
+
+	first := run()
+	second := run()
+
+	if string(first) != string(second) {
+		t.Errorf("Normalized output differs between runs:\n%s\n---\n%s", first, second)
+	}
+
+	var result formatter.JSONOutput
+	if err := json.Unmarshal(first, &result); err != nil {
+		t.Fatalf("Failed to parse JSON output: %v", err)
+	}
+	if !result.Metadata.Timestamp.IsZero() {
+		t.Error("Normalized output should not include a timestamp")
+	}
+	if len(result.ManifestFiles) != 2 || result.ManifestFiles[0].Path != "service-a/go.mod" {
+		t.Errorf("Expected sorted relative manifest paths, got %+v", result.ManifestFiles)
+	}
+}
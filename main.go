@@ -1,35 +1,147 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"net/url"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/brandonapol/snoop/audit"
 	"github.com/brandonapol/snoop/formatter"
+	"github.com/brandonapol/snoop/osv"
+	"github.com/brandonapol/snoop/pipeline"
 	"github.com/brandonapol/snoop/scanner"
+	"github.com/brandonapol/snoop/security"
+	"github.com/brandonapol/snoop/webhook"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
-const version = "0.1.0"
+// Build metadata, set at build time with -ldflags "-X main.version=..."
+var (
+	version   = "0.1.0"
+	commit    = "unknown"
+	buildDate = "unknown"
+)
 
 var (
-	path     string
-	format   string
-	severity string
-	verbose  bool
+	path            string
+	format          string
+	severity        string
+	verbose         bool
+	normalized      bool
+	profile         string
+	checkRuntime    bool
+	autoConcurrency bool
+	sbomPath        string
+	noCache         bool
+	cacheTTL        time.Duration
+
+	includePrerelease bool
+	includeIndirect   bool
+	showFixes         bool
+	pathsFrom         string
+	manifestsFrom     string
+	includeInfo       bool
+	strictIncludes    bool
+	crossEcosystem    bool
+	reportFlags       map[string]string // Flags in effect, recorded in the scan manifest
+	onlyFixable       bool
+	strict            bool
+	goCombined        bool
+	severityReport    bool
+	summaryOnly       bool
+	failOn            string
+	checks            []string
+	typosquatList     string
+	typosquatOnly     bool
+	popularPackages   []string // Names the typosquat check compares against; nil uses the built-in list
+
+	typosquatConfidence    string
+	minTyposquatConfidence security.Confidence // Parsed from --typosquat-confidence
+
+	excludePaths        []string
+	ecosystems          []string
+	respectGitignore    bool
+	maxDepth            int
+	followSymlinks      bool
+	excludePackages     []string
+	excludePackagesFrom string
+	packageExclusions   []audit.PackageExclusion
+	ignoreFile          string
+	suppressions        []audit.Suppression
+
+	webhookURL     string
+	webhookHeaders []string
+	webhookSummary bool
+	webhookSink    *webhook.Sink
+
+	maxUnpinnedAdvisories int
+
+	noProgress bool
+
+	daemonMode     bool
+	daemonInterval time.Duration
+	outputDir      string
+
+	outputFile string
+
+	printSchema bool
+
+	manifestConcurrency int
+
+	osvURL      string
+	registryURL string
+	rateLimit   float64
+
+	// osvClient replaces the default OSV client of every audit runner when set
+	osvClient *osv.Client
 )
 
+// exitVulnerabilities is the exit status when findings reach the --fail-on threshold
+const exitVulnerabilities = 1
+
+// failOnLevels are the accepted --fail-on values; "none" never fails the run
+var failOnLevels = []string{"none", "critical", "high", "moderate", "low", "info"}
+
+// summaryFormats are the --format values --summary applies to
+var summaryFormats = []formatter.OutputFormat{formatter.FormatTable, formatter.FormatMarkdown, formatter.FormatJSON}
+
+// checkVuln is the --checks value for vulnerability audits; the others are
+// supply chain checks of Node.js dependencies
+const checkVuln = "vuln"
+
+// checkNames are the accepted --checks values
+var checkNames = []string{checkVuln, security.CheckTyposquat, security.CheckMaintainer, security.CheckScripts}
+
+// exitUnverified is the exit status under --strict when an ecosystem's
+// backend was unavailable, so a clean-looking report can't pass CI
+const exitUnverified = 2
+
+// exitInterrupted is the exit status when a signal stops the scan, following
+// the shell's 128 + SIGINT
+const exitInterrupted = 130
+
 var rootCmd = &cobra.Command{
 	Use:   "snoop",
-	Short: "A security audit tool for Node.js, Python, Go, and Maven packages",
-	Long: `Snoop is a CLI tool that automatically detects Node.js, Python, Go, and Maven package manifests
-in a directory and runs comprehensive security audits.
+	Short: "A security audit tool for Node.js, Python, Go, Maven, Swift, Rust, Ruby, and PHP packages",
+	Long: `Snoop is a CLI tool that automatically detects Node.js, Python, Go, Maven, Swift, Rust, Ruby,
+and PHP package manifests in a directory and runs comprehensive security audits.
 
 It detects package.json, package-lock.json, yarn.lock, pnpm-lock.yaml, requirements.txt,
-Pipfile, pyproject.toml, go.mod, and pom.xml files. It uses npm audit for Node.js and the
-built-in OSV API for Python, Go, and Maven to identify vulnerabilities, typosquatting risks,
-and other supply chain security issues.
+Pipfile, pyproject.toml, go.mod, pom.xml, build.gradle, build.gradle.kts, Package.swift,
+Package.resolved, Cargo.toml, Cargo.lock, Gemfile.lock, and composer.lock files. It uses npm
+audit for Node.js and the built-in OSV API for Python, Go, Maven, Gradle, Swift, Cargo, Ruby,
+and PHP to identify vulnerabilities, typosquatting risks, and other supply chain security
+issues.
 
 Examples:
   # Scan current directory
@@ -45,263 +157,699 @@ Examples:
   snoop --severity high
 
   # Generate markdown report
-  snoop --format markdown > SECURITY.md`,
+  snoop --format markdown > SECURITY.md
+
+  # Upload results to GitHub code scanning
+  snoop --format sarif > snoop.sarif
+
+  # Export a CycloneDX SBOM of every dependency, with known vulnerabilities
+  snoop --format cyclonedx > sbom.cdx.json
+
+  # Show findings as failing tests in Jenkins or GitLab
+  snoop --format junit > snoop-junit.xml
+
+  # Triage findings in a spreadsheet
+  snoop --format csv > findings.csv
+
+  # Count critical findings, one tab-separated line per finding
+  snoop --format grep | grep -c '^critical'
+
+  # Fail the build when a high or critical vulnerability is found
+  snoop --fail-on high
+
+  # Use the CI preset, but only report critical issues
+  snoop --profile ci --severity critical
+
+  # Audit an SBOM generated by syft or cdxgen
+  snoop --sbom sbom.cdx.json
+
+  # Push results to a dashboard from a scheduled job
+  snoop --webhook https://dashboard.example.com/snoop --webhook-header "Authorization: Bearer $TOKEN"
+
+  # Scan a fleet of repositories into a JSON Lines stream
+  find ~/src -maxdepth 1 -mindepth 1 -type d | snoop --paths-from - >> fleet.ndjson
+
+  # Rescan every 6 hours, posting to a webhook when findings change
+  snoop --daemon --interval 6h --webhook https://hooks.example.com/snoop
+
+  # Leave first-party packages out of the audit
+  snoop --exclude-package npm:@acme/ui --exclude-package python:acme-utils
+
+  # Audit only the manifests changed on this branch
+  git diff --name-only main | snoop --manifests-from -
+
+  # Generate a stable report suitable for committing
+  snoop --format json --normalized > security-report.json
+
+  # Print the version and build information
+  snoop version`,
 	Version: version,
-	Run: func(cmd *cobra.Command, args []string) {
-		if verbose && format == "table" {
-			fmt.Printf("Snoop v%s\n", version)
-			fmt.Printf("Scanning directory: %s\n", path)
-			fmt.Printf("Output format: %s\n", format)
-			fmt.Printf("Minimum severity: %s\n", severity)
-			fmt.Println()
+	PreRunE: preRunScan,
+	Run:     runScan,
+}
+
+// scanCmd is the default command under its own name, for symmetry with
+// the other subcommands
+var scanCmd = &cobra.Command{
+	Use:   "scan",
+	Short: "Scan a directory for package manifests and audit them (the default command)",
+	Long: `Scan detects the package manifests in a directory and audits their dependencies
+for known vulnerabilities and supply chain risks. Running snoop without a
+subcommand does the same, so "snoop scan --path ." and "snoop --path ." are
+equivalent.`,
+	Args:    cobra.NoArgs,
+	PreRunE: preRunScan,
+	Run:     runScan,
+}
+
+// preRunScan applies the --profile, validates the scan flags, and loads the
+// files they name before anything is scanned
+func preRunScan(cmd *cobra.Command, args []string) error {
+	if err := applyProfile(cmd.Flags(), profile); err != nil {
+		return err
+	}
+
+	if !slices.Contains(failOnLevels, failOn) {
+		return fmt.Errorf("invalid --fail-on %q (available: %s)", failOn, strings.Join(failOnLevels, ", "))
+	}
+	if manifestsFrom != "" && (pathsFrom != "" || sbomPath != "") {
+		return fmt.Errorf("--manifests-from can't be combined with --paths-from or --sbom")
+	}
+	if daemonMode && (pathsFrom != "" || manifestsFrom != "" || sbomPath != "") {
+		return fmt.Errorf("--daemon can't be combined with --paths-from, --manifests-from, or --sbom")
+	}
+	if daemonMode && daemonInterval <= 0 {
+		return fmt.Errorf("invalid --interval %s: must be positive", daemonInterval)
+	}
+	if cacheTTL <= 0 {
+		return fmt.Errorf("invalid --cache-ttl %s: must be positive", cacheTTL)
+	}
+	if outputDir != "" && !daemonMode {
+		return fmt.Errorf("--output-dir requires --daemon")
+	}
+	if outputFile != "" && daemonMode {
+		return fmt.Errorf("--output can't be combined with --daemon; use --output-dir")
+	}
+	if summaryOnly && !slices.Contains(summaryFormats, formatter.OutputFormat(format)) {
+		return fmt.Errorf("--summary only applies to --format table, markdown, or json")
+	}
+	if summaryOnly && (pathsFrom != "" || daemonMode) {
+		return fmt.Errorf("--summary can't be combined with --paths-from or --daemon")
+	}
+
+	if err := validateServiceURL("osv-url", osvURL); err != nil {
+		return err
+	}
+	if err := validateServiceURL("registry-url", registryURL); err != nil {
+		return err
+	}
+
+	if err := validateEcosystems(ecosystems); err != nil {
+		return err
+	}
+
+	for _, check := range checks {
+		if !slices.Contains(checkNames, check) {
+			return fmt.Errorf("invalid --checks value %q (available: %s)", check, strings.Join(checkNames, ", "))
 		}
+	}
 
-		// Create scanner
-		s, err := scanner.New(path, verbose)
+	if err := scanner.ValidateExcludePatterns(excludePaths); err != nil {
+		return err
+	}
+	if manifestConcurrency < 1 {
+		return fmt.Errorf("--manifest-concurrency must be at least 1, got %d", manifestConcurrency)
+	}
+	if rateLimit < 0 {
+		return fmt.Errorf("--rate-limit must be 0 (no limit) or more, got %g", rateLimit)
+	}
+	if maxDepth < -1 {
+		return fmt.Errorf("--max-depth must be -1 (no limit) or at least 0, got %d", maxDepth)
+	}
+
+	if typosquatOnly && typosquatList == "" {
+		return fmt.Errorf("--typosquat-list-only requires --typosquat-list")
+	}
+	if typosquatList != "" {
+		names, err := security.LoadPopularPackages(typosquatList)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+			return err
+		}
+		if !typosquatOnly {
+			names = security.MergePopularPackages(names)
 		}
+		popularPackages = names
+	}
+	confidence, err := security.ParseConfidence(typosquatConfidence)
+	if err != nil {
+		return fmt.Errorf("invalid --typosquat-confidence: %w", err)
+	}
+	minTyposquatConfidence = confidence
+
+	exclusions, err := readPackageExclusions(excludePackages, excludePackagesFrom)
+	if err != nil {
+		return err
+	}
+	packageExclusions = exclusions
 
-		// Scan for manifest files
-		if verbose && format == "table" {
-			fmt.Println("Scanning for Node.js package manifests...")
+	if suppressions, err = readSuppressions(ignoreFile, path); err != nil {
+		return err
+	}
+
+	// Validate the webhook before scanning so a typo doesn't waste a run
+	if webhookURL != "" {
+		sink, err := webhook.New(webhookURL, webhookHeaders)
+		if err != nil {
+			return err
 		}
+		webhookSink = sink
+	}
+
+	// Recorded after profiles apply so the scan manifest shows effective values
+	reportFlags = effectiveFlags(cmd.Flags())
+	return nil
+}
 
-		result, err := s.Scan()
+// runScan scans and audits, then writes the report
+func runScan(cmd *cobra.Command, args []string) {
+	// --schema describes the JSON report rather than producing one
+	if printSchema {
+		schema, err := formatter.JSONSchema()
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error scanning directory: %v\n", err)
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
+		fmt.Fprintln(cmd.OutOrStdout(), schema)
+		return
+	}
 
-		// Display any errors encountered during scanning
-		if len(result.Errors) > 0 && verbose && format == "table" {
-			fmt.Println("\nWarnings during scan:")
-			for _, scanErr := range result.Errors {
-				fmt.Printf("  - %v\n", scanErr)
-			}
-			fmt.Println()
-		}
+	if verbose {
+		fmt.Fprintf(os.Stderr, "Snoop v%s\n", version)
+		fmt.Fprintf(os.Stderr, "Scanning directory: %s\n", path)
+		fmt.Fprintf(os.Stderr, "Output format: %s\n", format)
+		fmt.Fprintf(os.Stderr, "Minimum severity: %s\n", severity)
+		fmt.Fprintln(os.Stderr)
+	}
 
-		// Check if manifests found
-		if !result.HasManifests() {
-			fmt.Println("No package manifests found in the specified directory.")
-			return
+	// Interrupting cancels OSV requests and npm audits in flight, rather than
+	// leaving them to run out their timeouts
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Bulk mode scans many directories and streams one JSON report per line
+	if pathsFrom != "" {
+		source, err := openPathsSource(pathsFrom)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		dirs, err := readPaths(source)
+		_ = source.Close()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
 		}
 
-		// Check which types of manifests we found
-		hasNodeJS := false
-		hasPython := false
-		hasGo := false
-		hasMaven := false
-		for _, file := range result.Files {
-			if scanner.IsNodeJSManifest(file.Type) {
-				hasNodeJS = true
-			}
-			if scanner.IsPythonManifest(file.Type) {
-				hasPython = true
-			}
-			if scanner.IsGoManifest(file.Type) {
-				hasGo = true
-			}
-			if scanner.IsMavenManifest(file.Type) {
-				hasMaven = true
+		w := io.Writer(os.Stdout)
+		var file *os.File
+		if outputFile != "" {
+			if file, err = createOutputFile(outputFile); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
 			}
+			w = file
 		}
 
-		// Check if npm is installed (only if we have Node.js manifests)
-		if hasNodeJS {
-			if err := audit.CheckNpmInstalled(); err != nil {
-				if verbose && format == "table" {
-					fmt.Fprintf(os.Stderr, "Warning: npm is not installed. Skipping Node.js audit.\n")
-				}
-				hasNodeJS = false
+		err = runBulk(ctx, dirs, w)
+		if file != nil {
+			if closeErr := file.Close(); err == nil && closeErr != nil {
+				err = fmt.Errorf("failed to write output file %s: %w", outputFile, closeErr)
 			}
 		}
+		exitIfInterrupted(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
 
-		// Python, Go, and Maven auditing use built-in OSV API, no external tools needed
+	// Daemon mode rescans on a schedule until interrupted
+	if daemonMode {
+		newDaemon(path).run(ctx, daemonInterval)
+		return
+	}
 
-		// If we have no tools available for Node.js and no Python/Go/Maven manifests, exit
-		if !hasNodeJS && !hasPython && !hasGo && !hasMaven {
-			fmt.Println("\nNo audit tools available. Please install npm for Node.js auditing.")
-			fmt.Println("Python, Go, and Maven auditing use built-in vulnerability database (no additional tools needed).")
-			return
+	// An SBOM replaces snoop's own manifest detection entirely
+	if sbomPath != "" {
+		if verbose {
+			fmt.Fprintf(os.Stderr, "Auditing SBOM: %s\n", sbomPath)
 		}
 
-		// Get package.json files
-		packageJSONFiles := result.GetManifestsByType(scanner.PackageJSON)
-		if hasNodeJS && len(packageJSONFiles) == 0 {
-			if verbose && format == "table" {
-				fmt.Println("\nNo package.json files found. Skipping npm audit.")
-			}
-		}
+		runner := newAuditRunner(ctx, verbose)
+		sbomResult := runner.RunSBOMAudit(sbomPath)
+		exitIfInterrupted(ctx)
+		audit.FilterSeverity(sbomResult, reportSeverity())
+		writeReport(&formatter.ScanOutput{
+			Metadata:           newOutputMetadata(path),
+			ScanResults:        &scanner.ScanResult{},
+			SBOMAuditResults:   []*audit.SBOMAuditResult{sbomResult},
+			ShowCrossEcosystem: crossEcosystem,
+			ShowSeverityMatrix: severityReport,
+			Backends:           []formatter.ScanBackend{{Name: "osv", Endpoint: runner.OSVEndpoint()}},
+			TotalVulns:         sbomResult.Summary.Total,
+			HasErrors:          sbomResult.Error != nil,
+		})
+		return
+	}
 
-		if verbose && format == "table" {
-			fmt.Printf("\nRunning npm audit on %d package.json file(s)...\n", len(packageJSONFiles))
+	var output *formatter.ScanOutput
+	var notice string
+	var err error
+	if manifestsFrom != "" {
+		// An explicit manifest list replaces the directory walk
+		var paths []string
+		paths, err = readManifestList(manifestsFrom)
+		if err == nil {
+			output, notice, err = scanManifestList(ctx, path, paths, verbose)
 		}
+	} else {
+		output, notice, err = scanProject(ctx, path, verbose)
+	}
+	exitIfInterrupted(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if notice != "" {
+		fmt.Println(notice)
+		return
+	}
+
+	writeReport(output)
+}
 
-		// Create audit runner with 60 second timeout
-		runner := audit.NewRunner(60*time.Second, verbose && format == "table")
+// scanProject detects the manifests under dir and audits them. When there is
+// nothing to audit it returns a notice to show instead of a report.
+func scanProject(ctx context.Context, dir string, logProgress bool) (*formatter.ScanOutput, string, error) {
+	// Create scanner
+	s, err := scanner.New(dir, logProgress)
+	if err != nil {
+		return nil, "", err
+	}
 
-		// Convert severity flag to audit.Severity type
-		minSeverity := audit.Severity(severity)
+	// Scan for manifest files
+	if logProgress {
+		fmt.Fprintln(os.Stderr, "Scanning for Node.js package manifests...")
+	}
 
-		// Track overall results
-		totalVulnerabilities := 0
-		hasErrors := false
-		auditResults := make([]*audit.AuditResult, 0)
+	s.SetExcludes(excludePaths)
+	s.SetRespectGitignore(respectGitignore)
+	s.SetMaxDepth(maxDepth)
+	s.SetFollowSymlinks(followSymlinks)
 
-		// Run audit on each package.json
-		for _, pkgFile := range packageJSONFiles {
-			if verbose && format == "table" {
-				fmt.Printf("\nAuditing: %s\n", pkgFile.Path)
-			}
+	result, err := s.Scan()
+	if err != nil {
+		return nil, "", fmt.Errorf("scanning directory: %w", err)
+	}
 
-			auditResult := runner.RunAudit(pkgFile.Path)
+	return auditManifests(ctx, dir, result, logProgress)
+}
 
-			if auditResult.Error != nil {
-				hasErrors = true
-			}
+// auditManifests audits the manifests found by a scan of dir, stopping early
+// once ctx is done. When there is nothing to audit it returns a notice to show
+// instead of a report.
+func auditManifests(ctx context.Context, dir string, result *scanner.ScanResult, logProgress bool) (*formatter.ScanOutput, string, error) {
+	// Display any errors encountered during scanning
+	if (len(result.Errors) > 0 || len(result.Warnings) > 0) && logProgress {
+		fmt.Fprintln(os.Stderr, "\nWarnings during scan:")
+		for _, scanErr := range result.Errors {
+			fmt.Fprintf(os.Stderr, "  - %v\n", scanErr)
+		}
+		for _, warning := range result.Warnings {
+			fmt.Fprintf(os.Stderr, "  - %s\n", warning)
+		}
+		fmt.Fprintln(os.Stderr)
+	}
 
-			// Filter vulnerabilities by severity
-			auditResult.Vulnerabilities = audit.FilterBySeverity(auditResult.Vulnerabilities, minSeverity)
+	// Leave out the ecosystems not selected with --ecosystems
+	result.Files = filterEcosystems(result.Files, ecosystems)
 
-			auditResults = append(auditResults, auditResult)
-			totalVulnerabilities += auditResult.Summary.Total
+	// Runtime version declarations are only audited with --runtime, so
+	// without it they don't count as manifests
+	if !checkRuntime {
+		result.Files = slices.DeleteFunc(result.Files, func(file scanner.DetectedFile) bool {
+			return scanner.IsRuntimeManifest(file.Type)
+		})
+	}
+
+	// Check if manifests found
+	if !result.HasManifests() {
+		if len(ecosystems) > 0 {
+			return nil, fmt.Sprintf("No %s manifests found in the specified directory.", strings.Join(ecosystems, ", ")), nil
 		}
+		return nil, "No package manifests found in the specified directory.", nil
+	}
 
-		// Run Python audits
-		pythonAuditResults := make([]*audit.PythonAuditResult, 0)
-
-		if hasPython {
-			// Get Python manifest files that pip-audit supports
-			pythonManifests := []scanner.DetectedFile{}
-			for _, manifestType := range []scanner.ManifestType{
-				scanner.RequirementsTxt,
-				scanner.Pipfile,
-				scanner.PyprojectTOML,
-			} {
-				pythonManifests = append(pythonManifests, result.GetManifestsByType(manifestType)...)
-			}
+	progress := newAuditProgress()
+	defer progress.finish()
+
+	runner := newAuditRunner(ctx, logProgress)
+	runner.SetIncludePolicy(audit.IncludePolicy{Root: dir, Strict: strictIncludes})
+
+	opts := pipeline.Options{
+		Runner:              runner,
+		MinSeverity:         reportSeverity(),
+		SkipVulnerabilities: !slices.Contains(checks, checkVuln),
+		Runtime:             checkRuntime,
+		CombineGo:           goCombined,
+		SupplyChain: security.ManifestOptions{
+			Checks:          supplyChainChecks(),
+			Fetch:           security.FetchOptions{RegistryURL: registryURL},
+			PopularPackages: popularPackages,
+			MinConfidence:   minTyposquatConfidence,
+		},
+		Concurrency: manifestConcurrency,
+		Verbose:     logProgress,
+	}
+	// A nil *auditProgress would still make a non-nil Progress
+	if progress != nil {
+		opts.Progress = progress
+	}
 
-			if len(pythonManifests) > 0 && verbose && format == "table" {
-				fmt.Printf("\nChecking %d Python manifest file(s) for vulnerabilities using OSV API...\n", len(pythonManifests))
-			}
+	output, err := pipeline.Audit(ctx, result, opts)
+	if errors.Is(err, pipeline.ErrNothingToAudit) {
+		return nil, "\nNo audit tools available. Please install npm for Node.js auditing.\n" +
+			"Python, Go, Maven, Swift, Cargo, Ruby, and Composer auditing use built-in vulnerability database (no additional tools needed).", nil
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	output.Metadata = newOutputMetadata(dir)
+	output.ShowFixes = showFixes
+	output.ShowCrossEcosystem = crossEcosystem
+	output.ShowSeverityMatrix = severityReport
+	return output, "", nil
+}
+
+// supplyChainChecks returns the --checks values that select supply chain checks
+func supplyChainChecks() []string {
+	var selected []string
+	for _, check := range checks {
+		if check != checkVuln && !slices.Contains(selected, check) {
+			selected = append(selected, check)
+		}
+	}
+	return selected
+}
 
-			for _, manifestFile := range pythonManifests {
-				if verbose && format == "table" {
-					fmt.Printf("\nAuditing Python: %s\n", manifestFile.Path)
-				}
+// newAuditRunner creates an audit runner configured from the command-line
+// flags, whose audits stop once ctx is done
+func newAuditRunner(ctx context.Context, logProgress bool) *audit.Runner {
+	// Create audit runner with 60 second timeout
+	runner := audit.NewRunner(60*time.Second, logProgress)
+	runner.SetContext(ctx)
+	runner.SetMaxUnpinnedAdvisories(maxUnpinnedAdvisories)
+	runner.SetIncludePrerelease(includePrerelease)
+	runner.SetIncludeIndirect(includeIndirect)
+	runner.SetExcludedPackages(packageExclusions)
+	runner.SetMinTyposquattingConfidence(minTyposquatConfidence)
+	if osvClient != nil {
+		runner.SetOSVClient(osvClient)
+	} else {
+		if osvURL != "" {
+			runner.SetOSVClient(osv.NewClientWithURL(osv.QueryURL(osvURL)))
+		}
+		runner.SetOSVRateLimit(rateLimit)
+		if cache := osvCache(); cache != nil {
+			runner.SetOSVCache(cache)
+		}
+	}
+	if autoConcurrency {
+		runner.EnableAutoConcurrency()
+	}
+	return runner
+}
 
-				pythonResult := runner.RunPythonAudit(manifestFile.Path, string(manifestFile.Type))
+// exitIfInterrupted ends the run when a signal cancelled ctx, instead of
+// reporting a scan that was cut short
+func exitIfInterrupted(ctx context.Context) {
+	if ctx.Err() != nil {
+		fmt.Fprintln(os.Stderr, "Interrupted")
+		os.Exit(exitInterrupted)
+	}
+}
 
-				if pythonResult.Error != nil {
-					hasErrors = true
-				}
+// validateServiceURL checks that the value of a flag naming a service, such
+// as --osv-url, is an http or https URL. An empty value uses the default.
+func validateServiceURL(flagName, value string) error {
+	if value == "" {
+		return nil
+	}
+	parsed, err := url.Parse(value)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("invalid --%s %q: must be an http or https URL", flagName, value)
+	}
+	return nil
+}
 
-				// Note: Python audit doesn't provide detailed severity, so we can't filter by severity
-				// All vulnerabilities are currently treated as "high" in the python audit module
+// osvCache returns the on-disk cache of OSV responses, or nil when --no-cache
+// is set or the user has no cache directory
+func osvCache() *osv.Cache {
+	// A daemon cycle must see advisories published since the last one, which
+	// cached responses would hide for up to --cache-ttl
+	if noCache || daemonMode {
+		return nil
+	}
+	dir, err := osv.DefaultCacheDir()
+	if err != nil {
+		return nil
+	}
+	return osv.NewCache(dir, cacheTTL)
+}
 
-				pythonAuditResults = append(pythonAuditResults, pythonResult)
-				totalVulnerabilities += pythonResult.Summary.Total
-			}
+// effectiveFlags returns the value of every flag in effect. Webhook settings
+// are redacted since URLs and headers commonly carry credentials.
+func effectiveFlags(flagSet *pflag.FlagSet) map[string]string {
+	flags := make(map[string]string)
+	flagSet.VisitAll(func(flag *pflag.Flag) {
+		if flag.Name == "help" {
+			return
+		}
+		value := flag.Value.String()
+		if strings.HasPrefix(flag.Name, "webhook") && flag.Name != "webhook-summary" && flag.Changed {
+			value = "[redacted]"
 		}
+		flags[flag.Name] = value
+	})
+	return flags
+}
 
-		// Run Go audits
-		goAuditResults := make([]*audit.GoAuditResult, 0)
+// newOutputMetadata describes a run over dir
+func newOutputMetadata(dir string) formatter.OutputMetadata {
+	return formatter.OutputMetadata{
+		Timestamp:   time.Now(),
+		Directory:   dir,
+		ToolName:    "Snoop",
+		ToolVersion: version,
+	}
+}
 
-		if hasGo {
-			// Get go.mod files
-			goModFiles := result.GetManifestsByType(scanner.GoMod)
+// reportSeverity returns the minimum severity to report. Info findings are
+// usually noise, so they're only reported with --include-info or --severity info.
+func reportSeverity() audit.Severity {
+	minSeverity := audit.Severity(severity)
+	if includeInfo && minSeverity == audit.SeverityLow {
+		return audit.SeverityInfo
+	}
+	return minSeverity
+}
 
-			if len(goModFiles) > 0 && verbose && format == "table" {
-				fmt.Printf("\nChecking %d Go module file(s) for vulnerabilities using OSV API...\n", len(goModFiles))
-			}
+// exceedsFailOn reports whether the report has findings at or above the
+// --fail-on threshold. Only findings at or above --severity count, so the
+// stricter of the two levels applies.
+func exceedsFailOn(output *formatter.ScanOutput) bool {
+	if failOn == "none" {
+		return false
+	}
+	total := formatter.AggregateSummary(output)
+	return min(total.AtOrAbove(audit.Severity(failOn)), total.AtOrAbove(reportSeverity())) > 0
+}
 
-			for _, goModFile := range goModFiles {
-				if verbose && format == "table" {
-					fmt.Printf("\nAuditing Go: %s\n", goModFile.Path)
-				}
+// prepareReport applies the flags that reshape a report before formatting
+func prepareReport(output *formatter.ScanOutput) {
+	output.Flags = reportFlags
+	output.SummaryOnly = summaryOnly
 
-				goResult := runner.RunGoAudit(goModFile.Path, string(goModFile.Type))
+	if active, _ := audit.ActiveSuppressions(suppressions, time.Now()); len(active) > 0 {
+		formatter.Suppress(output, active)
+	}
 
-				if goResult.Error != nil {
-					hasErrors = true
-				}
+	if onlyFixable {
+		formatter.OnlyFixable(output)
+	}
 
-				goAuditResults = append(goAuditResults, goResult)
-				totalVulnerabilities += goResult.Summary.Total
-			}
-		}
+	// Make the report deterministic for committing to version control
+	if normalized {
+		formatter.Normalize(output)
+	}
+}
 
-		// Run Maven audits
-		mavenAuditResults := make([]*audit.MavenAuditResult, 0)
+// createOutputFile creates the --output file, along with any missing parent
+// directories
+func createOutputFile(name string) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory for output file %s: %w", name, err)
+	}
+	file, err := os.Create(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create output file: %w", err)
+	}
+	return file, nil
+}
 
-		if hasMaven {
-			// Get pom.xml files
-			pomFiles := result.GetManifestsByType(scanner.PomXML)
+// writeOutput prints the formatted report, or writes it to the --output file
+func writeOutput(report string) error {
+	if outputFile == "" {
+		fmt.Println(report)
+		return nil
+	}
 
-			if len(pomFiles) > 0 && verbose && format == "table" {
-				fmt.Printf("\nChecking %d Maven project file(s) for vulnerabilities using OSV API...\n", len(pomFiles))
-			}
+	file, err := createOutputFile(outputFile)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(file, report); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to write output file %s: %w", outputFile, err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write output file %s: %w", outputFile, err)
+	}
+	return nil
+}
 
-			for _, pomFile := range pomFiles {
-				if verbose && format == "table" {
-					fmt.Printf("\nAuditing Maven: %s\n", pomFile.Path)
-				}
+// writeReport formats the output in the requested format and prints it
+func writeReport(output *formatter.ScanOutput) {
+	prepareReport(output)
 
-				mavenResult := runner.RunMavenAudit(pomFile.Path, string(pomFile.Type))
+	// Get formatter and format output
+	formatterInst := formatter.GetFormatter(formatter.OutputFormat(format))
+	formattedOutput, err := formatterInst.Format(output)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error formatting output: %v\n", err)
+		os.Exit(1)
+	}
 
-				if mavenResult.Error != nil {
-					hasErrors = true
-				}
+	if err := writeOutput(formattedOutput); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
 
-				mavenAuditResults = append(mavenAuditResults, mavenResult)
-				totalVulnerabilities += mavenResult.Summary.Total
-			}
-		}
+	if webhookSink != nil {
+		sendWebhook(output)
+	}
 
-		// Prepare output data
-		output := &formatter.ScanOutput{
-			Metadata: formatter.OutputMetadata{
-				Timestamp:   time.Now(),
-				Directory:   path,
-				ToolName:    "Snoop",
-				ToolVersion: version,
-			},
-			ScanResults:        result,
-			AuditResults:       auditResults,
-			PythonAuditResults: pythonAuditResults,
-			GoAuditResults:     goAuditResults,
-			MavenAuditResults:  mavenAuditResults,
-			TotalVulns:         totalVulnerabilities,
-			HasErrors:          hasErrors,
-		}
+	if exceedsFailOn(output) {
+		os.Exit(exitVulnerabilities)
+	}
+	if strict && len(formatter.UnverifiedEcosystems(output)) > 0 {
+		os.Exit(exitUnverified)
+	}
+}
 
-		// Get formatter and format output
-		formatterInst := formatter.GetFormatter(formatter.OutputFormat(format))
-		formattedOutput, err := formatterInst.Format(output)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error formatting output: %v\n", err)
-			os.Exit(1)
-		}
+// sendWebhook posts the JSON report, or its summary, to the configured
+// webhook. Failures are reported without affecting the printed report.
+func sendWebhook(output *formatter.ScanOutput) {
+	var payload string
+	var err error
+	if webhookSummary {
+		payload, err = formatter.FormatSummaryJSON(output)
+	} else {
+		payload, err = (&formatter.JSONFormatter{}).Format(output)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error formatting webhook payload: %v\n", err)
+		return
+	}
+
+	if err := webhookSink.Send([]byte(payload)); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		return
+	}
 
-		fmt.Println(formattedOutput)
-	},
+	if verbose {
+		fmt.Fprintf(os.Stderr, "Report sent to %s\n", webhookURL)
+	}
 }
 
 func init() {
+	// The root command scans too, so existing invocations keep working
+	addScanFlags(rootCmd.Flags())
+	addScanFlags(scanCmd.Flags())
+	rootCmd.AddCommand(scanCmd, versionCmd, explainCmd)
+}
+
+// addScanFlags defines the flags of a scan on flags
+func addScanFlags(flags *pflag.FlagSet) {
 	// Get current directory as default
 	currentDir, err := os.Getwd()
 	if err != nil {
 		currentDir = "."
 	}
 
-	// Define flags
-	rootCmd.Flags().StringVarP(&path, "path", "p", currentDir, "Directory to scan for package manifests")
-	rootCmd.Flags().StringVarP(&format, "format", "f", "table", "Output format (json, table, markdown)")
-	rootCmd.Flags().StringVarP(&severity, "severity", "s", "low", "Minimum severity level to report (critical, high, medium, low)")
-	rootCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output")
+	flags.StringVarP(&path, "path", "p", currentDir, "Directory to scan for package manifests")
+	flags.StringVarP(&format, "format", "f", "table", "Output format (json, table, markdown, sarif, grep, cyclonedx, junit, csv, html, gitlab)")
+	flags.StringVarP(&severity, "severity", "s", "low", "Minimum severity level to report (critical, high, medium, low, info)")
+	flags.BoolVar(&includeInfo, "include-info", false, "Also report info-severity findings, which are excluded by default")
+	flags.BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output (printed to stderr)")
+	flags.BoolVar(&noProgress, "no-progress", false, "Don't show audit progress on stderr; it's only shown when stdout and stderr are terminals")
+	flags.StringVarP(&outputFile, "output", "o", "", "Write the report to this file instead of stdout, creating parent directories")
+	flags.BoolVar(&printSchema, "schema", false, "Print the JSON Schema of the JSON report (--format json) and exit")
+	flags.IntVar(&maxUnpinnedAdvisories, "max-unpinned-advisories", audit.DefaultMaxUnpinnedAdvisories, "Collapse advisories for packages without a pinned version when more than this many are found (0 disables)")
+	flags.StringVar(&profile, "profile", "", fmt.Sprintf("Apply a preset of flag defaults (%s); explicit flags take precedence", strings.Join(profileNames(), ", ")))
+	flags.BoolVar(&checkRuntime, "runtime", false, "Also check declared runtime versions (.nvmrc, .python-version, .tool-versions, go directive) for vulnerabilities")
+	flags.BoolVar(&autoConcurrency, "auto-concurrency", false, "Query the OSV API in parallel, adapting concurrency to its rate limits")
+	flags.IntVar(&manifestConcurrency, "manifest-concurrency", pipeline.DefaultConcurrency, "How many manifest files to audit at once; 1 audits them one at a time")
+	flags.StringVar(&osvURL, "osv-url", "", "Base URL of a self-hosted OSV API mirror serving /v1/query, such as https://osv.example.com (default https://api.osv.dev)")
+	flags.Float64Var(&rateLimit, "rate-limit", osv.DefaultRateLimit, "Most OSV API requests to send per second, shared by every parallel query; 0 disables the limit")
+	flags.StringVar(&registryURL, "registry-url", "", "npm registry to fetch package metadata from for supply chain checks (default "+security.DefaultRegistryURL+")")
+	flags.BoolVar(&noCache, "no-cache", false, "Query OSV for every package instead of reusing responses cached in ~/.cache/snoop/osv (always the case with --daemon)")
+	flags.DurationVar(&cacheTTL, "cache-ttl", osv.DefaultCacheTTL, "How long cached OSV responses are reused before OSV is queried again")
+	flags.StringVar(&sbomPath, "sbom", "", "Audit the components of a CycloneDX or SPDX JSON SBOM instead of scanning for manifests")
+	flags.BoolVar(&goCombined, "go-combined", false, "Audit all go.mod files as one deduplicated module set instead of one report per module")
+	flags.BoolVar(&severityReport, "severity-report", false, "Open markdown output with a severity-by-ecosystem matrix")
+	flags.BoolVar(&crossEcosystem, "cross-ecosystem", false, "Show advisories that affect dependencies in more than one ecosystem")
+	flags.BoolVar(&strictIncludes, "strict-includes", false, "Skip requirements.txt -r includes that resolve outside the scanned directory instead of following them")
+	flags.BoolVar(&includePrerelease, "include-prerelease", true, "Consider pre-release pins (e.g. 2.0.0-rc.1) affected by ranges that don't name a pre-release of the same version")
+	flags.BoolVar(&includeIndirect, "include-indirect", false, "Also audit go.mod requirements marked // indirect, which are skipped by default")
+	flags.StringSliceVar(&ecosystems, "ecosystems", nil, fmt.Sprintf("Only scan and audit these ecosystems, comma-separated (%s); default all", strings.Join(ecosystemNames, ", ")))
+	flags.StringSliceVar(&checks, "checks", []string{checkVuln}, fmt.Sprintf("Checks to run, comma-separated (%s); all but vuln inspect Node.js dependencies, and typosquat Python ones audited with vuln", strings.Join(checkNames, ", ")))
+	flags.StringVar(&typosquatList, "typosquat-list", "", "File of package names, one per line, to check for typosquats in addition to the built-in popular npm packages")
+	flags.BoolVar(&typosquatOnly, "typosquat-list-only", false, "Check for typosquats of the --typosquat-list names only, instead of adding them to the built-in list")
+	flags.StringVar(&typosquatConfidence, "typosquat-confidence", string(security.ConfidenceHigh), "Lowest confidence of typosquatting match to report (high, medium, low), scored by how much of the popular name a match changes")
+	flags.BoolVar(&showFixes, "fix", false, "Show how to fix each Node.js and Go finding, including overrides for transitive dependencies")
+	flags.BoolVar(&summaryOnly, "summary", false, "Print only the overall vulnerability counts and manifest count, without per-manifest results (table, markdown, and json)")
+	flags.StringVar(&failOn, "fail-on", "none", fmt.Sprintf("Exit with status 1 when a reported finding is at or above this severity (%s)", strings.Join(failOnLevels, ", ")))
+	flags.BoolVar(&strict, "strict", false, "Exit with status 2 when an ecosystem couldn't be verified because its vulnerability backend was unavailable")
+	flags.BoolVar(&onlyFixable, "only-fixable", false, "Only report findings with a published fix; the rest are counted as hidden")
+	flags.StringVar(&pathsFrom, "paths-from", "", "Scan each directory listed in this file (\"-\" for stdin) and print one JSON report per line")
+	flags.StringVar(&manifestsFrom, "manifests-from", "", "Audit the manifest files listed in this file (\"-\" for stdin), one path per line, instead of scanning --path")
+	flags.StringSliceVar(&excludePaths, "exclude", nil, "Skip files and directories matching these comma-separated globs, relative to --path (e.g. **/testdata/**)")
+	flags.IntVar(&maxDepth, "max-depth", -1, "Deepest directory level to scan below --path; 0 scans only --path itself, -1 has no limit")
+	flags.BoolVar(&followSymlinks, "follow-symlinks", false, "Scan symlinked directories and manifests; each directory is scanned once, so link cycles are skipped")
+	flags.BoolVar(&respectGitignore, "respect-gitignore", true, "Skip paths ignored by .gitignore files found while scanning; =false scans everything")
+	flags.StringArrayVar(&excludePackages, "exclude-package", nil, "Leave a package out of the audit entirely, as ecosystem:name such as npm:@acme/ui (repeatable)")
+	flags.StringVar(&excludePackagesFrom, "exclude-packages-from", "", "Leave the packages listed in this file out of the audit, one ecosystem:name per line")
+	flags.StringVar(&ignoreFile, "ignore-file", "", "Suppression file listing accepted-risk advisories (YAML or JSON; default: .snoopignore in --path)")
+	flags.BoolVar(&daemonMode, "daemon", false, "Keep running, rescanning every --interval and alerting only when findings change")
+	flags.DurationVar(&daemonInterval, "interval", 6*time.Hour, "Time between scans in --daemon mode")
+	flags.StringVar(&outputDir, "output-dir", "", fmt.Sprintf("Write each --daemon report to this directory, keeping the newest %d", daemonReportsKept))
+	flags.StringVar(&webhookURL, "webhook", "", "POST the JSON report to this URL after the scan")
+	flags.StringArrayVar(&webhookHeaders, "webhook-header", nil, "Header to send with the webhook, as \"Name: value\" (repeatable)")
+	flags.BoolVar(&webhookSummary, "webhook-summary", false, "Send only the summary counts to the webhook instead of the full report")
+	flags.BoolVar(&normalized, "normalized", false, "Produce a deterministic, diff-friendly report (sorted, relative paths, no timestamp)")
 }
 
 func main() {
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/brandonapol/snoop/formatter"
+	"github.com/brandonapol/snoop/scanner"
+)
+
+// scanManifestList audits the manifests listed in paths instead of those found
+// by walking dir, which only serves as the report's root. Listed paths that
+// can't be audited are skipped with a warning.
+func scanManifestList(ctx context.Context, dir string, paths []string, logProgress bool) (*formatter.ScanOutput, string, error) {
+	if logProgress {
+		fmt.Fprintf(os.Stderr, "Classifying %d listed manifest path(s)...\n", len(paths))
+	}
+
+	result := scanner.ScanFiles(paths, logProgress)
+	if !result.HasManifests() {
+		notice := "No package manifests found in the list."
+		if len(result.Warnings) > 0 {
+			notice += "\n  - " + strings.Join(result.Warnings, "\n  - ")
+		}
+		return nil, notice, nil
+	}
+
+	return auditManifests(ctx, dir, result, logProgress)
+}
+
+// readManifestList reads the --manifests-from list, where "-" means stdin
+func readManifestList(name string) ([]string, error) {
+	source, err := openPathsSource(name)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = source.Close() }()
+
+	return readPaths(source)
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/brandonapol/snoop/scanner"
+)
+
+func TestScanManifestListSkipsBogusPaths(t *testing.T) {
+	// Manifests without dependencies need no network access
+	dir := t.TempDir()
+	requirements := filepath.Join(dir, "requirements.txt")
+	if err := os.WriteFile(requirements, []byte("# no dependencies\n"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	goMod := filepath.Join(dir, "service", "go.mod")
+	if err := os.MkdirAll(filepath.Dir(goMod), 0o755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(goMod, []byte("module example.com/service\n"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	bogus := filepath.Join(dir, "notes.txt")
+
+	list := filepath.Join(t.TempDir(), "manifests.txt")
+	content := requirements + "\n" + bogus + "\n" + goMod + "\n"
+	if err := os.WriteFile(list, []byte(content), 0o644); err != nil {
+		t.Fatalf("failed to write manifest list: %v", err)
+	}
+
+	paths, err := readManifestList(list)
+	if err != nil {
+		t.Fatalf("readManifestList() error = %v", err)
+	}
+	output, notice, err := scanManifestList(context.Background(), dir, paths, false)
+	if err != nil {
+		t.Fatalf("scanManifestList() error = %v", err)
+	}
+	if output == nil {
+		t.Fatalf("scanManifestList() found no manifests: %s", notice)
+	}
+
+	if len(output.PythonAuditResults) != 1 || output.PythonAuditResults[0].ManifestPath != requirements {
+		t.Errorf("PythonAuditResults = %+v, expected only %s", output.PythonAuditResults, requirements)
+	}
+	if len(output.GoAuditResults) != 1 {
+		t.Errorf("GoAuditResults has %d results, expected 1", len(output.GoAuditResults))
+	}
+
+	warnings := output.ScanResults.Warnings
+	if len(warnings) != 1 || !strings.Contains(warnings[0], bogus) {
+		t.Errorf("ScanResults.Warnings = %v, expected one warning about %s", warnings, bogus)
+	}
+}
+
+func TestRuntimeFilesNeedRuntimeFlag(t *testing.T) {
+	result := &scanner.ScanResult{Files: []scanner.DetectedFile{
+		{Path: ".nvmrc", Type: scanner.Nvmrc},
+		{Path: ".python-version", Type: scanner.PythonVersion},
+	}}
+
+	output, notice, err := auditManifests(context.Background(), t.TempDir(), result, false)
+	if err != nil {
+		t.Fatalf("auditManifests() error = %v", err)
+	}
+	if output != nil || !strings.Contains(notice, "No package manifests found") {
+		t.Errorf("auditManifests() notice = %q, expected no manifests without --runtime", notice)
+	}
+}
@@ -0,0 +1,138 @@
+package osv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// AdvisoryStore holds every advisory seen during a run, keyed by OSV ID, so
+// details for findings that were already fetched never need another request
+type AdvisoryStore struct {
+	mu         sync.RWMutex
+	advisories map[string]Vulnerability
+}
+
+// NewAdvisoryStore creates an empty advisory store
+func NewAdvisoryStore() *AdvisoryStore {
+	return &AdvisoryStore{advisories: make(map[string]Vulnerability)}
+}
+
+// Add records advisories, replacing any earlier copy with the same ID. A nil
+// store records nothing.
+func (s *AdvisoryStore) Add(vulns ...Vulnerability) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, vuln := range vulns {
+		if vuln.ID != "" {
+			s.advisories[vuln.ID] = vuln
+		}
+	}
+}
+
+// Get returns the advisory with the given OSV ID if it has been seen
+func (s *AdvisoryStore) Get(id string) (Vulnerability, bool) {
+	if s == nil {
+		return Vulnerability{}, false
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	vuln, ok := s.advisories[id]
+	return vuln, ok
+}
+
+// Len returns the number of advisories in the store
+func (s *AdvisoryStore) Len() int {
+	if s == nil {
+		return 0
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.advisories)
+}
+
+// Advisories returns the store of every advisory this client has returned
+func (c *Client) Advisories() *AdvisoryStore {
+	return c.advisories
+}
+
+// GetVulnerability returns the advisory with the given OSV ID, answering from
+// the advisory store when the advisory was already returned by a query. It
+// returns an error wrapping ErrNotFound when OSV has no such advisory.
+func (c *Client) GetVulnerability(id string) (*Vulnerability, error) {
+	return c.GetVulnerabilityContext(context.Background(), id)
+}
+
+// GetVuln is GetVulnerability: it returns the advisory with the given OSV ID,
+// or an error wrapping ErrNotFound when OSV has no such advisory
+func (c *Client) GetVuln(id string) (*Vulnerability, error) {
+	return c.GetVulnerability(id)
+}
+
+// GetVulnerabilityContext is GetVulnerability, giving up as soon as ctx is done
+func (c *Client) GetVulnerabilityContext(ctx context.Context, id string) (*Vulnerability, error) {
+	if vuln, ok := c.advisories.Get(id); ok {
+		return &vuln, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.vulnURL(id), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if err := c.waitForRate(ctx); err != nil {
+		return nil, fmt.Errorf("failed to fetch advisory %s: %w", id, err)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch advisory %s: %w", id, err)
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("failed to close response body: %w", closeErr)
+		}
+	}()
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return nil, ErrRateLimited
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("OSV API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var vuln Vulnerability
+	if err := json.Unmarshal(body, &vuln); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal advisory: %w", err)
+	}
+
+	c.advisories.Add(vuln)
+	return &vuln, nil
+}
+
+// vulnURL returns the /v1/vulns/{id} endpoint next to the query endpoint
+func (c *Client) vulnURL(id string) string {
+	return strings.TrimSuffix(c.apiURL, "/query") + "/vulns/" + url.PathEscape(id)
+}
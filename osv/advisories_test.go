@@ -0,0 +1,115 @@
+package osv
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestKnownAdvisoryIsNotFetchedAgain(t *testing.T) {
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id, ok := strings.CutPrefix(r.URL.Path, "/vulns/"); ok {
+			fetches.Add(1)
+			if err := json.NewEncoder(w).Encode(Vulnerability{ID: id, Summary: "fetched"}); err != nil {
+				t.Errorf("failed to encode mock advisory: %v", err)
+			}
+			return
+		}
+		if err := json.NewEncoder(w).Encode(QueryResponse{Vulns: []Vulnerability{{ID: "GHSA-known", Summary: "queried"}}}); err != nil {
+			t.Errorf("failed to encode mock response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClientWithURL(server.URL)
+	if _, err := client.QueryPackage(Package{Name: "lodash", Version: "4.17.20", Ecosystem: NPM}); err != nil {
+		t.Fatalf("QueryPackage() unexpected error: %v", err)
+	}
+
+	vuln, err := client.GetVulnerability("GHSA-known")
+	if err != nil {
+		t.Fatalf("GetVulnerability() unexpected error: %v", err)
+	}
+	if vuln.Summary != "queried" {
+		t.Errorf("GetVulnerability() summary = %q, expected the queried copy", vuln.Summary)
+	}
+	if fetches.Load() != 0 {
+		t.Errorf("GetVulnerability() made %d fetches for a known advisory, expected 0", fetches.Load())
+	}
+
+	// An unknown advisory is fetched once and then served from the store
+	for range 2 {
+		if _, err := client.GetVulnerability("GHSA-unknown"); err != nil {
+			t.Fatalf("GetVulnerability() unexpected error: %v", err)
+		}
+	}
+	if fetches.Load() != 1 {
+		t.Errorf("GetVulnerability() made %d fetches for an unknown advisory, expected 1", fetches.Load())
+	}
+	if client.Advisories().Len() != 2 {
+		t.Errorf("Advisories().Len() = %d, expected 2", client.Advisories().Len())
+	}
+}
+
+func TestVulnURL(t *testing.T) {
+	tests := []struct {
+		apiURL   string
+		id       string
+		expected string
+	}{
+		{osvAPIURL, "GHSA-xxxx-yyyy-zzzz", "https://api.osv.dev/v1/vulns/GHSA-xxxx-yyyy-zzzz"},
+		{"http://127.0.0.1:8080", "PYSEC-2024-1", "http://127.0.0.1:8080/vulns/PYSEC-2024-1"},
+	}
+
+	for _, tt := range tests {
+		client := NewClientWithURL(tt.apiURL)
+		if got := client.vulnURL(tt.id); got != tt.expected {
+			t.Errorf("vulnURL(%q) = %q, expected %q", tt.id, got, tt.expected)
+		}
+	}
+}
+
+func TestGetVulnerabilityNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"code":5,"message":"Bug not found."}`, http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := NewClientWithURL(server.URL)
+	vuln, err := client.GetVulnerability("GHSA-missing")
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("GetVulnerability() error = %v, expected ErrNotFound", err)
+	}
+	if vuln != nil {
+		t.Errorf("GetVulnerability() = %+v, expected nil", vuln)
+	}
+	if client.Advisories().Len() != 0 {
+		t.Errorf("Advisories().Len() = %d, expected 0", client.Advisories().Len())
+	}
+}
+
+func TestGetVulnNotFound(t *testing.T) {
+	var fetched atomic.Value
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetched.Store(r.URL.Path)
+		http.Error(w, `{"code":5,"message":"Bug not found."}`, http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := NewClientWithURL(server.URL + "/v1/query")
+	vuln, err := client.GetVuln("GHSA-missing")
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("GetVuln() error = %v, expected ErrNotFound", err)
+	}
+	if vuln != nil {
+		t.Errorf("GetVuln() = %+v, expected nil", vuln)
+	}
+	if path, _ := fetched.Load().(string); path != "/v1/vulns/GHSA-missing" {
+		t.Errorf("GetVuln() fetched %q, expected /v1/vulns/GHSA-missing", path)
+	}
+}
@@ -0,0 +1,236 @@
+package osv
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxBatchQueries is the most queries the querybatch endpoint accepts at once
+const maxBatchQueries = 1000
+
+// BatchQueryRequest represents the OSV API querybatch request
+type BatchQueryRequest struct {
+	Queries []QueryRequest `json:"queries"`
+}
+
+// BatchQueryResponse represents the OSV API querybatch response. Results are
+// in the same order as the queries, and their vulnerabilities carry only an ID
+// and modification time.
+type BatchQueryResponse struct {
+	Results []BatchQueryResult `json:"results"`
+}
+
+// BatchQueryResult lists the vulnerabilities matching one query of a batch
+type BatchQueryResult struct {
+	Vulns         []Vulnerability `json:"vulns"`
+	NextPageToken string          `json:"next_page_token,omitempty"`
+}
+
+// QueryBatch queries the OSV API for many packages with one request per
+// thousand packages, returning responses in the same order as pkgs. The
+// batch endpoint only returns IDs, so each advisory is then fetched once from
+// /v1/vulns/{id} to fill in its summary, severity, and affected ranges.
+// Cached packages aren't sent, and every advisory returned is recorded in the
+// client's advisory store.
+func (c *Client) QueryBatch(pkgs []Package) ([]*QueryResponse, error) {
+	return c.QueryBatchContext(context.Background(), pkgs)
+}
+
+// QueryBatchContext is QueryBatch, giving up as soon as ctx is done
+func (c *Client) QueryBatchContext(ctx context.Context, pkgs []Package) ([]*QueryResponse, error) {
+	responses := make([]*QueryResponse, len(pkgs))
+
+	var pending []int
+	for i, pkg := range pkgs {
+		if c.cache != nil {
+			if response, ok := c.cache.Get(c.apiURL, pkg); ok {
+				c.advisories.Add(response.Vulns...)
+				responses[i] = response
+				continue
+			}
+		}
+		pending = append(pending, i)
+	}
+
+	results := make([]BatchQueryResult, 0, len(pending))
+	for start := 0; start < len(pending); start += maxBatchQueries {
+		chunk := pending[start:min(start+maxBatchQueries, len(pending))]
+		queries := make([]QueryRequest, len(chunk))
+		for j, i := range chunk {
+			queries[j] = QueryRequest{Package: pkgs[i]}
+		}
+
+		batch, err := c.queryBatchAPI(ctx, queries)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, batch...)
+	}
+
+	var ids []string
+	seen := make(map[string]bool)
+	for _, result := range results {
+		for _, vuln := range result.Vulns {
+			if !seen[vuln.ID] {
+				seen[vuln.ID] = true
+				ids = append(ids, vuln.ID)
+			}
+		}
+	}
+	advisories, err := c.fetchVulnerabilities(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	for j, i := range pending {
+		result := results[j]
+
+		// A paged result is rare enough to re-query on its own
+		if result.NextPageToken != "" {
+			response, err := c.QueryPackageContext(ctx, pkgs[i])
+			if err != nil {
+				return nil, err
+			}
+			responses[i] = response
+			continue
+		}
+
+		response := &QueryResponse{}
+		for _, vuln := range result.Vulns {
+			response.Vulns = append(response.Vulns, advisories[vuln.ID])
+		}
+		if c.cache != nil {
+			// A cache that can't be written only costs a re-query next time
+			_ = c.cache.Put(c.apiURL, pkgs[i], response)
+		}
+		responses[i] = response
+	}
+
+	return responses, nil
+}
+
+// queryBatchAPI sends one querybatch request
+func (c *Client) queryBatchAPI(ctx context.Context, queries []QueryRequest) ([]BatchQueryResult, error) {
+	jsonData, err := json.Marshal(BatchQueryRequest{Queries: queries})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal batch request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.batchURL(), bytes.NewReader(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if err := c.waitForRate(ctx); err != nil {
+		return nil, fmt.Errorf("failed to query OSV API: %w", err)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query OSV API: %w", err)
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("failed to close response body: %w", closeErr)
+		}
+	}()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, ErrRateLimited
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("OSV API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var response BatchQueryResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal batch response: %w", err)
+	}
+	if len(response.Results) != len(queries) {
+		return nil, fmt.Errorf("OSV API returned %d batch results for %d queries", len(response.Results), len(queries))
+	}
+
+	return response.Results, nil
+}
+
+// fetchVulnerabilities fetches the full advisory for each ID. Fetches run in
+// parallel when a concurrency controller is set.
+func (c *Client) fetchVulnerabilities(ctx context.Context, ids []string) (map[string]Vulnerability, error) {
+	advisories := make(map[string]Vulnerability, len(ids))
+
+	if c.controller == nil {
+		for _, id := range ids {
+			vuln, err := c.GetVulnerabilityContext(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			advisories[id] = *vuln
+		}
+		return advisories, nil
+	}
+
+	var (
+		mu       sync.Mutex
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	for _, id := range ids {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			vuln, err := c.fetchWithRetry(ctx, id)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				return
+			}
+			advisories[id] = *vuln
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return advisories, nil
+}
+
+// fetchWithRetry fetches an advisory under the concurrency controller,
+// backing off and retrying when rate limited
+func (c *Client) fetchWithRetry(ctx context.Context, id string) (*Vulnerability, error) {
+	for attempt := 1; ; attempt++ {
+		epoch := c.controller.acquire()
+		vuln, err := c.GetVulnerabilityContext(ctx, id)
+		c.controller.release(epoch, err)
+
+		if !errors.Is(err, ErrRateLimited) || attempt > maxRateLimitRetries {
+			return vuln, err
+		}
+		if err := sleepContext(ctx, c.controller.backoff*time.Duration(attempt)); err != nil {
+			return nil, fmt.Errorf("failed to fetch advisory %s: %w", id, err)
+		}
+	}
+}
+
+// batchURL returns the /v1/querybatch endpoint next to the query endpoint
+func (c *Client) batchURL() string {
+	return strings.TrimSuffix(c.apiURL, "/query") + "/querybatch"
+}
@@ -0,0 +1,178 @@
+package osv
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// mockBatchRequests records what a mock batch server was asked for
+type mockBatchRequests struct {
+	mu      sync.Mutex
+	paths   map[string]int // Requests per URL path
+	batched []string       // Package names sent to querybatch
+}
+
+// newMockBatchServer serves querybatch from ids, keyed by package name, and
+// full advisories from /vulns/{id}
+func newMockBatchServer(t *testing.T, ids map[string][]string, paged map[string]bool) (*httptest.Server, *mockBatchRequests) {
+	t.Helper()
+	requests := &mockBatchRequests{paths: make(map[string]int)}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.mu.Lock()
+		requests.paths[r.URL.Path]++
+		requests.mu.Unlock()
+
+		var response any
+		switch {
+		case r.URL.Path == "/querybatch":
+			var request BatchQueryRequest
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			batch := BatchQueryResponse{Results: make([]BatchQueryResult, 0)}
+			for _, query := range request.Queries {
+				requests.mu.Lock()
+				requests.batched = append(requests.batched, query.Package.Name)
+				requests.mu.Unlock()
+				result := BatchQueryResult{}
+				for _, id := range ids[query.Package.Name] {
+					result.Vulns = append(result.Vulns, Vulnerability{ID: id, Modified: "2024-01-01T00:00:00Z"})
+				}
+				if paged[query.Package.Name] {
+					result.NextPageToken = "next"
+				}
+				batch.Results = append(batch.Results, result)
+			}
+			response = batch
+		case strings.HasPrefix(r.URL.Path, "/vulns/"):
+			id := strings.TrimPrefix(r.URL.Path, "/vulns/")
+			response = Vulnerability{
+				ID:       id,
+				Summary:  "summary of " + id,
+				Severity: []Severity{{Type: "CVSS_V3", Score: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"}},
+				Affected: []Affected{{Ranges: []VersionRange{{Type: "ECOSYSTEM", Events: []Event{{Introduced: "0"}, {Fixed: "2.0.0"}}}}}},
+			}
+		default:
+			// The single-package query endpoint
+			var request QueryRequest
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			single := QueryResponse{}
+			for _, id := range ids[request.Package.Name] {
+				single.Vulns = append(single.Vulns, Vulnerability{ID: id, Summary: "queried " + id})
+			}
+			response = single
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Errorf("failed to encode mock response: %v", err)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server, requests
+}
+
+func TestQueryBatchHydratesAdvisoriesInOrder(t *testing.T) {
+	server, requests := newMockBatchServer(t, map[string][]string{
+		"requests": {"GHSA-shared"},
+		"urllib3":  {"GHSA-shared", "PYSEC-2023-1"},
+		"paged":    {"PYSEC-2023-2"},
+	}, map[string]bool{"paged": true})
+
+	client := NewClientWithURL(server.URL + "/query")
+	pkgs := []Package{
+		{Name: "requests", Version: "2.19.0", Ecosystem: PyPI},
+		{Name: "clean", Version: "1.0.0", Ecosystem: PyPI},
+		{Name: "urllib3", Version: "1.24.1", Ecosystem: PyPI},
+		{Name: "paged", Version: "0.1.0", Ecosystem: PyPI},
+	}
+	responses, err := client.QueryBatch(pkgs)
+	if err != nil {
+		t.Fatalf("QueryBatch() unexpected error: %v", err)
+	}
+	if len(responses) != len(pkgs) {
+		t.Fatalf("QueryBatch() returned %d responses, expected %d", len(responses), len(pkgs))
+	}
+
+	ids := func(response *QueryResponse) string {
+		var ids []string
+		for _, vuln := range response.Vulns {
+			ids = append(ids, vuln.ID)
+		}
+		return strings.Join(ids, ",")
+	}
+	expected := []string{"GHSA-shared", "", "GHSA-shared,PYSEC-2023-1", "PYSEC-2023-2"}
+	for i, response := range responses {
+		if got := ids(response); got != expected[i] {
+			t.Errorf("QueryBatch() response for %s = %q, expected %q", pkgs[i].Name, got, expected[i])
+		}
+	}
+
+	hydrated := responses[2].Vulns[1]
+	if hydrated.Summary != "summary of PYSEC-2023-1" || len(hydrated.Severity) != 1 || len(hydrated.Affected) != 1 {
+		t.Errorf("QueryBatch() advisory was not hydrated: %+v", hydrated)
+	}
+	if responses[3].Vulns[0].Summary != "queried PYSEC-2023-2" {
+		t.Errorf("paged result should be re-queried on its own, got %+v", responses[3].Vulns[0])
+	}
+
+	if requests.paths["/querybatch"] != 1 {
+		t.Errorf("querybatch requests = %d, expected 1", requests.paths["/querybatch"])
+	}
+	if requests.paths["/vulns/GHSA-shared"] != 1 {
+		t.Errorf("shared advisory fetched %d times, expected once", requests.paths["/vulns/GHSA-shared"])
+	}
+	if client.Advisories().Len() != 3 {
+		t.Errorf("advisory store has %d advisories, expected 3", client.Advisories().Len())
+	}
+}
+
+func TestQueryBatchSkipsCachedPackages(t *testing.T) {
+	server, requests := newMockBatchServer(t, map[string][]string{"lodash": {"GHSA-fresh"}}, nil)
+
+	cache := NewCache(t.TempDir(), DefaultCacheTTL)
+	cached := Package{Name: "minimist", Version: "1.2.5", Ecosystem: NPM}
+	client := NewClientWithURL(server.URL + "/query")
+	if err := cache.Put(client.Endpoint(), cached, &QueryResponse{Vulns: []Vulnerability{{ID: "GHSA-cached"}}}); err != nil {
+		t.Fatalf("Cache.Put() unexpected error: %v", err)
+	}
+
+	client.SetCache(cache)
+	fresh := Package{Name: "lodash", Version: "4.17.20", Ecosystem: NPM}
+	responses, err := client.QueryBatch([]Package{cached, fresh})
+	if err != nil {
+		t.Fatalf("QueryBatch() unexpected error: %v", err)
+	}
+
+	if len(requests.batched) != 1 || requests.batched[0] != "lodash" {
+		t.Errorf("QueryBatch() sent %v, expected only the uncached lodash", requests.batched)
+	}
+	if responses[0].Vulns[0].ID != "GHSA-cached" || responses[1].Vulns[0].ID != "GHSA-fresh" {
+		t.Errorf("QueryBatch() responses = %+v, %+v", responses[0], responses[1])
+	}
+	if response, ok := cache.Get(client.Endpoint(), fresh); !ok || response.Vulns[0].Summary != "summary of GHSA-fresh" {
+		t.Errorf("QueryBatch() should cache the hydrated response, got %+v", response)
+	}
+}
+
+func TestQueryBatchRejectsMismatchedResults(t *testing.T) {
+	// A server that doesn't implement querybatch answers like a single query
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"vulns": []}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithURL(server.URL)
+	_, err := client.QueryBatch([]Package{{Name: "lodash", Version: "4.17.20", Ecosystem: NPM}})
+	if err == nil || !strings.Contains(err.Error(), "0 batch results for 1 queries") {
+		t.Errorf("QueryBatch() error = %v, expected a result count mismatch", err)
+	}
+}
@@ -0,0 +1,148 @@
+package osv
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultCacheTTL is how long a cached response is used before OSV is queried
+// again, so newly published advisories show up within a day
+const DefaultCacheTTL = 24 * time.Hour
+
+// errCacheUnwritable is returned by Put once a write to the cache has failed
+var errCacheUnwritable = errors.New("cache directory is not writable")
+
+// Cache stores OSV query responses on disk, one JSON file per package version
+// and API endpoint, so a mirror's responses are never served for another's.
+// Entries are written atomically, so one cache directory can be shared by
+// parallel queries and by concurrent snoop processes.
+type Cache struct {
+	dir        string
+	ttl        time.Duration
+	unwritable atomic.Bool // Set after a failed write so later writes are skipped
+}
+
+// cacheEntry is the on-disk format of a cached response
+type cacheEntry struct {
+	Key      string        `json:"key"`
+	StoredAt time.Time     `json:"storedAt"`
+	Response QueryResponse `json:"response"`
+}
+
+// NewCache creates a cache that keeps its entries in dir and treats entries
+// older than ttl as misses
+func NewCache(dir string, ttl time.Duration) *Cache {
+	return &Cache{dir: dir, ttl: ttl}
+}
+
+// DefaultCacheDir returns the per-user cache directory for OSV responses,
+// ~/.cache/snoop/osv on Linux
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate user cache directory: %w", err)
+	}
+	return filepath.Join(dir, "snoop", "osv"), nil
+}
+
+// cacheKey identifies a query by the API endpoint it's sent to, and the
+// ecosystem, name, and version it asks about
+func cacheKey(endpoint string, pkg Package) string {
+	return endpoint + "|" + string(pkg.Ecosystem) + "|" + pkg.Name + "|" + pkg.Version
+}
+
+// entryPath returns the file holding the entry for key
+func (c *Cache) entryPath(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// Get returns the response for pkg cached from endpoint. Entries that can't be
+// parsed, such as one truncated by an interrupted write, are removed and
+// treated as misses, as are entries older than the cache's TTL.
+func (c *Cache) Get(endpoint string, pkg Package) (*QueryResponse, bool) {
+	key := cacheKey(endpoint, pkg)
+	path := c.entryPath(key)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Key != key {
+		_ = os.Remove(path)
+		return nil, false
+	}
+	if time.Since(entry.StoredAt) > c.ttl {
+		return nil, false
+	}
+
+	return &entry.Response, true
+}
+
+// Put stores the response endpoint gave for pkg. The entry is written to a
+// temporary file in the cache directory and renamed into place, so an
+// interrupted write never leaves a partial entry behind. After a write fails, such as in a read-only
+// directory, Put stops trying and the cache serves only what it already holds.
+func (c *Cache) Put(endpoint string, pkg Package, response *QueryResponse) (err error) {
+	if c.unwritable.Load() {
+		return errCacheUnwritable
+	}
+
+	key := cacheKey(endpoint, pkg)
+	data, err := json.Marshal(cacheEntry{
+		Key:      key,
+		StoredAt: time.Now().UTC(),
+		Response: *response,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache entry: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			c.unwritable.Store(true)
+		}
+	}()
+
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	// The temporary file must be in the same directory for the rename to be atomic
+	tmp, err := os.CreateTemp(c.dir, ".entry-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create cache entry: %w", err)
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to sync cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to close cache entry: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, c.entryPath(key)); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to commit cache entry: %w", err)
+	}
+
+	return nil
+}
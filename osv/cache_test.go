@@ -0,0 +1,173 @@
+package osv
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCorruptCacheEntryIsRequeried(t *testing.T) {
+	var queries atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries.Add(1)
+		if err := json.NewEncoder(w).Encode(QueryResponse{Vulns: []Vulnerability{{ID: "GO-2023-0001"}}}); err != nil {
+			t.Errorf("failed to encode mock response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	cacheDir := t.TempDir()
+	cache := NewCache(cacheDir, DefaultCacheTTL)
+	pkg := Package{Name: "github.com/example/lib", Version: "v1.0.0", Ecosystem: Go}
+
+	// Simulate an entry truncated by a process killed mid-write
+	entryPath := cache.entryPath(cacheKey(server.URL, pkg))
+	if err := os.WriteFile(entryPath, []byte(`{"key":"`+cacheKey(server.URL, pkg)+`","response":{"vul`), 0644); err != nil {
+		t.Fatalf("Failed to write corrupt cache entry: %v", err)
+	}
+
+	client := NewClientWithURL(server.URL)
+	client.SetCache(cache)
+
+	response, err := client.QueryPackage(pkg)
+	if err != nil {
+		t.Fatalf("QueryPackage() unexpected error: %v", err)
+	}
+	if len(response.Vulns) != 1 || response.Vulns[0].ID != "GO-2023-0001" {
+		t.Errorf("QueryPackage() = %+v, expected GO-2023-0001", response.Vulns)
+	}
+	if queries.Load() != 1 {
+		t.Errorf("QueryPackage() made %d API queries, expected 1", queries.Load())
+	}
+
+	// The re-fetched response replaces the corrupt entry and is served from cache
+	if _, err := client.QueryPackage(pkg); err != nil {
+		t.Fatalf("QueryPackage() unexpected error: %v", err)
+	}
+	if queries.Load() != 1 {
+		t.Errorf("QueryPackage() made %d API queries after caching, expected 1", queries.Load())
+	}
+
+	// No temporary files are left behind by the atomic write
+	matches, err := filepath.Glob(filepath.Join(cacheDir, ".entry-*.tmp"))
+	if err != nil {
+		t.Fatalf("Failed to list cache directory: %v", err)
+	}
+	if len(matches) != 0 {
+		t.Errorf("cache directory contains temporary files %v", matches)
+	}
+}
+
+func TestCacheEntryForDifferentKeyIsIgnored(t *testing.T) {
+	cache := NewCache(t.TempDir(), DefaultCacheTTL)
+	pkg := Package{Name: "requests", Version: "2.25.0", Ecosystem: PyPI}
+
+	// Write a well-formed entry under the wrong file to mimic a hash collision or tampering
+	data, err := json.Marshal(cacheEntry{Key: osvAPIURL + "|PyPI|flask|2.0.0"})
+	if err != nil {
+		t.Fatalf("Failed to marshal cache entry: %v", err)
+	}
+	if err := os.WriteFile(cache.entryPath(cacheKey(osvAPIURL, pkg)), data, 0644); err != nil {
+		t.Fatalf("Failed to write cache entry: %v", err)
+	}
+
+	if _, ok := cache.Get(osvAPIURL, pkg); ok {
+		t.Errorf("Get() returned an entry stored for a different key")
+	}
+}
+
+func TestExpiredCacheEntryIsAMiss(t *testing.T) {
+	cache := NewCache(t.TempDir(), time.Hour)
+	pkg := Package{Name: "requests", Version: "2.25.0", Ecosystem: PyPI}
+
+	if err := cache.Put(osvAPIURL, pkg, &QueryResponse{Vulns: []Vulnerability{{ID: "PYSEC-2023-74"}}}); err != nil {
+		t.Fatalf("Put() unexpected error: %v", err)
+	}
+	if _, ok := cache.Get(osvAPIURL, pkg); !ok {
+		t.Fatal("Get() missed a fresh entry")
+	}
+
+	data, err := json.Marshal(cacheEntry{Key: cacheKey(osvAPIURL, pkg), StoredAt: time.Now().Add(-2 * time.Hour)})
+	if err != nil {
+		t.Fatalf("Failed to marshal cache entry: %v", err)
+	}
+	if err := os.WriteFile(cache.entryPath(cacheKey(osvAPIURL, pkg)), data, 0644); err != nil {
+		t.Fatalf("Failed to write cache entry: %v", err)
+	}
+	if _, ok := cache.Get(osvAPIURL, pkg); ok {
+		t.Error("Get() returned an entry older than the TTL")
+	}
+}
+
+func TestCacheEntriesAreKeptPerEndpoint(t *testing.T) {
+	newServer := func(id string) (*httptest.Server, *atomic.Int32) {
+		var queries atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			queries.Add(1)
+			if err := json.NewEncoder(w).Encode(QueryResponse{Vulns: []Vulnerability{{ID: id}}}); err != nil {
+				t.Errorf("failed to encode mock response: %v", err)
+			}
+		}))
+		t.Cleanup(server.Close)
+		return server, &queries
+	}
+	public, publicQueries := newServer("GHSA-public")
+	mirror, mirrorQueries := newServer("GHSA-mirror")
+
+	// Both clients share one cache directory, as scans against different --osv-url mirrors do
+	cache := NewCache(t.TempDir(), DefaultCacheTTL)
+	pkg := Package{Name: "lodash", Version: "4.17.20", Ecosystem: NPM}
+	for _, server := range []*httptest.Server{public, mirror} {
+		client := NewClientWithURL(server.URL)
+		client.SetCache(cache)
+		// The second query is served from the cache
+		for range 2 {
+			if _, err := client.QueryPackage(pkg); err != nil {
+				t.Fatalf("QueryPackage() unexpected error: %v", err)
+			}
+		}
+	}
+
+	if publicQueries.Load() != 1 || mirrorQueries.Load() != 1 {
+		t.Errorf("endpoints were queried %d and %d times, expected once each", publicQueries.Load(), mirrorQueries.Load())
+	}
+	for endpoint, id := range map[string]string{public.URL: "GHSA-public", mirror.URL: "GHSA-mirror"} {
+		if response, ok := cache.Get(endpoint, pkg); !ok || response.Vulns[0].ID != id {
+			t.Errorf("Get(%s) = %+v, expected %s", endpoint, response, id)
+		}
+	}
+}
+
+func TestUnwritableCacheFallsBackToQueries(t *testing.T) {
+	var queries atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries.Add(1)
+		if err := json.NewEncoder(w).Encode(QueryResponse{}); err != nil {
+			t.Errorf("failed to encode mock response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	// A regular file where the cache directory should be can't be written to
+	blocker := filepath.Join(t.TempDir(), "osv")
+	if err := os.WriteFile(blocker, nil, 0644); err != nil {
+		t.Fatalf("Failed to write blocking file: %v", err)
+	}
+
+	client := NewClientWithURL(server.URL)
+	client.SetCache(NewCache(blocker, DefaultCacheTTL))
+
+	for range 2 {
+		if _, err := client.QueryPackage(Package{Name: "flask", Version: "2.0.0", Ecosystem: PyPI}); err != nil {
+			t.Fatalf("QueryPackage() unexpected error: %v", err)
+		}
+	}
+	if queries.Load() != 2 {
+		t.Errorf("QueryPackage() made %d API queries, expected 2", queries.Load())
+	}
+}
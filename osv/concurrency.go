@@ -0,0 +1,103 @@
+package osv
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// DefaultMaxConcurrency caps how many queries an adaptive client runs at once
+const DefaultMaxConcurrency = 32
+
+// maxRateLimitRetries is how many times a rate-limited query is retried
+const maxRateLimitRetries = 5
+
+// ConcurrencyController chooses how many OSV queries run at once using
+// additive-increase/multiplicative-decrease: it starts at one, adds one after
+// a full window of successful queries, and halves when the API responds with
+// 429. It never grows back past a level that was rate limited.
+type ConcurrencyController struct {
+	mu        sync.Mutex
+	cond      *sync.Cond
+	limit     int
+	ceiling   int // Highest level not known to trip rate limiting
+	inFlight  int
+	successes int // Successful queries since the last change
+	epoch     int // Bumped on every decrease
+	backoff   time.Duration
+	onChange  func(limit int, reason string)
+}
+
+// NewConcurrencyController creates a controller that never exceeds maxConcurrency queries in flight
+func NewConcurrencyController(maxConcurrency int) *ConcurrencyController {
+	if maxConcurrency < 1 {
+		maxConcurrency = DefaultMaxConcurrency
+	}
+	c := &ConcurrencyController{
+		limit:   1,
+		ceiling: maxConcurrency,
+		backoff: time.Second,
+	}
+	c.cond = sync.NewCond(&c.mu)
+	return c
+}
+
+// OnChange registers a callback invoked whenever the concurrency level changes
+func (c *ConcurrencyController) OnChange(fn func(limit int, reason string)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onChange = fn
+}
+
+// Limit returns the current concurrency level
+func (c *ConcurrencyController) Limit() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.limit
+}
+
+// acquire blocks until a query may start and returns the epoch it started in
+func (c *ConcurrencyController) acquire() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for c.inFlight >= c.limit {
+		c.cond.Wait()
+	}
+	c.inFlight++
+	return c.epoch
+}
+
+// release records the outcome of a query started in the given epoch
+func (c *ConcurrencyController) release(epoch int, err error) {
+	c.mu.Lock()
+	c.inFlight--
+
+	reason := ""
+	switch {
+	case errors.Is(err, ErrRateLimited):
+		// Queries already in flight when the level dropped were started under
+		// the old level, so their 429s don't warrant another decrease
+		if epoch == c.epoch {
+			c.ceiling = max(c.limit-1, 1)
+			c.limit = max(c.limit/2, 1)
+			c.successes = 0
+			c.epoch++
+			reason = "rate limited"
+		}
+	case err == nil:
+		c.successes++
+		if c.successes >= c.limit && c.limit < c.ceiling {
+			c.limit++
+			c.successes = 0
+			reason = "increasing"
+		}
+	}
+
+	limit, onChange := c.limit, c.onChange
+	c.cond.Broadcast()
+	c.mu.Unlock()
+
+	if reason != "" && onChange != nil {
+		onChange(limit, reason)
+	}
+}
@@ -0,0 +1,148 @@
+package osv
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// rateLimitingTransport answers 429 whenever more than threshold requests are in flight
+type rateLimitingTransport struct {
+	mu          sync.Mutex
+	threshold   int
+	inFlight    int
+	rateLimited int
+}
+
+func (t *rateLimitingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	t.inFlight++
+	limited := t.inFlight > t.threshold
+	if limited {
+		t.rateLimited++
+	}
+	t.mu.Unlock()
+
+	// Hold the request open so concurrent queries overlap
+	time.Sleep(5 * time.Millisecond)
+
+	t.mu.Lock()
+	t.inFlight--
+	t.mu.Unlock()
+
+	status, body := http.StatusOK, `{"vulns":[]}`
+	if limited {
+		status, body = http.StatusTooManyRequests, "rate limited"
+	}
+	return &http.Response{
+		StatusCode: status,
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Header:     make(http.Header),
+		Request:    req,
+	}, nil
+}
+
+func TestConcurrencyControllerStabilizesBelowRateLimit(t *testing.T) {
+	const threshold = 4
+
+	transport := &rateLimitingTransport{threshold: threshold}
+	controller := NewConcurrencyController(16)
+	controller.backoff = time.Millisecond
+
+	client := &Client{
+		httpClient: &http.Client{Transport: transport},
+		apiURL:     "http://osv.test/v1/query",
+	}
+	client.SetConcurrencyController(controller)
+
+	var pkgs []Package
+	for i := 0; i < 200; i++ {
+		pkgs = append(pkgs, Package{Name: fmt.Sprintf("pkg-%d", i), Version: "1.0.0", Ecosystem: PyPI})
+	}
+
+	results := client.QueryPackages(pkgs)
+	for i, result := range results {
+		if result.Err != nil {
+			t.Errorf("QueryPackages() result %d error = %v, expected nil", i, result.Err)
+		}
+	}
+
+	if transport.rateLimited == 0 {
+		t.Errorf("QueryPackages() never probed past the rate limit of %d", threshold)
+	}
+	if limit := controller.Limit(); limit < 2 || limit > threshold {
+		t.Errorf("Limit() = %d, expected between 2 and %d", limit, threshold)
+	}
+}
+
+func TestQueryPackagesWithoutControllerKeepsOrder(t *testing.T) {
+	client := &Client{
+		httpClient: &http.Client{Transport: &rateLimitingTransport{threshold: 1}},
+		apiURL:     "http://osv.test/v1/query",
+	}
+
+	pkgs := []Package{{Name: "a", Ecosystem: Go}, {Name: "b", Ecosystem: Go}}
+	results := client.QueryPackages(pkgs)
+	if len(results) != len(pkgs) {
+		t.Fatalf("QueryPackages() returned %d results, expected %d", len(results), len(pkgs))
+	}
+	for i, result := range results {
+		if result.Err != nil || result.Response == nil {
+			t.Errorf("QueryPackages() result %d = %+v, expected a response", i, result)
+		}
+	}
+}
+
+// countingTransport answers every request with status, counting them
+type countingTransport struct {
+	mu       sync.Mutex
+	status   int
+	requests int
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	t.requests++
+	t.mu.Unlock()
+
+	return &http.Response{
+		StatusCode: t.status,
+		Body:       io.NopCloser(strings.NewReader("rate limited")),
+		Header:     make(http.Header),
+		Request:    req,
+	}, nil
+}
+
+func TestControlledQueriesDoNotNestRateLimitRetries(t *testing.T) {
+	transport := &countingTransport{status: http.StatusTooManyRequests}
+	client := NewClientWithURL("http://osv.test/v1/query", WithRetryDelay(time.Millisecond), WithRateLimit(0))
+	client.httpClient = &http.Client{Transport: transport}
+
+	controller := NewConcurrencyController(4)
+	controller.backoff = time.Millisecond
+	var decreases int
+	controller.OnChange(func(limit int, reason string) {
+		if reason == "rate limited" {
+			decreases++
+		}
+	})
+	client.SetConcurrencyController(controller)
+
+	results := client.QueryPackages([]Package{{Name: "requests", Version: "2.25.0", Ecosystem: PyPI}})
+	if !errors.Is(results[0].Err, ErrRateLimited) {
+		t.Fatalf("QueryPackages() error = %v, expected ErrRateLimited", results[0].Err)
+	}
+
+	// Only the controller retries, so every 429 reaches it
+	if expected := maxRateLimitRetries + 1; transport.requests != expected {
+		t.Errorf("sent %d requests, expected %d", transport.requests, expected)
+	}
+	if decreases != transport.requests {
+		t.Errorf("controller saw %d rate limits, expected %d", decreases, transport.requests)
+	}
+}
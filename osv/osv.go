@@ -2,16 +2,31 @@ package osv
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math/rand/v2"
+	"net"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
+
+	"golang.org/x/time/rate"
 )
 
 // OSV API endpoint
 const osvAPIURL = "https://api.osv.dev/v1/query"
 
+// ErrRateLimited is returned when the OSV API responds with 429 Too Many Requests
+var ErrRateLimited = errors.New("OSV API rate limit exceeded")
+
+// ErrNotFound is returned when OSV has no advisory with the requested ID
+var ErrNotFound = errors.New("advisory not found")
+
 // Ecosystem represents the package ecosystem
 type Ecosystem string
 
@@ -20,6 +35,20 @@ const (
 	Go    Ecosystem = "Go"
 	NPM   Ecosystem = "npm"
 	Maven Ecosystem = "Maven"
+
+	// SwiftURL names Swift packages by repository URL, such as github.com/apple/swift-nio
+	SwiftURL Ecosystem = "SwiftURL"
+
+	// CratesIO names Rust crates as Cargo.lock does, such as serde
+	CratesIO Ecosystem = "crates.io"
+
+	// Further ecosystems reachable through SBOM package URLs
+	RubyGems  Ecosystem = "RubyGems"
+	NuGet     Ecosystem = "NuGet"
+	Packagist Ecosystem = "Packagist"
+
+	// Bitnami tracks advisories for language runtimes such as Node.js and Python
+	Bitnami Ecosystem = "Bitnami"
 )
 
 // Package represents a package to query
@@ -76,9 +105,12 @@ type Vulnerability struct {
 	Aliases    []string    `json:"aliases,omitempty"`
 	Modified   string      `json:"modified"`
 	Published  string      `json:"published"`
+	Withdrawn  string      `json:"withdrawn,omitempty"` // When the advisory was retracted, such as after being disputed
 	References []Reference `json:"references,omitempty"`
 	Severity   []Severity  `json:"severity,omitempty"`
 	Affected   []Affected  `json:"affected,omitempty"`
+
+	DatabaseSpecific map[string]any `json:"database_specific,omitempty"`
 }
 
 // QueryResponse represents the OSV API query response
@@ -86,24 +118,195 @@ type QueryResponse struct {
 	Vulns []Vulnerability `json:"vulns"`
 }
 
+// QueryResult is the outcome of a single query made by QueryPackages
+type QueryResult struct {
+	Response *QueryResponse
+	Err      error
+}
+
+// DefaultMaxAttempts is how many times a query is sent before giving up
+const DefaultMaxAttempts = 3
+
+// DefaultRetryDelay is the wait before the first retry of a query; each
+// further retry waits twice as long
+const DefaultRetryDelay = 500 * time.Millisecond
+
+// maxRetryAfter caps how long a Retry-After header may hold up a query
+const maxRetryAfter = 30 * time.Second
+
+// DefaultRateLimit is how many requests per second a client sends the OSV API
+// at most, unless WithRateLimit or SetRateLimit says otherwise
+const DefaultRateLimit = 25.0
+
 // Client represents an OSV API client
 type Client struct {
-	httpClient *http.Client
-	apiURL     string
+	httpClient  *http.Client
+	apiURL      string
+	controller  *ConcurrencyController // Nil queries one package at a time
+	cache       *Cache                 // Nil always queries the API
+	advisories  *AdvisoryStore         // Every advisory returned during this run
+	maxAttempts int                    // Tries per query, counting the first
+	retryDelay  time.Duration          // Base of the exponential backoff between tries
+	limiter     *rate.Limiter          // Paces requests from every goroutine; nil doesn't
+}
+
+// ClientOption configures a client created by NewClient or NewClientWithURL
+type ClientOption func(*Client)
+
+// WithMaxAttempts sets how many times a query is sent before giving up. One
+// disables retries.
+func WithMaxAttempts(attempts int) ClientOption {
+	return func(c *Client) {
+		c.maxAttempts = max(attempts, 1)
+	}
+}
+
+// WithRetryDelay sets the wait before the first retry of a query
+func WithRetryDelay(delay time.Duration) ClientOption {
+	return func(c *Client) {
+		c.retryDelay = delay
+	}
+}
+
+// WithRateLimit sets how many requests per second the client sends at most.
+// Zero or less removes the limit.
+func WithRateLimit(perSecond float64) ClientOption {
+	return func(c *Client) {
+		c.SetRateLimit(perSecond)
+	}
 }
 
 // NewClient creates a new OSV API client
-func NewClient() *Client {
-	return &Client{
+func NewClient(options ...ClientOption) *Client {
+	return NewClientWithURL(osvAPIURL, options...)
+}
+
+// NewClientWithURL creates an OSV API client that queries the given endpoint,
+// such as a self-hosted mirror's /v1/query (see QueryURL). Like every client,
+// it connects through the proxy named by HTTP_PROXY or HTTPS_PROXY, except
+// for hosts listed in NO_PROXY.
+func NewClientWithURL(apiURL string, options ...ClientOption) *Client {
+	client := &Client{
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
-		apiURL: osvAPIURL,
+		apiURL:      apiURL,
+		advisories:  NewAdvisoryStore(),
+		maxAttempts: DefaultMaxAttempts,
+		retryDelay:  DefaultRetryDelay,
+		limiter:     newRateLimiter(DefaultRateLimit),
+	}
+	for _, option := range options {
+		option(client)
 	}
+	return client
 }
 
-// QueryPackage queries the OSV API for vulnerabilities in a package
+// QueryURL returns the /v1/query endpoint of the OSV API served at baseURL,
+// such as https://osv.example.com. A URL that already ends in /v1/query is
+// returned unchanged.
+func QueryURL(baseURL string) string {
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	if strings.HasSuffix(baseURL, "/v1/query") {
+		return baseURL
+	}
+	return baseURL + "/v1/query"
+}
+
+// Endpoint returns the OSV query endpoint this client sends requests to
+func (c *Client) Endpoint() string {
+	return c.apiURL
+}
+
+// SetConcurrencyController makes QueryPackages run queries in parallel at the
+// level chosen by the controller
+func (c *Client) SetConcurrencyController(controller *ConcurrencyController) {
+	c.controller = controller
+}
+
+// SetRateLimit sets how many requests per second the client sends at most,
+// however many goroutines share it: queries, batch queries, and advisory
+// fetches all wait their turn. Zero or less removes the limit.
+func (c *Client) SetRateLimit(perSecond float64) {
+	c.limiter = newRateLimiter(perSecond)
+}
+
+// newRateLimiter returns a limiter spacing requests evenly at perSecond, or
+// nil for no limit. Its burst of one keeps parallel queries from all going
+// out at once.
+func newRateLimiter(perSecond float64) *rate.Limiter {
+	if perSecond <= 0 {
+		return nil
+	}
+	return rate.NewLimiter(rate.Limit(perSecond), 1)
+}
+
+// waitForRate blocks until the rate limit allows another request, returning
+// ctx's error if it's done first
+func (c *Client) waitForRate(ctx context.Context) error {
+	if c.limiter == nil {
+		return nil
+	}
+	return c.limiter.Wait(ctx)
+}
+
+// SetCache makes the client answer queries from cache when possible and store
+// fresh responses in it
+func (c *Client) SetCache(cache *Cache) {
+	c.cache = cache
+}
+
+// QueryPackage queries the OSV API for vulnerabilities in a package. Every
+// advisory returned is recorded in the client's advisory store.
 func (c *Client) QueryPackage(pkg Package) (*QueryResponse, error) {
+	return c.QueryPackageContext(context.Background(), pkg)
+}
+
+// QueryPackageContext is QueryPackage, giving up as soon as ctx is done,
+// whether a request is in flight or waiting to be retried
+func (c *Client) QueryPackageContext(ctx context.Context, pkg Package) (*QueryResponse, error) {
+	return c.queryPackageContext(ctx, pkg, true)
+}
+
+// queryPackageContext is QueryPackageContext. Without retryRateLimited, a 429
+// is returned at once for the caller to back off.
+func (c *Client) queryPackageContext(ctx context.Context, pkg Package, retryRateLimited bool) (*QueryResponse, error) {
+	response, err := c.queryPackage(ctx, pkg, retryRateLimited)
+	if err != nil {
+		return nil, err
+	}
+
+	c.advisories.Add(response.Vulns...)
+	return response, nil
+}
+
+// queryPackage answers a query from cache when possible, falling back to the API
+func (c *Client) queryPackage(ctx context.Context, pkg Package, retryRateLimited bool) (*QueryResponse, error) {
+	if c.cache == nil {
+		return c.queryAPI(ctx, pkg, retryRateLimited)
+	}
+
+	if response, ok := c.cache.Get(c.apiURL, pkg); ok {
+		return response, nil
+	}
+
+	response, err := c.queryAPI(ctx, pkg, retryRateLimited)
+	if err != nil {
+		return nil, err
+	}
+
+	// A cache that can't be written only costs a re-query next time
+	_ = c.cache.Put(c.apiURL, pkg, response)
+
+	return response, nil
+}
+
+// queryAPI sends a query to the OSV API, retrying with exponential backoff
+// and jitter on rate limiting, server errors, and network timeouts. A
+// Retry-After header sets the wait instead when present. Without
+// retryRateLimited, rate limiting is left to the caller, which is how the
+// concurrency controller sees every 429.
+func (c *Client) queryAPI(ctx context.Context, pkg Package, retryRateLimited bool) (*QueryResponse, error) {
 	request := QueryRequest{
 		Package: pkg,
 	}
@@ -113,9 +316,91 @@ func (c *Client) QueryPackage(pkg Package) (*QueryResponse, error) {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	resp, err := c.httpClient.Post(c.apiURL, "application/json", bytes.NewBuffer(jsonData))
+	for attempt := 1; ; attempt++ {
+		response, retryAfter, retry, err := c.postQuery(ctx, jsonData)
+		if errors.Is(err, ErrRateLimited) && !retryRateLimited {
+			return response, err
+		}
+		if err == nil || !retry || attempt >= c.maxAttempts || ctx.Err() != nil {
+			return response, err
+		}
+
+		if retryAfter == 0 {
+			retryAfter = c.backoff(attempt)
+		}
+		if err := sleepContext(ctx, retryAfter); err != nil {
+			return nil, fmt.Errorf("failed to query OSV API: %w", err)
+		}
+	}
+}
+
+// sleepContext waits for d, returning ctx's error if it's done first
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// backoff returns the wait before retry number attempt: the retry delay
+// doubled for each earlier retry, of which a random half is jitter so
+// parallel queries don't retry in lockstep
+func (c *Client) backoff(attempt int) time.Duration {
+	delay := c.retryDelay << (attempt - 1)
+	if delay <= 0 {
+		return 0
+	}
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// retryableStatus reports whether a response status is worth retrying
+func retryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as an HTTP
+// date, returning zero when it is missing or unparsable
+func parseRetryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+
+	var wait time.Duration
+	if seconds, err := strconv.Atoi(header); err == nil {
+		wait = time.Duration(seconds) * time.Second
+	} else if at, err := http.ParseTime(header); err == nil {
+		wait = time.Until(at)
+	}
+	return min(max(wait, 0), maxRetryAfter)
+}
+
+// postQuery makes a single query attempt. On failure it reports whether the
+// query is worth retrying and how long the server asked to wait, if at all.
+func (c *Client) postQuery(ctx context.Context, jsonData []byte) (_ *QueryResponse, retryAfter time.Duration, retry bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.apiURL, bytes.NewReader(jsonData))
+	if err != nil {
+		return nil, 0, false, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if err := c.waitForRate(ctx); err != nil {
+		return nil, 0, false, fmt.Errorf("failed to query OSV API: %w", err)
+	}
+	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query OSV API: %w", err)
+		var netErr net.Error
+		timeout := errors.As(err, &netErr) && netErr.Timeout()
+		return nil, 0, timeout, fmt.Errorf("failed to query OSV API: %w", err)
 	}
 	defer func() {
 		if closeErr := resp.Body.Close(); closeErr != nil && err == nil {
@@ -124,43 +409,105 @@ func (c *Client) QueryPackage(pkg Package) (*QueryResponse, error) {
 	}()
 
 	if resp.StatusCode != http.StatusOK {
+		retryAfter, retry = parseRetryAfter(resp.Header.Get("Retry-After")), retryableStatus(resp.StatusCode)
+		if resp.StatusCode == http.StatusTooManyRequests {
+			return nil, retryAfter, retry, ErrRateLimited
+		}
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("OSV API returned status %d: %s", resp.StatusCode, string(body))
+		return nil, retryAfter, retry, fmt.Errorf("OSV API returned status %d: %s", resp.StatusCode, string(body))
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, 0, false, fmt.Errorf("failed to read response body: %w", err)
 	}
 
 	var response QueryResponse
 	if err := json.Unmarshal(body, &response); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+		return nil, 0, false, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
-	return &response, nil
+	return &response, 0, false, nil
+}
+
+// QueryPackages queries the OSV API for each package, returning results in the
+// same order. Queries run in parallel when a concurrency controller is set.
+func (c *Client) QueryPackages(pkgs []Package) []QueryResult {
+	return c.QueryPackagesContext(context.Background(), pkgs)
 }
 
-// GetSeverityScore extracts a severity score from vulnerability
-func (v *Vulnerability) GetSeverityScore() string {
-	if len(v.Severity) > 0 {
-		return v.Severity[0].Score
+// QueryPackagesContext is QueryPackages, giving up on every query not yet
+// answered once ctx is done
+func (c *Client) QueryPackagesContext(ctx context.Context, pkgs []Package) []QueryResult {
+	results := make([]QueryResult, len(pkgs))
+
+	if c.controller == nil {
+		for i, pkg := range pkgs {
+			response, err := c.QueryPackageContext(ctx, pkg)
+			results[i] = QueryResult{Response: response, Err: err}
+		}
+		return results
+	}
+
+	var wg sync.WaitGroup
+	for i, pkg := range pkgs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = c.queryWithRetry(ctx, pkg)
+		}()
 	}
-	return "unknown"
+	wg.Wait()
+
+	return results
 }
 
-// GetSeverityLevel returns a simplified severity level
-func (v *Vulnerability) GetSeverityLevel() string {
-	// Check aliases for CVE severity indicators
-	for _, alias := range v.Aliases {
-		if len(alias) > 0 {
-			// Most vulnerabilities are at least "high" if they have a CVE
-			return "high"
+// queryWithRetry queries a package under the concurrency controller, backing
+// off and retrying when rate limited. The query itself doesn't retry a 429,
+// so the controller sees each one and the backoffs don't nest.
+func (c *Client) queryWithRetry(ctx context.Context, pkg Package) QueryResult {
+	for attempt := 1; ; attempt++ {
+		epoch := c.controller.acquire()
+		response, err := c.queryPackageContext(ctx, pkg, false)
+		c.controller.release(epoch, err)
+
+		if !errors.Is(err, ErrRateLimited) || attempt > maxRateLimitRetries {
+			return QueryResult{Response: response, Err: err}
+		}
+		if err := sleepContext(ctx, c.controller.backoff*time.Duration(attempt)); err != nil {
+			return QueryResult{Err: fmt.Errorf("failed to query OSV API: %w", err)}
 		}
 	}
+}
+
+// PublishedTime returns when the advisory was published, or the zero time if
+// the timestamp is missing or malformed
+func (v *Vulnerability) PublishedTime() time.Time {
+	return parseTimestamp(v.Published)
+}
 
-	// Default to high for any vulnerability
-	return "high"
+// ModifiedTime returns when the advisory was last modified, or the zero time if
+// the timestamp is missing or malformed
+func (v *Vulnerability) ModifiedTime() time.Time {
+	return parseTimestamp(v.Modified)
+}
+
+// IsWithdrawn reports whether the advisory has been withdrawn, so it no
+// longer describes a live vulnerability
+func (v *Vulnerability) IsWithdrawn() bool {
+	return strings.TrimSpace(v.Withdrawn) != ""
+}
+
+// parseTimestamp parses an RFC3339 timestamp, tolerating a missing time zone
+// or time of day
+func parseTimestamp(value string) time.Time {
+	value = strings.TrimSpace(value)
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02"} {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed.UTC()
+		}
+	}
+	return time.Time{}
 }
 
 // GetCVEs returns all CVE identifiers for this vulnerability
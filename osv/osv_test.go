@@ -0,0 +1,319 @@
+package osv
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseTimestamp(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected time.Time
+	}{
+		{"2023-08-01T12:30:00Z", time.Date(2023, 8, 1, 12, 30, 0, 0, time.UTC)},
+		{"2023-08-01T12:30:00.123456Z", time.Date(2023, 8, 1, 12, 30, 0, 123456000, time.UTC)},
+		{"2023-08-01T14:30:00+02:00", time.Date(2023, 8, 1, 12, 30, 0, 0, time.UTC)},
+		{"2023-08-01T12:30:00", time.Date(2023, 8, 1, 12, 30, 0, 0, time.UTC)},
+		{"2023-08-01", time.Date(2023, 8, 1, 0, 0, 0, 0, time.UTC)},
+		{"", time.Time{}},
+		{"not a date", time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result := parseTimestamp(tt.input)
+			if !result.Equal(tt.expected) {
+				t.Errorf("parseTimestamp(%q) = %v, expected %v", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestQueryPackageRetriesTransientErrors(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) <= 2 {
+			http.Error(w, "service unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		if err := json.NewEncoder(w).Encode(QueryResponse{Vulns: []Vulnerability{{ID: "GHSA-retried"}}}); err != nil {
+			t.Errorf("failed to encode mock response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClientWithURL(server.URL, WithRetryDelay(time.Millisecond))
+	response, err := client.QueryPackage(Package{Name: "lodash", Version: "4.17.20", Ecosystem: NPM})
+	if err != nil {
+		t.Fatalf("QueryPackage() unexpected error: %v", err)
+	}
+	if len(response.Vulns) != 1 || response.Vulns[0].ID != "GHSA-retried" {
+		t.Errorf("QueryPackage() = %+v, expected GHSA-retried", response.Vulns)
+	}
+	if requests.Load() != 3 {
+		t.Errorf("QueryPackage() sent %d requests, expected 3", requests.Load())
+	}
+}
+
+func TestQueryPackageGivesUpAfterMaxAttempts(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Retry-After", "0")
+		http.Error(w, "slow down", http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := NewClientWithURL(server.URL, WithMaxAttempts(2), WithRetryDelay(time.Millisecond))
+	_, err := client.QueryPackage(Package{Name: "lodash", Version: "4.17.20", Ecosystem: NPM})
+	if !errors.Is(err, ErrRateLimited) {
+		t.Errorf("QueryPackage() error = %v, expected ErrRateLimited", err)
+	}
+	if requests.Load() != 2 {
+		t.Errorf("QueryPackage() sent %d requests, expected 2", requests.Load())
+	}
+}
+
+func TestQueryPackageDoesNotRetryClientErrors(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		http.Error(w, "bad request", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	client := NewClientWithURL(server.URL, WithRetryDelay(time.Millisecond))
+	if _, err := client.QueryPackage(Package{Name: "lodash", Version: "4.17.20", Ecosystem: NPM}); err == nil {
+		t.Error("QueryPackage() expected error for a 400 response")
+	}
+	if requests.Load() != 1 {
+		t.Errorf("QueryPackage() sent %d requests, expected 1", requests.Load())
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		header   string
+		expected time.Duration
+	}{
+		{header: "", expected: 0},
+		{header: "2", expected: 2 * time.Second},
+		{header: "3600", expected: maxRetryAfter},
+		{header: "soon", expected: 0},
+		{header: "Mon, 01 Jan 2001 00:00:00 GMT", expected: 0},
+	}
+
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.header); got != tt.expected {
+			t.Errorf("parseRetryAfter(%q) = %v, expected %v", tt.header, got, tt.expected)
+		}
+	}
+}
+
+func TestQueryURL(t *testing.T) {
+	tests := []struct {
+		baseURL  string
+		expected string
+	}{
+		{"https://osv.example.com", "https://osv.example.com/v1/query"},
+		{"https://osv.example.com/", "https://osv.example.com/v1/query"},
+		{"https://mirror.example.com/osv/v1/query", "https://mirror.example.com/osv/v1/query"},
+		{"http://127.0.0.1:8080/osv", "http://127.0.0.1:8080/osv/v1/query"},
+	}
+
+	for _, tt := range tests {
+		if got := QueryURL(tt.baseURL); got != tt.expected {
+			t.Errorf("QueryURL(%q) = %q, expected %q", tt.baseURL, got, tt.expected)
+		}
+	}
+}
+
+// proxyTestMode selects the half of TestQueryHonorsProxyEnvironment that runs
+// in a child process; net/http reads the proxy variables once per process
+const proxyTestMode = "SNOOP_OSV_PROXY_TEST"
+
+func TestQueryHonorsProxyEnvironment(t *testing.T) {
+	switch os.Getenv(proxyTestMode) {
+	case "proxy":
+		var proxied atomic.Int32
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// A proxied request names the origin server in its URL
+			if r.URL.Host != "osv.mirror.invalid" {
+				t.Errorf("proxy received a request for %q, expected osv.mirror.invalid", r.URL.Host)
+			}
+			proxied.Add(1)
+			if err := json.NewEncoder(w).Encode(QueryResponse{Vulns: []Vulnerability{{ID: "GHSA-proxied"}}}); err != nil {
+				t.Errorf("failed to encode mock response: %v", err)
+			}
+		}))
+		defer proxy.Close()
+		t.Setenv("HTTP_PROXY", proxy.URL)
+
+		client := NewClientWithURL("http://osv.mirror.invalid/v1/query", WithMaxAttempts(1))
+		response, err := client.QueryPackage(Package{Name: "lodash", Version: "4.17.20", Ecosystem: NPM})
+		if err != nil {
+			t.Fatalf("QueryPackage() unexpected error: %v", err)
+		}
+		if len(response.Vulns) != 1 || proxied.Load() != 1 {
+			t.Errorf("QueryPackage() = %+v with %d proxied requests, expected the proxy's answer", response, proxied.Load())
+		}
+		return
+
+	case "no-proxy":
+		var proxied atomic.Int32
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			proxied.Add(1)
+		}))
+		defer proxy.Close()
+		t.Setenv("HTTP_PROXY", proxy.URL)
+		t.Setenv("NO_PROXY", "osv.mirror.invalid")
+
+		client := NewClientWithURL("http://osv.mirror.invalid/v1/query", WithMaxAttempts(1))
+		if _, err := client.QueryPackage(Package{Name: "lodash", Version: "4.17.20", Ecosystem: NPM}); err == nil {
+			t.Error("QueryPackage() expected an error connecting to the unresolvable host directly")
+		}
+		if proxied.Load() != 0 {
+			t.Errorf("proxy received %d requests for a NO_PROXY host, expected 0", proxied.Load())
+		}
+		return
+	}
+
+	for _, mode := range []string{"proxy", "no-proxy"} {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestQueryHonorsProxyEnvironment$")
+		cmd.Env = append(os.Environ(), proxyTestMode+"="+mode, "HTTP_PROXY=", "http_proxy=", "NO_PROXY=", "no_proxy=")
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Errorf("%s: %v\n%s", mode, err, output)
+		}
+	}
+}
+
+func TestQueryPackageContextCancelsInFlightRequest(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Hold the request until the client gives up or the test ends
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	client := NewClientWithURL(server.URL)
+	start := time.Now()
+	_, err := client.QueryPackageContext(ctx, Package{Name: "lodash", Version: "4.17.20", Ecosystem: NPM})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("QueryPackageContext() error = %v, expected context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("QueryPackageContext() returned after %v, expected it to stop when cancelled", elapsed)
+	}
+}
+
+func TestQueryPackageContextCancelsRetryWait(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	client := NewClientWithURL(server.URL)
+	start := time.Now()
+	_, err := client.QueryPackageContext(ctx, Package{Name: "lodash", Version: "4.17.20", Ecosystem: NPM})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("QueryPackageContext() error = %v, expected context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("QueryPackageContext() returned after %v, expected it to stop waiting to retry", elapsed)
+	}
+	if attempts.Load() != 1 {
+		t.Errorf("server received %d attempts, expected 1", attempts.Load())
+	}
+}
+
+func TestRateLimitPacesParallelQueries(t *testing.T) {
+	var mu sync.Mutex
+	var received []time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		received = append(received, time.Now())
+		mu.Unlock()
+		_, _ = w.Write([]byte(`{"vulns":[]}`))
+	}))
+	defer server.Close()
+
+	const perSecond = 50
+	client := NewClientWithURL(server.URL, WithRateLimit(perSecond))
+	client.SetConcurrencyController(NewConcurrencyController(8))
+
+	var pkgs []Package
+	for i := 0; i < 6; i++ {
+		pkgs = append(pkgs, Package{Name: fmt.Sprintf("pkg-%d", i), Version: "1.0.0", Ecosystem: PyPI})
+	}
+	for i, result := range client.QueryPackages(pkgs) {
+		if result.Err != nil {
+			t.Errorf("QueryPackages() result %d error = %v, expected nil", i, result.Err)
+		}
+	}
+
+	// Every query goes out in parallel, yet the limiter spaces them a
+	// fiftieth of a second apart; allow a little for timer imprecision
+	interval := time.Second / perSecond
+	slices.SortFunc(received, time.Time.Compare)
+	if len(received) != len(pkgs) {
+		t.Fatalf("server received %d requests, expected %d", len(received), len(pkgs))
+	}
+	if elapsed := received[len(received)-1].Sub(received[0]); elapsed < time.Duration(len(pkgs)-1)*interval*9/10 {
+		t.Errorf("%d requests arrived within %v, expected them paced at %d per second", len(received), elapsed, perSecond)
+	}
+}
+
+func TestRateLimitWaitStopsWhenCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"vulns":[]}`))
+	}))
+	defer server.Close()
+
+	// One request every 100 seconds: the second would wait far past the test
+	client := NewClientWithURL(server.URL, WithRateLimit(0.01))
+	pkg := Package{Name: "lodash", Version: "4.17.20", Ecosystem: NPM}
+	if _, err := client.QueryPackage(pkg); err != nil {
+		t.Fatalf("QueryPackage() unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	start := time.Now()
+	_, err := client.QueryPackageContext(ctx, pkg)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("QueryPackageContext() error = %v, expected context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("QueryPackageContext() returned after %v, expected it to stop waiting for the rate limit", elapsed)
+	}
+
+	// Zero removes the limit
+	client.SetRateLimit(0)
+	if _, err := client.QueryPackage(pkg); err != nil {
+		t.Errorf("QueryPackage() without a rate limit unexpected error: %v", err)
+	}
+}
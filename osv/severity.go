@@ -0,0 +1,319 @@
+package osv
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Severity types whose scores are CVSS vectors, in order of preference
+const (
+	SeverityCVSSV3 = "CVSS_V3"
+	SeverityCVSSV2 = "CVSS_V2"
+)
+
+// GetSeverityLevel returns a simplified severity level: critical, high,
+// moderate, or low. Sources are tried in order: the CVSS vectors in
+// severity, then database_specific.severity, then database_specific.cvss,
+// each on the advisory before its affected entries. Only then does it
+// default to high.
+func (v *Vulnerability) GetSeverityLevel() string {
+	if score, ok := v.CVSSScore(); ok {
+		return severityFromScore(score)
+	}
+
+	for _, resolve := range []func(map[string]any) (string, bool){databaseSeverity, databaseCVSS} {
+		for _, databaseSpecific := range v.databaseSpecifics() {
+			if level, ok := resolve(databaseSpecific); ok {
+				return level
+			}
+		}
+	}
+
+	// Default to high for any vulnerability
+	return "high"
+}
+
+// GetSeverityScore returns the advisory's severity score as published: the
+// first entry in severity, or else the CVSS score or severity recorded in
+// database_specific. It returns "unknown" when there is none.
+func (v *Vulnerability) GetSeverityScore() string {
+	if len(v.Severity) > 0 {
+		return v.Severity[0].Score
+	}
+
+	for _, databaseSpecific := range v.databaseSpecifics() {
+		switch cvss := databaseSpecific["cvss"].(type) {
+		case float64:
+			return strconv.FormatFloat(cvss, 'f', -1, 64)
+		case string:
+			return cvss
+		case map[string]any:
+			for _, key := range []string{"vector_string", "vectorString", "vector", "score"} {
+				switch value := cvss[key].(type) {
+				case string:
+					return value
+				case float64:
+					return strconv.FormatFloat(value, 'f', -1, 64)
+				}
+			}
+		}
+	}
+	for _, databaseSpecific := range v.databaseSpecifics() {
+		switch severity := databaseSpecific["severity"].(type) {
+		case string:
+			return severity
+		case float64:
+			return strconv.FormatFloat(severity, 'f', -1, 64)
+		}
+	}
+
+	return "unknown"
+}
+
+// databaseSpecifics returns the advisory's database_specific object followed
+// by those of its affected entries, skipping any that are absent
+func (v *Vulnerability) databaseSpecifics() []map[string]any {
+	var objects []map[string]any
+	if v.DatabaseSpecific != nil {
+		objects = append(objects, v.DatabaseSpecific)
+	}
+	for _, affected := range v.Affected {
+		if affected.DatabaseSpecific != nil {
+			objects = append(objects, affected.DatabaseSpecific)
+		}
+	}
+	return objects
+}
+
+// CVSSScore returns the CVSS base score of the advisory, preferring a v3
+// vector over a v2 one
+func (v *Vulnerability) CVSSScore() (float64, bool) {
+	for _, severityType := range []string{SeverityCVSSV3, SeverityCVSSV2} {
+		for _, severity := range v.Severity {
+			if severity.Type != severityType {
+				continue
+			}
+			if score, ok := cvssBaseScore(severity); ok {
+				return score, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// severityFromScore maps a CVSS base score to a severity level
+func severityFromScore(score float64) string {
+	switch {
+	case score >= 9.0:
+		return "critical"
+	case score >= 7.0:
+		return "high"
+	case score >= 4.0:
+		return "moderate"
+	default:
+		return "low"
+	}
+}
+
+// databaseSeverity reads the "severity" a database such as GitHub's attaches
+// to an advisory, either a level such as {"severity": "MODERATE"} or a score
+// such as {"severity": 7.5}
+func databaseSeverity(databaseSpecific map[string]any) (string, bool) {
+	switch severity := databaseSpecific["severity"].(type) {
+	case string:
+		switch strings.ToLower(strings.TrimSpace(severity)) {
+		case "critical":
+			return "critical", true
+		case "high":
+			return "high", true
+		case "moderate", "medium":
+			return "moderate", true
+		case "low":
+			return "low", true
+		}
+		if score, ok := cvssBaseScore(Severity{Score: severity}); ok {
+			return severityFromScore(score), true
+		}
+	case float64:
+		if severity >= 0 && severity <= 10 {
+			return severityFromScore(severity), true
+		}
+	}
+	return "", false
+}
+
+// databaseCVSS reads a "cvss" entry in database_specific, which may be a
+// score, a vector, or an object such as {"score": 7.5, "vector_string": "..."}
+func databaseCVSS(databaseSpecific map[string]any) (string, bool) {
+	var score float64
+	var ok bool
+
+	switch cvss := databaseSpecific["cvss"].(type) {
+	case float64:
+		score, ok = cvss, cvss >= 0 && cvss <= 10
+	case string:
+		score, ok = cvssBaseScore(Severity{Type: cvssTypeOf(cvss), Score: cvss})
+	case map[string]any:
+		if value, isNumber := cvss["score"].(float64); isNumber && value >= 0 && value <= 10 {
+			score, ok = value, true
+			break
+		}
+		for _, key := range []string{"vector_string", "vectorString", "vector"} {
+			if vector, isString := cvss[key].(string); isString {
+				if score, ok = cvssBaseScore(Severity{Type: cvssTypeOf(vector), Score: vector}); ok {
+					break
+				}
+			}
+		}
+	}
+
+	if !ok {
+		return "", false
+	}
+	return severityFromScore(score), true
+}
+
+// cvssTypeOf guesses the severity type of a bare CVSS vector
+func cvssTypeOf(vector string) string {
+	if strings.HasPrefix(vector, "CVSS:3.") {
+		return SeverityCVSSV3
+	}
+	return SeverityCVSSV2
+}
+
+// cvssBaseScore computes the base score of a CVSS v3.x or v2 vector. Some
+// sources put the numeric score in place of the vector, which is accepted too.
+func cvssBaseScore(severity Severity) (float64, bool) {
+	score := strings.TrimSpace(severity.Score)
+	if value, err := strconv.ParseFloat(score, 64); err == nil {
+		return value, value >= 0 && value <= 10
+	}
+
+	metrics := parseCVSSVector(score)
+	if metrics == nil {
+		return 0, false
+	}
+	if strings.HasPrefix(score, "CVSS:3.") {
+		return cvss3BaseScore(metrics)
+	}
+	if severity.Type == SeverityCVSSV2 {
+		return cvss2BaseScore(metrics)
+	}
+	return 0, false
+}
+
+// parseCVSSVector splits a vector such as "CVSS:3.1/AV:N/AC:L" into its
+// metrics, ignoring the version prefix and any surrounding parentheses
+func parseCVSSVector(vector string) map[string]string {
+	vector = strings.Trim(vector, "()")
+	if vector == "" {
+		return nil
+	}
+
+	metrics := make(map[string]string)
+	for _, part := range strings.Split(vector, "/") {
+		name, value, ok := strings.Cut(part, ":")
+		if !ok {
+			return nil
+		}
+		metrics[name] = value
+	}
+	return metrics
+}
+
+// cvss3BaseScore implements the CVSS v3.1 base score equations
+func cvss3BaseScore(metrics map[string]string) (float64, bool) {
+	changed := metrics["S"] == "C"
+	privileges := map[string]float64{"N": 0.85, "L": 0.62, "H": 0.27}
+	if changed {
+		privileges = map[string]float64{"N": 0.85, "L": 0.68, "H": 0.5}
+	}
+	impacts := map[string]float64{"H": 0.56, "L": 0.22, "N": 0}
+
+	values, ok := lookupMetrics(metrics, []metricWeights{
+		{"AV", map[string]float64{"N": 0.85, "A": 0.62, "L": 0.55, "P": 0.2}},
+		{"AC", map[string]float64{"L": 0.77, "H": 0.44}},
+		{"PR", privileges},
+		{"UI", map[string]float64{"N": 0.85, "R": 0.62}},
+		{"C", impacts},
+		{"I", impacts},
+		{"A", impacts},
+	})
+	if !ok || (metrics["S"] != "U" && !changed) {
+		return 0, false
+	}
+	av, ac, pr, ui, c, i, a := values[0], values[1], values[2], values[3], values[4], values[5], values[6]
+
+	iss := 1 - (1-c)*(1-i)*(1-a)
+	impact := 6.42 * iss
+	if changed {
+		impact = 7.52*(iss-0.029) - 3.25*math.Pow(iss-0.02, 15)
+	}
+	if impact <= 0 {
+		return 0, true
+	}
+
+	exploitability := 8.22 * av * ac * pr * ui
+	if changed {
+		return roundUp(math.Min(1.08*(impact+exploitability), 10)), true
+	}
+	return roundUp(math.Min(impact+exploitability, 10)), true
+}
+
+// cvss2BaseScore implements the CVSS v2 base score equations
+func cvss2BaseScore(metrics map[string]string) (float64, bool) {
+	impacts := map[string]float64{"N": 0, "P": 0.275, "C": 0.660}
+
+	values, ok := lookupMetrics(metrics, []metricWeights{
+		{"AV", map[string]float64{"L": 0.395, "A": 0.646, "N": 1.0}},
+		{"AC", map[string]float64{"H": 0.35, "M": 0.61, "L": 0.71}},
+		{"Au", map[string]float64{"M": 0.45, "S": 0.56, "N": 0.704}},
+		{"C", impacts},
+		{"I", impacts},
+		{"A", impacts},
+	})
+	if !ok {
+		return 0, false
+	}
+	av, ac, au, c, i, a := values[0], values[1], values[2], values[3], values[4], values[5]
+
+	impact := 10.41 * (1 - (1-c)*(1-i)*(1-a))
+	if impact == 0 {
+		return 0, true
+	}
+	exploitability := 20 * av * ac * au
+
+	score := (0.6*impact + 0.4*exploitability - 1.5) * 1.176
+	return math.Round(score*10) / 10, true
+}
+
+// metricWeights maps the values of one CVSS metric to their weights
+type metricWeights struct {
+	name    string
+	weights map[string]float64
+}
+
+// lookupMetrics returns the weight of each metric, failing if any is missing
+// or has an unknown value
+func lookupMetrics(metrics map[string]string, weights []metricWeights) ([]float64, bool) {
+	values := make([]float64, len(weights))
+	for i, metric := range weights {
+		weight, ok := metric.weights[metrics[metric.name]]
+		if !ok {
+			return nil, false
+		}
+		values[i] = weight
+	}
+	return values, true
+}
+
+// roundUp is the CVSS v3.1 Roundup function: the smallest number with one
+// decimal place that is at least value, computed without float drift
+func roundUp(value float64) float64 {
+	scaled := int(math.Round(value * 100000))
+	if scaled%10000 == 0 {
+		return float64(scaled) / 100000
+	}
+	return float64(scaled/10000+1) / 10
+}
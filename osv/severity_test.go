@@ -0,0 +1,186 @@
+package osv
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCVSSBaseScore(t *testing.T) {
+	tests := []struct {
+		severity Severity
+		expected float64
+	}{
+		{Severity{Type: SeverityCVSSV3, Score: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"}, 9.8},
+		{Severity{Type: SeverityCVSSV3, Score: "CVSS:3.1/AV:N/AC:L/PR:N/UI:R/S:U/C:H/I:H/A:H"}, 8.8},
+		{Severity{Type: SeverityCVSSV3, Score: "CVSS:3.1/AV:N/AC:L/PR:N/UI:R/S:C/C:L/I:L/A:N"}, 6.1},
+		{Severity{Type: SeverityCVSSV3, Score: "CVSS:3.1/AV:L/AC:H/PR:L/UI:N/S:U/C:L/I:N/A:N"}, 2.5},
+		{Severity{Type: SeverityCVSSV3, Score: "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H"}, 10.0},
+		{Severity{Type: SeverityCVSSV3, Score: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:N"}, 0},
+		{Severity{Type: SeverityCVSSV2, Score: "AV:N/AC:L/Au:N/C:P/I:P/A:P"}, 7.5},
+		{Severity{Type: SeverityCVSSV2, Score: "(AV:N/AC:M/Au:N/C:N/I:P/A:N)"}, 4.3},
+		{Severity{Type: SeverityCVSSV2, Score: "AV:N/AC:L/Au:N/C:C/I:C/A:C"}, 10.0},
+		{Severity{Type: SeverityCVSSV3, Score: "7.2"}, 7.2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.severity.Score, func(t *testing.T) {
+			score, ok := cvssBaseScore(tt.severity)
+			if !ok || score != tt.expected {
+				t.Errorf("cvssBaseScore(%q) = %v, %v, expected %v", tt.severity.Score, score, ok, tt.expected)
+			}
+		})
+	}
+
+	for _, vector := range []string{"", "CVSS:3.1/AV:N/AC:L", "CVSS:3.1/AV:X/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", "CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N"} {
+		if score, ok := cvssBaseScore(Severity{Type: SeverityCVSSV3, Score: vector}); ok {
+			t.Errorf("cvssBaseScore(%q) = %v, expected the vector to be rejected", vector, score)
+		}
+	}
+}
+
+func TestGetSeverityLevel(t *testing.T) {
+	tests := []struct {
+		name     string
+		vuln     Vulnerability
+		expected string
+	}{
+		{
+			name:     "critical CVSS v3",
+			vuln:     Vulnerability{Severity: []Severity{{Type: SeverityCVSSV3, Score: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"}}},
+			expected: "critical",
+		},
+		{
+			name:     "moderate CVSS v3",
+			vuln:     Vulnerability{Severity: []Severity{{Type: SeverityCVSSV3, Score: "CVSS:3.1/AV:N/AC:L/PR:N/UI:R/S:C/C:L/I:L/A:N"}}},
+			expected: "moderate",
+		},
+		{
+			name:     "low CVSS v3",
+			vuln:     Vulnerability{Severity: []Severity{{Type: SeverityCVSSV3, Score: "CVSS:3.1/AV:L/AC:H/PR:L/UI:N/S:U/C:L/I:N/A:N"}}},
+			expected: "low",
+		},
+		{
+			name: "v3 preferred over v2",
+			vuln: Vulnerability{Severity: []Severity{
+				{Type: SeverityCVSSV2, Score: "AV:N/AC:L/Au:N/C:P/I:P/A:P"},
+				{Type: SeverityCVSSV3, Score: "CVSS:3.1/AV:L/AC:H/PR:L/UI:N/S:U/C:L/I:N/A:N"},
+			}},
+			expected: "low",
+		},
+		{
+			name:     "CVSS v2 only",
+			vuln:     Vulnerability{Severity: []Severity{{Type: SeverityCVSSV2, Score: "AV:N/AC:M/Au:N/C:N/I:P/A:N"}}},
+			expected: "moderate",
+		},
+		{
+			name:     "database severity",
+			vuln:     Vulnerability{DatabaseSpecific: map[string]any{"severity": "MODERATE"}},
+			expected: "moderate",
+		},
+		{
+			name:     "affected database severity",
+			vuln:     Vulnerability{Affected: []Affected{{DatabaseSpecific: map[string]any{"severity": "LOW"}}}},
+			expected: "low",
+		},
+		{
+			name: "CVSS v4 falls back to database severity",
+			vuln: Vulnerability{
+				Severity:         []Severity{{Type: "CVSS_V4", Score: "CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N"}},
+				DatabaseSpecific: map[string]any{"severity": "CRITICAL"},
+			},
+			expected: "critical",
+		},
+		{
+			name:     "no severity information",
+			vuln:     Vulnerability{Aliases: []string{"CVE-2024-0001"}},
+			expected: "high",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if level := tt.vuln.GetSeverityLevel(); level != tt.expected {
+				t.Errorf("GetSeverityLevel() = %q, expected %q", level, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSeverityResolvedFromEachSource(t *testing.T) {
+	tests := []struct {
+		name          string
+		entry         string
+		expectedLevel string
+		expectedScore string
+	}{
+		{
+			name:          "severity array",
+			entry:         `{"id": "GHSA-1", "severity": [{"type": "CVSS_V3", "score": "CVSS:3.1/AV:N/AC:L/PR:N/UI:R/S:U/C:H/I:H/A:H"}], "database_specific": {"severity": "LOW"}}`,
+			expectedLevel: "high",
+			expectedScore: "CVSS:3.1/AV:N/AC:L/PR:N/UI:R/S:U/C:H/I:H/A:H",
+		},
+		{
+			name:          "empty severity array with qualitative database severity",
+			entry:         `{"id": "GHSA-2", "severity": [], "database_specific": {"severity": "CRITICAL", "cwe_ids": ["CWE-79"]}}`,
+			expectedLevel: "critical",
+			expectedScore: "CRITICAL",
+		},
+		{
+			name:          "numeric database severity",
+			entry:         `{"id": "PYSEC-3", "severity": [], "database_specific": {"severity": 5.3}}`,
+			expectedLevel: "moderate",
+			expectedScore: "5.3",
+		},
+		{
+			name:          "database cvss object",
+			entry:         `{"id": "GHSA-4", "severity": [], "database_specific": {"cvss": {"score": 9.1, "vector_string": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:N"}}}`,
+			expectedLevel: "critical",
+			expectedScore: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:N",
+		},
+		{
+			name:          "database cvss vector only",
+			entry:         `{"id": "GHSA-5", "database_specific": {"cvss": {"vectorString": "CVSS:3.1/AV:L/AC:H/PR:L/UI:N/S:U/C:L/I:N/A:N"}}}`,
+			expectedLevel: "low",
+			expectedScore: "CVSS:3.1/AV:L/AC:H/PR:L/UI:N/S:U/C:L/I:N/A:N",
+		},
+		{
+			name:          "database cvss number",
+			entry:         `{"id": "PYSEC-6", "database_specific": {"cvss": 7.5}}`,
+			expectedLevel: "high",
+			expectedScore: "7.5",
+		},
+		{
+			name:          "database severity preferred over database cvss",
+			entry:         `{"id": "GHSA-7", "database_specific": {"severity": "LOW", "cvss": 9.8}}`,
+			expectedLevel: "low",
+			expectedScore: "9.8",
+		},
+		{
+			name:          "affected database severity",
+			entry:         `{"id": "GHSA-8", "severity": [], "affected": [{"package": {"name": "jinja2", "ecosystem": "PyPI"}, "database_specific": {"severity": "MODERATE"}}]}`,
+			expectedLevel: "moderate",
+			expectedScore: "MODERATE",
+		},
+		{
+			name:          "no severity anywhere",
+			entry:         `{"id": "GO-9", "severity": [], "database_specific": {"url": "https://pkg.go.dev/vuln/GO-9"}}`,
+			expectedLevel: "high",
+			expectedScore: "unknown",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var vuln Vulnerability
+			if err := json.Unmarshal([]byte(tt.entry), &vuln); err != nil {
+				t.Fatalf("failed to parse entry: %v", err)
+			}
+			if level := vuln.GetSeverityLevel(); level != tt.expectedLevel {
+				t.Errorf("GetSeverityLevel() = %q, expected %q", level, tt.expectedLevel)
+			}
+			if score := vuln.GetSeverityScore(); score != tt.expectedScore {
+				t.Errorf("GetSeverityScore() = %q, expected %q", score, tt.expectedScore)
+			}
+		})
+	}
+}
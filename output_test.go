@@ -0,0 +1,39 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteOutputCreatesParentDirectories(t *testing.T) {
+	defer func(prev string) { outputFile = prev }(outputFile)
+
+	outputFile = filepath.Join(t.TempDir(), "reports", "nightly", "snoop.json")
+	if err := writeOutput(`{"audits":[]}`); err != nil {
+		t.Fatalf("writeOutput() unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+	if string(data) != "{\"audits\":[]}\n" {
+		t.Errorf("output file = %q, expected the report", data)
+	}
+}
+
+func TestWriteOutputReportsUnwritableFile(t *testing.T) {
+	defer func(prev string) { outputFile = prev }(outputFile)
+
+	// A regular file where a parent directory should be
+	blocker := filepath.Join(t.TempDir(), "blocker")
+	if err := os.WriteFile(blocker, nil, 0644); err != nil {
+		t.Fatalf("Failed to create blocking file: %v", err)
+	}
+
+	outputFile = filepath.Join(blocker, "snoop.json")
+	if err := writeOutput("report"); err == nil {
+		t.Error("writeOutput() expected an error for an unwritable path")
+	}
+}
@@ -0,0 +1,37 @@
+package pipeline
+
+import (
+	"sync"
+
+	"github.com/brandonapol/snoop/scanner"
+)
+
+// auditEach calls run for every manifest, with at most workers calls in
+// flight, and returns the results in the order of manifests so reports stay
+// reproducible however the audits interleave. run must be safe to call
+// concurrently; each result is written only by the call that produced it.
+// progress, which may be nil, advances as each call returns.
+func auditEach[T any](manifests []scanner.DetectedFile, workers int, progress Progress, run func(scanner.DetectedFile) T) []T {
+	results := make([]T, len(manifests))
+	if workers < 1 {
+		workers = 1
+	}
+
+	slots := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for i, manifest := range manifests {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			results[i] = run(manifest)
+			if progress != nil {
+				progress.Advance(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
@@ -0,0 +1,48 @@
+package pipeline
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/brandonapol/snoop/scanner"
+)
+
+func TestAuditEachKeepsOrderAndBound(t *testing.T) {
+	manifests := make([]scanner.DetectedFile, 20)
+	for i := range manifests {
+		manifests[i] = scanner.DetectedFile{Path: fmt.Sprintf("service-%02d/go.mod", i)}
+	}
+
+	var running, peak atomic.Int32
+	results := auditEach(manifests, 3, nil, func(manifest scanner.DetectedFile) string {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		// Later manifests finish first
+		var i int
+		if _, err := fmt.Sscanf(manifest.Path, "service-%02d/go.mod", &i); err != nil {
+			t.Errorf("failed to parse %s: %v", manifest.Path, err)
+		}
+		time.Sleep(time.Duration(len(manifests)-i) * time.Millisecond)
+		running.Add(-1)
+		return manifest.Path
+	})
+
+	if len(results) != len(manifests) {
+		t.Fatalf("auditEach() returned %d results, expected %d", len(results), len(manifests))
+	}
+	for i, result := range results {
+		if result != manifests[i].Path {
+			t.Errorf("auditEach() result %d = %q, expected %q", i, result, manifests[i].Path)
+		}
+	}
+	if peak.Load() > 3 {
+		t.Errorf("auditEach() ran %d audits at once, expected at most 3", peak.Load())
+	}
+}
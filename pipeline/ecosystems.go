@@ -0,0 +1,293 @@
+package pipeline
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/brandonapol/snoop/audit"
+	"github.com/brandonapol/snoop/osv"
+	"github.com/brandonapol/snoop/scanner"
+)
+
+// ecosystem describes how Audit handles the manifests of an ecosystem
+// whose audits query OSV
+type ecosystem struct {
+	files     string                          // What its manifests are called in the log, such as "Go module file(s)"; empty logs nothing
+	detect    func(scanner.ManifestType) bool // Whether a scan found something to audit
+	enabled   func(Options) bool              // Whether the options call for its audits; nil always does
+	manifests func(*scanner.ScanResult) []scanner.DetectedFile
+	audit     func(a *auditor, manifests []scanner.DetectedFile) // Audits manifests into a.output
+}
+
+// ecosystems lists the ecosystems Audit checks besides Node.js, in the order
+// their audits run
+var ecosystems = []ecosystem{
+	{
+		files:     "Python manifest file(s)",
+		detect:    scanner.IsPythonManifest,
+		manifests: pythonManifestsToAudit,
+		audit: func(a *auditor, manifests []scanner.DetectedFile) {
+			a.output.PythonAuditResults = auditAll(a, "Python", manifests, func(file scanner.DetectedFile) *audit.PythonAuditResult {
+				result := a.runner.RunPythonAudit(file.Path, string(file.Type))
+				audit.FilterSeverity(result, a.opts.MinSeverity)
+				return result
+			}, func(result *audit.PythonAuditResult) (int, error) { return result.Summary.Total, result.Error })
+		},
+	},
+	{
+		files:     "Go module file(s)",
+		detect:    scanner.IsGoManifest,
+		manifests: manifestsOfType(scanner.GoMod),
+		audit:     auditGo,
+	},
+	{
+		files:     "Maven project file(s)",
+		detect:    scanner.IsMavenManifest,
+		manifests: manifestsOfType(scanner.PomXML, scanner.BuildGradle, scanner.BuildGradleKts),
+		audit: func(a *auditor, manifests []scanner.DetectedFile) {
+			a.output.MavenAuditResults = auditAll(a, "Maven", manifests, func(file scanner.DetectedFile) *audit.MavenAuditResult {
+				result := a.runner.RunMavenAudit(file.Path, string(file.Type))
+				audit.FilterSeverity(result, a.opts.MinSeverity)
+				return result
+			}, func(result *audit.MavenAuditResult) (int, error) { return result.Summary.Total, result.Error })
+		},
+	},
+	{
+		files:     "Swift package file(s)",
+		detect:    scanner.IsSwiftManifest,
+		manifests: swiftManifestsToAudit,
+		audit: func(a *auditor, manifests []scanner.DetectedFile) {
+			a.output.SwiftAuditResults = auditAll(a, "Swift", manifests, func(file scanner.DetectedFile) *audit.SwiftAuditResult {
+				result := a.runner.RunSwiftAudit(file.Path, string(file.Type))
+				audit.FilterSeverity(result, a.opts.MinSeverity)
+				return result
+			}, func(result *audit.SwiftAuditResult) (int, error) { return result.Summary.Total, result.Error })
+		},
+	},
+	{
+		files:     "Cargo.lock file(s)",
+		detect:    scanner.IsCargoManifest,
+		manifests: manifestsOfType(scanner.CargoLock),
+		audit: func(a *auditor, manifests []scanner.DetectedFile) {
+			a.output.CargoAuditResults = auditAll(a, "Cargo", manifests, func(file scanner.DetectedFile) *audit.CargoAuditResult {
+				result := a.runner.RunCargoAudit(file.Path, string(file.Type))
+				audit.FilterSeverity(result, a.opts.MinSeverity)
+				return result
+			}, func(result *audit.CargoAuditResult) (int, error) { return result.Summary.Total, result.Error })
+		},
+	},
+	{
+		files:     "Gemfile.lock file(s)",
+		detect:    scanner.IsRubyManifest,
+		manifests: manifestsOfType(scanner.GemfileLock),
+		audit: func(a *auditor, manifests []scanner.DetectedFile) {
+			a.output.RubyAuditResults = auditAll(a, "Ruby", manifests, func(file scanner.DetectedFile) *audit.RubyAuditResult {
+				result := a.runner.RunRubyAudit(file.Path, string(file.Type))
+				audit.FilterSeverity(result, a.opts.MinSeverity)
+				return result
+			}, func(result *audit.RubyAuditResult) (int, error) { return result.Summary.Total, result.Error })
+		},
+	},
+	{
+		files:     "composer.lock file(s)",
+		detect:    scanner.IsComposerManifest,
+		manifests: manifestsOfType(scanner.ComposerLock),
+		audit: func(a *auditor, manifests []scanner.DetectedFile) {
+			a.output.ComposerAuditResults = auditAll(a, "Composer", manifests, func(file scanner.DetectedFile) *audit.ComposerAuditResult {
+				result := a.runner.RunComposerAudit(file.Path, string(file.Type))
+				audit.FilterSeverity(result, a.opts.MinSeverity)
+				return result
+			}, func(result *audit.ComposerAuditResult) (int, error) { return result.Summary.Total, result.Error })
+		},
+	},
+	{
+		files: "runtime version declaration(s)",
+		// The go directive in go.mod declares the Go toolchain version
+		detect: func(t scanner.ManifestType) bool {
+			return scanner.IsRuntimeManifest(t) || t == scanner.GoMod
+		},
+		enabled:   func(opts Options) bool { return opts.Runtime },
+		manifests: manifestsOfType(scanner.Nvmrc, scanner.PythonVersion, scanner.ToolVersions, scanner.GoMod),
+		audit: func(a *auditor, manifests []scanner.DetectedFile) {
+			a.output.RuntimeAuditResults = auditAll(a, "runtime", manifests, func(file scanner.DetectedFile) *audit.RuntimeAuditResult {
+				result := a.runner.RunRuntimeAudit(file.Path, string(file.Type))
+				audit.FilterSeverity(result, a.opts.MinSeverity)
+				return result
+			}, func(result *audit.RuntimeAuditResult) (int, error) { return result.Summary.Total, result.Error })
+		},
+	},
+	{
+		// Manifest types registered by library users
+		detect:    scanner.IsCustomManifest,
+		manifests: customManifestsToAudit,
+		audit: func(a *auditor, manifests []scanner.DetectedFile) {
+			a.output.CustomAuditResults = auditAll(a, "", manifests, func(file scanner.DetectedFile) *audit.CustomAuditResult {
+				ecosystem, _ := scanner.CustomManifestEcosystem(file.Type)
+				if a.opts.Verbose {
+					fmt.Fprintf(os.Stderr, "\nAuditing %s: %s\n", ecosystem, file.Path)
+				}
+
+				result := a.runner.RunCustomAudit(file.Path, string(file.Type), osv.Ecosystem(ecosystem))
+				audit.FilterSeverity(result, a.opts.MinSeverity)
+				return result
+			}, func(result *audit.CustomAuditResult) (int, error) { return result.Summary.Total, result.Error })
+		},
+	},
+}
+
+// auditGo audits each go.mod on its own, or every one of them as one
+// deduplicated module set with Options.CombineGo
+func auditGo(a *auditor, manifests []scanner.DetectedFile) {
+	if !a.opts.CombineGo || len(manifests) < 2 {
+		a.output.GoAuditResults = auditAll(a, "Go", manifests, func(file scanner.DetectedFile) *audit.GoAuditResult {
+			result := a.runner.RunGoAudit(file.Path, string(file.Type))
+			audit.FilterSeverity(result, a.opts.MinSeverity)
+			return result
+		}, func(result *audit.GoAuditResult) (int, error) { return result.Summary.Total, result.Error })
+		return
+	}
+
+	paths := make([]string, 0, len(manifests))
+	for _, manifest := range manifests {
+		paths = append(paths, manifest.Path)
+	}
+	if a.opts.Verbose {
+		fmt.Fprintf(os.Stderr, "\nAuditing Go: %d modules combined\n", len(paths))
+	}
+
+	result := a.runner.RunCombinedGoAudit(paths)
+	if a.opts.Progress != nil {
+		a.opts.Progress.Advance(len(paths))
+	}
+	audit.FilterSeverity(result, a.opts.MinSeverity)
+	a.output.GoAuditResults = []*audit.GoAuditResult{result}
+	a.tally(result.Summary.Total, result.Error)
+}
+
+// manifestsOfType returns a selector of the manifests of the given types, in
+// that order
+func manifestsOfType(types ...scanner.ManifestType) func(*scanner.ScanResult) []scanner.DetectedFile {
+	return func(result *scanner.ScanResult) []scanner.DetectedFile {
+		var manifests []scanner.DetectedFile
+		for _, manifestType := range types {
+			manifests = append(manifests, result.GetManifestsByType(manifestType)...)
+		}
+		return manifests
+	}
+}
+
+// customManifestsToAudit returns the manifests of types registered by
+// library users
+func customManifestsToAudit(result *scanner.ScanResult) []scanner.DetectedFile {
+	var manifests []scanner.DetectedFile
+	for _, file := range result.Files {
+		if _, ok := scanner.CustomManifestEcosystem(file.Type); ok {
+			manifests = append(manifests, file)
+		}
+	}
+	return manifests
+}
+
+// swiftManifestsToAudit returns every Package.resolved, plus each Package.swift
+// without one beside it. Package.resolved has the exact version of every
+// package, so it takes the place of the Package.swift it was resolved from.
+func swiftManifestsToAudit(result *scanner.ScanResult) []scanner.DetectedFile {
+	resolved := result.GetManifestsByType(scanner.PackageResolved)
+	resolvedDirs := make(map[string]bool)
+	for _, file := range resolved {
+		resolvedDirs[filepath.Dir(file.Path)] = true
+	}
+
+	manifests := resolved
+	for _, file := range result.GetManifestsByType(scanner.PackageSwift) {
+		if !resolvedDirs[filepath.Dir(file.Path)] {
+			manifests = append(manifests, file)
+		}
+	}
+	return manifests
+}
+
+// npmManifestsToAudit returns the package.json files to audit, leaving out the
+// members of npm workspaces: npm audit at a workspace root covers every
+// member, which has no lockfile of its own to audit. The members left out are
+// returned keyed by the path of their root's package.json.
+func npmManifestsToAudit(packageJSONFiles []scanner.DetectedFile) ([]scanner.DetectedFile, map[string][]string) {
+	var roots []scanner.DetectedFile
+	declared := make(map[string]*audit.PackageJSON)
+	for _, file := range packageJSONFiles {
+		manifest, err := audit.ParsePackageJSON(file.Path)
+		if err == nil && len(manifest.Workspaces) > 0 {
+			roots = append(roots, file)
+			declared[file.Path] = manifest
+		}
+	}
+
+	// npm doesn't nest workspaces, so a root that is itself a member of
+	// another is audited by the outer one
+	rootOf := func(file scanner.DetectedFile) string {
+		for _, root := range roots {
+			if declared[root.Path].HasWorkspaceMember(filepath.Dir(root.Path), filepath.Dir(file.Path)) {
+				return root.Path
+			}
+		}
+		return ""
+	}
+	var nested []string
+	for _, root := range roots {
+		if rootOf(root) != "" {
+			nested = append(nested, root.Path)
+		}
+	}
+	roots = slices.DeleteFunc(roots, func(root scanner.DetectedFile) bool {
+		return slices.Contains(nested, root.Path)
+	})
+
+	members := make(map[string][]string)
+	var manifests []scanner.DetectedFile
+	for _, file := range packageJSONFiles {
+		if root := rootOf(file); root != "" {
+			members[root] = append(members[root], file.Path)
+			continue
+		}
+		manifests = append(manifests, file)
+	}
+	return manifests, members
+}
+
+// pythonLockfiles maps a Python manifest to the lockfile that pins its
+// dependencies when the two sit in the same directory
+var pythonLockfiles = map[scanner.ManifestType]scanner.ManifestType{
+	scanner.Pipfile:       scanner.PipfileLock,
+	scanner.PyprojectTOML: scanner.PoetryLock,
+}
+
+// pythonManifestsToAudit returns the Python manifests to audit. A lockfile's
+// exact versions replace the ranges of the manifest next to it, so a Pipfile
+// or pyproject.toml with a lockfile beside it is left out.
+func pythonManifestsToAudit(result *scanner.ScanResult) []scanner.DetectedFile {
+	locked := make(map[string]bool)
+	for _, lockType := range pythonLockfiles {
+		for _, lockfile := range result.GetManifestsByType(lockType) {
+			locked[string(lockType)+"|"+filepath.Dir(lockfile.Path)] = true
+		}
+	}
+
+	var manifests []scanner.DetectedFile
+	for _, manifestType := range []scanner.ManifestType{
+		scanner.RequirementsTxt,
+		scanner.Pipfile,
+		scanner.PipfileLock,
+		scanner.PyprojectTOML,
+		scanner.PoetryLock,
+	} {
+		for _, manifest := range result.GetManifestsByType(manifestType) {
+			if lockType, ok := pythonLockfiles[manifestType]; ok && locked[string(lockType)+"|"+filepath.Dir(manifest.Path)] {
+				continue
+			}
+			manifests = append(manifests, manifest)
+		}
+	}
+	return manifests
+}
@@ -0,0 +1,59 @@
+package pipeline
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/brandonapol/snoop/scanner"
+)
+
+func TestPythonLockfileReplacesItsManifest(t *testing.T) {
+	result := &scanner.ScanResult{Files: []scanner.DetectedFile{
+		{Path: filepath.Join("api", "pyproject.toml"), Type: scanner.PyprojectTOML},
+		{Path: filepath.Join("api", "poetry.lock"), Type: scanner.PoetryLock},
+		{Path: filepath.Join("worker", "Pipfile"), Type: scanner.Pipfile},
+		{Path: filepath.Join("docs", "Pipfile.lock"), Type: scanner.PipfileLock},
+		{Path: "requirements.txt", Type: scanner.RequirementsTxt},
+	}}
+
+	var audited []string
+	for _, manifest := range pythonManifestsToAudit(result) {
+		audited = append(audited, filepath.ToSlash(manifest.Path))
+	}
+
+	expected := "requirements.txt,worker/Pipfile,docs/Pipfile.lock,api/poetry.lock"
+	if strings.Join(audited, ",") != expected {
+		t.Errorf("pythonManifestsToAudit() = %v, expected %s", audited, expected)
+	}
+}
+
+func TestNpmWorkspaceMembersAuditedWithTheirRoot(t *testing.T) {
+	root := filepath.Join("..", "test-project-workspaces")
+	s, err := scanner.New(root, false)
+	if err != nil {
+		t.Fatalf("scanner.New() error = %v", err)
+	}
+	result, err := s.Scan()
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+
+	manifests, members := npmManifestsToAudit(result.GetManifestsByType(scanner.PackageJSON))
+
+	rootPath := filepath.Join(root, "package.json")
+	if len(manifests) != 1 || manifests[0].Path != rootPath {
+		t.Errorf("npmManifestsToAudit() = %v, expected only %s", manifests, rootPath)
+	}
+
+	var workspaces []string
+	for _, member := range members[rootPath] {
+		workspaces = append(workspaces, filepath.ToSlash(member))
+	}
+	slices.Sort(workspaces)
+	expected := filepath.ToSlash(root) + "/packages/api/package.json," + filepath.ToSlash(root) + "/packages/web/package.json"
+	if strings.Join(workspaces, ",") != expected {
+		t.Errorf("workspace members = %v, expected %s", workspaces, expected)
+	}
+}
@@ -0,0 +1,271 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/brandonapol/snoop/audit"
+	"github.com/brandonapol/snoop/formatter"
+	"github.com/brandonapol/snoop/scanner"
+	"github.com/brandonapol/snoop/security"
+)
+
+// DefaultConcurrency is how many manifests are audited at once unless
+// Options.Concurrency says otherwise
+const DefaultConcurrency = 4
+
+// ErrNothingToAudit is returned by Audit when none of the manifests found
+// belong to an ecosystem it can audit
+var ErrNothingToAudit = errors.New("no auditable manifests found")
+
+// Options configures an Audit
+type Options struct {
+	// Runner runs the vulnerability audits with its own settings; nil uses
+	// the defaults of audit.NewRunner. Audit sets its context to ctx, and its
+	// supply chain checks to those of SupplyChain.
+	Runner *audit.Runner
+	// MinSeverity drops findings less severe than it. npm's info findings are
+	// only kept when it's audit.SeverityInfo.
+	MinSeverity audit.Severity
+	// SkipVulnerabilities leaves out the vulnerability audits, to run only
+	// the supply chain checks
+	SkipVulnerabilities bool
+	// Runtime audits the runtime versions declared by .nvmrc,
+	// .python-version, .tool-versions, and the go directive of go.mod
+	Runtime bool
+	// CombineGo audits every go.mod as one deduplicated module set instead of
+	// one report per module
+	CombineGo bool
+	// SupplyChain configures the supply chain checks of each package.json,
+	// and of the Python packages audited for vulnerabilities; they're
+	// skipped when it selects no checks
+	SupplyChain security.ManifestOptions
+	// Concurrency is how many manifests of an ecosystem are audited at once;
+	// below 1 audits them one at a time
+	Concurrency int
+	// Progress, when set, follows the audits as they finish
+	Progress Progress
+	// Verbose logs each step to stderr
+	Verbose bool
+}
+
+// Progress follows an Audit. Start is told how many manifests will be
+// audited before the first one starts; Advance is then called from the
+// concurrent audits as each finishes.
+type Progress interface {
+	Start(total int)
+	Advance(n int)
+}
+
+// auditor holds the state of one Audit, shared by the audits of every
+// ecosystem. The output's totals are only updated between audits, from the
+// goroutine that called Audit.
+type auditor struct {
+	opts   Options
+	runner *audit.Runner
+	output *formatter.ScanOutput
+}
+
+// Audit checks the manifests of a scan for vulnerabilities, and the
+// dependencies of each package.json with the selected supply chain checks,
+// stopping early once ctx is done. Every ecosystem scanner.Scan detects is
+// audited, including manifest types registered with scanner.RegisterManifest
+// and audit.RegisterParser. The output is ready for a formatter once the
+// caller fills in its Metadata and display options.
+func Audit(ctx context.Context, result *scanner.ScanResult, opts Options) (*formatter.ScanOutput, error) {
+	runner := opts.Runner
+	if runner == nil {
+		runner = audit.NewRunner(60*time.Second, opts.Verbose)
+	}
+	runner.SetContext(ctx)
+	runner.SetSupplyChainChecks(opts.SupplyChain.Checks)
+
+	// Check which types of manifests we found
+	hasNodeJS := false
+	var active []ecosystem
+	for _, file := range result.Files {
+		if scanner.IsNodeJSManifest(file.Type) {
+			hasNodeJS = true
+			break
+		}
+	}
+	for _, eco := range ecosystems {
+		if eco.enabled != nil && !eco.enabled(opts) {
+			continue
+		}
+		for _, file := range result.Files {
+			if eco.detect(file.Type) {
+				active = append(active, eco)
+				break
+			}
+		}
+	}
+	if !hasNodeJS && len(active) == 0 {
+		return nil, ErrNothingToAudit
+	}
+
+	// Check if npm is installed (only if we have Node.js manifests)
+	// Without npm, package-lock.json or pinned package.json dependencies are checked against OSV instead
+	npmInstalled := true
+	if hasNodeJS && !opts.SkipVulnerabilities {
+		if err := audit.CheckNpmInstalled(); err != nil {
+			if opts.Verbose {
+				fmt.Fprintf(os.Stderr, "Warning: npm is not installed. Falling back to OSV for locked or pinned Node.js dependencies.\n")
+			}
+			npmInstalled = false
+		}
+	}
+
+	// Vulnerability audits can be left out to run only the supply chain checks
+	if opts.SkipVulnerabilities {
+		active = nil
+	}
+
+	// Get package.json files. Workspace members are audited by a single npm
+	// audit at their workspace root, which holds the lockfile.
+	packageJSONFiles := result.GetManifestsByType(scanner.PackageJSON)
+	auditedPackageJSON, workspaceMembers := npmManifestsToAudit(packageJSONFiles)
+	if opts.SkipVulnerabilities {
+		auditedPackageJSON = nil
+	}
+	if opts.Verbose && !opts.SkipVulnerabilities {
+		for root, members := range workspaceMembers {
+			for _, member := range members {
+				fmt.Fprintf(os.Stderr, "Auditing workspace member %s with its root %s\n", member, root)
+			}
+		}
+	}
+	if hasNodeJS && len(packageJSONFiles) == 0 {
+		if opts.Verbose {
+			fmt.Fprintln(os.Stderr, "\nNo package.json files found. Skipping npm audit.")
+		}
+	}
+
+	if opts.Verbose && !opts.SkipVulnerabilities {
+		fmt.Fprintf(os.Stderr, "\nRunning npm audit on %d package.json file(s)...\n", len(auditedPackageJSON))
+	}
+
+	// Every manifest is listed before the first audit, so progress can show
+	// the total
+	var supplyChainManifests []scanner.DetectedFile
+	if len(opts.SupplyChain.Checks) > 0 {
+		supplyChainManifests = packageJSONFiles
+	}
+	manifests := make([][]scanner.DetectedFile, len(active))
+	total := len(auditedPackageJSON) + len(supplyChainManifests)
+	for i, eco := range active {
+		manifests[i] = eco.manifests(result)
+		total += len(manifests[i])
+	}
+	if opts.Progress != nil {
+		opts.Progress.Start(total)
+	}
+
+	a := &auditor{
+		opts:   opts,
+		runner: runner,
+		output: &formatter.ScanOutput{
+			ScanResults:          result,
+			AuditResults:         make([]*audit.AuditResult, 0),
+			PythonAuditResults:   make([]*audit.PythonAuditResult, 0),
+			GoAuditResults:       make([]*audit.GoAuditResult, 0),
+			MavenAuditResults:    make([]*audit.MavenAuditResult, 0),
+			SwiftAuditResults:    make([]*audit.SwiftAuditResult, 0),
+			CargoAuditResults:    make([]*audit.CargoAuditResult, 0),
+			RubyAuditResults:     make([]*audit.RubyAuditResult, 0),
+			ComposerAuditResults: make([]*audit.ComposerAuditResult, 0),
+			RuntimeAuditResults:  make([]*audit.RuntimeAuditResult, 0),
+			CustomAuditResults:   make([]*audit.CustomAuditResult, 0),
+			SecurityResults:      make([]*security.ManifestReport, 0),
+		},
+	}
+
+	// Run audit on each package.json
+	a.output.AuditResults = auditAll(a, "", auditedPackageJSON, func(pkgFile scanner.DetectedFile) *audit.AuditResult {
+		if opts.Verbose {
+			fmt.Fprintf(os.Stderr, "\nAuditing: %s\n", pkgFile.Path)
+		}
+
+		var auditResult *audit.AuditResult
+		if npmInstalled {
+			auditResult = runner.RunAudit(pkgFile.Path)
+		} else {
+			auditResult = runner.RunNpmOSVAudit(pkgFile.Path)
+		}
+		auditResult.Workspaces = workspaceMembers[pkgFile.Path]
+
+		// Info findings are dropped from the summary too unless requested
+		if opts.MinSeverity != audit.SeverityInfo {
+			auditResult.ExcludeInfo()
+		}
+
+		// Filter vulnerabilities by severity, recounting the summary
+		audit.FilterSeverity(auditResult, opts.MinSeverity)
+		return auditResult
+	}, func(auditResult *audit.AuditResult) (int, error) {
+		return auditResult.Summary.Total, auditResult.Error
+	})
+
+	// Run supply chain checks on the dependencies of each package.json
+	if len(supplyChainManifests) > 0 {
+		a.output.SecurityResults = auditEach(supplyChainManifests, opts.Concurrency, opts.Progress, func(pkgFile scanner.DetectedFile) *security.ManifestReport {
+			if opts.Verbose {
+				fmt.Fprintf(os.Stderr, "\nChecking supply chain (%s): %s\n", strings.Join(opts.SupplyChain.Checks, ", "), pkgFile.Path)
+			}
+
+			return security.CheckManifest(ctx, pkgFile.Path, opts.SupplyChain)
+		})
+	}
+
+	// Run the audits of every other ecosystem, which query OSV
+	for i, eco := range active {
+		if eco.files != "" && len(manifests[i]) > 0 && opts.Verbose {
+			fmt.Fprintf(os.Stderr, "\nChecking %d %s for vulnerabilities using OSV API...\n", len(manifests[i]), eco.files)
+		}
+		eco.audit(a, manifests[i])
+	}
+
+	a.output.Backends = scanBackends(runner, npmInstalled && len(auditedPackageJSON) > 0, !npmInstalled || len(active) > 0)
+	return a.output, nil
+}
+
+// auditAll audits manifests with run, concurrently up to the configured
+// limit, and adds each result's findings and error to the output's totals,
+// which outcome reports. label, unless empty, names the ecosystem in the log.
+func auditAll[T any](a *auditor, label string, manifests []scanner.DetectedFile, run func(scanner.DetectedFile) T, outcome func(T) (int, error)) []T {
+	results := auditEach(manifests, a.opts.Concurrency, a.opts.Progress, func(file scanner.DetectedFile) T {
+		if a.opts.Verbose && label != "" {
+			fmt.Fprintf(os.Stderr, "\nAuditing %s: %s\n", label, file.Path)
+		}
+		return run(file)
+	})
+	for _, result := range results {
+		a.tally(outcome(result))
+	}
+	return results
+}
+
+// tally adds the findings of one audit result to the output's totals
+func (a *auditor) tally(findings int, err error) {
+	if err != nil {
+		a.output.HasErrors = true
+	}
+	a.output.TotalVulns += findings
+}
+
+// scanBackends lists the vulnerability data sources a scan used
+func scanBackends(runner *audit.Runner, usedNpm, usedOSV bool) []formatter.ScanBackend {
+	var backends []formatter.ScanBackend
+	if usedNpm {
+		npmVersion, _ := audit.NpmVersion()
+		backends = append(backends, formatter.ScanBackend{Name: "npm audit", Version: npmVersion})
+	}
+	if usedOSV {
+		backends = append(backends, formatter.ScanBackend{Name: "osv", Endpoint: runner.OSVEndpoint()})
+	}
+	return backends
+}
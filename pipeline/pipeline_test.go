@@ -0,0 +1,151 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/brandonapol/snoop/audit"
+	"github.com/brandonapol/snoop/osv"
+	"github.com/brandonapol/snoop/scanner"
+)
+
+func TestAuditConcurrently(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response := osv.QueryResponse{Vulns: []osv.Vulnerability{{ID: "GHSA-m2qf-hxjv-5gpq", Summary: "Flask session cookie disclosure"}}}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Errorf("failed to encode mock response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	runner := audit.NewRunner(0, false)
+	runner.SetOSVClient(osv.NewClientWithURL(server.URL))
+
+	dir := t.TempDir()
+	var result scanner.ScanResult
+	for i := range 8 {
+		manifest := filepath.Join(dir, fmt.Sprintf("service-%d", i), "requirements.txt")
+		if err := os.MkdirAll(filepath.Dir(manifest), 0o755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(manifest, []byte("flask==2.0.0\n"), 0o644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+		result.Files = append(result.Files, scanner.DetectedFile{Path: manifest, Type: scanner.RequirementsTxt})
+	}
+
+	output, err := Audit(context.Background(), &result, Options{Runner: runner, Concurrency: DefaultConcurrency})
+	if err != nil {
+		t.Fatalf("Audit() unexpected error: %v", err)
+	}
+	if output.TotalVulns != 8 {
+		t.Errorf("TotalVulns = %d, expected 8", output.TotalVulns)
+	}
+	if len(output.PythonAuditResults) != 8 {
+		t.Fatalf("got %d Python results, expected 8", len(output.PythonAuditResults))
+	}
+	for i, pythonResult := range output.PythonAuditResults {
+		if pythonResult.ManifestPath != result.Files[i].Path {
+			t.Errorf("Python result %d is for %s, expected %s", i, pythonResult.ManifestPath, result.Files[i].Path)
+		}
+	}
+}
+
+func TestAuditRegisteredManifestType(t *testing.T) {
+	const manifestType = "deps.pipeline"
+
+	if err := scanner.RegisterManifest(manifestType, string(osv.PyPI)); err != nil {
+		t.Fatalf("RegisterManifest() unexpected error: %v", err)
+	}
+	err := audit.RegisterParser(manifestType, func(path string) ([]audit.ManifestPackage, error) {
+		return []audit.ManifestPackage{{Name: "jinja2", Version: "2.10", IsDirect: true}}, nil
+	})
+	if err != nil {
+		t.Fatalf("RegisterParser() unexpected error: %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"vulns":[{"id":"PYSEC-2024-1","summary":"sandbox escape"}]}`)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, manifestType), nil, 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	s, err := scanner.New(dir, false)
+	if err != nil {
+		t.Fatalf("scanner.New() unexpected error: %v", err)
+	}
+	result, err := s.Scan()
+	if err != nil {
+		t.Fatalf("Scan() unexpected error: %v", err)
+	}
+
+	runner := audit.NewRunner(0, false)
+	runner.SetOSVClient(osv.NewClientWithURL(server.URL))
+	output, err := Audit(context.Background(), result, Options{Runner: runner})
+	if err != nil {
+		t.Fatalf("Audit() unexpected error: %v", err)
+	}
+	if len(output.CustomAuditResults) != 1 || output.CustomAuditResults[0].Ecosystem != osv.PyPI {
+		t.Fatalf("CustomAuditResults = %+v, expected one PyPI result", output.CustomAuditResults)
+	}
+	if output.TotalVulns != 1 {
+		t.Errorf("TotalVulns = %d, expected 1", output.TotalVulns)
+	}
+	if len(output.Backends) != 1 || output.Backends[0].Name != "osv" {
+		t.Errorf("Backends = %+v, expected only osv", output.Backends)
+	}
+}
+
+func TestAuditNothingToAudit(t *testing.T) {
+	// Runtime version files are only audited with Options.Runtime
+	result := &scanner.ScanResult{Files: []scanner.DetectedFile{{Path: ".nvmrc", Type: scanner.Nvmrc}}}
+
+	if _, err := Audit(context.Background(), result, Options{}); !errors.Is(err, ErrNothingToAudit) {
+		t.Errorf("Audit() error = %v, expected ErrNothingToAudit", err)
+	}
+}
+
+func TestAuditSummaryCountsFilteredFindings(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"vulns":[
+			{"id":"GHSA-high","database_specific":{"severity":"HIGH"}},
+			{"id":"GHSA-low","database_specific":{"severity":"LOW"}}
+		]}`)
+	}))
+	defer server.Close()
+
+	manifest := filepath.Join(t.TempDir(), "requirements.txt")
+	if err := os.WriteFile(manifest, []byte("flask==2.0.0\n"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	result := &scanner.ScanResult{Files: []scanner.DetectedFile{{Path: manifest, Type: scanner.RequirementsTxt}}}
+
+	runner := audit.NewRunner(0, false)
+	runner.SetOSVClient(osv.NewClientWithURL(server.URL))
+	output, err := Audit(context.Background(), result, Options{Runner: runner, MinSeverity: audit.SeverityHigh})
+	if err != nil {
+		t.Fatalf("Audit() unexpected error: %v", err)
+	}
+
+	python := output.PythonAuditResults[0]
+	if len(python.Vulnerabilities) != 1 || python.Vulnerabilities[0].ID != "GHSA-high" {
+		t.Fatalf("Vulnerabilities = %+v, expected only GHSA-high", python.Vulnerabilities)
+	}
+	expected := audit.VulnerabilitySummary{High: 1, Total: 1, Direct: 1}
+	if python.Summary != expected {
+		t.Errorf("Summary = %+v, expected %+v to match the filtered findings", python.Summary, expected)
+	}
+	if output.TotalVulns != 1 {
+		t.Errorf("TotalVulns = %d, expected 1", output.TotalVulns)
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// profiles are named bundles of flag defaults for common use cases. Each entry
+// maps a flag name to the value it takes when the flag isn't set explicitly.
+var profiles = map[string]map[string]string{
+	// Machine-readable output for pipelines
+	"ci": {
+		"format":  "json",
+		"strict":  "true",
+		"fail-on": "high",
+	},
+	// Everything, in the terminal
+	"dev": {
+		"format":   "table",
+		"severity": "info",
+	},
+	// A stable document for committing alongside the code
+	"report": {
+		"format":     "markdown",
+		"normalized": "true",
+	},
+}
+
+// profileNames returns the available profile names in sorted order
+func profileNames() []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// applyProfile sets the defaults of the named profile on every flag the user
+// hasn't set explicitly, so individual flags always override the profile
+func applyProfile(flags *pflag.FlagSet, name string) error {
+	if name == "" {
+		return nil
+	}
+
+	preset, ok := profiles[name]
+	if !ok {
+		return fmt.Errorf("unknown profile %q (available: %s)", name, strings.Join(profileNames(), ", "))
+	}
+
+	for flagName, value := range preset {
+		if flags.Changed(flagName) {
+			continue
+		}
+		if err := flags.Set(flagName, value); err != nil {
+			return fmt.Errorf("profile %q: invalid value for --%s: %w", name, flagName, err)
+		}
+	}
+
+	return nil
+}
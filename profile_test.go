@@ -0,0 +1,77 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+// newProfileTestFlags registers the flags profiles can set on a fresh flag set
+func newProfileTestFlags() *pflag.FlagSet {
+	flags := pflag.NewFlagSet("snoop", pflag.ContinueOnError)
+	flags.String("format", "table", "")
+	flags.String("severity", "low", "")
+	flags.Bool("normalized", false, "")
+	flags.Bool("strict", false, "")
+	flags.String("fail-on", "none", "")
+	return flags
+}
+
+func TestApplyProfile(t *testing.T) {
+	tests := []struct {
+		profile  string
+		args     []string
+		expected map[string]string
+	}{
+		{
+			profile:  "ci",
+			expected: map[string]string{"format": "json", "severity": "low", "normalized": "false", "strict": "true", "fail-on": "high"},
+		},
+		{
+			profile:  "dev",
+			expected: map[string]string{"format": "table", "severity": "info", "normalized": "false"},
+		},
+		{
+			profile:  "report",
+			expected: map[string]string{"format": "markdown", "severity": "low", "normalized": "true"},
+		},
+		{
+			profile:  "ci",
+			args:     []string{"--format", "markdown"},
+			expected: map[string]string{"format": "markdown", "severity": "low", "normalized": "false"},
+		},
+		{
+			profile:  "report",
+			args:     []string{"--normalized=false", "--severity", "critical"},
+			expected: map[string]string{"format": "markdown", "severity": "critical", "normalized": "false"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.profile, func(t *testing.T) {
+			flags := newProfileTestFlags()
+			if err := flags.Parse(tt.args); err != nil {
+				t.Fatalf("Parse() unexpected error: %v", err)
+			}
+
+			if err := applyProfile(flags, tt.profile); err != nil {
+				t.Fatalf("applyProfile() unexpected error: %v", err)
+			}
+
+			for name, want := range tt.expected {
+				if got := flags.Lookup(name).Value.String(); got != want {
+					t.Errorf("--%s = %q, expected %q", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestApplyProfileUnknown(t *testing.T) {
+	if err := applyProfile(newProfileTestFlags(), "nope"); err == nil {
+		t.Error("applyProfile() expected error for unknown profile")
+	}
+	if err := applyProfile(newProfileTestFlags(), ""); err != nil {
+		t.Errorf("applyProfile() with no profile unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/brandonapol/snoop/formatter"
+)
+
+// auditProgress shows how many manifests have been audited on a single,
+// redrawn line, such as "Auditing 37/212 manifests". A nil auditProgress
+// shows nothing, so callers needn't check whether progress is enabled.
+type auditProgress struct {
+	mu    sync.Mutex // Serializes redraws from concurrent audits
+	w     io.Writer
+	done  int
+	total int
+}
+
+// newAuditProgress returns a progress line, drawn once Start is told the
+// total, or nil when progress shouldn't be shown (see showProgress)
+func newAuditProgress() *auditProgress {
+	if !showProgress() {
+		return nil
+	}
+	return &auditProgress{w: os.Stderr}
+}
+
+// showProgress reports whether to show progress: only for an interactive
+// run, where both stdout and stderr are terminals, and not when it's turned
+// off with --no-progress, replaced by --verbose logging, or the report is
+// JSON meant for another program
+func showProgress() bool {
+	if noProgress || verbose || formatter.OutputFormat(format) == formatter.FormatJSON {
+		return false
+	}
+	return isTerminal(os.Stdout) && isTerminal(os.Stderr)
+}
+
+// isTerminal reports whether file is a terminal rather than a pipe or file
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// Start draws the progress line for total manifests; with none to audit
+// nothing is shown
+func (p *auditProgress) Start(total int) {
+	if p == nil || total == 0 {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.total = total
+	p.draw()
+}
+
+// Advance counts n more manifests as audited. It's safe to call from
+// concurrent audits.
+func (p *auditProgress) Advance(n int) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done += n
+	p.draw()
+}
+
+// draw rewrites the progress line; the caller holds mu
+func (p *auditProgress) draw() {
+	fmt.Fprintf(p.w, "\rAuditing %d/%d manifests", p.done, p.total)
+}
+
+// finish clears the progress line, if Start drew one, so the report starts
+// on a clean line
+func (p *auditProgress) finish() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.total > 0 {
+		fmt.Fprint(p.w, "\r\033[K")
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestAuditProgressCountsConcurrentAudits(t *testing.T) {
+	var out strings.Builder
+	p := &auditProgress{w: &out, total: 12}
+
+	var wg sync.WaitGroup
+	for range 12 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.Advance(1)
+		}()
+	}
+	wg.Wait()
+	p.finish()
+
+	if !strings.Contains(out.String(), "\rAuditing 12/12 manifests") {
+		t.Errorf("progress output = %q, expected it to reach 12/12", out.String())
+	}
+	if !strings.HasSuffix(out.String(), "\r\033[K") {
+		t.Errorf("progress output = %q, expected the line cleared at the end", out.String())
+	}
+}
+
+func TestAuditProgressNilIsQuiet(t *testing.T) {
+	var p *auditProgress
+	p.Start(10)
+	p.Advance(1)
+	p.finish()
+}
+
+func TestShowProgressOff(t *testing.T) {
+	previous := noProgress
+	t.Cleanup(func() { noProgress = previous })
+
+	noProgress = true
+	if showProgress() {
+		t.Error("showProgress() = true with --no-progress, expected false")
+	}
+	if newAuditProgress() != nil {
+		t.Error("newAuditProgress() with --no-progress expected nil")
+	}
+}
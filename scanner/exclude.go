@@ -0,0 +1,74 @@
+package scanner
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// ValidateExcludePatterns checks that every pattern passed to SetExcludes is
+// well formed
+func ValidateExcludePatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		for _, segment := range strings.Split(cleanExcludePattern(pattern), "/") {
+			if _, err := path.Match(segment, ""); err != nil {
+				return fmt.Errorf("invalid exclude pattern %q: %w", pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+// SetExcludes makes Scan skip files and directories whose path relative to
+// the root matches one of patterns. Patterns use filepath.Match syntax with
+// "/" separators, where a "**" segment also matches any number of
+// directories, so **/testdata/** skips every testdata directory.
+func (s *Scanner) SetExcludes(patterns []string) {
+	s.excludes = nil
+	for _, pattern := range patterns {
+		if pattern = cleanExcludePattern(pattern); pattern != "" {
+			s.excludes = append(s.excludes, pattern)
+		}
+	}
+}
+
+// cleanExcludePattern trims the leading ./ and trailing / a pattern may be
+// written with
+func cleanExcludePattern(pattern string) string {
+	pattern = strings.TrimPrefix(strings.TrimSpace(pattern), "./")
+	return strings.TrimSuffix(pattern, "/")
+}
+
+// isExcluded reports whether rel, a slash-separated path relative to the
+// root, matches one of the exclude patterns
+func (s *Scanner) isExcluded(rel string) bool {
+	for _, pattern := range s.excludes {
+		if matchSegments(strings.Split(pattern, "/"), strings.Split(rel, "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSegments matches path segments against pattern segments, letting a
+// "**" segment stand for zero or more path segments
+func matchSegments(pattern, segments []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for skip := 0; skip <= len(segments); skip++ {
+				if matchSegments(pattern[1:], segments[skip:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segments) == 0 {
+			return false
+		}
+		if matched, _ := path.Match(pattern[0], segments[0]); !matched {
+			return false
+		}
+		pattern, segments = pattern[1:], segments[1:]
+	}
+	return len(segments) == 0
+}
@@ -0,0 +1,57 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+)
+
+// gitignoreFile is the name of the ignore files Scan reads
+const gitignoreFile = ".gitignore"
+
+// SetRespectGitignore controls whether Scan reads the .gitignore files it
+// walks past and skips the paths they ignore. It is on by default.
+func (s *Scanner) SetRespectGitignore(respect bool) {
+	s.respectGitignore = respect
+}
+
+// gitignoreRules holds the patterns of every .gitignore read so far in a
+// walk. Each pattern only applies below the directory its file is in, so
+// one list serves the whole tree; files deeper in the tree are read later
+// and so take precedence, as they do in git.
+type gitignoreRules struct {
+	patterns []gitignore.Pattern
+}
+
+// load reads the .gitignore in dir, if there is one. domain is dir's path
+// relative to the scan root, split into segments.
+func (r *gitignoreRules) load(dir string, domain []string) error {
+	data, err := os.ReadFile(filepath.Join(dir, gitignoreFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read %s: %w", filepath.Join(dir, gitignoreFile), err)
+	}
+
+	lines := bufio.NewScanner(bytes.NewReader(data))
+	for lines.Scan() {
+		line := strings.TrimSuffix(lines.Text(), "\r")
+		if strings.HasPrefix(line, "#") || strings.TrimSpace(line) == "" {
+			continue
+		}
+		r.patterns = append(r.patterns, gitignore.ParsePattern(line, domain))
+	}
+	return lines.Err()
+}
+
+// ignored reports whether the path with the given segments, relative to the
+// scan root, is ignored by the rules read so far
+func (r *gitignoreRules) ignored(segments []string, isDir bool) bool {
+	return len(r.patterns) > 0 && gitignore.NewMatcher(r.patterns).Match(segments, isDir)
+}
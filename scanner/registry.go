@@ -0,0 +1,110 @@
+package scanner
+
+import (
+	"fmt"
+	"sync"
+)
+
+// manifest is a registered manifest type: the OSV ecosystem its packages
+// belong to, and whether a library user added it with RegisterManifest
+type manifest struct {
+	ecosystem string // Empty for runtime version declarations
+	custom    bool
+}
+
+// manifests maps every filename Scan detects to its manifest type: the
+// built-in ones below, and those library users register
+var (
+	manifestsMu sync.RWMutex
+	manifests   = map[ManifestType]manifest{
+		// Node.js manifests
+		PackageJSON:     {ecosystem: "npm"},
+		PackageLockJSON: {ecosystem: "npm"},
+		YarnLock:        {ecosystem: "npm"},
+		PnpmLockYAML:    {ecosystem: "npm"},
+
+		// Python manifests
+		RequirementsTxt: {ecosystem: "PyPI"},
+		Pipfile:         {ecosystem: "PyPI"},
+		PipfileLock:     {ecosystem: "PyPI"},
+		PoetryLock:      {ecosystem: "PyPI"},
+		PyprojectTOML:   {ecosystem: "PyPI"},
+
+		// Go manifests
+		GoMod: {ecosystem: "Go"},
+		GoSum: {ecosystem: "Go"},
+
+		// Maven/Java manifests
+		PomXML:         {ecosystem: "Maven"},
+		BuildGradle:    {ecosystem: "Maven"},
+		BuildGradleKts: {ecosystem: "Maven"},
+
+		// Cargo manifests
+		CargoToml: {ecosystem: "crates.io"},
+		CargoLock: {ecosystem: "crates.io"},
+
+		// Ruby manifests
+		GemfileLock: {ecosystem: "RubyGems"},
+
+		// PHP manifests
+		ComposerLock: {ecosystem: "Packagist"},
+
+		// Swift Package Manager manifests
+		PackageSwift:    {ecosystem: "SwiftURL"},
+		PackageResolved: {ecosystem: "SwiftURL"},
+
+		// Runtime version declarations
+		Nvmrc:         {},
+		PythonVersion: {},
+		ToolVersions:  {},
+	}
+)
+
+// RegisterManifest makes Scan detect files named filename as manifests of the
+// given OSV ecosystem (e.g. "PyPI" or "crates.io"). Pair it with
+// audit.RegisterParser to audit the packages they declare.
+func RegisterManifest(filename string, ecosystem string) error {
+	if filename == "" || ecosystem == "" {
+		return fmt.Errorf("manifest filename and ecosystem are required")
+	}
+
+	manifestsMu.Lock()
+	defer manifestsMu.Unlock()
+
+	if existing, ok := manifests[ManifestType(filename)]; ok {
+		if !existing.custom {
+			return fmt.Errorf("%s is a built-in manifest type", filename)
+		}
+		return fmt.Errorf("manifest %s is already registered", filename)
+	}
+	manifests[ManifestType(filename)] = manifest{ecosystem: ecosystem, custom: true}
+	return nil
+}
+
+// ManifestEcosystem returns the OSV ecosystem the packages of a manifest type
+// belong to. Runtime version declarations have none.
+func ManifestEcosystem(t ManifestType) (string, bool) {
+	manifestsMu.RLock()
+	defer manifestsMu.RUnlock()
+
+	registered, ok := manifests[t]
+	return registered.ecosystem, ok && registered.ecosystem != ""
+}
+
+// IsCustomManifest returns true if the manifest type was added with RegisterManifest
+func IsCustomManifest(t ManifestType) bool {
+	_, ok := CustomManifestEcosystem(t)
+	return ok
+}
+
+// CustomManifestEcosystem returns the OSV ecosystem a registered manifest type belongs to
+func CustomManifestEcosystem(t ManifestType) (string, bool) {
+	manifestsMu.RLock()
+	defer manifestsMu.RUnlock()
+
+	registered, ok := manifests[t]
+	if !ok || !registered.custom {
+		return "", false
+	}
+	return registered.ecosystem, true
+}
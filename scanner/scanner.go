@@ -2,8 +2,10 @@ package scanner
 
 import (
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 // ManifestType represents the type of package manifest (Node.js or Python)
@@ -28,7 +30,28 @@ const (
 	GoSum ManifestType = "go.sum"
 
 	// Maven/Java manifest types
-	PomXML ManifestType = "pom.xml"
+	PomXML         ManifestType = "pom.xml"
+	BuildGradle    ManifestType = "build.gradle"
+	BuildGradleKts ManifestType = "build.gradle.kts"
+
+	// Cargo (Rust) manifest types
+	CargoToml ManifestType = "Cargo.toml"
+	CargoLock ManifestType = "Cargo.lock"
+
+	// Ruby manifest types
+	GemfileLock ManifestType = "Gemfile.lock"
+
+	// PHP manifest types
+	ComposerLock ManifestType = "composer.lock"
+
+	// Swift Package Manager manifest types
+	PackageSwift    ManifestType = "Package.swift"
+	PackageResolved ManifestType = "Package.resolved"
+
+	// Runtime version declarations
+	Nvmrc         ManifestType = ".nvmrc"
+	PythonVersion ManifestType = ".python-version"
+	ToolVersions  ManifestType = ".tool-versions"
 )
 
 // DetectedFile represents a detected manifest file
@@ -39,37 +62,20 @@ type DetectedFile struct {
 
 // ScanResult contains the results of scanning a directory
 type ScanResult struct {
-	Files  []DetectedFile
-	Errors []error
-}
-
-// manifestFiles is the list of files we're looking for
-var manifestFiles = []string{
-	// Node.js manifests
-	string(PackageJSON),
-	string(PackageLockJSON),
-	string(YarnLock),
-	string(PnpmLockYAML),
-
-	// Python manifests
-	string(RequirementsTxt),
-	string(Pipfile),
-	string(PipfileLock),
-	string(PoetryLock),
-	string(PyprojectTOML),
-
-	// Go manifests
-	string(GoMod),
-	string(GoSum),
-
-	// Maven/Java manifests
-	string(PomXML),
+	Files    []DetectedFile
+	Errors   []error
+	Warnings []string // Paths skipped by ScanFiles
 }
 
-// Scanner handles directory scanning for Node.js, Python, Go, and Maven manifest files
+// Scanner handles directory scanning for Node.js, Python, Go, Maven, Cargo, Ruby, PHP, and Swift manifest files
 type Scanner struct {
 	rootPath string
 	verbose  bool
+	excludes []string // Slash-separated patterns; see SetExcludes
+
+	respectGitignore bool
+	maxDepth         int // Deepest directory walked, with the root at 0; negative for no limit
+	followSymlinks   bool
 }
 
 // New creates a new Scanner instance
@@ -88,83 +94,173 @@ func New(rootPath string, verbose bool) (*Scanner, error) {
 	}
 
 	return &Scanner{
-		rootPath: rootPath,
-		verbose:  verbose,
+		rootPath:         rootPath,
+		verbose:          verbose,
+		respectGitignore: true,
+		maxDepth:         -1,
 	}, nil
 }
 
-// Scan walks the directory tree and detects all Node.js, Python, Go, and Maven manifest files
+// SetMaxDepth limits how many directories below the root Scan descends: 0
+// scans only the root directory, 1 its immediate subdirectories too, and so
+// on. A negative depth removes the limit.
+func (s *Scanner) SetMaxDepth(depth int) {
+	s.maxDepth = depth
+}
+
+// Scan walks the directory tree and detects all Node.js, Python, Go, Maven, Cargo, Ruby, PHP, and Swift manifest files
 func (s *Scanner) Scan() (*ScanResult, error) {
 	result := &ScanResult{
 		Files:  make([]DetectedFile, 0),
 		Errors: make([]error, 0),
 	}
 
-	err := filepath.Walk(s.rootPath, func(path string, info os.FileInfo, err error) error {
+	var ignores gitignoreRules
+	var visited []os.FileInfo // Directories walked so far, when following symlinks
+
+	var visit fs.WalkDirFunc
+	visit = func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			// Collect error but continue walking
 			result.Errors = append(result.Errors, fmt.Errorf("error accessing %s: %w", path, err))
 			return nil
 		}
 
+		isDir := d.IsDir()
+		symlink := d.Type()&fs.ModeSymlink != 0
+		if symlink {
+			if !s.followSymlinks {
+				if _, ok := DetectManifestType(d.Name()); ok {
+					result.Errors = append(result.Errors, fmt.Errorf("skipped symlinked manifest %s; use --follow-symlinks to scan it", path))
+				}
+				return nil
+			}
+			target, err := os.Stat(path)
+			if err != nil {
+				result.Errors = append(result.Errors, fmt.Errorf("error following symlink %s: %w", path, err))
+				return nil
+			}
+			isDir = target.IsDir()
+		}
+
+		// skip leaves out path, and everything below it when it's a directory.
+		// WalkDir sees a symlink as a file, and SkipDir on a file would skip
+		// the rest of its directory.
+		skip := func() error {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		var segments []string
+		if rel, err := filepath.Rel(s.rootPath, path); err == nil && rel != "." {
+			rel = filepath.ToSlash(rel)
+			segments = strings.Split(rel, "/")
+
+			if isDir && s.maxDepth >= 0 && len(segments) > s.maxDepth {
+				if s.verbose {
+					fmt.Fprintf(os.Stderr, "Skipping directory beyond max depth: %s\n", path)
+				}
+				return skip()
+			}
+
+			if s.isExcluded(rel) {
+				if s.verbose {
+					fmt.Fprintf(os.Stderr, "Skipping excluded path: %s\n", path)
+				}
+				return skip()
+			}
+
+			if s.respectGitignore && ignores.ignored(segments, isDir) {
+				if s.verbose {
+					fmt.Fprintf(os.Stderr, "Skipping path ignored by .gitignore: %s\n", path)
+				}
+				return skip()
+			}
+		}
+
 		// Skip directories
-		if info.IsDir() {
-			dirName := info.Name()
+		if isDir {
+			dirName := d.Name()
 
 			// Skip node_modules directories to avoid deep recursion
 			if dirName == "node_modules" {
 				if s.verbose {
-					fmt.Printf("Skipping node_modules: %s\n", path)
+					fmt.Fprintf(os.Stderr, "Skipping node_modules: %s\n", path)
 				}
-				return filepath.SkipDir
+				return skip()
 			}
 
 			// Skip Python virtual environment directories
 			if dirName == "venv" || dirName == ".venv" || dirName == "env" || dirName == ".env" || dirName == "__pycache__" {
 				if s.verbose {
-					fmt.Printf("Skipping Python directory: %s\n", path)
+					fmt.Fprintf(os.Stderr, "Skipping Python directory: %s\n", path)
 				}
-				return filepath.SkipDir
+				return skip()
 			}
 
-			// Skip Go vendor directory
+			// Skip vendor directories: Go's vendored modules, the gems
+			// Bundler installs to vendor/bundle, and Composer's packages
 			if dirName == "vendor" {
 				if s.verbose {
-					fmt.Printf("Skipping vendor directory: %s\n", path)
+					fmt.Fprintf(os.Stderr, "Skipping vendor directory: %s\n", path)
 				}
-				return filepath.SkipDir
+				return skip()
+			}
+
+			// Skip SwiftPM build directory, which holds checkouts of dependencies
+			if dirName == ".build" {
+				if s.verbose {
+					fmt.Fprintf(os.Stderr, "Skipping SwiftPM build directory: %s\n", path)
+				}
+				return skip()
 			}
 
-			// Skip Maven target directory
+			// Skip Maven and Cargo target directories
 			if dirName == "target" {
 				if s.verbose {
-					fmt.Printf("Skipping Maven target directory: %s\n", path)
+					fmt.Fprintf(os.Stderr, "Skipping target directory: %s\n", path)
 				}
-				return filepath.SkipDir
+				return skip()
 			}
 
-			return nil
-		}
+			if symlink {
+				return s.walkSymlinkedDir(path, visited, visit)
+			}
 
-		// Check if this file is one of our target manifests
-		filename := info.Name()
-		for _, manifestFile := range manifestFiles {
-			if filename == manifestFile {
-				detected := DetectedFile{
-					Path: path,
-					Type: ManifestType(manifestFile),
+			if s.followSymlinks {
+				if info, err := d.Info(); err == nil {
+					visited = append(visited, info)
 				}
-				result.Files = append(result.Files, detected)
+			}
 
-				if s.verbose {
-					fmt.Printf("Found %s: %s\n", manifestFile, path)
+			// Read the directory's own ignore rules before walking into it
+			if s.respectGitignore {
+				if err := ignores.load(path, segments); err != nil {
+					result.Errors = append(result.Errors, err)
 				}
-				break
+			}
+
+			return nil
+		}
+
+		// Check if this file is one of our target manifests
+		if manifestType, ok := DetectManifestType(d.Name()); ok {
+			result.Files = append(result.Files, DetectedFile{
+				Path: path,
+				Type: manifestType,
+			})
+
+			if s.verbose {
+				fmt.Fprintf(os.Stderr, "Found %s: %s\n", manifestType, path)
 			}
 		}
 
 		return nil
-	})
+	}
+
+	err := filepath.WalkDir(s.rootPath, visit)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to walk directory: %w", err)
@@ -173,6 +269,53 @@ func (s *Scanner) Scan() (*ScanResult, error) {
 	return result, nil
 }
 
+// DetectManifestType classifies a file by name as a built-in manifest or one
+// registered with RegisterManifest
+func DetectManifestType(filename string) (ManifestType, bool) {
+	manifestsMu.RLock()
+	defer manifestsMu.RUnlock()
+
+	if _, ok := manifests[ManifestType(filename)]; ok {
+		return ManifestType(filename), true
+	}
+	return "", false
+}
+
+// ScanFiles classifies an explicit list of manifest paths by filename, in
+// place of walking a directory. Paths that are missing, aren't files, or
+// aren't recognized manifests are skipped with a warning.
+func ScanFiles(paths []string, verbose bool) *ScanResult {
+	result := &ScanResult{
+		Files:  make([]DetectedFile, 0),
+		Errors: make([]error, 0),
+	}
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		switch {
+		case err != nil:
+			result.Warnings = append(result.Warnings, fmt.Sprintf("skipped %s: %v", path, err))
+			continue
+		case info.IsDir():
+			result.Warnings = append(result.Warnings, fmt.Sprintf("skipped %s: not a file", path))
+			continue
+		}
+
+		manifestType, ok := DetectManifestType(filepath.Base(path))
+		if !ok {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("skipped %s: not a recognized manifest", path))
+			continue
+		}
+
+		result.Files = append(result.Files, DetectedFile{Path: path, Type: manifestType})
+		if verbose {
+			fmt.Fprintf(os.Stderr, "Found %s: %s\n", manifestType, path)
+		}
+	}
+
+	return result
+}
+
 // GetManifestsByType returns all detected files of a specific type
 func (r *ScanResult) GetManifestsByType(manifestType ManifestType) []DetectedFile {
 	var filtered []DetectedFile
@@ -223,7 +366,38 @@ func IsGoManifest(t ManifestType) bool {
 	return t == GoMod || t == GoSum
 }
 
-// IsMavenManifest returns true if the manifest type is for Maven/Java
+// IsMavenManifest returns true if the manifest type is for Maven/Java,
+// including Gradle builds, which share Maven coordinates
 func IsMavenManifest(t ManifestType) bool {
-	return t == PomXML
+	return t == PomXML || IsGradleManifest(t)
+}
+
+// IsGradleManifest returns true if the manifest type is a Gradle build file
+func IsGradleManifest(t ManifestType) bool {
+	return t == BuildGradle || t == BuildGradleKts
+}
+
+// IsCargoManifest returns true if the manifest type is for Cargo (Rust)
+func IsCargoManifest(t ManifestType) bool {
+	return t == CargoToml || t == CargoLock
+}
+
+// IsRubyManifest returns true if the manifest type is for Ruby
+func IsRubyManifest(t ManifestType) bool {
+	return t == GemfileLock
+}
+
+// IsComposerManifest returns true if the manifest type is for PHP Composer
+func IsComposerManifest(t ManifestType) bool {
+	return t == ComposerLock
+}
+
+// IsSwiftManifest returns true if the manifest type is for Swift Package Manager
+func IsSwiftManifest(t ManifestType) bool {
+	return t == PackageSwift || t == PackageResolved
+}
+
+// IsRuntimeManifest returns true if the manifest type declares a language runtime version
+func IsRuntimeManifest(t ManifestType) bool {
+	return t == Nvmrc || t == PythonVersion || t == ToolVersions
 }
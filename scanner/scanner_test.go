@@ -3,6 +3,8 @@ package scanner
 import (
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -327,3 +329,460 @@ func TestIsPythonManifest(t *testing.T) {
 		})
 	}
 }
+
+func TestIsGradleManifest(t *testing.T) {
+	tests := []struct {
+		manifestType ManifestType
+		gradle       bool
+		maven        bool
+	}{
+		{BuildGradle, true, true},
+		{BuildGradleKts, true, true},
+		{PomXML, false, true},
+		{PackageJSON, false, false},
+		{GoMod, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.manifestType), func(t *testing.T) {
+			if result := IsGradleManifest(tt.manifestType); result != tt.gradle {
+				t.Errorf("IsGradleManifest(%q) = %v, expected %v", tt.manifestType, result, tt.gradle)
+			}
+			if result := IsMavenManifest(tt.manifestType); result != tt.maven {
+				t.Errorf("IsMavenManifest(%q) = %v, expected %v", tt.manifestType, result, tt.maven)
+			}
+		})
+	}
+}
+
+func TestIsCargoManifest(t *testing.T) {
+	tests := []struct {
+		manifestType ManifestType
+		expected     bool
+	}{
+		{CargoToml, true},
+		{CargoLock, true},
+		{PomXML, false},
+		{PackageJSON, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.manifestType), func(t *testing.T) {
+			if result := IsCargoManifest(tt.manifestType); result != tt.expected {
+				t.Errorf("IsCargoManifest(%q) = %v, expected %v", tt.manifestType, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestScanSkipsBundlerVendorDirectory(t *testing.T) {
+	tmpDir := t.TempDir()
+	// Installed gems bring Gemfile.lock files of their own
+	for _, path := range []string{"Gemfile.lock", filepath.Join("vendor", "bundle", "ruby", "3.2.0", "gems", "rack-2.2.3", "Gemfile.lock")} {
+		file := filepath.Join(tmpDir, path)
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatalf("Failed to create directory for %s: %v", path, err)
+		}
+		if err := os.WriteFile(file, []byte(""), 0644); err != nil {
+			t.Fatalf("Failed to create test file %s: %v", path, err)
+		}
+	}
+
+	scanner, err := New(tmpDir, false)
+	if err != nil {
+		t.Fatalf("Failed to create scanner: %v", err)
+	}
+	result, err := scanner.Scan()
+	if err != nil {
+		t.Fatalf("Scan() unexpected error: %v", err)
+	}
+
+	lockfiles := result.GetManifestsByType(GemfileLock)
+	if len(lockfiles) != 1 || lockfiles[0].Path != filepath.Join(tmpDir, "Gemfile.lock") {
+		t.Errorf("Scan() found Gemfile.lock files %v, expected only the root one", lockfiles)
+	}
+	if !IsRubyManifest(GemfileLock) || IsRubyManifest(PackageJSON) {
+		t.Error("IsRubyManifest() should be true only for Gemfile.lock")
+	}
+}
+
+func TestScanSkipsComposerVendorDirectory(t *testing.T) {
+	tmpDir := t.TempDir()
+	// Installed packages can ship composer.lock files of their own
+	for _, path := range []string{"composer.lock", filepath.Join("vendor", "guzzlehttp", "guzzle", "composer.lock")} {
+		file := filepath.Join(tmpDir, path)
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatalf("Failed to create directory for %s: %v", path, err)
+		}
+		if err := os.WriteFile(file, []byte("{}"), 0644); err != nil {
+			t.Fatalf("Failed to create test file %s: %v", path, err)
+		}
+	}
+
+	scanner, err := New(tmpDir, false)
+	if err != nil {
+		t.Fatalf("Failed to create scanner: %v", err)
+	}
+	result, err := scanner.Scan()
+	if err != nil {
+		t.Fatalf("Scan() unexpected error: %v", err)
+	}
+
+	lockfiles := result.GetManifestsByType(ComposerLock)
+	if len(lockfiles) != 1 || lockfiles[0].Path != filepath.Join(tmpDir, "composer.lock") {
+		t.Errorf("Scan() found composer.lock files %v, expected only the root one", lockfiles)
+	}
+	if !IsComposerManifest(ComposerLock) || IsComposerManifest(GemfileLock) {
+		t.Error("IsComposerManifest() should be true only for composer.lock")
+	}
+}
+
+func TestScanDetectsGradleBuilds(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, path := range []string{"build.gradle", filepath.Join("app", "build.gradle.kts"), "settings.gradle"} {
+		file := filepath.Join(tmpDir, path)
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatalf("Failed to create directory for %s: %v", path, err)
+		}
+		if err := os.WriteFile(file, []byte(""), 0644); err != nil {
+			t.Fatalf("Failed to create test file %s: %v", path, err)
+		}
+	}
+
+	scanner, err := New(tmpDir, false)
+	if err != nil {
+		t.Fatalf("Failed to create scanner: %v", err)
+	}
+	result, err := scanner.Scan()
+	if err != nil {
+		t.Fatalf("Scan() unexpected error: %v", err)
+	}
+
+	if len(result.Files) != 2 {
+		t.Fatalf("Scan() found %d files, expected 2 (settings.gradle isn't a manifest): %v", len(result.Files), result.Files)
+	}
+	if got := len(result.GetManifestsByType(BuildGradle)); got != 1 {
+		t.Errorf("Scan() found %d build.gradle files, expected 1", got)
+	}
+	if got := len(result.GetManifestsByType(BuildGradleKts)); got != 1 {
+		t.Errorf("Scan() found %d build.gradle.kts files, expected 1", got)
+	}
+}
+
+func TestRegisterManifest(t *testing.T) {
+	if err := RegisterManifest("Gemfile.custom", "RubyGems"); err != nil {
+		t.Fatalf("RegisterManifest() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		filename  string
+		ecosystem string
+	}{
+		{"duplicate", "Gemfile.custom", "RubyGems"},
+		{"built-in", string(RequirementsTxt), "PyPI"},
+		{"missing ecosystem", "deps.txt", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := RegisterManifest(tt.filename, tt.ecosystem); err == nil {
+				t.Errorf("RegisterManifest(%q, %q) expected error, got nil", tt.filename, tt.ecosystem)
+			}
+		})
+	}
+
+	if ecosystem, ok := CustomManifestEcosystem("Gemfile.custom"); !ok || ecosystem != "RubyGems" {
+		t.Errorf("CustomManifestEcosystem() = %q, %v, expected RubyGems, true", ecosystem, ok)
+	}
+	if IsCustomManifest(RequirementsTxt) {
+		t.Errorf("IsCustomManifest(%s) = true, expected false", RequirementsTxt)
+	}
+	if detected, ok := DetectManifestType("Gemfile.custom"); !ok || detected != "Gemfile.custom" {
+		t.Errorf("DetectManifestType(Gemfile.custom) = %q, %v, expected it detected", detected, ok)
+	}
+}
+
+func TestManifestEcosystem(t *testing.T) {
+	tests := []struct {
+		manifestType ManifestType
+		ecosystem    string
+		ok           bool
+	}{
+		{RequirementsTxt, "PyPI", true},
+		{CargoLock, "crates.io", true},
+		{Nvmrc, "", false},
+		{"unknown.txt", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.manifestType), func(t *testing.T) {
+			if ecosystem, ok := ManifestEcosystem(tt.manifestType); ecosystem != tt.ecosystem || ok != tt.ok {
+				t.Errorf("ManifestEcosystem(%s) = %q, %v, expected %q, %v", tt.manifestType, ecosystem, ok, tt.ecosystem, tt.ok)
+			}
+		})
+	}
+}
+
+func TestScanExcludes(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := []string{
+		"package.json",
+		"testdata/package.json",
+		"service/package.json",
+		"service/testdata/fixtures/requirements.txt",
+		"fixtures/one/package.json",
+		"fixtures/two/go.mod",
+		"docs/requirements.txt",
+	}
+	for _, name := range files {
+		path := filepath.Join(tmpDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte("{}"), 0644); err != nil {
+			t.Fatalf("Failed to create test file %s: %v", name, err)
+		}
+	}
+
+	scanner, err := New(tmpDir, false)
+	if err != nil {
+		t.Fatalf("Failed to create scanner: %v", err)
+	}
+	scanner.SetExcludes([]string{"**/testdata/**", "fixtures/*", "./docs/requirements.txt"})
+
+	result, err := scanner.Scan()
+	if err != nil {
+		t.Fatalf("Scan() unexpected error: %v", err)
+	}
+
+	found := make(map[string]bool)
+	for _, file := range result.Files {
+		rel, _ := filepath.Rel(tmpDir, file.Path)
+		found[filepath.ToSlash(rel)] = true
+	}
+	expected := map[string]bool{"package.json": true, "service/package.json": true}
+	if len(found) != len(expected) {
+		t.Errorf("Scan() found %v, expected %v", found, expected)
+	}
+	for name := range expected {
+		if !found[name] {
+			t.Errorf("Scan() did not find %s", name)
+		}
+	}
+}
+
+func TestMatchSegments(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"**/testdata/**", "testdata", true},
+		{"**/testdata/**", "a/b/testdata", true},
+		{"**/testdata/**", "a/testdata/c/package.json", true},
+		{"**/testdata/**", "a/testdatas", false},
+		{"fixtures/*", "fixtures/one", true},
+		{"fixtures/*", "fixtures/one/package.json", false},
+		{"fixtures/*", "src/fixtures/one", false},
+		{"*.lock", "yarn.lock", true},
+		{"*.lock", "web/yarn.lock", false},
+		{"**/*.lock", "web/yarn.lock", true},
+	}
+
+	for _, tt := range tests {
+		got := matchSegments(strings.Split(tt.pattern, "/"), strings.Split(tt.path, "/"))
+		if got != tt.want {
+			t.Errorf("matchSegments(%q, %q) = %v, expected %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestValidateExcludePatterns(t *testing.T) {
+	if err := ValidateExcludePatterns([]string{"**/testdata/**", "vendor/*"}); err != nil {
+		t.Errorf("ValidateExcludePatterns() unexpected error: %v", err)
+	}
+	if err := ValidateExcludePatterns([]string{"fixtures/[a-"}); err == nil {
+		t.Error("ValidateExcludePatterns() expected an error for a malformed pattern")
+	}
+}
+
+func TestScanRespectsGitignore(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		".gitignore":                         "# build output\nbuild/\n*.out/\n",
+		"package.json":                       "{}",
+		"build/requirements.txt":             "",
+		"reports.out/package.json":           "{}",
+		"service/.gitignore":                 "generated\n!generated/keep\n",
+		"service/package.json":               "{}",
+		"service/generated/requirements.txt": "",
+		"service/generated/keep/go.mod":      "module keep\n",
+		"other/generated/requirements.txt":   "",
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test file %s: %v", name, err)
+		}
+	}
+
+	scan := func(respect bool) map[string]bool {
+		scanner, err := New(tmpDir, false)
+		if err != nil {
+			t.Fatalf("Failed to create scanner: %v", err)
+		}
+		scanner.SetRespectGitignore(respect)
+
+		result, err := scanner.Scan()
+		if err != nil {
+			t.Fatalf("Scan() unexpected error: %v", err)
+		}
+		found := make(map[string]bool)
+		for _, file := range result.Files {
+			rel, _ := filepath.Rel(tmpDir, file.Path)
+			found[filepath.ToSlash(rel)] = true
+		}
+		return found
+	}
+
+	// A negation can't re-include a path whose parent directory is ignored,
+	// so service/generated/keep stays out, as it does in git
+	expected := map[string]bool{
+		"package.json":                     true,
+		"service/package.json":             true,
+		"other/generated/requirements.txt": true,
+	}
+	if found := scan(true); !reflect.DeepEqual(found, expected) {
+		t.Errorf("Scan() found %v, expected %v", found, expected)
+	}
+
+	if found := scan(false); len(found) != 7 {
+		t.Errorf("Scan() with .gitignore disabled found %d files, expected 7: %v", len(found), found)
+	}
+}
+
+func TestScanMaxDepth(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, name := range []string{
+		"package.json",
+		"a/requirements.txt",
+		"a/b/go.mod",
+		"a/b/c/package.json",
+	} {
+		path := filepath.Join(tmpDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte("{}"), 0644); err != nil {
+			t.Fatalf("Failed to create test file %s: %v", name, err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		root     string
+		maxDepth int
+		expected int
+	}{
+		{"root only", tmpDir, 0, 1},
+		{"one level", tmpDir, 1, 2},
+		{"two levels", tmpDir, 2, 3},
+		{"deeper than the tree", tmpDir, 10, 4},
+		{"no limit", tmpDir, -1, 4},
+		{"trailing slash", tmpDir + string(filepath.Separator), 1, 2},
+		{"trailing slashes", tmpDir + string(filepath.Separator) + string(filepath.Separator), 0, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner, err := New(tt.root, false)
+			if err != nil {
+				t.Fatalf("Failed to create scanner: %v", err)
+			}
+			scanner.SetMaxDepth(tt.maxDepth)
+
+			result, err := scanner.Scan()
+			if err != nil {
+				t.Fatalf("Scan() unexpected error: %v", err)
+			}
+			if len(result.Files) != tt.expected {
+				t.Errorf("Scan() with max depth %d found %d files, expected %d: %v", tt.maxDepth, len(result.Files), tt.expected, result.Files)
+			}
+		})
+	}
+}
+
+func TestScanSymlinks(t *testing.T) {
+	base := t.TempDir()
+	root := filepath.Join(base, "root")
+	outside := filepath.Join(base, "outside")
+	for name, content := range map[string]string{
+		"root/package.json":          "{}",
+		"root/real/requirements.txt": "",
+		"outside/package.json":       "{}",
+		"outside/go.mod":             "module outside\n",
+	} {
+		path := filepath.Join(base, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test file %s: %v", name, err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(root, "app"), 0755); err != nil {
+		t.Fatalf("Failed to create app directory: %v", err)
+	}
+
+	links := map[string]string{
+		"app/package.json": filepath.Join(outside, "package.json"), // Manifest outside the tree
+		"ext":              outside,                                // Symlinked workspace
+		"ws":               filepath.Join(root, "real"),            // Already scanned as real
+		"real/loop":        root,                                   // Cycle back to the root
+	}
+	for link, target := range links {
+		if err := os.Symlink(target, filepath.Join(root, filepath.FromSlash(link))); err != nil {
+			t.Skipf("symlinks not supported: %v", err)
+		}
+	}
+
+	scan := func(follow bool) ([]string, []error) {
+		scanner, err := New(root, false)
+		if err != nil {
+			t.Fatalf("Failed to create scanner: %v", err)
+		}
+		scanner.SetFollowSymlinks(follow)
+
+		result, err := scanner.Scan()
+		if err != nil {
+			t.Fatalf("Scan() unexpected error: %v", err)
+		}
+		var found []string
+		for _, file := range result.Files {
+			rel, _ := filepath.Rel(root, file.Path)
+			found = append(found, filepath.ToSlash(rel))
+		}
+		return found, result.Errors
+	}
+
+	found, errs := scan(false)
+	if expected := []string{"package.json", "real/requirements.txt"}; !reflect.DeepEqual(found, expected) {
+		t.Errorf("Scan() without following symlinks found %v, expected %v", found, expected)
+	}
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "skipped symlinked manifest") {
+		t.Errorf("Scan() errors = %v, expected a note for the symlinked manifest", errs)
+	}
+
+	found, errs = scan(true)
+	expected := []string{"app/package.json", "ext/go.mod", "ext/package.json", "package.json", "real/requirements.txt"}
+	if !reflect.DeepEqual(found, expected) {
+		t.Errorf("Scan() following symlinks found %v, expected %v", found, expected)
+	}
+	if len(errs) != 0 {
+		t.Errorf("Scan() following symlinks unexpected errors: %v", errs)
+	}
+}
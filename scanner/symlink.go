@@ -0,0 +1,54 @@
+package scanner
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// SetFollowSymlinks controls how Scan treats symbolic links. By default they
+// are not followed: symlinked directories are left out, and a symlinked
+// manifest is skipped with a note in ScanResult.Errors. When following, a
+// symlinked manifest is scanned like a regular file, and a symlinked
+// directory is walked as if it were a plain directory at the link's path.
+//
+// Following links guards against cycles by remembering every directory
+// walked so far, by device and inode (see os.SameFile). A link to one of
+// them, whether an ancestor that would loop forever or a directory already
+// scanned through another path, is not walked again, so each directory's
+// manifests are reported once, under the first path that reached it.
+func (s *Scanner) SetFollowSymlinks(follow bool) {
+	s.followSymlinks = follow
+}
+
+// walkSymlinkedDir walks the directory the symlink at path points to,
+// passing each entry to visit under path rather than the link's target,
+// unless the target is already in visited
+func (s *Scanner) walkSymlinkedDir(path string, visited []os.FileInfo, visit fs.WalkDirFunc) error {
+	target, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return visit(path, nil, fmt.Errorf("resolving symlink: %w", err))
+	}
+	info, err := os.Stat(target)
+	if err != nil {
+		return visit(path, nil, err)
+	}
+
+	for _, seen := range visited {
+		if os.SameFile(info, seen) {
+			if s.verbose {
+				fmt.Fprintf(os.Stderr, "Skipping symlink to an already scanned directory: %s -> %s\n", path, target)
+			}
+			return nil
+		}
+	}
+
+	return filepath.WalkDir(target, func(entry string, d fs.DirEntry, err error) error {
+		rel, relErr := filepath.Rel(target, entry)
+		if relErr != nil {
+			return relErr
+		}
+		return visit(filepath.Join(path, rel), d, err)
+	})
+}
@@ -0,0 +1,166 @@
+package security
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Supply chain checks that CheckManifest can run
+const (
+	CheckTyposquat  = "typosquat"
+	CheckMaintainer = "maintainer"
+	CheckScripts    = "scripts"
+)
+
+// maxListedFailures caps how many packages a failed-lookup warning names
+const maxListedFailures = 5
+
+// ManifestOptions selects which checks CheckManifest runs and how
+type ManifestOptions struct {
+	Checks     []string // Any of CheckTyposquat, CheckMaintainer, and CheckScripts
+	Fetch      FetchOptions
+	Popularity PopularityThresholds
+	// PopularPackages replaces the built-in names the typosquat check compares
+	// against when set; see LoadPopularPackages and MergePopularPackages
+	PopularPackages []string
+	// MinConfidence drops typosquat matches less confident than it; empty
+	// keeps every match
+	MinConfidence Confidence
+}
+
+// ManifestReport holds the supply chain findings for the dependencies of a
+// single package.json
+type ManifestReport struct {
+	PackageJSONPath string
+	Checked         int               // Dependencies checked
+	Reports         []*SecurityReport // Only dependencies with a finding, by name
+	Warnings        []string
+}
+
+// CheckManifest runs the selected checks against every dependency and
+// devDependency of a package.json. The maintainer check fetches each package's
+// registry metadata, which also feeds the popularity check. The scripts check
+// reads install scripts from the installed copy under node_modules, so it
+// needs dependencies to be installed.
+func CheckManifest(ctx context.Context, packageJSONPath string, opts ManifestOptions) *ManifestReport {
+	report := &ManifestReport{PackageJSONPath: packageJSONPath, Reports: make([]*SecurityReport, 0)}
+
+	names, err := manifestDependencies(packageJSONPath)
+	if err != nil {
+		report.Warnings = append(report.Warnings, err.Error())
+		return report
+	}
+	report.Checked = len(names)
+
+	nodeModules := filepath.Join(filepath.Dir(packageJSONPath), "node_modules")
+	checkScripts := slices.Contains(opts.Checks, CheckScripts)
+	if checkScripts && len(names) > 0 {
+		if _, err := os.Stat(nodeModules); err != nil {
+			report.Warnings = append(report.Warnings, "install scripts not checked: node_modules not found, run npm install first")
+			checkScripts = false
+		}
+	}
+
+	var unfetched []string
+	var fetchErr error
+	for _, name := range names {
+		if ctx.Err() != nil {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("supply chain checks stopped: %v", ctx.Err()))
+			break
+		}
+
+		dependency := &SecurityReport{PackageName: name, SuspiciousPatterns: make([]*SuspiciousPattern, 0)}
+
+		if slices.Contains(opts.Checks, CheckTyposquat) {
+			if opts.PopularPackages != nil {
+				dependency.TyposquattingRisk = checkTyposquattingAgainst(name, name, opts.PopularPackages, 2)
+			} else {
+				dependency.TyposquattingRisk = CheckTyposquatting(name, 2)
+			}
+			if risk := dependency.TyposquattingRisk; risk != nil && !risk.Confidence.AtLeast(opts.MinConfidence) {
+				dependency.TyposquattingRisk = nil
+			}
+		}
+
+		if slices.Contains(opts.Checks, CheckMaintainer) {
+			metadata, err := FetchPackageMetadataContext(ctx, name, opts.Fetch)
+			if err != nil {
+				unfetched = append(unfetched, name)
+				if fetchErr == nil {
+					fetchErr = err
+				}
+			} else {
+				dependency.MaintainerRisk = AnalyzeMaintainerRisk(metadata)
+				dependency.PopularityRisk = AnalyzePopularityRisk(metadata, opts.Popularity)
+			}
+		}
+
+		if checkScripts {
+			installed := filepath.Join(nodeModules, filepath.FromSlash(name), "package.json")
+			if _, err := os.Stat(installed); err == nil {
+				patterns, err := DetectSuspiciousPatterns(installed)
+				if err != nil {
+					report.Warnings = append(report.Warnings, fmt.Sprintf("install scripts of %s not checked: %v", name, err))
+				} else {
+					dependency.SuspiciousPatterns = patterns
+				}
+			}
+		}
+
+		dependency.OverallRiskLevel = dependency.assessRisk()
+		if dependency.OverallRiskLevel != "low" {
+			report.Reports = append(report.Reports, dependency)
+		}
+	}
+
+	if len(unfetched) > 0 {
+		listed := unfetched
+		if len(listed) > maxListedFailures {
+			listed = listed[:maxListedFailures]
+		}
+		warning := fmt.Sprintf("maintainer not checked for %d package(s): %s", len(unfetched), strings.Join(listed, ", "))
+		if len(unfetched) > len(listed) {
+			warning += fmt.Sprintf(" and %d more", len(unfetched)-len(listed))
+		}
+		report.Warnings = append(report.Warnings, fmt.Sprintf("%s (%v)", warning, fetchErr))
+	}
+
+	return report
+}
+
+// manifestDependencies returns the sorted names of the dependencies and
+// devDependencies declared in a package.json
+func manifestDependencies(packageJSONPath string) ([]string, error) {
+	data, err := os.ReadFile(packageJSONPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read package.json: %w", err)
+	}
+
+	var manifest struct {
+		Dependencies    map[string]string `json:"dependencies"`
+		DevDependencies map[string]string `json:"devDependencies"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse package.json: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	names := make([]string, 0, len(manifest.Dependencies)+len(manifest.DevDependencies))
+	for _, deps := range []map[string]string{manifest.Dependencies, manifest.DevDependencies} {
+		for name := range deps {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
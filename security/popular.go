@@ -0,0 +1,58 @@
+package security
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// LoadPopularPackages reads a list of package names to compare dependencies
+// against for typosquatting, one per line. Blank lines and # comments are
+// skipped and repeated names are kept once. The file must list at least one
+// name.
+func LoadPopularPackages(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open typosquat list: %w", err)
+	}
+	defer file.Close()
+
+	var names []string
+	lines := bufio.NewScanner(file)
+	for lines.Scan() {
+		name := strings.TrimSpace(lines.Text())
+		if name == "" || strings.HasPrefix(name, "#") {
+			continue
+		}
+		names = append(names, name)
+	}
+	if err := lines.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read typosquat list %s: %w", path, err)
+	}
+
+	names = dedupeNames(names)
+	if len(names) == 0 {
+		return nil, fmt.Errorf("typosquat list %s has no package names", path)
+	}
+	return names, nil
+}
+
+// MergePopularPackages returns the built-in list of popular npm packages
+// followed by names, without repeats
+func MergePopularPackages(names []string) []string {
+	return dedupeNames(append(append([]string(nil), popularPackages...), names...))
+}
+
+// dedupeNames drops repeated names, keeping the first of each
+func dedupeNames(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	unique := make([]string, 0, len(names))
+	for _, name := range names {
+		if !seen[name] {
+			seen[name] = true
+			unique = append(unique, name)
+		}
+	}
+	return unique
+}
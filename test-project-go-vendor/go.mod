@@ -0,0 +1,9 @@
+module test-vendored-go
+
+go 1.19
+
+require (
+	github.com/gorilla/websocket v1.5.0
+	golang.org/x/net v0.17.0
+	gopkg.in/yaml.v2 v2.4.0
+)
@@ -0,0 +1,20 @@
+plugins {
+    kotlin("jvm") version "1.9.22"
+}
+
+group = "com.test"
+version = "1.0-SNAPSHOT"
+
+repositories {
+    mavenCentral()
+}
+
+dependencies {
+    // Log4Shell (CVE-2021-44228)
+    implementation("org.apache.logging.log4j:log4j-core:2.14.1")
+    implementation("com.fasterxml.jackson.core:jackson-databind:2.9.8")
+    implementation(group = "org.springframework", name = "spring-core", version = "5.2.0.RELEASE")
+    implementation("io.netty:netty-all:4.1.42.Final")
+    runtimeOnly("com.h2database:h2:1.4.199")
+    testImplementation("org.yaml:snakeyaml:1.26")
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+
+	"github.com/spf13/cobra"
+)
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print the version and build information",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Fprint(cmd.OutOrStdout(), versionInfo())
+	},
+}
+
+// versionInfo describes the build: the version, commit, and build date set
+// through -ldflags, with the commit and date Go records for builds from a
+// git checkout filling in when they weren't set, then the Go toolchain and
+// platform
+func versionInfo() string {
+	revision, built := commit, buildDate
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && revision == "unknown":
+				revision = setting.Value
+			case setting.Key == "vcs.time" && built == "unknown":
+				built = setting.Value
+			}
+		}
+	}
+
+	return fmt.Sprintf("snoop %s\ncommit: %s\nbuilt: %s\ngo: %s %s/%s\n",
+		version, revision, built, runtime.Version(), runtime.GOOS, runtime.GOARCH)
+}
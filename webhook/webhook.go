@@ -0,0 +1,109 @@
+package webhook
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultMaxAttempts is how many times a report is sent before giving up
+const DefaultMaxAttempts = 3
+
+// Sink posts JSON reports to an HTTP endpoint such as a dashboard or chat webhook
+type Sink struct {
+	url         string
+	headers     http.Header
+	httpClient  *http.Client
+	maxAttempts int
+	backoff     time.Duration
+}
+
+// New creates a sink for url. Each header has the form "Name: value".
+func New(url string, headers []string) (*Sink, error) {
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return nil, fmt.Errorf("invalid webhook URL %q: must start with http:// or https://", url)
+	}
+
+	sink := &Sink{
+		url:     url,
+		headers: make(http.Header),
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		maxAttempts: DefaultMaxAttempts,
+		backoff:     time.Second,
+	}
+
+	for _, header := range headers {
+		name, value, err := ParseHeader(header)
+		if err != nil {
+			return nil, err
+		}
+		sink.headers.Add(name, value)
+	}
+
+	return sink, nil
+}
+
+// ParseHeader splits a "Name: value" header into its name and value
+func ParseHeader(header string) (string, string, error) {
+	name, value, ok := strings.Cut(header, ":")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" || strings.ContainsAny(name, " \t") {
+		return "", "", fmt.Errorf("invalid webhook header %q: expected \"Name: value\"", header)
+	}
+	return name, strings.TrimSpace(value), nil
+}
+
+// Send posts payload as JSON, retrying on network errors, rate limiting, and
+// server errors
+func (s *Sink) Send(payload []byte) error {
+	var lastErr error
+	for attempt := 1; attempt <= s.maxAttempts; attempt++ {
+		if attempt > 1 {
+			time.Sleep(s.backoff * time.Duration(attempt-1))
+		}
+
+		retry, err := s.post(payload)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry {
+			break
+		}
+	}
+	return fmt.Errorf("failed to send webhook: %w", lastErr)
+}
+
+// post makes a single delivery attempt and reports whether a failure is worth retrying
+func (s *Sink) post(payload []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(payload))
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, values := range s.headers {
+		req.Header[name] = values
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	err = fmt.Errorf("endpoint returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, err
+}
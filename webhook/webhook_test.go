@@ -0,0 +1,113 @@
+package webhook
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSendPostsPayloadWithHeaders(t *testing.T) {
+	var received []byte
+	var authorization, contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received, _ = io.ReadAll(r.Body)
+		authorization = r.Header.Get("Authorization")
+		contentType = r.Header.Get("Content-Type")
+	}))
+	defer server.Close()
+
+	sink, err := New(server.URL, []string{"Authorization: Bearer secret-token"})
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	payload := `{"totalVulnerabilities":2}`
+	if err := sink.Send([]byte(payload)); err != nil {
+		t.Fatalf("Send() unexpected error: %v", err)
+	}
+
+	if string(received) != payload {
+		t.Errorf("Send() payload = %s, expected %s", received, payload)
+	}
+	if authorization != "Bearer secret-token" {
+		t.Errorf("Send() Authorization = %q, expected %q", authorization, "Bearer secret-token")
+	}
+	if contentType != "application/json" {
+		t.Errorf("Send() Content-Type = %q, expected application/json", contentType)
+	}
+}
+
+func TestSendRetriesServerErrors(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) < 3 {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	sink, err := New(server.URL, nil)
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+	sink.backoff = time.Millisecond
+
+	if err := sink.Send([]byte(`{}`)); err != nil {
+		t.Fatalf("Send() unexpected error: %v", err)
+	}
+	if attempts.Load() != 3 {
+		t.Errorf("Send() made %d attempts, expected 3", attempts.Load())
+	}
+}
+
+func TestSendDoesNotRetryClientErrors(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	sink, err := New(server.URL, nil)
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+	sink.backoff = time.Millisecond
+
+	if err := sink.Send([]byte(`{}`)); err == nil {
+		t.Errorf("Send() expected an error for 401")
+	}
+	if attempts.Load() != 1 {
+		t.Errorf("Send() made %d attempts, expected 1", attempts.Load())
+	}
+}
+
+func TestParseHeader(t *testing.T) {
+	tests := []struct {
+		header  string
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{"Authorization: Bearer abc", "Authorization", "Bearer abc", false},
+		{"X-Token:abc:def", "X-Token", "abc:def", false},
+		{"NoColon", "", "", true},
+		{": value", "", "", true},
+		{"Bad Name: value", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			name, value, err := ParseHeader(tt.header)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseHeader(%q) error = %v, wantErr %v", tt.header, err, tt.wantErr)
+			}
+			if name != tt.name || value != tt.value {
+				t.Errorf("ParseHeader(%q) = %q, %q, expected %q, %q", tt.header, name, value, tt.name, tt.value)
+			}
+		})
+	}
+}